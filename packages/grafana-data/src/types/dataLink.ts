@@ -50,6 +50,11 @@ export interface DataLink<T extends DataQuery = any> {
   // @internal and subject to change in future releases
   internal?: InternalDataLink<T>;
 
+  // Transformations applied to the field value before the variables of an external link are interpolated. Internal
+  // links define them in `internal.transformations`.
+  // @internal and subject to change in future releases
+  transformations?: DataLinkTransformationConfig[];
+
   origin?: DataLinkConfigOrigin;
 }
 
@@ -62,6 +67,7 @@ export interface DataLink<T extends DataQuery = any> {
 export enum SupportedTransformationType {
   Regex = 'regex',
   Logfmt = 'logfmt',
+  JsonPath = 'jsonpath',
 }
 
 /** @internal */
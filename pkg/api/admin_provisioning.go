@@ -3,12 +3,19 @@ package api
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/web"
 )
 
+// maxWebhookPayloadSize is the maximum size of a webhook payload accepted from a Git server.
+const maxWebhookPayloadSize = 10 << 20
+
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
 //
 // Reload dashboard provisioning configurations.
@@ -55,6 +62,29 @@ func (hs *HTTPServer) AdminProvisioningReloadDatasources(c *contextmodel.ReqCont
 	return response.Success("Datasources config reloaded")
 }
 
+// swagger:route GET /admin/provisioning/datasources/drift admin_provisioning adminProvisioningGetDatasourcesDrift
+//
+// Get the differences between the provisioned datasources and their provisioning config files.
+//
+// Compares the datasources of the provisioning config files with the datasources stored in the database, for example after they have been edited in the UI, and returns the datasources that differ. The values of the secure json data are not returned.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:datasources`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningGetDatasourcesDriftResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningGetDatasourcesDrift(c *contextmodel.ReqContext) response.Response {
+	drift, err := hs.ProvisioningService.GetDataSourcesDrift(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to detect the datasources drift", err)
+	}
+	return response.JSON(http.StatusOK, drift)
+}
+
 // swagger:route POST /admin/provisioning/plugins/reload admin_provisioning adminProvisioningReloadPlugins
 //
 // Reload plugin provisioning configurations.
@@ -85,3 +115,40 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route POST /provisioning/dashboards/{name}/webhook provisioning provisioningDashboardsWebhook
+//
+// Notify a Git dashboard provisioner about changes.
+//
+// Schedules the dashboard provisioner with the given name to fetch its Git repository and provision the changed dashboards.
+// The request is authenticated with the `webhookSecret` of the provisioner, either as a GitHub or Gitea style `sha256` HMAC signature of the payload or as a GitLab style token.
+//
+// Responses:
+// 202: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) ProvisioningDashboardsWebhook(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxWebhookPayloadSize))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read webhook payload", err)
+	}
+
+	err = hs.ProvisioningService.HandleDashboardsWebhook(web.Params(c.Req)[":name"], c.Req.Header, body)
+	switch {
+	case err == nil:
+		return response.JSON(http.StatusAccepted, map[string]string{"message": "Dashboard provisioning scheduled"})
+	case errors.Is(err, dashboards.ErrProvisionerNotFound), errors.Is(err, dashboards.ErrWebhookNotConfigured):
+		return response.Error(http.StatusNotFound, "Dashboard provisioner not found", err)
+	case errors.Is(err, dashboards.ErrWebhookInvalidSignature):
+		return response.Error(http.StatusUnauthorized, "Invalid webhook signature", err)
+	default:
+		return response.Error(http.StatusInternalServerError, "Failed to handle webhook", err)
+	}
+}
+
+// swagger:response adminProvisioningGetDatasourcesDriftResponse
+type AdminProvisioningGetDatasourcesDriftResponse struct {
+	// in:body
+	Body []*datasources.DataSourceDrift `json:"body"`
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -165,3 +166,38 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningGetDatasourcesDrift(t *testing.T) {
+	pService := provisioning.NewProvisioningServiceMock(context.Background())
+	pService.GetDataSourcesDriftFunc = func(ctx context.Context) ([]*datasources.DataSourceDrift, error) {
+		return []*datasources.DataSourceDrift{{
+			OrgID:  1,
+			Name:   "Prometheus",
+			UID:    "prom",
+			Fields: []datasources.FieldDrift{{Field: "url", Provisioned: "http://prometheus:9090", Current: "http://prometheus:9091"}},
+		}}, nil
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.ProvisioningService = pService
+	})
+
+	t.Run("should return the drift with the datasources scope", func(t *testing.T) {
+		permissions := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersDatasources}}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/datasources/drift"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.JSONEq(t, `[{"orgId":1,"name":"Prometheus","uid":"prom","fields":[{"field":"url","provisioned":"http://prometheus:9090","current":"http://prometheus:9091"}]}]`, string(body))
+	})
+
+	t.Run("should fail without permission", func(t *testing.T) {
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/datasources/drift"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
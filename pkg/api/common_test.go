@@ -28,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardusage/dashboardusagetest"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
@@ -294,11 +295,12 @@ func SetupAPITestServer(t *testing.T, opts ...APITestServerOption) *webtest.Serv
 	t.Helper()
 
 	hs := &HTTPServer{
-		RouteRegister:      routing.NewRouteRegister(),
-		License:            &licensing.OSSLicensingService{},
-		Features:           featuremgmt.WithFeatures(),
-		QuotaService:       quotatest.New(false, nil),
-		searchUsersService: &searchusers.OSSService{},
+		RouteRegister:         routing.NewRouteRegister(),
+		License:               &licensing.OSSLicensingService{},
+		Features:              featuremgmt.WithFeatures(),
+		QuotaService:          quotatest.New(false, nil),
+		searchUsersService:    &searchusers.OSSService{},
+		dashboardUsageService: dashboardusagetest.NewFakeService(),
 	}
 
 	for _, opt := range opts {
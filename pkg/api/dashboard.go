@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -220,10 +221,26 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		Meta:      meta,
 	}
 
+	hs.recordDashboardView(c, dash)
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(http.StatusOK, dto)
 }
 
+// recordDashboardView records the view in the dashboard usage. The dashboards loaded by the image renderer are not
+// counted since nobody is looking at them.
+func (hs *HTTPServer) recordDashboardView(c *contextmodel.ReqContext, dash *dashboards.Dashboard) {
+	if c.IsRenderCall {
+		return
+	}
+
+	var userID int64
+	if c.SignedInUser.IsRealUser() {
+		userID = c.SignedInUser.UserID
+	}
+	hs.dashboardUsageService.RecordView(c.Req.Context(), c.SignedInUser.GetOrgID(), dash.UID, userID)
+}
+
 func (hs *HTTPServer) getAnnotationPermissionsByScope(c *contextmodel.ReqContext, actions *dashboardsV0.AnnotationActions, scope string) {
 	var err error
 
@@ -478,6 +495,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	audit.SetResource(ctx, "dashboards", dashboard.UID)
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
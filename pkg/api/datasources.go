@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -179,6 +180,9 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if _, resp := hs.checkProvisionedDataSourceEdit(c, ds); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
@@ -258,6 +262,9 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if _, resp := hs.checkProvisionedDataSourceEdit(c, ds); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
@@ -309,6 +316,9 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 	if dataSource.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if _, resp := hs.checkProvisionedDataSourceEdit(c, dataSource); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{Name: name, OrgID: c.SignedInUser.GetOrgID()}
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -364,6 +374,25 @@ func validateJSONData(ctx context.Context, jsonData *simplejson.Json, cfg *setti
 		}
 	}
 
+	return validateLabelPolicies(jsonData)
+}
+
+// validateLabelPolicies checks that every rule of the label policies is a single label matcher.
+func validateLabelPolicies(jsonData *simplejson.Json) error {
+	labelPolicies, err := datasources.GetLabelPolicies(jsonData)
+	if err != nil {
+		datasourcesLogger.Error("Unable to parse label policies", "error", err)
+		return fmt.Errorf("validation error, invalid format of label policies: %w", err)
+	}
+	for _, policy := range labelPolicies {
+		for _, rule := range policy.Rules {
+			matchers, err := parser.ParseMetricSelector("{" + rule + "}")
+			if err != nil || len(matchers) != 1 {
+				datasourcesLogger.Error("Cannot add a label policy rule that is not a single label matcher", "rule", rule)
+				return errors.New("validation error, invalid label policy rule syntax")
+			}
+		}
+	}
 	return nil
 }
 
@@ -477,6 +506,7 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 	// Clear permission cache for the user who's created the data source, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
+	audit.SetResource(c.Req.Context(), "datasources", dataSource.UID)
 
 	ds := hs.convertModelToDtos(c.Req.Context(), dataSource)
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -603,10 +633,13 @@ func getEncodedString(jsonData *simplejson.Json, key string) string {
 }
 
 func checkTeamHTTPHeaderPermissions(hs *HTTPServer, c *contextmodel.ReqContext, ds *datasources.DataSource, cmd datasources.UpdateDataSourceCommand) (bool, error) {
-	currentTeamHTTPHeaders := getEncodedString(ds.JsonData, "teamHttpHeaders")
-	newTeamHTTPHeaders := getEncodedString(cmd.JsonData, "teamHttpHeaders")
-	if (currentTeamHTTPHeaders != "" || newTeamHTTPHeaders != "") && currentTeamHTTPHeaders != newTeamHTTPHeaders {
-		return evaluateTeamHTTPHeaderPermissions(hs, c, datasources.ScopePrefix+ds.UID)
+	// label policies restrict the access to the data source just like team headers
+	for _, key := range []string{"teamHttpHeaders", datasources.LabelPoliciesJSONDataKey} {
+		current := getEncodedString(ds.JsonData, key)
+		updated := getEncodedString(cmd.JsonData, key)
+		if (current != "" || updated != "") && current != updated {
+			return evaluateTeamHTTPHeaderPermissions(hs, c, datasources.ScopePrefix+ds.UID)
+		}
 	}
 	return true, nil
 }
@@ -615,6 +648,10 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
 	}
+	warning, resp := hs.checkProvisionedDataSourceEdit(c, ds)
+	if resp != nil {
+		return resp
+	}
 
 	_, err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
@@ -650,12 +687,33 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
 
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"message":    "Datasource updated",
 		"id":         cmd.ID,
 		"name":       cmd.Name,
 		"datasource": datasourceDTO,
-	})
+	}
+	if warning != "" {
+		result["warning"] = warning
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// checkProvisionedDataSourceEdit applies the ui_edits setting of the data source provisioning to a change of a data
+// source. It returns an error response when the changes of the provisioned data sources are blocked, and a warning
+// when they are allowed with a warning.
+func (hs *HTTPServer) checkProvisionedDataSourceEdit(c *contextmodel.ReqContext, ds *datasources.DataSource) (string, response.Response) {
+	uiEdits := hs.Cfg.DataSourceProvisioning.UIEdits
+	if uiEdits == "" || uiEdits == setting.ProvisionedDataSourceUIEditsAllow || !hs.ProvisioningService.IsDataSourceProvisioned(ds.OrgID, ds.Name) {
+		return "", nil
+	}
+
+	if uiEdits == setting.ProvisionedDataSourceUIEditsBlock {
+		return "", response.Error(http.StatusForbidden, "Cannot change a provisioned data source, change its provisioning file instead", nil)
+	}
+
+	datasourcesLogger.Warn("Provisioned data source changed outside of its provisioning file", "orgId", ds.OrgID, "uid", ds.UID, "name", ds.Name, "login", c.SignedInUser.GetLogin())
+	return "The data source is provisioned from a file. The changes differ from the file, and can be overwritten when the file is provisioned again.", nil
 }
 
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -387,6 +388,59 @@ func TestUpdateDataSourceByID_DataSourceNameExists(t *testing.T) {
 	require.Equal(t, http.StatusConflict, sc.resp.Code)
 }
 
+func TestUpdateDataSourceByID_ProvisionedDataSource(t *testing.T) {
+	for _, tc := range []struct {
+		uiEdits         string
+		expectedCode    int
+		expectedWarning bool
+	}{
+		{uiEdits: setting.ProvisionedDataSourceUIEditsAllow, expectedCode: http.StatusOK},
+		{uiEdits: setting.ProvisionedDataSourceUIEditsWarn, expectedCode: http.StatusOK, expectedWarning: true},
+		{uiEdits: setting.ProvisionedDataSourceUIEditsBlock, expectedCode: http.StatusForbidden},
+	} {
+		t.Run(tc.uiEdits, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.DataSourceProvisioning.UIEdits = tc.uiEdits
+			pService := provisioning.NewProvisioningServiceMock(context.Background())
+			pService.IsDataSourceProvisionedFunc = func(orgID int64, name string) bool {
+				return orgID == 1 && name == "provisioned"
+			}
+			hs := &HTTPServer{
+				DataSourcesService: &dataSourcesServiceMock{
+					expectedDatasource: &datasources.DataSource{ID: 1, OrgID: 1, UID: "provisioned", Name: "provisioned"},
+				},
+				ProvisioningService:  pService,
+				Cfg:                  cfg,
+				AccessControl:        acimpl.ProvideAccessControl(cfg),
+				accesscontrolService: actest.FakeService{},
+				Live:                 newTestLive(t, nil),
+			}
+
+			sc := setupScenarioContext(t, "/api/datasources/1")
+			sc.m.Put(sc.url, routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				c.Req = web.SetURLParams(c.Req, map[string]string{":id": "1"})
+				c.Req.Body = mockRequestBody(datasources.UpdateDataSourceCommand{
+					Access: "proxy",
+					Type:   "test",
+					Name:   "provisioned",
+				})
+				c.SignedInUser = authedUserWithPermissions(1, 1, []ac.Permission{})
+				return hs.UpdateDataSourceByID(c)
+			}))
+
+			sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, tc.expectedCode, sc.resp.Code)
+			if tc.expectedCode == http.StatusOK {
+				var body map[string]any
+				require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &body))
+				_, hasWarning := body["warning"]
+				require.Equal(t, tc.expectedWarning, hasWarning)
+			}
+		})
+	}
+}
+
 func TestAPI_datasources_AccessControl(t *testing.T) {
 	type testCase struct {
 		desc         string
@@ -521,6 +575,50 @@ func TestValidateLBACHeader(t *testing.T) {
 	}
 }
 
+func TestValidateLabelPolicies(t *testing.T) {
+	testcases := []struct {
+		desc    string
+		given   string
+		wantErr bool
+	}{
+		{
+			desc:  "Should allow valid rules",
+			given: `{"labelPolicies": [{"teamId": 1, "rules": ["namespace=~\"payments-.*\"", "cluster!=\"dev\""]}]}`,
+		},
+		{
+			desc:  "Should allow json data without label policies",
+			given: `{"httpMethod": "POST"}`,
+		},
+		{
+			desc:    "Should return an error for a rule with several matchers",
+			given:   `{"labelPolicies": [{"teamId": 1, "rules": ["namespace=\"a\", cluster=\"b\""]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Should return an error for a rule that is not a matcher",
+			given:   `{"labelPolicies": [{"role": "Viewer", "rules": ["namespace"]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Should return an error for a policy without team or role",
+			given:   `{"labelPolicies": [{"rules": ["namespace=\"a\""]}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			jsonData, err := simplejson.NewJson([]byte(tc.given))
+			require.NoError(t, err)
+			err = validateLabelPolicies(jsonData)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 type dataSourcesServiceMock struct {
 	datasources.DataSourceService
 
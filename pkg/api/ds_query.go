@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -13,10 +14,13 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -76,13 +80,40 @@ func (hs *HTTPServer) QueryMetricsV2(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	start := time.Now()
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+	hs.recordDashboardQuery(c, time.Since(start), resp, err)
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
 	return hs.toJsonStreamingResponse(c.Req.Context(), resp)
 }
 
+// recordDashboardQuery records the query in the usage of the dashboard it was sent from, if any. The dashboard is sent
+// by the client, so the query is only recorded when the user can read the dashboard.
+func (hs *HTTPServer) recordDashboardQuery(c *contextmodel.ReqContext, duration time.Duration, resp *backend.QueryDataResponse, err error) {
+	dashboardUID := c.Req.Header.Get(query.HeaderDashboardUID)
+	if dashboardUID == "" || !hs.Cfg.DashboardUsage.Enabled {
+		return
+	}
+
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+	if canRead, evalErr := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); evalErr != nil || !canRead {
+		return
+	}
+
+	failed := err != nil
+	if resp != nil {
+		for _, res := range resp.Responses {
+			if res.Error != nil {
+				failed = true
+				break
+			}
+		}
+	}
+	hs.dashboardUsageService.RecordQuery(c.Req.Context(), c.SignedInUser.GetOrgID(), dashboardUID, duration, failed)
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(ctx context.Context, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
 	if hs.Features.IsEnabled(ctx, featuremgmt.FlagDatasourceQueryMultiStatus) {
@@ -20,6 +20,8 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	pluginClient "github.com/grafana/grafana/pkg/plugins/manager/client"
 	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage/dashboardusagetest"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -89,6 +91,13 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 		hs.Features = featuremgmt.WithFeatures(featuremgmt.FlagDatasourceQueryMultiStatus, false)
 		hs.QuotaService = quotatest.New(false, nil)
 	})
+	usageService := dashboardusagetest.NewFakeService()
+	serverWithUsage := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.DashboardUsage.Enabled = true
+		hs.queryDataService = qds
+		hs.dashboardUsageService = usageService
+	})
 
 	t.Run("Status code is 400 when data source response has an error and feature toggle is disabled", func(t *testing.T) {
 		req := serverFeatureDisabled.NewPostRequest("/api/ds/query", strings.NewReader(reqValid))
@@ -107,6 +116,33 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 		require.NoError(t, resp.Body.Close())
 		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
 	})
+
+	t.Run("Failed queries are recorded in the usage of the dashboard they are sent from", func(t *testing.T) {
+		req := serverWithUsage.NewPostRequest("/api/ds/query", strings.NewReader(reqValid))
+		req.Header.Set(query.HeaderDashboardUID, "dash")
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+			datasources.ActionQuery:         []string{datasources.ScopeAll},
+			dashboards.ActionDashboardsRead: []string{dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")},
+		}}})
+		resp, err := serverWithUsage.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, []dashboardusagetest.Query{{OrgID: 1, DashboardUID: "dash", Failed: true}}, usageService.Queries)
+	})
+
+	t.Run("Queries are not recorded in the usage of dashboards the user cannot read", func(t *testing.T) {
+		usageService.Queries = nil
+		req := serverWithUsage.NewPostRequest("/api/ds/query", strings.NewReader(reqValid))
+		req.Header.Set(query.HeaderDashboardUID, "other")
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+			datasources.ActionQuery:         []string{datasources.ScopeAll},
+			dashboards.ActionDashboardsRead: []string{dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")},
+		}}})
+		resp, err := serverWithUsage.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Empty(t, usageService.Queries)
+	})
 }
 
 func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
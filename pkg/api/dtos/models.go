@@ -25,6 +25,7 @@ type LoginCommand struct {
 	User     string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
 	Remember bool   `json:"remember"`
+	MFACode  string `json:"mfaCode"`
 }
 
 type CurrentUser struct {
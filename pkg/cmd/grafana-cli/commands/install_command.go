@@ -93,11 +93,7 @@ func installPlugin(ctx context.Context, pluginID, version string, c utils.Comman
 		}
 	}
 
-	repository := repo.NewManager(repo.ManagerCfg{
-		SkipTLSVerify: c.Bool("insecure"),
-		BaseURL:       c.PluginRepoURL(),
-		Logger:        services.Logger,
-	})
+	repository := newRepositoryManager(c)
 
 	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
 
@@ -135,6 +131,23 @@ func installPlugin(ctx context.Context, pluginID, version string, c utils.Comman
 	return nil
 }
 
+// newRepositoryManager returns the repository manager searching the private plugin repositories of the config file,
+// and then the plugin repository URL.
+func newRepositoryManager(c utils.CommandLine) *repo.Manager {
+	repositories, repoURLEnabled := c.PluginRepositories()
+	var baseURL string
+	if repoURLEnabled {
+		baseURL = c.PluginRepoURL()
+	}
+
+	return repo.NewManager(repo.ManagerCfg{
+		SkipTLSVerify: c.Bool("insecure"),
+		BaseURL:       baseURL,
+		Repositories:  repositories,
+		Logger:        services.Logger,
+	})
+}
+
 // uninstallPlugin removes the plugin directory
 func uninstallPlugin(_ context.Context, pluginID string, c utils.CommandLine) error {
 	for _, bundle := range services.GetLocalPlugins(c.PluginDirectory()) {
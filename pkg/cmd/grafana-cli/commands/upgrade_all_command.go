@@ -2,6 +2,9 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"runtime"
 
 	"github.com/hashicorp/go-version"
 
@@ -10,9 +13,18 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/repo"
 )
 
 func shouldUpgrade(installed plugins.FoundPlugin, remote models.Plugin) bool {
+	latest := latestSupportedVersion(remote)
+	if latest == nil {
+		return false
+	}
+	return isNewerVersion(installed, latest.Version)
+}
+
+func isNewerVersion(installed plugins.FoundPlugin, latest string) bool {
 	installedVer := installed.JSONData.Info.Version
 	if installedVer == "" {
 		installedVer = "0.0.0"
@@ -22,8 +34,7 @@ func shouldUpgrade(installed plugins.FoundPlugin, remote models.Plugin) bool {
 		return false
 	}
 
-	latest := latestSupportedVersion(remote)
-	latestVersion, err := version.NewVersion(latest.Version)
+	latestVersion, err := version.NewVersion(latest)
 	if err != nil {
 		return false
 	}
@@ -35,22 +46,23 @@ func upgradeAllCommand(c utils.CommandLine) error {
 
 	localPlugins := services.GetLocalPlugins(pluginsDir)
 
-	remotePlugins, err := services.ListAllPlugins(c.String("repo"))
-	if err != nil {
-		return err
-	}
+	repository := newRepositoryManager(c)
+	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
 
 	pluginsToUpgrade := make([]plugins.FoundPlugin, 0)
 
 	for _, localPlugin := range localPlugins {
-		for _, p := range remotePlugins.Plugins {
-			remotePlugin := p
-			if localPlugin.Primary.JSONData.ID != remotePlugin.ID {
+		pluginID := localPlugin.Primary.JSONData.ID
+		latest, err := repository.PluginVersion(pluginID, "", compatOpts)
+		if err != nil {
+			if isNotInRepositories(err) {
+				logger.Debugf("Skipping %s, which is not available in the plugin repositories\n", pluginID)
 				continue
 			}
-			if shouldUpgrade(localPlugin.Primary, remotePlugin) {
-				pluginsToUpgrade = append(pluginsToUpgrade, localPlugin.Primary)
-			}
+			return err
+		}
+		if isNewerVersion(localPlugin.Primary, latest.Version) {
+			pluginsToUpgrade = append(pluginsToUpgrade, localPlugin.Primary)
 		}
 	}
 
@@ -58,7 +70,7 @@ func upgradeAllCommand(c utils.CommandLine) error {
 	for _, p := range pluginsToUpgrade {
 		logger.Infof("Updating %v \n", p.JSONData.ID)
 
-		err = uninstallPlugin(ctx, p.JSONData.ID, c)
+		err := uninstallPlugin(ctx, p.JSONData.ID, c)
 		if err != nil {
 			return err
 		}
@@ -75,3 +87,12 @@ func upgradeAllCommand(c utils.CommandLine) error {
 
 	return nil
 }
+
+// isNotInRepositories returns true if the plugin is not available for the system in any plugin repository.
+func isNotInRepositories(err error) bool {
+	var errResp repo.ErrResponse4xx
+	if errors.As(err, &errResp) && errResp.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	return errors.Is(err, repo.ErrArcNotFoundBase)
+}
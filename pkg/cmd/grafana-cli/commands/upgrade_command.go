@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 
 	"github.com/fatih/color"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/plugins/repo"
 )
 
 func upgradeCommand(c utils.CommandLine) error {
@@ -25,12 +27,13 @@ func upgradeCommand(c utils.CommandLine) error {
 		return err
 	}
 
-	plugin, err := services.GetPluginInfoFromRepo(pluginID, c.PluginRepoURL())
+	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
+	latest, err := newRepositoryManager(c).PluginVersion(pluginID, "", compatOpts)
 	if err != nil {
 		return err
 	}
 
-	if shouldUpgrade(localPlugin, plugin) {
+	if isNewerVersion(localPlugin, latest.Version) {
 		if err = uninstallPlugin(ctx, pluginID, c); err != nil {
 			return fmt.Errorf("failed to remove plugin '%s': %w", pluginID, err)
 		}
@@ -25,6 +25,7 @@ type CommandLine interface {
 
 	PluginDirectory() string
 	PluginRepoURL() string
+	PluginRepositories() ([]setting.PluginRepositorySettings, bool)
 	PluginURL() string
 }
 
@@ -74,24 +75,42 @@ func (c *ContextCommandLine) PluginRepoURL() string {
 	}
 
 	// if --config flag is set, try to get the GrafanaComAPIURL setting
-	if c.ConfigFile() != "" {
-		configOptions := strings.Split(c.String("configOverrides"), " ")
-		cfg, err := setting.NewCfgFromArgs(setting.CommandLineArgs{
-			Config:   c.ConfigFile(),
-			HomePath: c.HomePath(),
-			Args:     append(configOptions, c.Args().Slice()...),
-		})
-
-		if err != nil {
-			logger.Debug("Could not parse config file", err)
-		} else if cfg.GrafanaComAPIURL != "" {
-			return cfg.GrafanaComAPIURL + "/plugins"
-		}
+	if cfg := c.cfg(); cfg != nil && cfg.GrafanaComAPIURL != "" {
+		return cfg.GrafanaComAPIURL + "/plugins"
 	}
 	// fallback to default value
 	return c.String("repo")
 }
 
+// PluginRepositories returns the private plugin repositories of the --config file, and whether the repository of
+// PluginRepoURL is used too. It is always used if the --repo flag is specified.
+func (c *ContextCommandLine) PluginRepositories() ([]setting.PluginRepositorySettings, bool) {
+	cfg := c.cfg()
+	if cfg == nil {
+		return nil, true
+	}
+	return cfg.PluginRepositories, slices.Contains(c.FlagNames(), "repo") || !cfg.PluginGrafanaComRepositoryDisabled
+}
+
+// cfg returns the configuration of the --config file, or nil if it is not set or cannot be parsed.
+func (c *ContextCommandLine) cfg() *setting.Cfg {
+	if c.ConfigFile() == "" {
+		return nil
+	}
+
+	configOptions := strings.Split(c.String("configOverrides"), " ")
+	cfg, err := setting.NewCfgFromArgs(setting.CommandLineArgs{
+		Config:   c.ConfigFile(),
+		HomePath: c.HomePath(),
+		Args:     append(configOptions, c.Args().Slice()...),
+	})
+	if err != nil {
+		logger.Debug("Could not parse config file", err)
+		return nil
+	}
+	return cfg
+}
+
 func (c *ContextCommandLine) PluginURL() string {
 	return c.String("pluginUrl")
 }
@@ -5,6 +5,8 @@ package utils
 import (
 	mock "github.com/stretchr/testify/mock"
 	cli "github.com/urfave/cli/v2"
+
+	setting "github.com/grafana/grafana/pkg/setting"
 )
 
 // MockCommandLine is an autogenerated mock type for the CommandLine type
@@ -132,6 +134,32 @@ func (_m *MockCommandLine) PluginRepoURL() string {
 	return r0
 }
 
+// PluginRepositories provides a mock function with given fields:
+func (_m *MockCommandLine) PluginRepositories() ([]setting.PluginRepositorySettings, bool) {
+	ret := _m.Called()
+
+	var r0 []setting.PluginRepositorySettings
+	var r1 bool
+	if rf, ok := ret.Get(0).(func() ([]setting.PluginRepositorySettings, bool)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []setting.PluginRepositorySettings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]setting.PluginRepositorySettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // PluginURL provides a mock function with given fields:
 func (_m *MockCommandLine) PluginURL() string {
 	ret := _m.Called()
@@ -20,6 +20,9 @@ type PluginManagementCfg struct {
 
 	GrafanaComAPIURL string
 
+	PluginRepositories           []setting.PluginRepositorySettings
+	GrafanaComRepositoryDisabled bool
+
 	GrafanaAppURL string
 
 	Features Features
@@ -38,6 +41,7 @@ type Features struct {
 func NewPluginManagementCfg(devMode bool, pluginsPath string, pluginSettings setting.PluginSettings, pluginsAllowUnsigned []string,
 	pluginsCDNURLTemplate string, appURL string, features Features, angularSupportEnabled bool,
 	grafanaComAPIURL string, disablePlugins []string, hideAngularDeprecation []string, forwardHostEnvVars []string,
+	pluginRepositories []setting.PluginRepositorySettings, grafanaComRepositoryDisabled bool,
 ) *PluginManagementCfg {
 	return &PluginManagementCfg{
 		PluginsPath:            pluginsPath,
@@ -52,5 +56,8 @@ func NewPluginManagementCfg(devMode bool, pluginsPath string, pluginSettings set
 		AngularSupportEnabled:  angularSupportEnabled,
 		HideAngularDeprecation: hideAngularDeprecation,
 		ForwardHostEnvVars:     forwardHostEnvVars,
+
+		PluginRepositories:           pluginRepositories,
+		GrafanaComRepositoryDisabled: grafanaComRepositoryDisabled,
 	}
 }
@@ -35,4 +35,25 @@ var (
 	// Exposed as a base error to wrap it with plugin cancelled errors.
 	ErrPluginRequestCanceledErrorBase = errutil.ClientClosedRequest("plugin.requestCanceled",
 		errutil.WithPublicMessage("Plugin request canceled"))
+
+	// ErrDataSourceQueueFull error returned when too many requests are waiting
+	// for a data source that runs its maximum number of concurrent requests.
+	ErrDataSourceQueueFull = errutil.TooManyRequests("plugin.dataSourceQueueFull",
+		errutil.WithPublicMessage("Too many requests are waiting for the data source, try again later"))
+
+	// ErrDataSourceQueueTimeout error returned when a request waited too long
+	// for a data source that runs its maximum number of concurrent requests.
+	ErrDataSourceQueueTimeout = errutil.TooManyRequests("plugin.dataSourceQueueTimeout",
+		errutil.WithPublicMessage("Timed out waiting for the data source to accept the request, try again later"))
+
+	// ErrDataSourceCircuitOpen error returned when the circuit breaker of a data source
+	// is open after too many consecutive failed requests.
+	ErrDataSourceCircuitOpen = errutil.BadGateway("plugin.dataSourceCircuitOpen",
+		errutil.WithPublicMessage("The data source is unavailable after too many failed requests, try again later"),
+		errutil.WithDownstream())
+
+	// ErrQueryResponseTooLarge error returned when the response of a query
+	// exceeds the configured size limits.
+	ErrQueryResponseTooLarge = errutil.BadRequest("plugin.queryResponseTooLarge",
+		errutil.WithPublicMessage("The query response exceeds the size limits, narrow down the query"))
 )
@@ -21,8 +21,22 @@ type Version struct {
 	Version string              `json:"version"`
 	Arch    map[string]ArchMeta `json:"packages"`
 	URL     string              `json:"url"`
+	// DownloadURL is the archive of the version in a plugin index file.
+	DownloadURL string `json:"downloadUrl,omitempty"`
 }
 
 type ArchMeta struct {
 	SHA256 string `json:"sha256"`
+	// DownloadURL is the archive of the package in a plugin index file.
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+// PluginIndex is the static index file of a plugin repository.
+type PluginIndex struct {
+	Plugins []IndexPlugin `json:"plugins"`
+}
+
+type IndexPlugin struct {
+	ID       string    `json:"id"`
+	Versions []Version `json:"versions"`
 }
@@ -0,0 +1,212 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/grafana/grafana/pkg/plugins/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// repository is a source of plugin versions and archives.
+type repository interface {
+	name() string
+	// pluginVersions returns the versions of the plugin, newest first. It returns an ErrResponse4xx with a 404 status
+	// if the repository does not have the plugin.
+	pluginVersions(pluginID string, compatOpts CompatOpts) ([]Version, error)
+	// downloadURL returns the URL or the path of the archive of the plugin version.
+	downloadURL(pluginID string, version VersionData, compatOpts SystemCompatOpts) (string, error)
+	httpClient() *Client
+}
+
+func newRepository(cfg setting.PluginRepositorySettings, logger log.PrettyLogger) repository {
+	client := NewClient(cfg.SkipTLSVerify, logger)
+	if cfg.IndexFile != "" {
+		return &indexRepository{
+			repoName: cfg.Name,
+			location: cfg.IndexFile,
+			client:   client,
+			log:      logger,
+		}
+	}
+	return &apiRepository{
+		repoName: cfg.Name,
+		baseURL:  cfg.URL,
+		client:   client,
+		log:      logger,
+	}
+}
+
+// apiRepository is a repository serving the grafana.com plugin API.
+type apiRepository struct {
+	repoName string
+	baseURL  string
+	client   *Client
+
+	log log.PrettyLogger
+}
+
+func (r *apiRepository) name() string {
+	return r.repoName
+}
+
+func (r *apiRepository) httpClient() *Client {
+	return r.client
+}
+
+func (r *apiRepository) downloadURL(pluginID string, version VersionData, _ SystemCompatOpts) (string, error) {
+	return fmt.Sprintf("%s/%s/versions/%s/download", r.baseURL, pluginID, version.Version), nil
+}
+
+// pluginVersions will get version info from /api/plugins/$pluginID/versions
+func (r *apiRepository) pluginVersions(pluginID string, compatOpts CompatOpts) ([]Version, error) {
+	u, err := url.Parse(r.baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = path.Join(u.Path, pluginID, "versions")
+
+	body, err := r.client.SendReq(u, compatOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v PluginVersions
+	err = json.Unmarshal(body, &v)
+	if err != nil {
+		r.log.Error("Failed to unmarshal plugin repo response", err)
+		return nil, err
+	}
+
+	if len(v.Versions) == 0 {
+		// /plugins/{pluginId}/versions returns 200 even if the plugin doesn't exists
+		// but the response is empty. In this case we return 404.
+		return nil, newErrResponse4xx(http.StatusNotFound).withMessage("Plugin not found")
+	}
+
+	return v.Versions, nil
+}
+
+// indexRepository is a repository listing its plugins in a static index file, on disk or served over HTTP. The
+// download URLs of the index can be relative to the location of the index.
+type indexRepository struct {
+	repoName string
+	location string
+	client   *Client
+
+	log log.PrettyLogger
+}
+
+func (r *indexRepository) name() string {
+	return r.repoName
+}
+
+func (r *indexRepository) httpClient() *Client {
+	return r.client
+}
+
+func (r *indexRepository) locationURL() (*url.URL, bool) {
+	u, err := url.Parse(r.location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+	return u, true
+}
+
+func (r *indexRepository) index(compatOpts CompatOpts) (PluginIndex, error) {
+	var body []byte
+	var err error
+	if u, ok := r.locationURL(); ok {
+		body, err = r.client.SendReq(u, compatOpts)
+	} else {
+		// We can ignore the gosec G304 warning since the path of the index comes from the configuration.
+		// nolint:gosec
+		body, err = os.ReadFile(r.location)
+	}
+	if err != nil {
+		return PluginIndex{}, fmt.Errorf("failed to read the index of plugin repository %s: %w", r.repoName, err)
+	}
+
+	var index PluginIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return PluginIndex{}, fmt.Errorf("failed to parse the index of plugin repository %s: %w", r.repoName, err)
+	}
+	return index, nil
+}
+
+func (r *indexRepository) pluginVersions(pluginID string, compatOpts CompatOpts) ([]Version, error) {
+	index, err := r.index(compatOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range index.Plugins {
+		if p.ID != pluginID || len(p.Versions) == 0 {
+			continue
+		}
+		versions := make([]Version, len(p.Versions))
+		copy(versions, p.Versions)
+		sortVersionsNewestFirst(versions)
+		return versions, nil
+	}
+
+	return nil, newErrResponse4xx(http.StatusNotFound).withMessage("Plugin not found")
+}
+
+func (r *indexRepository) downloadURL(pluginID string, version VersionData, compatOpts SystemCompatOpts) (string, error) {
+	ref := version.DownloadURL
+	if archMeta, exists := version.Arch[compatOpts.OSAndArch()]; exists && archMeta.DownloadURL != "" {
+		ref = archMeta.DownloadURL
+	} else if archMeta, exists := version.Arch["any"]; exists && archMeta.DownloadURL != "" {
+		ref = archMeta.DownloadURL
+	}
+	if ref == "" {
+		return "", fmt.Errorf("plugin repository %s has no download URL for %s v%s", r.repoName, pluginID, version.Version)
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if u, ok := r.locationURL(); ok {
+		return u.ResolveReference(refURL).String(), nil
+	}
+	if refURL.IsAbs() || filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(r.location), filepath.FromSlash(ref)), nil
+}
+
+// sortVersionsNewestFirst sorts the versions the way grafana.com does. The versions that are not semantic versions
+// are kept last.
+func sortVersionsNewestFirst(versions []Version) {
+	semvers := make(map[string]*semver.Version, len(versions))
+	for _, v := range versions {
+		if sv, err := semver.NewVersion(v.Version); err == nil {
+			semvers[v.Version] = sv
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, vj := semvers[versions[i].Version], semvers[versions[j].Version]
+		if vi == nil || vj == nil {
+			return vi != nil
+		}
+		return vi.GreaterThan(vj)
+	})
+}
+
+// isNotFound returns true if the repository does not have the plugin.
+func isNotFound(err error) bool {
+	var errResp ErrResponse4xx
+	return errors.As(err, &errResp) && errResp.StatusCode() == http.StatusNotFound
+}
@@ -2,60 +2,79 @@ package repo
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
 	"net/url"
-	"path"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/log"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type Manager struct {
-	client  *Client
-	baseURL string
+	client *Client
+	// repositories are searched in order for the plugins to install.
+	repositories []repository
 
 	log log.PrettyLogger
 }
 
 func ProvideService(cfg *config.PluginManagementCfg) (*Manager, error) {
-	baseURL, err := url.JoinPath(cfg.GrafanaComAPIURL, "/plugins")
-	if err != nil {
-		return nil, err
+	var baseURL string
+	if !cfg.GrafanaComRepositoryDisabled {
+		var err error
+		if baseURL, err = url.JoinPath(cfg.GrafanaComAPIURL, "/plugins"); err != nil {
+			return nil, err
+		}
 	}
 
 	return NewManager(ManagerCfg{
 		SkipTLSVerify: false,
 		BaseURL:       baseURL,
+		Repositories:  cfg.PluginRepositories,
 		Logger:        log.NewPrettyLogger("plugin.repository"),
 	}), nil
 }
 
 type ManagerCfg struct {
 	SkipTLSVerify bool
-	BaseURL       string
-	Logger        log.PrettyLogger
+	// BaseURL is the grafana.com plugin API, which is searched after the Repositories. It is not used if empty.
+	BaseURL      string
+	Repositories []setting.PluginRepositorySettings
+	Logger       log.PrettyLogger
 }
 
 func NewManager(cfg ManagerCfg) *Manager {
+	client := NewClient(cfg.SkipTLSVerify, cfg.Logger)
+
+	repositories := make([]repository, 0, len(cfg.Repositories)+1)
+	for _, r := range cfg.Repositories {
+		repositories = append(repositories, newRepository(r, cfg.Logger))
+	}
+	if cfg.BaseURL != "" {
+		repositories = append(repositories, &apiRepository{
+			repoName: "grafana.com",
+			baseURL:  cfg.BaseURL,
+			client:   client,
+			log:      cfg.Logger,
+		})
+	}
+
 	return &Manager{
-		baseURL: cfg.BaseURL,
-		client:  NewClient(cfg.SkipTLSVerify, cfg.Logger),
-		log:     cfg.Logger,
+		client:       client,
+		repositories: repositories,
+		log:          cfg.Logger,
 	}
 }
 
 // GetPluginArchive fetches the requested plugin archive
 func (m *Manager) GetPluginArchive(ctx context.Context, pluginID, version string, compatOpts CompatOpts) (*PluginArchive, error) {
-	dlOpts, err := m.GetPluginArchiveInfo(ctx, pluginID, version, compatOpts)
+	r, dlOpts, err := m.pluginArchiveInfo(pluginID, version, compatOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	return m.client.Download(ctx, dlOpts.URL, dlOpts.Checksum, compatOpts)
+	return r.httpClient().Download(ctx, dlOpts.URL, dlOpts.Checksum, compatOpts)
 }
 
 // GetPluginArchiveByURL fetches the requested plugin archive from the provided `pluginZipURL`
@@ -65,75 +84,74 @@ func (m *Manager) GetPluginArchiveByURL(ctx context.Context, pluginZipURL string
 
 // GetPluginArchiveInfo returns the options for downloading the requested plugin (with optional `version`)
 func (m *Manager) GetPluginArchiveInfo(_ context.Context, pluginID, version string, compatOpts CompatOpts) (*PluginArchiveInfo, error) {
-	v, err := m.PluginVersion(pluginID, version, compatOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	return &PluginArchiveInfo{
-		Version:  v.Version,
-		Checksum: v.Checksum,
-		URL:      m.downloadURL(pluginID, v.Version),
-	}, nil
+	_, info, err := m.pluginArchiveInfo(pluginID, version, compatOpts)
+	return info, err
 }
 
-// PluginVersion will return plugin version based on the requested information
-func (m *Manager) PluginVersion(pluginID, version string, compatOpts CompatOpts) (VersionData, error) {
-	versions, err := m.grafanaCompatiblePluginVersions(pluginID, compatOpts)
+func (m *Manager) pluginArchiveInfo(pluginID, version string, compatOpts CompatOpts) (repository, *PluginArchiveInfo, error) {
+	r, v, err := m.pluginVersion(pluginID, version, compatOpts)
 	if err != nil {
-		return VersionData{}, err
-	}
-
-	sysCompatOpts, exists := compatOpts.System()
-	if !exists {
-		return VersionData{}, errors.New("no system compatibility requirements set")
+		return nil, nil, err
 	}
 
-	compatibleVer, err := SelectSystemCompatibleVersion(m.log, versions, pluginID, version, sysCompatOpts)
+	sysCompatOpts, _ := compatOpts.System()
+	downloadURL, err := r.downloadURL(pluginID, v, sysCompatOpts)
 	if err != nil {
-		return VersionData{}, err
+		return nil, nil, err
 	}
 
-	isGrafanaCorePlugin := strings.HasPrefix(compatibleVer.URL, "https://github.com/grafana/grafana/tree/main/public/app/plugins/")
-	_, hasAnyArch := compatibleVer.Arch["any"]
-	if isGrafanaCorePlugin && hasAnyArch {
-		// Trying to install a coupled core plugin
-		return VersionData{}, ErrCorePlugin(pluginID)
-	}
-
-	return compatibleVer, nil
+	return r, &PluginArchiveInfo{
+		Version:  v.Version,
+		Checksum: v.Checksum,
+		URL:      downloadURL,
+	}, nil
 }
 
-func (m *Manager) downloadURL(pluginID, version string) string {
-	return fmt.Sprintf("%s/%s/versions/%s/download", m.baseURL, pluginID, version)
+// PluginVersion will return plugin version based on the requested information
+func (m *Manager) PluginVersion(pluginID, version string, compatOpts CompatOpts) (VersionData, error) {
+	_, v, err := m.pluginVersion(pluginID, version, compatOpts)
+	return v, err
 }
 
-// grafanaCompatiblePluginVersions will get version info from /api/plugins/$pluginID/versions
-func (m *Manager) grafanaCompatiblePluginVersions(pluginID string, compatOpts CompatOpts) ([]Version, error) {
-	u, err := url.Parse(m.baseURL)
-	if err != nil {
-		return nil, err
-	}
-
-	u.Path = path.Join(u.Path, pluginID, "versions")
-
-	body, err := m.client.SendReq(u, compatOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	var v PluginVersions
-	err = json.Unmarshal(body, &v)
-	if err != nil {
-		m.log.Error("Failed to unmarshal plugin repo response", err)
-		return nil, err
+// pluginVersion returns the plugin version from the first repository that has the plugin. The other repositories
+// are not searched if that repository has no version compatible with the system.
+func (m *Manager) pluginVersion(pluginID, version string, compatOpts CompatOpts) (repository, VersionData, error) {
+	if len(m.repositories) == 0 {
+		return nil, VersionData{}, errors.New("no plugin repositories configured")
 	}
 
-	if len(v.Versions) == 0 {
-		// /plugins/{pluginId}/versions returns 200 even if the plugin doesn't exists
-		// but the response is empty. In this case we return 404.
-		return nil, newErrResponse4xx(http.StatusNotFound).withMessage("Plugin not found")
+	var notFoundErr error
+	for _, r := range m.repositories {
+		versions, err := r.pluginVersions(pluginID, compatOpts)
+		if isNotFound(err) {
+			m.log.Debugf("Plugin %s not found in plugin repository %s", pluginID, r.name())
+			notFoundErr = err
+			continue
+		}
+		if err != nil {
+			return nil, VersionData{}, err
+		}
+
+		sysCompatOpts, exists := compatOpts.System()
+		if !exists {
+			return nil, VersionData{}, errors.New("no system compatibility requirements set")
+		}
+
+		compatibleVer, err := SelectSystemCompatibleVersion(m.log, versions, pluginID, version, sysCompatOpts)
+		if err != nil {
+			return nil, VersionData{}, err
+		}
+
+		isGrafanaCorePlugin := strings.HasPrefix(compatibleVer.URL, "https://github.com/grafana/grafana/tree/main/public/app/plugins/")
+		_, hasAnyArch := compatibleVer.Arch["any"]
+		if isGrafanaCorePlugin && hasAnyArch {
+			// Trying to install a coupled core plugin
+			return nil, VersionData{}, ErrCorePlugin(pluginID)
+		}
+
+		m.log.Debugf("Found plugin %s v%s in plugin repository %s", pluginID, compatibleVer.Version, r.name())
+		return r, compatibleVer, nil
 	}
 
-	return v.Versions, nil
+	return nil, VersionData{}, notFoundErr
 }
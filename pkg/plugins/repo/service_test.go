@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/plugins/log"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -216,3 +218,114 @@ func createPluginVersions(versions ...versionArg) []Version {
 
 	return vs
 }
+
+func TestGetPluginArchiveFromRepositories(t *testing.T) {
+	const (
+		pluginID       = "grafana-test-datasource"
+		opSys          = "darwin"
+		arch           = "amd64"
+		grafanaVersion = "10.0.0"
+		sha            = "69f698961b6ea651211a187874434821c4727cc22de022e3a7059116d21c75b1"
+	)
+
+	pluginZip := createPluginArchive(t)
+	d, err := os.ReadFile(pluginZip.Name())
+	require.NoError(t, err)
+	require.NoError(t, pluginZip.Close())
+	require.NoError(t, os.Remove(pluginZip.Name()))
+
+	indexDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(indexDir, "archives"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, "archives", "test-1.1.0.zip"), d, 0600))
+
+	writeIndex := func(t *testing.T, checksum string) string {
+		t.Helper()
+		index := fmt.Sprintf(`{
+			"plugins": [{
+				"id": "%s",
+				"versions": [
+					{"version": "1.0.0", "downloadUrl": "archives/test-1.0.0.zip"},
+					{"version": "1.1.0", "packages": {"any": {"sha256": "%s", "downloadUrl": "archives/test-1.1.0.zip"}}}
+				]
+			}]
+		}`, pluginID, checksum)
+		indexFile := filepath.Join(indexDir, "index.json")
+		require.NoError(t, os.WriteFile(indexFile, []byte(index), 0600))
+		return indexFile
+	}
+
+	srv := mockPluginVersionsAPI(t, srvData{
+		pluginID:       "grafana-other-datasource",
+		version:        "2.0.0",
+		opSys:          opSys,
+		arch:           arch,
+		grafanaVersion: grafanaVersion,
+		sha:            sha,
+		archive:        d,
+	})
+	t.Cleanup(srv.Close)
+
+	co := NewCompatOpts(grafanaVersion, opSys, arch)
+
+	t.Run("Should download the latest version from the index file", func(t *testing.T) {
+		m := NewManager(ManagerCfg{
+			BaseURL:      srv.URL,
+			Repositories: []setting.PluginRepositorySettings{{Name: "internal", IndexFile: writeIndex(t, sha)}},
+			Logger:       log.NewTestPrettyLogger(),
+		})
+
+		info, err := m.GetPluginArchiveInfo(context.Background(), pluginID, "", co)
+		require.NoError(t, err)
+		require.Equal(t, "1.1.0", info.Version)
+		require.Equal(t, filepath.Join(indexDir, "archives", "test-1.1.0.zip"), info.URL)
+
+		archive, err := m.GetPluginArchive(context.Background(), pluginID, "", co)
+		require.NoError(t, err)
+		verifyArchive(t, archive)
+	})
+
+	t.Run("Should verify the checksum of the archives of the index file", func(t *testing.T) {
+		m := NewManager(ManagerCfg{
+			Repositories: []setting.PluginRepositorySettings{{Name: "internal", IndexFile: writeIndex(t, "1a2b3c")}},
+			Logger:       log.NewTestPrettyLogger(),
+		})
+
+		_, err := m.GetPluginArchive(context.Background(), pluginID, "1.1.0", co)
+		require.ErrorIs(t, err, ErrChecksumMismatchBase)
+	})
+
+	t.Run("Should search the next repository for the plugins missing from a repository", func(t *testing.T) {
+		m := NewManager(ManagerCfg{
+			BaseURL:      srv.URL,
+			Repositories: []setting.PluginRepositorySettings{{Name: "internal", IndexFile: writeIndex(t, sha)}},
+			Logger:       log.NewTestPrettyLogger(),
+		})
+
+		archive, err := m.GetPluginArchive(context.Background(), "grafana-other-datasource", "2.0.0", co)
+		require.NoError(t, err)
+		verifyArchive(t, archive)
+
+		_, err = m.GetPluginArchive(context.Background(), "grafana-missing-datasource", "", co)
+		var errResp ErrResponse4xx
+		require.ErrorAs(t, err, &errResp)
+		require.Equal(t, http.StatusNotFound, errResp.StatusCode())
+	})
+
+	t.Run("Should not search grafana.com if it is disabled", func(t *testing.T) {
+		m := NewManager(ManagerCfg{
+			Repositories: []setting.PluginRepositorySettings{{Name: "internal", IndexFile: writeIndex(t, sha)}},
+			Logger:       log.NewTestPrettyLogger(),
+		})
+
+		_, err := m.GetPluginArchiveInfo(context.Background(), "grafana-other-datasource", "2.0.0", co)
+		var errResp ErrResponse4xx
+		require.ErrorAs(t, err, &errResp)
+		require.Equal(t, http.StatusNotFound, errResp.StatusCode())
+	})
+}
+
+func TestSortVersionsNewestFirst(t *testing.T) {
+	versions := []Version{{Version: "1.0.0"}, {Version: "main"}, {Version: "1.10.0"}, {Version: "1.2.0-beta.1"}, {Version: "1.2.0"}}
+	sortVersionsNewestFirst(versions)
+	require.Equal(t, []Version{{Version: "1.10.0"}, {Version: "1.2.0"}, {Version: "1.2.0-beta.1"}, {Version: "1.0.0"}, {Version: "main"}}, versions)
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type VersionData struct {
-	Version  string
-	Checksum string
-	Arch     map[string]ArchMeta
-	URL      string
+	Version     string
+	Checksum    string
+	Arch        map[string]ArchMeta
+	URL         string
+	DownloadURL string
 }
 
 // SelectSystemCompatibleVersion selects the most appropriate plugin version based on os + architecture
@@ -30,10 +31,11 @@ func SelectSystemCompatibleVersion(log log.PrettyLogger, versions []Version, plu
 
 	if version == "" {
 		return VersionData{
-			Version:  latestForArch.Version,
-			Checksum: checksum(latestForArch, compatOpts),
-			Arch:     latestForArch.Arch,
-			URL:      latestForArch.URL,
+			Version:     latestForArch.Version,
+			Checksum:    checksum(latestForArch, compatOpts),
+			Arch:        latestForArch.Arch,
+			URL:         latestForArch.URL,
+			DownloadURL: latestForArch.DownloadURL,
 		}, nil
 	}
 	for _, v := range versions {
@@ -56,10 +58,11 @@ func SelectSystemCompatibleVersion(log log.PrettyLogger, versions []Version, plu
 	}
 
 	return VersionData{
-		Version:  ver.Version,
-		Checksum: checksum(ver, compatOpts),
-		Arch:     ver.Arch,
-		URL:      ver.URL,
+		Version:     ver.Version,
+		Checksum:    checksum(ver, compatOpts),
+		Arch:        ver.Arch,
+		URL:         ver.URL,
+		DownloadURL: ver.DownloadURL,
 	}, nil
 }
 
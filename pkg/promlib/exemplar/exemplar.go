@@ -0,0 +1,44 @@
+// Package exemplar samples exemplars and turns them into a single frame that time series panels display as markers
+// on top of the series. It is used by Prometheus, and by the data sources that extract exemplars from their own
+// responses, such as trace IDs found in Loki log lines or InfluxDB columns.
+package exemplar
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type Exemplar struct {
+	// SeriesLabels are the labels of the series the exemplar belongs to, they are added to the exemplar frame as
+	// label fields.
+	SeriesLabels map[string]string
+	// Fields and RowIdx are the fields of the response and the row the exemplar comes from. The fields whose name
+	// is a tracked label override the value from SeriesLabels.
+	Fields    []*data.Field
+	RowIdx    int
+	Value     float64
+	Timestamp time.Time
+}
+
+// NewAnnotationsMeta returns the metadata of an exemplar frame that is not processed by the Prometheus frontend, so
+// that the frame is displayed with the annotations of the time series panels.
+func NewAnnotationsMeta() *data.FrameMeta {
+	return &data.FrameMeta{
+		DataTopic: data.DataTopicAnnotations,
+		Custom:    map[string]any{"resultType": "exemplar"},
+	}
+}
+
+// alignTimestamp rounds t down to a multiple of step.
+func alignTimestamp(t time.Time, step time.Duration) time.Time {
+	if step <= 0 {
+		return t.UTC()
+	}
+	ns := t.UnixNano()
+	aligned := ns - ns%int64(step)
+	if ns < 0 && ns%int64(step) != 0 {
+		aligned -= int64(step)
+	}
+	return time.Unix(0, aligned).UTC()
+}
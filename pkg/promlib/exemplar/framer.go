@@ -14,6 +14,7 @@ type Framer struct {
 	labelTracker LabelTracker
 	meta         *data.FrameMeta
 	refID        string
+	links        map[string][]data.DataLink
 }
 
 func NewFramer(sampler Sampler, labelTracker LabelTracker) *Framer {
@@ -21,6 +22,7 @@ func NewFramer(sampler Sampler, labelTracker LabelTracker) *Framer {
 		frames:       data.Frames{},
 		sampler:      sampler,
 		labelTracker: labelTracker,
+		links:        map[string][]data.DataLink{},
 	}
 }
 
@@ -32,6 +34,12 @@ func (f *Framer) SetRefID(refID string) {
 	f.refID = refID
 }
 
+// SetLinks sets the data links of the label field of the exemplar frame with the given name, for example to open
+// the trace of the trace ID label.
+func (f *Framer) SetLinks(labelName string, links []data.DataLink) {
+	f.links[labelName] = links
+}
+
 func (f *Framer) AddFrame(frame *data.Frame) {
 	f.frames = append(f.frames, frame)
 }
@@ -57,7 +65,11 @@ func (f *Framer) Frames() (data.Frames, error) {
 	labelNames := f.labelTracker.GetNames()
 	exemplarLabels := make(map[string]string, len(labelNames))
 	for _, labelName := range labelNames {
-		exemplarFrame.Fields = append(exemplarFrame.Fields, data.NewField(labelName, nil, make([]string, 0, len(exemplars))))
+		labelField := data.NewField(labelName, nil, make([]string, 0, len(exemplars)))
+		if links, ok := f.links[labelName]; ok {
+			labelField.Config = &data.FieldConfig{Links: links}
+		}
+		exemplarFrame.Fields = append(exemplarFrame.Fields, labelField)
 	}
 
 	// add the sampled exemplars to the new exemplar frame
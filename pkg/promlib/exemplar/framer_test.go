@@ -0,0 +1,62 @@
+package exemplar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/exemplar"
+)
+
+func TestFramer(t *testing.T) {
+	t.Run("should add the links and the metadata to the exemplar frame", func(t *testing.T) {
+		sampler := exemplar.NewStandardDeviationSampler()
+		sampler.SetStep(time.Minute)
+		labelTracker := exemplar.NewLabelTracker()
+		framer := exemplar.NewFramer(sampler, labelTracker)
+		framer.SetRefID("A")
+		framer.SetMeta(exemplar.NewAnnotationsMeta())
+		links := []data.DataLink{{Title: "Open the trace", URL: "/explore?trace=${__value.raw}"}}
+		framer.SetLinks("traceID", links)
+
+		series := data.NewFrame("series")
+		framer.AddFrame(series)
+		for i, traceID := range []string{"abc", "def"} {
+			labels := map[string]string{"job": "api", "traceID": traceID}
+			labelTracker.Add(labels)
+			sampler.Add(exemplar.Exemplar{
+				SeriesLabels: labels,
+				Value:        float64(i),
+				Timestamp:    time.Unix(int64(i)*60, 0),
+			})
+		}
+
+		frames, err := framer.Frames()
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		require.Equal(t, series, frames[0])
+
+		exemplarFrame := frames[1]
+		require.Equal(t, "exemplar", exemplarFrame.Name)
+		require.Equal(t, "A", exemplarFrame.RefID)
+		require.Equal(t, data.DataTopicAnnotations, exemplarFrame.Meta.DataTopic)
+		require.Len(t, exemplarFrame.Fields, 4)
+		require.Equal(t, "job", exemplarFrame.Fields[2].Name)
+		require.Nil(t, exemplarFrame.Fields[2].Config)
+		require.Equal(t, "traceID", exemplarFrame.Fields[3].Name)
+		require.Equal(t, links, exemplarFrame.Fields[3].Config.Links)
+		require.Equal(t, "abc", exemplarFrame.Fields[3].At(0))
+		require.Equal(t, "def", exemplarFrame.Fields[3].At(1))
+	})
+
+	t.Run("should only return the frames without exemplars", func(t *testing.T) {
+		framer := exemplar.NewFramer(exemplar.NewStandardDeviationSampler(), exemplar.NewLabelTracker())
+		framer.AddFrame(data.NewFrame("series"))
+
+		frames, err := framer.Frames()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+	})
+}
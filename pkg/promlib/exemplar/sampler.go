@@ -3,30 +3,28 @@ package exemplar
 import (
 	"sort"
 	"time"
-
-	"github.com/grafana/grafana/pkg/promlib/models"
 )
 
 type Sampler interface {
-	Add(models.Exemplar)
+	Add(Exemplar)
 	SetStep(time.Duration)
-	Sample() []models.Exemplar
+	Sample() []Exemplar
 	Reset()
 }
 
 var _ Sampler = (*NoOpSampler)(nil)
 
 type NoOpSampler struct {
-	exemplars []models.Exemplar
+	exemplars []Exemplar
 }
 
 func NewNoOpSampler() Sampler {
 	return &NoOpSampler{
-		exemplars: []models.Exemplar{},
+		exemplars: []Exemplar{},
 	}
 }
 
-func (e *NoOpSampler) Add(ex models.Exemplar) {
+func (e *NoOpSampler) Add(ex Exemplar) {
 	e.exemplars = append(e.exemplars, ex)
 }
 
@@ -34,7 +32,7 @@ func (e *NoOpSampler) SetStep(time.Duration) {
 	// noop
 }
 
-func (e *NoOpSampler) Sample() []models.Exemplar {
+func (e *NoOpSampler) Sample() []Exemplar {
 	sort.SliceStable(e.exemplars, func(i, j int) bool {
 		return e.exemplars[i].Timestamp.Before(e.exemplars[j].Timestamp)
 	})
@@ -42,5 +40,5 @@ func (e *NoOpSampler) Sample() []models.Exemplar {
 }
 
 func (e *NoOpSampler) Reset() {
-	e.exemplars = []models.Exemplar{}
+	e.exemplars = []Exemplar{}
 }
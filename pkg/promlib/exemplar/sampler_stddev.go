@@ -4,13 +4,11 @@ import (
 	"math"
 	"sort"
 	"time"
-
-	"github.com/grafana/grafana/pkg/promlib/models"
 )
 
 type StandardDeviationSampler struct {
 	step    time.Duration
-	buckets map[time.Time][]models.Exemplar
+	buckets map[time.Time][]Exemplar
 	count   int
 	mean    float64
 	m2      float64
@@ -18,7 +16,7 @@ type StandardDeviationSampler struct {
 
 func NewStandardDeviationSampler() Sampler {
 	return &StandardDeviationSampler{
-		buckets: map[time.Time][]models.Exemplar{},
+		buckets: map[time.Time][]Exemplar{},
 	}
 }
 
@@ -26,12 +24,12 @@ func (e *StandardDeviationSampler) SetStep(step time.Duration) {
 	e.step = step
 }
 
-func (e *StandardDeviationSampler) Add(ex models.Exemplar) {
-	bucketTs := models.AlignTimeRange(ex.Timestamp, e.step, 0)
+func (e *StandardDeviationSampler) Add(ex Exemplar) {
+	bucketTs := alignTimestamp(ex.Timestamp, e.step)
 	e.updateAggregations(ex.Value)
 
 	if _, exists := e.buckets[bucketTs]; !exists {
-		e.buckets[bucketTs] = []models.Exemplar{ex}
+		e.buckets[bucketTs] = []Exemplar{ex}
 		return
 	}
 
@@ -57,14 +55,14 @@ func (e *StandardDeviationSampler) standardDeviation() float64 {
 	return math.Sqrt(e.m2 / float64(e.count-1))
 }
 
-func (e *StandardDeviationSampler) Sample() []models.Exemplar {
-	exemplars := make([]models.Exemplar, 0, len(e.buckets))
+func (e *StandardDeviationSampler) Sample() []Exemplar {
+	exemplars := make([]Exemplar, 0, len(e.buckets))
 	for _, b := range e.buckets {
 		// sort by value in descending order
 		sort.SliceStable(b, func(i, j int) bool {
 			return b[i].Value > b[j].Value
 		})
-		sampled := []models.Exemplar{}
+		sampled := []Exemplar{}
 		for _, ex := range b {
 			if len(sampled) == 0 {
 				sampled = append(sampled, ex)
@@ -86,7 +84,7 @@ func (e *StandardDeviationSampler) Sample() []models.Exemplar {
 
 func (e *StandardDeviationSampler) Reset() {
 	e.step = 0
-	e.buckets = map[time.Time][]models.Exemplar{}
+	e.buckets = map[time.Time][]Exemplar{}
 	e.count = 0
 	e.mean = 0
 	e.m2 = 0
@@ -6,8 +6,8 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 
+	"github.com/grafana/grafana/pkg/promlib/exemplar"
 	"github.com/grafana/grafana/pkg/promlib/models"
-	"github.com/grafana/grafana/pkg/promlib/querydata/exemplar"
 )
 
 const update = true
@@ -28,14 +28,14 @@ func TestNoOpSampler(t *testing.T) {
 	})
 }
 
-func generateTestExemplars(tr models.TimeRange) []models.Exemplar {
-	exemplars := []models.Exemplar{}
+func generateTestExemplars(tr models.TimeRange) []exemplar.Exemplar {
+	exemplars := []exemplar.Exemplar{}
 	next := tr.Start.UTC()
 	for {
 		if next.Equal(tr.End) || next.After(tr.End) {
 			break
 		}
-		exemplars = append(exemplars, models.Exemplar{
+		exemplars = append(exemplars, exemplar.Exemplar{
 			Timestamp: next,
 			Value:     float64(next.Unix()),
 		})
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// variablePlaceholders replace the interval and range variables while the query is parsed, as they are only
+// interpolated when the query is executed. Durations and numbers are printed back exactly as they are written here.
+var variablePlaceholders = []struct {
+	variable    string
+	placeholder string
+}{
+	// variables that are a prefix of another variable have to be replaced last
+	{varIntervalMs, "0.1234567891"},
+	{varRangeMs, "0.1234567892"},
+	{varRangeS, "0.1234567893"},
+	{varRateIntervalMs, "0.1234567894"},
+	{varInterval, "1y1w1d1h1m1s1ms"},
+	{varRange, "1y1w1d1h1m1s2ms"},
+	{varRateInterval, "1y1w1d1h1m1s3ms"},
+	{varIntervalMsAlt, "0.1234567895"},
+	{varRangeMsAlt, "0.1234567896"},
+	{varRangeSAlt, "0.1234567897"},
+	{varRateIntervalMsAlt, "0.1234567898"},
+	{varIntervalAlt, "1y1w1d1h1m1s4ms"},
+	{varRangeAlt, "1y1w1d1h1m1s5ms"},
+	{varRateIntervalAlt, "1y1w1d1h1m1s6ms"},
+}
+
+// ParseLabelMatcher parses a single label matcher, e.g. namespace=~"payments-.*".
+func ParseLabelMatcher(rawMatcher string) (*labels.Matcher, error) {
+	matchers, err := parser.ParseMetricSelector("{" + rawMatcher + "}")
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) != 1 {
+		return nil, fmt.Errorf("expected a single label matcher, got %d", len(matchers))
+	}
+	return matchers[0], nil
+}
+
+// InjectLabelMatchers adds the matchers to every selector of the query. Unlike ApplyQueryFilters it never replaces
+// the matchers of the query, so the series returned by the query always match the injected matchers.
+func InjectLabelMatchers(rawExpr string, matchers []*labels.Matcher) (string, error) {
+	if len(matchers) == 0 {
+		return rawExpr, nil
+	}
+
+	for _, p := range variablePlaceholders {
+		if strings.Contains(rawExpr, p.placeholder) {
+			return "", fmt.Errorf("query contains the reserved value %q", p.placeholder)
+		}
+		rawExpr = strings.ReplaceAll(rawExpr, p.variable, p.placeholder)
+	}
+
+	expr, err := parser.ParseExpr(rawExpr)
+	if err != nil {
+		return "", err
+	}
+
+	parser.Inspect(expr, func(node parser.Node, nodes []parser.Node) error {
+		if v, ok := node.(*parser.VectorSelector); ok {
+			v.LabelMatchers = append(v.LabelMatchers, matchers...)
+		}
+		return nil
+	})
+
+	result := expr.String()
+	for _, p := range variablePlaceholders {
+		result = strings.ReplaceAll(result, p.placeholder, p.variable)
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectLabelMatchers(t *testing.T) {
+	matchers := []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchRegexp, "namespace", "payments-.*"),
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Selector without matchers",
+			query:    `http_requests_total`,
+			expected: `http_requests_total{namespace=~"payments-.*"}`,
+		},
+		{
+			name:     "Selector with a matcher on the same label",
+			query:    `http_requests_total{namespace="billing"}`,
+			expected: `http_requests_total{namespace="billing",namespace=~"payments-.*"}`,
+		},
+		{
+			name:     "Selector with a negative matcher on the same label",
+			query:    `http_requests_total{namespace!~"payments-.*"}`,
+			expected: `http_requests_total{namespace!~"payments-.*",namespace=~"payments-.*"}`,
+		},
+		{
+			name:     "Selector by metric name label",
+			query:    `{__name__=~".+"}`,
+			expected: `{__name__=~".+",namespace=~"payments-.*"}`,
+		},
+		{
+			name:     "Binary expression and subquery",
+			query:    `sum(rate(http_requests_total[5m])) / max_over_time(up[1h:1m])`,
+			expected: `sum(rate(http_requests_total{namespace=~"payments-.*"}[5m])) / max_over_time(up{namespace=~"payments-.*"}[1h:1m])`,
+		},
+		{
+			name:     "Interval and range variables",
+			query:    `rate(http_requests_total[$__rate_interval]) * $__range_s / ${__interval_ms}`,
+			expected: `rate(http_requests_total{namespace=~"payments-.*"}[$__rate_interval]) * $__range_s / ${__interval_ms}`,
+		},
+		{
+			name:     "Query without selectors",
+			query:    `vector(1)`,
+			expected: `vector(1)`,
+		},
+		{
+			name:      "Invalid query",
+			query:     `http_requests_total{namespace="billing"} or {`,
+			expectErr: true,
+		},
+		{
+			name:      "Query with a reserved value",
+			query:     `http_requests_total offset 1y1w1d1h1m1s1ms`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := InjectLabelMatchers(tt.query, matchers)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, expr)
+		})
+	}
+}
+
+func TestParseLabelMatcher(t *testing.T) {
+	matcher, err := ParseLabelMatcher(`namespace=~"payments-.*"`)
+	require.NoError(t, err)
+	require.Equal(t, labels.MatchRegexp, matcher.Type)
+	require.Equal(t, "namespace", matcher.Name)
+	require.Equal(t, "payments-.*", matcher.Value)
+
+	_, err = ParseLabelMatcher(`namespace="a", cluster="b"`)
+	require.Error(t, err)
+
+	_, err = ParseLabelMatcher(`namespace="a"} or up{`)
+	require.Error(t, err)
+
+	_, err = ParseLabelMatcher(`namespace`)
+	require.Error(t, err)
+}
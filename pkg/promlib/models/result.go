@@ -1,8 +1,6 @@
 package models
 
 import (
-	"time"
-
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
@@ -45,11 +43,3 @@ func ResultTypeFromFrame(frame *data.Frame) ResultType {
 func (r ResultType) String() string {
 	return string(r)
 }
-
-type Exemplar struct {
-	SeriesLabels map[string]string
-	Fields       []*data.Field
-	RowIdx       int
-	Value        float64
-	Timestamp    time.Time
-}
@@ -13,8 +13,8 @@ import (
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/grafana/grafana/pkg/promlib/converter"
+	"github.com/grafana/grafana/pkg/promlib/exemplar"
 	"github.com/grafana/grafana/pkg/promlib/models"
-	"github.com/grafana/grafana/pkg/promlib/querydata/exemplar"
 	"github.com/grafana/grafana/pkg/promlib/utils"
 )
 
@@ -87,7 +87,7 @@ func (s *QueryData) processExemplars(ctx context.Context, q *models.Query, dr ba
 		for rowIdx := 0; rowIdx < frame.Fields[0].Len(); rowIdx++ {
 			ts := frame.CopyAt(0, rowIdx).(time.Time)
 			val := frame.CopyAt(1, rowIdx).(float64)
-			ex := models.Exemplar{
+			ex := exemplar.Exemplar{
 				RowIdx:       rowIdx,
 				Fields:       frame.Fields[2:],
 				Value:        val,
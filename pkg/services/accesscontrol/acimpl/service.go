@@ -43,7 +43,7 @@ var SharedWithMeFolderPermission = accesscontrol.Permission{
 	Scope:  dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.SharedWithMeFolderUID),
 }
 
-var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix}
+var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix, accesscontrol.CustomRolePrefix}
 
 func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
 	accessControl accesscontrol.AccessControl, features featuremgmt.FeatureToggles, tracer tracing.Tracer) (*Service, error) {
@@ -379,6 +379,24 @@ func (s *Service) RegisterFixedRoles(ctx context.Context) error {
 	return nil
 }
 
+// RegisteredActions returns the actions of the basic roles and of the declared roles, custom roles can only be made
+// of these actions.
+func (s *Service) RegisteredActions() map[string]bool {
+	actions := map[string]bool{}
+	for _, role := range s.roles {
+		for _, p := range role.Permissions {
+			actions[p.Action] = true
+		}
+	}
+	s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+		for _, p := range registration.Role.Permissions {
+			actions[p.Action] = true
+		}
+		return true
+	})
+	return actions
+}
+
 // DeclarePluginRoles allow the caller to declare, to the service, plugin roles and their assignments
 // to organization roles ("Viewer", "Editor", "Admin") or "Grafana Admin"
 func (s *Service) DeclarePluginRoles(ctx context.Context, ID, name string, regs []plugins.RoleRegistration) error {
@@ -0,0 +1,94 @@
+package customroles
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	ActionRead   = "roles:read"
+	ActionWrite  = "roles:write"
+	ActionDelete = "roles:delete"
+)
+
+var (
+	ScopeAll      = "roles:*"
+	ScopeProvider = accesscontrol.NewScopeProvider("roles")
+)
+
+const invalidRoleMessage = "Invalid role: {{ .Public.reason }}"
+
+var (
+	ErrRoleNotFound     = errutil.NotFound("customroles.notFound", errutil.WithPublicMessage("Role not found"))
+	ErrRoleNameTaken    = errutil.Conflict("customroles.nameTaken", errutil.WithPublicMessage("A role with the same name already exists in the organization"))
+	ErrRoleAssigned     = errutil.BadRequest("customroles.assigned", errutil.WithPublicMessage("Role is assigned, remove its assignments or delete it with force"))
+	ErrAssigneeNotFound = errutil.NotFound("customroles.assigneeNotFound", errutil.WithPublicMessage("User, team or service account not found in the organization"))
+	ErrEscalation       = errutil.Forbidden("customroles.escalation", errutil.WithPublicMessage("You cannot grant permissions you don't have"))
+	ErrInvalidRole      = errutil.BadRequest("customroles.invalid").
+				MustTemplate(invalidRoleMessage, errutil.WithPublic(invalidRoleMessage))
+)
+
+// ErrInvalidRoleReason returns an ErrInvalidRole error explaining why the role is invalid.
+func ErrInvalidRoleReason(reason string) error {
+	return ErrInvalidRole.Build(errutil.TemplateData{Public: map[string]any{"reason": reason}})
+}
+
+// Service manages the roles the organizations define with any of the actions registered by Grafana, and their
+// assignments to users, teams and service accounts.
+type Service interface {
+	GetRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error)
+	GetRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error)
+	CreateRole(ctx context.Context, cmd *CreateRoleCommand) (*accesscontrol.RoleDTO, error)
+	UpdateRole(ctx context.Context, cmd *UpdateRoleCommand) (*accesscontrol.RoleDTO, error)
+	// DeleteRole deletes the role, it fails with ErrRoleAssigned when the role is assigned unless force is true.
+	DeleteRole(ctx context.Context, orgID int64, uid string, force bool) error
+
+	GetAssignments(ctx context.Context, orgID int64, uid string) (*RoleAssignments, error)
+	AddAssignment(ctx context.Context, orgID int64, uid string, assignee Assignee) error
+	RemoveAssignment(ctx context.Context, orgID int64, uid string, assignee Assignee) error
+}
+
+type CreateRoleCommand struct {
+	OrgID int64 `json:"-"`
+	// UID is generated when empty.
+	UID string `json:"uid"`
+	// Name must be prefixed with custom:, for example custom:folder-x:editor.
+	Name        string                     `json:"name"`
+	DisplayName string                     `json:"displayName"`
+	Description string                     `json:"description"`
+	Group       string                     `json:"group"`
+	Permissions []accesscontrol.Permission `json:"permissions"`
+}
+
+type UpdateRoleCommand struct {
+	OrgID       int64                      `json:"-"`
+	UID         string                     `json:"-"`
+	Name        string                     `json:"name"`
+	DisplayName string                     `json:"displayName"`
+	Description string                     `json:"description"`
+	Group       string                     `json:"group"`
+	Permissions []accesscontrol.Permission `json:"permissions"`
+}
+
+type AssigneeKind string
+
+const (
+	AssigneeUser           AssigneeKind = "user"
+	AssigneeTeam           AssigneeKind = "team"
+	AssigneeServiceAccount AssigneeKind = "serviceAccount"
+)
+
+// Assignee is a user, team or service account a role is assigned to.
+type Assignee struct {
+	Kind AssigneeKind
+	ID   int64
+}
+
+type RoleAssignments struct {
+	RoleUID         string  `json:"roleUid"`
+	Users           []int64 `json:"users"`
+	Teams           []int64 `json:"teams"`
+	ServiceAccounts []int64 `json:"serviceAccounts"`
+}
@@ -0,0 +1,180 @@
+package customrolesimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := customroles.ScopeProvider.GetResourceScopeUID(ac.Parameter(":roleUID"))
+
+	s.routeRegister.Group("/api/access-control/custom-roles", func(r routing.RouteRegister) {
+		r.Get("/", authorize(ac.EvalPermission(customroles.ActionRead)), routing.Wrap(s.listRolesHandler))
+		r.Post("/", authorize(ac.EvalPermission(customroles.ActionWrite)), routing.Wrap(s.createRoleHandler))
+		r.Get("/:roleUID", authorize(ac.EvalPermission(customroles.ActionRead, uidScope)), routing.Wrap(s.getRoleHandler))
+		r.Put("/:roleUID", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.updateRoleHandler))
+		r.Delete("/:roleUID", authorize(ac.EvalPermission(customroles.ActionDelete, uidScope)), routing.Wrap(s.deleteRoleHandler))
+
+		r.Get("/:roleUID/assignments", authorize(ac.EvalPermission(customroles.ActionRead, uidScope)), routing.Wrap(s.getAssignmentsHandler))
+		r.Put("/:roleUID/assignments/users/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeUser, true)))
+		r.Delete("/:roleUID/assignments/users/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeUser, false)))
+		r.Put("/:roleUID/assignments/teams/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeTeam, true)))
+		r.Delete("/:roleUID/assignments/teams/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeTeam, false)))
+		r.Put("/:roleUID/assignments/service-accounts/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeServiceAccount, true)))
+		r.Delete("/:roleUID/assignments/service-accounts/:id", authorize(ac.EvalPermission(customroles.ActionWrite, uidScope)), routing.Wrap(s.assignmentHandler(customroles.AssigneeServiceAccount, false)))
+	}, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+// GET /api/access-control/custom-roles
+func (s *Service) listRolesHandler(c *contextmodel.ReqContext) response.Response {
+	roles, err := s.GetRoles(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list roles", err)
+	}
+
+	// Only return the roles the signed in user can read
+	filtered := make([]*ac.RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(customroles.ActionRead, customroles.ScopeProvider.GetResourceScopeUID(role.UID)))
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list roles", err)
+		}
+		if ok {
+			filtered = append(filtered, role)
+		}
+	}
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// GET /api/access-control/custom-roles/:roleUID
+func (s *Service) getRoleHandler(c *contextmodel.ReqContext) response.Response {
+	role, err := s.GetRole(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// POST /api/access-control/custom-roles
+func (s *Service) createRoleHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := customroles.CreateRoleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	if err := s.checkCanGrant(c, cmd.Permissions); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create role", err)
+	}
+
+	role, err := s.CreateRole(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create role", err)
+	}
+	return response.JSON(http.StatusCreated, role)
+}
+
+// PUT /api/access-control/custom-roles/:roleUID
+func (s *Service) updateRoleHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := customroles.UpdateRoleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":roleUID"]
+
+	if err := s.checkCanGrant(c, cmd.Permissions); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update role", err)
+	}
+
+	role, err := s.UpdateRole(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// DELETE /api/access-control/custom-roles/:roleUID
+func (s *Service) deleteRoleHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteRole(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"], c.QueryBool("force"))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete role", err)
+	}
+	return response.Success("Role deleted")
+}
+
+// GET /api/access-control/custom-roles/:roleUID/assignments
+func (s *Service) getAssignmentsHandler(c *contextmodel.ReqContext) response.Response {
+	assignments, err := s.GetAssignments(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role assignments", err)
+	}
+	return response.JSON(http.StatusOK, assignments)
+}
+
+// PUT and DELETE /api/access-control/custom-roles/:roleUID/assignments/{users,teams,service-accounts}/:id
+func (s *Service) assignmentHandler(kind customroles.AssigneeKind, add bool) func(c *contextmodel.ReqContext) response.Response {
+	return func(c *contextmodel.ReqContext) response.Response {
+		id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "id is invalid", err)
+		}
+		assignee := customroles.Assignee{Kind: kind, ID: id}
+		orgID := c.SignedInUser.GetOrgID()
+		uid := web.Params(c.Req)[":roleUID"]
+
+		if !add {
+			if err := s.RemoveAssignment(c.Req.Context(), orgID, uid, assignee); err != nil {
+				return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove role assignment", err)
+			}
+			return response.Success("Role assignment removed")
+		}
+
+		role, err := s.GetRole(c.Req.Context(), orgID, uid)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to assign role", err)
+		}
+		if err := s.checkCanGrant(c, role.Permissions); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to assign role", err)
+		}
+		if err := s.AddAssignment(c.Req.Context(), orgID, uid, assignee); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to assign role", err)
+		}
+		return response.Success("Role assigned")
+	}
+}
+
+// checkCanGrant prevents privilege escalation: the signed in user can only create, update or assign a role made of
+// permissions they have.
+func (s *Service) checkCanGrant(c *contextmodel.ReqContext, permissions []ac.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	evaluators := make([]ac.Evaluator, 0, len(permissions))
+	for _, p := range permissions {
+		if p.Scope == "" {
+			evaluators = append(evaluators, ac.EvalPermission(p.Action))
+		} else {
+			evaluators = append(evaluators, ac.EvalPermission(p.Action, p.Scope))
+		}
+	}
+
+	ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalAll(evaluators...))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return customroles.ErrEscalation.Errorf("user %s cannot grant permissions they don't have", c.SignedInUser.GetID())
+	}
+	return nil
+}
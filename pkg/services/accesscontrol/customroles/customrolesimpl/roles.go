@@ -0,0 +1,38 @@
+package customrolesimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:roles:reader",
+			DisplayName: "Custom roles reader",
+			Description: "Read the custom roles of the organization and their assignments.",
+			Group:       "Access control",
+			Permissions: []accesscontrol.Permission{
+				{Action: customroles.ActionRead, Scope: customroles.ScopeAll},
+			},
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:roles:writer",
+			DisplayName: "Custom roles writer",
+			Description: "Create, update, delete and assign the custom roles of the organization.",
+			Group:       "Access control",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: customroles.ActionWrite, Scope: customroles.ScopeAll},
+				{Action: customroles.ActionDelete, Scope: customroles.ScopeAll},
+			}),
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reader, writer)
+}
@@ -0,0 +1,311 @@
+package customrolesimpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// maxNameLength is the length of the name column of the role table.
+const maxNameLength = 190
+
+var _ customroles.Service = (*Service)(nil)
+
+// actionRegistry knows the actions registered by the basic and fixed roles.
+type actionRegistry interface {
+	RegisteredActions() map[string]bool
+}
+
+type Service struct {
+	store         *store
+	actions       actionRegistry
+	accessControl accesscontrol.AccessControl
+	cache         *localcache.CacheService
+	routeRegister routing.RouteRegister
+	log           log.Logger
+}
+
+func ProvideService(db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	acService *acimpl.Service, cache *localcache.CacheService) (*Service, error) {
+	s := &Service{
+		store:         &store{db: db},
+		actions:       acService,
+		accessControl: accessControl,
+		cache:         cache,
+		routeRegister: routeRegister,
+		log:           log.New("accesscontrol.customroles"),
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) GetRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	return s.store.getRoles(ctx, orgID)
+}
+
+func (s *Service) GetRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	return s.store.getRole(ctx, orgID, uid)
+}
+
+func (s *Service) CreateRole(ctx context.Context, cmd *customroles.CreateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(cmd.UID) || util.IsShortUIDTooLong(cmd.UID) {
+		return nil, customroles.ErrInvalidRoleReason(fmt.Sprintf("invalid uid %q", cmd.UID))
+	}
+
+	permissions, err := s.validate(cmd.Name, cmd.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, cmd.OrgID, cmd.Name, cmd.UID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &accesscontrol.Role{
+		OrgID:       cmd.OrgID,
+		Version:     1,
+		UID:         cmd.UID,
+		Name:        cmd.Name,
+		DisplayName: cmd.DisplayName,
+		Description: cmd.Description,
+		Group:       cmd.Group,
+		Created:     now,
+		Updated:     now,
+	}
+	if err := s.store.createRole(ctx, role, permissions); err != nil {
+		return nil, err
+	}
+
+	return s.store.getRole(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *Service) UpdateRole(ctx context.Context, cmd *customroles.UpdateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	existing, err := s.store.getRole(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.validate(cmd.Name, cmd.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, cmd.OrgID, cmd.Name, cmd.UID); err != nil {
+		return nil, err
+	}
+
+	role := existing.Role()
+	role.Version++
+	role.Name = cmd.Name
+	role.DisplayName = cmd.DisplayName
+	role.Description = cmd.Description
+	role.Group = cmd.Group
+	role.Updated = time.Now()
+	if err := s.store.updateRole(ctx, &role, permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.clearAssigneesCache(ctx, cmd.OrgID, role.ID); err != nil {
+		s.log.Warn("Failed to clear the permission cache of the role assignees", "role", role.UID, "error", err)
+	}
+
+	return s.store.getRole(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *Service) DeleteRole(ctx context.Context, orgID int64, uid string, force bool) error {
+	role, err := s.store.getRole(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := s.store.getAssignments(ctx, orgID, role.ID)
+	if err != nil {
+		return err
+	}
+	if !force && len(assignments.Users)+len(assignments.Teams)+len(assignments.ServiceAccounts) > 0 {
+		return customroles.ErrRoleAssigned.Errorf("role %s is assigned", uid)
+	}
+
+	if err := s.store.deleteRole(ctx, role.ID); err != nil {
+		return err
+	}
+
+	s.clearCache(orgID, assignments)
+	return nil
+}
+
+func (s *Service) GetAssignments(ctx context.Context, orgID int64, uid string) (*customroles.RoleAssignments, error) {
+	role, err := s.store.getRole(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.store.getAssignments(ctx, orgID, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	assignments.RoleUID = role.UID
+	return assignments, nil
+}
+
+func (s *Service) AddAssignment(ctx context.Context, orgID int64, uid string, assignee customroles.Assignee) error {
+	role, err := s.store.getRole(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAssignee(ctx, orgID, assignee); err != nil {
+		return err
+	}
+
+	if assignee.Kind == customroles.AssigneeTeam {
+		err = s.store.addTeamAssignment(ctx, orgID, role.ID, assignee.ID)
+	} else {
+		err = s.store.addUserAssignment(ctx, orgID, role.ID, assignee.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.clearAssigneeCache(orgID, assignee)
+	return nil
+}
+
+func (s *Service) RemoveAssignment(ctx context.Context, orgID int64, uid string, assignee customroles.Assignee) error {
+	role, err := s.store.getRole(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	if assignee.Kind == customroles.AssigneeTeam {
+		err = s.store.removeTeamAssignment(ctx, orgID, role.ID, assignee.ID)
+	} else {
+		err = s.store.removeUserAssignment(ctx, orgID, role.ID, assignee.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.clearAssigneeCache(orgID, assignee)
+	return nil
+}
+
+// validate checks the name of the role and that its permissions are made of registered actions and valid scopes. It
+// returns the permissions without duplicates.
+func (s *Service) validate(name string, permissions []accesscontrol.Permission) ([]accesscontrol.Permission, error) {
+	if !strings.HasPrefix(name, accesscontrol.CustomRolePrefix) || len(name) == len(accesscontrol.CustomRolePrefix) {
+		return nil, customroles.ErrInvalidRoleReason(fmt.Sprintf("name must be prefixed with %q", accesscontrol.CustomRolePrefix))
+	}
+	if len(name) > maxNameLength {
+		return nil, customroles.ErrInvalidRoleReason(fmt.Sprintf("name cannot be longer than %d characters", maxNameLength))
+	}
+
+	registered := s.actions.RegisteredActions()
+	type key struct{ action, scope string }
+	seen := map[key]bool{}
+	result := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		if !registered[p.Action] {
+			return nil, customroles.ErrInvalidRoleReason(fmt.Sprintf("unknown action %q", p.Action))
+		}
+		if p.Scope != "" && !accesscontrol.ValidateScope(p.Scope) {
+			return nil, customroles.ErrInvalidRoleReason(fmt.Sprintf("invalid scope %q", p.Scope))
+		}
+
+		k := key{p.Action, p.Scope}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+	}
+	return result, nil
+}
+
+func (s *Service) checkNameAvailable(ctx context.Context, orgID int64, name, uid string) error {
+	exists, err := s.store.roleNameExists(ctx, orgID, name, uid)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return customroles.ErrRoleNameTaken.Errorf("role %q already exists in organization %d", name, orgID)
+	}
+	return nil
+}
+
+// checkAssignee checks that the assignee is a member of the organization, and that users and service accounts are not
+// mixed up.
+func (s *Service) checkAssignee(ctx context.Context, orgID int64, assignee customroles.Assignee) error {
+	switch assignee.Kind {
+	case customroles.AssigneeTeam:
+		exists, err := s.store.teamExists(ctx, orgID, assignee.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return customroles.ErrAssigneeNotFound.Errorf("team %d not found in organization %d", assignee.ID, orgID)
+		}
+		return nil
+	case customroles.AssigneeUser, customroles.AssigneeServiceAccount:
+		isServiceAccount, err := s.store.isServiceAccount(ctx, orgID, assignee.ID)
+		if err != nil {
+			return err
+		}
+		if isServiceAccount != (assignee.Kind == customroles.AssigneeServiceAccount) {
+			return customroles.ErrAssigneeNotFound.Errorf("%s %d not found in organization %d", assignee.Kind, assignee.ID, orgID)
+		}
+		return nil
+	default:
+		return customroles.ErrAssigneeNotFound.Errorf("unknown assignee kind %q", assignee.Kind)
+	}
+}
+
+// clearAssigneesCache removes the cached permissions of the assignees of the role, so that changes to the role apply
+// right away.
+func (s *Service) clearAssigneesCache(ctx context.Context, orgID, roleID int64) error {
+	assignments, err := s.store.getAssignments(ctx, orgID, roleID)
+	if err != nil {
+		return err
+	}
+	s.clearCache(orgID, assignments)
+	return nil
+}
+
+func (s *Service) clearCache(orgID int64, assignments *customroles.RoleAssignments) {
+	for _, id := range assignments.Users {
+		s.clearAssigneeCache(orgID, customroles.Assignee{Kind: customroles.AssigneeUser, ID: id})
+	}
+	for _, id := range assignments.ServiceAccounts {
+		s.clearAssigneeCache(orgID, customroles.Assignee{Kind: customroles.AssigneeServiceAccount, ID: id})
+	}
+	for _, id := range assignments.Teams {
+		s.clearAssigneeCache(orgID, customroles.Assignee{Kind: customroles.AssigneeTeam, ID: id})
+	}
+}
+
+func (s *Service) clearAssigneeCache(orgID int64, assignee customroles.Assignee) {
+	if assignee.Kind == customroles.AssigneeTeam {
+		s.cache.Delete(accesscontrol.GetTeamPermissionCacheKey(assignee.ID, orgID))
+		return
+	}
+
+	usr := &user.SignedInUser{UserID: assignee.ID, OrgID: orgID, IsServiceAccount: assignee.Kind == customroles.AssigneeServiceAccount}
+	s.cache.Delete(accesscontrol.GetPermissionCacheKey(usr))
+	s.cache.Delete(accesscontrol.GetUserDirectPermissionCacheKey(usr))
+}
@@ -0,0 +1,168 @@
+package customrolesimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeActionRegistry map[string]bool
+
+func (f fakeActionRegistry) RegisteredActions() map[string]bool {
+	return f
+}
+
+func TestIntegrationCustomRoles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	sql, cfg := db.InitTestDBWithCfg(t)
+	cfg.AutoAssignOrg = true
+	cfg.AutoAssignOrgId = 1
+
+	teamService, err := teamimpl.ProvideService(sql, cfg, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	orgService, err := orgimpl.ProvideService(sql, cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+	_, err = orgService.GetOrCreate(ctx, "test")
+	require.NoError(t, err)
+	userService, err := userimpl.ProvideService(
+		sql, orgService, cfg, teamService, localcache.ProvideService(), tracing.InitializeTracerForTest(),
+		quotatest.New(false, nil), supportbundlestest.NewFakeBundleService(),
+	)
+	require.NoError(t, err)
+
+	usr, err := userService.Create(ctx, &user.CreateUserCommand{Login: "alice", OrgID: 1})
+	require.NoError(t, err)
+	sa, err := userService.Create(ctx, &user.CreateUserCommand{Login: "sa-ci", OrgID: 1, IsServiceAccount: true})
+	require.NoError(t, err)
+	tm, err := teamService.CreateTeam(ctx, "Platform", "", 1)
+	require.NoError(t, err)
+
+	s := &Service{
+		store:   &store{db: sql},
+		actions: fakeActionRegistry{"dashboards:read": true, "folders:read": true, "users:read": true},
+		cache:   localcache.ProvideService(),
+		log:     log.New("test"),
+	}
+
+	t.Run("Should validate the role", func(t *testing.T) {
+		_, err := s.CreateRole(ctx, &customroles.CreateRoleCommand{OrgID: 1, Name: "dashboards:reader"})
+		require.ErrorIs(t, err, customroles.ErrInvalidRole)
+
+		_, err = s.CreateRole(ctx, &customroles.CreateRoleCommand{OrgID: 1, Name: "custom:reader", Permissions: []accesscontrol.Permission{
+			{Action: "dashboards:delete", Scope: "dashboards:*"},
+		}})
+		require.ErrorIs(t, err, customroles.ErrInvalidRole)
+
+		_, err = s.CreateRole(ctx, &customroles.CreateRoleCommand{OrgID: 1, UID: "invalid uid", Name: "custom:reader"})
+		require.ErrorIs(t, err, customroles.ErrInvalidRole)
+	})
+
+	var role *accesscontrol.RoleDTO
+	t.Run("Should create the role", func(t *testing.T) {
+		role, err = s.CreateRole(ctx, &customroles.CreateRoleCommand{
+			OrgID:       1,
+			Name:        "custom:dashboards:reader",
+			DisplayName: "Dashboards reader",
+			Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:read", Scope: "dashboards:*"},
+				{Action: "dashboards:read", Scope: "dashboards:*"},
+			},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, role.UID)
+		require.Equal(t, int64(1), role.Version)
+		require.Len(t, role.Permissions, 1)
+
+		_, err = s.CreateRole(ctx, &customroles.CreateRoleCommand{OrgID: 1, Name: "custom:dashboards:reader"})
+		require.ErrorIs(t, err, customroles.ErrRoleNameTaken)
+
+		roles, err := s.GetRoles(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+
+		roles, err = s.GetRoles(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, roles)
+	})
+
+	t.Run("Should update the role", func(t *testing.T) {
+		updated, err := s.UpdateRole(ctx, &customroles.UpdateRoleCommand{
+			OrgID:       1,
+			UID:         role.UID,
+			Name:        "custom:dashboards:reader",
+			DisplayName: "Dashboards and folders reader",
+			Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:read", Scope: "dashboards:*"},
+				{Action: "folders:read", Scope: "folders:*"},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), updated.Version)
+		require.Equal(t, "Dashboards and folders reader", updated.DisplayName)
+		require.Len(t, updated.Permissions, 2)
+
+		_, err = s.UpdateRole(ctx, &customroles.UpdateRoleCommand{OrgID: 2, UID: role.UID, Name: "custom:dashboards:reader"})
+		require.ErrorIs(t, err, customroles.ErrRoleNotFound)
+	})
+
+	t.Run("Should assign the role", func(t *testing.T) {
+		require.NoError(t, s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeUser, ID: usr.ID}))
+		require.NoError(t, s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeUser, ID: usr.ID}))
+		require.NoError(t, s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeServiceAccount, ID: sa.ID}))
+		require.NoError(t, s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeTeam, ID: tm.ID}))
+
+		err := s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeServiceAccount, ID: usr.ID})
+		require.ErrorIs(t, err, customroles.ErrAssigneeNotFound)
+		err = s.AddAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeTeam, ID: tm.ID + 100})
+		require.ErrorIs(t, err, customroles.ErrAssigneeNotFound)
+
+		assignments, err := s.GetAssignments(ctx, 1, role.UID)
+		require.NoError(t, err)
+		require.Equal(t, &customroles.RoleAssignments{
+			RoleUID:         role.UID,
+			Users:           []int64{usr.ID},
+			Teams:           []int64{tm.ID},
+			ServiceAccounts: []int64{sa.ID},
+		}, assignments)
+	})
+
+	t.Run("Should unassign the role", func(t *testing.T) {
+		require.NoError(t, s.RemoveAssignment(ctx, 1, role.UID, customroles.Assignee{Kind: customroles.AssigneeServiceAccount, ID: sa.ID}))
+
+		assignments, err := s.GetAssignments(ctx, 1, role.UID)
+		require.NoError(t, err)
+		require.Empty(t, assignments.ServiceAccounts)
+	})
+
+	t.Run("Should only delete an assigned role with force", func(t *testing.T) {
+		err := s.DeleteRole(ctx, 1, role.UID, false)
+		require.ErrorIs(t, err, customroles.ErrRoleAssigned)
+
+		require.NoError(t, s.DeleteRole(ctx, 1, role.UID, true))
+		_, err = s.GetRole(ctx, 1, role.UID)
+		require.ErrorIs(t, err, customroles.ErrRoleNotFound)
+	})
+}
@@ -0,0 +1,252 @@
+package customrolesimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+)
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) getRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	roles := make([]*accesscontrol.RoleDTO, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored []accesscontrol.Role
+		if err := sess.Where("org_id = ? AND name LIKE ?", orgID, accesscontrol.CustomRolePrefix+"%").
+			Asc("name").Find(&stored); err != nil {
+			return err
+		}
+
+		for i := range stored {
+			role, err := withPermissions(sess, &stored[i])
+			if err != nil {
+				return err
+			}
+			roles = append(roles, role)
+		}
+		return nil
+	})
+	return roles, err
+}
+
+func (s *store) getRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	var role *accesscontrol.RoleDTO
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		stored, err := getRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		role, err = withPermissions(sess, stored)
+		return err
+	})
+	return role, err
+}
+
+func (s *store) roleNameExists(ctx context.Context, orgID int64, name, exceptUID string) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND name = ? AND uid <> ?", orgID, name, exceptUID).Exist(&accesscontrol.Role{})
+		return err
+	})
+	return exists, err
+}
+
+func (s *store) createRole(ctx context.Context, role *accesscontrol.Role, permissions []accesscontrol.Permission) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(role); err != nil {
+				return err
+			}
+			return insertPermissions(sess, role.ID, permissions)
+		})
+	})
+}
+
+// updateRole updates the role and replaces its permissions.
+func (s *store) updateRole(ctx context.Context, role *accesscontrol.Role, permissions []accesscontrol.Permission) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.ID(role.ID).Cols("name", "display_name", "description", "group_name", "version", "updated").
+				Update(role); err != nil {
+				return err
+			}
+			if _, err := sess.Exec("DELETE FROM permission WHERE role_id = ?", role.ID); err != nil {
+				return err
+			}
+			return insertPermissions(sess, role.ID, permissions)
+		})
+	})
+}
+
+// deleteRole deletes the role, its permissions and its assignments.
+func (s *store) deleteRole(ctx context.Context, roleID int64) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			for _, query := range []string{
+				"DELETE FROM user_role WHERE role_id = ?",
+				"DELETE FROM team_role WHERE role_id = ?",
+				"DELETE FROM permission WHERE role_id = ?",
+				"DELETE FROM role WHERE id = ?",
+			} {
+				if _, err := sess.Exec(query, roleID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (s *store) getAssignments(ctx context.Context, orgID, roleID int64) (*customroles.RoleAssignments, error) {
+	result := &customroles.RoleAssignments{
+		Users:           make([]int64, 0),
+		Teams:           make([]int64, 0),
+		ServiceAccounts: make([]int64, 0),
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var users []struct {
+			UserID           int64 `xorm:"user_id"`
+			IsServiceAccount bool  `xorm:"is_service_account"`
+		}
+		rawSQL := "SELECT ur.user_id, u.is_service_account FROM user_role AS ur " +
+			"INNER JOIN " + s.db.GetDialect().Quote("user") + " AS u ON u.id = ur.user_id " +
+			"WHERE ur.org_id = ? AND ur.role_id = ? ORDER BY ur.user_id"
+		if err := sess.SQL(rawSQL, orgID, roleID).Find(&users); err != nil {
+			return err
+		}
+		for _, u := range users {
+			if u.IsServiceAccount {
+				result.ServiceAccounts = append(result.ServiceAccounts, u.UserID)
+			} else {
+				result.Users = append(result.Users, u.UserID)
+			}
+		}
+
+		return sess.SQL("SELECT team_id FROM team_role WHERE org_id = ? AND role_id = ? ORDER BY team_id", orgID, roleID).
+			Find(&result.Teams)
+	})
+	return result, err
+}
+
+// isServiceAccount returns whether the user is a service account, and customroles.ErrAssigneeNotFound if the user is
+// not a member of the organization.
+func (s *store) isServiceAccount(ctx context.Context, orgID, userID int64) (bool, error) {
+	var isServiceAccount []bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "SELECT u.is_service_account FROM org_user AS ou " +
+			"INNER JOIN " + s.db.GetDialect().Quote("user") + " AS u ON u.id = ou.user_id " +
+			"WHERE ou.org_id = ? AND ou.user_id = ?"
+		return sess.SQL(rawSQL, orgID, userID).Find(&isServiceAccount)
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(isServiceAccount) == 0 {
+		return false, customroles.ErrAssigneeNotFound.Errorf("user %d not found in organization %d", userID, orgID)
+	}
+	return isServiceAccount[0], nil
+}
+
+func (s *store) teamExists(ctx context.Context, orgID, teamID int64) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.SQL("SELECT 1 FROM team WHERE org_id = ? AND id = ?", orgID, teamID).Exist()
+		return err
+	})
+	return exists, err
+}
+
+func (s *store) addUserAssignment(ctx context.Context, orgID, roleID, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND role_id = ? AND user_id = ?", orgID, roleID, userID).Exist(&accesscontrol.UserRole{})
+		if err != nil || exists {
+			return err
+		}
+		_, err = sess.Insert(&accesscontrol.UserRole{OrgID: orgID, RoleID: roleID, UserID: userID, Created: time.Now()})
+		return err
+	})
+}
+
+func (s *store) removeUserAssignment(ctx context.Context, orgID, roleID, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_role WHERE org_id = ? AND role_id = ? AND user_id = ?", orgID, roleID, userID)
+		return err
+	})
+}
+
+func (s *store) addTeamAssignment(ctx context.Context, orgID, roleID, teamID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND role_id = ? AND team_id = ?", orgID, roleID, teamID).Exist(&accesscontrol.TeamRole{})
+		if err != nil || exists {
+			return err
+		}
+		_, err = sess.Insert(&accesscontrol.TeamRole{OrgID: orgID, RoleID: roleID, TeamID: teamID, Created: time.Now()})
+		return err
+	})
+}
+
+func (s *store) removeTeamAssignment(ctx context.Context, orgID, roleID, teamID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM team_role WHERE org_id = ? AND role_id = ? AND team_id = ?", orgID, roleID, teamID)
+		return err
+	})
+}
+
+func getRole(sess *db.Session, orgID int64, uid string) (*accesscontrol.Role, error) {
+	var role accesscontrol.Role
+	has, err := sess.Where("org_id = ? AND uid = ? AND name LIKE ?", orgID, uid, accesscontrol.CustomRolePrefix+"%").Get(&role)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, customroles.ErrRoleNotFound.Errorf("role %s not found in organization %d", uid, orgID)
+	}
+	return &role, nil
+}
+
+func withPermissions(sess *db.Session, role *accesscontrol.Role) (*accesscontrol.RoleDTO, error) {
+	permissions := make([]accesscontrol.Permission, 0)
+	if err := sess.Where("role_id = ?", role.ID).Asc("action", "scope").Find(&permissions); err != nil {
+		return nil, err
+	}
+
+	return &accesscontrol.RoleDTO{
+		ID:          role.ID,
+		OrgID:       role.OrgID,
+		Version:     role.Version,
+		UID:         role.UID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Group:       role.Group,
+		Permissions: permissions,
+		Created:     role.Created,
+		Updated:     role.Updated,
+	}, nil
+}
+
+func insertPermissions(sess *db.Session, roleID int64, permissions []accesscontrol.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		p.ID = 0
+		p.RoleID = roleID
+		p.Kind, p.Attribute, p.Identifier = p.SplitScope()
+		p.Created = now
+		p.Updated = now
+		rows = append(rows, p)
+	}
+	_, err := sess.InsertMulti(&rows)
+	return err
+}
@@ -16,6 +16,8 @@ const (
 	BasicRolePrefix    = "basic:"
 	BasicRoleUIDPrefix = "basic_"
 
+	CustomRolePrefix = "custom:"
+
 	ExternalServiceRolePrefix    = "extsvc:"
 	ExternalServiceRoleUIDPrefix = "extsvc_"
 
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	SinkSQL  = "sql"
+	SinkFile = "file"
+	SinkLoki = "loki"
+)
+
+var (
+	ErrQueryNotSupported = errutil.BadRequest("audit.queryNotSupported", errutil.WithPublicMessage("The audit log can only be queried when the sql sink is enabled"))
+	ErrInvalidQuery      = errutil.BadRequest("audit.invalidQuery")
+)
+
+// Service records the API calls that change something in Grafana.
+type Service interface {
+	// Middleware returns the HTTP middleware that records the mutating API calls.
+	Middleware() web.Middleware
+	// Search returns the entries of the audit log, it requires the sql sink.
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
+
+// Entry is a record of the audit log.
+type Entry struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"timestamp"`
+	OrgID   int64     `json:"orgId"`
+	// ActorID is the namespaced ID of the identity that made the call, for example user:1 or service-account:2.
+	ActorID    string `json:"actorId"`
+	ActorLogin string `json:"actorLogin"`
+	// Action is the HTTP method and route of the call, for example POST /api/dashboards/db.
+	Action       string `json:"action"`
+	ResourceKind string `json:"resourceKind"`
+	ResourceUID  string `json:"resourceUid"`
+	Result       string `json:"result"`
+	StatusCode   int    `json:"statusCode"`
+	RequestID    string `json:"requestId"`
+	RemoteAddr   string `json:"remoteAddr"`
+}
+
+type SearchQuery struct {
+	// From and To limit the entries to a time range, they are ignored when zero.
+	From         time.Time
+	To           time.Time
+	OrgID        int64
+	ActorLogin   string
+	Action       string
+	ResourceKind string
+	ResourceUID  string
+	Result       string
+	Page         int
+	Limit        int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Entries    []*Entry `json:"entries"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+type resourceKey struct{}
+
+// Resource identifies the resource changed by an API call.
+type Resource struct {
+	Kind string
+	UID  string
+}
+
+// WithResource returns a context that handlers can record the changed resource in with SetResource.
+func WithResource(ctx context.Context) (context.Context, *Resource) {
+	resource := &Resource{}
+	return context.WithValue(ctx, resourceKey{}, resource), resource
+}
+
+// SetResource records the resource changed by an API call, when it cannot be found in the route parameters, for
+// example because the resource is created by the call.
+func SetResource(ctx context.Context, kind, uid string) {
+	if resource, ok := ctx.Value(resourceKey{}).(*Resource); ok {
+		resource.Kind = kind
+		resource.UID = uid
+	}
+}
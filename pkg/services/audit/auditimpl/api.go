@@ -0,0 +1,117 @@
+package auditimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/audit"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Get("/api/admin/audit", middleware.ReqGrafanaAdmin, routing.Wrap(s.searchHandler))
+}
+
+// swagger:route GET /admin/audit admin searchAuditLog
+//
+// Search the audit log of the API calls that changed something.
+//
+// Requires the sql sink of the audit log. Results are sorted by time, most recent first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: searchAuditLogResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) searchHandler(c *contextmodel.ReqContext) response.Response {
+	query := &audit.SearchQuery{
+		OrgID:        c.QueryInt64("orgId"),
+		ActorLogin:   c.Query("actor"),
+		Action:       c.Query("action"),
+		ResourceKind: c.Query("resourceKind"),
+		ResourceUID:  c.Query("resourceUid"),
+		Result:       c.Query("result"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPerPage
+	}
+	if query.Limit > maxPerPage {
+		return response.Err(audit.ErrInvalidQuery.Errorf("perpage cannot be greater than %d", maxPerPage))
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search the audit log", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters searchAuditLog
+type SearchAuditLogParams struct {
+	// Start of the time range, in milliseconds since epoch.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// End of the time range, in milliseconds since epoch.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// Login of the user or service account that made the calls.
+	// in:query
+	// required:false
+	Actor string `json:"actor"`
+	// Part of the method and route of the calls, for example /api/dashboards.
+	// in:query
+	// required:false
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	ResourceKind string `json:"resourceKind"`
+	// in:query
+	// required:false
+	ResourceUID string `json:"resourceUid"`
+	// in:query
+	// required:false
+	// enum: success,failure
+	Result string `json:"result"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response searchAuditLogResponse
+type SearchAuditLogResponse struct {
+	// in:body
+	Body *audit.SearchResult `json:"body"`
+}
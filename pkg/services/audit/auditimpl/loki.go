@@ -0,0 +1,108 @@
+package auditimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiSink pushes the entries to Loki, with one stream per result so that failed calls are easy to find.
+type lokiSink struct {
+	client            *http.Client
+	url               string
+	tenantID          string
+	basicAuthUser     string
+	basicAuthPassword string
+}
+
+func newLokiSink(cfg setting.AuditSettings) (*lokiSink, error) {
+	if cfg.LokiURL == "" {
+		return nil, fmt.Errorf("loki_url is required by the loki audit sink")
+	}
+	u, err := url.Parse(cfg.LokiURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loki_url: %w", err)
+	}
+
+	return &lokiSink{
+		client:            &http.Client{Timeout: 10 * time.Second},
+		url:               u.JoinPath(lokiPushPath).String(),
+		tenantID:          cfg.LokiTenantID,
+		basicAuthUser:     cfg.LokiBasicAuthUser,
+		basicAuthPassword: cfg.LokiBasicAuthPassword,
+	}, nil
+}
+
+func (s *lokiSink) Name() string {
+	return audit.SinkLoki
+}
+
+func (s *lokiSink) Write(ctx context.Context, entries []*audit.Entry) error {
+	streams := map[string]*lokiStream{}
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		stream, ok := streams[e.Result]
+		if !ok {
+			stream = &lokiStream{Stream: map[string]string{"source": "grafana-audit", "result": e.Result}}
+			streams[e.Result] = stream
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.Created.UnixNano(), 10), string(line)})
+	}
+
+	push := lokiPushRequest{Streams: make([]lokiStream, 0, len(streams))}
+	for _, stream := range streams {
+		push.Streams = append(push.Streams, *stream)
+	}
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.tenantID)
+	}
+	if s.basicAuthUser != "" || s.basicAuthPassword != "" {
+		req.SetBasicAuth(s.basicAuthUser, s.basicAuthPassword)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push audit entries to Loki: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to push audit entries to Loki: status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
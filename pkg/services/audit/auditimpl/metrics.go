@@ -0,0 +1,41 @@
+package auditimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "grafana"
+
+type metrics struct {
+	entries     *prometheus.CounterVec
+	writeErrors *prometheus.CounterVec
+	dropped     prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		entries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "audit",
+			Name:      "entries_total",
+			Help:      "Number of audit entries written, by sink",
+		}, []string{"sink"}),
+		writeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "audit",
+			Name:      "write_errors_total",
+			Help:      "Number of failed writes of audit entries, by sink",
+		}, []string{"sink"}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "audit",
+			Name:      "entries_dropped_total",
+			Help:      "Number of audit entries dropped because the queue was full",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.entries, m.writeErrors, m.dropped)
+	}
+	return m
+}
@@ -0,0 +1,294 @@
+package auditimpl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	queueSize         = 1000
+	batchSize         = 100
+	enqueueTimeout    = 50 * time.Millisecond
+	flushInterval     = time.Second
+	retentionInterval = time.Hour
+)
+
+var _ audit.Service = (*Service)(nil)
+
+// sink is a destination of the audit log.
+type sink interface {
+	Name() string
+	Write(ctx context.Context, entries []*audit.Entry) error
+}
+
+type Service struct {
+	cfg     setting.AuditSettings
+	store   *sqlStore
+	sinks   []sink
+	queue   chan *audit.Entry
+	log     log.Logger
+	metrics *metrics
+	now     func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, reg prometheus.Registerer) (*Service, error) {
+	s := &Service{
+		cfg:     cfg.Audit,
+		queue:   make(chan *audit.Entry, queueSize),
+		log:     log.New("audit"),
+		metrics: newMetrics(reg),
+		now:     time.Now,
+	}
+	if !cfg.Audit.Enabled {
+		return s, nil
+	}
+
+	for _, name := range cfg.Audit.Sinks {
+		switch name {
+		case audit.SinkSQL:
+			s.store = &sqlStore{db: db}
+			s.sinks = append(s.sinks, s.store)
+		case audit.SinkFile:
+			path := cfg.Audit.FilePath
+			if path == "" {
+				path = filepath.Join(cfg.LogsPath, "audit.log")
+			}
+			fs, err := newFileSink(path)
+			if err != nil {
+				return nil, err
+			}
+			s.sinks = append(s.sinks, fs)
+		case audit.SinkLoki:
+			ls, err := newLokiSink(cfg.Audit)
+			if err != nil {
+				return nil, err
+			}
+			s.sinks = append(s.sinks, ls)
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+
+	s.registerAPIEndpoints(routeRegister)
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run writes the queued entries to the sinks in batches and removes the entries of the sql sink that are older than
+// the retention.
+func (s *Service) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	retentionTicker := time.NewTicker(retentionInterval)
+	defer retentionTicker.Stop()
+
+	batch := make([]*audit.Entry, 0, batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		s.write(ctx, batch)
+		batch = make([]*audit.Entry, 0, batchSize)
+	}
+
+	for {
+		select {
+		case e := <-s.queue:
+			batch = append(batch, e)
+			if len(batch) >= batchSize {
+				flush(ctx)
+			}
+		case <-flushTicker.C:
+			flush(ctx)
+		case <-retentionTicker.C:
+			s.deleteExpired(ctx)
+		case <-ctx.Done():
+			// Write what is left in the queue, the context is already canceled so it cannot be used for that.
+			drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case e := <-s.queue:
+					batch = append(batch, e)
+				default:
+					flush(drainCtx)
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error) {
+	if s.store == nil {
+		return nil, audit.ErrQueryNotSupported
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		if !s.cfg.Enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.shouldAudit(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, resource := audit.WithResource(r.Context())
+			*r = *r.WithContext(ctx)
+
+			rw := web.Rw(w, r)
+			next.ServeHTTP(rw, r)
+
+			s.record(s.newEntry(r, rw.Status(), resource))
+		})
+	}
+}
+
+func (s *Service) shouldAudit(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		return false
+	}
+	for _, prefix := range s.cfg.ExcludePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) newEntry(r *http.Request, status int, resource *audit.Resource) *audit.Entry {
+	// The router replaces the request of the web context with one holding the route and its parameters.
+	c := contexthandler.FromContext(r.Context())
+	req := r
+	if c != nil && c.Req != nil {
+		req = c.Req
+	}
+
+	route, ok := middleware.RouteOperationName(req)
+	if !ok || !strings.HasPrefix(route, "/") {
+		route = r.URL.Path
+	}
+
+	e := &audit.Entry{
+		Created:    s.now(),
+		Action:     r.Method + " " + route,
+		Result:     audit.ResultSuccess,
+		StatusCode: status,
+		RequestID:  r.Header.Get("X-Request-Id"),
+		RemoteAddr: web.RemoteAddr(r),
+	}
+	if status >= http.StatusBadRequest {
+		e.Result = audit.ResultFailure
+	}
+	if e.RequestID == "" {
+		e.RequestID = tracing.TraceIDFromContext(r.Context(), false)
+	}
+
+	if c != nil && c.SignedInUser != nil {
+		e.OrgID = c.SignedInUser.GetOrgID()
+		e.ActorID = c.SignedInUser.GetID().String()
+		e.ActorLogin = c.SignedInUser.GetLogin()
+	}
+
+	e.ResourceKind, e.ResourceUID = resourceFromRoute(route, web.Params(req))
+	if resource.Kind != "" {
+		e.ResourceKind = resource.Kind
+	}
+	if resource.UID != "" {
+		e.ResourceUID = resource.UID
+	}
+	return e
+}
+
+// resourceFromRoute guesses the changed resource from the route: the kind is the first part of the route after /api/
+// and the UID is the value of the first parameter, for example dashboards and abc for /api/dashboards/uid/:uid.
+func resourceFromRoute(route string, params map[string]string) (string, string) {
+	var kind, uid string
+	parts := strings.Split(strings.TrimPrefix(route, "/api/"), "/")
+	for _, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			if uid == "" {
+				uid = params[part]
+			}
+		case kind == "" && part != "" && part != "admin":
+			kind = part
+		}
+	}
+	return kind, uid
+}
+
+// record queues the entry. When the queue is full, it waits a little for the sinks to catch up and then drops the
+// entry: the sinks are never written to from the request.
+func (s *Service) record(e *audit.Entry) {
+	select {
+	case s.queue <- e:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case s.queue <- e:
+	case <-timer.C:
+		s.metrics.dropped.Inc()
+		s.log.Warn("Dropped audit entry, the queue is full", "action", e.Action, "actor", e.ActorLogin, "requestId", e.RequestID)
+	}
+}
+
+func (s *Service) write(ctx context.Context, entries []*audit.Entry) {
+	for _, sk := range s.sinks {
+		if err := sk.Write(ctx, entries); err != nil {
+			s.metrics.writeErrors.WithLabelValues(sk.Name()).Inc()
+			s.log.Error("Failed to write audit entries", "sink", sk.Name(), "count", len(entries), "error", err)
+			continue
+		}
+		s.metrics.entries.WithLabelValues(sk.Name()).Add(float64(len(entries)))
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.store == nil || s.cfg.SQLRetention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.SQLRetention))
+	if err != nil {
+		s.log.Error("Failed to delete expired audit entries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted expired audit entries", "count", deleted)
+	}
+}
@@ -0,0 +1,190 @@
+package auditimpl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeSink struct {
+	entries []*audit.Entry
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) Write(ctx context.Context, entries []*audit.Entry) error {
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func newTestService(sinks ...sink) *Service {
+	return &Service{
+		cfg: setting.AuditSettings{
+			Enabled:      true,
+			ExcludePaths: []string{"/api/ds/query"},
+		},
+		sinks:   sinks,
+		queue:   make(chan *audit.Entry, queueSize),
+		metrics: newMetrics(nil),
+		now:     func() time.Time { return time.UnixMilli(1700000000000) },
+	}
+}
+
+// handler does what the context handler and the router do before calling the API handler.
+func handler(route string, params map[string]string, status int, resource *audit.Resource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCtx := &contextmodel.ReqContext{
+			Context:      &web.Context{Req: r},
+			SignedInUser: &user.SignedInUser{UserID: 2, OrgID: 3, Login: "editor"},
+		}
+		*r = *r.WithContext(context.WithValue(r.Context(), ctxkey.Key{}, reqCtx))
+
+		if route != "" {
+			middleware.ProvideRouteOperationName(route).(func(http.ResponseWriter, *http.Request, *web.Context))(w, r, reqCtx.Context)
+		}
+		reqCtx.Req = web.SetURLParams(reqCtx.Req, params)
+
+		if resource != nil {
+			audit.SetResource(reqCtx.Req.Context(), resource.Kind, resource.UID)
+		}
+		w.WriteHeader(status)
+	})
+}
+
+func serve(s *Service, h http.Handler, method, path string) {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	s.Middleware()(h).ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestService_Middleware(t *testing.T) {
+	t.Run("should record mutating API calls", func(t *testing.T) {
+		s := newTestService()
+		serve(s, handler("/api/dashboards/uid/:uid", map[string]string{":uid": "abc"}, http.StatusOK, nil), http.MethodDelete, "/api/dashboards/uid/abc")
+
+		require.Len(t, s.queue, 1)
+		e := <-s.queue
+		assert.Equal(t, &audit.Entry{
+			Created:      time.UnixMilli(1700000000000),
+			OrgID:        3,
+			ActorID:      "user:2",
+			ActorLogin:   "editor",
+			Action:       "DELETE /api/dashboards/uid/:uid",
+			ResourceKind: "dashboards",
+			ResourceUID:  "abc",
+			Result:       audit.ResultSuccess,
+			StatusCode:   http.StatusOK,
+			RequestID:    "req-1",
+			RemoteAddr:   "192.0.2.1",
+		}, e)
+	})
+
+	t.Run("should record failed calls and the resource set by the handler", func(t *testing.T) {
+		s := newTestService()
+		serve(s, handler("/api/folders", nil, http.StatusForbidden, &audit.Resource{Kind: "folders", UID: "new"}), http.MethodPost, "/api/folders")
+
+		require.Len(t, s.queue, 1)
+		e := <-s.queue
+		assert.Equal(t, audit.ResultFailure, e.Result)
+		assert.Equal(t, http.StatusForbidden, e.StatusCode)
+		assert.Equal(t, "folders", e.ResourceKind)
+		assert.Equal(t, "new", e.ResourceUID)
+	})
+
+	t.Run("should not record reads, excluded paths and non API calls", func(t *testing.T) {
+		s := newTestService()
+		serve(s, handler("/api/dashboards/uid/:uid", nil, http.StatusOK, nil), http.MethodGet, "/api/dashboards/uid/abc")
+		serve(s, handler("/api/ds/query", nil, http.StatusOK, nil), http.MethodPost, "/api/ds/query")
+		serve(s, handler("", nil, http.StatusOK, nil), http.MethodPost, "/login")
+
+		assert.Len(t, s.queue, 0)
+	})
+
+	t.Run("should drop the entry without writing to the sinks when the queue stays full", func(t *testing.T) {
+		fs := &fakeSink{}
+		s := newTestService(fs)
+		s.queue = make(chan *audit.Entry)
+		serve(s, handler("/api/teams", nil, http.StatusOK, nil), http.MethodPost, "/api/teams")
+
+		assert.Empty(t, fs.entries)
+		assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.dropped))
+	})
+
+	t.Run("should wait for the queue to have room", func(t *testing.T) {
+		s := newTestService()
+		s.queue = make(chan *audit.Entry)
+		received := make(chan *audit.Entry, 1)
+		go func() { received <- <-s.queue }()
+		serve(s, handler("/api/teams", nil, http.StatusOK, nil), http.MethodPost, "/api/teams")
+
+		e := <-received
+		assert.Equal(t, "teams", e.ResourceKind)
+		assert.Equal(t, float64(0), testutil.ToFloat64(s.metrics.dropped))
+	})
+}
+
+func TestService_Run(t *testing.T) {
+	fs := &fakeSink{}
+	s := newTestService(fs)
+	for i := 0; i < 3; i++ {
+		s.queue <- &audit.Entry{Action: "POST /api/teams"}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Run(ctx), context.Canceled)
+	assert.Len(t, fs.entries, 3)
+}
+
+func TestResourceFromRoute(t *testing.T) {
+	tests := []struct {
+		route  string
+		params map[string]string
+		kind   string
+		uid    string
+	}{
+		{route: "/api/dashboards/db", kind: "dashboards"},
+		{route: "/api/folders/:folder_uid/permissions", params: map[string]string{":folder_uid": "f1"}, kind: "folders", uid: "f1"},
+		{route: "/api/admin/users/:id/password", params: map[string]string{":id": "7"}, kind: "users", uid: "7"},
+		{route: "/api/serviceaccounts/:serviceAccountId/tokens/:tokenId", params: map[string]string{":serviceAccountId": "1", ":tokenId": "2"}, kind: "serviceaccounts", uid: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			kind, uid := resourceFromRoute(tt.route, tt.params)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.uid, uid)
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	fs, err := newFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Write(context.Background(), []*audit.Entry{{ActorLogin: "admin"}, {ActorLogin: "editor"}}))
+	require.NoError(t, fs.Write(context.Background(), []*audit.Entry{{ActorLogin: "viewer"}}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[2], `"actorLogin":"viewer"`)
+}
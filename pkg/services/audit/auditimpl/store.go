@@ -0,0 +1,173 @@
+package auditimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+const deleteBatchSize = 1000
+
+type entryRow struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	Created      int64  `xorm:"created"`
+	OrgID        int64  `xorm:"org_id"`
+	ActorID      string `xorm:"actor_id"`
+	ActorLogin   string `xorm:"actor_login"`
+	Action       string `xorm:"action"`
+	ResourceKind string `xorm:"resource_kind"`
+	ResourceUID  string `xorm:"resource_uid"`
+	Result       string `xorm:"result"`
+	StatusCode   int    `xorm:"status_code"`
+	RequestID    string `xorm:"request_id"`
+	RemoteAddr   string `xorm:"remote_addr"`
+}
+
+func (entryRow) TableName() string { return "audit_log" }
+
+// sqlStore is the sql sink, it is the only sink the audit log can be searched in.
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Name() string {
+	return audit.SinkSQL
+}
+
+func (s *sqlStore) Write(ctx context.Context, entries []*audit.Entry) error {
+	rows := make([]*entryRow, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, &entryRow{
+			Created:      e.Created.UnixMilli(),
+			OrgID:        e.OrgID,
+			ActorID:      e.ActorID,
+			ActorLogin:   e.ActorLogin,
+			Action:       e.Action,
+			ResourceKind: e.ResourceKind,
+			ResourceUID:  e.ResourceUID,
+			Result:       e.Result,
+			StatusCode:   e.StatusCode,
+			RequestID:    e.RequestID,
+			RemoteAddr:   e.RemoteAddr,
+		})
+	}
+
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti(&rows)
+		return err
+	})
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if !query.From.IsZero() {
+		where = append(where, "created >= ?")
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "created <= ?")
+		args = append(args, query.To.UnixMilli())
+	}
+	if query.OrgID != 0 {
+		where = append(where, "org_id = ?")
+		args = append(args, query.OrgID)
+	}
+	if query.ActorLogin != "" {
+		where = append(where, "actor_login = ?")
+		args = append(args, query.ActorLogin)
+	}
+	if query.Action != "" {
+		where = append(where, "action LIKE ?")
+		args = append(args, "%"+query.Action+"%")
+	}
+	if query.ResourceKind != "" {
+		where = append(where, "resource_kind = ?")
+		args = append(args, query.ResourceKind)
+	}
+	if query.ResourceUID != "" {
+		where = append(where, "resource_uid = ?")
+		args = append(args, query.ResourceUID)
+	}
+	if query.Result != "" {
+		where = append(where, "result = ?")
+		args = append(args, query.Result)
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	result := &audit.SearchResult{
+		Entries: make([]*audit.Entry, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.SQL("SELECT COUNT(*) FROM audit_log"+whereSQL, args...).Get(&result.TotalCount); err != nil {
+			return err
+		}
+
+		rows := make([]*entryRow, 0)
+		offset := int64((query.Page - 1) * query.Limit)
+		rawSQL := "SELECT * FROM audit_log" + whereSQL + " ORDER BY created DESC, id DESC" +
+			s.db.GetDialect().LimitOffset(int64(query.Limit), offset)
+		if err := sess.SQL(rawSQL, args...).Find(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			result.Entries = append(result.Entries, &audit.Entry{
+				ID:           row.ID,
+				Created:      time.UnixMilli(row.Created),
+				OrgID:        row.OrgID,
+				ActorID:      row.ActorID,
+				ActorLogin:   row.ActorLogin,
+				Action:       row.Action,
+				ResourceKind: row.ResourceKind,
+				ResourceUID:  row.ResourceUID,
+				Result:       row.Result,
+				StatusCode:   row.StatusCode,
+				RequestID:    row.RequestID,
+				RemoteAddr:   row.RemoteAddr,
+			})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// DeleteOlderThan deletes the entries created before the given time in batches, so that a large backlog does not
+// lock the table for a long time.
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []int64
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("audit_log").Cols("id").Where("created < ?", before.UnixMilli()).
+				Limit(deleteBatchSize).Find(&ids)
+		})
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+
+		err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			res, err := sess.Table("audit_log").In("id", ids).Delete(&entryRow{})
+			total += res
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+
+		if len(ids) < deleteBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package auditimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+	setup := func(t *testing.T) *sqlStore {
+		t.Helper()
+		store := &sqlStore{db: db.InitTestDB(t)}
+		err := store.Write(context.Background(), []*audit.Entry{
+			{Created: now.Add(-3 * time.Hour), OrgID: 1, ActorID: "user:1", ActorLogin: "admin", Action: "POST /api/dashboards/db", ResourceKind: "dashboards", ResourceUID: "abc", Result: audit.ResultSuccess, StatusCode: 200},
+			{Created: now.Add(-2 * time.Hour), OrgID: 1, ActorID: "user:2", ActorLogin: "editor", Action: "DELETE /api/dashboards/uid/:uid", ResourceKind: "dashboards", ResourceUID: "abc", Result: audit.ResultFailure, StatusCode: 403},
+			{Created: now.Add(-time.Hour), OrgID: 2, ActorID: "service-account:3", ActorLogin: "sa-deploy", Action: "POST /api/datasources", ResourceKind: "datasources", ResourceUID: "prom", Result: audit.ResultSuccess, StatusCode: 200},
+		})
+		require.NoError(t, err)
+		return store
+	}
+
+	t.Run("should return the entries sorted from the most recent", func(t *testing.T) {
+		store := setup(t)
+
+		res, err := store.Search(context.Background(), &audit.SearchQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, res.TotalCount)
+		require.Len(t, res.Entries, 3)
+		assert.Equal(t, "sa-deploy", res.Entries[0].ActorLogin)
+		assert.Equal(t, "admin", res.Entries[2].ActorLogin)
+		assert.Equal(t, now.Add(-time.Hour).UnixMilli(), res.Entries[0].Created.UnixMilli())
+	})
+
+	t.Run("should filter the entries", func(t *testing.T) {
+		store := setup(t)
+
+		res, err := store.Search(context.Background(), &audit.SearchQuery{OrgID: 1, ResourceKind: "dashboards", ResourceUID: "abc", Result: audit.ResultFailure, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, res.Entries, 1)
+		assert.Equal(t, "editor", res.Entries[0].ActorLogin)
+
+		res, err = store.Search(context.Background(), &audit.SearchQuery{Action: "/api/dashboards", From: now.Add(-150 * time.Minute), Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, res.Entries, 1)
+		assert.Equal(t, "DELETE /api/dashboards/uid/:uid", res.Entries[0].Action)
+	})
+
+	t.Run("should paginate the entries", func(t *testing.T) {
+		store := setup(t)
+
+		res, err := store.Search(context.Background(), &audit.SearchQuery{Page: 2, Limit: 2})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, res.TotalCount)
+		require.Len(t, res.Entries, 1)
+		assert.Equal(t, "admin", res.Entries[0].ActorLogin)
+	})
+
+	t.Run("should delete the entries older than the given time", func(t *testing.T) {
+		store := setup(t)
+
+		deleted, err := store.DeleteOlderThan(context.Background(), now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, deleted)
+
+		res, err := store.Search(context.Background(), &audit.SearchQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, res.Entries, 1)
+		assert.Equal(t, "sa-deploy", res.Entries[0].ActorLogin)
+	})
+}
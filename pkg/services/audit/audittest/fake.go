@@ -0,0 +1,26 @@
+package audittest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var _ audit.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedResult *audit.SearchResult
+	ExpectedErr    error
+}
+
+func (f *FakeService) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return next
+	}
+}
+
+func (f *FakeService) Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error) {
+	return f.ExpectedResult, f.ExpectedErr
+}
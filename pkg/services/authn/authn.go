@@ -34,6 +34,7 @@ const (
 	MetaKeyUsername   = "username"
 	MetaKeyAuthModule = "authModule"
 	MetaKeyIsLogin    = "isLogin"
+	MetaKeyMFACode    = "mfaCode"
 )
 
 // ClientParams are hints to the auth service about how to handle the identity management
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -35,7 +36,7 @@ func ProvideRegistration(
 	authInfoService login.AuthInfoService, renderService rendering.Service,
 	features *featuremgmt.FeatureManager, oauthTokenService oauthtoken.OAuthTokenService,
 	socialService social.Service, cache *remotecache.RemoteCache, signingKeysService signingkeys.Service,
-	ldapService service.LDAP, settingsProviderService setting.Provider,
+	ldapService service.LDAP, settingsProviderService setting.Provider, mfaService mfa.Service,
 ) Registration {
 	logger := log.New("authn.registration")
 
@@ -98,6 +99,7 @@ func ProvideRegistration(
 	userSync := sync.ProvideUserSync(userService, userProtectionService, authInfoService, quotaService)
 	orgSync := sync.ProvideOrgSync(userService, orgService, accessControlService, cfg)
 	authnSvc.RegisterPostAuthHook(userSync.SyncUserHook, 10)
+	authnSvc.RegisterPostAuthHook(mfaService.VerifyHook, 15)
 	authnSvc.RegisterPostAuthHook(userSync.EnableUserHook, 20)
 	authnSvc.RegisterPostAuthHook(orgSync.SyncOrgRolesHook, 30)
 	authnSvc.RegisterPostAuthHook(userSync.SyncLastSeenHook, 130)
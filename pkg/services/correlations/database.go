@@ -2,6 +2,7 @@ package correlations
 
 import (
 	"context"
+	"fmt"
 
 	"xorm.io/core"
 
@@ -11,8 +12,17 @@ import (
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// targetExistsCondition filters out the query correlations whose target data source does not exist, the target of
+// external correlations is not a data source.
+const targetExistsCondition = "(correlation.target_uid IS NULL OR dst.uid IS NOT NULL)"
+
 // createCorrelation adds a correlation
 func (s CorrelationsService) createCorrelation(ctx context.Context, cmd CreateCorrelationCommand) (Correlation, error) {
+	// the target of external correlations is a URL, not a data source
+	if cmd.Config.Type == ConfigTypeExternal {
+		cmd.TargetUID = nil
+	}
+
 	correlation := Correlation{
 		UID:         util.GenerateShortUID(),
 		OrgID:       cmd.OrgId,
@@ -127,6 +137,10 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 		}
 		if cmd.Config != nil {
 			session.MustCols("config")
+			// correlations created before the config type was configurable are query correlations
+			if correlation.Config.Type == "" {
+				correlation.Config.Type = ConfigTypeQuery
+			}
 			if cmd.Config.Field != nil {
 				correlation.Config.Field = *cmd.Config.Field
 			}
@@ -139,6 +153,14 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 			if cmd.Config.Transformations != nil {
 				correlation.Config.Transformations = cmd.Config.Transformations
 			}
+			if correlation.Config.Type == ConfigTypeExternal && correlation.TargetUID != nil {
+				correlation.TargetUID = nil
+				session.MustCols("target_uid")
+			}
+			// the type can change, so the updated correlation is validated like a new one
+			if err := validateCorrelation(correlation.TargetUID, correlation.Config); err != nil {
+				return fmt.Errorf("%w: %s", ErrInvalidCorrelationConfig, err)
+			}
 		}
 
 		updateCount, err := session.Where("uid = ? AND source_uid = ?", correlation.UID, correlation.SourceUID).Limit(1).Update(correlation)
@@ -171,7 +193,7 @@ func (s CorrelationsService) getCorrelation(ctx context.Context, cmd GetCorrelat
 		}
 
 		// Correlations created before the fix #72498 may have org_id = 0, but it's deprecated and will be removed in #72325
-		found, err := session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ?", cmd.OrgId).Join("", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId).Where("correlation.uid = ? AND correlation.source_uid = ?", correlation.UID, correlation.SourceUID).Get(&correlation)
+		found, err := session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ?", cmd.OrgId).Join("LEFT", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId).Where("correlation.uid = ? AND correlation.source_uid = ? AND "+targetExistsCondition, correlation.UID, correlation.SourceUID).Get(&correlation)
 		if !found {
 			return ErrCorrelationNotFound
 		}
@@ -222,7 +244,7 @@ func (s CorrelationsService) getCorrelationsBySourceUID(ctx context.Context, cmd
 			return ErrSourceDataSourceDoesNotExists
 		}
 		// Correlations created before the fix #72498 may have org_id = 0, but it's deprecated and will be removed in #72325
-		return session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ?", cmd.OrgId).Join("", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId).Where("correlation.source_uid = ?", cmd.SourceUID).Find(&correlations)
+		return session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ?", cmd.OrgId).Join("LEFT", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId).Where("correlation.source_uid = ? AND "+targetExistsCondition, cmd.SourceUID).Find(&correlations)
 	})
 
 	if err != nil {
@@ -243,7 +265,9 @@ func (s CorrelationsService) getCorrelations(ctx context.Context, cmd GetCorrela
 		offset := cmd.Limit * (cmd.Page - 1)
 
 		// Correlations created before the fix #72498 may have org_id = 0, but it's deprecated and will be removed in #72325
-		q := session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ? ", cmd.OrgId).Join("", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId)
+		q := session.Select("correlation.*").Join("", "data_source AS dss", "correlation.source_uid = dss.uid and (correlation.org_id = 0 or dss.org_id = correlation.org_id) and dss.org_id = ? ", cmd.OrgId).Join("LEFT", "data_source AS dst", "correlation.target_uid = dst.uid and dst.org_id = ?", cmd.OrgId)
+
+		q.Where(targetExistsCondition)
 
 		if len(cmd.SourceUIDs) > 0 {
 			q.In("dss.uid", cmd.SourceUIDs)
@@ -0,0 +1,80 @@
+package correlations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationUpdateCorrelation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := CorrelationsService{
+		SQLStore: db.InitTestDB(t),
+		log:      log.NewNopLogger(),
+		DataSourceService: &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{UID: "loki", OrgID: 1},
+			{UID: "tempo", OrgID: 1},
+		}},
+	}
+
+	tempo := "tempo"
+	external, err := s.createCorrelation(context.Background(), CreateCorrelationCommand{
+		SourceUID: "loki",
+		// ignored for external correlations
+		TargetUID: &tempo,
+		OrgId:     1,
+		Config: CorrelationConfig{
+			Field:  "ticket",
+			Type:   ConfigTypeExternal,
+			Target: map[string]any{"url": "https://tickets.example.com/${ticket}"},
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, external.TargetUID)
+
+	t.Run("Fails to change an external correlation without target data source to a query correlation", func(t *testing.T) {
+		queryType := ConfigTypeQuery
+		_, err := s.updateCorrelation(context.Background(), UpdateCorrelationCommand{
+			UID:       external.UID,
+			SourceUID: "loki",
+			OrgId:     1,
+			Config:    &CorrelationConfigUpdateDTO{Type: &queryType},
+		})
+		require.ErrorIs(t, err, ErrInvalidCorrelationConfig)
+
+		stored := Correlation{UID: external.UID, SourceUID: "loki"}
+		err = s.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Get(&stored)
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, ConfigTypeExternal, stored.Config.Type)
+		require.Nil(t, stored.TargetUID)
+	})
+
+	t.Run("Updates the target of an external correlation", func(t *testing.T) {
+		target := map[string]any{"url": "https://tickets.example.com/browse/${ticket}"}
+		updated, err := s.updateCorrelation(context.Background(), UpdateCorrelationCommand{
+			UID:       external.UID,
+			SourceUID: "loki",
+			OrgId:     1,
+			Config:    &CorrelationConfigUpdateDTO{Target: &target},
+		})
+		require.NoError(t, err)
+		require.Equal(t, target, updated.Config.Target)
+	})
+}
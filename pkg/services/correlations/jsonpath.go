@@ -0,0 +1,59 @@
+package correlations
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// validateJSONPath checks the expression of a jsonpath transformation. The frontend evaluates a subset of JSONPath
+// that selects a single value: the root `$` followed by keys (`.key` or `['key']`) and array indexes (`[0]`).
+func validateJSONPath(expr string) error {
+	if !strings.HasPrefix(expr, "$") {
+		return errors.New("the expression must start with $")
+	}
+
+	rest := expr[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return fmt.Errorf("empty key at %q", rest)
+			}
+			rest = rest[1+end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return fmt.Errorf("unclosed bracket at %q", rest)
+			}
+			if err := validateJSONPathSubscript(rest[1:end]); err != nil {
+				return err
+			}
+			rest = rest[end+1:]
+		default:
+			return fmt.Errorf("unexpected character at %q", rest)
+		}
+	}
+
+	return nil
+}
+
+func validateJSONPathSubscript(subscript string) error {
+	if len(subscript) >= 2 && (subscript[0] == '\'' || subscript[0] == '"') && subscript[len(subscript)-1] == subscript[0] {
+		if len(subscript) == 2 {
+			return errors.New("empty key")
+		}
+		return nil
+	}
+
+	if index, err := strconv.Atoi(subscript); err != nil || index < 0 {
+		return fmt.Errorf("invalid subscript %q, only quoted keys and array indexes are supported", subscript)
+	}
+
+	return nil
+}
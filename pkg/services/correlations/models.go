@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -18,6 +20,10 @@ var (
 	ErrInvalidTransformationType     = errors.New("invalid transformation type")
 	ErrTransformationNotNested       = errors.New("transformations must be nested under config")
 	ErrTransformationRegexReqExp     = errors.New("regex transformations require expression")
+	ErrTransformationJSONPathReqExp  = errors.New("jsonpath transformations require a valid expression")
+	ErrExternalCorrelationReqURL     = errors.New("correlations of type \"external\" require a target url")
+	ErrExternalCorrelationURLScheme  = errors.New("the target url of correlations of type \"external\" must be a http or https url")
+	ErrInvalidCorrelationConfig      = errors.New("invalid correlation config")
 	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
 	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
 )
@@ -30,7 +36,7 @@ const (
 type CorrelationConfigType string
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	//Enum: regex,logfmt,jsonpath
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
@@ -38,11 +44,20 @@ type Transformation struct {
 }
 
 const (
+	// ConfigTypeQuery correlations run a query in the target data source
 	ConfigTypeQuery CorrelationConfigType = "query"
+	// ConfigTypeExternal correlations open the URL in the target, such as a ticket or a runbook
+	ConfigTypeExternal CorrelationConfigType = "external"
+)
+
+const (
+	TransformationTypeRegex    = "regex"
+	TransformationTypeLogfmt   = "logfmt"
+	TransformationTypeJSONPath = "jsonpath"
 )
 
 func (t CorrelationConfigType) Validate() error {
-	if t != ConfigTypeQuery {
+	if t != ConfigTypeQuery && t != ConfigTypeExternal {
 		return fmt.Errorf("%s: \"%s\"", ErrInvalidConfigType, t)
 	}
 	return nil
@@ -50,10 +65,18 @@ func (t CorrelationConfigType) Validate() error {
 
 func (t Transformations) Validate() error {
 	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
+		switch v.Type {
+		case TransformationTypeRegex:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			}
+		case TransformationTypeLogfmt:
+		case TransformationTypeJSONPath:
+			if err := validateJSONPath(v.Expression); err != nil {
+				return fmt.Errorf("%s: \"%s\": %s", ErrTransformationJSONPathReqExp, v.Expression, err)
+			}
+		default:
 			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
-		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
 		}
 	}
 	return nil
@@ -70,7 +93,7 @@ type CorrelationConfig struct {
 	// Target type
 	// required:true
 	Type CorrelationConfigType `json:"type" binding:"Required"`
-	// Target data query
+	// Target data query, or the URL to open for external correlations
 	// required:true
 	// example: {"prop1":"value1","prop2":"value"}
 	Target map[string]any `json:"target" binding:"Required"`
@@ -80,19 +103,44 @@ type CorrelationConfig struct {
 	Transformations Transformations `json:"transformations,omitempty"`
 }
 
+// Validate checks the type, the target and the transformations of the config
+func (c CorrelationConfig) Validate() error {
+	if err := c.Type.Validate(); err != nil {
+		return err
+	}
+
+	if c.Type == ConfigTypeExternal {
+		targetURL, _ := c.Target["url"].(string)
+		if targetURL == "" {
+			return ErrExternalCorrelationReqURL
+		}
+		// the URL is opened in the browser of the users, so it cannot run scripts
+		if u, err := url.Parse(targetURL); err != nil || (!strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https")) {
+			return ErrExternalCorrelationURLScheme
+		}
+	}
+
+	return c.Transformations.Validate()
+}
+
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 	target := c.Target
 	transformations := c.Transformations
 	if target == nil {
 		target = map[string]any{}
 	}
+	// correlations created before the config type was configurable are query correlations
+	configType := c.Type
+	if configType == "" {
+		configType = ConfigTypeQuery
+	}
 	return json.Marshal(struct {
 		Type            CorrelationConfigType `json:"type"`
 		Field           string                `json:"field"`
 		Target          map[string]any        `json:"target"`
 		Transformations Transformations       `json:"transformations,omitempty"`
 	}{
-		Type:            ConfigTypeQuery,
+		Type:            configType,
 		Field:           c.Field,
 		Target:          target,
 		Transformations: transformations,
@@ -147,7 +195,7 @@ type CreateCorrelationCommand struct {
 	// UID of the data source for which correlation is created.
 	SourceUID string `json:"-"`
 	OrgId     int64  `json:"-"`
-	// Target data source UID to which the correlation is created. required if config.type = query
+	// Target data source UID to which the correlation is created. required if config.type = query, ignored if config.type = external
 	// example: PE1C5CBDA0504A6A3
 	TargetUID *string `json:"targetUID"`
 	// Optional label identifying the correlation
@@ -163,17 +211,19 @@ type CreateCorrelationCommand struct {
 }
 
 func (c CreateCorrelationCommand) Validate() error {
-	if err := c.Config.Type.Validate(); err != nil {
+	return validateCorrelation(c.TargetUID, c.Config)
+}
+
+// validateCorrelation checks that query correlations have a target data source, and the config.
+func validateCorrelation(targetUID *string, config CorrelationConfig) error {
+	if err := config.Type.Validate(); err != nil {
 		return err
 	}
-	if c.TargetUID == nil && c.Config.Type == ConfigTypeQuery {
+	if targetUID == nil && config.Type == ConfigTypeQuery {
 		return fmt.Errorf("correlations of type \"%s\" must have a targetUID", ConfigTypeQuery)
 	}
 
-	if err := c.Config.Transformations.Validate(); err != nil {
-		return err
-	}
-	return nil
+	return config.Validate()
 }
 
 // swagger:model
@@ -204,7 +254,7 @@ type CorrelationConfigUpdateDTO struct {
 	Field *string `json:"field"`
 	// Target type
 	Type *CorrelationConfigType `json:"type"`
-	// Target data query
+	// Target data query, or the URL to open for external correlations
 	// example: {"prop1":"value1","prop2":"value"}
 	Target *map[string]any `json:"target"`
 	// Source data transformations
@@ -219,6 +269,10 @@ func (c CorrelationConfigUpdateDTO) Validate() error {
 		}
 	}
 
+	if err := Transformations(c.Transformations).Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -247,7 +301,7 @@ func (c UpdateCorrelationCommand) Validate() error {
 		}
 	}
 
-	if c.Label == nil && c.Description == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Type == nil && c.Config.Target == nil)) {
+	if c.Label == nil && c.Description == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Type == nil && c.Config.Target == nil && c.Config.Transformations == nil)) {
 		return ErrUpdateCorrelationEmptyParams
 	}
 
@@ -41,6 +41,50 @@ func TestCorrelationModels(t *testing.T) {
 			require.Error(t, cmd.Validate())
 		})
 
+		t.Run("Successfully validates an external correlation without target UID", func(t *testing.T) {
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config: CorrelationConfig{
+					Field:  "field",
+					Target: map[string]any{"url": "https://example.com/${traceId}"},
+					Type:   ConfigTypeExternal,
+				},
+			}
+
+			require.NoError(t, cmd.Validate())
+		})
+
+		t.Run("Fails if the target URL of an external correlation is not set", func(t *testing.T) {
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config: CorrelationConfig{
+					Field:  "field",
+					Target: map[string]any{},
+					Type:   ConfigTypeExternal,
+				},
+			}
+
+			require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationReqURL)
+		})
+
+		t.Run("Fails if the target URL of an external correlation is not a http or https URL", func(t *testing.T) {
+			for _, targetURL := range []string{"javascript:alert(document.cookie)", "JavaScript:alert(1)", "data:text/html,<script>alert(1)</script>", "//example.com/${traceId}", "${url}"} {
+				cmd := &CreateCorrelationCommand{
+					SourceUID: "some-uid",
+					OrgId:     1,
+					Config: CorrelationConfig{
+						Field:  "field",
+						Target: map[string]any{"url": targetURL},
+						Type:   ConfigTypeExternal,
+					},
+				}
+
+				require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationURLScheme, targetURL)
+			}
+		})
+
 		t.Run("Fails if config type is unknown", func(t *testing.T) {
 			config := &CorrelationConfig{
 				Field:  "field",
@@ -66,6 +110,7 @@ func TestCorrelationModels(t *testing.T) {
 
 			tests := []test{
 				{input: "query", assertion: require.NoError},
+				{input: "external", assertion: require.NoError},
 				{input: "link", assertion: require.Error},
 			}
 
@@ -75,6 +120,32 @@ func TestCorrelationModels(t *testing.T) {
 		})
 	})
 
+	t.Run("Transformations Validate", func(t *testing.T) {
+		type test struct {
+			input     Transformation
+			assertion require.ErrorAssertionFunc
+		}
+
+		tests := []test{
+			{input: Transformation{Type: "logfmt"}, assertion: require.NoError},
+			{input: Transformation{Type: "regex", Expression: "id=(\\w+)"}, assertion: require.NoError},
+			{input: Transformation{Type: "regex"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath", Expression: "$.user.id", MapValue: "userId"}, assertion: require.NoError},
+			{input: Transformation{Type: "jsonpath", Expression: "$['user name'].ids[0]"}, assertion: require.NoError},
+			{input: Transformation{Type: "jsonpath", Expression: "$"}, assertion: require.NoError},
+			{input: Transformation{Type: "jsonpath"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath", Expression: "user.id"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath", Expression: "$..id"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath", Expression: "$.ids[*]"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath", Expression: "$.ids[0"}, assertion: require.Error},
+			{input: Transformation{Type: "unknown"}, assertion: require.Error},
+		}
+
+		for _, tc := range tests {
+			tc.assertion(t, Transformations{tc.input}.Validate(), tc.input.Expression)
+		}
+	})
+
 	t.Run("CorrelationConfig JSON Marshaling", func(t *testing.T) {
 		t.Run("Applies a default empty object if target is not defined", func(t *testing.T) {
 			config := CorrelationConfig{
@@ -87,5 +158,25 @@ func TestCorrelationModels(t *testing.T) {
 
 			require.Equal(t, `{"type":"query","field":"field","target":{}}`, string(data))
 		})
+
+		t.Run("Keeps the type of external correlations", func(t *testing.T) {
+			config := CorrelationConfig{
+				Field:  "field",
+				Type:   ConfigTypeExternal,
+				Target: map[string]any{"url": "https://example.com"},
+			}
+
+			data, err := json.Marshal(config)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"type":"external","field":"field","target":{"url":"https://example.com"}}`, string(data))
+		})
+
+		t.Run("Defaults to the query type", func(t *testing.T) {
+			data, err := json.Marshal(CorrelationConfig{Field: "field"})
+			require.NoError(t, err)
+
+			require.Equal(t, `{"type":"query","field":"field","target":{}}`, string(data))
+		})
 	})
 }
@@ -0,0 +1,55 @@
+package dashboardusageimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/dashboards", func(r routing.RouteRegister) {
+		r.Get("/uid/:uid/insights", authorize(ac.EvalPermission(dashboardusage.ActionInsightsRead, uidScope)), routing.Wrap(s.getInsightsHandler))
+		r.Get("/stale", authorize(ac.EvalPermission(dashboardusage.ActionInsightsRead, dashboards.ScopeDashboardsAll)), routing.Wrap(s.getStaleDashboardsHandler))
+	}, requestmeta.SetOwner(requestmeta.TeamBackend))
+}
+
+// GET /api/dashboards/uid/:uid/insights
+func (s *Service) getInsightsHandler(c *contextmodel.ReqContext) response.Response {
+	insights, err := s.GetInsights(c.Req.Context(), &dashboardusage.GetInsightsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: web.Params(c.Req)[":uid"],
+		Days:         c.QueryInt("days"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the dashboard insights", err)
+	}
+	return response.JSON(http.StatusOK, insights)
+}
+
+// GET /api/dashboards/stale
+func (s *Service) getStaleDashboardsHandler(c *contextmodel.ReqContext) response.Response {
+	query := &dashboardusage.GetStaleDashboardsQuery{
+		OrgID: c.SignedInUser.GetOrgID(),
+		Limit: c.QueryInt("limit"),
+	}
+	if days := c.QueryInt("days"); days > 0 {
+		query.NotViewedSince = s.now().Add(-time.Duration(days) * day)
+	}
+
+	stale, err := s.GetStaleDashboards(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the stale dashboards", err)
+	}
+	return response.JSON(http.StatusOK, stale)
+}
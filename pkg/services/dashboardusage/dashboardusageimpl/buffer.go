@@ -0,0 +1,139 @@
+package dashboardusageimpl
+
+import (
+	"time"
+)
+
+type dashboardKey struct {
+	orgID        int64
+	dashboardUID string
+}
+
+type dayKey struct {
+	dashboardKey
+	// dayStart is the unix time of the start of the day, in UTC.
+	dayStart int64
+}
+
+type userKey struct {
+	dashboardKey
+	userID int64
+}
+
+type usage struct {
+	views           int64
+	queries         int64
+	queryErrors     int64
+	queryDurationMs int64
+}
+
+type dashboardUsage struct {
+	usage
+	lastViewed   int64
+	lastViewedBy int64
+}
+
+type userUsage struct {
+	views      int64
+	lastViewed int64
+}
+
+// buffer aggregates the views and queries recorded since the last flush, so that they are written with a few
+// updates instead of one insert per view or query.
+type buffer struct {
+	dashboards map[dashboardKey]*dashboardUsage
+	days       map[dayKey]*usage
+	users      map[userKey]*userUsage
+}
+
+func newBuffer() *buffer {
+	return &buffer{
+		dashboards: make(map[dashboardKey]*dashboardUsage),
+		days:       make(map[dayKey]*usage),
+		users:      make(map[userKey]*userUsage),
+	}
+}
+
+func (b *buffer) addView(key dashboardKey, userID int64, now time.Time) {
+	d := b.dashboard(key)
+	d.views++
+	if now.Unix() >= d.lastViewed {
+		d.lastViewed = now.Unix()
+		d.lastViewedBy = userID
+	}
+	b.day(key, now).views++
+
+	if userID == 0 {
+		return
+	}
+	uk := userKey{dashboardKey: key, userID: userID}
+	u, ok := b.users[uk]
+	if !ok {
+		u = &userUsage{}
+		b.users[uk] = u
+	}
+	u.views++
+	u.lastViewed = now.Unix()
+}
+
+func (b *buffer) addQuery(key dashboardKey, duration time.Duration, failed bool, now time.Time) {
+	for _, u := range []*usage{&b.dashboard(key).usage, b.day(key, now)} {
+		u.queries++
+		u.queryDurationMs += duration.Milliseconds()
+		if failed {
+			u.queryErrors++
+		}
+	}
+}
+
+func (b *buffer) dashboard(key dashboardKey) *dashboardUsage {
+	d, ok := b.dashboards[key]
+	if !ok {
+		d = &dashboardUsage{}
+		b.dashboards[key] = d
+	}
+	return d
+}
+
+func (b *buffer) day(key dashboardKey, now time.Time) *usage {
+	dk := dayKey{dashboardKey: key, dayStart: dayStart(now)}
+	u, ok := b.days[dk]
+	if !ok {
+		u = &usage{}
+		b.days[dk] = u
+	}
+	return u
+}
+
+// dashboardUIDs returns the UIDs of the dashboards in the buffer, by organization.
+func (b *buffer) dashboardUIDs() map[int64][]string {
+	uids := make(map[int64][]string)
+	for key := range b.dashboards {
+		uids[key.orgID] = append(uids[key.orgID], key.dashboardUID)
+	}
+	return uids
+}
+
+// retain removes the usage of the dashboards for which keep returns false.
+func (b *buffer) retain(keep func(key dashboardKey) bool) {
+	for key := range b.dashboards {
+		if !keep(key) {
+			delete(b.dashboards, key)
+		}
+	}
+	for key := range b.days {
+		if !keep(key.dashboardKey) {
+			delete(b.days, key)
+		}
+	}
+	for key := range b.users {
+		if !keep(key.dashboardKey) {
+			delete(b.users, key)
+		}
+	}
+}
+
+// size returns the number of rows written by a flush of the buffer.
+func (b *buffer) size() int {
+	return len(b.dashboards) + len(b.days) + len(b.users)
+}
@@ -0,0 +1,25 @@
+package dashboardusageimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:dashboards.insights:reader",
+			DisplayName: "Dashboard insights reader",
+			Description: "Read the usage of the dashboards of the organization and list the stale dashboards.",
+			Group:       "Dashboards",
+			Permissions: []accesscontrol.Permission{
+				{Action: dashboardusage.ActionInsightsRead, Scope: dashboards.ScopeDashboardsAll},
+			},
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reader)
+}
@@ -0,0 +1,316 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// maxBufferSize is the number of rows the buffer is flushed at, before the flush interval.
+	maxBufferSize       = 10000
+	retentionInterval   = time.Hour
+	defaultInsightsDays = 30
+	defaultStaleLimit   = 100
+	maxStaleLimit       = 1000
+	day                 = 24 * time.Hour
+)
+
+var _ dashboardusage.Service = (*Service)(nil)
+
+type Service struct {
+	cfg           setting.DashboardUsageSettings
+	store         *store
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	log           log.Logger
+	now           func() time.Time
+
+	mu       sync.Mutex
+	buf      *buffer
+	flushNow chan struct{}
+}
+
+func ProvideService(
+	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	acService accesscontrol.Service,
+) (*Service, error) {
+	s := &Service{
+		cfg:           cfg.DashboardUsage,
+		store:         &store{db: db},
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		log:           log.New("dashboard-usage"),
+		now:           time.Now,
+		buf:           newBuffer(),
+		flushNow:      make(chan struct{}, 1),
+	}
+	if !cfg.DashboardUsage.Enabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run writes the recorded usage to the database every flush interval and removes the usage older than the retention.
+// Every instance writes its own usage, the counters are incremented so that nothing is lost in a high availability
+// setup.
+func (s *Service) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(s.cfg.FlushInterval)
+	defer flushTicker.Stop()
+	retentionTicker := time.NewTicker(retentionInterval)
+	defer retentionTicker.Stop()
+
+	for {
+		select {
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-s.flushNow:
+			s.flush(ctx)
+		case <-retentionTicker.C:
+			s.deleteExpired(ctx)
+		case <-ctx.Done():
+			// The context is already canceled so it cannot be used to write what is left in the buffer.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			s.flush(flushCtx)
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) RecordView(ctx context.Context, orgID int64, dashboardUID string, userID int64) {
+	if !s.cfg.Enabled || dashboardUID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.addView(dashboardKey{orgID: orgID, dashboardUID: dashboardUID}, userID, s.now())
+	s.flushIfFull()
+}
+
+func (s *Service) RecordQuery(ctx context.Context, orgID int64, dashboardUID string, duration time.Duration, failed bool) {
+	if !s.cfg.Enabled || dashboardUID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.addQuery(dashboardKey{orgID: orgID, dashboardUID: dashboardUID}, duration, failed, s.now())
+	s.flushIfFull()
+}
+
+// flushIfFull asks Run to flush the buffer when it is full, it must be called with the lock held.
+func (s *Service) flushIfFull() {
+	if s.buf.size() < maxBufferSize {
+		return
+	}
+	select {
+	case s.flushNow <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	buf := s.buf
+	s.buf = newBuffer()
+	s.mu.Unlock()
+
+	if buf.size() == 0 {
+		return
+	}
+
+	// The dashboard UID of the queries is sent by the client, the usage of dashboards that do not exist is not written
+	if err := s.dropUnknownDashboards(ctx, buf); err != nil {
+		s.log.Error("Failed to check the dashboards of the usage, the usage is not written", "error", err)
+		return
+	}
+
+	var failed int
+	for key, u := range buf.dashboards {
+		if err := s.store.addDashboardUsage(ctx, key, u); err != nil {
+			failed++
+			s.log.Debug("Failed to write the dashboard usage", "orgId", key.orgID, "uid", key.dashboardUID, "error", err)
+		}
+	}
+	for key, u := range buf.days {
+		if err := s.store.addDailyUsage(ctx, key, u); err != nil {
+			failed++
+			s.log.Debug("Failed to write the daily dashboard usage", "orgId", key.orgID, "uid", key.dashboardUID, "error", err)
+		}
+	}
+	for key, u := range buf.users {
+		if err := s.store.addUserUsage(ctx, key, u); err != nil {
+			failed++
+			s.log.Debug("Failed to write the dashboard usage of the user", "orgId", key.orgID, "uid", key.dashboardUID, "userId", key.userID, "error", err)
+		}
+	}
+	if failed > 0 {
+		s.log.Error("Failed to write the dashboard usage", "failed", failed, "total", buf.size())
+	}
+}
+
+func (s *Service) dropUnknownDashboards(ctx context.Context, buf *buffer) error {
+	existing := make(map[int64]map[string]bool)
+	for orgID, uids := range buf.dashboardUIDs() {
+		found, err := s.store.existingDashboards(ctx, orgID, uids)
+		if err != nil {
+			return err
+		}
+		existing[orgID] = found
+	}
+
+	buf.retain(func(key dashboardKey) bool {
+		if existing[key.orgID][key.dashboardUID] {
+			return true
+		}
+		s.log.Debug("Dropped the usage of an unknown dashboard", "orgId", key.orgID, "uid", key.dashboardUID)
+		return false
+	})
+	return nil
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.deleteOlderThan(ctx, s.now().Add(-s.cfg.Retention))
+	if err != nil {
+		s.log.Error("Failed to delete the expired dashboard usage", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted the expired dashboard usage", "count", deleted)
+	}
+}
+
+func (s *Service) GetInsights(ctx context.Context, query *dashboardusage.GetInsightsQuery) (*dashboardusage.Insights, error) {
+	exists, err := s.store.dashboardExists(ctx, query.OrgID, query.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, dashboardusage.ErrDashboardNotFound.Errorf("dashboard with uid %s not found", query.DashboardUID)
+	}
+
+	days := query.Days
+	if days <= 0 {
+		days = defaultInsightsDays
+	}
+	// There is no usage to return past the retention
+	if maxDays := int(s.cfg.Retention / day); maxDays > 0 && days > maxDays {
+		days = maxDays
+	}
+
+	today := dayStart(s.now())
+	since := today - int64(days-1)*int64(day/time.Second)
+	rows, err := s.store.getDailyUsage(ctx, query.OrgID, query.DashboardUID, since)
+	if err != nil {
+		return nil, err
+	}
+	viewers, err := s.store.countViewers(ctx, query.OrgID, query.DashboardUID, since)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.store.getDashboardUsage(ctx, query.OrgID, query.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &dashboardusage.Insights{
+		DashboardUID:  query.DashboardUID,
+		Days:          days,
+		UniqueViewers: viewers,
+		LastViewedBy:  total.LastViewedBy,
+		Daily:         make([]*dashboardusage.DailyUsage, 0, days),
+	}
+	if total.LastViewed > 0 {
+		lastViewed := time.Unix(total.LastViewed, 0)
+		insights.LastViewed = &lastViewed
+	}
+
+	byDay := make(map[int64]*dailyUsageRow, len(rows))
+	for _, row := range rows {
+		byDay[row.DayStart] = row
+	}
+
+	var durationMs int64
+	for d := since; d <= today; d += int64(day / time.Second) {
+		daily := &dashboardusage.DailyUsage{Day: time.Unix(d, 0).UTC()}
+		if row, ok := byDay[d]; ok {
+			daily.Views = row.Views
+			daily.Queries = row.Queries
+			daily.QueryErrors = row.QueryErrors
+			daily.AverageLoadTimeMs = average(row.QueryDurationMs, row.Queries)
+
+			insights.Views += row.Views
+			insights.Queries += row.Queries
+			insights.QueryErrors += row.QueryErrors
+			durationMs += row.QueryDurationMs
+		}
+		insights.Daily = append(insights.Daily, daily)
+	}
+	insights.AverageLoadTimeMs = average(durationMs, insights.Queries)
+
+	return insights, nil
+}
+
+func (s *Service) GetStaleDashboards(ctx context.Context, query *dashboardusage.GetStaleDashboardsQuery) ([]*dashboardusage.StaleDashboard, error) {
+	if query.NotViewedSince.IsZero() {
+		query.NotViewedSince = s.now().Add(-s.cfg.StaleAfter)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultStaleLimit
+	}
+	if query.Limit > maxStaleLimit {
+		query.Limit = maxStaleLimit
+	}
+
+	rows, err := s.store.getStaleDashboards(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dashboardusage.StaleDashboard, 0, len(rows))
+	for _, row := range rows {
+		stale := &dashboardusage.StaleDashboard{
+			UID:       row.UID,
+			Title:     row.Title,
+			FolderUID: row.FolderUID,
+			Created:   row.Created,
+			Updated:   row.Updated,
+		}
+		if row.LastViewed > 0 {
+			lastViewed := time.Unix(row.LastViewed, 0)
+			stale.LastViewed = &lastViewed
+		}
+		result = append(result, stale)
+	}
+	return result, nil
+}
+
+func average(totalMs, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(totalMs) / float64(count)
+}
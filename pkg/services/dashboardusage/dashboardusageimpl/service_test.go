@@ -0,0 +1,195 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupService(t *testing.T) (*Service, db.DB) {
+	t.Helper()
+
+	sqlStore := db.InitTestDB(t)
+	s := &Service{
+		cfg: setting.DashboardUsageSettings{
+			Enabled:       true,
+			FlushInterval: time.Minute,
+			Retention:     90 * day,
+			StaleAfter:    90 * day,
+		},
+		store:    &store{db: sqlStore},
+		log:      log.NewNopLogger(),
+		now:      time.Now,
+		buf:      newBuffer(),
+		flushNow: make(chan struct{}, 1),
+	}
+	return s, sqlStore
+}
+
+func insertDashboard(t *testing.T, sqlStore db.DB, uid string, created time.Time) {
+	t.Helper()
+
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"title": "Dashboard " + uid}))
+		dash.OrgID = 1
+		dash.UID = uid
+		dash.SetVersion(1)
+		dash.Created = created
+		dash.Updated = created
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestIntegrationService_GetInsights(t *testing.T) {
+	s, sqlStore := setupService(t)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	insertDashboard(t, sqlStore, "dash", now.Add(-200*day))
+
+	s.now = func() time.Time { return now.Add(-2 * day) }
+	s.RecordView(ctx, 1, "dash", 1)
+	s.RecordQuery(ctx, 1, "dash", 100*time.Millisecond, false)
+	s.flush(ctx)
+
+	s.now = func() time.Time { return now.Add(-time.Hour) }
+	s.RecordView(ctx, 1, "dash", 0)
+	s.now = func() time.Time { return now }
+	s.RecordView(ctx, 1, "dash", 2)
+	s.RecordView(ctx, 1, "dash", 1)
+	s.RecordQuery(ctx, 1, "dash", 200*time.Millisecond, false)
+	s.RecordQuery(ctx, 1, "dash", 600*time.Millisecond, true)
+	// Other dashboards and organizations are not counted
+	s.RecordView(ctx, 1, "other", 1)
+	s.RecordView(ctx, 2, "dash", 1)
+	s.flush(ctx)
+
+	t.Run("should aggregate the usage of the period", func(t *testing.T) {
+		insights, err := s.GetInsights(ctx, &dashboardusage.GetInsightsQuery{OrgID: 1, DashboardUID: "dash", Days: 7})
+		require.NoError(t, err)
+
+		assert.Equal(t, 7, insights.Days)
+		assert.Equal(t, int64(4), insights.Views)
+		assert.Equal(t, int64(2), insights.UniqueViewers)
+		assert.Equal(t, int64(3), insights.Queries)
+		assert.Equal(t, int64(1), insights.QueryErrors)
+		assert.Equal(t, float64(300), insights.AverageLoadTimeMs)
+		require.NotNil(t, insights.LastViewed)
+		assert.Equal(t, now.Unix(), insights.LastViewed.Unix())
+		assert.Equal(t, int64(1), insights.LastViewedBy)
+
+		require.Len(t, insights.Daily, 7)
+		assert.Equal(t, time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC), insights.Daily[0].Day)
+		assert.Equal(t, int64(1), insights.Daily[4].Views)
+		assert.Equal(t, float64(100), insights.Daily[4].AverageLoadTimeMs)
+		assert.Equal(t, int64(0), insights.Daily[5].Views)
+		assert.Equal(t, int64(3), insights.Daily[6].Views)
+		assert.Equal(t, float64(400), insights.Daily[6].AverageLoadTimeMs)
+	})
+
+	t.Run("should only count the viewers of the period", func(t *testing.T) {
+		insights, err := s.GetInsights(ctx, &dashboardusage.GetInsightsQuery{OrgID: 1, DashboardUID: "dash", Days: 1})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), insights.Views)
+		assert.Equal(t, int64(2), insights.UniqueViewers)
+		require.Len(t, insights.Daily, 1)
+	})
+
+	t.Run("should return not found for unknown dashboards", func(t *testing.T) {
+		_, err := s.GetInsights(ctx, &dashboardusage.GetInsightsQuery{OrgID: 1, DashboardUID: "unknown"})
+		require.ErrorIs(t, err, dashboardusage.ErrDashboardNotFound)
+	})
+}
+
+func TestIntegrationService_GetStaleDashboards(t *testing.T) {
+	s, sqlStore := setupService(t)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+	insertDashboard(t, sqlStore, "never-viewed", now.Add(-200*day))
+	insertDashboard(t, sqlStore, "viewed-long-ago", now.Add(-200*day))
+	insertDashboard(t, sqlStore, "viewed-recently", now.Add(-200*day))
+	insertDashboard(t, sqlStore, "created-recently", now.Add(-10*day))
+
+	s.now = func() time.Time { return now.Add(-120 * day) }
+	s.RecordView(ctx, 1, "viewed-long-ago", 1)
+	s.RecordView(ctx, 1, "viewed-recently", 1)
+	s.now = func() time.Time { return now.Add(-time.Hour) }
+	s.RecordView(ctx, 1, "viewed-recently", 1)
+	s.flush(ctx)
+
+	s.now = func() time.Time { return now }
+	stale, err := s.GetStaleDashboards(ctx, &dashboardusage.GetStaleDashboardsQuery{OrgID: 1})
+	require.NoError(t, err)
+
+	require.Len(t, stale, 2)
+	assert.Equal(t, "never-viewed", stale[0].UID)
+	assert.Nil(t, stale[0].LastViewed)
+	assert.Equal(t, "viewed-long-ago", stale[1].UID)
+	require.NotNil(t, stale[1].LastViewed)
+	assert.Equal(t, now.Add(-120*day).Unix(), stale[1].LastViewed.Unix())
+
+	t.Run("should delete the expired usage but keep the last view", func(t *testing.T) {
+		s.deleteExpired(ctx)
+
+		insights, err := s.GetInsights(ctx, &dashboardusage.GetInsightsQuery{OrgID: 1, DashboardUID: "viewed-long-ago", Days: 365})
+		require.NoError(t, err)
+		assert.Equal(t, 90, insights.Days)
+		assert.Equal(t, int64(0), insights.Views)
+		assert.Equal(t, int64(0), insights.UniqueViewers)
+		require.NotNil(t, insights.LastViewed)
+
+		stale, err := s.GetStaleDashboards(ctx, &dashboardusage.GetStaleDashboardsQuery{OrgID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, stale, 1)
+		assert.Equal(t, "never-viewed", stale[0].UID)
+	})
+}
+
+func TestIntegrationService_FlushDropsUnknownDashboards(t *testing.T) {
+	s, sqlStore := setupService(t)
+	ctx := context.Background()
+	insertDashboard(t, sqlStore, "dash", time.Now())
+
+	s.RecordView(ctx, 1, "dash", 1)
+	s.RecordQuery(ctx, 1, "dash", time.Second, false)
+	s.RecordView(ctx, 1, "unknown", 1)
+	s.RecordQuery(ctx, 1, "unknown", time.Second, false)
+	// Dashboards of other organizations are unknown too
+	s.RecordQuery(ctx, 2, "dash", time.Second, false)
+	s.flush(ctx)
+
+	for _, table := range []string{"dashboard_usage", "dashboard_usage_daily", "dashboard_usage_user"} {
+		var uids []string
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(table).Cols("dashboard_uid").Find(&uids)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dash"}, uids, table)
+	}
+}
+
+func TestService_Disabled(t *testing.T) {
+	s := &Service{cfg: setting.DashboardUsageSettings{Enabled: false}, buf: newBuffer(), now: time.Now}
+
+	s.RecordView(context.Background(), 1, "dash", 1)
+	s.RecordQuery(context.Background(), 1, "dash", time.Second, false)
+	assert.Equal(t, 0, s.buf.size())
+}
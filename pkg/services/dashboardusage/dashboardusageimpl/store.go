@@ -0,0 +1,279 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+type dashboardUsageRow struct {
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	OrgID           int64  `xorm:"org_id"`
+	DashboardUID    string `xorm:"dashboard_uid"`
+	Views           int64  `xorm:"views"`
+	Queries         int64  `xorm:"queries"`
+	QueryErrors     int64  `xorm:"query_errors"`
+	QueryDurationMs int64  `xorm:"query_duration_ms"`
+	// LastViewed is a unix time, 0 when the dashboard has only been queried.
+	LastViewed   int64 `xorm:"last_viewed"`
+	LastViewedBy int64 `xorm:"last_viewed_by"`
+}
+
+func (dashboardUsageRow) TableName() string { return "dashboard_usage" }
+
+type dailyUsageRow struct {
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	OrgID           int64  `xorm:"org_id"`
+	DashboardUID    string `xorm:"dashboard_uid"`
+	DayStart        int64  `xorm:"day_start"`
+	Views           int64  `xorm:"views"`
+	Queries         int64  `xorm:"queries"`
+	QueryErrors     int64  `xorm:"query_errors"`
+	QueryDurationMs int64  `xorm:"query_duration_ms"`
+}
+
+func (dailyUsageRow) TableName() string { return "dashboard_usage_daily" }
+
+type userUsageRow struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	UserID       int64  `xorm:"user_id"`
+	Views        int64  `xorm:"views"`
+	LastViewed   int64  `xorm:"last_viewed"`
+}
+
+func (userUsageRow) TableName() string { return "dashboard_usage_user" }
+
+type staleDashboardRow struct {
+	UID        string    `xorm:"uid"`
+	Title      string    `xorm:"title"`
+	FolderUID  string    `xorm:"folder_uid"`
+	Created    time.Time `xorm:"created"`
+	Updated    time.Time `xorm:"updated"`
+	LastViewed int64     `xorm:"last_viewed"`
+}
+
+// existingDashboardsBatchSize is the number of dashboard UIDs looked up per query, to stay below the limit of
+// parameters of the databases.
+const existingDashboardsBatchSize = 500
+
+type store struct {
+	db db.DB
+}
+
+// upsert runs the update, and the insert when the update did not change any row. The update is retried when the row
+// is inserted by another instance in the meantime.
+func (s *store) upsert(ctx context.Context, update func(sess *db.Session) (int64, error), insert func(sess *db.Session) error) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		updated, err := update(sess)
+		if err != nil || updated > 0 {
+			return err
+		}
+
+		err = insert(sess)
+		if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+			_, err = update(sess)
+		}
+		return err
+	})
+}
+
+func (s *store) addDashboardUsage(ctx context.Context, key dashboardKey, u *dashboardUsage) error {
+	return s.upsert(ctx, func(sess *db.Session) (int64, error) {
+		// last_viewed_by is set before last_viewed since MySQL uses the updated value of the columns set before.
+		res, err := sess.Exec(`UPDATE dashboard_usage SET
+			views = views + ?, queries = queries + ?, query_errors = query_errors + ?, query_duration_ms = query_duration_ms + ?,
+			last_viewed_by = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed_by END,
+			last_viewed = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed END
+			WHERE org_id = ? AND dashboard_uid = ?`,
+			u.views, u.queries, u.queryErrors, u.queryDurationMs,
+			u.lastViewed, u.lastViewedBy,
+			u.lastViewed, u.lastViewed,
+			key.orgID, key.dashboardUID,
+		)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}, func(sess *db.Session) error {
+		_, err := sess.Insert(&dashboardUsageRow{
+			OrgID:           key.orgID,
+			DashboardUID:    key.dashboardUID,
+			Views:           u.views,
+			Queries:         u.queries,
+			QueryErrors:     u.queryErrors,
+			QueryDurationMs: u.queryDurationMs,
+			LastViewed:      u.lastViewed,
+			LastViewedBy:    u.lastViewedBy,
+		})
+		return err
+	})
+}
+
+func (s *store) addDailyUsage(ctx context.Context, key dayKey, u *usage) error {
+	return s.upsert(ctx, func(sess *db.Session) (int64, error) {
+		res, err := sess.Exec(`UPDATE dashboard_usage_daily SET
+			views = views + ?, queries = queries + ?, query_errors = query_errors + ?, query_duration_ms = query_duration_ms + ?
+			WHERE org_id = ? AND dashboard_uid = ? AND day_start = ?`,
+			u.views, u.queries, u.queryErrors, u.queryDurationMs,
+			key.orgID, key.dashboardUID, key.dayStart,
+		)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}, func(sess *db.Session) error {
+		_, err := sess.Insert(&dailyUsageRow{
+			OrgID:           key.orgID,
+			DashboardUID:    key.dashboardUID,
+			DayStart:        key.dayStart,
+			Views:           u.views,
+			Queries:         u.queries,
+			QueryErrors:     u.queryErrors,
+			QueryDurationMs: u.queryDurationMs,
+		})
+		return err
+	})
+}
+
+func (s *store) addUserUsage(ctx context.Context, key userKey, u *userUsage) error {
+	return s.upsert(ctx, func(sess *db.Session) (int64, error) {
+		res, err := sess.Exec(`UPDATE dashboard_usage_user SET
+			views = views + ?, last_viewed = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed END
+			WHERE org_id = ? AND dashboard_uid = ? AND user_id = ?`,
+			u.views, u.lastViewed, u.lastViewed,
+			key.orgID, key.dashboardUID, key.userID,
+		)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}, func(sess *db.Session) error {
+		_, err := sess.Insert(&userUsageRow{
+			OrgID:        key.orgID,
+			DashboardUID: key.dashboardUID,
+			UserID:       key.userID,
+			Views:        u.views,
+			LastViewed:   u.lastViewed,
+		})
+		return err
+	})
+}
+
+func (s *store) dashboardExists(ctx context.Context, orgID int64, uid string) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.SQL("SELECT 1 FROM dashboard WHERE org_id = ? AND uid = ? AND is_folder = "+s.db.GetDialect().BooleanStr(false), orgID, uid).Exist()
+		return err
+	})
+	return exists, err
+}
+
+// existingDashboards returns the UIDs of the given UIDs that are dashboards of the organization.
+func (s *store) existingDashboards(ctx context.Context, orgID int64, uids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(uids))
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(uids); start += existingDashboardsBatchSize {
+			end := start + existingDashboardsBatchSize
+			if end > len(uids) {
+				end = len(uids)
+			}
+
+			var found []string
+			err := sess.Table("dashboard").
+				Where("org_id = ? AND is_folder = "+s.db.GetDialect().BooleanStr(false), orgID).
+				In("uid", uids[start:end]).
+				Cols("uid").
+				Find(&found)
+			if err != nil {
+				return err
+			}
+			for _, uid := range found {
+				existing[uid] = true
+			}
+		}
+		return nil
+	})
+	return existing, err
+}
+
+func (s *store) getDashboardUsage(ctx context.Context, orgID int64, uid string) (*dashboardUsageRow, error) {
+	var row dashboardUsageRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, uid).Get(&row)
+		return err
+	})
+	return &row, err
+}
+
+// getDailyUsage returns the daily usage of the dashboard since the given day, oldest first.
+func (s *store) getDailyUsage(ctx context.Context, orgID int64, uid string, since int64) ([]*dailyUsageRow, error) {
+	var rows []*dailyUsageRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND dashboard_uid = ? AND day_start >= ?", orgID, uid, since).OrderBy("day_start ASC").Find(&rows)
+	})
+	return rows, err
+}
+
+// countViewers returns the number of users who viewed the dashboard since the given time.
+func (s *store) countViewers(ctx context.Context, orgID int64, uid string, since int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("org_id = ? AND dashboard_uid = ? AND last_viewed >= ?", orgID, uid, since).Count(&userUsageRow{})
+		return err
+	})
+	return count, err
+}
+
+func (s *store) getStaleDashboards(ctx context.Context, query *dashboardusage.GetStaleDashboardsQuery) ([]*staleDashboardRow, error) {
+	var rows []*staleDashboardRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := `SELECT d.uid, d.title, COALESCE(d.folder_uid, '') AS folder_uid, d.created, d.updated, COALESCE(u.last_viewed, 0) AS last_viewed
+			FROM dashboard d
+			LEFT JOIN dashboard_usage u ON u.org_id = d.org_id AND u.dashboard_uid = d.uid
+			WHERE d.org_id = ? AND d.is_folder = ` + s.db.GetDialect().BooleanStr(false) + ` AND d.created < ?
+			AND COALESCE(u.last_viewed, 0) < ?
+			ORDER BY last_viewed ASC, d.created ASC, d.id ASC ` + s.db.GetDialect().Limit(int64(query.Limit))
+		return sess.SQL(sql, query.OrgID, query.NotViewedSince, query.NotViewedSince.Unix()).Find(&rows)
+	})
+	return rows, err
+}
+
+// deleteOlderThan deletes the daily and per user usage older than the given time, and the usage of the deleted
+// dashboards.
+func (s *store) deleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, q := range []struct {
+			sql  string
+			args []any
+		}{
+			{sql: "DELETE FROM dashboard_usage_daily WHERE day_start < ?", args: []any{dayStart(before)}},
+			{sql: "DELETE FROM dashboard_usage_user WHERE last_viewed < ?", args: []any{before.Unix()}},
+			{sql: `DELETE FROM dashboard_usage WHERE NOT EXISTS (
+				SELECT 1 FROM dashboard WHERE dashboard.org_id = dashboard_usage.org_id AND dashboard.uid = dashboard_usage.dashboard_uid)`},
+		} {
+			res, err := sess.Exec(append([]any{q.sql}, q.args...)...)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += affected
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// dayStart returns the unix time of the start of the day of t, in UTC.
+func dayStart(t time.Time) int64 {
+	return t.UTC().Truncate(24 * time.Hour).Unix()
+}
@@ -0,0 +1,51 @@
+package dashboardusagetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+var _ dashboardusage.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedInsights        *dashboardusage.Insights
+	ExpectedStaleDashboards []*dashboardusage.StaleDashboard
+	ExpectedErr             error
+
+	Views   []View
+	Queries []Query
+}
+
+type View struct {
+	OrgID        int64
+	DashboardUID string
+	UserID       int64
+}
+
+type Query struct {
+	OrgID        int64
+	DashboardUID string
+	Failed       bool
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) RecordView(ctx context.Context, orgID int64, dashboardUID string, userID int64) {
+	f.Views = append(f.Views, View{OrgID: orgID, DashboardUID: dashboardUID, UserID: userID})
+}
+
+func (f *FakeService) RecordQuery(ctx context.Context, orgID int64, dashboardUID string, duration time.Duration, failed bool) {
+	f.Queries = append(f.Queries, Query{OrgID: orgID, DashboardUID: dashboardUID, Failed: failed})
+}
+
+func (f *FakeService) GetInsights(ctx context.Context, query *dashboardusage.GetInsightsQuery) (*dashboardusage.Insights, error) {
+	return f.ExpectedInsights, f.ExpectedErr
+}
+
+func (f *FakeService) GetStaleDashboards(ctx context.Context, query *dashboardusage.GetStaleDashboardsQuery) ([]*dashboardusage.StaleDashboard, error) {
+	return f.ExpectedStaleDashboards, f.ExpectedErr
+}
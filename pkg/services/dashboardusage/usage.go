@@ -0,0 +1,80 @@
+package dashboardusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// ActionInsightsRead allows reading the usage of the dashboards in scope.
+	ActionInsightsRead = "dashboards.insights:read"
+)
+
+var ErrDashboardNotFound = errutil.NotFound("dashboardusage.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
+
+// Service records who views the dashboards and how their queries perform, and aggregates it per dashboard, per user
+// and per day.
+type Service interface {
+	// RecordView records a view of the dashboard. userID is 0 when the dashboard is not viewed by a user, for example
+	// by an anonymous visitor.
+	RecordView(ctx context.Context, orgID int64, dashboardUID string, userID int64)
+	// RecordQuery records a query of a panel of the dashboard.
+	RecordQuery(ctx context.Context, orgID int64, dashboardUID string, duration time.Duration, failed bool)
+
+	GetInsights(ctx context.Context, query *GetInsightsQuery) (*Insights, error)
+	// GetStaleDashboards returns the dashboards that have not been viewed for a while, least recently viewed first.
+	GetStaleDashboards(ctx context.Context, query *GetStaleDashboardsQuery) ([]*StaleDashboard, error)
+}
+
+type GetInsightsQuery struct {
+	OrgID        int64
+	DashboardUID string
+	// Days is the number of days the usage is aggregated over, including today.
+	Days int
+}
+
+// Insights is the usage of a dashboard.
+type Insights struct {
+	DashboardUID string `json:"dashboardUid"`
+	// Days is the number of days Views, UniqueViewers, Queries, QueryErrors and AverageLoadTimeMs are aggregated over.
+	Days          int   `json:"days"`
+	Views         int64 `json:"views"`
+	UniqueViewers int64 `json:"uniqueViewers"`
+	Queries       int64 `json:"queries"`
+	QueryErrors   int64 `json:"queryErrors"`
+	// AverageLoadTimeMs is the average duration of the queries of the dashboard in milliseconds.
+	AverageLoadTimeMs float64 `json:"averageLoadTimeMs"`
+	// LastViewed is the last time the dashboard was viewed, regardless of Days.
+	LastViewed *time.Time `json:"lastViewed,omitempty"`
+	// LastViewedBy is the ID of the user who last viewed the dashboard, 0 when it was not a user.
+	LastViewedBy int64         `json:"lastViewedBy,omitempty"`
+	Daily        []*DailyUsage `json:"daily"`
+}
+
+// DailyUsage is the usage of a dashboard during a day, in UTC.
+type DailyUsage struct {
+	Day               time.Time `json:"day"`
+	Views             int64     `json:"views"`
+	Queries           int64     `json:"queries"`
+	QueryErrors       int64     `json:"queryErrors"`
+	AverageLoadTimeMs float64   `json:"averageLoadTimeMs"`
+}
+
+type GetStaleDashboardsQuery struct {
+	OrgID int64
+	// NotViewedSince excludes the dashboards viewed or created after this time.
+	NotViewedSince time.Time
+	Limit          int
+}
+
+type StaleDashboard struct {
+	UID       string    `json:"uid"`
+	Title     string    `json:"title"`
+	FolderUID string    `json:"folderUid,omitempty"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	// LastViewed is empty when the dashboard has never been viewed since the usage is recorded.
+	LastViewed *time.Time `json:"lastViewed,omitempty"`
+}
@@ -114,6 +114,19 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *contextmodel.ReqConte
 		return
 	}
 
+	// the proxied requests cannot be restricted with label policies, so restricted users cannot use the proxy
+	if datasources.SupportsLabelPolicies(ds.Type) {
+		labelPolicies, err := ds.LabelPolicies()
+		if err != nil {
+			c.JsonApiErr(http.StatusForbidden, "Access denied by the label policies of the data source", err)
+			return
+		}
+		if rules, err := datasources.LabelPolicyRules(labelPolicies, c.SignedInUser); err != nil || len(rules) > 0 {
+			c.JsonApiErr(http.StatusForbidden, "Access denied by the label policies of the data source", err)
+			return
+		}
+	}
+
 	// find plugin
 	plugin, exists := p.pluginStore.Plugin(c.Req.Context(), ds.Type)
 	if !exists {
@@ -9,11 +9,14 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -125,6 +128,70 @@ func TestDatasourceProxy_proxyDatasourceRequest(t *testing.T) {
 	}
 }
 
+func TestDatasourceProxy_proxyDatasourceRequestWithLabelPolicies(t *testing.T) {
+	jsonData := simplejson.NewFromAny(map[string]any{
+		"labelPolicies": []any{
+			map[string]any{"teamId": 1, "rules": []any{`namespace=~"payments-.*"`}},
+		},
+	})
+
+	tcs := []struct {
+		name           string
+		user           *user.SignedInUser
+		expectedStatus int
+	}{
+		{
+			name:           "Users restricted by a label policy cannot use the proxy",
+			user:           &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Users without a label policy cannot use the proxy",
+			user:           &user.SignedInUser{OrgRole: org.RoleEditor},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Org admins can use the proxy",
+			user:           &user.SignedInUser{OrgRole: org.RoleAdmin},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pluginID := datasources.DS_PROMETHEUS
+
+			pluginStore := &pluginstore.FakePluginStore{PluginList: []pluginstore.Plugin{
+				{JSONData: plugins.JSONData{ID: pluginID}},
+			}}
+
+			p := DataSourceProxyService{
+				PluginRequestValidator: &fakePluginRequestValidator{},
+				pluginStore:            pluginStore,
+			}
+
+			responseRecorder := httptest.NewRecorder()
+			c := &contextmodel.ReqContext{
+				Context: &web.Context{
+					Req:  &http.Request{URL: &url.URL{}},
+					Resp: web.NewResponseWriter("GET", responseRecorder),
+				},
+				SignedInUser: tc.user,
+				Logger:       log.NewNopLogger(),
+			}
+
+			// the empty URL fails after the label policies are checked
+			p.proxyDatasourceRequest(c, &datasources.DataSource{
+				Type:     pluginID,
+				JsonData: jsonData,
+			})
+
+			resp := responseRecorder.Result()
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
 type fakePluginRequestValidator struct{}
 
 func (rv *fakePluginRequestValidator) Validate(_ string, _ *http.Request) error {
@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	alertName = "DatasourceUnhealthy"
+	// alertResendFactor is the number of check intervals an alert stays active without being sent again, so that it
+	// resolves when the health checks stop.
+	alertResendFactor = 3
+)
+
+// sendAlert sends an alert to the Grafana Alertmanager of the organization while the data source is unhealthy, and
+// resolves it when the data source recovers.
+func (s *ServiceImpl) sendAlert(ctx context.Context, ds *datasources.DataSource, previous *Result, result *Result) {
+	if s.alertmanagers == nil {
+		return
+	}
+	if result.healthy() && (previous == nil || previous.healthy()) {
+		return
+	}
+
+	alert := models.PostableAlert{
+		Alert: models.Alert{
+			Labels: models.LabelSet{
+				"alertname":       alertName,
+				"datasource_uid":  ds.UID,
+				"datasource_name": ds.Name,
+				"datasource_type": ds.Type,
+			},
+			GeneratorURL: strfmt.URI(fmt.Sprintf("%s/connections/datasources/edit/%s", strings.TrimSuffix(s.appURL, "/"), ds.UID)),
+		},
+		Annotations: models.LabelSet{
+			"summary":     fmt.Sprintf("Data source %s is unhealthy", ds.Name),
+			"description": result.Message,
+		},
+		StartsAt: strfmt.DateTime(result.Checked),
+		EndsAt:   strfmt.DateTime(result.Checked.Add(alertResendFactor * s.cfg.Interval)),
+	}
+	if result.healthy() {
+		alert.Annotations["summary"] = fmt.Sprintf("Data source %s is healthy again", ds.Name)
+		alert.StartsAt = strfmt.DateTime(previous.Checked)
+		alert.EndsAt = strfmt.DateTime(result.Checked)
+	}
+
+	am, err := s.alertmanagers.AlertmanagerFor(ds.OrgID)
+	if err != nil {
+		s.log.Error("Failed to get the Alertmanager of the organization", "orgId", ds.OrgID, "error", err)
+		return
+	}
+	if err := am.PutAlerts(ctx, apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{alert}}); err != nil {
+		s.log.Error("Failed to send the data source health alert", "orgId", ds.OrgID, "uid", ds.UID, "error", err)
+	}
+}
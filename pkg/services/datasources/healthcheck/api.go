@@ -0,0 +1,57 @@
+package healthcheck
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ServiceImpl) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/datasources", func(r routing.RouteRegister) {
+		r.Get("/health", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getHealthHandler))
+		r.Get("/uid/:uid/health/history", authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.getHealthHistoryHandler))
+	}, requestmeta.SetOwner(requestmeta.TeamBackend))
+}
+
+// GET /api/datasources/health
+func (s *ServiceImpl) getHealthHandler(c *contextmodel.ReqContext) response.Response {
+	health, err := s.GetHealth(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the health of the data sources", err)
+	}
+
+	// Only return the data sources the user can read.
+	readable := make([]*DataSourceHealth, 0, len(health))
+	for _, h := range health {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(h.UID)))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate the data source permissions", err)
+		}
+		if ok {
+			readable = append(readable, h)
+		}
+	}
+	return response.JSON(http.StatusOK, readable)
+}
+
+// GET /api/datasources/uid/:uid/health/history
+func (s *ServiceImpl) getHealthHistoryHandler(c *contextmodel.ReqContext) response.Response {
+	health, err := s.GetHealthHistory(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the health history of the data source", err)
+	}
+	return response.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,356 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	lockName = "datasource health check"
+	// maxConcurrentChecks is the number of data sources checked at the same time.
+	maxConcurrentChecks = 10
+)
+
+// Service periodically checks the health of the data sources and keeps the results.
+type Service interface {
+	// GetHealth returns the latest health check result of the data sources of an organization.
+	GetHealth(ctx context.Context, orgID int64) ([]*DataSourceHealth, error)
+	// GetHealthHistory returns the health check results of a data source, the most recent first.
+	GetHealthHistory(ctx context.Context, orgID int64, uid string) (*DataSourceHealth, error)
+}
+
+// Result of a health check.
+type Result struct {
+	// Status is OK, ERROR or UNKNOWN, as returned by the data source plugin.
+	Status    string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+	LatencyMs int64     `json:"latencyMs"`
+	Checked   time.Time `json:"checked"`
+}
+
+func (r *Result) healthy() bool {
+	return r.Status == backend.HealthStatusOk.String()
+}
+
+// DataSourceHealth is the health of a data source.
+type DataSourceHealth struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Latest is the result of the latest health check, nil when the data source has not been checked yet.
+	Latest  *Result   `json:"latest"`
+	History []*Result `json:"history,omitempty"`
+}
+
+type serverLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type multiOrgAlertmanager interface {
+	AlertmanagerFor(orgID int64) (notifier.Alertmanager, error)
+}
+
+var _ Service = (*ServiceImpl)(nil)
+
+type ServiceImpl struct {
+	cfg                   setting.DataSourceHealthCheckSettings
+	appURL                string
+	store                 *store
+	dataSourceService     datasources.DataSourceService
+	pluginStore           pluginstore.Store
+	pluginContextProvider pluginContextProvider
+	pluginClient          plugins.Client
+	serverLock            serverLock
+	// alertmanagers is nil when unified alerting is disabled.
+	alertmanagers multiOrgAlertmanager
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	log           log.Logger
+	now           func() time.Time
+
+	healthStatus  *prometheus.GaugeVec
+	checkDuration *prometheus.HistogramVec
+}
+
+func ProvideService(
+	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	dataSourceService datasources.DataSourceService, pluginStore pluginstore.Store,
+	pluginContextProvider *plugincontext.Provider, pluginClient plugins.Client,
+	serverLockService *serverlock.ServerLockService, ng *ngalert.AlertNG, promRegisterer prometheus.Registerer,
+) *ServiceImpl {
+	s := newService(cfg.DataSourceHealthCheck, db, dataSourceService, pluginStore, pluginContextProvider, pluginClient, serverLockService)
+	s.appURL = cfg.AppURL
+	s.accessControl = accessControl
+	s.routeRegister = routeRegister
+	if ng != nil && ng.MultiOrgAlertmanager != nil {
+		s.alertmanagers = ng.MultiOrgAlertmanager
+	}
+	if !s.cfg.Enabled {
+		return s
+	}
+
+	promRegisterer.MustRegister(s.healthStatus, s.checkDuration)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func newService(
+	cfg setting.DataSourceHealthCheckSettings, db db.DB, dataSourceService datasources.DataSourceService,
+	pluginStore pluginstore.Store, pluginContextProvider pluginContextProvider, pluginClient plugins.Client,
+	serverLock serverLock,
+) *ServiceImpl {
+	return &ServiceImpl{
+		cfg:                   cfg,
+		store:                 &store{db: db},
+		dataSourceService:     dataSourceService,
+		pluginStore:           pluginStore,
+		pluginContextProvider: pluginContextProvider,
+		pluginClient:          pluginClient,
+		serverLock:            serverLock,
+		log:                   log.New("datasources.health-check"),
+		now:                   time.Now,
+		healthStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "health_status",
+			Help:      "Result of the latest health check of the data source, 1 when healthy and 0 when unhealthy",
+		}, []string{"datasource", "datasource_type"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "health_check_duration_seconds",
+			Help:      "Duration of the background health checks of the data sources",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"datasource_type"}),
+	}
+}
+
+func (s *ServiceImpl) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *ServiceImpl) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runScheduled(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runScheduled checks the data sources on a single instance. The lock interval is half of the check interval, so
+// that the instances do not check the data sources again in the same interval. Every instance then updates its
+// metrics from the stored results.
+func (s *ServiceImpl) runScheduled(ctx context.Context) {
+	err := s.serverLock.LockAndExecute(ctx, lockName, s.cfg.Interval/2, func(ctx context.Context) {
+		if err := s.checkAll(ctx); err != nil {
+			s.log.Error("Failed to check the health of the data sources", "error", err)
+		}
+	})
+	if err != nil {
+		s.log.Error("Failed to lock the data source health check", "error", err)
+	}
+
+	if err := s.updateMetrics(ctx); err != nil {
+		s.log.Error("Failed to update the data source health metrics", "error", err)
+	}
+}
+
+// checkAll checks the health of every data source, stores the results, and removes the results of the deleted data
+// sources.
+func (s *ServiceImpl) checkAll(ctx context.Context) error {
+	all, err := s.dataSourceService.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.store.latestResults(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	// The results are stored one at a time, as SQLite does not support concurrent writes.
+	var mu sync.Mutex
+	checked := make(map[resultKey]bool, len(all))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentChecks)
+	for _, ds := range all {
+		key := resultKey{orgID: ds.OrgID, uid: ds.UID}
+		checked[key] = true
+		if !s.checkable(gctx, ds) {
+			continue
+		}
+
+		g.Go(func() error {
+			result := s.check(gctx, ds)
+			mu.Lock()
+			defer mu.Unlock()
+			if err := s.store.addResult(gctx, key, result, s.cfg.HistorySize); err != nil {
+				s.log.Error("Failed to store the data source health check result", "orgId", ds.OrgID, "uid", ds.UID, "error", err)
+				return nil
+			}
+			if !result.healthy() && (previous[key] == nil || previous[key].healthy()) {
+				s.log.Warn("Data source is unhealthy", "orgId", ds.OrgID, "uid", ds.UID, "message", result.Message)
+			}
+			if s.cfg.AlertingEnabled {
+				s.sendAlert(gctx, ds, previous[key], result)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return s.store.deleteResultsExcept(ctx, checked)
+}
+
+// checkable returns whether the health of the data source can be checked in the background. The plugin must have a
+// backend, and the data source must not forward the identity of the users.
+func (s *ServiceImpl) checkable(ctx context.Context, ds *datasources.DataSource) bool {
+	p, exists := s.pluginStore.Plugin(ctx, ds.Type)
+	if !exists || !p.Backend {
+		return false
+	}
+	return !oauthtoken.IsOAuthPassThruEnabled(ds)
+}
+
+func (s *ServiceImpl) check(ctx context.Context, ds *datasources.DataSource) *Result {
+	start := s.now()
+	status, message := s.checkHealth(ctx, ds)
+	elapsed := s.now().Sub(start)
+	s.checkDuration.WithLabelValues(ds.Type).Observe(elapsed.Seconds())
+
+	return &Result{
+		Status:    status.String(),
+		Message:   message,
+		LatencyMs: elapsed.Milliseconds(),
+		Checked:   start,
+	}
+}
+
+func (s *ServiceImpl) checkHealth(ctx context.Context, ds *datasources.DataSource) (backend.HealthStatus, string) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	pCtx, err := s.pluginContextProvider.GetWithDataSource(ctx, ds.Type, nil, ds)
+	if err != nil {
+		return backend.HealthStatusError, err.Error()
+	}
+	// The plugin context only gets the organization of the user.
+	pCtx.OrgID = ds.OrgID
+
+	resp, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: pCtx,
+		Headers:       map[string]string{},
+	})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return backend.HealthStatusError, fmt.Sprintf("health check timed out after %s", s.cfg.Timeout)
+		}
+		return backend.HealthStatusError, err.Error()
+	}
+	return resp.Status, resp.Message
+}
+
+// updateMetrics sets the health status metric from the latest stored results.
+func (s *ServiceImpl) updateMetrics(ctx context.Context) error {
+	all, err := s.dataSourceService.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.store.latestResults(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	s.healthStatus.Reset()
+	for _, ds := range all {
+		result, ok := latest[resultKey{orgID: ds.OrgID, uid: ds.UID}]
+		if !ok {
+			continue
+		}
+		var value float64
+		if result.healthy() {
+			value = 1
+		}
+		s.healthStatus.WithLabelValues(ds.UID, ds.Type).Set(value)
+	}
+	return nil
+}
+
+func (s *ServiceImpl) GetHealth(ctx context.Context, orgID int64) ([]*DataSourceHealth, error) {
+	dataSources, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.store.latestResults(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]*DataSourceHealth, 0, len(dataSources))
+	for _, ds := range dataSources {
+		health = append(health, &DataSourceHealth{
+			UID:    ds.UID,
+			Name:   ds.Name,
+			Type:   ds.Type,
+			Latest: latest[resultKey{orgID: orgID, uid: ds.UID}],
+		})
+	}
+	return health, nil
+}
+
+func (s *ServiceImpl) GetHealthHistory(ctx context.Context, orgID int64, uid string) (*DataSourceHealth, error) {
+	ds, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: orgID, UID: uid})
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.store.history(ctx, resultKey{orgID: orgID, uid: uid})
+	if err != nil {
+		return nil, err
+	}
+
+	health := &DataSourceHealth{UID: ds.UID, Name: ds.Name, Type: ds.Type, History: history}
+	if len(history) > 0 {
+		health.Latest = history[0]
+	}
+	return health, nil
+}
@@ -0,0 +1,198 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeServerLock struct{}
+
+func (fakeServerLock) LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
+	fn(ctx)
+	return nil
+}
+
+type fakePluginContextProvider struct{}
+
+func (fakePluginContextProvider) GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	return backend.PluginContext{
+		PluginID:                   pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: ds.UID, Type: ds.Type},
+	}, nil
+}
+
+type fakeAlertmanager struct {
+	notifier.Alertmanager
+	mu     sync.Mutex
+	alerts []apimodels.PostableAlerts
+}
+
+func (f *fakeAlertmanager) PutAlerts(ctx context.Context, alerts apimodels.PostableAlerts) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alerts = append(f.alerts, alerts)
+	return nil
+}
+
+type fakeMultiOrgAlertmanager struct {
+	am *fakeAlertmanager
+}
+
+func (f *fakeMultiOrgAlertmanager) AlertmanagerFor(orgID int64) (notifier.Alertmanager, error) {
+	return f.am, nil
+}
+
+func setupService(t *testing.T, dataSources []*datasources.DataSource, health map[string]*backend.CheckHealthResult) (*ServiceImpl, *fakeDatasources.FakeDataSourceService) {
+	t.Helper()
+
+	dsService := &fakeDatasources.FakeDataSourceService{DataSources: dataSources}
+	pluginStore := pluginstore.NewFakePluginStore(
+		pluginstore.Plugin{JSONData: plugins.JSONData{ID: "prometheus", Type: plugins.TypeDataSource, Backend: true}},
+		pluginstore.Plugin{JSONData: plugins.JSONData{ID: "frontend", Type: plugins.TypeDataSource}},
+	)
+	client := &clienttest.TestClient{
+		CheckHealthFunc: func(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			uid := req.PluginContext.DataSourceInstanceSettings.UID
+			if uid == "slow" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			if result, ok := health[uid]; ok {
+				return result, nil
+			}
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	cfg := setting.DataSourceHealthCheckSettings{
+		Enabled:     true,
+		Interval:    time.Minute,
+		Timeout:     100 * time.Millisecond,
+		HistorySize: 2,
+	}
+	s := newService(cfg, db.InitTestDB(t), dsService, pluginStore, fakePluginContextProvider{}, client, fakeServerLock{})
+	return s, dsService
+}
+
+func TestIntegrationService_CheckAll(t *testing.T) {
+	dataSources := []*datasources.DataSource{
+		{OrgID: 1, UID: "healthy", Name: "Healthy", Type: "prometheus"},
+		{OrgID: 1, UID: "unhealthy", Name: "Unhealthy", Type: "prometheus"},
+		{OrgID: 1, UID: "failing", Name: "Failing", Type: "prometheus"},
+		{OrgID: 1, UID: "slow", Name: "Slow", Type: "prometheus"},
+		{OrgID: 1, UID: "frontend", Name: "Frontend", Type: "frontend"},
+		{OrgID: 1, UID: "forwarded", Name: "Forwarded", Type: "prometheus", JsonData: simplejson.NewFromAny(map[string]any{"oauthPassThru": true})},
+		{OrgID: 2, UID: "healthy", Name: "Healthy", Type: "prometheus"},
+	}
+	health := map[string]*backend.CheckHealthResult{
+		"healthy":   {Status: backend.HealthStatusOk, Message: "Data source is working"},
+		"unhealthy": {Status: backend.HealthStatusError, Message: "Bad gateway"},
+	}
+	s, dsService := setupService(t, dataSources, health)
+	ctx := context.Background()
+
+	s.runScheduled(ctx)
+
+	org1, err := s.GetHealth(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, org1, 6)
+	byUID := map[string]*DataSourceHealth{}
+	for _, h := range org1 {
+		byUID[h.UID] = h
+	}
+
+	assert.Equal(t, "OK", byUID["healthy"].Latest.Status)
+	assert.Equal(t, "Data source is working", byUID["healthy"].Latest.Message)
+	assert.Equal(t, "ERROR", byUID["unhealthy"].Latest.Status)
+	assert.Equal(t, "Bad gateway", byUID["unhealthy"].Latest.Message)
+	assert.Equal(t, "ERROR", byUID["failing"].Latest.Status)
+	assert.Equal(t, "connection refused", byUID["failing"].Latest.Message)
+	assert.Equal(t, "health check timed out after 100ms", byUID["slow"].Latest.Message)
+	assert.GreaterOrEqual(t, byUID["slow"].Latest.LatencyMs, int64(100))
+	assert.Nil(t, byUID["frontend"].Latest, "data sources without a backend are not checked")
+	assert.Nil(t, byUID["forwarded"].Latest, "data sources forwarding the user identity are not checked")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.healthStatus.WithLabelValues("healthy", "prometheus")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(s.healthStatus.WithLabelValues("unhealthy", "prometheus")))
+
+	t.Run("Should keep the history size", func(t *testing.T) {
+		s.runScheduled(ctx)
+		s.runScheduled(ctx)
+
+		history, err := s.GetHealthHistory(ctx, 1, "unhealthy")
+		require.NoError(t, err)
+		require.Len(t, history.History, 2)
+		assert.Same(t, history.History[0], history.Latest)
+		assert.Equal(t, "Unhealthy", history.Name)
+
+		_, err = s.GetHealthHistory(ctx, 1, "unknown")
+		require.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+	})
+
+	t.Run("Should remove the results of the deleted data sources", func(t *testing.T) {
+		dsService.DataSources = dataSources[1:]
+		s.runScheduled(ctx)
+
+		latest, err := s.store.latestResults(ctx, 0)
+		require.NoError(t, err)
+		assert.NotContains(t, latest, resultKey{orgID: 1, uid: "healthy"})
+		assert.Contains(t, latest, resultKey{orgID: 2, uid: "healthy"})
+	})
+}
+
+func TestIntegrationService_Alerts(t *testing.T) {
+	health := map[string]*backend.CheckHealthResult{
+		"prom": {Status: backend.HealthStatusOk},
+	}
+	s, _ := setupService(t, []*datasources.DataSource{{OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"}}, health)
+	am := &fakeAlertmanager{}
+	s.alertmanagers = &fakeMultiOrgAlertmanager{am: am}
+	s.cfg.AlertingEnabled = true
+	s.appURL = "http://localhost:3000/"
+	ctx := context.Background()
+
+	s.runScheduled(ctx)
+	require.Empty(t, am.alerts, "no alert is sent while the data source is healthy")
+
+	health["prom"] = &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "Bad gateway"}
+	s.runScheduled(ctx)
+	require.Len(t, am.alerts, 1)
+	alert := am.alerts[0].PostableAlerts[0]
+	assert.Equal(t, "DatasourceUnhealthy", alert.Labels["alertname"])
+	assert.Equal(t, "prom", alert.Labels["datasource_uid"])
+	assert.Equal(t, "Bad gateway", alert.Annotations["description"])
+	assert.Equal(t, "http://localhost:3000/connections/datasources/edit/prom", alert.GeneratorURL.String())
+	assert.True(t, time.Time(alert.EndsAt).After(time.Now()))
+
+	health["prom"] = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+	s.runScheduled(ctx)
+	require.Len(t, am.alerts, 2)
+	resolved := am.alerts[1].PostableAlerts[0]
+	assert.Equal(t, alert.Labels, resolved.Labels)
+	assert.False(t, time.Time(resolved.EndsAt).After(time.Now()))
+}
@@ -0,0 +1,133 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type healthCheckRow struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DataSourceUID string `xorm:"data_source_uid"`
+	Status        string `xorm:"status"`
+	Message       string `xorm:"message"`
+	LatencyMs     int64  `xorm:"latency_ms"`
+	// Checked is a unix time.
+	Checked int64 `xorm:"checked"`
+}
+
+func (healthCheckRow) TableName() string { return "data_source_health_check" }
+
+func (r *healthCheckRow) result() *Result {
+	return &Result{
+		Status:    r.Status,
+		Message:   r.Message,
+		LatencyMs: r.LatencyMs,
+		Checked:   time.Unix(r.Checked, 0),
+	}
+}
+
+type resultKey struct {
+	orgID int64
+	uid   string
+}
+
+type store struct {
+	db db.DB
+}
+
+// addResult stores the result of a health check, and removes the oldest results of the data source above the history
+// size.
+func (s *store) addResult(ctx context.Context, key resultKey, result *Result, historySize int) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(&healthCheckRow{
+				OrgID:         key.orgID,
+				DataSourceUID: key.uid,
+				Status:        result.Status,
+				Message:       result.Message,
+				LatencyMs:     result.LatencyMs,
+				Checked:       result.Checked.Unix(),
+			}); err != nil {
+				return err
+			}
+
+			var ids []int64
+			if err := sess.Table("data_source_health_check").Cols("id").
+				Where("org_id = ? AND data_source_uid = ?", key.orgID, key.uid).
+				Desc("id").Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) <= historySize {
+				return nil
+			}
+
+			_, err := sess.In("id", ids[historySize:]).Delete(&healthCheckRow{})
+			return err
+		})
+	})
+}
+
+// latestResults returns the latest result of every data source of an organization, or of every organization when
+// the organization id is 0.
+func (s *store) latestResults(ctx context.Context, orgID int64) (map[resultKey]*Result, error) {
+	var rows []healthCheckRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "SELECT * FROM data_source_health_check WHERE id IN (SELECT MAX(id) FROM data_source_health_check"
+		var args []any
+		if orgID != 0 {
+			sql += " WHERE org_id = ?"
+			args = append(args, orgID)
+		}
+		sql += " GROUP BY org_id, data_source_uid)"
+		return sess.SQL(sql, args...).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[resultKey]*Result, len(rows))
+	for i := range rows {
+		results[resultKey{orgID: rows[i].OrgID, uid: rows[i].DataSourceUID}] = rows[i].result()
+	}
+	return results, nil
+}
+
+// history returns the results of a data source, the most recent first.
+func (s *store) history(ctx context.Context, key resultKey) ([]*Result, error) {
+	var rows []healthCheckRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND data_source_uid = ?", key.orgID, key.uid).Desc("id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*Result, 0, len(rows))
+	for i := range rows {
+		history = append(history, rows[i].result())
+	}
+	return history, nil
+}
+
+// deleteResultsExcept removes the results of the data sources that are not in the keys, as they have been deleted.
+func (s *store) deleteResultsExcept(ctx context.Context, keys map[resultKey]bool) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored []healthCheckRow
+		if err := sess.Table("data_source_health_check").Distinct("org_id", "data_source_uid").Find(&stored); err != nil {
+			return err
+		}
+
+		for _, row := range stored {
+			if keys[resultKey{orgID: row.OrgID, uid: row.DataSourceUID}] {
+				continue
+			}
+			if _, err := sess.Where("org_id = ? AND data_source_uid = ?", row.OrgID, row.DataSourceUID).Delete(&healthCheckRow{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -3,9 +3,13 @@ package datasources
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models/roletype"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -124,6 +128,80 @@ func GetTeamHTTPHeaders(jsonData *simplejson.Json) (*TeamHTTPHeaders, error) {
 	return teamHTTPHeaders, nil
 }
 
+// LabelPoliciesJSONDataKey is the jsonData key of the label policies of Prometheus and Loki data sources.
+const LabelPoliciesJSONDataKey = "labelPolicies"
+
+// LabelPolicy restricts the series and log streams that the members of a team, or the users with an organization
+// role, can query from a data source.
+type LabelPolicy struct {
+	TeamID int64             `json:"teamId,omitempty"`
+	Role   roletype.RoleType `json:"role,omitempty"`
+	// Rules are label matchers, e.g. namespace=~"payments-.*", that every series and log stream has to match.
+	Rules []string `json:"rules"`
+}
+
+// SupportsLabelPolicies returns true if label policies are enforced for the data source type.
+func SupportsLabelPolicies(dsType string) bool {
+	return dsType == DS_PROMETHEUS || dsType == DS_LOKI
+}
+
+func (ds DataSource) LabelPolicies() ([]LabelPolicy, error) {
+	return GetLabelPolicies(ds.JsonData)
+}
+
+func GetLabelPolicies(jsonData *simplejson.Json) ([]LabelPolicy, error) {
+	if jsonData == nil {
+		return nil, nil
+	}
+	if _, ok := jsonData.CheckGet(LabelPoliciesJSONDataKey); !ok {
+		return nil, nil
+	}
+
+	labelPoliciesJSON, err := jsonData.Get(LabelPoliciesJSONDataKey).MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var labelPolicies []LabelPolicy
+	if err := json.Unmarshal(labelPoliciesJSON, &labelPolicies); err != nil {
+		return nil, err
+	}
+	for _, policy := range labelPolicies {
+		if (policy.TeamID == 0) == (policy.Role == "") {
+			return nil, errors.New("either teamId or role must be set in labelPolicies")
+		}
+		if policy.Role != "" && !policy.Role.IsValid() {
+			return nil, fmt.Errorf("invalid role %q in labelPolicies", policy.Role)
+		}
+		if len(policy.Rules) == 0 {
+			return nil, errors.New("rules are missing or empty in labelPolicies")
+		}
+	}
+
+	return labelPolicies, nil
+}
+
+// LabelPolicyRules returns the rules that the queries of the user have to be restricted with, combining the rules of
+// all the label policies that apply to the user. The rules are combined with AND, so a user with several policies
+// only gets the series and log streams that match all of them. Organization admins are not restricted. If the data
+// source has label policies but none of them applies to the user, the user cannot query the data source.
+func LabelPolicyRules(labelPolicies []LabelPolicy, requester identity.Requester) ([]string, error) {
+	if len(labelPolicies) == 0 || requester.GetOrgRole() == roletype.RoleAdmin {
+		return nil, nil
+	}
+
+	var rules []string
+	for _, policy := range labelPolicies {
+		if policy.Role == requester.GetOrgRole() || (policy.TeamID != 0 && slices.Contains(requester.GetTeams(), policy.TeamID)) {
+			rules = append(rules, policy.Rules...)
+		}
+	}
+	if len(rules) == 0 {
+		return nil, ErrLabelPolicyAccessDenied.Errorf("no label policy applies to the user")
+	}
+
+	return rules, nil
+}
+
 // AllowedCookies parses the jsondata.keepCookies and returns a list of
 // allowed cookies, otherwise an empty list.
 func (ds DataSource) AllowedCookies() []string {
@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models/roletype"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 func TestAllowedCookies(t *testing.T) {
@@ -102,3 +104,104 @@ func TestTeamHTTPHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestLabelPolicies(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		given   string
+		want    []LabelPolicy
+		wantErr bool
+	}{
+		{
+			desc:  "Usual json data with labelPolicies",
+			given: `{"labelPolicies": [{"teamId": 101, "rules": ["namespace=~\"payments-.*\""]}, {"role": "Viewer", "rules": ["namespace=\"public\""]}]}`,
+			want: []LabelPolicy{
+				{TeamID: 101, Rules: []string{`namespace=~"payments-.*"`}},
+				{Role: roletype.RoleViewer, Rules: []string{`namespace="public"`}},
+			},
+		},
+		{
+			desc:  "Json data without labelPolicies",
+			given: `{"foo": "bar"}`,
+			want:  nil,
+		},
+		{
+			desc:    "Policy without team or role",
+			given:   `{"labelPolicies": [{"rules": ["namespace=\"public\""]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Policy with both team and role",
+			given:   `{"labelPolicies": [{"teamId": 101, "role": "Viewer", "rules": ["namespace=\"public\""]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Policy with invalid role",
+			given:   `{"labelPolicies": [{"role": "Reader", "rules": ["namespace=\"public\""]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Policy without rules",
+			given:   `{"labelPolicies": [{"teamId": 101}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			jsonData, err := simplejson.NewJson([]byte(test.given))
+			require.NoError(t, err)
+
+			ds := DataSource{
+				ID:       1235,
+				JsonData: jsonData,
+				UID:      "test",
+			}
+
+			actual, err := ds.LabelPolicies()
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestLabelPolicyRules(t *testing.T) {
+	policies := []LabelPolicy{
+		{TeamID: 1, Rules: []string{`namespace=~"payments-.*"`}},
+		{TeamID: 2, Rules: []string{`cluster="prod"`}},
+		{Role: roletype.RoleViewer, Rules: []string{`namespace="public"`}},
+	}
+
+	t.Run("returns the rules of the teams and the role of the user", func(t *testing.T) {
+		rules, err := LabelPolicyRules(policies, &user.SignedInUser{OrgRole: roletype.RoleViewer, Teams: []int64{1, 3}})
+		require.NoError(t, err)
+		require.Equal(t, []string{`namespace=~"payments-.*"`, `namespace="public"`}, rules)
+	})
+
+	t.Run("combines the rules of all the teams of the user", func(t *testing.T) {
+		rules, err := LabelPolicyRules(policies, &user.SignedInUser{OrgRole: roletype.RoleEditor, Teams: []int64{1, 2}})
+		require.NoError(t, err)
+		require.Equal(t, []string{`namespace=~"payments-.*"`, `cluster="prod"`}, rules)
+	})
+
+	t.Run("denies access to users without policies", func(t *testing.T) {
+		_, err := LabelPolicyRules(policies, &user.SignedInUser{OrgRole: roletype.RoleEditor, Teams: []int64{3}})
+		require.ErrorIs(t, err, ErrLabelPolicyAccessDenied)
+	})
+
+	t.Run("does not restrict org admins", func(t *testing.T) {
+		rules, err := LabelPolicyRules(policies, &user.SignedInUser{OrgRole: roletype.RoleAdmin, Teams: []int64{1}})
+		require.NoError(t, err)
+		require.Empty(t, rules)
+	})
+
+	t.Run("does not restrict data sources without policies", func(t *testing.T) {
+		rules, err := LabelPolicyRules(nil, &user.SignedInUser{OrgRole: roletype.RoleViewer})
+		require.NoError(t, err)
+		require.Empty(t, rules)
+	})
+}
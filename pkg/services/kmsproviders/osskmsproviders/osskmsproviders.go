@@ -5,6 +5,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/vaultprovider"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -24,7 +25,24 @@ func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featurem
 }
 
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.cfg, s.enc),
-	}, nil
+	}
+
+	available := s.cfg.SectionWithEnvOverrides("security").Key("available_encryption_providers").Strings(" ")
+	for _, id := range available {
+		providerID := secrets.ProviderID(id)
+		// Providers of other kinds are not available in OSS.
+		if kind, err := providerID.Kind(); err != nil || kind != vaultprovider.Kind {
+			continue
+		}
+
+		provider, err := vaultprovider.NewFromConfig(s.cfg, providerID)
+		if err != nil {
+			return nil, err
+		}
+		providers[providerID] = provider
+	}
+
+	return providers, nil
 }
@@ -0,0 +1,374 @@
+package vaultprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kind is the kind of the Hashicorp Vault providers, whose identifiers have
+// the format hashicorpvault.<keyName>.
+const Kind = "hashicorpvault"
+
+var (
+	_ secrets.Provider           = (*Provider)(nil)
+	_ secrets.BackgroundProvider = (*Provider)(nil)
+	_ secrets.RotatableProvider  = (*Provider)(nil)
+)
+
+// Settings of a Hashicorp Vault provider, read from the
+// [security.encryption.hashicorpvault.<keyName>] section.
+type Settings struct {
+	URL       string
+	Namespace string
+
+	// Token is used to authenticate within Vault, unless the AppRole
+	// role id and secret id are configured.
+	Token            string
+	AppRoleMountPath string
+	AppRoleRoleID    string
+	AppRoleSecretID  string
+
+	TransitEnginePath    string
+	KeyRing              string
+	TokenRenewalInterval time.Duration
+
+	// RotateKeyOnDataKeysRotation makes the provider rotate the transit key
+	// every time the data keys are rotated.
+	RotateKeyOnDataKeysRotation bool
+}
+
+func (s Settings) useAppRole() bool {
+	return s.AppRoleRoleID != ""
+}
+
+// ReadSettings reads the settings of the Hashicorp Vault provider with the given identifier.
+func ReadSettings(cfg *setting.Cfg, id secrets.ProviderID) (Settings, error) {
+	section := cfg.SectionWithEnvOverrides("security.encryption." + string(id))
+
+	settings := Settings{
+		URL:                         strings.TrimSuffix(section.Key("url").MustString("http://localhost:8200"), "/"),
+		Namespace:                   section.Key("namespace").String(),
+		Token:                       section.Key("token").String(),
+		AppRoleMountPath:            strings.Trim(section.Key("approle_mount_path").MustString("approle"), "/"),
+		AppRoleRoleID:               section.Key("approle_role_id").String(),
+		AppRoleSecretID:             section.Key("approle_secret_id").String(),
+		TransitEnginePath:           strings.Trim(section.Key("transit_engine_path").MustString("transit"), "/"),
+		KeyRing:                     section.Key("key_ring").String(),
+		TokenRenewalInterval:        section.Key("token_renewal_interval").MustDuration(5 * time.Minute),
+		RotateKeyOnDataKeysRotation: section.Key("rotate_key_on_data_keys_rotation").MustBool(false),
+	}
+
+	if settings.KeyRing == "" {
+		return Settings{}, fmt.Errorf("missing key_ring for encryption provider %s", id)
+	}
+	if settings.Token == "" && settings.AppRoleRoleID == "" {
+		return Settings{}, fmt.Errorf("missing token or approle_role_id for encryption provider %s", id)
+	}
+	if settings.Token != "" && settings.AppRoleRoleID != "" {
+		return Settings{}, fmt.Errorf("token and approle_role_id are mutually exclusive for encryption provider %s", id)
+	}
+	if _, err := url.Parse(settings.URL); err != nil {
+		return Settings{}, fmt.Errorf("invalid url for encryption provider %s: %w", id, err)
+	}
+
+	return settings, nil
+}
+
+// Provider is a key encryption key provider backed by the transit secrets
+// engine of Hashicorp Vault. The ciphertexts returned by Vault embed the
+// version of the key used to encrypt them, so data keys encrypted before a
+// key rotation can still be decrypted, as long as the version is above the
+// min_decryption_version of the key.
+type Provider struct {
+	settings Settings
+	client   *http.Client
+	log      log.Logger
+
+	mtx   sync.Mutex
+	token string
+	// tokenExpiry is the expiry of the token obtained with AppRole, zero if it does not expire.
+	tokenExpiry time.Time
+	now         func() time.Time
+}
+
+func New(id secrets.ProviderID, settings Settings, client *http.Client) *Provider {
+	return &Provider{
+		settings: settings,
+		client:   client,
+		log:      log.New("kmsproviders.hashicorpvault", "provider", id),
+		token:    settings.Token,
+		now:      time.Now,
+	}
+}
+
+// NewFromConfig reads the settings of the provider with the given identifier and returns it.
+func NewFromConfig(cfg *setting.Cfg, id secrets.ProviderID) (*Provider, error) {
+	settings, err := ReadSettings(cfg, id)
+	if err != nil {
+		return nil, err
+	}
+	return New(id, settings, &http.Client{Timeout: 30 * time.Second}), nil
+}
+
+func (p *Provider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	err := p.do(ctx, http.MethodPost, p.transitPath("encrypt", p.settings.KeyRing), map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(blob),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with key %s: %w", p.settings.KeyRing, err)
+	}
+	return []byte(resp.Ciphertext), nil
+}
+
+func (p *Provider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	version, err := KeyVersion(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Plaintext string `json:"plaintext"`
+	}
+	err = p.do(ctx, http.MethodPost, p.transitPath("decrypt", p.settings.KeyRing), map[string]string{
+		"ciphertext": string(blob),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with version %d of key %s: %w", version, p.settings.KeyRing, err)
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Plaintext)
+}
+
+// RotateKey creates a new version of the transit key, used for all the
+// following encryptions, when rotate_key_on_data_keys_rotation is enabled.
+func (p *Provider) RotateKey(ctx context.Context) error {
+	if !p.settings.RotateKeyOnDataKeysRotation {
+		return nil
+	}
+
+	if err := p.do(ctx, http.MethodPost, p.transitPath("keys", p.settings.KeyRing, "rotate"), nil, nil); err != nil {
+		return fmt.Errorf("failed to rotate key %s: %w", p.settings.KeyRing, err)
+	}
+
+	p.log.Info("Rotated transit key", "key", p.settings.KeyRing)
+	return nil
+}
+
+// Run renews the token periodically, so periodic service tokens do not
+// expire. When AppRole is used, it logs in again once the renewal fails.
+func (p *Provider) Run(ctx context.Context) error {
+	if p.settings.TokenRenewalInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.settings.TokenRenewalInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.renewToken(ctx); err != nil {
+				p.log.Error("Failed to renew token", "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *Provider) renewToken(ctx context.Context) error {
+	err := p.do(ctx, http.MethodPost, "auth/token/renew-self", nil, nil)
+	if err == nil || !p.settings.useAppRole() {
+		return err
+	}
+
+	p.log.Debug("Failed to renew token, logging in again", "error", err)
+	p.resetToken()
+	_, err = p.currentToken(ctx)
+	return err
+}
+
+func (p *Provider) transitPath(elem ...string) string {
+	return path.Join(append([]string{p.settings.TransitEnginePath}, elem...)...)
+}
+
+// KeyVersion returns the version of the transit key used to encrypt the ciphertext.
+func KeyVersion(ciphertext []byte) (int, error) {
+	parts := strings.SplitN(string(ciphertext), ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		return 0, errors.New("malformed ciphertext: expected format vault:v<version>:<ciphertext>")
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("malformed ciphertext: invalid key version %q", parts[1])
+	}
+
+	return version, nil
+}
+
+// currentToken returns the token used to authenticate within Vault, and logs in with AppRole when needed.
+func (p *Provider) currentToken(ctx context.Context) (string, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.settings.useAppRole() {
+		return p.token, nil
+	}
+
+	if p.token != "" && (p.tokenExpiry.IsZero() || p.now().Before(p.tokenExpiry)) {
+		return p.token, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	err := p.send(ctx, http.MethodPost, path.Join("auth", p.settings.AppRoleMountPath, "login"), "", map[string]string{
+		"role_id":   p.settings.AppRoleRoleID,
+		"secret_id": p.settings.AppRoleSecretID,
+	}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in with AppRole: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("failed to log in with AppRole: no token returned")
+	}
+
+	p.token = resp.Auth.ClientToken
+	p.tokenExpiry = time.Time{}
+	if resp.Auth.LeaseDuration > 0 {
+		// Log in again slightly before the token expires, so in-flight requests do not fail.
+		lease := time.Duration(resp.Auth.LeaseDuration) * time.Second
+		p.tokenExpiry = p.now().Add(lease - lease/10)
+	}
+
+	return p.token, nil
+}
+
+func (p *Provider) resetToken() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.token = ""
+}
+
+// do sends an authenticated request to Vault. When AppRole is used, a request
+// rejected because of an expired or revoked token is retried after logging in again.
+func (p *Provider) do(ctx context.Context, method, apiPath string, body any, out any) error {
+	token, err := p.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = p.send(ctx, method, apiPath, token, body, out)
+	var respErr *responseError
+	if p.settings.useAppRole() && errors.As(err, &respErr) && respErr.statusCode == http.StatusForbidden {
+		p.resetToken()
+		if token, err = p.currentToken(ctx); err != nil {
+			return err
+		}
+		err = p.send(ctx, method, apiPath, token, body, out)
+	}
+
+	return err
+}
+
+type responseError struct {
+	statusCode int
+	errors     []string
+}
+
+func (e *responseError) Error() string {
+	if len(e.errors) == 0 {
+		return fmt.Sprintf("vault responded with status %d", e.statusCode)
+	}
+	return fmt.Sprintf("vault responded with status %d: %s", e.statusCode, strings.Join(e.errors, ", "))
+}
+
+func (p *Provider) send(ctx context.Context, method, apiPath, token string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.settings.URL+"/v1/"+apiPath, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.settings.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.settings.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			p.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		respErr := &responseError{statusCode: resp.StatusCode}
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil {
+			respErr.errors = errResp.Errors
+		}
+		return respErr
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	// The login endpoint returns the token in the auth field, the other ones in the data field.
+	if strings.HasPrefix(apiPath, "auth/") {
+		return json.Unmarshal(respBody, out)
+	}
+
+	var dataResp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &dataResp); err != nil {
+		return err
+	}
+	return json.Unmarshal(dataResp.Data, out)
+}
@@ -0,0 +1,323 @@
+package vaultprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeTransit is a stand-in for the AppRole, token and transit APIs of Vault.
+// Its ciphertexts embed the key version, like the ones of Vault.
+type fakeTransit struct {
+	mtx                  sync.Mutex
+	namespace            string
+	tokens               map[string]bool
+	latestVersion        int
+	minDecryptionVersion int
+	logins               int
+	renewals             int
+}
+
+func newFakeTransit(tokens ...string) *fakeTransit {
+	f := &fakeTransit{
+		tokens:               map[string]bool{},
+		latestVersion:        1,
+		minDecryptionVersion: 1,
+	}
+	for _, token := range tokens {
+		f.tokens[token] = true
+	}
+	return f
+}
+
+// locked runs fn with the lock of the stand-in held, so tests can read and update its state.
+func (f *fakeTransit) locked(fn func()) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	fn()
+}
+
+func (f *fakeTransit) revokeTokens() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.tokens = map[string]bool{}
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	if r.Header.Get("X-Vault-Namespace") != f.namespace {
+		respondErrors(w, http.StatusForbidden, "namespace not authorized")
+		return
+	}
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			respondErrors(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		f.logins++
+		token := "approle-token-" + strconv.Itoa(f.logins)
+		f.tokens[token] = true
+		respond(w, map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 3600}})
+		return
+	}
+
+	if !f.tokens[r.Header.Get("X-Vault-Token")] {
+		respondErrors(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/auth/token/renew-self":
+		f.renewals++
+		respond(w, map[string]any{"auth": map[string]any{"client_token": r.Header.Get("X-Vault-Token")}})
+	case "/v1/transit/keys/grafana/rotate":
+		f.latestVersion++
+		w.WriteHeader(http.StatusNoContent)
+	case "/v1/transit/encrypt/grafana":
+		inner := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", f.latestVersion, body["plaintext"])))
+		respond(w, map[string]any{"data": map[string]any{
+			"ciphertext":  fmt.Sprintf("vault:v%d:%s", f.latestVersion, inner),
+			"key_version": f.latestVersion,
+		}})
+	case "/v1/transit/decrypt/grafana":
+		parts := strings.SplitN(body["ciphertext"], ":", 3)
+		version, _ := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+		if version < f.minDecryptionVersion {
+			respondErrors(w, http.StatusBadRequest, "ciphertext or signature version is disallowed by policy (too old)")
+			return
+		}
+		inner, _ := base64.StdEncoding.DecodeString(parts[2])
+		innerVersion, plaintext, _ := strings.Cut(string(inner), ":")
+		if innerVersion != strconv.Itoa(version) {
+			respondErrors(w, http.StatusBadRequest, "invalid ciphertext: unable to decrypt")
+			return
+		}
+		respond(w, map[string]any{"data": map[string]any{"plaintext": plaintext}})
+	default:
+		respondErrors(w, http.StatusNotFound)
+	}
+}
+
+func respond(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func respondErrors(w http.ResponseWriter, status int, errs ...string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"errors": errs})
+}
+
+func setupProvider(t *testing.T, transit *fakeTransit, settings Settings) *Provider {
+	t.Helper()
+
+	server := httptest.NewServer(transit)
+	t.Cleanup(server.Close)
+
+	settings.URL = server.URL
+	settings.TransitEnginePath = "transit"
+	settings.AppRoleMountPath = "approle"
+	settings.KeyRing = "grafana"
+	return New("hashicorpvault.v1", settings, server.Client())
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should encrypt and decrypt with a token", func(t *testing.T) {
+		transit := newFakeTransit("token")
+		transit.namespace = "grafana"
+		p := setupProvider(t, transit, Settings{Token: "token", Namespace: "grafana"})
+
+		encrypted, err := p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		version, err := KeyVersion(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		decrypted, err := p.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("Should fail with an invalid token", func(t *testing.T) {
+		p := setupProvider(t, newFakeTransit("token"), Settings{Token: "invalid"})
+
+		_, err := p.Encrypt(ctx, []byte("data key"))
+		require.ErrorContains(t, err, "vault responded with status 403: permission denied")
+	})
+
+	t.Run("Should decrypt the data keys encrypted with previous key versions", func(t *testing.T) {
+		transit := newFakeTransit("token")
+		p := setupProvider(t, transit, Settings{Token: "token", RotateKeyOnDataKeysRotation: true})
+
+		v1, err := p.Encrypt(ctx, []byte("v1 data key"))
+		require.NoError(t, err)
+
+		require.NoError(t, p.RotateKey(ctx))
+		v2, err := p.Encrypt(ctx, []byte("v2 data key"))
+		require.NoError(t, err)
+		version, err := KeyVersion(v2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+
+		decrypted, err := p.Decrypt(ctx, v1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v1 data key"), decrypted)
+		decrypted, err = p.Decrypt(ctx, v2)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v2 data key"), decrypted)
+
+		// data keys must be re-encrypted before the previous versions are disallowed
+		transit.locked(func() { transit.minDecryptionVersion = 2 })
+		_, err = p.Decrypt(ctx, v1)
+		require.ErrorContains(t, err, "failed to decrypt with version 1 of key grafana")
+	})
+
+	t.Run("Should not rotate the key unless enabled", func(t *testing.T) {
+		transit := newFakeTransit("token")
+		p := setupProvider(t, transit, Settings{Token: "token"})
+
+		require.NoError(t, p.RotateKey(ctx))
+		transit.locked(func() { assert.Equal(t, 1, transit.latestVersion) })
+	})
+
+	t.Run("Should log in with AppRole and log in again once the token is revoked", func(t *testing.T) {
+		transit := newFakeTransit()
+		p := setupProvider(t, transit, Settings{AppRoleRoleID: "role", AppRoleSecretID: "secret"})
+
+		encrypted, err := p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		transit.locked(func() { assert.Equal(t, 1, transit.logins) })
+
+		transit.revokeTokens()
+		decrypted, err := p.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+		transit.locked(func() { assert.Equal(t, 2, transit.logins) })
+	})
+
+	t.Run("Should log in with AppRole again once the token expires", func(t *testing.T) {
+		transit := newFakeTransit()
+		p := setupProvider(t, transit, Settings{AppRoleRoleID: "role", AppRoleSecretID: "secret"})
+		now := time.Now()
+		p.now = func() time.Time { return now }
+
+		_, err := p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		now = now.Add(time.Hour)
+		_, err = p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		transit.locked(func() { assert.Equal(t, 2, transit.logins) })
+	})
+
+	t.Run("Should fail to log in with an invalid AppRole secret id", func(t *testing.T) {
+		p := setupProvider(t, newFakeTransit(), Settings{AppRoleRoleID: "role", AppRoleSecretID: "invalid"})
+
+		_, err := p.Encrypt(ctx, []byte("data key"))
+		require.ErrorContains(t, err, "failed to log in with AppRole")
+	})
+
+	t.Run("Should renew the token periodically", func(t *testing.T) {
+		transit := newFakeTransit("token")
+		p := setupProvider(t, transit, Settings{Token: "token", TokenRenewalInterval: time.Millisecond})
+
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() {
+			done <- p.Run(ctx)
+		}()
+
+		require.Eventually(t, func() bool {
+			var renewals int
+			transit.locked(func() { renewals = transit.renewals })
+			return renewals > 1
+		}, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func TestKeyVersion(t *testing.T) {
+	version, err := KeyVersion([]byte("vault:v12:abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, version)
+
+	for _, ciphertext := range []string{"", "abcd", "vault:abcd", "vault:12:abcd", "vault:v0:abcd", "vault:vx:abcd"} {
+		_, err := KeyVersion([]byte(ciphertext))
+		assert.Error(t, err, ciphertext)
+	}
+}
+
+func TestReadSettings(t *testing.T) {
+	readSettings := func(t *testing.T, rawCfg string) (Settings, error) {
+		raw, err := ini.Load([]byte(rawCfg))
+		require.NoError(t, err)
+		return ReadSettings(&setting.Cfg{Raw: raw}, secrets.ProviderID("hashicorpvault.v1"))
+	}
+
+	t.Run("Should read the settings with the defaults", func(t *testing.T) {
+		settings, err := readSettings(t, `
+		[security.encryption.hashicorpvault.v1]
+		url = https://vault.example.com/
+		approle_role_id = role
+		approle_secret_id = secret
+		key_ring = grafana
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, Settings{
+			URL:                  "https://vault.example.com",
+			AppRoleMountPath:     "approle",
+			AppRoleRoleID:        "role",
+			AppRoleSecretID:      "secret",
+			TransitEnginePath:    "transit",
+			KeyRing:              "grafana",
+			TokenRenewalInterval: 5 * time.Minute,
+		}, settings)
+	})
+
+	t.Run("Should require a key ring", func(t *testing.T) {
+		_, err := readSettings(t, `
+		[security.encryption.hashicorpvault.v1]
+		token = token
+		`)
+		require.ErrorContains(t, err, "missing key_ring")
+	})
+
+	t.Run("Should require exactly one authentication method", func(t *testing.T) {
+		_, err := readSettings(t, `
+		[security.encryption.hashicorpvault.v1]
+		key_ring = grafana
+		`)
+		require.ErrorContains(t, err, "missing token or approle_role_id")
+
+		_, err = readSettings(t, `
+		[security.encryption.hashicorpvault.v1]
+		key_ring = grafana
+		token = token
+		approle_role_id = role
+		`)
+		require.ErrorContains(t, err, "mutually exclusive")
+	})
+}
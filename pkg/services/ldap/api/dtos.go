@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/ldapsync"
 	"github.com/grafana/grafana/pkg/services/org"
 )
 
@@ -52,3 +53,9 @@ type LDAPServerDTO struct {
 	Available bool   `json:"available"`
 	Error     string `json:"error"`
 }
+
+// swagger:response getLDAPSyncStatusResponse
+type GetLDAPSyncStatusResponse struct {
+	// in:body
+	Body ldapsync.Status `json:"body"`
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/ldapsync"
 	"github.com/grafana/grafana/pkg/services/ldap/multildap"
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -37,6 +38,7 @@ type Service struct {
 	sessionService       auth.UserTokenService
 	log                  log.Logger
 	ldapService          service.LDAP
+	ldapSyncService      ldapsync.Service
 	identitySynchronizer authn.IdentitySynchronizer
 }
 
@@ -44,7 +46,7 @@ func ProvideService(
 	cfg *setting.Cfg, router routing.RouteRegister, accessControl ac.AccessControl,
 	userService user.Service, authInfoService login.AuthInfoService, ldapGroupsService ldap.Groups,
 	identitySynchronizer authn.IdentitySynchronizer, orgService org.Service, ldapService service.LDAP,
-	sessionService auth.UserTokenService, bundleRegistry supportbundles.Service,
+	sessionService auth.UserTokenService, bundleRegistry supportbundles.Service, ldapSyncService ldapsync.Service,
 ) *Service {
 	s := &Service{
 		cfg:                  cfg,
@@ -54,6 +56,7 @@ func ProvideService(
 		orgService:           orgService,
 		sessionService:       sessionService,
 		ldapService:          ldapService,
+		ldapSyncService:      ldapSyncService,
 		log:                  log.New("ldap.api"),
 		identitySynchronizer: identitySynchronizer,
 	}
@@ -62,7 +65,9 @@ func ProvideService(
 
 	router.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Post("/ldap/reload", authorize(ac.EvalPermission(ac.ActionLDAPConfigReload)), routing.Wrap(s.ReloadLDAPCfg))
+		adminRoute.Post("/ldap/sync", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.PostSyncLDAP))
 		adminRoute.Post("/ldap/sync/:id", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.PostSyncUserWithLDAP))
+		adminRoute.Get("/ldap/sync-status", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.GetLDAPSyncStatus))
 		adminRoute.Get("/ldap/:username", authorize(ac.EvalPermission(ac.ActionLDAPUsersRead)), routing.Wrap(s.GetUserFromLDAP))
 		adminRoute.Get("/ldap/status", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.GetLDAPStatus))
 	}, middleware.ReqSignedIn)
@@ -154,6 +159,72 @@ func (s *Service) GetLDAPStatus(c *contextmodel.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, serverDTOs)
 }
 
+// swagger:route GET /admin/ldap/sync-status admin_ldap getLDAPSyncStatus
+//
+// Returns the schedule of the background LDAP synchronization and the result of the previous synchronization.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.status:read`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getLDAPSyncStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetLDAPSyncStatus(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.LDAPAuthEnabled {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	status, err := s.ldapSyncService.Status(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the LDAP synchronization status", err)
+	}
+
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /admin/ldap/sync admin_ldap postSyncLDAP
+//
+// Starts the synchronization of all the LDAP users in the background. Returns 409 when a synchronization is
+// already running, or started recently on any instance. Team memberships are only synchronized in Grafana Enterprise.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.user:sync`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) PostSyncLDAP(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.LDAPAuthEnabled {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	if err := s.ldapSyncService.Trigger(); err != nil {
+		if errors.Is(err, ldapsync.ErrSyncDisabled) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		if errors.Is(err, ldapsync.ErrSyncAlreadyRunning) || errors.Is(err, ldapsync.ErrSyncRecentlyRun) {
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to start the LDAP synchronization", err)
+	}
+
+	message := "LDAP synchronization started"
+	if !s.cfg.IsEnterprise {
+		message += ", team memberships are only synchronized in Grafana Enterprise"
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": message})
+}
+
 // swagger:route POST /admin/ldap/sync/{user_id} admin_ldap postSyncUserWithLDAP
 //
 // Enables a single Grafana user to be synchronized against LDAP.
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/ldapsync"
 	"github.com/grafana/grafana/pkg/services/ldap/multildap"
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -74,6 +76,7 @@ func setupAPITest(t *testing.T, opts ...func(a *Service)) (*Service, *webtest.Se
 		service.NewLDAPFakeService(),
 		authtest.NewFakeUserAuthTokenService(),
 		supportbundlestest.NewFakeBundleService(),
+		ldapsync.NewFakeService(),
 	)
 
 	for _, o := range opts {
@@ -537,6 +540,93 @@ func TestPostSyncUserWithLDAPAPIEndpoint_WhenUserNotInLDAP(t *testing.T) {
 	assert.JSONEq(t, expected, string(bodyBytes))
 }
 
+func TestGetLDAPSyncStatusAPIEndpoint(t *testing.T) {
+	started := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+	next := started.Add(24 * time.Hour)
+
+	_, server := setupAPITest(t, func(a *Service) {
+		a.ldapSyncService = &ldapsync.FakeService{ExpectedStatus: &ldapsync.Status{
+			Enabled:  true,
+			Schedule: "0 1 * * *",
+			NextSync: &next,
+			PrevSync: &ldapsync.SyncResult{
+				Started:       started,
+				Elapsed:       "2s",
+				Synced:        10,
+				DisabledUsers: []ldapsync.UserResult{{UserID: 3, Login: "ldap-gone"}},
+				FailedUsers:   []ldapsync.UserResult{},
+			},
+		}}
+	})
+
+	req := server.NewGetRequest("/api/admin/ldap/sync-status")
+	webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {"ldap.status:read": {}}},
+	})
+
+	res, err := server.Send(req)
+	defer func() { require.NoError(t, res.Body.Close()) }()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	expected := `
+	{
+		"enabled": true,
+		"schedule": "0 1 * * *",
+		"nextSync": "2024-05-02T01:00:00Z",
+		"running": false,
+		"prevSync": {
+			"started": "2024-05-01T01:00:00Z",
+			"elapsed": "2s",
+			"synced": 10,
+			"disabledUsers": [{ "userId": 3, "login": "ldap-gone" }],
+			"failedUsers": []
+		}
+	}
+	`
+
+	bodyBytes, _ := io.ReadAll(res.Body)
+	assert.JSONEq(t, expected, string(bodyBytes))
+}
+
+func TestPostSyncLDAPAPIEndpoint(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		triggerErr   error
+		expectedCode int
+	}{
+		{desc: "should start the synchronization", expectedCode: http.StatusAccepted},
+		{desc: "should return 400 when the synchronization is disabled", triggerErr: ldapsync.ErrSyncDisabled, expectedCode: http.StatusBadRequest},
+		{desc: "should return 409 when the synchronization is running", triggerErr: ldapsync.ErrSyncAlreadyRunning, expectedCode: http.StatusConflict},
+		{desc: "should return 409 when the synchronization started recently", triggerErr: ldapsync.ErrSyncRecentlyRun, expectedCode: http.StatusConflict},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			syncService := &ldapsync.FakeService{ExpectedError: tc.triggerErr}
+			_, server := setupAPITest(t, func(a *Service) {
+				a.ldapSyncService = syncService
+			})
+
+			req := server.NewPostRequest("/api/admin/ldap/sync", nil)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID: 1,
+				Permissions: map[int64]map[string][]string{
+					1: {"ldap.user:sync": {}}},
+			})
+
+			res, err := server.Send(req)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+			assert.True(t, syncService.TriggerCalled)
+		})
+	}
+}
+
 func TestLDAP_AccessControl(t *testing.T) {
 	f, errC := os.CreateTemp("", "ldap.toml")
 	require.NoError(t, errC)
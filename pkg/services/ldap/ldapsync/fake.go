@@ -0,0 +1,22 @@
+package ldapsync
+
+import "context"
+
+type FakeService struct {
+	ExpectedStatus *Status
+	ExpectedError  error
+	TriggerCalled  bool
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (s *FakeService) Status(ctx context.Context) (*Status, error) {
+	return s.ExpectedStatus, s.ExpectedError
+}
+
+func (s *FakeService) Trigger() error {
+	s.TriggerCalled = true
+	return s.ExpectedError
+}
@@ -0,0 +1,394 @@
+package ldapsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	lockName      = "ldap sync"
+	kvNamespace   = "ldap"
+	kvStatusKey   = "sync-status"
+	usersPerQuery = 100
+	// maxSyncDuration is the longest a synchronization can run.
+	maxSyncDuration = time.Hour
+)
+
+var (
+	ErrSyncDisabled       = errors.New("LDAP background synchronization is not enabled")
+	ErrSyncAlreadyRunning = errors.New("LDAP synchronization is already running")
+	// ErrSyncRecentlyRun is returned when a synchronization started less than the lock interval ago, on any instance.
+	ErrSyncRecentlyRun = errors.New("LDAP synchronization started recently, try again later")
+)
+
+// Service synchronizes the users that authenticated with LDAP with the directory.
+type Service interface {
+	// Status returns the schedule of the synchronization and the result of the previous run.
+	Status(ctx context.Context) (*Status, error)
+	// Trigger starts a synchronization in the background. It fails when the synchronization can not start, because
+	// one is already running or started recently.
+	Trigger() error
+}
+
+// Status of the background synchronization.
+type Status struct {
+	Enabled  bool        `json:"enabled"`
+	Schedule string      `json:"schedule"`
+	NextSync *time.Time  `json:"nextSync,omitempty"`
+	Running  bool        `json:"running"`
+	PrevSync *SyncResult `json:"prevSync,omitempty"`
+}
+
+// SyncResult is the result of a synchronization.
+type SyncResult struct {
+	Started time.Time `json:"started"`
+	Elapsed string    `json:"elapsed"`
+	// Synced is the number of users found in the directory whose attributes and roles were updated.
+	Synced        int          `json:"synced"`
+	DisabledUsers []UserResult `json:"disabledUsers"`
+	FailedUsers   []UserResult `json:"failedUsers"`
+	// Error is set when the synchronization could not run, for example because a LDAP server is unavailable.
+	Error string `json:"error,omitempty"`
+}
+
+type UserResult struct {
+	UserID int64  `json:"userId"`
+	Login  string `json:"login"`
+	Error  string `json:"error,omitempty"`
+}
+
+type serverLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+type SyncImpl struct {
+	cfg                  *setting.Cfg
+	ldapService          service.LDAP
+	authInfoService      login.AuthInfoService
+	userService          user.Service
+	identitySynchronizer authn.IdentitySynchronizer
+	sessionService       auth.UserTokenService
+	serverLock           serverLock
+	kv                   *kvstore.NamespacedKVStore
+	log                  log.Logger
+	now                  func() time.Time
+
+	schedule cron.Schedule
+	// lockInterval is the interval of the server lock of the synchronizations, and their timeout.
+	lockInterval time.Duration
+	running      atomic.Bool
+}
+
+func ProvideService(
+	cfg *setting.Cfg, ldapService service.LDAP, authInfoService login.AuthInfoService, userService user.Service,
+	identitySynchronizer authn.IdentitySynchronizer, sessionService auth.UserTokenService,
+	serverLockService *serverlock.ServerLockService, kv kvstore.KVStore,
+) *SyncImpl {
+	s := &SyncImpl{
+		cfg:                  cfg,
+		ldapService:          ldapService,
+		authInfoService:      authInfoService,
+		userService:          userService,
+		identitySynchronizer: identitySynchronizer,
+		sessionService:       sessionService,
+		serverLock:           serverLockService,
+		kv:                   kvstore.WithNamespace(kv, 0, kvNamespace),
+		log:                  log.New("ldap.sync"),
+		now:                  time.Now,
+	}
+
+	if cfg.LDAPAuthEnabled && cfg.LDAPActiveSyncEnabled {
+		schedule, err := cron.ParseStandard(cfg.LDAPSyncCron)
+		if err != nil {
+			s.log.Error("Invalid LDAP sync schedule, background synchronization is disabled", "schedule", cfg.LDAPSyncCron, "error", err)
+		} else {
+			s.schedule = schedule
+			s.lockInterval = lockInterval(schedule, s.now())
+		}
+	}
+
+	return s
+}
+
+// lockInterval returns half of the shortest time between two of the next scheduled synchronizations, so the
+// instances that wake up a bit later do not run the synchronization again, limited to maxSyncDuration.
+func lockInterval(schedule cron.Schedule, now time.Time) time.Duration {
+	interval := maxSyncDuration
+	prev := schedule.Next(now)
+	for i := 0; i < 10; i++ {
+		next := schedule.Next(prev)
+		interval = min(interval, next.Sub(prev)/2)
+		prev = next
+	}
+	return interval
+}
+
+func (s *SyncImpl) IsDisabled() bool {
+	return s.schedule == nil
+}
+
+func (s *SyncImpl) Run(ctx context.Context) error {
+	for {
+		next := s.schedule.Next(s.now())
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-timer.C:
+			err := s.lockAndSync(ctx, false)
+			switch {
+			case errors.Is(err, ErrSyncRecentlyRun):
+				s.log.Info("Skipping LDAP synchronization, a synchronization started recently", "interval", s.lockInterval)
+			case errors.Is(err, ErrSyncAlreadyRunning):
+				s.log.Warn("Skipping LDAP synchronization, a synchronization is already running")
+			case err != nil:
+				s.log.Error("Failed to lock and execute LDAP synchronization", "error", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// lockAndSync runs the synchronization on a single instance, unless a synchronization started less than the lock
+// interval ago. The synchronizations time out after the lock interval, so two of them never run at the same time,
+// whether they are scheduled or triggered. With background, the synchronization runs in a goroutine once the lock
+// is acquired.
+func (s *SyncImpl) lockAndSync(ctx context.Context, background bool) error {
+	syncErr := ErrSyncRecentlyRun
+	err := s.serverLock.LockAndExecute(ctx, lockName, s.lockInterval, func(ctx context.Context) {
+		if !s.running.CompareAndSwap(false, true) {
+			syncErr = ErrSyncAlreadyRunning
+			return
+		}
+		syncErr = nil
+
+		run := func(ctx context.Context) {
+			defer s.running.Store(false)
+			ctx, cancel := context.WithTimeout(ctx, s.lockInterval)
+			defer cancel()
+			s.sync(ctx)
+		}
+		if background {
+			go run(context.WithoutCancel(ctx))
+			return
+		}
+		run(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// Trigger acquires the server lock and starts the synchronization in the background.
+func (s *SyncImpl) Trigger() error {
+	if s.IsDisabled() {
+		return ErrSyncDisabled
+	}
+	if s.running.Load() {
+		return ErrSyncAlreadyRunning
+	}
+
+	return s.lockAndSync(context.Background(), true)
+}
+
+func (s *SyncImpl) Status(ctx context.Context) (*Status, error) {
+	status := &Status{
+		Enabled:  !s.IsDisabled(),
+		Schedule: s.cfg.LDAPSyncCron,
+		Running:  s.running.Load(),
+	}
+	if status.Enabled {
+		next := s.schedule.Next(s.now())
+		status.NextSync = &next
+	}
+
+	value, ok, err := s.kv.Get(ctx, kvStatusKey)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		status.PrevSync = &SyncResult{}
+		if err := json.Unmarshal([]byte(value), status.PrevSync); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *SyncImpl) sync(ctx context.Context) {
+	result := s.Sync(ctx)
+	if result.Error != "" {
+		s.log.Error("LDAP synchronization failed", "error", result.Error, "elapsed", result.Elapsed)
+	} else {
+		s.log.Info("LDAP synchronization finished", "synced", result.Synced, "disabled", len(result.DisabledUsers), "failed", len(result.FailedUsers), "elapsed", result.Elapsed)
+	}
+
+	value, err := json.Marshal(result)
+	if err == nil {
+		// the result is saved even when the synchronization timed out
+		err = s.kv.Set(context.WithoutCancel(ctx), kvStatusKey, string(value))
+	}
+	if err != nil {
+		s.log.Error("Failed to save the result of the LDAP synchronization", "error", err)
+	}
+}
+
+// Sync updates the users that authenticated with LDAP with their attributes, roles and teams in the directory.
+// Users that are not found in the directory anymore, or that do not match any of the group mappings, are disabled
+// and logged out.
+func (s *SyncImpl) Sync(ctx context.Context) *SyncResult {
+	result := &SyncResult{Started: s.now(), DisabledUsers: []UserResult{}, FailedUsers: []UserResult{}}
+	defer func() {
+		result.Elapsed = s.now().Sub(result.Started).String()
+	}()
+
+	client := s.ldapService.Client()
+	if client == nil {
+		result.Error = service.ErrUnableToCreateLDAPClient.Error()
+		return result
+	}
+
+	// a server that cannot be reached returns no users, which would disable all the users of that server
+	statuses, err := client.Ping()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for _, status := range statuses {
+		if !status.Available {
+			result.Error = fmt.Sprintf("LDAP server %s:%d is unavailable: %v", status.Host, status.Port, status.Error)
+			return result
+		}
+	}
+
+	userIDs, err := s.authInfoService.GetUserIDsByAuthModule(ctx, &login.GetUserIDsByAuthModuleQuery{AuthModule: login.LDAPAuthModule})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for start := 0; start < len(userIDs); start += usersPerQuery {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err().Error()
+			return result
+		}
+
+		end := min(start+usersPerQuery, len(userIDs))
+		if err := s.syncUsers(ctx, userIDs[start:end], result); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	return result
+}
+
+func (s *SyncImpl) syncUsers(ctx context.Context, userIDs []int64, result *SyncResult) error {
+	users := make([]*user.User, 0, len(userIDs))
+	logins := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: id})
+		if errors.Is(err, user.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		users = append(users, usr)
+		logins = append(logins, usr.Login)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	infos, err := s.ldapService.Client().Users(logins)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]*login.ExternalUserInfo, len(infos))
+	for _, info := range infos {
+		key := strings.ToLower(info.Login)
+		// users found on several servers are synced with the first one, like at login
+		if _, ok := found[key]; !ok {
+			found[key] = info
+		}
+	}
+
+	for _, usr := range users {
+		info, ok := found[strings.ToLower(usr.Login)]
+		if !ok || info.IsDisabled {
+			if usr.IsDisabled {
+				continue
+			}
+			reason := "User was removed from the LDAP directory, disabling it"
+			if ok {
+				reason = "User does not match any LDAP group mapping anymore, disabling it"
+			}
+			if err := s.disableUser(ctx, usr, reason); err != nil {
+				result.FailedUsers = append(result.FailedUsers, UserResult{UserID: usr.ID, Login: usr.Login, Error: err.Error()})
+				continue
+			}
+			result.DisabledUsers = append(result.DisabledUsers, UserResult{UserID: usr.ID, Login: usr.Login})
+			continue
+		}
+
+		if err := s.identitySynchronizer.SyncIdentity(ctx, s.identityFromLDAPUser(info)); err != nil {
+			s.log.Warn("Failed to sync user with LDAP", "userID", usr.ID, "login", usr.Login, "error", err)
+			result.FailedUsers = append(result.FailedUsers, UserResult{UserID: usr.ID, Login: usr.Login, Error: err.Error()})
+			continue
+		}
+		result.Synced++
+	}
+	return nil
+}
+
+func (s *SyncImpl) disableUser(ctx context.Context, usr *user.User, reason string) error {
+	if s.cfg.AdminUser == usr.Login {
+		return fmt.Errorf("refusing to disable grafana super admin %q", usr.Login)
+	}
+
+	s.log.Info(reason, "userID", usr.ID, "login", usr.Login)
+	isDisabled := true
+	if err := s.userService.Update(ctx, &user.UpdateUserCommand{UserID: usr.ID, IsDisabled: &isDisabled}); err != nil {
+		return err
+	}
+	return s.sessionService.RevokeAllUserTokens(ctx, usr.ID)
+}
+
+func (s *SyncImpl) identityFromLDAPUser(info *login.ExternalUserInfo) *authn.Identity {
+	return &authn.Identity{
+		OrgRoles:        info.OrgRoles,
+		Login:           info.Login,
+		Name:            info.Name,
+		Email:           info.Email,
+		IsGrafanaAdmin:  info.IsGrafanaAdmin,
+		AuthenticatedBy: info.AuthModule,
+		AuthID:          info.AuthId,
+		Groups:          info.Groups,
+		ClientParams: authn.ClientParams{
+			SyncUser: true,
+			// team sync is only available in Grafana Enterprise
+			SyncTeams: true,
+			// users that do not match any group mapping are disabled, like at login
+			EnableUser:   !info.IsDisabled,
+			SyncOrgRoles: !s.cfg.LDAPSkipOrgRoleSync,
+		},
+	}
+}
@@ -0,0 +1,301 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeUserService struct {
+	*usertest.FakeUserService
+	users    map[int64]*user.User
+	disabled []int64
+}
+
+func (f *fakeUserService) GetByID(ctx context.Context, query *user.GetUserByIDQuery) (*user.User, error) {
+	if u, ok := f.users[query.ID]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (f *fakeUserService) Update(ctx context.Context, cmd *user.UpdateUserCommand) error {
+	if cmd.IsDisabled != nil && *cmd.IsDisabled {
+		f.disabled = append(f.disabled, cmd.UserID)
+	}
+	return nil
+}
+
+type fakeSynchronizer struct {
+	identities []*authn.Identity
+	errs       map[string]error
+}
+
+func (f *fakeSynchronizer) SyncIdentity(ctx context.Context, identity *authn.Identity) error {
+	f.identities = append(f.identities, identity)
+	return f.errs[identity.Login]
+}
+
+type fakeServerLock struct {
+	maxInterval time.Duration
+	// locked skips the functions, like when a synchronization started less than maxInterval ago
+	locked bool
+	calls  atomic.Int32
+}
+
+func (f *fakeServerLock) LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
+	f.maxInterval = maxInterval
+	defer f.calls.Add(1)
+	if !f.locked {
+		fn(ctx)
+	}
+	return nil
+}
+
+type syncEnv struct {
+	s            *SyncImpl
+	client       *multildap.MultiLDAPmock
+	users        *fakeUserService
+	synchronizer *fakeSynchronizer
+	revoked      []int64
+}
+
+func setupSyncTest(t *testing.T, userIDs []int64, users map[int64]*user.User, ldapUsers []*login.ExternalUserInfo) *syncEnv {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.LDAPAuthEnabled = true
+	cfg.LDAPActiveSyncEnabled = true
+	cfg.LDAPSyncCron = "0 1 * * *"
+	cfg.AdminUser = "admin"
+
+	env := &syncEnv{
+		client: &multildap.MultiLDAPmock{
+			ExpectedUsers:    ldapUsers,
+			ExpectedStatuses: []*multildap.ServerStatus{{Host: "127.0.0.1", Port: 389, Available: true}},
+		},
+		users:        &fakeUserService{FakeUserService: usertest.NewUserServiceFake(), users: users},
+		synchronizer: &fakeSynchronizer{errs: map[string]error{}},
+	}
+	sessionService := authtest.NewFakeUserAuthTokenService()
+	sessionService.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+		env.revoked = append(env.revoked, userID)
+		return nil
+	}
+
+	env.s = ProvideService(
+		cfg,
+		&service.LDAPFakeService{ExpectedClient: env.client},
+		&authinfotest.FakeService{ExpectedUserIDs: userIDs},
+		env.users,
+		env.synchronizer,
+		sessionService,
+		nil,
+		kvstore.NewFakeKVStore(),
+	)
+	env.s.serverLock = &fakeServerLock{}
+	env.s.log = log.NewNopLogger()
+	return env
+}
+
+func TestSync(t *testing.T) {
+	users := map[int64]*user.User{
+		1: {ID: 1, Login: "ldap-daniel"},
+		2: {ID: 2, Login: "ldap-removed"},
+		3: {ID: 3, Login: "ldap-already-disabled", IsDisabled: true},
+		4: {ID: 4, Login: "admin"},
+		5: {ID: 5, Login: "LDAP-Torkel"},
+	}
+	ldapUsers := []*login.ExternalUserInfo{
+		{Login: "ldap-daniel", AuthModule: login.LDAPAuthModule, AuthId: "cn=daniel", OrgRoles: map[int64]org.RoleType{1: org.RoleEditor}},
+		{Login: "ldap-torkel", AuthModule: login.LDAPAuthModule, AuthId: "cn=torkel", Groups: []string{"cn=admins"}},
+	}
+
+	t.Run("should sync users found in the directory and disable the others", func(t *testing.T) {
+		// user 6 was deleted from Grafana
+		env := setupSyncTest(t, []int64{1, 2, 3, 4, 5, 6}, users, ldapUsers)
+
+		result := env.s.Sync(context.Background())
+
+		require.Empty(t, result.Error)
+		assert.Equal(t, 2, result.Synced)
+		assert.Equal(t, []UserResult{{UserID: 2, Login: "ldap-removed"}}, result.DisabledUsers)
+		require.Len(t, result.FailedUsers, 1)
+		assert.Equal(t, int64(4), result.FailedUsers[0].UserID)
+
+		assert.Equal(t, []int64{2}, env.users.disabled)
+		assert.Equal(t, []int64{2}, env.revoked)
+
+		require.Len(t, env.synchronizer.identities, 2)
+		daniel := env.synchronizer.identities[0]
+		assert.Equal(t, "cn=daniel", daniel.AuthID)
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleEditor}, daniel.OrgRoles)
+		assert.True(t, daniel.ClientParams.SyncUser)
+		assert.True(t, daniel.ClientParams.SyncTeams)
+		assert.True(t, daniel.ClientParams.SyncOrgRoles)
+		assert.True(t, daniel.ClientParams.EnableUser)
+		assert.Equal(t, []string{"cn=admins"}, env.synchronizer.identities[1].Groups)
+	})
+
+	t.Run("should disable users that do not match any group mapping anymore", func(t *testing.T) {
+		users := map[int64]*user.User{
+			1: {ID: 1, Login: "ldap-left-groups"},
+			2: {ID: 2, Login: "ldap-left-groups-disabled", IsDisabled: true},
+		}
+		ldapUsers := []*login.ExternalUserInfo{
+			{Login: "ldap-left-groups", AuthModule: login.LDAPAuthModule, AuthId: "cn=left", IsDisabled: true},
+			{Login: "ldap-left-groups-disabled", AuthModule: login.LDAPAuthModule, AuthId: "cn=left-disabled", IsDisabled: true},
+		}
+		env := setupSyncTest(t, []int64{1, 2}, users, ldapUsers)
+
+		result := env.s.Sync(context.Background())
+
+		require.Empty(t, result.Error)
+		assert.Equal(t, 0, result.Synced)
+		assert.Equal(t, []UserResult{{UserID: 1, Login: "ldap-left-groups"}}, result.DisabledUsers)
+		assert.Equal(t, []int64{1}, env.users.disabled)
+		assert.Equal(t, []int64{1}, env.revoked)
+		// the users are not enabled again by the synchronization of their identity
+		assert.Empty(t, env.synchronizer.identities)
+	})
+
+	t.Run("should report users that failed to sync", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1, 5}, users, ldapUsers)
+		env.synchronizer.errs["ldap-daniel"] = errors.New("org not found")
+
+		result := env.s.Sync(context.Background())
+
+		require.Empty(t, result.Error)
+		assert.Equal(t, 1, result.Synced)
+		assert.Equal(t, []UserResult{{UserID: 1, Login: "ldap-daniel", Error: "org not found"}}, result.FailedUsers)
+	})
+
+	t.Run("should not disable users when a server is unavailable", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1, 2}, users, ldapUsers)
+		env.client.ExpectedStatuses = append(env.client.ExpectedStatuses, &multildap.ServerStatus{Host: "127.0.0.2", Port: 389, Error: errors.New("connection refused")})
+
+		result := env.s.Sync(context.Background())
+
+		assert.Contains(t, result.Error, "127.0.0.2:389 is unavailable")
+		assert.False(t, env.client.UsersCalled)
+		assert.Empty(t, env.users.disabled)
+	})
+
+	t.Run("should not disable users when the search fails", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1, 2}, users, ldapUsers)
+		env.client.ExpectedErr = errors.New("search failed")
+
+		result := env.s.Sync(context.Background())
+
+		assert.Equal(t, "search failed", result.Error)
+		assert.Empty(t, env.users.disabled)
+		assert.Empty(t, env.synchronizer.identities)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("should be disabled without active sync", func(t *testing.T) {
+		env := setupSyncTest(t, nil, nil, nil)
+		env.s.schedule = nil
+
+		status, err := env.s.Status(context.Background())
+		require.NoError(t, err)
+		assert.False(t, status.Enabled)
+		assert.Nil(t, status.NextSync)
+		assert.Nil(t, status.PrevSync)
+		assert.ErrorIs(t, env.s.Trigger(), ErrSyncDisabled)
+	})
+
+	t.Run("should return the next run and the result of the scheduled run", func(t *testing.T) {
+		users := map[int64]*user.User{1: {ID: 1, Login: "ldap-removed"}}
+		env := setupSyncTest(t, []int64{1}, users, nil)
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		env.s.now = func() time.Time { return now }
+
+		require.NoError(t, env.s.lockAndSync(context.Background(), false))
+		assert.Equal(t, time.Hour, env.s.serverLock.(*fakeServerLock).maxInterval)
+
+		status, err := env.s.Status(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, "0 1 * * *", status.Schedule)
+		assert.Equal(t, env.s.schedule.Next(now), *status.NextSync)
+		require.NotNil(t, status.PrevSync)
+		assert.Equal(t, now, status.PrevSync.Started.UTC())
+		assert.Equal(t, []UserResult{{UserID: 1, Login: "ldap-removed"}}, status.PrevSync.DisabledUsers)
+	})
+}
+
+func TestTrigger(t *testing.T) {
+	users := map[int64]*user.User{1: {ID: 1, Login: "ldap-removed"}}
+
+	t.Run("should run the synchronization with the server lock", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1}, users, nil)
+		lock := &fakeServerLock{}
+		env.s.serverLock = lock
+
+		require.NoError(t, env.s.Trigger())
+		require.Eventually(t, func() bool {
+			status, err := env.s.Status(context.Background())
+			return err == nil && status.PrevSync != nil
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, time.Hour, lock.maxInterval)
+		assert.Equal(t, []int64{1}, env.users.disabled)
+	})
+
+	t.Run("should fail when a synchronization started recently", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1}, users, nil)
+		lock := &fakeServerLock{locked: true}
+		env.s.serverLock = lock
+
+		require.ErrorIs(t, env.s.Trigger(), ErrSyncRecentlyRun)
+		assert.Equal(t, int32(1), lock.calls.Load())
+
+		status, err := env.s.Status(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, status.PrevSync)
+		assert.Empty(t, env.users.disabled)
+	})
+
+	t.Run("should fail when a synchronization is already running", func(t *testing.T) {
+		env := setupSyncTest(t, []int64{1}, users, nil)
+		env.s.running.Store(true)
+
+		require.ErrorIs(t, env.s.Trigger(), ErrSyncAlreadyRunning)
+		assert.Empty(t, env.users.disabled)
+	})
+}
+
+func TestLockInterval(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for schedule, expected := range map[string]time.Duration{
+		"0 1 * * *":    time.Hour,
+		"*/10 * * * *": 5 * time.Minute,
+		"0,20 * * * *": 10 * time.Minute,
+		"@every 90m":   45 * time.Minute,
+		"*/30 * * * *": 15 * time.Minute,
+	} {
+		parsed, err := cron.ParseStandard(schedule)
+		require.NoError(t, err)
+		assert.Equal(t, expected, lockInterval(parsed, now), schedule)
+	}
+}
@@ -1,6 +1,8 @@
 package multildap
 
 import (
+	"strings"
+
 	"github.com/grafana/grafana/pkg/services/ldap"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -11,9 +13,15 @@ type MultiLDAPmock struct {
 	ID          int64
 	UserCalled  bool
 	LoginCalled bool
+	UsersCalled bool
 	UserInfo    *user.User
 	AuthModule  string
 	ExpectedErr error
+
+	// ExpectedUsers are the users of the directory returned by Users
+	ExpectedUsers []*login.ExternalUserInfo
+	// ExpectedStatuses are the server statuses returned by Ping
+	ExpectedStatuses []*ServerStatus
 }
 
 func (m *MultiLDAPmock) Login(query *login.LoginUserQuery) (
@@ -39,3 +47,28 @@ func (m *MultiLDAPmock) User(loginstr string) (
 	}
 	return result, ldap.ServerConfig{}, nil
 }
+
+func (m *MultiLDAPmock) Users(logins []string) (
+	[]*login.ExternalUserInfo,
+	error,
+) {
+	m.UsersCalled = true
+	if m.ExpectedErr != nil {
+		return nil, m.ExpectedErr
+	}
+
+	result := []*login.ExternalUserInfo{}
+	for _, u := range m.ExpectedUsers {
+		for _, l := range logins {
+			if strings.EqualFold(u.Login, l) {
+				result = append(result, u)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *MultiLDAPmock) Ping() ([]*ServerStatus, error) {
+	return m.ExpectedStatuses, nil
+}
@@ -0,0 +1,172 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	scenarioWithPanel(t, "When an admin patches a library panel, it should store the version history",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - New name", 1, "Renamed the panel")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			result := validateAndUnMarshalVersionsResponse(t, resp)
+
+			require.Len(t, result.Result, 2)
+			require.Equal(t, int64(2), result.Result[0].Version)
+			require.Equal(t, int64(1), result.Result[0].ParentVersion)
+			require.Equal(t, "Panel - New name", result.Result[0].Name)
+			require.Equal(t, "Renamed the panel", result.Result[0].Message)
+			require.Equal(t, userInDbName, result.Result[0].CreatedBy.Name)
+			require.Nil(t, result.Result[0].Model)
+			require.Equal(t, int64(1), result.Result[1].Version)
+			require.Equal(t, "Text - Library Panel", result.Result[1].Name)
+		})
+
+	scenarioWithPanel(t, "When an admin gets a version of a library panel, it should return its model",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - New name", 1, "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var result model.LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+
+			require.Equal(t, int64(1), result.Result.Version)
+			require.Equal(t, sc.initialResult.Result.UID, result.Result.ElementUID)
+			var versionModel map[string]any
+			require.NoError(t, json.Unmarshal(result.Result.Model, &versionModel))
+			require.Equal(t, "Text - Library Panel", versionModel["title"])
+		})
+
+	scenarioWithPanel(t, "When an admin gets a version of a library panel that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "5"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "latest"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin compares two versions of a library panel, it should return the diff",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - New name", 1, "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(model.CalculateLibraryElementDiffCommand{Base: 1, New: 2, DiffType: "delta"})
+			resp := sc.service.calculateDiffHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			require.Contains(t, string(resp.Body()), "Model - New name")
+
+			sc.reqContext.Req.Body = mockRequestBody(model.CalculateLibraryElementDiffCommand{Base: 1, New: 3})
+			resp = sc.service.calculateDiffHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel, it should save it as the latest version",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - New name", 1, "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(model.RestoreLibraryElementVersionCommand{Version: 1})
+			resp := sc.service.restoreVersionHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(3), result.Result.Version)
+			require.Equal(t, "Text - Library Panel", result.Result.Name)
+			require.Equal(t, "Text - Library Panel", result.Result.Model["title"])
+
+			resp = sc.service.getVersionsHandler(sc.reqContext)
+			versions := validateAndUnMarshalVersionsResponse(t, resp)
+			require.Len(t, versions.Result, 3)
+			require.Equal(t, int64(1), versions.Result[0].RestoredFrom)
+			require.Equal(t, "Restored from version 1", versions.Result[0].Message)
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(model.RestoreLibraryElementVersionCommand{Version: 2})
+			resp := sc.service.restoreVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When expired versions are deleted, it should keep the configured number of versions",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - Version 2", 1, "")
+			patchLibraryPanel(t, sc, "Panel - Version 3", 2, "")
+			sc.service.Cfg.LibraryPanelVersionsToKeep = 2
+
+			deleted, err := sc.service.DeleteExpiredVersions(sc.reqContext.Req.Context())
+			require.NoError(t, err)
+			require.Equal(t, int64(1), deleted)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			versions := validateAndUnMarshalVersionsResponse(t, resp)
+			require.Len(t, versions.Result, 2)
+			require.Equal(t, int64(3), versions.Result[0].Version)
+			require.Equal(t, int64(2), versions.Result[1].Version)
+		})
+
+	scenarioWithPanel(t, "When an admin deletes a library panel, it should delete its versions",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanel(t, sc, "Panel - New name", 1, "")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			err := sc.sqlStore.WithDbSession(sc.reqContext.Req.Context(), func(session *db.Session) error {
+				count, err := session.Table(model.LibraryElementVersionTableName).Count()
+				require.Equal(t, int64(0), count)
+				return err
+			})
+			require.NoError(t, err)
+		})
+}
+
+func patchLibraryPanel(t *testing.T, sc scenarioContext, name string, version int64, message string) {
+	t.Helper()
+
+	cmd := model.PatchLibraryElementCommand{
+		FolderID:  sc.folder.ID, // nolint:staticcheck
+		FolderUID: &sc.folder.UID,
+		Name:      name,
+		Model: []byte(`
+			{
+			  "datasource": "${DS_GDEV-TESTDATA}",
+			  "id": 1,
+			  "title": "Model - New name",
+			  "type": "text"
+			}
+		`),
+		Kind:    int64(model.PanelElement),
+		Version: version,
+		Message: message,
+	}
+	sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+	sc.reqContext.Req.Body = mockRequestBody(cmd)
+	resp := sc.service.patchHandler(sc.reqContext)
+	require.Equal(t, 200, resp.Status())
+}
+
+func validateAndUnMarshalVersionsResponse(t *testing.T, resp response.Response) model.LibraryElementVersionsResponse {
+	t.Helper()
+	require.Equal(t, 200, resp.Status())
+	var result = model.LibraryElementVersionsResponse{}
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+	return result
+}
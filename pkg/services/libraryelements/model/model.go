@@ -137,6 +137,56 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// LibraryElementVersion is the model for a revision of a library element.
+type LibraryElementVersion struct {
+	ID            int64 `xorm:"pk autoincr 'id'"`
+	OrgID         int64 `xorm:"org_id"`
+	ElementID     int64 `xorm:"element_id"`
+	ParentVersion int64
+	RestoredFrom  int64
+	Version       int64
+	Name          string
+	Model         json.RawMessage
+	Message       string
+
+	Created   time.Time
+	CreatedBy int64
+}
+
+// LibraryElementVersionWithMeta is the model used to retrieve library element versions with their author.
+type LibraryElementVersionWithMeta struct {
+	ID            int64 `xorm:"pk autoincr 'id'"`
+	OrgID         int64 `xorm:"org_id"`
+	ElementID     int64 `xorm:"element_id"`
+	ParentVersion int64
+	RestoredFrom  int64
+	Version       int64
+	Name          string
+	Model         json.RawMessage
+	Message       string
+
+	Created time.Time
+
+	CreatedBy      int64
+	CreatedByName  string
+	CreatedByEmail string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for library element versions.
+type LibraryElementVersionDTO struct {
+	ID            int64                                  `json:"id"`
+	ElementUID    string                                 `json:"elementUid"`
+	ParentVersion int64                                  `json:"parentVersion"`
+	RestoredFrom  int64                                  `json:"restoredFrom"`
+	Version       int64                                  `json:"version"`
+	Name          string                                 `json:"name"`
+	Message       string                                 `json:"message"`
+	Created       time.Time                              `json:"created"`
+	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+	// The JSON model of the library element at this version, omitted when listing versions.
+	Model json.RawMessage `json:"model,omitempty"`
+}
+
 var (
 	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
 	ErrLibraryElementAlreadyExists = errors.New("library element with that name or UID already exists")
@@ -156,6 +206,8 @@ var (
 	ErrLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	ErrLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementVersionNotFound is an error for when a library element version can't be found.
+	ErrLibraryElementVersionNotFound = errors.New("library element version could not be found")
 )
 
 // Commands
@@ -206,6 +258,33 @@ type PatchLibraryElementCommand struct {
 	Version int64 `json:"version" binding:"Required"`
 	// required: false
 	UID string `json:"uid"`
+	// Message describing the change, stored in the version history.
+	// required: false
+	Message string `json:"message"`
+}
+
+// RestoreLibraryElementVersionCommand is the command for restoring a previous version of a LibraryElement
+type RestoreLibraryElementVersionCommand struct {
+	// Version of the library element to restore.
+	Version int64 `json:"version" binding:"Required"`
+}
+
+// CalculateLibraryElementDiffCommand is the command for comparing two versions of a LibraryElement
+type CalculateLibraryElementDiffCommand struct {
+	// Version used as the base of the comparison.
+	Base int64 `json:"base" binding:"Required"`
+	// Version compared with the base.
+	New int64 `json:"new" binding:"Required"`
+	// Format of the diff.
+	// Enum: json,basic,delta
+	DiffType string `json:"diffType"`
+}
+
+// ListLibraryElementVersionsQuery is the query used for listing the versions of a library element.
+type ListLibraryElementVersionsQuery struct {
+	UID   string
+	Limit int
+	Start int
 }
 
 // GetLibraryElementCommand is the command for getting a library element.
@@ -245,6 +324,16 @@ type LibraryElementArrayResponse struct {
 	Result []LibraryElementDTO `json:"result"`
 }
 
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
 // LibraryElementConnectionsResponse is a response struct for an array of LibraryElementConnectionDTO.
 type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
@@ -267,3 +356,5 @@ const (
 )
 
 const LibraryElementConnectionTableName = "library_element_connection"
+
+const LibraryElementVersionTableName = "library_element_version"
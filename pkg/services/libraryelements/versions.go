@@ -0,0 +1,255 @@
+package libraryelements
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/kinds/librarypanel"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+const (
+	maxVersionsToDeletePerBatch = 100
+	maxVersionDeletionBatches   = 50
+)
+
+// insertLibraryElementVersion stores the current state of a library element in its version history.
+func insertLibraryElementVersion(session *db.Session, element model.LibraryElement, restoredFrom int64, message string) error {
+	version := model.LibraryElementVersion{
+		OrgID:         element.OrgID,
+		ElementID:     element.ID,
+		ParentVersion: element.Version - 1,
+		RestoredFrom:  restoredFrom,
+		Version:       element.Version,
+		Name:          element.Name,
+		Model:         element.Model,
+		Message:       message,
+		Created:       element.Updated,
+		CreatedBy:     element.UpdatedBy,
+	}
+	if _, err := session.Insert(&version); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *LibraryElementService) selectLibraryElementVersions(session *db.Session, elementID int64) *db.Session {
+	user := l.SQLStore.GetDialect().Quote("user")
+	return session.Table(model.LibraryElementVersionTableName).Alias("lev").
+		Select("lev.*, u.login AS created_by_name, u.email AS created_by_email").
+		Join("LEFT", user+" AS u", "lev.created_by = u.id").
+		Where("lev.element_id=?", elementID)
+}
+
+// getElementForVersions gets the library element the versions belong to and checks that the user can see it.
+func (l *LibraryElementService) getElementForVersions(c context.Context, session *db.Session, signedInUser identity.Requester, uid string) (model.LibraryElementWithMeta, error) {
+	element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+	if err != nil {
+		return model.LibraryElementWithMeta{}, err
+	}
+	// nolint:staticcheck
+	if err := l.requireViewPermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+		return model.LibraryElementWithMeta{}, err
+	}
+	return element, nil
+}
+
+// getLibraryElementVersions lists the versions of a library element, the most recent first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser identity.Requester, query model.ListLibraryElementVersionsQuery) ([]model.LibraryElementVersionDTO, error) {
+	if query.Limit == 0 {
+		query.Limit = 1000
+	}
+
+	versions := make([]model.LibraryElementVersionWithMeta, 0)
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := l.getElementForVersions(c, session, signedInUser, query.UID)
+		if err != nil {
+			return err
+		}
+		return l.selectLibraryElementVersions(session, element.ID).
+			OrderBy("lev.version DESC").
+			Limit(query.Limit, query.Start).
+			Find(&versions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.LibraryElementVersionDTO, 0, len(versions))
+	for _, version := range versions {
+		dto := l.toLibraryElementVersionDTO(query.UID, version)
+		dto.Model = nil
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+// getLibraryElementVersion gets a version of a library element, including its model.
+func (l *LibraryElementService) getLibraryElementVersion(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementVersionDTO, error) {
+	var dto model.LibraryElementVersionDTO
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := l.getElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+		versionWithMeta, err := l.findLibraryElementVersion(session, element.ID, version)
+		if err != nil {
+			return err
+		}
+		dto = l.toLibraryElementVersionDTO(uid, versionWithMeta)
+		return nil
+	})
+	return dto, err
+}
+
+func (l *LibraryElementService) findLibraryElementVersion(session *db.Session, elementID int64, version int64) (model.LibraryElementVersionWithMeta, error) {
+	versions := make([]model.LibraryElementVersionWithMeta, 0)
+	if err := l.selectLibraryElementVersions(session, elementID).And("lev.version=?", version).Find(&versions); err != nil {
+		return model.LibraryElementVersionWithMeta{}, err
+	}
+	if len(versions) == 0 {
+		return model.LibraryElementVersionWithMeta{}, model.ErrLibraryElementVersionNotFound
+	}
+	return versions[0], nil
+}
+
+// calculateLibraryElementDiff compares the models of two versions of a library element.
+func (l *LibraryElementService) calculateLibraryElementDiff(c context.Context, signedInUser identity.Requester, uid string, cmd model.CalculateLibraryElementDiffCommand) (*dashdiffs.Result, dashdiffs.DiffType, error) {
+	var baseModel, newModel *simplejson.Json
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := l.getElementForVersions(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+		baseVersion, err := l.findLibraryElementVersion(session, element.ID, cmd.Base)
+		if err != nil {
+			return err
+		}
+		newVersion, err := l.findLibraryElementVersion(session, element.ID, cmd.New)
+		if err != nil {
+			return err
+		}
+		if baseModel, err = simplejson.NewJson(baseVersion.Model); err != nil {
+			return err
+		}
+		newModel, err = simplejson.NewJson(newVersion.Model)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	options := dashdiffs.Options{
+		OrgId:    signedInUser.GetOrgID(),
+		DiffType: dashdiffs.ParseDiffType(cmd.DiffType),
+	}
+	result, err := dashdiffs.CalculateDiff(c, &options, baseModel, newModel)
+	return result, options.DiffType, err
+}
+
+// restoreLibraryElementVersion saves a previous version of a library element as its latest version.
+func (l *LibraryElementService) restoreLibraryElementVersion(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementDTO, error) {
+	var cmd model.PatchLibraryElementCommand
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+		if err != nil {
+			return err
+		}
+		versionToRestore, err := l.findLibraryElementVersion(session, element.ID, version)
+		if err != nil {
+			return err
+		}
+		// the patch checks the version, so it fails if the element is updated in the meantime
+		cmd = model.PatchLibraryElementCommand{
+			FolderID: element.FolderID, // nolint:staticcheck
+			Name:     versionToRestore.Name,
+			Model:    versionToRestore.Model,
+			Kind:     element.Kind,
+			Version:  element.Version,
+			Message:  fmt.Sprintf("Restored from version %d", version),
+		}
+		return nil
+	})
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+
+	return l.patchLibraryElementVersion(c, signedInUser, cmd, uid, version)
+}
+
+// deleteExpiredVersions deletes the oldest versions of the library elements that have more versions than configured.
+func (l *LibraryElementService) deleteExpiredVersions(c context.Context) (int64, error) {
+	versionsToKeep := l.Cfg.LibraryPanelVersionsToKeep
+	if versionsToKeep < 1 {
+		versionsToKeep = 1
+	}
+
+	var deletedRows int64
+	for batch := 0; batch < maxVersionDeletionBatches; batch++ {
+		var versionIDsToDelete []any
+		err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+			versionIDsToDeleteQuery := `SELECT id
+				FROM ` + model.LibraryElementVersionTableName + `, (
+					SELECT element_id, count(version) as count, min(version) as min
+					FROM ` + model.LibraryElementVersionTableName + `
+					GROUP BY element_id
+				) AS vtd
+				WHERE ` + model.LibraryElementVersionTableName + `.element_id=vtd.element_id
+				AND version < vtd.min + vtd.count - ?
+				LIMIT ?`
+			return session.SQL(versionIDsToDeleteQuery, versionsToKeep, maxVersionsToDeletePerBatch).Find(&versionIDsToDelete)
+		})
+		if err != nil {
+			return deletedRows, err
+		}
+		if len(versionIDsToDelete) < 1 {
+			return deletedRows, nil
+		}
+
+		var deleted int64
+		err = l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
+			deleteExpiredSQL := `DELETE FROM ` + model.LibraryElementVersionTableName + ` WHERE id IN (?` + strings.Repeat(",?", len(versionIDsToDelete)-1) + `)`
+			result, err := session.Exec(append([]any{deleteExpiredSQL}, versionIDsToDelete...)...)
+			if err != nil {
+				return err
+			}
+			deleted, err = result.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return deletedRows, err
+		}
+
+		deletedRows += deleted
+		if deleted < int64(maxVersionsToDeletePerBatch) {
+			break
+		}
+	}
+
+	return deletedRows, nil
+}
+
+func (l *LibraryElementService) toLibraryElementVersionDTO(uid string, version model.LibraryElementVersionWithMeta) model.LibraryElementVersionDTO {
+	return model.LibraryElementVersionDTO{
+		ID:            version.ID,
+		ElementUID:    uid,
+		ParentVersion: version.ParentVersion,
+		RestoredFrom:  version.RestoredFrom,
+		Version:       version.Version,
+		Name:          version.Name,
+		Message:       version.Message,
+		Created:       version.Created,
+		CreatedBy: librarypanel.LibraryElementDTOMetaUser{
+			Id:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarUrl: dtos.GetGravatarUrl(l.Cfg, version.CreatedByEmail),
+		},
+		Model: version.Model,
+	}
+}
@@ -74,6 +74,10 @@ func (s *Service) GetUserLabels(ctx context.Context, query login.GetUserLabelsQu
 	return s.authInfoStore.GetUserLabels(ctx, query)
 }
 
+func (s *Service) GetUserIDsByAuthModule(ctx context.Context, query *login.GetUserIDsByAuthModuleQuery) ([]int64, error) {
+	return s.authInfoStore.GetUserIDsByAuthModule(ctx, query)
+}
+
 func (s *Service) setAuthInfoInCache(ctx context.Context, query *login.GetAuthInfoQuery, info *login.UserAuth) error {
 	cacheKey := generateCacheKey(query)
 	infoJSON, err := json.Marshal(info)
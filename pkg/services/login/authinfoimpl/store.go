@@ -106,6 +106,18 @@ func (s *Store) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuer
 	return labelMap, nil
 }
 
+// GetUserIDsByAuthModule returns the IDs of the users that have authenticated with the auth module
+func (s *Store) GetUserIDsByAuthModule(ctx context.Context, query *login.GetUserIDsByAuthModuleQuery) ([]int64, error) {
+	userIDs := []int64{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("user_auth").Where("auth_module = ?", query.AuthModule).Distinct("user_id").OrderBy("user_id").Find(&userIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
 func (s *Store) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
 	authUser := &login.UserAuth{
 		UserId:     cmd.UserId,
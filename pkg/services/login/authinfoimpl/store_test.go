@@ -54,6 +54,23 @@ func TestIntegrationAuthInfoStore(t *testing.T) {
 		require.Equal(t, login.GoogleAuthModule, labels[2])
 	})
 
+	t.Run("should get the users that authenticated with an auth module", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
+			AuthModule: login.LDAPAuthModule,
+			AuthId:     "3",
+			UserId:     3,
+		}))
+
+		userIDs, err := store.GetUserIDsByAuthModule(ctx, &login.GetUserIDsByAuthModuleQuery{AuthModule: login.LDAPAuthModule})
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 3}, userIDs)
+
+		userIDs, err = store.GetUserIDsByAuthModule(ctx, &login.GetUserIDsByAuthModuleQuery{AuthModule: login.JWTModule})
+		require.NoError(t, err)
+		require.Empty(t, userIDs)
+	})
+
 	t.Run("should always get the latest used", func(t *testing.T) {
 		ctx := context.Background()
 		require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
@@ -13,6 +13,7 @@ type FakeService struct {
 	ExpectedExternalUser *login.ExternalUserInfo
 	ExpectedError        error
 	ExpectedLabels       map[int64]string
+	ExpectedUserIDs      []int64
 
 	SetAuthInfoFn    func(ctx context.Context, cmd *login.SetAuthInfoCommand) error
 	UpdateAuthInfoFn func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error
@@ -27,6 +28,10 @@ func (a *FakeService) GetUserLabels(ctx context.Context, query login.GetUserLabe
 	return a.ExpectedLabels, a.ExpectedError
 }
 
+func (a *FakeService) GetUserIDsByAuthModule(ctx context.Context, query *login.GetUserIDsByAuthModuleQuery) ([]int64, error) {
+	return a.ExpectedUserIDs, a.ExpectedError
+}
+
 func (a *FakeService) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
 	if a.SetAuthInfoFn != nil {
 		return a.SetAuthInfoFn(ctx, cmd)
@@ -117,3 +117,7 @@ type GetAuthInfoQuery struct {
 type GetUserLabelsQuery struct {
 	UserIDs []int64
 }
+
+type GetUserIDsByAuthModuleQuery struct {
+	AuthModule string
+}
@@ -0,0 +1,80 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// ActionOrgPolicyRead allows reading the two-factor authentication policy of the organization.
+	ActionOrgPolicyRead = "orgs.mfa:read"
+	// ActionOrgPolicyWrite allows requiring two-factor authentication for all users of the organization.
+	ActionOrgPolicyWrite = "orgs.mfa:write"
+)
+
+var (
+	ErrCodeRequired        = errutil.Unauthorized("mfa.code-required", errutil.WithPublicMessage("Two-factor authentication code required"))
+	ErrInvalidCode         = errutil.Unauthorized("mfa.invalid-code", errutil.WithPublicMessage("Invalid two-factor authentication code"))
+	ErrEnrollmentRequired  = errutil.Unauthorized("mfa.enrollment-required", errutil.WithPublicMessage("Two-factor authentication is required by your organization, contact your administrator"))
+	ErrBasicAuthNotAllowed = errutil.Unauthorized("mfa.basic-auth-not-allowed", errutil.WithPublicMessage("Basic authentication is not allowed for users with two-factor authentication, use a service account token instead"))
+	ErrNotEnrolled         = errutil.BadRequest("mfa.not-enrolled", errutil.WithPublicMessage("Two-factor authentication is not enrolled"))
+	ErrAlreadyEnabled      = errutil.Conflict("mfa.already-enabled", errutil.WithPublicMessage("Two-factor authentication is already enabled"))
+	ErrRequiredByOrg       = errutil.Forbidden("mfa.required-by-org", errutil.WithPublicMessage("Two-factor authentication cannot be disabled because it is required by your organization"))
+	ErrUnsupportedIdentity = errutil.BadRequest("mfa.unsupported-identity", errutil.WithPublicMessage("Two-factor authentication is only supported for users stored in Grafana"))
+)
+
+// Service manages the TOTP (RFC 6238) second factor of users stored in the Grafana database.
+type Service interface {
+	// Enroll generates a new secret and recovery codes for the user. The enrollment is pending until it is enabled with a valid code.
+	Enroll(ctx context.Context, userID int64, login string) (*Enrollment, error)
+	// Enable verifies the code against the pending enrollment of the user and enables the second factor.
+	Enable(ctx context.Context, userID int64, code string) error
+	// Disable verifies the code and removes the second factor of the user.
+	Disable(ctx context.Context, userID int64, code string) error
+	// Reset removes the second factor of the user without verification and gives the user the grace period to enroll again.
+	Reset(ctx context.Context, userID int64) error
+	GetStatus(ctx context.Context, userID int64) (*Status, error)
+
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, orgID int64, required bool) (*OrgPolicy, error)
+
+	// VerifyHook is an authn post auth hook that enforces the second factor for users authenticated by their password.
+	VerifyHook(ctx context.Context, identity *authn.Identity, r *authn.Request) error
+}
+
+// Enrollment is returned once when the user enrolls. It is the only time the secret and the recovery codes are available.
+type Enrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioningUri"`
+	RecoveryCodes   []string `json:"recoveryCodes"`
+}
+
+type Status struct {
+	Enabled bool `json:"enabled"`
+	// Pending is true if the user enrolled but has not enabled the second factor yet.
+	Pending bool `json:"pending"`
+	// Required is true if any organization of the user requires the second factor.
+	Required bool `json:"required"`
+	// RecoveryCodesLeft is the number of recovery codes that were not used yet.
+	RecoveryCodesLeft int `json:"recoveryCodesLeft"`
+}
+
+type OrgPolicy struct {
+	// Required is true if all users of the organization that are stored in Grafana must use the second factor.
+	Required bool `json:"required"`
+	// RequiredSince is the time the organization started to require the second factor.
+	RequiredSince time.Time `json:"requiredSince,omitempty"`
+}
+
+// UpdateOrgPolicyCommand is the body of the request that updates the policy of the organization.
+type UpdateOrgPolicyCommand struct {
+	Required bool `json:"required"`
+}
+
+// CodeCommand is the body of the requests that need a code generated by the authenticator app or a recovery code.
+type CodeCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
@@ -0,0 +1,137 @@
+package mfaimpl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	authorizeInOrg := ac.AuthorizeInOrgMiddleware(s.accessControl, s.authnService)
+
+	s.routeRegister.Group("/api/user/mfa", func(r routing.RouteRegister) {
+		r.Get("/", middleware.ReqSignedIn, routing.Wrap(s.getStatusHandler))
+		r.Post("/enroll", middleware.ReqSignedIn, routing.Wrap(s.enrollHandler))
+		r.Post("/enable", middleware.ReqSignedIn, routing.Wrap(s.enableHandler))
+		r.Post("/disable", middleware.ReqSignedIn, routing.Wrap(s.disableHandler))
+	})
+
+	s.routeRegister.Group("/api/org/mfa", func(r routing.RouteRegister) {
+		r.Get("/", authorize(ac.EvalPermission(mfa.ActionOrgPolicyRead)), routing.Wrap(s.getOrgPolicyHandler))
+		r.Put("/", authorize(ac.EvalPermission(mfa.ActionOrgPolicyWrite)), routing.Wrap(s.updateOrgPolicyHandler))
+	})
+
+	userIDScope := ac.Scope("global.users", "id", ac.Parameter(":id"))
+	s.routeRegister.Delete("/api/admin/users/:id/mfa", authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersPasswordUpdate, userIDScope)), routing.Wrap(s.resetHandler))
+}
+
+// signedInUserID returns the ID of the signed in user. Service accounts and API keys cannot use the second factor.
+func signedInUserID(c *contextmodel.ReqContext) (int64, error) {
+	id := c.SignedInUser.GetID()
+	if !id.IsNamespace(identity.NamespaceUser) {
+		return 0, mfa.ErrUnsupportedIdentity.Errorf("identity %s cannot use two-factor authentication", id)
+	}
+	return id.ParseInt()
+}
+
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+	status, err := s.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+func (s *Service) enrollHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enroll two-factor authentication", err)
+	}
+	enrollment, err := s.Enroll(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enroll two-factor authentication", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func (s *Service) enableHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enable two-factor authentication", err)
+	}
+	cmd := mfa.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := s.Enable(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enable two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication enabled")
+}
+
+func (s *Service) disableHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+	}
+	cmd := mfa.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := s.Disable(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication disabled")
+}
+
+func (s *Service) getOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get two-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) updateOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := mfa.UpdateOrgPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	policy, err := s.SetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Required)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update two-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) resetHandler(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if _, err := s.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID}); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	if err := s.Reset(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication reset")
+}
@@ -0,0 +1,60 @@
+package mfaimpl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_UpdateOrgPolicy(t *testing.T) {
+	tests := []struct {
+		desc         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}{
+		{
+			desc:         "should update the policy with the permission to write the policy",
+			permissions:  []accesscontrol.Permission{{Action: mfa.ActionOrgPolicyWrite}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not update the policy with the permission to write the preferences of the organization",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsPreferencesWrite}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, _, _ := setupTestService(t)
+			s.routeRegister = routing.NewRouteRegister()
+			s.accessControl = acimpl.ProvideAccessControl(s.cfg)
+			s.authnService = &authntest.FakeService{}
+			s.registerAPIEndpoints()
+			server := webtest.NewServer(t, s.routeRegister)
+
+			req := server.NewRequest(http.MethodPut, "/api/org/mfa", strings.NewReader(`{"required": false}`))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:       1,
+				UserID:      1,
+				OrgRole:     org.RoleAdmin,
+				Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tt.permissions)},
+			})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	kvNamespace        = "mfa"
+	kvOrgPolicyKey     = "org-policy"
+	recoveryCodesCount = 10
+	// recoveryCodeAlphabet does not contain characters that are easy to confuse, such as 0 and O or 1 and l.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var _ mfa.Service = (*Service)(nil)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, kv kvstore.KVStore, secretsService secrets.Service,
+	orgService org.Service, userService user.Service, loginAttempts loginattempt.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl, acService accesscontrol.Service,
+	authnService authn.Service,
+) (*Service, error) {
+	s := &Service{
+		cfg:           cfg,
+		store:         &xormStore{db: sqlStore, now: time.Now},
+		kv:            kv,
+		secrets:       secretsService,
+		orgService:    orgService,
+		userService:   userService,
+		loginAttempts: loginAttempts,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		authnService:  authnService,
+		log:           log.New("mfa"),
+		now:           time.Now,
+	}
+
+	if cfg.MFA.Enabled {
+		if err := declareFixedRoles(acService); err != nil {
+			return nil, err
+		}
+		s.registerAPIEndpoints()
+	}
+
+	return s, nil
+}
+
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	kv            kvstore.KVStore
+	secrets       secrets.Service
+	orgService    org.Service
+	userService   user.Service
+	loginAttempts loginattempt.Service
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+	authnService  authn.Service
+	log           log.Logger
+	now           func() time.Time
+}
+
+func (s *Service) Enroll(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	current, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Enabled {
+		return nil, mfa.ErrAlreadyEnabled.Errorf("user %d already enabled two-factor authentication", userID)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	hashesJSON, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &userMFA{
+		UserID:        userID,
+		Secret:        base64.StdEncoding.EncodeToString(encrypted),
+		RecoveryCodes: string(hashesJSON),
+	}
+	if current != nil {
+		m.GraceUntil = current.GraceUntil
+	}
+	if err := s.store.Upsert(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return &mfa.Enrollment{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI(s.cfg.MFA.Issuer, login, secret),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+func (s *Service) Enable(ctx context.Context, userID int64, code string) error {
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.Secret == "" {
+		return mfa.ErrNotEnrolled.Errorf("user %d has not enrolled", userID)
+	}
+	if m.Enabled {
+		return mfa.ErrAlreadyEnabled.Errorf("user %d already enabled two-factor authentication", userID)
+	}
+
+	// recovery codes cannot be used to enable the second factor, the user has to prove that the authenticator app works.
+	ok, err := s.verify(ctx, m, code, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return mfa.ErrInvalidCode.Errorf("invalid code")
+	}
+
+	m.Enabled = true
+	m.GraceUntil = 0
+	return s.store.Upsert(ctx, m)
+}
+
+func (s *Service) Disable(ctx context.Context, userID int64, code string) error {
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || !m.Enabled {
+		return mfa.ErrNotEnrolled.Errorf("user %d has not enabled two-factor authentication", userID)
+	}
+
+	required, err := s.isRequired(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return mfa.ErrRequiredByOrg.Errorf("user %d is a member of an organization that requires two-factor authentication", userID)
+	}
+
+	ok, err := s.verify(ctx, m, code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return mfa.ErrInvalidCode.Errorf("invalid code")
+	}
+	return s.store.Delete(ctx, userID)
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	return s.store.Upsert(ctx, &userMFA{
+		UserID:     userID,
+		GraceUntil: s.now().Add(s.cfg.MFA.EnrollmentGracePeriod).Unix(),
+	})
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.isRequired(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &mfa.Status{Required: required}
+	if m != nil && m.Secret != "" {
+		status.Enabled = m.Enabled
+		status.Pending = !m.Enabled
+		hashes, err := recoveryCodeHashes(m)
+		if err != nil {
+			return nil, err
+		}
+		status.RecoveryCodesLeft = len(hashes)
+	}
+	return status, nil
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, kvOrgPolicyKey)
+	if err != nil {
+		return nil, err
+	}
+	policy := &mfa.OrgPolicy{}
+	if !ok {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, orgID int64, required bool) (*mfa.OrgPolicy, error) {
+	if !required {
+		if err := s.kv.Del(ctx, orgID, kvNamespace, kvOrgPolicyKey); err != nil {
+			return nil, err
+		}
+		return &mfa.OrgPolicy{}, nil
+	}
+
+	policy, err := s.GetOrgPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Required {
+		return policy, nil
+	}
+
+	// users get the grace period to enroll starting from the moment the organization requires the second factor.
+	policy = &mfa.OrgPolicy{Required: true, RequiredSince: s.now().UTC()}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.kv.Set(ctx, orgID, kvNamespace, kvOrgPolicyKey, string(value)); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// VerifyHook enforces the second factor for users stored in the Grafana database that authenticate with their password.
+//   - Login: users that enabled the second factor must provide a valid code or a recovery code.
+//     Users that did not enable it cannot log in if an organization they belong to requires it and their grace period is over.
+//   - Basic auth: it is rejected for users to whom the second factor applies, unless allowed by the configuration.
+func (s *Service) VerifyHook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
+	if !s.cfg.MFA.Enabled || identity.AuthenticatedBy != login.PasswordAuthModule || !identity.ID.IsNamespace(authn.NamespaceUser) {
+		return nil
+	}
+	userID, err := identity.ID.ParseInt()
+	if err != nil {
+		return err
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	enabled := m != nil && m.Enabled
+
+	if r.GetMeta(authn.MetaKeyIsLogin) != "true" {
+		if s.cfg.MFA.AllowBasicAuth {
+			return nil
+		}
+		if enabled {
+			return mfa.ErrBasicAuthNotAllowed.Errorf("user %d enabled two-factor authentication", userID)
+		}
+		enforced, err := s.isEnforced(ctx, userID, m)
+		if err != nil {
+			return err
+		}
+		if enforced {
+			return mfa.ErrBasicAuthNotAllowed.Errorf("two-factor authentication is required for user %d", userID)
+		}
+		return nil
+	}
+
+	if enabled {
+		code := r.GetMeta(authn.MetaKeyMFACode)
+		if code == "" {
+			return mfa.ErrCodeRequired.Errorf("user %d did not provide the code", userID)
+		}
+		ok, err := s.verify(ctx, m, code, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// invalid codes count as failed login attempts to prevent guessing the code.
+			if err := s.loginAttempts.Add(ctx, r.GetMeta(authn.MetaKeyUsername), web.RemoteAddr(r.HTTPRequest)); err != nil {
+				s.log.FromContext(ctx).Warn("Failed to record login attempt", "error", err)
+			}
+			return mfa.ErrInvalidCode.Errorf("user %d provided an invalid code", userID)
+		}
+		return nil
+	}
+
+	enforced, err := s.isEnforced(ctx, userID, m)
+	if err != nil {
+		return err
+	}
+	if enforced {
+		return mfa.ErrEnrollmentRequired.Errorf("two-factor authentication is required for user %d", userID)
+	}
+	return nil
+}
+
+// verify checks the code generated by the authenticator app and, if allowed, the recovery codes.
+// Used codes are stored so that they cannot be used again.
+func (s *Service) verify(ctx context.Context, m *userMFA, code string, allowRecoveryCode bool) (bool, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(m.Secret)
+	if err != nil {
+		return false, err
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := validateCode(string(secret), code, s.now(), m.LastUsedStep); ok {
+		m.LastUsedStep = step
+		return true, s.store.Upsert(ctx, m)
+	}
+
+	if !allowRecoveryCode {
+		return false, nil
+	}
+	hashes, err := recoveryCodeHashes(m)
+	if err != nil {
+		return false, err
+	}
+	hash := hashRecoveryCode(code)
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) != 1 {
+			continue
+		}
+		hashes = append(hashes[:i], hashes[i+1:]...)
+		hashesJSON, err := json.Marshal(hashes)
+		if err != nil {
+			return false, err
+		}
+		m.RecoveryCodes = string(hashesJSON)
+		s.log.FromContext(ctx).Info("Recovery code used", "userID", m.UserID, "left", len(hashes))
+		return true, s.store.Upsert(ctx, m)
+	}
+	return false, nil
+}
+
+// isRequired returns true if any organization of the user requires the second factor.
+func (s *Service) isRequired(ctx context.Context, userID int64) (bool, error) {
+	_, required, err := s.enforcedSince(ctx, userID)
+	return required, err
+}
+
+// isEnforced returns true if the user cannot log in without the second factor anymore, i.e.
+// an organization of the user requires it and the grace period of the user is over.
+func (s *Service) isEnforced(ctx context.Context, userID int64, m *userMFA) (bool, error) {
+	since, required, err := s.enforcedSince(ctx, userID)
+	if err != nil || !required {
+		return false, err
+	}
+	now := s.now()
+	if now.Before(since) {
+		return false, nil
+	}
+	if m != nil && now.Unix() < m.GraceUntil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// enforcedSince returns the earliest time when the grace period of an organization of the user that requires the second factor ends.
+func (s *Service) enforcedSince(ctx context.Context, userID int64) (time.Time, bool, error) {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	var since time.Time
+	required := false
+	for _, o := range orgs {
+		policy, err := s.GetOrgPolicy(ctx, o.OrgID)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if !policy.Required {
+			continue
+		}
+		end := policy.RequiredSince.Add(s.cfg.MFA.EnrollmentGracePeriod)
+		if !required || end.Before(since) {
+			since = end
+		}
+		required = true
+	}
+	return since, required, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		var sb strings.Builder
+		for j, c := range b {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeAlphabet[int(c)%len(recoveryCodeAlphabet)])
+		}
+		code := sb.String()
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes the normalized code. Recovery codes are random, so a salt is not needed.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func recoveryCodeHashes(m *userMFA) ([]string, error) {
+	var hashes []string
+	if m.RecoveryCodes == "" {
+		return hashes, nil
+	}
+	if err := json.Unmarshal([]byte(m.RecoveryCodes), &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
@@ -0,0 +1,220 @@
+package mfaimpl
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_EnrollAndVerify(t *testing.T) {
+	s, now, attempts := setupTestService(t)
+	ctx := context.Background()
+
+	enrollment, err := s.Enroll(ctx, 1, "admin")
+	require.NoError(t, err)
+	require.Len(t, enrollment.RecoveryCodes, recoveryCodesCount)
+
+	status, err := s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, status.Pending)
+	require.False(t, status.Enabled)
+
+	// a pending enrollment does not affect the login
+	require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")))
+
+	require.ErrorIs(t, s.Enable(ctx, 1, "000000"), mfa.ErrInvalidCode)
+	require.ErrorIs(t, s.Enable(ctx, 1, enrollment.RecoveryCodes[0]), mfa.ErrInvalidCode, "recovery codes cannot enable the second factor")
+	code := currentCode(t, enrollment.Secret, *now)
+	require.NoError(t, s.Enable(ctx, 1, code))
+
+	t.Run("login requires the code", func(t *testing.T) {
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")), mfa.ErrCodeRequired)
+	})
+
+	t.Run("login with a used code fails and counts as failed attempt", func(t *testing.T) {
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), loginRequest(code)), mfa.ErrInvalidCode)
+		require.True(t, attempts.AddCalled)
+	})
+
+	t.Run("login with the next code succeeds", func(t *testing.T) {
+		*now = now.Add(totpPeriod)
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest(currentCode(t, enrollment.Secret, *now))))
+	})
+
+	t.Run("recovery codes can be used once", func(t *testing.T) {
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest(enrollment.RecoveryCodes[0])))
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), loginRequest(enrollment.RecoveryCodes[0])), mfa.ErrInvalidCode)
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.Equal(t, recoveryCodesCount-1, status.RecoveryCodesLeft)
+	})
+
+	t.Run("basic auth is rejected", func(t *testing.T) {
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), basicAuthRequest()), mfa.ErrBasicAuthNotAllowed)
+		s.cfg.MFA.AllowBasicAuth = true
+		defer func() { s.cfg.MFA.AllowBasicAuth = false }()
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), basicAuthRequest()))
+	})
+
+	t.Run("other authentication methods are ignored", func(t *testing.T) {
+		identity := testIdentity(1)
+		identity.AuthenticatedBy = login.GenericOAuthModule
+		require.NoError(t, s.VerifyHook(ctx, identity, loginRequest("")))
+	})
+
+	t.Run("enroll again is rejected", func(t *testing.T) {
+		_, err := s.Enroll(ctx, 1, "admin")
+		require.ErrorIs(t, err, mfa.ErrAlreadyEnabled)
+	})
+
+	t.Run("disable requires a valid code", func(t *testing.T) {
+		require.ErrorIs(t, s.Disable(ctx, 1, "000000"), mfa.ErrInvalidCode)
+		require.NoError(t, s.Disable(ctx, 1, enrollment.RecoveryCodes[1]))
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")))
+	})
+}
+
+func TestService_OrgPolicy(t *testing.T) {
+	s, now, _ := setupTestService(t)
+	ctx := context.Background()
+	s.orgService.(*orgtest.FakeOrgService).ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1}, {OrgID: 2}}
+
+	policy, err := s.SetOrgPolicy(ctx, 2, true)
+	require.NoError(t, err)
+	require.True(t, policy.Required)
+	since := policy.RequiredSince
+
+	// requiring again keeps the original time so that the grace period is not extended
+	*now = now.Add(time.Hour)
+	policy, err = s.SetOrgPolicy(ctx, 2, true)
+	require.NoError(t, err)
+	require.True(t, since.Equal(policy.RequiredSince))
+
+	t.Run("users can log in during the grace period", func(t *testing.T) {
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")))
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), basicAuthRequest()))
+	})
+
+	*now = now.Add(s.cfg.MFA.EnrollmentGracePeriod)
+
+	t.Run("users without the second factor cannot log in after the grace period", func(t *testing.T) {
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")), mfa.ErrEnrollmentRequired)
+		require.ErrorIs(t, s.VerifyHook(ctx, testIdentity(1), basicAuthRequest()), mfa.ErrBasicAuthNotAllowed)
+	})
+
+	t.Run("reset gives the user a new grace period", func(t *testing.T) {
+		require.NoError(t, s.Reset(ctx, 1))
+		require.NoError(t, s.VerifyHook(ctx, testIdentity(1), loginRequest("")))
+	})
+
+	t.Run("the second factor cannot be disabled if required", func(t *testing.T) {
+		enrollment, err := s.Enroll(ctx, 1, "admin")
+		require.NoError(t, err)
+		require.NoError(t, s.Enable(ctx, 1, currentCode(t, enrollment.Secret, *now)))
+		require.ErrorIs(t, s.Disable(ctx, 1, enrollment.RecoveryCodes[0]), mfa.ErrRequiredByOrg)
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, status.Required)
+	})
+
+	t.Run("not requiring the second factor removes the policy", func(t *testing.T) {
+		policy, err := s.SetOrgPolicy(ctx, 2, false)
+		require.NoError(t, err)
+		require.False(t, policy.Required)
+
+		policy, err = s.GetOrgPolicy(ctx, 2)
+		require.NoError(t, err)
+		require.False(t, policy.Required)
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *time.Time, *loginattempttest.MockLoginAttemptService) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.MFA = setting.AuthMFASettings{Enabled: true, Issuer: "Grafana", EnrollmentGracePeriod: 24 * time.Hour}
+
+	now := time.Unix(1700000000, 0)
+	attempts := &loginattempttest.MockLoginAttemptService{}
+	s := &Service{
+		cfg:           cfg,
+		store:         newFakeStore(),
+		kv:            kvstore.NewFakeKVStore(),
+		secrets:       fakes.NewFakeSecretsService(),
+		orgService:    orgtest.NewOrgServiceFake(),
+		loginAttempts: attempts,
+		log:           log.NewNopLogger(),
+		now:           func() time.Time { return now },
+	}
+	return s, &now, attempts
+}
+
+func currentCode(t *testing.T, secret string, now time.Time) string {
+	t.Helper()
+	code, err := generateCode(secret, timeStep(now))
+	require.NoError(t, err)
+	return code
+}
+
+func testIdentity(userID int64) *authn.Identity {
+	return &authn.Identity{
+		ID:              authn.NewNamespaceID(authn.NamespaceUser, userID),
+		AuthenticatedBy: login.PasswordAuthModule,
+	}
+}
+
+func loginRequest(code string) *authn.Request {
+	r := &authn.Request{HTTPRequest: httptest.NewRequest("POST", "/login", nil)}
+	r.SetMeta(authn.MetaKeyIsLogin, "true")
+	r.SetMeta(authn.MetaKeyUsername, "admin")
+	if code != "" {
+		r.SetMeta(authn.MetaKeyMFACode, code)
+	}
+	return r
+}
+
+func basicAuthRequest() *authn.Request {
+	return &authn.Request{HTTPRequest: httptest.NewRequest("GET", "/api/dashboards", nil)}
+}
+
+type fakeStore struct {
+	rows map[int64]userMFA
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{rows: map[int64]userMFA{}}
+}
+
+func (f *fakeStore) Get(_ context.Context, userID int64) (*userMFA, error) {
+	m, ok := f.rows[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (f *fakeStore) Upsert(_ context.Context, m *userMFA) error {
+	f.rows[m.UserID] = *m
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, userID int64) error {
+	delete(f.rows, userID)
+	return nil
+}
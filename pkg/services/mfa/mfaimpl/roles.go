@@ -0,0 +1,37 @@
+package mfaimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:organization.mfa:reader",
+			DisplayName: "Organization two-factor authentication policy reader",
+			Description: "Read whether the organization requires two-factor authentication.",
+			Group:       "Organizations",
+			Permissions: []accesscontrol.Permission{
+				{Action: mfa.ActionOrgPolicyRead},
+			},
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:organization.mfa:writer",
+			DisplayName: "Organization two-factor authentication policy writer",
+			Description: "Read and update whether the organization requires two-factor authentication.",
+			Group:       "Organizations",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: mfa.ActionOrgPolicyWrite},
+			}),
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reader, writer)
+}
@@ -0,0 +1,82 @@
+package mfaimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// userMFA is the second factor of a user. Secret is encrypted and RecoveryCodes is a JSON array of hashes of the recovery codes.
+type userMFA struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	UserID        int64  `xorm:"user_id"`
+	Secret        string `xorm:"secret"`
+	Enabled       bool   `xorm:"enabled"`
+	LastUsedStep  int64  `xorm:"last_used_step"`
+	RecoveryCodes string `xorm:"recovery_codes"`
+	// GraceUntil is the Unix time until which the user can log in without the second factor
+	// even if an organization of the user requires it.
+	GraceUntil int64     `xorm:"grace_until"`
+	Created    time.Time `xorm:"created"`
+	Updated    time.Time `xorm:"updated"`
+}
+
+func (userMFA) TableName() string {
+	return "user_mfa"
+}
+
+type store interface {
+	Get(ctx context.Context, userID int64) (*userMFA, error)
+	Upsert(ctx context.Context, m *userMFA) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+type xormStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+// Get returns the second factor of the user or nil if the user never enrolled.
+func (xs *xormStore) Get(ctx context.Context, userID int64) (*userMFA, error) {
+	var result *userMFA
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		m := userMFA{}
+		has, err := sess.Where("user_id = ?", userID).Get(&m)
+		if err != nil {
+			return err
+		}
+		if has {
+			result = &m
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (xs *xormStore) Upsert(ctx context.Context, m *userMFA) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := userMFA{}
+		has, err := sess.Where("user_id = ?", m.UserID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		m.Updated = xs.now()
+		if !has {
+			m.Created = m.Updated
+			_, err = sess.Insert(m)
+			return err
+		}
+		m.ID = existing.ID
+		m.Created = existing.Created
+		_, err = sess.ID(existing.ID).AllCols().Update(m)
+		return err
+	})
+}
+
+func (xs *xormStore) Delete(ctx context.Context, userID int64) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
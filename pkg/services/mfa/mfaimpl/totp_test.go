@@ -0,0 +1,95 @@
+package mfaimpl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the base32 encoding of the SHA1 secret used by the test vectors of RFC 6238.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	// RFC 6238 Appendix B, truncated to 6 digits
+	testCases := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := generateCode(rfcSecret, timeStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, code, "time %d", tc.unix)
+	}
+
+	_, err := generateCode("not base32!", 1)
+	require.Error(t, err)
+}
+
+func TestValidateCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := timeStep(now)
+
+	t.Run("accepts the current code", func(t *testing.T) {
+		step, ok := validateCode(rfcSecret, "050471", now, 0)
+		require.True(t, ok)
+		require.Equal(t, current, step)
+	})
+
+	t.Run("accepts codes of adjacent periods", func(t *testing.T) {
+		previous, err := generateCode(rfcSecret, current-1)
+		require.NoError(t, err)
+		step, ok := validateCode(rfcSecret, previous, now, 0)
+		require.True(t, ok)
+		require.Equal(t, current-1, step)
+
+		next, err := generateCode(rfcSecret, current+1)
+		require.NoError(t, err)
+		_, ok = validateCode(rfcSecret, next, now, 0)
+		require.True(t, ok)
+	})
+
+	t.Run("rejects codes outside of the skew", func(t *testing.T) {
+		old, err := generateCode(rfcSecret, current-2)
+		require.NoError(t, err)
+		_, ok := validateCode(rfcSecret, old, now, 0)
+		require.False(t, ok)
+	})
+
+	t.Run("rejects used codes", func(t *testing.T) {
+		_, ok := validateCode(rfcSecret, "050471", now, current)
+		require.False(t, ok)
+	})
+
+	t.Run("rejects malformed codes", func(t *testing.T) {
+		for _, code := range []string{"", "05047", "0504711", "abcdef"} {
+			_, ok := validateCode(rfcSecret, code, now, 0)
+			require.False(t, ok, code)
+		}
+	})
+}
+
+func TestProvisioningURI(t *testing.T) {
+	u, err := url.Parse(provisioningURI("Grafana", "admin", rfcSecret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Grafana:admin", u.Path)
+	require.Equal(t, rfcSecret, u.Query().Get("secret"))
+	require.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := generateSecret()
+	require.NoError(t, err)
+	_, err = generateCode(secret, 1)
+	require.NoError(t, err)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -279,6 +280,40 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *contextmodel.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	var cfg apimodels.Config
+	if body.Config != nil {
+		cfg = body.Config.AlertmanagerConfig.Config
+	} else {
+		canSeeAutogen := c.SignedInUser.HasRole(org.RoleAdmin)
+		current, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), canSeeAutogen)
+		if err != nil {
+			if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to get the Alertmanager configuration")
+		}
+		cfg = current.AlertmanagerConfig.Config
+	}
+
+	at := time.Now()
+	if body.Time != nil {
+		at = *body.Time
+	}
+
+	silences, err := srv.silenceSvc.ListSilences(c.Req.Context(), c.SignedInUser, nil)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list silences", err)
+	}
+
+	res, err := notifier.TestRoutes(cfg, body.Labels, at, silences)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	return response.JSON(http.StatusOK, newTestRoutesResult(res))
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
@@ -388,6 +423,40 @@ func newTestTemplateResult(res *notifier.TestTemplatesResults) apimodels.TestTem
 	return apiRes
 }
 
+func newTestRoutesResult(res *notifier.TestRoutesResult) apimodels.TestRoutesResults {
+	apiRes := apimodels.TestRoutesResults{
+		Labels: res.Labels,
+		Time:   res.Time,
+		Routes: make([]apimodels.TestRouteResult, 0, len(res.Routes)),
+	}
+	for _, r := range res.Routes {
+		apiRes.Routes = append(apiRes.Routes, apimodels.TestRouteResult{
+			Key:                 r.Key,
+			Matchers:            r.Matchers,
+			Receiver:            r.Receiver,
+			GroupBy:             r.GroupBy,
+			GroupByAll:          r.GroupByAll,
+			GroupWait:           model.Duration(r.GroupWait),
+			GroupInterval:       model.Duration(r.GroupInterval),
+			RepeatInterval:      model.Duration(r.RepeatInterval),
+			Continue:            r.Continue,
+			MuteTimeIntervals:   r.MuteTimeIntervals,
+			ActiveTimeIntervals: r.ActiveTimeIntervals,
+			MutedBy:             r.MutedBy,
+		})
+	}
+	for _, s := range res.Silences {
+		apiRes.Silences = append(apiRes.Silences, apimodels.TestRouteSilence{
+			ID:        s.ID,
+			Comment:   s.Comment,
+			CreatedBy: s.CreatedBy,
+			StartsAt:  s.StartsAt,
+			EndsAt:    s.EndsAt,
+		})
+	}
+	return apiRes
+}
+
 func (srv AlertmanagerSrv) AlertmanagerFor(orgID int64) (notifier.Alertmanager, *response.NormalResponse) {
 	am, err := srv.mam.AlertmanagerFor(orgID)
 	if err == nil {
@@ -0,0 +1,439 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+const (
+	hclRuleGroupType          = "grafana_rule_group"
+	hclContactPointType       = "grafana_contact_point"
+	hclNotificationPolicyType = "grafana_notification_policy"
+	hclMuteTimingType         = "grafana_mute_timing"
+
+	// maxImportSize is the maximum size of the HCL accepted by the import.
+	maxImportSize = 10 * 1024 * 1024
+)
+
+// importOrder is the order in which the resources are applied so that the resources they reference exist.
+var importOrder = []string{hclMuteTimingType, hclContactPointType, hclNotificationPolicyType, hclRuleGroupType}
+
+// importStep is the change of a single imported resource. apply performs the change.
+type importStep struct {
+	change definitions.ProvisioningImportChange
+	apply  func(ctx context.Context) error
+}
+
+func newImportResourceBody(resourceType string) (interface{}, error) {
+	switch resourceType {
+	case hclRuleGroupType:
+		return &definitions.AlertRuleGroupExport{}, nil
+	case hclContactPointType:
+		return &definitions.ContactPoint{}, nil
+	case hclNotificationPolicyType:
+		return &definitions.RouteExport{}, nil
+	case hclMuteTimingType:
+		return &definitions.MuteTimeIntervalExportHcl{}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported resource type, expected one of %s", provisioning.ErrValidation, strings.Join(importOrder, ", "))
+}
+
+func (srv *ProvisioningSrv) RoutePostProvisioningImport(c *contextmodel.ReqContext) response.Response {
+	data, err := io.ReadAll(io.LimitReader(c.Req.Body, maxImportSize+1))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read request body")
+	}
+	if len(data) > maxImportSize {
+		return ErrResp(http.StatusRequestEntityTooLarge, fmt.Errorf("HCL must not be larger than %d bytes", maxImportSize), "")
+	}
+
+	resources, err := hcl.Decode(data, "import.tf", newImportResourceBody)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to decode HCL")
+	}
+
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	steps, err := srv.planImport(c.Req.Context(), c.SignedInUser, resources, provenance)
+	if err != nil {
+		return importErrorResponse(err)
+	}
+
+	result := definitions.ProvisioningImportResult{
+		DryRun:  c.QueryBoolWithDefault("dryRun", false),
+		Changes: make([]definitions.ProvisioningImportChange, 0, len(steps)),
+	}
+	for _, step := range steps {
+		result.Changes = append(result.Changes, step.change)
+	}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	// The resources are applied one by one, an error stops the import but does not revert the resources applied before.
+	for _, step := range steps {
+		if step.change.Action == definitions.ProvisioningImportActionNone {
+			continue
+		}
+		if err := step.apply(c.Req.Context()); err != nil {
+			return importErrorResponse(fmt.Errorf("failed to import %s: %w", step.change.Resource, err))
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func importErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, provisioning.ErrValidation),
+		errors.Is(err, alerting_models.ErrAlertRuleFailedValidation),
+		errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation):
+		return ErrResp(http.StatusBadRequest, err, "")
+	case errors.Is(err, store.ErrOptimisticLock):
+		return ErrResp(http.StatusConflict, err, "")
+	case errors.Is(err, store.ErrNoAlertmanagerConfiguration):
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "failed to import resources", err)
+}
+
+// planImport calculates the changes of the imported resources without applying them.
+func (srv *ProvisioningSrv) planImport(ctx context.Context, user identity.Requester, resources []hcl.Resource, provenance alerting_models.Provenance) ([]importStep, error) {
+	steps := make([]importStep, 0, len(resources))
+	seen := make(map[string]string, len(resources))
+	for _, resourceType := range importOrder {
+		for _, resource := range resources {
+			if resource.Type != resourceType {
+				continue
+			}
+			address := resource.Type + "." + resource.Name
+
+			var step importStep
+			var err error
+			switch body := resource.Body.(type) {
+			case *definitions.AlertRuleGroupExport:
+				step, err = srv.planRuleGroupImport(ctx, user, body, provenance)
+			case *definitions.ContactPoint:
+				step, err = srv.planContactPointImport(ctx, user, body, provenance)
+			case *definitions.RouteExport:
+				step, err = srv.planPolicyTreeImport(ctx, user, body, provenance)
+			case *definitions.MuteTimeIntervalExportHcl:
+				step, err = srv.planMuteTimingImport(ctx, user, body, provenance)
+			default:
+				err = fmt.Errorf("%w: unsupported resource type", provisioning.ErrValidation)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("resource %s: %w", address, err)
+			}
+
+			key := resource.Type + "/" + step.change.Name
+			if other, ok := seen[key]; ok {
+				return nil, fmt.Errorf("%w: resources %s and %s define the same %s", provisioning.ErrValidation, other, address, step.change.Name)
+			}
+			seen[key] = address
+
+			step.change.Resource = address
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+func (srv *ProvisioningSrv) planRuleGroupImport(ctx context.Context, user identity.Requester, body *definitions.AlertRuleGroupExport, provenance alerting_models.Provenance) (importStep, error) {
+	if body.FolderUID == "" || body.Name == "" {
+		return importStep{}, fmt.Errorf("%w: folder_uid and name must not be empty", provisioning.ErrValidation)
+	}
+	group, err := AlertRuleGroupFromAlertRuleGroupExport(user.GetOrgID(), *body)
+	if err != nil {
+		return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+	}
+
+	step := importStep{
+		change: definitions.ProvisioningImportChange{
+			Name:   group.FolderUID + "/" + group.Title,
+			Action: definitions.ProvisioningImportActionCreate,
+		},
+	}
+
+	existing, err := srv.alertRules.GetRuleGroup(ctx, user, group.FolderUID, group.Title)
+	if err != nil && !errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+		return importStep{}, err
+	}
+	if err == nil {
+		// HCL does not contain the UID and the instance limit of the rules, the existing rules are matched by title to keep them.
+		byTitle := make(map[string]alerting_models.AlertRule, len(existing.Rules))
+		for _, r := range existing.Rules {
+			byTitle[r.Title] = r
+		}
+		for i := range group.Rules {
+			current, ok := byTitle[group.Rules[i].Title]
+			if !ok {
+				continue
+			}
+			if group.Rules[i].UID == "" {
+				group.Rules[i].UID = current.UID
+			}
+			group.Rules[i].MaxInstances = current.MaxInstances
+		}
+
+		current, err := ruleGroupExport(user.GetOrgID(), existing)
+		if err != nil {
+			return importStep{}, err
+		}
+		imported, err := ruleGroupExport(user.GetOrgID(), group)
+		if err != nil {
+			return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+		}
+		step.change.Diff = importDiff(current, imported,
+			cmpopts.IgnoreFields(definitions.AlertRuleGroupExport{}, "Folder"),
+			cmpopts.IgnoreFields(definitions.AlertRuleExport{}, "UID", "DashboardUID", "PanelID"),
+			cmpopts.IgnoreFields(definitions.AlertQueryExport{}, "ModelString"),
+		)
+		step.change.Action = updateOrNone(step.change.Diff)
+	}
+
+	step.apply = func(ctx context.Context) error {
+		return srv.alertRules.ReplaceRuleGroup(ctx, user, group, provenance)
+	}
+	return step, nil
+}
+
+func (srv *ProvisioningSrv) planContactPointImport(ctx context.Context, user identity.Requester, body *definitions.ContactPoint, provenance alerting_models.Provenance) (importStep, error) {
+	if body.Name == "" {
+		return importStep{}, fmt.Errorf("%w: name must not be empty", provisioning.ErrValidation)
+	}
+	imported, err := EmbeddedContactPointsFromContactPoint(*body)
+	if err != nil {
+		return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+	}
+
+	step := importStep{
+		change: definitions.ProvisioningImportChange{
+			Name:   body.Name,
+			Action: definitions.ProvisioningImportActionCreate,
+		},
+	}
+
+	existing, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: user.GetOrgID(), Name: body.Name}, user)
+	if err != nil {
+		return importStep{}, err
+	}
+
+	// HCL does not contain the UID of the integrations, the existing integrations are matched by type in order.
+	// Existing integrations that are not matched are removed from the contact point.
+	unmatched := make(map[string][]string, len(existing))
+	for _, e := range existing {
+		unmatched[e.Type] = append(unmatched[e.Type], e.UID)
+	}
+	for i := range imported {
+		if uids := unmatched[imported[i].Type]; len(uids) > 0 {
+			imported[i].UID = uids[0]
+			unmatched[imported[i].Type] = uids[1:]
+		}
+	}
+
+	if len(existing) > 0 {
+		current, err := contactPointFromEmbeddedContactPoints(user.GetOrgID(), existing)
+		if err != nil {
+			return importStep{}, err
+		}
+		normalized, err := contactPointFromEmbeddedContactPoints(user.GetOrgID(), imported)
+		if err != nil {
+			return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+		}
+		step.change.Diff = importDiff(current, normalized)
+		step.change.Action = updateOrNone(step.change.Diff)
+	}
+
+	step.apply = func(ctx context.Context) error {
+		for _, cp := range imported {
+			if cp.UID == "" {
+				if _, err := srv.contactPointService.CreateContactPoint(ctx, user.GetOrgID(), cp, provenance); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := srv.contactPointService.UpdateContactPoint(ctx, user.GetOrgID(), cp, provenance); err != nil {
+				return err
+			}
+		}
+		for _, uids := range unmatched {
+			for _, uid := range uids {
+				if err := srv.contactPointService.DeleteContactPoint(ctx, user.GetOrgID(), uid); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return step, nil
+}
+
+func (srv *ProvisioningSrv) planPolicyTreeImport(ctx context.Context, user identity.Requester, body *definitions.RouteExport, provenance alerting_models.Provenance) (importStep, error) {
+	route, err := RouteFromRouteExport(body)
+	if err != nil {
+		return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+	}
+
+	existing, err := srv.policies.GetPolicyTree(ctx, user.GetOrgID())
+	if err != nil {
+		return importStep{}, err
+	}
+
+	diff := importDiff(RouteExportFromRoute(&existing), RouteExportFromRoute(route),
+		cmpopts.IgnoreFields(definitions.RouteExport{}, "Match", "MatchRE", "Matchers", "ObjectMatchers"),
+	)
+	return importStep{
+		change: definitions.ProvisioningImportChange{
+			Name:   "notification policy tree",
+			Action: updateOrNone(diff),
+			Diff:   diff,
+		},
+		apply: func(ctx context.Context) error {
+			return srv.policies.UpdatePolicyTree(ctx, user.GetOrgID(), *route, provenance)
+		},
+	}, nil
+}
+
+func (srv *ProvisioningSrv) planMuteTimingImport(ctx context.Context, user identity.Requester, body *definitions.MuteTimeIntervalExportHcl, provenance alerting_models.Provenance) (importStep, error) {
+	mt, err := MuteTimingFromMuteTimeIntervalExportHcl(*body)
+	if err != nil {
+		return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+	}
+	mt.Provenance = definitions.Provenance(provenance)
+
+	step := importStep{
+		change: definitions.ProvisioningImportChange{
+			Name:   mt.Name,
+			Action: definitions.ProvisioningImportActionCreate,
+		},
+	}
+
+	existing, err := srv.muteTimings.GetMuteTiming(ctx, mt.Name, user.GetOrgID())
+	if err != nil && !errors.Is(err, provisioning.ErrTimeIntervalNotFound) {
+		return importStep{}, err
+	}
+	if err == nil {
+		current, err := MuteTimingIntervalToMuteTimeIntervalHclExport(MuteTimeIntervalExportFromMuteTiming(user.GetOrgID(), existing))
+		if err != nil {
+			return importStep{}, err
+		}
+		imported, err := MuteTimingIntervalToMuteTimeIntervalHclExport(MuteTimeIntervalExportFromMuteTiming(user.GetOrgID(), mt))
+		if err != nil {
+			return importStep{}, fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+		}
+		step.change.Diff = importDiff(current, imported)
+		step.change.Action = updateOrNone(step.change.Diff)
+	}
+
+	create := step.change.Action == definitions.ProvisioningImportActionCreate
+	step.apply = func(ctx context.Context) error {
+		if create {
+			_, err := srv.muteTimings.CreateMuteTiming(ctx, mt, user.GetOrgID())
+			return err
+		}
+		_, err := srv.muteTimings.UpdateMuteTiming(ctx, mt, user.GetOrgID())
+		return err
+	}
+	return step, nil
+}
+
+// ruleGroupExport converts the rule group to the representation that is used in HCL.
+func ruleGroupExport(orgID int64, group alerting_models.AlertRuleGroup) (definitions.AlertRuleGroupExport, error) {
+	return AlertRuleGroupExportFromAlertRuleGroupWithFolderTitle(alerting_models.AlertRuleGroupWithFolderTitle{
+		AlertRuleGroup: &group,
+		OrgID:          orgID,
+	})
+}
+
+// contactPointFromEmbeddedContactPoints converts the integrations of a contact point to the representation that is used in HCL.
+func contactPointFromEmbeddedContactPoints(orgID int64, ecps []definitions.EmbeddedContactPoint) (definitions.ContactPoint, error) {
+	export, err := AlertingFileExportFromEmbeddedContactPoints(orgID, ecps)
+	if err != nil {
+		return definitions.ContactPoint{}, err
+	}
+	if len(export.ContactPoints) == 0 {
+		return definitions.ContactPoint{}, nil
+	}
+	return ContactPointFromContactPointExport(export.ContactPoints[0])
+}
+
+func updateOrNone(diff string) definitions.ProvisioningImportAction {
+	if diff == "" {
+		return definitions.ProvisioningImportActionNone
+	}
+	return definitions.ProvisioningImportActionUpdate
+}
+
+// importDiff returns the differences between the current and the imported representation of a resource, one field per line.
+// Secrets are never included in the diff. The imported secrets that are redacted keep the current value and are not reported.
+func importDiff(current, imported any, opts ...cmp.Option) string {
+	reporter := cmputil.DiffReporter{}
+	opts = append(opts, cmp.Reporter(&reporter), cmpopts.EquateEmpty())
+	if cmp.Equal(current, imported, opts...) {
+		return ""
+	}
+
+	b := strings.Builder{}
+	for _, d := range reporter.Diffs {
+		if secret, ok := secretValue(d.Right); ok && secret == definitions.RedactedValue {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s -> %s\n", d.Path, describeImportValue(d.Left), describeImportValue(d.Right))
+	}
+	return b.String()
+}
+
+var secretType = reflect.TypeOf(definitions.Secret(""))
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func secretValue(v reflect.Value) (string, bool) {
+	v = indirect(v)
+	if !v.IsValid() || v.Type() != secretType {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func describeImportValue(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return "<none>"
+	}
+	if _, ok := secretValue(v); ok {
+		return definitions.RedactedValue
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Struct:
+		// structs are added or removed elements of a list, e.g. integrations of a contact point that can contain secrets.
+		return v.Type().Name() + "{...}"
+	}
+	if !v.CanInterface() {
+		return v.String()
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
@@ -110,6 +110,11 @@ func validateRuleNode(
 		ExecErrState:    errorState,
 	}
 
+	if ruleNode.GrafanaManagedAlert.MaxInstances < 0 {
+		return nil, errors.New("max_instances cannot be negative")
+	}
+	newAlertRule.MaxInstances = ruleNode.GrafanaManagedAlert.MaxInstances
+
 	if ruleNode.GrafanaManagedAlert.NotificationSettings != nil {
 		newAlertRule.NotificationSettings, err = validateNotificationSettings(ruleNode.GrafanaManagedAlert.NotificationSettings)
 		if err != nil {
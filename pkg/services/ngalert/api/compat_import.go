@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertRuleGroupFromAlertRuleGroupExport converts definitions.AlertRuleGroupExport decoded from HCL to models.AlertRuleGroup.
+// Only the fields that are part of the HCL representation are converted.
+func AlertRuleGroupFromAlertRuleGroupExport(orgID int64, d definitions.AlertRuleGroupExport) (models.AlertRuleGroup, error) {
+	group := models.AlertRuleGroup{
+		Title:     d.Name,
+		FolderUID: d.FolderUID,
+		Interval:  d.IntervalSeconds,
+		Rules:     make([]models.AlertRule, 0, len(d.Rules)),
+	}
+	for _, r := range d.Rules {
+		rule, err := AlertRuleFromAlertRuleExport(r)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("rule %q: %w", r.Title, err)
+		}
+		rule.OrgID = orgID
+		rule.NamespaceUID = d.FolderUID
+		rule.RuleGroup = d.Name
+		rule.IntervalSeconds = d.IntervalSeconds
+		group.Rules = append(group.Rules, rule)
+	}
+	return group, nil
+}
+
+// AlertRuleFromAlertRuleExport converts definitions.AlertRuleExport decoded from HCL to models.AlertRule.
+func AlertRuleFromAlertRuleExport(r definitions.AlertRuleExport) (models.AlertRule, error) {
+	data := make([]models.AlertQuery, 0, len(r.Data))
+	for _, q := range r.Data {
+		query, err := AlertQueryFromAlertQueryExport(q)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("query %q: %w", q.RefID, err)
+		}
+		data = append(data, query)
+	}
+
+	noDataState, err := models.NoDataStateFromString(string(r.NoDataState))
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	execErrState, err := models.ErrStateFromString(string(r.ExecErrState))
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	forDuration := time.Duration(r.For)
+	if r.ForString != nil {
+		d, err := model.ParseDuration(*r.ForString)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("invalid for: %w", err)
+		}
+		forDuration = time.Duration(d)
+	}
+
+	ns, err := NotificationSettingsFromAlertRuleNotificationSettingsExport(r.NotificationSettings)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	result := models.AlertRule{
+		UID:                  r.UID,
+		Title:                r.Title,
+		Condition:            r.Condition,
+		Data:                 data,
+		DashboardUID:         r.DashboardUID,
+		PanelID:              r.PanelID,
+		NoDataState:          noDataState,
+		ExecErrState:         execErrState,
+		For:                  forDuration,
+		IsPaused:             r.IsPaused,
+		NotificationSettings: ns,
+		MaxInstances:         r.MaxInstances,
+	}
+	if r.Annotations != nil {
+		result.Annotations = *r.Annotations
+	}
+	if r.Labels != nil {
+		result.Labels = *r.Labels
+	}
+	return result, nil
+}
+
+// AlertQueryFromAlertQueryExport converts definitions.AlertQueryExport decoded from HCL to models.AlertQuery.
+func AlertQueryFromAlertQueryExport(q definitions.AlertQueryExport) (models.AlertQuery, error) {
+	mdl := json.RawMessage(q.ModelString)
+	if q.ModelString == "" {
+		raw, err := json.Marshal(q.Model)
+		if err != nil {
+			return models.AlertQuery{}, err
+		}
+		mdl = raw
+	}
+	if !json.Valid(mdl) {
+		return models.AlertQuery{}, errors.New("model is not a valid JSON")
+	}
+
+	result := models.AlertQuery{
+		RefID: q.RefID,
+		RelativeTimeRange: models.RelativeTimeRange{
+			From: models.Duration(time.Duration(q.RelativeTimeRange.FromSeconds) * time.Second),
+			To:   models.Duration(time.Duration(q.RelativeTimeRange.ToSeconds) * time.Second),
+		},
+		DatasourceUID: q.DatasourceUID,
+		Model:         mdl,
+	}
+	if q.QueryType != nil {
+		result.QueryType = *q.QueryType
+	}
+	return result, nil
+}
+
+// NotificationSettingsFromAlertRuleNotificationSettingsExport converts definitions.AlertRuleNotificationSettingsExport to []models.NotificationSettings
+func NotificationSettingsFromAlertRuleNotificationSettingsExport(ns *definitions.AlertRuleNotificationSettingsExport) ([]models.NotificationSettings, error) {
+	if ns == nil {
+		return nil, nil
+	}
+	groupWait, err := parseDurationIfNotNil(ns.GroupWait)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group_wait: %w", err)
+	}
+	groupInterval, err := parseDurationIfNotNil(ns.GroupInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group_interval: %w", err)
+	}
+	repeatInterval, err := parseDurationIfNotNil(ns.RepeatInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repeat_interval: %w", err)
+	}
+	return []models.NotificationSettings{
+		{
+			Receiver:          ns.Receiver,
+			GroupBy:           ns.GroupBy,
+			GroupWait:         groupWait,
+			GroupInterval:     groupInterval,
+			RepeatInterval:    repeatInterval,
+			MuteTimeIntervals: ns.MuteTimeIntervals,
+		},
+	}, nil
+}
+
+// EmbeddedContactPointsFromContactPoint converts definitions.ContactPoint decoded from HCL to the list of
+// definitions.EmbeddedContactPoint, one per integration.
+func EmbeddedContactPointsFromContactPoint(cp definitions.ContactPoint) ([]definitions.EmbeddedContactPoint, error) {
+	receiver, err := ContactPointToContactPointExport(cp)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]definitions.EmbeddedContactPoint, 0, len(receiver.GrafanaIntegrations.Integrations))
+	for _, integration := range receiver.GrafanaIntegrations.Integrations {
+		settings, err := simplejson.NewJson(integration.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse settings of %s integration: %w", integration.Type, err)
+		}
+		result = append(result, definitions.EmbeddedContactPoint{
+			Name:                  cp.Name,
+			Type:                  integration.Type,
+			Settings:              settings,
+			DisableResolveMessage: integration.DisableResolveMessage,
+		})
+	}
+	return result, nil
+}
+
+// RouteFromRouteExport converts definitions.RouteExport decoded from HCL to definitions.Route.
+func RouteFromRouteExport(export *definitions.RouteExport) (*definitions.Route, error) {
+	route := definitions.Route{
+		Receiver:       export.Receiver,
+		Match:          export.Match,
+		MatchRE:        export.MatchRE,
+		Matchers:       export.Matchers,
+		ObjectMatchers: export.ObjectMatchers,
+	}
+	if export.GroupByStr != nil {
+		route.GroupByStr = *export.GroupByStr
+	}
+	if export.MuteTimeIntervals != nil {
+		route.MuteTimeIntervals = *export.MuteTimeIntervals
+	}
+	if export.Continue != nil {
+		route.Continue = *export.Continue
+	}
+
+	// HCL represents object matchers as blocks, they take precedence over the object matchers of the file export.
+	if len(export.ObjectMatchersSlice) > 0 {
+		route.ObjectMatchers = make(definitions.ObjectMatchers, 0, len(export.ObjectMatchersSlice))
+		for _, m := range export.ObjectMatchersSlice {
+			matcher, err := matcherFromMatcherExport(m)
+			if err != nil {
+				return nil, err
+			}
+			route.ObjectMatchers = append(route.ObjectMatchers, matcher)
+		}
+	}
+
+	var err error
+	if route.GroupWait, err = parseDurationIfNotNil(export.GroupWait); err != nil {
+		return nil, fmt.Errorf("invalid group_wait: %w", err)
+	}
+	if route.GroupInterval, err = parseDurationIfNotNil(export.GroupInterval); err != nil {
+		return nil, fmt.Errorf("invalid group_interval: %w", err)
+	}
+	if route.RepeatInterval, err = parseDurationIfNotNil(export.RepeatInterval); err != nil {
+		return nil, fmt.Errorf("invalid repeat_interval: %w", err)
+	}
+
+	for _, r := range export.Routes {
+		child, err := RouteFromRouteExport(r)
+		if err != nil {
+			return nil, err
+		}
+		route.Routes = append(route.Routes, child)
+	}
+	return &route, nil
+}
+
+func matcherFromMatcherExport(m *definitions.MatcherExport) (*labels.Matcher, error) {
+	for _, t := range []labels.MatchType{labels.MatchEqual, labels.MatchNotEqual, labels.MatchRegexp, labels.MatchNotRegexp} {
+		if t.String() == m.Match {
+			return labels.NewMatcher(t, m.Label, m.Value)
+		}
+	}
+	return nil, fmt.Errorf("invalid match type %q of matcher for label %q", m.Match, m.Label)
+}
+
+// MuteTimingFromMuteTimeIntervalExportHcl converts definitions.MuteTimeIntervalExportHcl to definitions.MuteTimeInterval using JSON marshalling.
+// Returns error if structure could not be marshalled\unmarshalled
+func MuteTimingFromMuteTimeIntervalExportHcl(m definitions.MuteTimeIntervalExportHcl) (definitions.MuteTimeInterval, error) {
+	result := definitions.MuteTimeInterval{}
+	j := jsoniter.ConfigCompatibleWithStandardLibrary
+	mdata, err := j.Marshal(m)
+	if err != nil {
+		return result, err
+	}
+	err = j.Unmarshal(mdata, &result)
+	return result, err
+}
+
+func parseDurationIfNotNil(s *string) (*model.Duration, error) {
+	if s == nil {
+		return nil, nil
+	}
+	d, err := model.ParseDuration(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
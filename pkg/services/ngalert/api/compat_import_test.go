@@ -0,0 +1,174 @@
+package api
+
+import (
+	"os"
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestAlertRuleGroupFromAlertRuleGroupExport(t *testing.T) {
+	t.Run("converts the rule group exported to HCL back to the same rule group", func(t *testing.T) {
+		expected, err := os.ReadFile("test-data/post-rulegroup-101-export.hcl")
+		require.NoError(t, err)
+
+		resources, err := hcl.Decode(expected, "export.tf", newImportResourceBody)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+
+		group, err := AlertRuleGroupFromAlertRuleGroupExport(1, *resources[0].Body.(*definitions.AlertRuleGroupExport))
+		require.NoError(t, err)
+		require.Equal(t, "group101", group.Title)
+		require.Equal(t, "e4584834-1a87-4dff-8913-8a4748dfca79", group.FolderUID)
+		require.Len(t, group.Rules, 2)
+		for _, rule := range group.Rules {
+			require.Equal(t, int64(1), rule.OrgID)
+			require.Equal(t, group.Title, rule.RuleGroup)
+			require.Equal(t, group.FolderUID, rule.NamespaceUID)
+		}
+
+		export, err := ruleGroupExport(1, group)
+		require.NoError(t, err)
+		actual, err := hcl.Encode(hcl.Resource{Type: hclRuleGroupType, Name: resources[0].Name, Body: &export})
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(actual))
+	})
+
+	t.Run("fails if duration is invalid", func(t *testing.T) {
+		_, err := AlertRuleGroupFromAlertRuleGroupExport(1, definitions.AlertRuleGroupExport{
+			Name:      "group",
+			FolderUID: "folder",
+			Rules: []definitions.AlertRuleExport{{
+				Title:        "rule",
+				NoDataState:  definitions.NoData,
+				ExecErrState: definitions.AlertingErrState,
+				ForString:    util.Pointer("five minutes"),
+			}},
+		})
+		require.ErrorContains(t, err, "invalid for")
+	})
+
+	t.Run("fails if model is not JSON", func(t *testing.T) {
+		_, err := AlertRuleGroupFromAlertRuleGroupExport(1, definitions.AlertRuleGroupExport{
+			Name:      "group",
+			FolderUID: "folder",
+			Rules: []definitions.AlertRuleExport{{
+				Title:        "rule",
+				NoDataState:  definitions.NoData,
+				ExecErrState: definitions.AlertingErrState,
+				Data:         []definitions.AlertQueryExport{{RefID: "A", ModelString: "{"}},
+			}},
+		})
+		require.ErrorContains(t, err, "model is not a valid JSON")
+	})
+}
+
+func TestMuteTimingFromMuteTimeIntervalExportHcl(t *testing.T) {
+	expected, err := os.ReadFile("test-data/alertmanager_default_mutetimings-export.hcl")
+	require.NoError(t, err)
+
+	resources, err := hcl.Decode(expected, "export.tf", newImportResourceBody)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	encoded := make([]hcl.Resource, 0, len(resources))
+	for _, r := range resources {
+		mt, err := MuteTimingFromMuteTimeIntervalExportHcl(*r.Body.(*definitions.MuteTimeIntervalExportHcl))
+		require.NoError(t, err)
+
+		export, err := MuteTimingIntervalToMuteTimeIntervalHclExport(MuteTimeIntervalExportFromMuteTiming(1, mt))
+		require.NoError(t, err)
+		encoded = append(encoded, hcl.Resource{Type: hclMuteTimingType, Name: r.Name, Body: export})
+	}
+
+	actual, err := hcl.Encode(encoded...)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestRouteFromRouteExport(t *testing.T) {
+	t.Run("converts the route exported to HCL back to the same route", func(t *testing.T) {
+		matcher, err := labels.NewMatcher(labels.MatchNotEqual, "team", "ops")
+		require.NoError(t, err)
+		route := &definitions.Route{
+			Receiver:   "default",
+			GroupByStr: []string{"alertname"},
+			GroupWait:  util.Pointer(model.Duration(30_000_000_000)),
+			Routes: []*definitions.Route{
+				{
+					Receiver:          "team",
+					ObjectMatchers:    definitions.ObjectMatchers{matcher},
+					MuteTimeIntervals: []string{"weekends"},
+					Continue:          true,
+					RepeatInterval:    util.Pointer(model.Duration(3_600_000_000_000)),
+				},
+			},
+		}
+		expected := RouteExportFromRoute(route)
+
+		encoded, err := hcl.Encode(hcl.Resource{Type: hclNotificationPolicyType, Name: "policy", Body: expected})
+		require.NoError(t, err)
+		resources, err := hcl.Decode(encoded, "export.tf", newImportResourceBody)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+
+		actual, err := RouteFromRouteExport(resources[0].Body.(*definitions.RouteExport))
+		require.NoError(t, err)
+		require.Equal(t, "default", actual.Receiver)
+		require.Equal(t, []string{"alertname"}, actual.GroupByStr)
+		require.Equal(t, route.GroupWait, actual.GroupWait)
+		require.Len(t, actual.Routes, 1)
+		require.Equal(t, "team", actual.Routes[0].Receiver)
+		require.Len(t, actual.Routes[0].ObjectMatchers, 1)
+		require.Equal(t, matcher.String(), actual.Routes[0].ObjectMatchers[0].String())
+		require.Equal(t, []string{"weekends"}, actual.Routes[0].MuteTimeIntervals)
+		require.True(t, actual.Routes[0].Continue)
+		require.Equal(t, route.Routes[0].RepeatInterval, actual.Routes[0].RepeatInterval)
+	})
+
+	t.Run("fails if match type is invalid", func(t *testing.T) {
+		_, err := RouteFromRouteExport(&definitions.RouteExport{
+			Receiver:            "default",
+			ObjectMatchersSlice: []*definitions.MatcherExport{{Label: "team", Match: "==", Value: "ops"}},
+		})
+		require.ErrorContains(t, err, "invalid match type")
+	})
+}
+
+func TestEmbeddedContactPointsFromContactPoint(t *testing.T) {
+	cp := definitions.ContactPoint{
+		Name: "ops",
+		Email: []definitions.EmailIntegration{
+			{Addresses: []string{"ops@example.com"}},
+			{Addresses: []string{"oncall@example.com"}, DisableResolveMessage: util.Pointer(true)},
+		},
+		Webhook: []definitions.WebhookIntegration{
+			{URL: "http://localhost/hook"},
+		},
+	}
+
+	result, err := EmbeddedContactPointsFromContactPoint(cp)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	for _, ecp := range result {
+		require.Equal(t, "ops", ecp.Name)
+		require.Empty(t, ecp.UID)
+	}
+	require.Equal(t, "email", result[0].Type)
+	require.False(t, result[0].DisableResolveMessage)
+	require.Equal(t, "email", result[1].Type)
+	require.True(t, result[1].DisableResolveMessage)
+	require.Equal(t, "webhook", result[2].Type)
+	require.Equal(t, "http://localhost/hook", result[2].Settings.Get("url").MustString())
+
+	converted, err := contactPointFromEmbeddedContactPoints(1, result)
+	require.NoError(t, err)
+	require.Equal(t, cp.Email[0].Addresses, converted.Email[0].Addresses)
+	require.Equal(t, cp.Webhook[0].URL, converted.Webhook[0].URL)
+}
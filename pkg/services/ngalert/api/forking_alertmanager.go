@@ -190,6 +190,13 @@ func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contex
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
 
+func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaRoutes(ctx *contextmodel.ReqContext, conf apimodels.TestRoutesConfigBodyParams) response.Response {
+	if conf.Config != nil && !conf.Config.AlertmanagerConfig.ReceiverType().Can(apimodels.GrafanaReceiverType) {
+		return errorToResponse(backendTypeDoesNotMatchPayloadTypeError(apimodels.GrafanaBackend, conf.Config.AlertmanagerConfig.ReceiverType().String()))
+	}
+	return f.GrafanaSvc.RoutePostTestRoutes(ctx, conf)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaTemplates(ctx *contextmodel.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
 }
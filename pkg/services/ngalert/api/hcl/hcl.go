@@ -3,8 +3,12 @@ package hcl
 import (
 	"fmt"
 
+	hcl2 "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
 type Resource struct {
@@ -13,6 +17,15 @@ type Resource struct {
 	Body interface{} `hcl:",block"`
 }
 
+// evalContext provides the functions that are commonly used in Terraform to build attributes of the resources,
+// e.g. model = jsonencode({...}). Variables and references to other resources are not supported.
+var evalContext = &hcl2.EvalContext{
+	Functions: map[string]function.Function{
+		"jsonencode": stdlib.JSONEncodeFunc,
+		"jsondecode": stdlib.JSONDecodeFunc,
+	},
+}
+
 func Encode(resources ...Resource) (data []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -28,3 +41,47 @@ func Encode(resources ...Resource) (data []byte, err error) {
 	}
 	return f.Bytes(), nil
 }
+
+// Decode parses resource blocks from HCL. The body of every resource is decoded into the pointer to a struct that
+// newBody returns for the type of the resource. Blocks other than resource, e.g. terraform or provider, are ignored.
+func Decode(data []byte, filename string, newBody func(resourceType string) (interface{}, error)) (resources []Resource, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode HCL to struct: %v", r)
+		}
+	}()
+
+	file, diags := hclparse.NewParser().ParseHCL(data, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var content struct {
+		Resources []struct {
+			Type string    `hcl:"type,label"`
+			Name string    `hcl:"name,label"`
+			Body hcl2.Body `hcl:",remain"`
+		} `hcl:"resource,block"`
+		Remain hcl2.Body `hcl:",remain"`
+	}
+	if diags := gohcl.DecodeBody(file.Body, evalContext, &content); diags.HasErrors() {
+		return nil, diags
+	}
+
+	resources = make([]Resource, 0, len(content.Resources))
+	for _, r := range content.Resources {
+		body, err := newBody(r.Type)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s.%s: %w", r.Type, r.Name, err)
+		}
+		if diags := gohcl.DecodeBody(r.Body, evalContext, body); diags.HasErrors() {
+			return nil, diags
+		}
+		resources = append(resources, Resource{
+			Type: r.Type,
+			Name: r.Name,
+			Body: body,
+		})
+	}
+	return resources, nil
+}
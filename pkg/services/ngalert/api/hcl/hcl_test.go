@@ -1,6 +1,7 @@
 package hcl
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -73,3 +74,82 @@ func TestEncode(t *testing.T) {
 }
 `, string(encoded))
 }
+
+func TestDecode(t *testing.T) {
+	type data struct {
+		Name      string   `hcl:"name"`
+		Number    float64  `hcl:"number"`
+		NumberRef *float64 `hcl:"numberRef"`
+		Model     *string  `hcl:"model"`
+		Blocks    []data   `hcl:"blocks,block"`
+	}
+
+	newBody := func(resourceType string) (interface{}, error) {
+		if resourceType != "grafana_test" {
+			return nil, fmt.Errorf("unsupported resource type %q", resourceType)
+		}
+		return &data{}, nil
+	}
+
+	t.Run("decodes resources and ignores other blocks", func(t *testing.T) {
+		resources, err := Decode([]byte(`
+provider "grafana" {
+  url = "http://localhost:3000"
+}
+
+resource "grafana_test" "test-01" {
+  name   = "test"
+  number = 123
+  model  = jsonencode({ "refId" = "A" })
+
+  blocks {
+    name   = "el-0"
+    number = 1
+  }
+}
+`), "test.tf", newBody)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		require.Equal(t, "grafana_test", resources[0].Type)
+		require.Equal(t, "test-01", resources[0].Name)
+		require.Equal(t, &data{
+			Name:   "test",
+			Number: 123,
+			Model:  func(s string) *string { return &s }(`{"refId":"A"}`),
+			Blocks: []data{{Name: "el-0", Number: 1, Blocks: []data{}}},
+		}, resources[0].Body)
+	})
+
+	t.Run("decodes what Encode produces", func(t *testing.T) {
+		expected := &data{
+			Name:      "test",
+			Number:    1,
+			NumberRef: func(f float64) *float64 { return &f }(2),
+			Blocks:    []data{{Name: "el-0", Number: 3, Blocks: []data{}}},
+		}
+		encoded, err := Encode(Resource{Type: "grafana_test", Name: "test-01", Body: expected})
+		require.NoError(t, err)
+
+		resources, err := Decode(encoded, "test.tf", newBody)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		require.Equal(t, expected, resources[0].Body)
+	})
+
+	t.Run("fails on unsupported resource type", func(t *testing.T) {
+		_, err := Decode([]byte(`resource "grafana_folder" "folder" {}`), "test.tf", newBody)
+		require.ErrorContains(t, err, "grafana_folder.folder")
+	})
+
+	t.Run("fails on missing required attribute", func(t *testing.T) {
+		_, err := Decode([]byte(`resource "grafana_test" "test" {
+  number = 1
+}`), "test.tf", newBody)
+		require.ErrorContains(t, err, "name")
+	})
+
+	t.Run("fails on invalid syntax", func(t *testing.T) {
+		_, err := Decode([]byte(`resource "grafana_test" {`), "test.tf", newBody)
+		require.Error(t, err)
+	})
+}
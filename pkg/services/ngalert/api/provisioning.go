@@ -136,6 +136,10 @@ func (f *ProvisioningApiHandler) handleRouteExportMuteTimings(ctx *contextmodel.
 	return f.svc.RouteGetMuteTimingsExport(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRoutePostProvisioningImport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RoutePostProvisioningImport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteDeleteAlertRuleGroup(ctx *contextmodel.ReqContext, folderUID, group string) response.Response {
 	return f.svc.RouteDeleteAlertRuleGroup(ctx, folderUID, group)
 }
@@ -164,6 +164,19 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/routes/test alertmanager RoutePostTestGrafanaRoutes
+//
+// Test which notification policies an alert with the given labels would be routed to.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: TestRoutesResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+
 // swagger:route POST /alertmanager/grafana/config/api/v1/templates/test alertmanager RoutePostTestGrafanaTemplates
 //
 // Test Grafana managed templates without saving them.
@@ -323,6 +336,66 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestGrafanaRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfigBodyParams
+}
+
+type TestRoutesConfigBodyParams struct {
+	// Labels of the alert to route.
+	Labels model.LabelSet `json:"labels"`
+
+	// Time at which time intervals and silences are evaluated. Defaults to the current time.
+	Time *time.Time `json:"time,omitempty"`
+
+	// Configuration to evaluate instead of the current one. It has the same format as the payload of
+	// RoutePostGrafanaAlertingConfig and can be used to test a configuration before saving it.
+	Config *PostableUserConfig `json:"config,omitempty"`
+}
+
+// swagger:model
+type TestRoutesResults struct {
+	// Labels of the alert that was routed.
+	Labels model.LabelSet `json:"labels"`
+
+	// Time at which time intervals and silences were evaluated.
+	Time time.Time `json:"time"`
+
+	// Routes matched by the alert, in the order they are visited by the dispatcher.
+	Routes []TestRouteResult `json:"routes"`
+
+	// Silences that would suppress notifications for the alert.
+	Silences []TestRouteSilence `json:"silences,omitempty"`
+}
+
+type TestRouteResult struct {
+	// Key that uniquely identifies the route in the notification policy tree.
+	Key                 string         `json:"key"`
+	Matchers            []string       `json:"matchers,omitempty"`
+	Receiver            string         `json:"receiver"`
+	GroupBy             []string       `json:"group_by,omitempty"`
+	GroupByAll          bool           `json:"group_by_all,omitempty"`
+	GroupWait           model.Duration `json:"group_wait"`
+	GroupInterval       model.Duration `json:"group_interval"`
+	RepeatInterval      model.Duration `json:"repeat_interval"`
+	Continue            bool           `json:"continue"`
+	MuteTimeIntervals   []string       `json:"mute_time_intervals,omitempty"`
+	ActiveTimeIntervals []string       `json:"active_time_intervals,omitempty"`
+
+	// Time intervals that suppress notifications for this route at the evaluation time: mute time intervals
+	// that are active and active time intervals that are not.
+	MutedBy []string `json:"muted_by,omitempty"`
+}
+
+type TestRouteSilence struct {
+	ID        string    `json:"id"`
+	Comment   string    `json:"comment"`
+	CreatedBy string    `json:"createdBy"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+}
+
 // swagger:parameters RoutePostTestGrafanaTemplates
 type TestTemplatesConfigParams struct {
 	// in:body
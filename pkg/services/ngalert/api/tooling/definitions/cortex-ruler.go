@@ -487,6 +487,10 @@ type PostableGrafanaRule struct {
 	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
+	// The maximum number of alert instances the rule can produce. If the rule produces more, it goes into the Error state.
+	// 0 means that the default limit applies.
+	// minimum: 0
+	MaxInstances int64 `json:"max_instances,omitempty" yaml:"max_instances,omitempty"`
 }
 
 // swagger:model
@@ -507,6 +511,7 @@ type GettableGrafanaRule struct {
 	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	MaxInstances         int64                          `json:"max_instances,omitempty" yaml:"max_instances,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -0,0 +1,57 @@
+package definitions
+
+// swagger:route POST /v1/provisioning/import provisioning stable RoutePostProvisioningImport
+//
+// Import alert rule groups, contact points, notification policies and mute timings from Terraform HCL.
+// Supported resources are grafana_rule_group, grafana_contact_point, grafana_notification_policy and grafana_mute_timing.
+// Use dryRun to preview the changes without applying them.
+//
+//     Consumes:
+//     - application/terraform+hcl
+//     - text/hcl
+//
+//     Responses:
+//       200: ProvisioningImportResult
+//       400: ValidationError
+
+// swagger:parameters RoutePostProvisioningImport
+type ProvisioningImportParams struct {
+	// in:body
+	Body string
+
+	// Whether to only calculate the changes without applying them.
+	// in: query
+	// required: false
+	// default: false
+	DryRun bool `json:"dryRun"`
+}
+
+// ProvisioningImportAction is the action performed on a resource by an import.
+type ProvisioningImportAction string
+
+const (
+	ProvisioningImportActionCreate ProvisioningImportAction = "create"
+	ProvisioningImportActionUpdate ProvisioningImportAction = "update"
+	ProvisioningImportActionNone   ProvisioningImportAction = "none"
+)
+
+// swagger:model
+type ProvisioningImportResult struct {
+	// DryRun is true if the changes were calculated but not applied.
+	DryRun  bool                       `json:"dryRun"`
+	Changes []ProvisioningImportChange `json:"changes"`
+}
+
+// ProvisioningImportChange describes the change of a single resource of the imported HCL.
+type ProvisioningImportChange struct {
+	// Address of the resource in the imported HCL.
+	// example: grafana_contact_point.contact_point_0
+	Resource string `json:"resource"`
+	// Name of the resource in Grafana. Rule groups are identified by folder UID and group name.
+	// example: my-contact-point
+	Name string `json:"name"`
+	// enum: create,update,none
+	Action ProvisioningImportAction `json:"action"`
+	// Diff lists the changed fields with the current and the imported value.
+	Diff string `json:"diff,omitempty"`
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
@@ -792,6 +793,9 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 			if !p.Backend {
 				return fmt.Errorf("datasource refID %s is not a backend datasource", query.RefID)
 			}
+			if err := validateLabelPolicies(ctx.User, query.DataSource); err != nil {
+				return fmt.Errorf("datasource refID %s: %w", query.RefID, err)
+			}
 		case expr.TypeMLNode:
 			_, found := e.pluginsStore.Plugin(ctx.Ctx, query.DataSource.Type)
 			if !found {
@@ -804,6 +808,32 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 	return err
 }
 
+// validateLabelPolicies returns an error if the label policies of the data source restrict the queries of the user.
+// Rules are evaluated without the user that saved them, so their queries would not be restricted.
+func validateLabelPolicies(user identity.Requester, ds *datasources.DataSource) error {
+	if !datasources.SupportsLabelPolicies(ds.Type) {
+		return nil
+	}
+	labelPolicies, err := ds.LabelPolicies()
+	if err != nil {
+		return datasources.ErrLabelPolicyAccessDenied.Errorf("invalid label policies: %w", err)
+	}
+	if len(labelPolicies) == 0 {
+		return nil
+	}
+	if user == nil {
+		return datasources.ErrLabelPolicyAccessDenied.Errorf("the data source has label policies and the user is unknown")
+	}
+	rules, err := datasources.LabelPolicyRules(labelPolicies, user)
+	if err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		return datasources.ErrLabelPolicyAccessDenied.Errorf("rules cannot query a data source whose label policies restrict the user")
+	}
+	return nil
+}
+
 func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition) (ConditionEvaluator, error) {
 	if len(condition.Data) == 0 {
 		return nil, errors.New("expression list is empty. must be at least 1 expression")
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -524,6 +525,37 @@ func TestValidate(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "fail if the label policies of the datasource restrict the user",
+			error: true,
+			condition: func(services services) models.Condition {
+				dsQuery := models.GenerateAlertQuery()
+				ds := &datasources.DataSource{
+					UID:  dsQuery.DatasourceUID,
+					Type: datasources.DS_PROMETHEUS,
+					JsonData: simplejson.NewFromAny(map[string]any{
+						datasources.LabelPoliciesJSONDataKey: []any{
+							map[string]any{"role": "Viewer", "rules": []string{`namespace="public"`}},
+						},
+					}),
+				}
+				services.cache.DataSources = append(services.cache.DataSources, ds)
+				services.pluginsStore.PluginList = append(services.pluginsStore.PluginList, pluginstore.Plugin{
+					JSONData: plugins.JSONData{
+						ID:      ds.Type,
+						Backend: true,
+					},
+				})
+
+				return models.Condition{
+					Condition: "B",
+					Data: []models.AlertQuery{
+						dsQuery,
+						models.CreateClassicConditionExpression("B", dsQuery.RefID, "last", "gt", rand.Int()),
+					},
+				}
+			},
+		},
 		{
 			name:  "fail if hysteresis command is not the condition",
 			error: true,
@@ -249,6 +249,8 @@ type AlertRule struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// MaxInstances is the maximum number of alert instances the rule can produce. 0 means that the default limit applies.
+	MaxInstances int64 `xorm:"max_instances"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -514,6 +516,14 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		}
 	}
 
+	if alertRule.MaxInstances < 0 {
+		return fmt.Errorf("%w: field `max_instances` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if cfg.MaxInstancesPerRule > 0 && alertRule.MaxInstances > cfg.MaxInstancesPerRule {
+		return fmt.Errorf("%w: field `max_instances` cannot be greater than %d", ErrAlertRuleFailedValidation, cfg.MaxInstancesPerRule)
+	}
+
 	if len(alertRule.NotificationSettings) > 0 {
 		if len(alertRule.NotificationSettings) != 1 {
 			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
@@ -570,6 +580,8 @@ type AlertRuleVersion struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// MaxInstances is the maximum number of alert instances the rule can produce. 0 means that the default limit applies.
+	MaxInstances int64 `xorm:"max_instances"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
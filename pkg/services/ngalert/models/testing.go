@@ -488,6 +488,12 @@ func (a *AlertRuleMutators) WithIsPaused(paused bool) AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithMaxInstances(limit int64) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.MaxInstances = limit
+	}
+}
+
 func (g *AlertRuleGenerator) GenerateLabels(min, max int, prefix string) data.Labels {
 	count := max
 	if min > max {
@@ -569,6 +575,7 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		MaxInstances:    r.MaxInstances,
 	}
 
 	if r.DashboardUID != nil {
@@ -288,6 +288,9 @@ func (ng *AlertNG) init() error {
 		ApplyNoDataAndErrorToAllStates: ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingNoDataErrorExecution),
 		MaxStateSaveConcurrency:        ng.Cfg.UnifiedAlerting.MaxStateSaveConcurrency,
 		RulesPerRuleGroupLimit:         ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit,
+		MaxInstancesPerRule:            ng.Cfg.UnifiedAlerting.MaxInstancesPerRule,
+		MaxInstancesPerOrg:             ng.Cfg.UnifiedAlerting.MaxInstancesPerOrg,
+		MaxInstancesByOrg:              ng.Cfg.UnifiedAlerting.MaxInstancesByOrg,
 		Tracer:                         ng.tracer,
 		Log:                            log.New("ngalert.state.manager"),
 	}
@@ -0,0 +1,200 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// TestRoutesResult describes how an alert with a given label set is routed by a notification policy tree.
+type TestRoutesResult struct {
+	Labels   model.LabelSet
+	Time     time.Time
+	Routes   []TestRouteResult
+	Silences []TestRouteSilence
+}
+
+// TestRouteResult describes a single route matched by the label set.
+type TestRouteResult struct {
+	// Key is the unique key of the route in the routing tree.
+	Key                 string
+	Matchers            []string
+	Receiver            string
+	GroupBy             []string
+	GroupByAll          bool
+	GroupWait           time.Duration
+	GroupInterval       time.Duration
+	RepeatInterval      time.Duration
+	Continue            bool
+	MuteTimeIntervals   []string
+	ActiveTimeIntervals []string
+	// MutedBy contains the mute time intervals that are active and the active time intervals that are not
+	// active at the evaluation time. Notifications for this route are suppressed if it is not empty.
+	MutedBy []string
+}
+
+// TestRouteSilence describes a silence that would suppress notifications for the label set.
+type TestRouteSilence struct {
+	ID        string
+	Comment   string
+	CreatedBy string
+	StartsAt  time.Time
+	EndsAt    time.Time
+}
+
+// TestRoutes evaluates the routing tree of the given configuration against the label set at the given time.
+// It returns all matched routes, in the order the dispatcher would visit them, together with the silences
+// that are active at that time and match the label set.
+func TestRoutes(cfg apimodels.Config, lset model.LabelSet, at time.Time, silences []*models.Silence) (*TestRoutesResult, error) {
+	if cfg.Route == nil {
+		return nil, fmt.Errorf("no route provided in config")
+	}
+	if err := lset.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	isActive := func(name string) (bool, error) {
+		tis, ok := intervals[name]
+		if !ok {
+			return false, fmt.Errorf("time interval %q is not defined", name)
+		}
+		for _, ti := range tis {
+			if ti.ContainsTime(at) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	matched := root.Match(lset)
+
+	result := &TestRoutesResult{
+		Labels: lset,
+		Time:   at,
+		Routes: make([]TestRouteResult, 0, len(matched)),
+	}
+	for _, r := range matched {
+		res := TestRouteResult{
+			Key:                 r.Key(),
+			Matchers:            make([]string, 0, len(r.Matchers)),
+			Receiver:            r.RouteOpts.Receiver,
+			GroupBy:             make([]string, 0, len(r.RouteOpts.GroupBy)),
+			GroupByAll:          r.RouteOpts.GroupByAll,
+			GroupWait:           r.RouteOpts.GroupWait,
+			GroupInterval:       r.RouteOpts.GroupInterval,
+			RepeatInterval:      r.RouteOpts.RepeatInterval,
+			Continue:            r.Continue,
+			MuteTimeIntervals:   r.RouteOpts.MuteTimeIntervals,
+			ActiveTimeIntervals: r.RouteOpts.ActiveTimeIntervals,
+		}
+		for _, m := range r.Matchers {
+			res.Matchers = append(res.Matchers, m.String())
+		}
+		for l := range r.RouteOpts.GroupBy {
+			res.GroupBy = append(res.GroupBy, string(l))
+		}
+		sort.Strings(res.GroupBy)
+
+		for _, name := range r.RouteOpts.MuteTimeIntervals {
+			active, err := isActive(name)
+			if err != nil {
+				return nil, err
+			}
+			if active {
+				res.MutedBy = append(res.MutedBy, name)
+			}
+		}
+		for _, name := range r.RouteOpts.ActiveTimeIntervals {
+			active, err := isActive(name)
+			if err != nil {
+				return nil, err
+			}
+			if !active {
+				res.MutedBy = append(res.MutedBy, name)
+			}
+		}
+		result.Routes = append(result.Routes, res)
+	}
+
+	for _, s := range silences {
+		ok, err := silenceSuppresses(s, lset, at)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		result.Silences = append(result.Silences, TestRouteSilence{
+			ID:        stringOrEmpty(s.ID),
+			Comment:   stringOrEmpty(s.Comment),
+			CreatedBy: stringOrEmpty(s.CreatedBy),
+			StartsAt:  time.Time(*s.StartsAt),
+			EndsAt:    time.Time(*s.EndsAt),
+		})
+	}
+
+	return result, nil
+}
+
+// silenceSuppresses returns true if the silence is active at the given time and all its matchers match the label set.
+func silenceSuppresses(s *models.Silence, lset model.LabelSet, at time.Time) (bool, error) {
+	if s == nil || s.StartsAt == nil || s.EndsAt == nil {
+		return false, nil
+	}
+	if at.Before(time.Time(*s.StartsAt)) || !at.Before(time.Time(*s.EndsAt)) {
+		return false, nil
+	}
+	for _, m := range s.Matchers {
+		matcher, err := silenceMatcher(m)
+		if err != nil {
+			return false, err
+		}
+		if !matcher.Matches(string(lset[model.LabelName(matcher.Name)])) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func silenceMatcher(m *amv2.Matcher) (*labels.Matcher, error) {
+	if m == nil || m.Name == nil || m.Value == nil {
+		return nil, fmt.Errorf("invalid silence matcher")
+	}
+	isEqual := m.IsEqual == nil || *m.IsEqual
+	isRegex := m.IsRegex != nil && *m.IsRegex
+
+	matchType := labels.MatchEqual
+	switch {
+	case isEqual && isRegex:
+		matchType = labels.MatchRegexp
+	case !isEqual && isRegex:
+		matchType = labels.MatchNotRegexp
+	case !isEqual && !isRegex:
+		matchType = labels.MatchNotEqual
+	}
+	return labels.NewMatcher(matchType, *m.Name, *m.Value)
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
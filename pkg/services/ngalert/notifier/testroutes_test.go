@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const testRoutesConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [{
+				"receiver": "team-a",
+				"object_matchers": [["team", "=", "a"]],
+				"group_wait": "10s",
+				"mute_time_intervals": ["weekends"]
+			}, {
+				"receiver": "team-b",
+				"object_matchers": [["severity", "=~", "critical|high"]],
+				"continue": true,
+				"active_time_intervals": ["business-hours"]
+			}, {
+				"receiver": "oncall",
+				"object_matchers": [["severity", "=", "critical"]]
+			}]
+		},
+		"mute_time_intervals": [{
+			"name": "weekends",
+			"time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+		}],
+		"time_intervals": [{
+			"name": "business-hours",
+			"time_intervals": [{"times": [{"start_time": "09:00", "end_time": "17:00"}]}]
+		}],
+		"receivers": [{"name": "default"}, {"name": "team-a"}, {"name": "team-b"}, {"name": "oncall"}]
+	}
+}`
+
+func TestTestRoutes(t *testing.T) {
+	var cfg apimodels.PostableUserConfig
+	require.NoError(t, json.Unmarshal([]byte(testRoutesConfig), &cfg))
+
+	saturday := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mondayMorning := time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)
+	mondayNoon := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+
+	receivers := func(res *TestRoutesResult) []string {
+		var result []string
+		for _, r := range res.Routes {
+			result = append(result, r.Receiver)
+		}
+		return result
+	}
+
+	t.Run("should fall back to the root route", func(t *testing.T) {
+		res, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"alertname": "test"}, mondayNoon, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"default"}, receivers(res))
+		assert.Equal(t, []string{"alertname"}, res.Routes[0].GroupBy)
+		assert.Empty(t, res.Routes[0].MutedBy)
+	})
+
+	t.Run("should return timers of the matched route", func(t *testing.T) {
+		res, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"alertname": "test", "team": "a"}, mondayNoon, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a"}, receivers(res))
+		assert.Equal(t, 10*time.Second, res.Routes[0].GroupWait)
+		assert.Equal(t, []string{"alertname"}, res.Routes[0].GroupBy)
+		assert.Equal(t, []string{"weekends"}, res.Routes[0].MuteTimeIntervals)
+		assert.Empty(t, res.Routes[0].MutedBy)
+	})
+
+	t.Run("should report active mute time intervals", func(t *testing.T) {
+		res, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"team": "a"}, saturday, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a"}, receivers(res))
+		assert.Equal(t, []string{"weekends"}, res.Routes[0].MutedBy)
+	})
+
+	t.Run("should follow continue and report inactive active time intervals", func(t *testing.T) {
+		res, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"severity": "critical"}, mondayMorning, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-b", "oncall"}, receivers(res))
+		assert.Equal(t, []string{"business-hours"}, res.Routes[0].MutedBy)
+		assert.Empty(t, res.Routes[1].MutedBy)
+
+		res, err = TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"severity": "critical"}, mondayNoon, nil)
+		require.NoError(t, err)
+		assert.Empty(t, res.Routes[0].MutedBy)
+	})
+
+	t.Run("should return silences active at the given time", func(t *testing.T) {
+		silence := func(id string, start, end time.Time, name, value string, isEqual bool) *models.Silence {
+			return &models.Silence{
+				ID: util.Pointer(id),
+				Silence: amv2.Silence{
+					Comment:   util.Pointer("comment"),
+					CreatedBy: util.Pointer("user"),
+					StartsAt:  util.Pointer(strfmt.DateTime(start)),
+					EndsAt:    util.Pointer(strfmt.DateTime(end)),
+					Matchers: amv2.Matchers{{
+						Name:    util.Pointer(name),
+						Value:   util.Pointer(value),
+						IsEqual: util.Pointer(isEqual),
+						IsRegex: util.Pointer(false),
+					}},
+				},
+			}
+		}
+		silences := []*models.Silence{
+			silence("active", mondayMorning, mondayNoon.Add(time.Hour), "team", "a", true),
+			silence("expired", saturday, mondayMorning, "team", "a", true),
+			silence("pending", mondayNoon.Add(time.Minute), mondayNoon.Add(time.Hour), "team", "a", true),
+			silence("other-team", mondayMorning, mondayNoon.Add(time.Hour), "team", "b", true),
+			silence("negative", mondayMorning, mondayNoon.Add(time.Hour), "team", "b", false),
+		}
+
+		res, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"team": "a"}, mondayNoon, silences)
+		require.NoError(t, err)
+		require.Len(t, res.Silences, 2)
+		assert.Equal(t, "active", res.Silences[0].ID)
+		assert.Equal(t, "negative", res.Silences[1].ID)
+	})
+
+	t.Run("should fail on undefined time intervals", func(t *testing.T) {
+		invalid := cfg.AlertmanagerConfig.Config
+		invalid.MuteTimeIntervals = nil
+		_, err := TestRoutes(invalid, model.LabelSet{"team": "a"}, mondayNoon, nil)
+		require.ErrorContains(t, err, `time interval "weekends" is not defined`)
+	})
+
+	t.Run("should fail on invalid labels", func(t *testing.T) {
+		_, err := TestRoutes(cfg.AlertmanagerConfig.Config, model.LabelSet{"invalid label": "a"}, mondayNoon, nil)
+		require.Error(t, err)
+	})
+}
@@ -311,5 +311,6 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	writeInt(rule.MaxInstances)
 	return fingerprint(sum.Sum64())
 }
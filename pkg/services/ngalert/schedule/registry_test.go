@@ -203,6 +203,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			MaxInstances: 1000,
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -241,6 +242,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			MaxInstances: 500,
 		}
 
 		excludedFields := map[string]struct{}{
@@ -334,6 +334,20 @@ func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string, skipNormal
 	return result
 }
 
+// countOrgStates returns the number of states of all rules in the organization except the rule with the given UID.
+func (c *cache) countOrgStates(orgID int64, excludeRuleUID string) int64 {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	var count int64
+	for ruleUID, rs := range c.states[orgID] {
+		if ruleUID == excludeRuleUID {
+			continue
+		}
+		count += int64(len(rs.states))
+	}
+	return count
+}
+
 // removeByRuleUID deletes all entries in the state cache that match the given UID. Returns removed states
 func (c *cache) removeByRuleUID(orgID int64, uid string) []*State {
 	c.mtxStates.Lock()
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrInstanceLimitExceeded is the error of the result that replaces the results of an evaluation that produced more alert instances than allowed.
+var ErrInstanceLimitExceeded = errors.New("alert instance limit exceeded")
+
+const (
+	instanceLimitRule = "rule"
+	instanceLimitOrg  = "org"
+)
+
+// ruleInstanceLimit returns the effective limit of alert instances of the rule.
+// The limit of the rule is used only if it is lower than the default one. 0 means no limit.
+func (st *Manager) ruleInstanceLimit(alertRule *ngModels.AlertRule) int64 {
+	if alertRule.MaxInstances > 0 && (st.maxInstancesPerRule == 0 || alertRule.MaxInstances < st.maxInstancesPerRule) {
+		return alertRule.MaxInstances
+	}
+	return st.maxInstancesPerRule
+}
+
+// orgInstanceLimit returns the limit of alert instances of all rules in the organization. 0 means no limit.
+func (st *Manager) orgInstanceLimit(orgID int64) int64 {
+	if limit, ok := st.maxInstancesByOrg[orgID]; ok {
+		return limit
+	}
+	return st.maxInstancesPerOrg
+}
+
+// checkInstanceLimits returns ErrInstanceLimitExceeded if the results of the rule would create more alert instances
+// than allowed for a rule or for the organization the rule belongs to.
+func (st *Manager) checkInstanceLimits(alertRule *ngModels.AlertRule, results eval.Results) error {
+	count := int64(len(results))
+	if limit := st.ruleInstanceLimit(alertRule); limit > 0 && count > limit {
+		st.instanceLimitExceeded(alertRule.OrgID, instanceLimitRule)
+		return fmt.Errorf("%w: the rule produced %d alert instances but the limit is %d", ErrInstanceLimitExceeded, count, limit)
+	}
+	if limit := st.orgInstanceLimit(alertRule.OrgID); limit > 0 {
+		if total := st.cache.countOrgStates(alertRule.OrgID, alertRule.UID) + count; total > limit {
+			st.instanceLimitExceeded(alertRule.OrgID, instanceLimitOrg)
+			return fmt.Errorf("%w: the rule produced %d alert instances, which makes %d alert instances in the organization but the limit is %d", ErrInstanceLimitExceeded, count, total, limit)
+		}
+	}
+	return nil
+}
+
+func (st *Manager) instanceLimitExceeded(orgID int64, limit string) {
+	if st.metrics == nil {
+		return
+	}
+	st.metrics.InstanceLimitExceeded.WithLabelValues(strconv.FormatInt(orgID, 10), limit).Inc()
+}
+
+// instanceLimitExceededResults replaces the results of the evaluation with a single error result.
+// The returned rule is a copy of the given one that puts the error result into the Error state regardless of the execution error state of the rule,
+// so the alert instances are never truncated silently.
+func instanceLimitExceededResults(alertRule *ngModels.AlertRule, results eval.Results, evaluatedAt time.Time, err error) (*ngModels.AlertRule, eval.Results) {
+	var duration time.Duration
+	if len(results) > 0 {
+		duration = results[0].EvaluationDuration
+	}
+	limited := *alertRule
+	limited.ExecErrState = ngModels.ErrorErrState
+	return &limited, eval.Results{eval.NewResultFromError(err, evaluatedAt, duration)}
+}
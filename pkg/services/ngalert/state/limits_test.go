@@ -0,0 +1,135 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleInstanceLimit(t *testing.T) {
+	testCases := []struct {
+		name         string
+		defaultLimit int64
+		ruleLimit    int64
+		expected     int64
+	}{
+		{name: "no limits", expected: 0},
+		{name: "default limit only", defaultLimit: 10, expected: 10},
+		{name: "rule limit only", ruleLimit: 5, expected: 5},
+		{name: "rule limit lower than default", defaultLimit: 10, ruleLimit: 5, expected: 5},
+		{name: "rule limit higher than default", defaultLimit: 10, ruleLimit: 50, expected: 10},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &Manager{maxInstancesPerRule: tc.defaultLimit}
+			rule := ngmodels.RuleGen.With(ngmodels.RuleMuts.WithMaxInstances(tc.ruleLimit)).GenerateRef()
+			require.Equal(t, tc.expected, st.ruleInstanceLimit(rule))
+		})
+	}
+}
+
+func TestOrgInstanceLimit(t *testing.T) {
+	st := &Manager{maxInstancesPerOrg: 10, maxInstancesByOrg: map[int64]int64{2: 100, 3: 0}}
+	require.Equal(t, int64(10), st.orgInstanceLimit(1))
+	require.Equal(t, int64(100), st.orgInstanceLimit(2))
+	require.Equal(t, int64(0), st.orgInstanceLimit(3), "organizations can be exempted from the limit")
+}
+
+func TestProcessEvalResults_InstanceLimits(t *testing.T) {
+	evaluatedAt := time.Now()
+	newManager := func(maxPerRule, maxPerOrg int64) (*Manager, *metrics.State) {
+		m := metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics()
+		cfg := ManagerCfg{
+			Metrics:             m,
+			Tracer:              tracing.InitializeTracerForTest(),
+			Log:                 log.New("ngalert.state.manager"),
+			InstanceStore:       &FakeInstanceStore{},
+			Images:              &NotAvailableImageService{},
+			Clock:               clock.NewMock(),
+			Historian:           &FakeHistorian{},
+			MaxInstancesPerRule: maxPerRule,
+			MaxInstancesPerOrg:  maxPerOrg,
+		}
+		return NewManager(cfg, NewNoopPersister()), m
+	}
+	genResults := func(count int) eval.Results {
+		results := make(eval.Results, 0, count)
+		for i := 0; i < count; i++ {
+			results = append(results, eval.Result{
+				Instance:    data.Labels{"series": fmt.Sprint(i)},
+				State:       eval.Alerting,
+				EvaluatedAt: evaluatedAt,
+			})
+		}
+		return results
+	}
+	gen := ngmodels.RuleGen.With(
+		ngmodels.RuleMuts.WithOrgID(1),
+		ngmodels.RuleMuts.WithIntervalSeconds(10),
+		ngmodels.RuleMuts.WithErrorExecAs(ngmodels.AlertingErrState),
+	)
+
+	t.Run("rule within the limit creates all instances", func(t *testing.T) {
+		st, m := newManager(0, 0)
+		rule := gen.With(ngmodels.RuleMuts.WithMaxInstances(3)).GenerateRef()
+
+		transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule, genResults(3), nil)
+
+		require.Len(t, transitions, 3)
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 3)
+		require.Equal(t, 0, testutil.CollectAndCount(m.InstanceLimitExceeded))
+	})
+
+	t.Run("rule exceeding its limit goes into Error state", func(t *testing.T) {
+		st, m := newManager(0, 0)
+		rule := gen.With(ngmodels.RuleMuts.WithMaxInstances(2)).GenerateRef()
+
+		transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule, genResults(3), nil)
+
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Error, transitions[0].State.State)
+		require.ErrorIs(t, transitions[0].State.Error, ErrInstanceLimitExceeded)
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+		require.Equal(t, ngmodels.AlertingErrState, rule.ExecErrState, "rule should not be modified")
+		require.Equal(t, 1.0, testutil.ToFloat64(m.InstanceLimitExceeded.WithLabelValues("1", instanceLimitRule)))
+	})
+
+	t.Run("default limit applies to rules without limit", func(t *testing.T) {
+		st, _ := newManager(2, 0)
+		rule := gen.GenerateRef()
+
+		transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule, genResults(3), nil)
+
+		require.Len(t, transitions, 1)
+		require.ErrorIs(t, transitions[0].State.Error, ErrInstanceLimitExceeded)
+	})
+
+	t.Run("rule exceeding the limit of the organization goes into Error state", func(t *testing.T) {
+		st, m := newManager(0, 3)
+		rule1 := gen.GenerateRef()
+		rule2 := gen.GenerateRef()
+
+		require.Len(t, st.ProcessEvalResults(context.Background(), evaluatedAt, rule1, genResults(2), nil), 2)
+
+		transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule2, genResults(2), nil)
+		require.Len(t, transitions, 1)
+		require.ErrorIs(t, transitions[0].State.Error, ErrInstanceLimitExceeded)
+		require.Equal(t, 1.0, testutil.ToFloat64(m.InstanceLimitExceeded.WithLabelValues("1", instanceLimitOrg)))
+
+		// the states of the rule itself are not counted twice
+		require.Len(t, st.ProcessEvalResults(context.Background(), evaluatedAt.Add(10*time.Second), rule1, genResults(2), nil), 2)
+	})
+}
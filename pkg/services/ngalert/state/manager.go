@@ -51,6 +51,9 @@ type Manager struct {
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
 	rulesPerRuleGroupLimit         int64
+	maxInstancesPerRule            int64
+	maxInstancesPerOrg             int64
+	maxInstancesByOrg              map[int64]int64
 
 	persister StatePersister
 }
@@ -70,6 +73,12 @@ type ManagerCfg struct {
 	// to all states when corresponding execution in the rule definition is set to either `Alerting` or `OK`
 	ApplyNoDataAndErrorToAllStates bool
 	RulesPerRuleGroupLimit         int64
+	// MaxInstancesPerRule is the default limit of alert instances per rule. 0 means no limit.
+	MaxInstancesPerRule int64
+	// MaxInstancesPerOrg is the limit of alert instances of all rules in an organization. 0 means no limit.
+	MaxInstancesPerOrg int64
+	// MaxInstancesByOrg overrides MaxInstancesPerOrg for organizations.
+	MaxInstancesByOrg map[int64]int64
 
 	DisableExecution bool
 
@@ -98,6 +107,9 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
+		maxInstancesPerRule:            cfg.MaxInstancesPerRule,
+		maxInstancesPerOrg:             cfg.MaxInstancesPerOrg,
+		maxInstancesByOrg:              cfg.MaxInstancesByOrg,
 		persister:                      statePersister,
 		tracer:                         cfg.Tracer,
 	}
@@ -303,6 +315,13 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 
 	logger := st.log.FromContext(tracingCtx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
+	if err := st.checkInstanceLimits(alertRule, results); err != nil {
+		logger.Warn("Alert rule exceeded the limit of alert instances", "error", err)
+		span.AddEvent("instance limit exceeded", trace.WithAttributes(
+			attribute.Int("results", len(results)),
+		))
+		alertRule, results = instanceLimitExceededResults(alertRule, results, evaluatedAt, err)
+	}
 	states := st.setNextStateForRule(tracingCtx, alertRule, results, extraLabels, logger)
 	span.AddEvent("results processed", trace.WithAttributes(
 		attribute.Int64("state_transitions", int64(len(states))),
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	grafanastore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -71,6 +72,14 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("Deleted alert instances", "count", rows)
+
+		if st.emitEntityEvent() {
+			events := make([]grafanastore.EntityEvent, 0, len(ruleUID))
+			for _, uid := range ruleUID {
+				events = append(events, newAlertRuleEntityEvent(orgID, uid, grafanastore.EntityEventTypeDelete))
+			}
+			return insertEntityEvents(sess, events)
+		}
 		return nil
 	})
 }
@@ -162,6 +171,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				NotificationSettings: r.NotificationSettings,
+				MaxInstances:         r.MaxInstances,
 			})
 		}
 		if len(newRules) > 0 {
@@ -186,6 +196,14 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
+
+		if st.emitEntityEvent() {
+			events := make([]grafanastore.EntityEvent, 0, len(newRules))
+			for _, r := range newRules {
+				events = append(events, newAlertRuleEntityEvent(r.OrgID, r.UID, grafanastore.EntityEventTypeCreate))
+			}
+			return insertEntityEvents(sess, events)
+		}
 		return nil
 	})
 }
@@ -242,6 +260,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				MaxInstances:         r.New.MaxInstances,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -249,6 +268,14 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
+
+		if st.emitEntityEvent() {
+			events := make([]grafanastore.EntityEvent, 0, len(rules))
+			for _, r := range rules {
+				events = append(events, newAlertRuleEntityEvent(r.New.OrgID, r.New.UID, grafanastore.EntityEventTypeUpdate))
+			}
+			return insertEntityEvents(sess, events)
+		}
 		return nil
 	})
 }
@@ -787,3 +814,27 @@ func (st DBstore) GetNamespacesByRuleUID(ctx context.Context, orgID int64, uids
 	})
 	return result, err
 }
+
+// emitEntityEvent returns whether changes to alert rules are recorded as entity events, the search index uses them to
+// index alert rules incrementally.
+func (st DBstore) emitEntityEvent() bool {
+	return st.FeatureToggles != nil && st.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagPanelTitleSearch)
+}
+
+func newAlertRuleEntityEvent(orgID int64, uid string, eventType grafanastore.EntityEventType) grafanastore.EntityEvent {
+	return grafanastore.EntityEvent{
+		EventType: eventType,
+		EntityId:  grafanastore.CreateDatabaseEntityId(uid, orgID, grafanastore.EntityTypeAlertRule),
+		Created:   TimeNow().Unix(),
+	}
+}
+
+func insertEntityEvents(sess *db.Session, events []grafanastore.EntityEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if _, err := sess.Insert(&events); err != nil {
+		return fmt.Errorf("failed to create entity events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+// requestOutcome is how a request counts for the circuit breaker of its data source.
+type requestOutcome int
+
+const (
+	outcomeSuccess requestOutcome = iota
+	outcomeFailure
+	// outcomeIgnored is used for the requests that were cancelled by the caller.
+	outcomeIgnored
+)
+
+// dataSourceLimitsMetrics contains the prometheus metrics used by the DataSourceLimitsMiddleware.
+type dataSourceLimitsMetrics struct {
+	queuedRequests      *prometheus.GaugeVec
+	runningRequests     *prometheus.GaugeVec
+	circuitBreakerState *prometheus.GaugeVec
+	rejectedRequests    *prometheus.CounterVec
+}
+
+func newDataSourceLimitsMetrics(promRegisterer prometheus.Registerer) dataSourceLimitsMetrics {
+	m := dataSourceLimitsMetrics{
+		queuedRequests: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "queued_requests",
+			Help:      "Number of requests waiting for a data source that runs its maximum number of concurrent requests",
+		}, []string{"datasource", "datasource_type"}),
+		runningRequests: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "running_requests",
+			Help:      "Number of requests running for a data source with a concurrency limit",
+		}, []string{"datasource", "datasource_type"}),
+		circuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker of a data source: 0 is closed, 1 is half-open and 2 is open",
+		}, []string{"datasource", "datasource_type"}),
+		rejectedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "datasource",
+			Name:      "rejected_requests_total",
+			Help:      "Number of requests rejected by the concurrency limit or the circuit breaker of a data source",
+		}, []string{"datasource", "datasource_type", "reason"}),
+	}
+	promRegisterer.MustRegister(m.queuedRequests, m.runningRequests, m.circuitBreakerState, m.rejectedRequests)
+	return m
+}
+
+// dataSourceLimiter limits the concurrent requests of a data source and holds the state of its circuit breaker.
+type dataSourceLimiter struct {
+	// slots has a capacity of the maximum concurrent requests, or is nil if they are unlimited.
+	slots chan struct{}
+
+	mu       sync.Mutex
+	queued   int
+	state    circuitState
+	failures int
+	openedAt time.Time
+	// probing is set while the request checking if the data source recovered is running.
+	probing bool
+}
+
+// NewDataSourceLimitsMiddleware creates a new plugins.ClientMiddleware that limits the number of concurrent requests
+// to each data source, queueing the requests above the limit, and that stops sending requests to a data source with a
+// circuit breaker after too many consecutive failures or timeouts.
+func NewDataSourceLimitsMiddleware(cfg setting.DataSourceLimitsSettings, promRegisterer prometheus.Registerer) plugins.ClientMiddleware {
+	limits := newDataSourceLimits(cfg, promRegisterer)
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &DataSourceLimitsMiddleware{
+			dataSourceLimits: limits,
+			next:             next,
+		}
+	})
+}
+
+// dataSourceLimits holds the limiters of the data sources, which are shared by all the middleware instances.
+type dataSourceLimits struct {
+	cfg     setting.DataSourceLimitsSettings
+	metrics dataSourceLimitsMetrics
+	now     func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*dataSourceLimiter
+}
+
+func newDataSourceLimits(cfg setting.DataSourceLimitsSettings, promRegisterer prometheus.Registerer) *dataSourceLimits {
+	return &dataSourceLimits{
+		cfg:      cfg,
+		metrics:  newDataSourceLimitsMetrics(promRegisterer),
+		limiters: map[string]*dataSourceLimiter{},
+		now:      time.Now,
+	}
+}
+
+type DataSourceLimitsMiddleware struct {
+	*dataSourceLimits
+	next plugins.Client
+}
+
+// maxConcurrentQueries returns the concurrency limit of the data source, which can be set in its JSON data.
+func (m *dataSourceLimits) maxConcurrentQueries(settings *backend.DataSourceInstanceSettings) int {
+	var jsonData struct {
+		MaxConcurrentQueries *int `json:"maxConcurrentQueries"`
+	}
+	if len(settings.JSONData) > 0 && json.Unmarshal(settings.JSONData, &jsonData) == nil && jsonData.MaxConcurrentQueries != nil && *jsonData.MaxConcurrentQueries >= 0 {
+		return *jsonData.MaxConcurrentQueries
+	}
+	return m.cfg.MaxConcurrentQueries
+}
+
+// limiter returns the limiter of the data source, replacing it if its concurrency limit was changed.
+func (m *dataSourceLimits) limiter(orgID int64, settings *backend.DataSourceInstanceSettings) *dataSourceLimiter {
+	maxConcurrent := m.maxConcurrentQueries(settings)
+	key := fmt.Sprintf("%d/%s", orgID, settings.UID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.limiters[key]
+	if ok && cap(l.slots) == maxConcurrent {
+		return l
+	}
+
+	newLimiter := &dataSourceLimiter{}
+	if maxConcurrent > 0 {
+		newLimiter.slots = make(chan struct{}, maxConcurrent)
+	}
+	if ok {
+		// keep the state of the circuit breaker
+		l.mu.Lock()
+		newLimiter.state, newLimiter.failures, newLimiter.openedAt = l.state, l.failures, l.openedAt
+		l.mu.Unlock()
+	}
+	m.limiters[key] = newLimiter
+	return newLimiter
+}
+
+// allow checks the circuit breaker of the data source. Once the breaker has been open long enough, a single request
+// is let through to check if the data source recovered, probe is set for that request.
+func (m *dataSourceLimits) allow(l *dataSourceLimiter, settings *backend.DataSourceInstanceSettings) (probe bool, err error) {
+	if m.cfg.CircuitBreakerFailures == 0 {
+		return false, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch l.state {
+	case circuitOpen:
+		if m.now().Sub(l.openedAt) < m.cfg.CircuitBreakerOpenDuration {
+			return false, plugins.ErrDataSourceCircuitOpen.Errorf("circuit breaker of data source %s is open", settings.UID)
+		}
+		m.setState(l, settings, circuitHalfOpen)
+		l.probing = true
+		return true, nil
+	case circuitHalfOpen:
+		if l.probing {
+			return false, plugins.ErrDataSourceCircuitOpen.Errorf("circuit breaker of data source %s is half-open", settings.UID)
+		}
+		l.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record updates the circuit breaker of the data source with the outcome of a request. Only the probe changes an
+// open or half-open breaker: the other requests were let through before the breaker opened, and their outcomes are
+// outdated.
+func (m *dataSourceLimits) record(l *dataSourceLimiter, settings *backend.DataSourceInstanceSettings, probe bool, outcome requestOutcome) {
+	if m.cfg.CircuitBreakerFailures == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if probe {
+		// a cancelled probe lets another request through
+		l.probing = false
+		if l.state != circuitHalfOpen {
+			return
+		}
+		switch outcome {
+		case outcomeSuccess:
+			l.failures = 0
+			m.setState(l, settings, circuitClosed)
+		case outcomeFailure:
+			l.openedAt = m.now()
+			m.setState(l, settings, circuitOpen)
+		}
+		return
+	}
+
+	if l.state != circuitClosed {
+		return
+	}
+	switch outcome {
+	case outcomeSuccess:
+		l.failures = 0
+	case outcomeFailure:
+		l.failures++
+		if l.failures >= m.cfg.CircuitBreakerFailures {
+			l.openedAt = m.now()
+			m.setState(l, settings, circuitOpen)
+		}
+	}
+}
+
+func (m *dataSourceLimits) setState(l *dataSourceLimiter, settings *backend.DataSourceInstanceSettings, state circuitState) {
+	l.state = state
+	m.metrics.circuitBreakerState.WithLabelValues(settings.UID, settings.Type).Set(float64(state))
+}
+
+// acquire waits for a free slot of the data source, and returns the function that frees it.
+func (m *dataSourceLimits) acquire(ctx context.Context, l *dataSourceLimiter, settings *backend.DataSourceInstanceSettings) (func(), error) {
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	running := m.metrics.runningRequests.WithLabelValues(settings.UID, settings.Type)
+	release := func() {
+		<-l.slots
+		running.Dec()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		running.Inc()
+		return release, nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.queued >= m.cfg.MaxQueuedQueries {
+		l.mu.Unlock()
+		m.metrics.rejectedRequests.WithLabelValues(settings.UID, settings.Type, "queue_full").Inc()
+		return nil, plugins.ErrDataSourceQueueFull.Errorf("%d requests are waiting for data source %s", m.cfg.MaxQueuedQueries, settings.UID)
+	}
+	l.queued++
+	l.mu.Unlock()
+
+	queued := m.metrics.queuedRequests.WithLabelValues(settings.UID, settings.Type)
+	queued.Inc()
+	defer func() {
+		queued.Dec()
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(m.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		running.Inc()
+		return release, nil
+	case <-timer.C:
+		m.metrics.rejectedRequests.WithLabelValues(settings.UID, settings.Type, "queue_timeout").Inc()
+		return nil, plugins.ErrDataSourceQueueTimeout.Errorf("waited %s for data source %s", m.cfg.QueueTimeout, settings.UID)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run runs the request once the circuit breaker and the concurrency limit of the data source let it through.
+func (m *DataSourceLimitsMiddleware) run(ctx context.Context, pCtx backend.PluginContext, fn func() requestOutcome) error {
+	settings := pCtx.DataSourceInstanceSettings
+	if settings == nil {
+		fn()
+		return nil
+	}
+
+	l := m.limiter(pCtx.OrgID, settings)
+	probe, err := m.allow(l, settings)
+	if err != nil {
+		m.metrics.rejectedRequests.WithLabelValues(settings.UID, settings.Type, "circuit_open").Inc()
+		return err
+	}
+
+	release, err := m.acquire(ctx, l, settings)
+	if err != nil {
+		m.record(l, settings, probe, outcomeIgnored)
+		return err
+	}
+	defer release()
+
+	m.record(l, settings, probe, fn())
+	return nil
+}
+
+func outcomeFromError(err error) requestOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	if errors.Is(err, context.Canceled) {
+		return outcomeIgnored
+	}
+	return outcomeFailure
+}
+
+// outcomeFromQueryDataResponse only counts server errors and timeouts as failures, as invalid queries do not mean
+// that the data source is struggling.
+func outcomeFromQueryDataResponse(resp *backend.QueryDataResponse, err error) requestOutcome {
+	if err != nil || resp == nil {
+		return outcomeFromError(err)
+	}
+	for _, dr := range resp.Responses {
+		if dr.Status >= 500 || errors.Is(dr.Error, context.DeadlineExceeded) {
+			return outcomeFailure
+		}
+	}
+	return outcomeSuccess
+}
+
+func (m *DataSourceLimitsMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	var resp *backend.QueryDataResponse
+	var innerErr error
+	err := m.run(ctx, req.PluginContext, func() requestOutcome {
+		resp, innerErr = m.next.QueryData(ctx, req)
+		return outcomeFromQueryDataResponse(resp, innerErr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, innerErr
+}
+
+func (m *DataSourceLimitsMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	var innerErr error
+	err := m.run(ctx, req.PluginContext, func() requestOutcome {
+		var status int
+		statusSender := callResourceResponseSenderFunc(func(res *backend.CallResourceResponse) error {
+			if res != nil && res.Status != 0 {
+				status = res.Status
+			}
+			return sender.Send(res)
+		})
+		innerErr = m.next.CallResource(ctx, req, statusSender)
+		if innerErr == nil && status >= 500 {
+			return outcomeFailure
+		}
+		return outcomeFromError(innerErr)
+	})
+	if err != nil {
+		return err
+	}
+
+	return innerErr
+}
+
+func (m *DataSourceLimitsMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return m.next.CheckHealth(ctx, req)
+}
+
+func (m *DataSourceLimitsMiddleware) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	return m.next.CollectMetrics(ctx, req)
+}
+
+func (m *DataSourceLimitsMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	return m.next.SubscribeStream(ctx, req)
+}
+
+func (m *DataSourceLimitsMiddleware) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return m.next.PublishStream(ctx, req)
+}
+
+func (m *DataSourceLimitsMiddleware) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return m.next.RunStream(ctx, req, sender)
+}
@@ -0,0 +1,285 @@
+package clientmiddleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDataSourceLimitsMiddleware(t *testing.T) {
+	pCtx := backend.PluginContext{
+		OrgID:    1,
+		PluginID: "prometheus",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			UID:  "ds-uid",
+			Type: "prometheus",
+		},
+	}
+
+	newMiddleware := func(cfg setting.DataSourceLimitsSettings, next plugins.Client) *DataSourceLimitsMiddleware {
+		return &DataSourceLimitsMiddleware{
+			dataSourceLimits: newDataSourceLimits(cfg, prometheus.NewRegistry()),
+			next:             next,
+		}
+	}
+
+	t.Run("Should queue the requests above the concurrency limit", func(t *testing.T) {
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedQueries:     1,
+			QueueTimeout:         time.Minute,
+		}, &clienttest.TestClient{
+			QueryDataFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				started <- struct{}{}
+				<-unblock
+				return &backend.QueryDataResponse{}, nil
+			},
+		})
+
+		errs := make(chan error, 2)
+		query := func() {
+			_, err := mw.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+			errs <- err
+		}
+
+		go query()
+		<-started
+		go query()
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(mw.metrics.queuedRequests.WithLabelValues("ds-uid", "prometheus")) == 1
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.metrics.runningRequests.WithLabelValues("ds-uid", "prometheus")))
+
+		_, err := mw.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+		require.ErrorIs(t, err, plugins.ErrDataSourceQueueFull)
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.metrics.rejectedRequests.WithLabelValues("ds-uid", "prometheus", "queue_full")))
+
+		unblock <- struct{}{}
+		require.NoError(t, <-errs)
+		<-started
+		unblock <- struct{}{}
+		require.NoError(t, <-errs)
+		require.Equal(t, 0.0, testutil.ToFloat64(mw.metrics.queuedRequests.WithLabelValues("ds-uid", "prometheus")))
+		require.Equal(t, 0.0, testutil.ToFloat64(mw.metrics.runningRequests.WithLabelValues("ds-uid", "prometheus")))
+	})
+
+	t.Run("Should fail the requests that wait longer than the queue timeout", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedQueries:     1,
+			QueueTimeout:         10 * time.Millisecond,
+		}, &clienttest.TestClient{
+			CallResourceFunc: func(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+				<-unblock
+				return nil
+			},
+		})
+
+		go func() {
+			_ = mw.CallResource(context.Background(), &backend.CallResourceRequest{PluginContext: pCtx}, nopCallResourceSender)
+		}()
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(mw.metrics.runningRequests.WithLabelValues("ds-uid", "prometheus")) == 1
+		}, time.Second, time.Millisecond)
+
+		err := mw.CallResource(context.Background(), &backend.CallResourceRequest{PluginContext: pCtx}, nopCallResourceSender)
+		require.ErrorIs(t, err, plugins.ErrDataSourceQueueTimeout)
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.metrics.rejectedRequests.WithLabelValues("ds-uid", "prometheus", "queue_timeout")))
+	})
+
+	t.Run("Should use the concurrency limit of the data source", func(t *testing.T) {
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxConcurrentQueries: 10,
+			MaxQueuedQueries:     1,
+			QueueTimeout:         time.Minute,
+		}, &clienttest.TestClient{})
+
+		settings := *pCtx.DataSourceInstanceSettings
+		settings.JSONData = []byte(`{"maxConcurrentQueries": 2}`)
+		require.Equal(t, 2, cap(mw.limiter(1, &settings).slots))
+		require.Equal(t, 10, cap(mw.limiter(1, pCtx.DataSourceInstanceSettings).slots))
+		require.NotSame(t, mw.limiter(1, pCtx.DataSourceInstanceSettings), mw.limiter(2, pCtx.DataSourceInstanceSettings))
+	})
+
+	t.Run("Should open the circuit breaker after consecutive failures", func(t *testing.T) {
+		var responses []*backend.QueryDataResponse
+		var calls int
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxQueuedQueries:           1,
+			QueueTimeout:               time.Minute,
+			CircuitBreakerFailures:     2,
+			CircuitBreakerOpenDuration: time.Minute,
+		}, &clienttest.TestClient{
+			QueryDataFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				calls++
+				resp := responses[0]
+				responses = responses[1:]
+				return resp, nil
+			},
+		})
+		now := time.Now()
+		mw.now = func() time.Time { return now }
+		state := mw.metrics.circuitBreakerState.WithLabelValues("ds-uid", "prometheus")
+
+		failed := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Status: backend.StatusBadGateway, Error: errors.New("bad gateway")}}}
+		invalid := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Status: backend.StatusBadRequest, Error: errors.New("parse error")}}}
+		succeeded := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Status: backend.StatusOK}}}
+
+		query := func() error {
+			_, err := mw.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+			return err
+		}
+
+		// client errors do not count as failures
+		responses = []*backend.QueryDataResponse{failed, invalid, failed}
+		require.NoError(t, query())
+		require.NoError(t, query())
+		require.NoError(t, query())
+		require.Equal(t, float64(circuitClosed), testutil.ToFloat64(state))
+
+		responses = []*backend.QueryDataResponse{failed}
+		require.NoError(t, query())
+		require.Equal(t, float64(circuitOpen), testutil.ToFloat64(state))
+
+		require.ErrorIs(t, query(), plugins.ErrDataSourceCircuitOpen)
+		require.Equal(t, 4, calls)
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.metrics.rejectedRequests.WithLabelValues("ds-uid", "prometheus", "circuit_open")))
+
+		// a single failed request opens the circuit breaker again
+		now = now.Add(time.Minute)
+		responses = []*backend.QueryDataResponse{failed}
+		require.NoError(t, query())
+		require.Equal(t, float64(circuitOpen), testutil.ToFloat64(state))
+		require.ErrorIs(t, query(), plugins.ErrDataSourceCircuitOpen)
+
+		now = now.Add(time.Minute)
+		responses = []*backend.QueryDataResponse{succeeded, failed}
+		require.NoError(t, query())
+		require.Equal(t, float64(circuitClosed), testutil.ToFloat64(state))
+		require.NoError(t, query())
+		require.Equal(t, float64(circuitClosed), testutil.ToFloat64(state))
+		require.Equal(t, 7, calls)
+	})
+
+	t.Run("Should only let a single request through a half-open circuit breaker", func(t *testing.T) {
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxQueuedQueries:           1,
+			QueueTimeout:               time.Minute,
+			CircuitBreakerFailures:     1,
+			CircuitBreakerOpenDuration: time.Minute,
+		}, &clienttest.TestClient{})
+		now := time.Now()
+		mw.now = func() time.Time { return now }
+
+		l := mw.limiter(1, pCtx.DataSourceInstanceSettings)
+		settings := pCtx.DataSourceInstanceSettings
+		mw.record(l, settings, false, outcomeFailure)
+		_, err := mw.allow(l, settings)
+		require.ErrorIs(t, err, plugins.ErrDataSourceCircuitOpen)
+
+		now = now.Add(time.Minute)
+		probe, err := mw.allow(l, settings)
+		require.NoError(t, err)
+		require.True(t, probe)
+		_, err = mw.allow(l, settings)
+		require.ErrorIs(t, err, plugins.ErrDataSourceCircuitOpen)
+
+		// cancelled requests let another request through
+		mw.record(l, settings, probe, outcomeIgnored)
+		probe, err = mw.allow(l, settings)
+		require.NoError(t, err)
+		require.True(t, probe)
+		mw.record(l, settings, probe, outcomeSuccess)
+		probe, err = mw.allow(l, settings)
+		require.NoError(t, err)
+		require.False(t, probe)
+		_, err = mw.allow(l, settings)
+		require.NoError(t, err)
+	})
+
+	t.Run("Should ignore the outcome of requests let through before the circuit breaker opened", func(t *testing.T) {
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		failed := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Status: backend.StatusBadGateway, Error: errors.New("bad gateway")}}}
+		succeeded := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Status: backend.StatusOK}}}
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxQueuedQueries:           1,
+			QueueTimeout:               time.Minute,
+			CircuitBreakerFailures:     1,
+			CircuitBreakerOpenDuration: time.Minute,
+		}, &clienttest.TestClient{
+			QueryDataFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				if req.Queries[0].RefID == "slow" {
+					started <- struct{}{}
+					<-unblock
+					return succeeded, nil
+				}
+				return failed, nil
+			},
+		})
+		now := time.Now()
+		mw.now = func() time.Time { return now }
+		state := mw.metrics.circuitBreakerState.WithLabelValues("ds-uid", "prometheus")
+
+		query := func(refID string) error {
+			_, err := mw.QueryData(context.Background(), &backend.QueryDataRequest{
+				PluginContext: pCtx,
+				Queries:       []backend.DataQuery{{RefID: refID}},
+			})
+			return err
+		}
+
+		slow := make(chan error, 1)
+		go func() { slow <- query("slow") }()
+		<-started
+
+		require.NoError(t, query("A"))
+		require.Equal(t, float64(circuitOpen), testutil.ToFloat64(state))
+
+		// the slow request succeeds after the circuit breaker opened
+		close(unblock)
+		require.NoError(t, <-slow)
+		require.Equal(t, float64(circuitOpen), testutil.ToFloat64(state))
+		require.ErrorIs(t, query("A"), plugins.ErrDataSourceCircuitOpen)
+	})
+
+	t.Run("Should count the resource responses with a server error as failures", func(t *testing.T) {
+		mw := newMiddleware(setting.DataSourceLimitsSettings{
+			MaxQueuedQueries:           1,
+			QueueTimeout:               time.Minute,
+			CircuitBreakerFailures:     1,
+			CircuitBreakerOpenDuration: time.Minute,
+		}, &clienttest.TestClient{
+			CallResourceFunc: func(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+				return sender.Send(&backend.CallResourceResponse{Status: 503})
+			},
+		})
+
+		err := mw.CallResource(context.Background(), &backend.CallResourceRequest{PluginContext: pCtx}, nopCallResourceSender)
+		require.NoError(t, err)
+		err = mw.CallResource(context.Background(), &backend.CallResourceRequest{PluginContext: pCtx}, nopCallResourceSender)
+		require.ErrorIs(t, err, plugins.ErrDataSourceCircuitOpen)
+	})
+
+	t.Run("Should not count cancelled requests as failures", func(t *testing.T) {
+		require.Equal(t, outcomeIgnored, outcomeFromError(context.Canceled))
+		require.Equal(t, outcomeFailure, outcomeFromError(context.DeadlineExceeded))
+		require.Equal(t, outcomeFailure, outcomeFromQueryDataResponse(&backend.QueryDataResponse{
+			Responses: backend.Responses{"A": {Error: context.DeadlineExceeded}},
+		}, nil))
+	})
+}
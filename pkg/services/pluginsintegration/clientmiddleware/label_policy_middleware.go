@@ -0,0 +1,344 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models/roletype"
+	"github.com/grafana/grafana/pkg/plugins"
+	prommodels "github.com/grafana/grafana/pkg/promlib/models"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/tsdb/loki"
+)
+
+// resourceSelectorParams are the resources that restricted users can call on Prometheus and Loki data sources,
+// with the request parameters holding the queries or selectors the label matchers are injected into.
+var resourceSelectorParams = map[string]map[string]string{
+	datasources.DS_PROMETHEUS: {
+		"api/v1/labels":          "match[]",
+		"api/v1/series":          "match[]",
+		"api/v1/label/*/values":  "match[]",
+		"api/v1/query":           "query",
+		"api/v1/query_range":     "query",
+		"api/v1/query_exemplars": "query",
+	},
+	datasources.DS_LOKI: {
+		"labels":             "query",
+		"label/*/values":     "query",
+		"series":             "match[]",
+		"index/stats":        "query",
+		"index/volume":       "query",
+		"index/volume_range": "query",
+	},
+}
+
+// unrestrictedResources are the resources that do not return any series or log streams.
+var unrestrictedResources = map[string][]string{
+	datasources.DS_PROMETHEUS: {"version-detect", "api/v1/status/buildinfo"},
+}
+
+var labelValuesPathRegexp = regexp.MustCompile(`label/[^/]+/values$`)
+
+// NewLabelPolicyMiddleware creates a new plugins.ClientMiddleware that restricts the queries of Prometheus and Loki
+// data sources to the series and log streams allowed by the label policies of the data source. The label matchers
+// of the policies are injected into every query, and requests that cannot be restricted are denied.
+func NewLabelPolicyMiddleware() plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &LabelPolicyMiddleware{
+			next: next,
+		}
+	})
+}
+
+type LabelPolicyMiddleware struct {
+	next plugins.Client
+}
+
+func getLabelPolicies(pCtx backend.PluginContext) ([]datasources.LabelPolicy, error) {
+	settings := pCtx.DataSourceInstanceSettings
+	if settings == nil || len(settings.JSONData) == 0 || !datasources.SupportsLabelPolicies(pCtx.PluginID) {
+		return nil, nil
+	}
+
+	jsonData, err := simplejson.NewJson(settings.JSONData)
+	if err != nil {
+		return nil, datasources.ErrLabelPolicyAccessDenied.Errorf("failed to parse the data source json data: %w", err)
+	}
+	labelPolicies, err := datasources.GetLabelPolicies(jsonData)
+	if err != nil {
+		return nil, datasources.ErrLabelPolicyAccessDenied.Errorf("invalid label policies: %w", err)
+	}
+	return labelPolicies, nil
+}
+
+// getLabelMatchers returns the label matchers the request has to be restricted with, or no matchers if the request
+// is not restricted. Requests without an HTTP request context are denied, except for the alert and recording rule
+// evaluations: the rules cannot be saved by users restricted by the label policies of the data source.
+func (m *LabelPolicyMiddleware) getLabelMatchers(ctx context.Context, pCtx backend.PluginContext, fromAlert bool) ([]*labels.Matcher, error) {
+	labelPolicies, err := getLabelPolicies(pCtx)
+	if err != nil {
+		return nil, err
+	}
+	if len(labelPolicies) == 0 {
+		return nil, nil
+	}
+
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Req == nil || reqCtx.SignedInUser == nil {
+		if fromAlert {
+			return nil, nil
+		}
+		return nil, datasources.ErrLabelPolicyAccessDenied.Errorf("requests without a user cannot be restricted")
+	}
+
+	rules, err := datasources.LabelPolicyRules(labelPolicies, reqCtx.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers := make([]*labels.Matcher, 0, len(rules))
+	for _, rule := range rules {
+		matcher, err := prommodels.ParseLabelMatcher(rule)
+		if err != nil {
+			return nil, datasources.ErrLabelPolicyAccessDenied.Errorf("invalid label policy rule %q: %w", rule, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func injectLabelMatchers(pluginID string, expr string, matchers []*labels.Matcher) (string, error) {
+	if pluginID == datasources.DS_LOKI {
+		return loki.InjectStreamMatchers(expr, matchers)
+	}
+	return prommodels.InjectLabelMatchers(expr, matchers)
+}
+
+// selectorWithLabelMatchers returns a selector for the requests that do not provide their own.
+func selectorWithLabelMatchers(matchers []*labels.Matcher) string {
+	rendered := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		rendered = append(rendered, matcher.String())
+	}
+	return "{" + strings.Join(rendered, ", ") + "}"
+}
+
+func (m *LabelPolicyMiddleware) restrictQuery(pluginID string, query backend.DataQuery, matchers []*labels.Matcher) (backend.DataQuery, error) {
+	var model map[string]json.RawMessage
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return query, err
+	}
+	rawExpr, ok := model["expr"]
+	if !ok {
+		return query, fmt.Errorf("queries without an expression are not supported by label policies")
+	}
+
+	if pluginID == datasources.DS_PROMETHEUS {
+		// scope and ad hoc filters replace the matchers on the same labels, including the injected ones
+		var filters struct {
+			Scope        *prommodels.ScopeSpec    `json:"scope"`
+			AdhocFilters []prommodels.ScopeFilter `json:"adhocFilters"`
+		}
+		if err := json.Unmarshal(query.JSON, &filters); err != nil {
+			return query, err
+		}
+		if (filters.Scope != nil && len(filters.Scope.Filters) > 0) || len(filters.AdhocFilters) > 0 {
+			return query, fmt.Errorf("scope and ad hoc filters are not supported by label policies")
+		}
+	}
+
+	var expr string
+	if err := json.Unmarshal(rawExpr, &expr); err != nil {
+		return query, err
+	}
+	expr, err := injectLabelMatchers(pluginID, expr, matchers)
+	if err != nil {
+		return query, err
+	}
+	if model["expr"], err = json.Marshal(expr); err != nil {
+		return query, err
+	}
+
+	query.JSON, err = json.Marshal(model)
+	return query, err
+}
+
+func (m *LabelPolicyMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	_, fromAlert := req.Headers[ngalertmodels.FromAlertHeaderName]
+	matchers, err := m.getLabelMatchers(ctx, req.PluginContext, fromAlert)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) == 0 {
+		return m.next.QueryData(ctx, req)
+	}
+
+	queries := make([]backend.DataQuery, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		restricted, err := m.restrictQuery(req.PluginContext.PluginID, query, matchers)
+		if err != nil {
+			return nil, datasources.ErrLabelPolicyAccessDenied.Errorf("failed to apply the label policies to query %s: %w", query.RefID, err)
+		}
+		queries = append(queries, restricted)
+	}
+	req.Queries = queries
+
+	return m.next.QueryData(ctx, req)
+}
+
+// restrictResourceParams injects the label matchers into the queries or selectors of the parameters. If the
+// parameter is missing and addSelector is set, it is added with a selector matching the label matchers.
+func restrictResourceParams(pluginID string, values url.Values, param string, matchers []*labels.Matcher, addSelector bool) error {
+	if len(values[param]) == 0 {
+		if addSelector {
+			values.Set(param, selectorWithLabelMatchers(matchers))
+		}
+		return nil
+	}
+	for i, value := range values[param] {
+		restricted, err := injectLabelMatchers(pluginID, value, matchers)
+		if err != nil {
+			return err
+		}
+		values[param][i] = restricted
+	}
+	return nil
+}
+
+func (m *LabelPolicyMiddleware) restrictResource(req *backend.CallResourceRequest, matchers []*labels.Matcher) error {
+	pluginID := req.PluginContext.PluginID
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	resource := strings.TrimPrefix(u.Path, "/")
+	if resource != strings.TrimPrefix(req.Path, "/") || path.Clean(resource) != resource {
+		return fmt.Errorf("unexpected resource path %q", req.Path)
+	}
+
+	for _, unrestricted := range unrestrictedResources[pluginID] {
+		if resource == unrestricted {
+			return nil
+		}
+	}
+
+	endpoint := labelValuesPathRegexp.ReplaceAllString(resource, "label/*/values")
+	param, ok := resourceSelectorParams[pluginID][endpoint]
+	if !ok {
+		return fmt.Errorf("resource %q is not supported by label policies", resource)
+	}
+
+	// the parameters of POST requests are sent in the body
+	var bodyValues url.Values
+	if len(req.Body) > 0 {
+		if bodyValues, err = url.ParseQuery(string(req.Body)); err != nil {
+			return err
+		}
+		if err := restrictResourceParams(pluginID, bodyValues, param, matchers, false); err != nil {
+			return err
+		}
+		req.Body = []byte(bodyValues.Encode())
+	}
+
+	values := u.Query()
+	if err := restrictResourceParams(pluginID, values, param, matchers, len(bodyValues[param]) == 0); err != nil {
+		return err
+	}
+	u.RawQuery = values.Encode()
+	req.URL = u.String()
+
+	return nil
+}
+
+func (m *LabelPolicyMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	_, fromAlert := req.Headers[ngalertmodels.FromAlertHeaderName]
+	matchers, err := m.getLabelMatchers(ctx, req.PluginContext, fromAlert)
+	if err != nil {
+		return err
+	}
+	if len(matchers) == 0 {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	if err := m.restrictResource(req, matchers); err != nil {
+		return datasources.ErrLabelPolicyAccessDenied.Errorf("failed to apply the label policies to the resource request: %w", err)
+	}
+
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *LabelPolicyMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return m.next.CheckHealth(ctx, req)
+}
+
+func (m *LabelPolicyMiddleware) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	return m.next.CollectMetrics(ctx, req)
+}
+
+// canStream returns true if the user of the stream can stream from the data source. Streams are not called with an
+// HTTP request context, so only the organization admins can stream from data sources with label policies.
+func (m *LabelPolicyMiddleware) canStream(pCtx backend.PluginContext) (bool, error) {
+	labelPolicies, err := getLabelPolicies(pCtx)
+	if err != nil {
+		return false, err
+	}
+	if len(labelPolicies) == 0 {
+		return true, nil
+	}
+	return pCtx.User != nil && pCtx.User.Role == string(roletype.RoleAdmin), nil
+}
+
+func (m *LabelPolicyMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if req == nil {
+		return m.next.SubscribeStream(ctx, req)
+	}
+
+	ok, err := m.canStream(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusPermissionDenied}, nil
+	}
+
+	return m.next.SubscribeStream(ctx, req)
+}
+
+func (m *LabelPolicyMiddleware) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	if req == nil {
+		return m.next.PublishStream(ctx, req)
+	}
+
+	ok, err := m.canStream(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusPermissionDenied}, nil
+	}
+
+	return m.next.PublishStream(ctx, req)
+}
+
+func (m *LabelPolicyMiddleware) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return m.next.RunStream(ctx, req, sender)
+}
@@ -0,0 +1,249 @@
+package clientmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestLabelPolicyMiddleware(t *testing.T) {
+	const jsonData = `{"labelPolicies": [
+		{"teamId": 1, "rules": ["namespace=~\"payments-.*\""]},
+		{"role": "Editor", "rules": ["cluster=\"prod\""]}
+	]}`
+
+	pluginCtx := func(pluginID string) backend.PluginContext {
+		return backend.PluginContext{
+			PluginID: pluginID,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				JSONData: []byte(jsonData),
+			},
+		}
+	}
+
+	newTest := func(t *testing.T, usr *user.SignedInUser) (*clienttest.ClientDecoratorTest, context.Context) {
+		req, err := http.NewRequest(http.MethodGet, "/some/thing", nil)
+		require.NoError(t, err)
+		cdt := clienttest.NewClientDecoratorTest(t,
+			clienttest.WithReqContext(req, usr),
+			clienttest.WithMiddlewares(NewLabelPolicyMiddleware()),
+		)
+		return cdt, req.Context()
+	}
+
+	t.Run("Should inject the label matchers into Prometheus queries", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"expr": "sum(rate(http_requests_total{namespace=\"billing\"}[5m]))", "intervalMs": 15000}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, cdt.QueryDataReq)
+		require.JSONEq(t, `{"expr": "sum(rate(http_requests_total{namespace=\"billing\",namespace=~\"payments-.*\"}[5m]))", "intervalMs": 15000}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should inject the label matchers of all the policies of the user into Loki queries", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleEditor, Teams: []int64{1}})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_LOKI),
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"expr": "{app=\"checkout\"} |= \"error\""}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, cdt.QueryDataReq)
+		require.JSONEq(t, `{"expr": "{app=\"checkout\", namespace=~\"payments-.*\", cluster=\"prod\"} |= \"error\""}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should deny queries that cannot be restricted", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"expr": "up"}`)},
+				{RefID: "B", JSON: []byte(`{"expr": "up{"}`)},
+			},
+		})
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+
+		_, err = cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"expr": "up", "adhocFilters": [{"key": "namespace", "operator": "equals", "value": "billing"}]}`)},
+			},
+		})
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+
+		_, err = cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries: []backend.DataQuery{
+				{RefID: "A", JSON: []byte(`{"editorMode": "builder", "metric": "up"}`)},
+			},
+		})
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+	})
+
+	t.Run("Should combine the label matchers of the policies of all the teams of the user", func(t *testing.T) {
+		const jsonData = `{"labelPolicies": [
+			{"teamId": 1, "rules": ["namespace=\"payments\""]},
+			{"teamId": 2, "rules": ["namespace=\"billing\""]}
+		]}`
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1, 2}})
+
+		pCtx := pluginCtx(datasources.DS_PROMETHEUS)
+		pCtx.DataSourceInstanceSettings.JSONData = []byte(jsonData)
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pCtx,
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "up{namespace=\"payments\",namespace=\"billing\"}"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should deny requests without a user, except for alert rule evaluations", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelPolicyMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+
+		err = cdt.Decorator.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Path:          "api/v1/labels",
+			URL:           "api/v1/labels",
+		}, nopCallResourceSender)
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.CallResourceReq)
+
+		_, err = cdt.Decorator.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Headers:       map[string]string{ngalertmodels.FromAlertHeaderName: "true"},
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "up"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should deny users without a label policy", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{2}})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+	})
+
+	t.Run("Should not restrict org admins", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleAdmin})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "up"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should not restrict other data sources", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer})
+
+		_, err := cdt.Decorator.QueryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pluginCtx(datasources.DS_INFLUXDB),
+			Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"query": "SELECT * FROM cpu"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"query": "SELECT * FROM cpu"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should inject the label matchers into resource requests", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}})
+
+		err := cdt.Decorator.CallResource(ctx, &backend.CallResourceRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Path:          "api/v1/label/job/values",
+			URL:           "api/v1/label/job/values?start=1",
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+		u, err := url.Parse(cdt.CallResourceReq.URL)
+		require.NoError(t, err)
+		require.Equal(t, "api/v1/label/job/values", u.Path)
+		require.Equal(t, []string{`{namespace=~"payments-.*"}`}, u.Query()["match[]"])
+		require.Equal(t, "1", u.Query().Get("start"))
+
+		err = cdt.Decorator.CallResource(ctx, &backend.CallResourceRequest{
+			PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+			Path:          "api/v1/series",
+			URL:           "api/v1/series",
+			Method:        http.MethodPost,
+			Body:          []byte(url.Values{"match[]": {"up"}}.Encode()),
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+		body, err := url.ParseQuery(string(cdt.CallResourceReq.Body))
+		require.NoError(t, err)
+		require.Equal(t, []string{`up{namespace=~"payments-.*"}`}, body["match[]"])
+		require.Equal(t, "api/v1/series", cdt.CallResourceReq.URL)
+
+		err = cdt.Decorator.CallResource(ctx, &backend.CallResourceRequest{
+			PluginContext: pluginCtx(datasources.DS_LOKI),
+			Path:          "series",
+			URL:           "series?match[]=" + url.QueryEscape(`{app="checkout"}`),
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+		u, err = url.Parse(cdt.CallResourceReq.URL)
+		require.NoError(t, err)
+		require.Equal(t, []string{`{app="checkout", namespace=~"payments-.*"}`}, u.Query()["match[]"])
+	})
+
+	t.Run("Should deny resource requests that cannot be restricted", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}})
+
+		for _, path := range []string{"api/v1/metadata", "api/v1/label/../admin/tsdb/snapshot", "api/v1/status/config"} {
+			err := cdt.Decorator.CallResource(ctx, &backend.CallResourceRequest{
+				PluginContext: pluginCtx(datasources.DS_PROMETHEUS),
+				Path:          path,
+				URL:           path,
+			}, nopCallResourceSender)
+			require.ErrorIs(t, err, datasources.ErrLabelPolicyAccessDenied, path)
+		}
+		require.Nil(t, cdt.CallResourceReq)
+	})
+
+	t.Run("Should only allow org admins to stream from data sources with label policies", func(t *testing.T) {
+		cdt, ctx := newTest(t, &user.SignedInUser{OrgRole: org.RoleViewer, Teams: []int64{1}})
+
+		pCtx := pluginCtx(datasources.DS_LOKI)
+		pCtx.User = &backend.User{Role: string(org.RoleViewer)}
+		resp, err := cdt.Decorator.SubscribeStream(ctx, &backend.SubscribeStreamRequest{PluginContext: pCtx, Path: "tail/abc"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, resp.Status)
+		require.Nil(t, cdt.SubscribeStreamReq)
+
+		pCtx.User = &backend.User{Role: string(org.RoleAdmin)}
+		_, err = cdt.Decorator.SubscribeStream(ctx, &backend.SubscribeStreamRequest{PluginContext: pCtx, Path: "tail/abc"})
+		require.NoError(t, err)
+		require.NotNil(t, cdt.SubscribeStreamReq)
+	})
+}
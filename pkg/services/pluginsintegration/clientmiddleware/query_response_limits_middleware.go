@@ -0,0 +1,291 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	responseLimitFrames = "frames"
+	responseLimitRows   = "rows"
+	responseLimitBytes  = "bytes"
+)
+
+// NewQueryResponseLimitsMiddleware creates a new plugins.ClientMiddleware that limits the number of frames, rows and
+// serialized bytes of the query data responses, and truncates or rejects the responses above the limits.
+func NewQueryResponseLimitsMiddleware(cfg setting.QueryResponseLimitsSettings, promRegisterer prometheus.Registerer) plugins.ClientMiddleware {
+	limitHits := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "datasource",
+		Name:      "response_limit_hits_total",
+		Help:      "Number of query responses truncated or rejected because they exceeded a size limit",
+	}, []string{"datasource_type", "limit", "action"})
+	promRegisterer.MustRegister(limitHits)
+
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &QueryResponseLimitsMiddleware{
+			cfg:       cfg,
+			limitHits: limitHits,
+			next:      next,
+		}
+	})
+}
+
+type QueryResponseLimitsMiddleware struct {
+	cfg       setting.QueryResponseLimitsSettings
+	limitHits *prometheus.CounterVec
+	next      plugins.Client
+}
+
+func (m *QueryResponseLimitsMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp, err := m.next.QueryData(ctx, req)
+	if err != nil || resp == nil || req == nil {
+		return resp, err
+	}
+
+	dsType := req.PluginContext.PluginID
+	if req.PluginContext.DataSourceInstanceSettings != nil && req.PluginContext.DataSourceInstanceSettings.Type != "" {
+		dsType = req.PluginContext.DataSourceInstanceSettings.Type
+	}
+
+	limits := m.cfg.Limits(req.PluginContext.OrgID, dsType)
+	if limits.MaxFrames == 0 && limits.MaxRows == 0 && limits.MaxBytes == 0 {
+		return resp, nil
+	}
+
+	limited, limit, err := limitQueryDataResponse(resp, limits)
+	if err != nil {
+		return nil, err
+	}
+	if limit == "" {
+		return resp, nil
+	}
+
+	m.limitHits.WithLabelValues(dsType, limit, limits.Action).Inc()
+	if limits.Action == setting.QueryResponseLimitActionReject {
+		return nil, plugins.ErrQueryResponseTooLarge.Errorf("query response exceeds the %s limit of the %s data source", limit, dsType)
+	}
+	return limited, nil
+}
+
+// limitQueryDataResponse returns a copy of the response truncated to the limits, and the limit that was hit, if any.
+// The responses are processed in the order of their ref ids, so the truncation is deterministic. The response is not
+// modified, as it can be shared with the query cache.
+func limitQueryDataResponse(resp *backend.QueryDataResponse, limits setting.QueryResponseLimits) (*backend.QueryDataResponse, string, error) {
+	refIDs := make([]string, 0, len(resp.Responses))
+	for refID := range resp.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	var frames, rows int
+	var size int64
+	var limit string
+	limited := backend.NewQueryDataResponse()
+	for _, refID := range refIDs {
+		dr := resp.Responses[refID]
+		if limit != "" {
+			// The responses after the one that hit the limit only keep the notice.
+			dr.Frames = data.Frames{truncatedNoticeFrame(refID, limit, limits)}
+			limited.Responses[refID] = dr
+			continue
+		}
+
+		kept := make(data.Frames, 0, len(dr.Frames))
+		for _, frame := range dr.Frames {
+			if limits.MaxFrames > 0 && frames >= limits.MaxFrames {
+				limit = responseLimitFrames
+				break
+			}
+
+			if limits.MaxRows > 0 && rows+frame.Rows() > limits.MaxRows {
+				limit = responseLimitRows
+				frame = truncateFrameRows(frame, limits.MaxRows-rows)
+			}
+
+			if limits.MaxBytes > 0 {
+				frameSize := estimateFrameSize(frame, limits.MaxBytes-size)
+				if size+frameSize > limits.MaxBytes {
+					limit = responseLimitBytes
+					break
+				}
+				size += frameSize
+			}
+
+			kept = append(kept, frame)
+			frames++
+			rows += frame.Rows()
+			if limit != "" {
+				break
+			}
+		}
+
+		if limit != "" {
+			if len(kept) > 0 {
+				kept[len(kept)-1] = withTruncatedNotice(kept[len(kept)-1], limit, limits)
+			} else {
+				kept = append(kept, truncatedNoticeFrame(refID, limit, limits))
+			}
+		}
+		dr.Frames = kept
+		limited.Responses[refID] = dr
+	}
+
+	return limited, limit, nil
+}
+
+// estimateFrameSize estimates the size of the frame once serialized to JSON from the types and lengths of its
+// values, without serializing it. The estimation stops as soon as the size is above max.
+func estimateFrameSize(frame *data.Frame, max int64) int64 {
+	size := int64(len(frame.Name) + len(frame.RefID))
+	for _, field := range frame.Fields {
+		if size > max {
+			break
+		}
+
+		size += int64(len(field.Name))
+		for name, value := range field.Labels {
+			size += int64(len(name) + len(value))
+		}
+
+		fieldType := field.Type().NonNullableType()
+		switch fieldType {
+		case data.FieldTypeString, data.FieldTypeJSON:
+			for i := 0; i < field.Len() && size <= max; i++ {
+				size += int64(valueLen(field.At(i))) + 3
+			}
+		default:
+			size += int64(field.Len()) * (jsonValueSize(fieldType) + 1)
+		}
+	}
+	return size
+}
+
+// valueLen returns the length of a string or raw JSON value, or of the null literal for the nil values.
+func valueLen(v interface{}) int {
+	switch v := v.(type) {
+	case string:
+		return len(v)
+	case *string:
+		if v != nil {
+			return len(*v)
+		}
+	case json.RawMessage:
+		return len(v)
+	case *json.RawMessage:
+		if v != nil {
+			return len(*v)
+		}
+	}
+	return len("null")
+}
+
+// jsonValueSize returns the maximum size of a value of the fixed size field type once serialized to JSON.
+func jsonValueSize(fieldType data.FieldType) int64 {
+	switch fieldType {
+	case data.FieldTypeBool:
+		return int64(len("false"))
+	case data.FieldTypeInt8, data.FieldTypeUint8:
+		return 4
+	case data.FieldTypeInt16, data.FieldTypeUint16, data.FieldTypeEnum:
+		return 6
+	case data.FieldTypeInt32, data.FieldTypeUint32:
+		return 11
+	case data.FieldTypeTime:
+		// times are serialized as epoch milliseconds, with the nanoseconds in the entities when needed
+		return 16
+	default:
+		return 24
+	}
+}
+
+// truncateFrameRows returns a copy of the frame with its first rows.
+func truncateFrameRows(frame *data.Frame, rows int) *data.Frame {
+	fields := make([]*data.Field, len(frame.Fields))
+	for i, field := range frame.Fields {
+		truncated := data.NewFieldFromFieldType(field.Type(), rows)
+		truncated.Name = field.Name
+		truncated.Labels = field.Labels
+		truncated.Config = field.Config
+		for row := 0; row < rows; row++ {
+			truncated.Set(row, field.CopyAt(row))
+		}
+		fields[i] = truncated
+	}
+
+	truncated := data.NewFrame(frame.Name, fields...)
+	truncated.RefID = frame.RefID
+	truncated.Meta = frame.Meta
+	return truncated
+}
+
+func truncatedNotice(limit string, limits setting.QueryResponseLimits) data.Notice {
+	var value int64
+	switch limit {
+	case responseLimitFrames:
+		value = int64(limits.MaxFrames)
+	case responseLimitRows:
+		value = int64(limits.MaxRows)
+	case responseLimitBytes:
+		value = limits.MaxBytes
+	}
+
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("The query response was truncated, as it exceeds the limit of %d %s. Narrow down the query to see the complete results.", value, limit),
+	}
+}
+
+// withTruncatedNotice returns a shallow copy of the frame with the truncation notice.
+func withTruncatedNotice(frame *data.Frame, limit string, limits setting.QueryResponseLimits) *data.Frame {
+	meta := &data.FrameMeta{}
+	if frame.Meta != nil {
+		*meta = *frame.Meta
+	}
+	meta.Notices = append(append([]data.Notice{}, meta.Notices...), truncatedNotice(limit, limits))
+
+	noticed := *frame
+	noticed.Meta = meta
+	return &noticed
+}
+
+func truncatedNoticeFrame(refID string, limit string, limits setting.QueryResponseLimits) *data.Frame {
+	frame := data.NewFrame("")
+	frame.RefID = refID
+	frame.SetMeta(&data.FrameMeta{Notices: []data.Notice{truncatedNotice(limit, limits)}})
+	return frame
+}
+
+func (m *QueryResponseLimitsMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *QueryResponseLimitsMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return m.next.CheckHealth(ctx, req)
+}
+
+func (m *QueryResponseLimitsMiddleware) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	return m.next.CollectMetrics(ctx, req)
+}
+
+func (m *QueryResponseLimitsMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	return m.next.SubscribeStream(ctx, req)
+}
+
+func (m *QueryResponseLimitsMiddleware) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return m.next.PublishStream(ctx, req)
+}
+
+func (m *QueryResponseLimitsMiddleware) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return m.next.RunStream(ctx, req, sender)
+}
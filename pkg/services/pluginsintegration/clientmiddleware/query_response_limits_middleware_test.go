@@ -0,0 +1,161 @@
+package clientmiddleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryResponseLimitsMiddleware(t *testing.T) {
+	pCtx := backend.PluginContext{
+		OrgID:    1,
+		PluginID: "loki",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			UID:  "ds-uid",
+			Type: "loki",
+		},
+	}
+
+	newFrame := func(refID string, rows int) *data.Frame {
+		values := make([]int64, rows)
+		for i := range values {
+			values[i] = int64(i)
+		}
+		frame := data.NewFrame("", data.NewField("value", nil, values))
+		frame.RefID = refID
+		return frame
+	}
+
+	newResponse := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{newFrame("A", 3), newFrame("A", 3)}},
+			"B": {Frames: data.Frames{newFrame("B", 3)}},
+		}}
+	}
+
+	query := func(t *testing.T, cfg setting.QueryResponseLimitsSettings, resp *backend.QueryDataResponse) (*QueryResponseLimitsMiddleware, *backend.QueryDataResponse, error) {
+		t.Helper()
+		mw := NewQueryResponseLimitsMiddleware(cfg, prometheus.NewRegistry()).CreateClientMiddleware(&clienttest.TestClient{
+			QueryDataFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				return resp, nil
+			},
+		}).(*QueryResponseLimitsMiddleware)
+		limited, err := mw.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+		return mw, limited, err
+	}
+
+	limitsOf := func(limits setting.QueryResponseLimits) setting.QueryResponseLimitsSettings {
+		return setting.QueryResponseLimitsSettings{Enabled: true, Default: limits}
+	}
+
+	requireNotice := func(t *testing.T, frame *data.Frame, text string) {
+		t.Helper()
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		require.Contains(t, frame.Meta.Notices[0].Text, text)
+	}
+
+	t.Run("Should not change the responses within the limits", func(t *testing.T) {
+		resp := newResponse()
+		_, limited, err := query(t, limitsOf(setting.QueryResponseLimits{MaxFrames: 3, MaxRows: 9, Action: "truncate"}), resp)
+		require.NoError(t, err)
+		require.Same(t, resp, limited)
+	})
+
+	t.Run("Should truncate the frames above the limit", func(t *testing.T) {
+		resp := newResponse()
+		mw, limited, err := query(t, limitsOf(setting.QueryResponseLimits{MaxFrames: 1, Action: "truncate"}), resp)
+		require.NoError(t, err)
+
+		require.Len(t, limited.Responses["A"].Frames, 1)
+		requireNotice(t, limited.Responses["A"].Frames[0], "exceeds the limit of 1 frames")
+		require.Equal(t, 3, limited.Responses["A"].Frames[0].Rows())
+		require.Len(t, limited.Responses["B"].Frames, 1)
+		require.Equal(t, 0, limited.Responses["B"].Frames[0].Rows())
+		requireNotice(t, limited.Responses["B"].Frames[0], "exceeds the limit of 1 frames")
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.limitHits.WithLabelValues("loki", "frames", "truncate")))
+
+		// the original response is not modified
+		require.Len(t, resp.Responses["A"].Frames, 2)
+		require.Nil(t, resp.Responses["A"].Frames[0].Meta)
+	})
+
+	t.Run("Should truncate the rows above the limit", func(t *testing.T) {
+		resp := newResponse()
+		_, limited, err := query(t, limitsOf(setting.QueryResponseLimits{MaxRows: 4, Action: "truncate"}), resp)
+		require.NoError(t, err)
+
+		frames := limited.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Equal(t, 3, frames[0].Rows())
+		require.Equal(t, 1, frames[1].Rows())
+		require.Equal(t, "A", frames[1].RefID)
+		require.Equal(t, int64(0), frames[1].Fields[0].At(0))
+		requireNotice(t, frames[1], "exceeds the limit of 4 rows")
+		require.Equal(t, 3, resp.Responses["A"].Frames[1].Rows())
+	})
+
+	t.Run("Should truncate the frames above the serialized size limit", func(t *testing.T) {
+		_, limited, err := query(t, limitsOf(setting.QueryResponseLimits{MaxBytes: 100, Action: "truncate"}), newResponse())
+		require.NoError(t, err)
+
+		frames := limited.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		requireNotice(t, frames[0], "exceeds the limit of 100 bytes")
+	})
+
+	t.Run("Should estimate the serialized size from the types and lengths of the values", func(t *testing.T) {
+		str := "value"
+		frame := data.NewFrame("frame",
+			data.NewField("int", nil, []int64{1, 2}),
+			data.NewField("str", data.Labels{"job": "api"}, []*string{&str, nil}),
+		)
+		frame.RefID = "A"
+		// names, labels, 2 int64 values, and 2 quoted string values with separators
+		require.Equal(t, int64(len("frame")+len("A")+len("int")+2*25+len("str")+len("job")+len("api")+len("value")+3+len("null")+3), estimateFrameSize(frame, 1000))
+	})
+
+	t.Run("Should stop estimating the serialized size above the limit", func(t *testing.T) {
+		values := make([]string, 1000)
+		for i := range values {
+			values[i] = "a long enough log line"
+		}
+		frame := data.NewFrame("", data.NewField("line", nil, values))
+		size := estimateFrameSize(frame, 100)
+		require.Greater(t, size, int64(100))
+		require.Less(t, size, int64(200))
+	})
+
+	t.Run("Should reject the responses above the limits", func(t *testing.T) {
+		mw, limited, err := query(t, limitsOf(setting.QueryResponseLimits{MaxRows: 4, Action: "reject"}), newResponse())
+		require.ErrorIs(t, err, plugins.ErrQueryResponseTooLarge)
+		require.Nil(t, limited)
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.limitHits.WithLabelValues("loki", "rows", "reject")))
+	})
+
+	t.Run("Should use the limits of the data source type and org", func(t *testing.T) {
+		cfg := setting.QueryResponseLimitsSettings{
+			Enabled:         true,
+			DataSourceTypes: map[string]setting.QueryResponseLimits{"loki": {MaxFrames: 1, Action: "reject"}},
+			Orgs:            map[int64]setting.QueryResponseLimits{2: {Action: "reject"}},
+		}
+		_, _, err := query(t, cfg, newResponse())
+		require.ErrorIs(t, err, plugins.ErrQueryResponseTooLarge)
+
+		pCtx.OrgID = 2
+		defer func() { pCtx.OrgID = 1 }()
+		_, limited, err := query(t, cfg, newResponse())
+		require.NoError(t, err)
+		require.Len(t, limited.Responses["A"].Frames, 2)
+	})
+}
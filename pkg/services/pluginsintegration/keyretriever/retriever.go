@@ -2,20 +2,36 @@ package keyretriever
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/log"
 	"github.com/grafana/grafana/pkg/plugins/manager/signature/statickey"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/keyretriever/dynamic"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/keystore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var _ plugins.KeyRetriever = (*Service)(nil)
 
 type Service struct {
 	kr plugins.KeyRetriever
+
+	repositories   []setting.PluginRepositorySettings
+	repositoryKeys *keystore.RepositoryKeyStore
+	lock           sync.Mutex
+	synced         bool
+	log            log.Logger
 }
 
-func ProvideService(dkr *dynamic.KeyRetriever) *Service {
-	s := &Service{}
+func ProvideService(cfg *setting.Cfg, dkr *dynamic.KeyRetriever, repositoryKeys *keystore.RepositoryKeyStore) *Service {
+	s := &Service{
+		repositories:   cfg.PluginRepositories,
+		repositoryKeys: repositoryKeys,
+		log:            log.New("plugin.signature.key_retriever"),
+	}
 	if !dkr.IsDisabled() {
 		s.kr = dkr
 	} else {
@@ -24,6 +40,72 @@ func ProvideService(dkr *dynamic.KeyRetriever) *Service {
 	return s
 }
 
+// GetPublicKey returns the grafana.com public key, or the public key of a private plugin repository.
 func (kr *Service) GetPublicKey(ctx context.Context, keyID string) (string, error) {
-	return kr.kr.GetPublicKey(ctx, keyID)
+	key, err := kr.kr.GetPublicKey(ctx, keyID)
+	if err == nil {
+		return key, nil
+	}
+
+	repositoryKey, exists, rErr := kr.getRepositoryKey(ctx, keyID)
+	if rErr != nil {
+		return "", rErr
+	}
+	if exists {
+		return repositoryKey, nil
+	}
+	return "", err
+}
+
+func (kr *Service) getRepositoryKey(ctx context.Context, keyID string) (string, bool, error) {
+	if err := kr.syncRepositoryKeys(ctx); err != nil {
+		return "", false, err
+	}
+	return kr.repositoryKeys.Get(ctx, keyID)
+}
+
+// syncRepositoryKeys stores the public keys of the configured plugin repositories, and removes the keys of the
+// repositories that are no longer configured. Keys that cannot be read are kept, so plugins signed with them still
+// load if the key file is temporarily unavailable.
+func (kr *Service) syncRepositoryKeys(ctx context.Context) error {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	if kr.synced {
+		return nil
+	}
+
+	shouldKeep := make(map[string]bool)
+	for _, r := range kr.repositories {
+		if r.PublicKeyID == "" {
+			continue
+		}
+		shouldKeep[r.PublicKeyID] = true
+
+		// We can ignore the gosec G304 warning since the path of the key comes from the configuration.
+		// nolint:gosec
+		key, err := os.ReadFile(r.PublicKeyFile)
+		if err != nil {
+			kr.log.Error("Failed to read the public key of plugin repository", "repository", r.Name, "error", err)
+			continue
+		}
+		if err := kr.repositoryKeys.Set(ctx, r.PublicKeyID, string(key)); err != nil {
+			return fmt.Errorf("failed to store the public key of plugin repository %s: %w", r.Name, err)
+		}
+	}
+
+	keys, err := kr.repositoryKeys.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !shouldKeep[key] {
+			if err := kr.repositoryKeys.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	kr.synced = true
+	return nil
 }
@@ -2,6 +2,8 @@ package keyretriever
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
@@ -15,9 +17,38 @@ import (
 func Test_GetPublicKey(t *testing.T) {
 	t.Run("it should return a static key", func(t *testing.T) {
 		cfg := &setting.Cfg{}
-		kr := ProvideService(dynamic.ProvideService(cfg, keystore.ProvideService(kvstore.NewFakeKVStore())))
+		kv := kvstore.NewFakeKVStore()
+		kr := ProvideService(cfg, dynamic.ProvideService(cfg, keystore.ProvideService(kv)), keystore.ProvideRepositoryKeyStore(kv))
 		key, err := kr.GetPublicKey(context.Background(), statickey.GetDefaultKeyID())
 		require.NoError(t, err)
 		require.Equal(t, statickey.GetDefaultKey(), key)
 	})
+
+	t.Run("it should return the key of a plugin repository", func(t *testing.T) {
+		keyFile := filepath.Join(t.TempDir(), "acme.asc")
+		require.NoError(t, os.WriteFile(keyFile, []byte("acme public key"), 0600))
+
+		kv := kvstore.NewFakeKVStore()
+		repositoryKeys := keystore.ProvideRepositoryKeyStore(kv)
+		require.NoError(t, repositoryKeys.Set(context.Background(), "removed", "removed public key"))
+
+		cfg := &setting.Cfg{
+			PluginSkipPublicKeyDownload: true,
+			PluginRepositories: []setting.PluginRepositorySettings{
+				{Name: "internal", IndexFile: "index.json", PublicKeyID: "acme", PublicKeyFile: keyFile},
+			},
+		}
+		kr := ProvideService(cfg, dynamic.ProvideService(cfg, keystore.ProvideService(kv)), repositoryKeys)
+
+		key, err := kr.GetPublicKey(context.Background(), "acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme public key", key)
+
+		_, err = kr.GetPublicKey(context.Background(), "removed")
+		require.Error(t, err)
+
+		key, err = kr.GetPublicKey(context.Background(), statickey.GetDefaultKeyID())
+		require.NoError(t, err)
+		require.Equal(t, statickey.GetDefaultKey(), key)
+	})
 }
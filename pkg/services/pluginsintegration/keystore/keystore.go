@@ -12,8 +12,9 @@ type Service struct {
 }
 
 const (
-	namespace = "plugin.publickeys"
-	prefix    = "key-"
+	namespace               = "plugin.publickeys"
+	repositoryKeysNamespace = "plugin.repositorypublickeys"
+	prefix                  = "key-"
 )
 
 var _ plugins.KeyStore = (*Service)(nil)
@@ -23,3 +24,15 @@ func ProvideService(kv kvstore.KVStore) *Service {
 		CacheKvStore: cachekvstore.NewCacheKvStoreWithPrefix(kv, namespace, prefix),
 	}
 }
+
+// RepositoryKeyStore is a store for the public keys of the private plugin repositories. They are stored apart from
+// the public keys retrieved from grafana.com, which are replaced every time they are retrieved.
+type RepositoryKeyStore struct {
+	*cachekvstore.CacheKvStore
+}
+
+func ProvideRepositoryKeyStore(kv kvstore.KVStore) *RepositoryKeyStore {
+	return &RepositoryKeyStore{
+		CacheKvStore: cachekvstore.NewCacheKvStore(kv, repositoryKeysNamespace),
+	}
+}
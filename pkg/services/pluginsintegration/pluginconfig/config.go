@@ -38,6 +38,8 @@ func ProvidePluginManagementConfig(cfg *setting.Cfg, settingProvider setting.Pro
 		cfg.DisablePlugins,
 		cfg.HideAngularDeprecation,
 		cfg.ForwardHostEnvVars,
+		cfg.PluginRepositories,
+		cfg.PluginGrafanaComRepositoryDisabled,
 	), nil
 }
 
@@ -112,6 +112,7 @@ var WireSet = wire.NewSet(
 	signature.ProvideService,
 	wire.Bind(new(plugins.KeyStore), new(*keystore.Service)),
 	keystore.ProvideService,
+	keystore.ProvideRepositoryKeyStore,
 	wire.Bind(new(plugins.KeyRetriever), new(*keyretriever.Service)),
 	keyretriever.ProvideService,
 	dynamic.ProvideService,
@@ -178,9 +179,22 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		clientmiddleware.NewOAuthTokenMiddleware(oAuthTokenService),
 		clientmiddleware.NewCookiesMiddleware(skipCookiesNames),
 		clientmiddleware.NewResourceResponseMiddleware(),
-		clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features),
+		// LabelPolicyMiddleware should be above CachingMiddleware, so the restricted queries are cached separately
+		clientmiddleware.NewLabelPolicyMiddleware(),
 	)
 
+	// QueryResponseLimitsMiddleware should be above CachingMiddleware, so the cached responses are limited too
+	if cfg.QueryResponseLimits.Enabled {
+		middlewares = append(middlewares, clientmiddleware.NewQueryResponseLimitsMiddleware(cfg.QueryResponseLimits, promRegisterer))
+	}
+
+	middlewares = append(middlewares, clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features))
+
+	// DataSourceLimitsMiddleware should be below CachingMiddleware, so the cached responses are not limited
+	if cfg.DataSourceLimits.Enabled {
+		middlewares = append(middlewares, clientmiddleware.NewDataSourceLimitsMiddleware(cfg.DataSourceLimits, promRegisterer))
+	}
+
 	if features.IsEnabledGlobally(featuremgmt.FlagIdForwarding) {
 		middlewares = append(middlewares, clientmiddleware.NewForwardIDMiddleware())
 	}
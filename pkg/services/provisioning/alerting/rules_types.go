@@ -75,6 +75,7 @@ type AlertRuleV1 struct {
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+	MaxInstances         values.Int64Value       `json:"maxInstances" yaml:"maxInstances"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -132,6 +133,10 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no data set", alertRule.Title)
 	}
 	alertRule.IsPaused = rule.IsPaused.Value()
+	alertRule.MaxInstances = rule.MaxInstances.Value()
+	if alertRule.MaxInstances < 0 {
+		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: maxInstances cannot be negative", alertRule.Title)
+	}
 	if rule.NotificationSettings != nil {
 		ns, err := rule.NotificationSettings.mapToModel()
 		if err != nil {
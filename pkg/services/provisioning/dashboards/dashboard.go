@@ -3,6 +3,7 @@ package dashboards
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -21,6 +22,7 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	HandleWebhook(name string, header http.Header, body []byte) error
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -130,6 +132,17 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// HandleWebhook validates a webhook received for the specified provisioner and schedules the provisioner
+// to pick up the changes. Only provisioners reading from a Git repository support webhooks.
+func (provider *Provisioner) HandleWebhook(name string, header http.Header, body []byte) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == name {
+			return reader.handleWebhook(header, body)
+		}
+	}
+	return ErrProvisionerNotFound
+}
+
 func getFileReaders(
 	configs []*config,
 	logger log.Logger,
@@ -153,6 +166,18 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
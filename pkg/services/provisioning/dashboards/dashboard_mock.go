@@ -1,6 +1,9 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -8,6 +11,7 @@ type calls struct {
 	PollChanges                 []any
 	GetProvisionerResolvedPath  []any
 	GetAllowUIUpdatesFromConfig []any
+	HandleWebhook               []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +21,7 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	HandleWebhookFunc               func(name string, header http.Header, body []byte) error
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -67,3 +72,12 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// HandleWebhook is a mock implementation of `Provisioner.HandleWebhook`
+func (dpm *ProvisionerMock) HandleWebhook(name string, header http.Header, body []byte) error {
+	dpm.Calls.HandleWebhook = append(dpm.Calls.HandleWebhook, name)
+	if dpm.HandleWebhookFunc != nil {
+		return dpm.HandleWebhookFunc(name, header, body)
+	}
+	return nil
+}
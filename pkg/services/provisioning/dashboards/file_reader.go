@@ -41,6 +41,11 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// git is set when dashboards are read from a checkout of a Git repository.
+	git *gitRepository
+	// trigger schedules an immediate walk of the disk, e.g. when a webhook is received.
+	trigger chan struct{}
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -74,7 +79,8 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 	}, nil
 }
 
-// pollChanges periodically runs walkDisk based on interval specified in the config.
+// pollChanges periodically runs walkDisk based on interval specified in the config,
+// or earlier if a walk is triggered.
 func (fr *FileReader) pollChanges(ctx context.Context) {
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
 	for {
@@ -83,6 +89,10 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 			if err := fr.walkDisk(ctx); err != nil {
 				fr.log.Error("failed to search for dashboards", "error", err)
 			}
+		case <-fr.trigger:
+			if err := fr.walkDisk(ctx); err != nil {
+				fr.log.Error("failed to search for dashboards", "error", err)
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -93,6 +103,13 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
 	fr.log.Debug("Start walking disk", "path", fr.Path)
+	if fr.git != nil {
+		// keep provisioning from the existing checkout if the remote is unavailable
+		if err := fr.git.sync(ctx); err != nil {
+			fr.log.Error("failed to sync git repository", "url", fr.git.url, "error", err)
+		}
+	}
+
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
 		return err
@@ -303,6 +320,9 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
 		}
+		if fr.git != nil {
+			dp.CommitSHA = fr.git.head()
+		}
 		_, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(ctx, dash, dp)
 		if err != nil {
 			return provisioningMetadata, err
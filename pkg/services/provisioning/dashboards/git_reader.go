@@ -0,0 +1,284 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+var (
+	// ErrWebhookNotConfigured is returned when a webhook is received for a provisioner without a webhook secret.
+	ErrWebhookNotConfigured = errors.New("webhook is not configured for dashboard provisioner")
+	// ErrWebhookInvalidSignature is returned when a webhook payload signature does not match the webhook secret.
+	ErrWebhookInvalidSignature = errors.New("invalid webhook signature")
+	// ErrProvisionerNotFound is returned when no dashboard provisioner with the given name exists.
+	ErrProvisionerNotFound = errors.New("dashboard provisioner not found")
+)
+
+// gitRepository keeps a local checkout of a branch of a Git repository in sync with its remote.
+type gitRepository struct {
+	url           string
+	branch        string
+	dir           string
+	username      string
+	password      string
+	sshKey        string
+	webhookSecret string
+	log           log.Logger
+
+	mux    sync.Mutex
+	commit string
+}
+
+// NewDashboardGitReader returns a new FileReader that reads dashboards from a local checkout of a Git
+// repository. The checkout is updated from the remote every time the reader walks the disk.
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	url, ok := cfg.Options["url"].(string)
+	if !ok || url == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+
+	branch, _ := cfg.Options["branch"].(string)
+
+	dir, _ := cfg.Options["path"].(string)
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "grafana-provisioning-git", sanitizeDirName(cfg.Name))
+	}
+
+	subPath, _ := cfg.Options["subPath"].(string)
+	if subPath != "" && !filepath.IsLocal(subPath) {
+		return nil, fmt.Errorf("subPath %q must be a relative path inside the repository", subPath)
+	}
+
+	if cfg.Folder != "" || cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty for git provisioners, folders mirror the repository structure")
+	}
+
+	repo := &gitRepository{
+		url:           url,
+		branch:        branch,
+		dir:           dir,
+		username:      cfg.SecureOptions["username"],
+		password:      cfg.SecureOptions["password"],
+		sshKey:        cfg.SecureOptions["sshPrivateKey"],
+		webhookSecret: cfg.SecureOptions["webhookSecret"],
+		log:           log,
+	}
+	if username, ok := cfg.Options["username"].(string); ok && repo.username == "" {
+		repo.username = username
+	}
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         filepath.Join(dir, subPath),
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		folderService:                folderService,
+		FoldersFromFilesStructure:    true,
+		usageTracker:                 newUsageTracker(),
+		git:                          repo,
+		trigger:                      make(chan struct{}, 1),
+	}, nil
+}
+
+// sync clones the repository if there is no local checkout yet, otherwise it fetches the branch and resets the
+// checkout to it.
+func (r *gitRepository) sync(ctx context.Context) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	env, cleanup, err := r.environment()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(r.dir), 0o750); err != nil {
+			return err
+		}
+		args := []string{"clone", "--quiet", "--depth", "1", "--single-branch"}
+		if r.branch != "" {
+			args = append(args, "--branch", r.branch)
+		}
+		if _, err := r.run(ctx, env, "", append(args, "--", r.url, r.dir)...); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+	} else {
+		ref := r.branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		// fetch from the configured URL rather than origin so that changes to the URL are picked up
+		if _, err := r.run(ctx, env, r.dir, "fetch", "--quiet", "--depth", "1", "--", r.url, ref); err != nil {
+			return fmt.Errorf("failed to fetch repository: %w", err)
+		}
+		if _, err := r.run(ctx, env, r.dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return fmt.Errorf("failed to update checkout: %w", err)
+		}
+		if _, err := r.run(ctx, env, r.dir, "clean", "--quiet", "-fdx"); err != nil {
+			return fmt.Errorf("failed to clean checkout: %w", err)
+		}
+	}
+
+	out, err := r.run(ctx, env, r.dir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve commit: %w", err)
+	}
+
+	commit := strings.TrimSpace(out)
+	if commit != r.commit {
+		r.log.Info("Updated git checkout", "url", r.url, "branch", r.branch, "commit", commit)
+	}
+	r.commit = commit
+	return nil
+}
+
+// head returns the commit SHA of the local checkout.
+func (r *gitRepository) head() string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.commit
+}
+
+// environment returns the environment git is run with. Credentials are passed through the environment instead of
+// the command line or the remote URL so they never end up in the process list or the checkout configuration.
+func (r *gitRepository) environment() ([]string, func(), error) {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cleanup := func() {}
+
+	if r.password != "" {
+		username := r.username
+		if username == "" {
+			username = "git"
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + r.password))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	if r.sshKey != "" {
+		f, err := os.CreateTemp("", "grafana-git-key-")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() {
+			if err := os.Remove(f.Name()); err != nil {
+				r.log.Warn("Failed to remove temporary ssh key", "error", err)
+			}
+		}
+		if _, err := f.WriteString(strings.TrimSpace(r.sshKey) + "\n"); err != nil {
+			_ = f.Close()
+			cleanup()
+			return nil, nil, err
+		}
+		if err := f.Close(); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %q -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", f.Name()))
+	}
+
+	return env, cleanup, nil
+}
+
+func (r *gitRepository) run(ctx context.Context, env []string, dir string, args ...string) (string, error) {
+	// nolint:gosec
+	// The arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// verifyWebhook checks the webhook payload against the configured secret. GitHub and Gitea style HMAC signatures
+// as well as GitLab style tokens are supported.
+func (r *gitRepository) verifyWebhook(header http.Header, body []byte) error {
+	if r.webhookSecret == "" {
+		return ErrWebhookNotConfigured
+	}
+
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(r.webhookSecret)) != 1 {
+			return ErrWebhookInvalidSignature
+		}
+		return nil
+	}
+
+	signature := header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		signature = header.Get("X-Gitea-Signature")
+	}
+	signature = strings.TrimPrefix(signature, "sha256=")
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || signature == "" {
+		return ErrWebhookInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(r.webhookSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrWebhookInvalidSignature
+	}
+	return nil
+}
+
+// handleWebhook validates the webhook and schedules a sync of the repository.
+func (fr *FileReader) handleWebhook(header http.Header, body []byte) error {
+	if fr.git == nil {
+		return ErrWebhookNotConfigured
+	}
+	if err := fr.git.verifyWebhook(header, body); err != nil {
+		return err
+	}
+
+	select {
+	case fr.trigger <- struct{}{}:
+	default:
+		// a sync is already scheduled
+	}
+	return nil
+}
+
+func sanitizeDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
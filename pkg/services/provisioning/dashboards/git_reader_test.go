@@ -0,0 +1,199 @@
+package dashboards
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := filepath.Join(t.TempDir(), "dashboards.git")
+	work := t.TempDir()
+	runGit(t, "", "init", "--quiet", "--bare", remote)
+	runGit(t, "", "clone", "--quiet", remote, work)
+
+	dashboard, err := os.ReadFile(filepath.Join(oneDashboard, "dashboard1.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "dashboards", "team-a"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "team-a", "dashboard1.json"), dashboard, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "README.md"), []byte("dashboards"), 0o600))
+	firstCommit := commitAndPush(t, work, "add dashboard")
+
+	cfg := &config{
+		Name:  configName,
+		Type:  "git",
+		OrgID: 1,
+		Options: map[string]any{
+			"url":     remote,
+			"branch":  "main",
+			"path":    filepath.Join(t.TempDir(), "checkout"),
+			"subPath": "dashboards",
+		},
+	}
+
+	var provisioned []*dashboards.DashboardProvisioning
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+	fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil)
+	fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.MatchedBy(func(cmd *folder.CreateFolderCommand) bool {
+		return cmd.Title == "team-a"
+	})).Return(&folder.Folder{ID: 1, UID: "team-a"}, nil)
+	fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).
+		Return(&dashboards.Dashboard{}, nil).
+		Run(func(args mock.Arguments) {
+			provisioned = append(provisioned, args.Get(2).(*dashboards.DashboardProvisioning))
+		})
+
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), fakeService, &fakeDashboardStore{}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, reader.walkDisk(context.Background()))
+	require.Len(t, provisioned, 1)
+	assert.Equal(t, firstCommit, provisioned[0].CommitSHA)
+	assert.Equal(t, "dashboard1.json", filepath.Base(provisioned[0].ExternalID))
+
+	t.Run("should provision changes pushed to the branch", func(t *testing.T) {
+		changed := strings.Replace(string(dashboard), `"title": "Grafana"`, `"title": "Grafana changed"`, 1)
+		require.NotEqual(t, string(dashboard), changed)
+		require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "team-a", "dashboard1.json"), []byte(changed), 0o600))
+		secondCommit := commitAndPush(t, work, "change dashboard")
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.Len(t, provisioned, 2)
+		assert.Equal(t, secondCommit, provisioned[1].CommitSHA)
+	})
+
+	t.Run("should keep provisioning from the checkout when the remote is unavailable", func(t *testing.T) {
+		reader.git.url = filepath.Join(t.TempDir(), "missing.git")
+		reader.git.branch = "missing"
+		require.NoError(t, reader.walkDisk(context.Background()))
+	})
+}
+
+func TestNewDashboardGitReader(t *testing.T) {
+	setup := func() *config {
+		return &config{
+			Name:    "Git",
+			Type:    "git",
+			OrgID:   1,
+			Options: map[string]any{"url": "https://example.com/dashboards.git"},
+		}
+	}
+
+	t.Run("requires url", func(t *testing.T) {
+		cfg := setup()
+		delete(cfg.Options, "url")
+		_, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects subPath outside of the repository", func(t *testing.T) {
+		cfg := setup()
+		cfg.Options["subPath"] = "../etc"
+		_, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects folder", func(t *testing.T) {
+		cfg := setup()
+		cfg.Folder = "General"
+		_, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("mirrors repository structure", func(t *testing.T) {
+		reader, err := NewDashboardGitReader(setup(), log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		require.True(t, reader.FoldersFromFilesStructure)
+	})
+}
+
+func TestGitReaderWebhook(t *testing.T) {
+	cfg := &config{
+		Name:          "Git",
+		Type:          "git",
+		OrgID:         1,
+		Options:       map[string]any{"url": "https://example.com/dashboards.git"},
+		SecureOptions: map[string]string{"webhookSecret": "secret"},
+	}
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+	require.NoError(t, err)
+
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	t.Run("rejects invalid signature", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", "sha256=0000")
+		require.ErrorIs(t, reader.handleWebhook(header, body), ErrWebhookInvalidSignature)
+		require.ErrorIs(t, reader.handleWebhook(http.Header{}, body), ErrWebhookInvalidSignature)
+	})
+
+	t.Run("accepts valid signature and triggers a sync", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", signature)
+		require.NoError(t, reader.handleWebhook(header, body))
+		// a second webhook while a sync is pending must not block
+		require.NoError(t, reader.handleWebhook(header, body))
+		require.Len(t, reader.trigger, 1)
+	})
+
+	t.Run("accepts gitlab token", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Gitlab-Token", "secret")
+		require.NoError(t, reader.handleWebhook(header, body))
+
+		header.Set("X-Gitlab-Token", "wrong")
+		require.ErrorIs(t, reader.handleWebhook(header, body), ErrWebhookInvalidSignature)
+	})
+
+	t.Run("file readers don't support webhooks", func(t *testing.T) {
+		fileReader, err := NewDashboardFileReader(&config{Options: map[string]any{"path": oneDashboard}}, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, fileReader.handleWebhook(http.Header{}, body), ErrWebhookNotConfigured)
+	})
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=grafana", "GIT_AUTHOR_EMAIL=grafana@example.com",
+		"GIT_COMMITTER_NAME=grafana", "GIT_COMMITTER_EMAIL=grafana@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func commitAndPush(t *testing.T, dir string, message string) string {
+	t.Helper()
+
+	runGit(t, dir, "add", "--all")
+	runGit(t, dir, "commit", "--quiet", "-m", message)
+	runGit(t, dir, "push", "--quiet", "origin", "HEAD:main")
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
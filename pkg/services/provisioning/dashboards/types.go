@@ -18,6 +18,7 @@ type config struct {
 	FolderUID             string
 	Editable              bool
 	Options               map[string]any
+	SecureOptions         map[string]string
 	DisableDeletion       bool
 	UpdateIntervalSeconds int64
 	AllowUIUpdates        bool
@@ -45,16 +46,17 @@ type configV1 struct {
 }
 
 type configs struct {
-	Name                  values.StringValue `json:"name" yaml:"name"`
-	Type                  values.StringValue `json:"type" yaml:"type"`
-	OrgID                 values.Int64Value  `json:"orgId" yaml:"orgId"`
-	Folder                values.StringValue `json:"folder" yaml:"folder"`
-	FolderUID             values.StringValue `json:"folderUid" yaml:"folderUid"`
-	Editable              values.BoolValue   `json:"editable" yaml:"editable"`
-	Options               values.JSONValue   `json:"options" yaml:"options"`
-	DisableDeletion       values.BoolValue   `json:"disableDeletion" yaml:"disableDeletion"`
-	UpdateIntervalSeconds values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
-	AllowUIUpdates        values.BoolValue   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	Name                  values.StringValue    `json:"name" yaml:"name"`
+	Type                  values.StringValue    `json:"type" yaml:"type"`
+	OrgID                 values.Int64Value     `json:"orgId" yaml:"orgId"`
+	Folder                values.StringValue    `json:"folder" yaml:"folder"`
+	FolderUID             values.StringValue    `json:"folderUid" yaml:"folderUid"`
+	Editable              values.BoolValue      `json:"editable" yaml:"editable"`
+	Options               values.JSONValue      `json:"options" yaml:"options"`
+	SecureOptions         values.StringMapValue `json:"secureOptions" yaml:"secureOptions"`
+	DisableDeletion       values.BoolValue      `json:"disableDeletion" yaml:"disableDeletion"`
+	UpdateIntervalSeconds values.Int64Value     `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
+	AllowUIUpdates        values.BoolValue      `json:"allowUiUpdates" yaml:"allowUiUpdates"`
 }
 
 func createDashboardJSON(data *simplejson.Json, lastModified time.Time, cfg *config, folderID int64, folderUID string) (*dashboards.SaveDashboardDTO, error) {
@@ -121,6 +123,7 @@ func (dc *configV1) mapToDashboardsAsConfig() ([]*config, error) {
 			FolderUID:             v.FolderUID.Value(),
 			Editable:              v.Editable.Value(),
 			Options:               v.Options.Value(),
+			SecureOptions:         v.SecureOptions.Value(),
 			DisableDeletion:       v.DisableDeletion.Value(),
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds.Value(),
 			AllowUIUpdates:        v.AllowUIUpdates.Value(),
@@ -0,0 +1,175 @@
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// DriftStore is the store the provisioned data sources are compared with.
+type DriftStore interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+	DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error)
+}
+
+// DataSourceDrift lists the differences between a provisioned data source and the data source in the database, for
+// example after it has been edited in the UI.
+type DataSourceDrift struct {
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name"`
+	UID   string `json:"uid,omitempty"`
+	// Missing is set when the provisioned data source does not exist in the database.
+	Missing bool         `json:"missing,omitempty"`
+	Fields  []FieldDrift `json:"fields,omitempty"`
+}
+
+// FieldDrift is a field whose value in the database is not the provisioned value.
+type FieldDrift struct {
+	Field string `json:"field"`
+	// Provisioned and Current are not set for the secure json data, so that the secrets are not returned.
+	Provisioned any `json:"provisioned"`
+	Current     any `json:"current"`
+}
+
+// DetectDrift compares the data sources of the provisioning files with the data sources in the database, and returns
+// the data sources that differ.
+func DetectDrift(ctx context.Context, configDirectory string, store DriftStore, orgService org.Service) ([]*DataSourceDrift, error) {
+	cr := &configReader{log: log.New("provisioning.datasources"), orgService: orgService}
+	configs, err := cr.readConfig(ctx, configDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := []*DataSourceDrift{}
+	for _, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			drift, err := detectDataSourceDrift(ctx, store, ds)
+			if err != nil {
+				return nil, err
+			}
+			if drift != nil {
+				drifts = append(drifts, drift)
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// ProvisionedDataSources returns the keys of the data sources of the provisioning files.
+func ProvisionedDataSources(ctx context.Context, configDirectory string, orgService org.Service) (map[DataSourceMapKey]bool, error) {
+	cr := &configReader{log: log.New("provisioning.datasources"), orgService: orgService}
+	configs, err := cr.readConfig(ctx, configDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[DataSourceMapKey]bool{}
+	for _, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			keys[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = true
+		}
+	}
+	return keys, nil
+}
+
+func detectDataSourceDrift(ctx context.Context, store DriftStore, ds *upsertDataSourceFromConfig) (*DataSourceDrift, error) {
+	dataSource, err := store.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: ds.OrgID, Name: ds.Name})
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return &DataSourceDrift{OrgID: ds.OrgID, Name: ds.Name, UID: ds.UID, Missing: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &DataSourceDrift{OrgID: ds.OrgID, Name: ds.Name, UID: dataSource.UID}
+	compare := func(field string, provisioned, current any) {
+		if !reflect.DeepEqual(provisioned, current) {
+			drift.Fields = append(drift.Fields, FieldDrift{Field: field, Provisioned: provisioned, Current: current})
+		}
+	}
+
+	// The data source keeps its uid when the provisioning file does not set one.
+	if ds.UID != "" {
+		compare("uid", ds.UID, dataSource.UID)
+	}
+	compare("type", ds.Type, dataSource.Type)
+	compare("access", ds.Access, string(dataSource.Access))
+	compare("url", ds.URL, dataSource.URL)
+	compare("user", ds.User, dataSource.User)
+	compare("database", ds.Database, dataSource.Database)
+	compare("basicAuth", ds.BasicAuth, dataSource.BasicAuth)
+	compare("basicAuthUser", ds.BasicAuthUser, dataSource.BasicAuthUser)
+	compare("withCredentials", ds.WithCredentials, dataSource.WithCredentials)
+	compare("isDefault", ds.IsDefault, dataSource.IsDefault)
+	compare("editable", ds.Editable, !dataSource.ReadOnly)
+
+	provisionedJSON, err := normalizeJSONData(ds.JSONData)
+	if err != nil {
+		return nil, err
+	}
+	var currentJSON map[string]any
+	if dataSource.JsonData != nil {
+		if currentJSON, err = normalizeJSONData(dataSource.JsonData.MustMap()); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range unionKeys(provisionedJSON, currentJSON) {
+		compare("jsonData."+key, provisionedJSON[key], currentJSON[key])
+	}
+
+	// The secure json data of the provisioning files replaces all of the secure json data of the data source.
+	decrypted, err := store.DecryptedValues(ctx, dataSource)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range unionKeys(ds.SecureJSONData, decrypted) {
+		provisioned, inFile := ds.SecureJSONData[key]
+		current, inDB := decrypted[key]
+		if inFile != inDB || provisioned != current {
+			drift.Fields = append(drift.Fields, FieldDrift{Field: "secureJsonData." + key})
+		}
+	}
+
+	if len(drift.Fields) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
+
+// normalizeJSONData converts the json data to the types it has once stored, so that the values read from YAML and
+// from the database can be compared.
+func normalizeJSONData(jsonData map[string]any) (map[string]any, error) {
+	normalized := map[string]any{}
+	if len(jsonData) == 0 {
+		return normalized, nil
+	}
+
+	b, err := json.Marshal(jsonData)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package datasources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+)
+
+const driftConfig = "testdata/drift"
+
+type fakeDriftStore struct {
+	spyStore
+	secure map[string]map[string]string
+}
+
+func (s *fakeDriftStore) DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
+	return s.secure[ds.UID], nil
+}
+
+func TestDetectDrift(t *testing.T) {
+	orgFake := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1}}
+	store := &fakeDriftStore{
+		spyStore: spyStore{items: []*datasources.DataSource{
+			{
+				OrgID: 1, Name: "Prometheus", UID: "prom", Type: "prometheus", Access: datasources.DS_ACCESS_PROXY,
+				URL: "http://prometheus:9091", IsDefault: true,
+				JsonData: simplejson.NewFromAny(map[string]any{"httpMethod": "POST", "timeInterval": "15s"}),
+			},
+			{
+				OrgID: 1, Name: "Loki", UID: "loki", Type: "loki", Access: datasources.DS_ACCESS_PROXY,
+				URL: "http://loki:3100", ReadOnly: true,
+				JsonData: simplejson.NewFromAny(map[string]any{"maxLines": 1000}),
+			},
+		}},
+		secure: map[string]map[string]string{
+			"prom": {"httpHeaderValue1": "changed"},
+		},
+	}
+
+	drift, err := DetectDrift(context.Background(), driftConfig, store, orgFake)
+	require.NoError(t, err)
+	require.Equal(t, []*DataSourceDrift{
+		{
+			OrgID: 1, Name: "Prometheus", UID: "prom",
+			Fields: []FieldDrift{
+				{Field: "url", Provisioned: "http://prometheus:9090", Current: "http://prometheus:9091"},
+				{Field: "jsonData.timeInterval", Provisioned: "30s", Current: "15s"},
+				{Field: "secureJsonData.httpHeaderValue1"},
+			},
+		},
+		{OrgID: 1, Name: "Tempo", Missing: true},
+	}, drift)
+
+	t.Run("Should not report the provisioned data sources without changes", func(t *testing.T) {
+		store.items[0].URL = "http://prometheus:9090"
+		store.items[0].JsonData.Set("timeInterval", "30s")
+		store.secure["prom"]["httpHeaderValue1"] = "secret"
+		store.items = append(store.items, &datasources.DataSource{OrgID: 1, Name: "Tempo", UID: "tempo", Type: "tempo", Access: datasources.DS_ACCESS_PROXY, URL: "http://tempo:3200", ReadOnly: true})
+
+		drift, err := DetectDrift(context.Background(), driftConfig, store, orgFake)
+		require.NoError(t, err)
+		require.Empty(t, drift)
+	})
+
+	t.Run("Should return the provisioned data sources", func(t *testing.T) {
+		provisioned, err := ProvisionedDataSources(context.Background(), driftConfig, orgFake)
+		require.NoError(t, err)
+		require.Equal(t, map[DataSourceMapKey]bool{
+			{Name: "Prometheus", OrgId: 1}: true,
+			{Name: "Loki", OrgId: 1}:       true,
+			{Name: "Tempo", OrgId: 1}:      true,
+		}, provisioned)
+	})
+}
+
+func TestFilesChecksum(t *testing.T) {
+	dir := t.TempDir()
+	empty, err := FilesChecksum(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+
+	checksum, err := FilesChecksum(dir)
+	require.NoError(t, err)
+	require.Equal(t, empty, checksum)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "datasources.yaml"), []byte("apiVersion: 1"), 0600))
+	changed, err := FilesChecksum(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, changed)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("Data sources"), 0600))
+	unchanged, err := FilesChecksum(dir)
+	require.NoError(t, err)
+	require.Equal(t, changed, unchanged, "only the provisioning files are read")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "datasources.yaml"), []byte("apiVersion: 1\ndatasources: []"), 0600))
+	changed, err = FilesChecksum(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, unchanged, changed)
+}
@@ -0,0 +1,43 @@
+package datasources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FilesChecksum returns a checksum of the provisioning files of a directory, which changes when a file is added,
+// removed or changed. The files are read rather than watched for events, as the provisioning directories are often
+// mounted from Kubernetes config maps, whose files are replaced through symlinks.
+func FilesChecksum(configDirectory string) (string, error) {
+	h := sha256.New()
+	files, err := os.ReadDir(configDirectory)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return hex.EncodeToString(h.Sum(nil)), nil
+		}
+		return "", err
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the directory comes from the provisioning path
+		content, err := os.ReadFile(filepath.Join(configDirectory, file.Name()))
+		if err != nil {
+			return "", err
+		}
+		_, _ = h.Write([]byte(file.Name()))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write(content)
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
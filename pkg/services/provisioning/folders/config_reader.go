@@ -0,0 +1,190 @@
+package folders
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*foldersAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*foldersAsConfig, error) {
+	var configs []*foldersAsConfig
+	cr.log.Debug("Looking for folder provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read folder provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing folder provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseFolderConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating folders")
+	if err := validateFolders(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseFolderConfig(path string, file fs.DirEntry) (*foldersAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *foldersAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToFoldersFromConfig(), nil
+}
+
+func validateFolders(configs []*foldersAsConfig) error {
+	seen := map[int64]map[string]bool{}
+	for _, cfg := range configs {
+		for index, f := range cfg.Folders {
+			if f.UID == "" || f.Title == "" {
+				return fmt.Errorf("folder item %d in configuration doesn't contain required fields uid and title", index+1)
+			}
+			if f.UID == accesscontrol.GeneralFolderUID || f.UID == f.ParentUID {
+				return fmt.Errorf("folder %q has an invalid uid", f.UID)
+			}
+			if f.OrgID < 1 {
+				f.OrgID = 1
+			}
+			if seen[f.OrgID][f.UID] {
+				return fmt.Errorf("folder %q in organization %d is provisioned more than once", f.UID, f.OrgID)
+			}
+			if _, ok := seen[f.OrgID]; !ok {
+				seen[f.OrgID] = map[string]bool{}
+			}
+			seen[f.OrgID][f.UID] = true
+
+			for _, p := range f.Permissions {
+				if err := validatePermission(f.UID, p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePermission(uid string, p *permissionFromConfig) error {
+	assignees := 0
+	for _, a := range []string{p.Role, p.Team, p.User} {
+		if a != "" {
+			assignees++
+		}
+	}
+	if assignees != 1 {
+		return fmt.Errorf("permission of folder %q must contain exactly one of role, team or user", uid)
+	}
+
+	switch org.RoleType(p.Role) {
+	case "", org.RoleViewer, org.RoleEditor, org.RoleAdmin:
+	default:
+		return fmt.Errorf("invalid role %q in permission of folder %q, must be Viewer, Editor or Admin", p.Role, uid)
+	}
+
+	switch p.Permission {
+	case dashboardaccess.PERMISSION_VIEW.String(), dashboardaccess.PERMISSION_EDIT.String(), dashboardaccess.PERMISSION_ADMIN.String():
+	default:
+		return fmt.Errorf("invalid permission %q in permission of folder %q, must be View, Edit or Admin", p.Permission, uid)
+	}
+
+	return nil
+}
+
+// sortByHierarchy returns the folders ordered so that every folder comes after its parent if the parent is
+// provisioned as well.
+func sortByHierarchy(configs []*foldersAsConfig) ([]*folderFromConfig, error) {
+	type key struct {
+		orgID int64
+		uid   string
+	}
+
+	byKey := map[key]*folderFromConfig{}
+	var all []*folderFromConfig
+	for _, cfg := range configs {
+		for _, f := range cfg.Folders {
+			byKey[key{f.OrgID, f.UID}] = f
+			all = append(all, f)
+		}
+	}
+
+	sorted := make([]*folderFromConfig, 0, len(all))
+	visited := map[key]bool{}
+	visiting := map[key]bool{}
+	var visit func(f *folderFromConfig) error
+	visit = func(f *folderFromConfig) error {
+		k := key{f.OrgID, f.UID}
+		if visited[k] {
+			return nil
+		}
+		if visiting[k] {
+			return fmt.Errorf("folder %q is its own ancestor", f.UID)
+		}
+		visiting[k] = true
+		if parent, ok := byKey[key{f.OrgID, f.ParentUID}]; ok {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		visiting[k] = false
+		visited[k] = true
+		sorted = append(sorted, f)
+		return nil
+	}
+
+	for _, f := range all {
+		if err := visit(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,80 @@
+package folders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+	invalidPermission = "./testdata/test-configs/invalid-permission"
+	cycle             = "./testdata/test-configs/cycle"
+	correctProperties = "./testdata/test-configs/correct-properties"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Permission with several assignees should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(invalidPermission)
+		require.EqualError(t, err, `permission of folder "platform" must contain exactly one of role, team or user`)
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+		require.Len(t, cfg[0].Folders, 2)
+
+		platform := cfg[0].Folders[1]
+		require.Equal(t, "platform", platform.UID)
+		require.Equal(t, "Platform", platform.Title)
+		require.Equal(t, "Dashboards of the platform team", platform.Description)
+		require.Equal(t, int64(1), platform.OrgID)
+		require.True(t, platform.DeleteOnRemoval)
+		require.Equal(t, []*permissionFromConfig{
+			{Role: "Viewer", Permission: "View"},
+			{Team: "Platform", Permission: "Edit"},
+		}, platform.Permissions)
+	})
+}
+
+func TestSortByHierarchy(t *testing.T) {
+	t.Run("Parents come before their children", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+
+		folders, err := sortByHierarchy(cfg)
+		require.NoError(t, err)
+		require.Len(t, folders, 2)
+		require.Equal(t, "platform", folders[0].UID)
+		require.Equal(t, "platform-alerts", folders[1].UID)
+	})
+
+	t.Run("Cycles should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(cycle)
+		require.NoError(t, err)
+
+		_, err = sortByHierarchy(cfg)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,260 @@
+package folders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var provisionerPermissions = []accesscontrol.Permission{
+	{Action: dashboards.ActionFoldersCreate},
+	{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionFoldersDelete, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionFoldersPermissionsRead, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionFoldersPermissionsWrite, Scope: dashboards.ScopeFoldersAll},
+	{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+}
+
+// Provision scans a directory for provisioning config files
+// and provisions the folders and their permissions in those files.
+func Provision(ctx context.Context, configDirectory string, folderService folder.Service, dashboardProvisioningService dashboards.DashboardProvisioningService,
+	folderPermissionsService accesscontrol.FolderPermissionsService, teamService team.Service, userService user.Service, orgService org.Service, kv kvstore.KVStore) error {
+	logger := log.New("provisioning.folders")
+	fp := FolderProvisioner{
+		log:                          logger,
+		cfgProvider:                  newConfigReader(logger),
+		folderService:                folderService,
+		dashboardProvisioningService: dashboardProvisioningService,
+		folderPermissionsService:     folderPermissionsService,
+		teamService:                  teamService,
+		userService:                  userService,
+		orgService:                   orgService,
+		provenance:                   utils.NewProvenanceStore(kv, "folders"),
+	}
+	return fp.applyChanges(ctx, configDirectory)
+}
+
+// FolderProvisioner is responsible for provisioning folders and their permissions based on
+// configuration read by the `configReader`
+type FolderProvisioner struct {
+	log                          log.Logger
+	cfgProvider                  configReader
+	folderService                folder.Service
+	dashboardProvisioningService dashboards.DashboardProvisioningService
+	folderPermissionsService     accesscontrol.FolderPermissionsService
+	teamService                  team.Service
+	userService                  user.Service
+	orgService                   org.Service
+	provenance                   *utils.ProvenanceStore
+}
+
+func (fp *FolderProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := fp.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	folders, err := sortByHierarchy(configs)
+	if err != nil {
+		return err
+	}
+
+	previous, err := fp.provenance.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := utils.ProvisionedResources{}
+	for _, f := range folders {
+		if err := utils.CheckOrgExists(ctx, fp.orgService, f.OrgID); err != nil {
+			return err
+		}
+
+		prev, _ := previous.Get(f.OrgID, f.UID)
+		resource, err := fp.apply(ctx, f, prev)
+		if err != nil {
+			return err
+		}
+		current.Set(f.OrgID, f.UID, resource)
+	}
+
+	if err := fp.removeFolders(ctx, previous, current); err != nil {
+		return err
+	}
+
+	return fp.provenance.Save(ctx, current)
+}
+
+// apply creates, updates or moves the folder and grants or revokes its provisioned permissions.
+func (fp *FolderProvisioner) apply(ctx context.Context, f *folderFromConfig, prev utils.ProvisionedResource) (utils.ProvisionedResource, error) {
+	resource := utils.ProvisionedResource{DeleteOnRemoval: f.DeleteOnRemoval, Assignments: map[string]string{}}
+	signedInUser := accesscontrol.BackgroundUser("folder_provisioning", f.OrgID, org.RoleAdmin, provisionerPermissions)
+
+	existing, err := fp.folderService.Get(ctx, &folder.GetFolderQuery{UID: &f.UID, OrgID: f.OrgID, SignedInUser: signedInUser})
+	switch {
+	case errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, folder.ErrFolderNotFound):
+		fp.log.Info("Creating folder from configuration", "uid", f.UID, "orgId", f.OrgID)
+		if _, err := fp.dashboardProvisioningService.SaveFolderForProvisionedDashboards(ctx, &folder.CreateFolderCommand{
+			UID:         f.UID,
+			OrgID:       f.OrgID,
+			Title:       f.Title,
+			Description: f.Description,
+			ParentUID:   f.ParentUID,
+		}); err != nil {
+			return resource, fmt.Errorf("failed to create folder %q: %w", f.UID, err)
+		}
+	case err != nil:
+		return resource, err
+	default:
+		if existing.Title != f.Title || existing.Description != f.Description {
+			fp.log.Info("Updating folder from configuration", "uid", f.UID, "orgId", f.OrgID)
+			if _, err := fp.folderService.Update(ctx, &folder.UpdateFolderCommand{
+				UID:            f.UID,
+				OrgID:          f.OrgID,
+				NewTitle:       &f.Title,
+				NewDescription: &f.Description,
+				Overwrite:      true,
+				SignedInUser:   signedInUser,
+			}); err != nil {
+				return resource, fmt.Errorf("failed to update folder %q: %w", f.UID, err)
+			}
+		}
+		if existing.ParentUID != f.ParentUID {
+			fp.log.Info("Moving folder from configuration", "uid", f.UID, "orgId", f.OrgID, "parentUid", f.ParentUID)
+			if _, err := fp.folderService.Move(ctx, &folder.MoveFolderCommand{
+				UID:          f.UID,
+				NewParentUID: f.ParentUID,
+				OrgID:        f.OrgID,
+				SignedInUser: signedInUser,
+			}); err != nil {
+				return resource, fmt.Errorf("failed to move folder %q: %w", f.UID, err)
+			}
+		}
+	}
+
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(f.Permissions)+len(prev.Assignments))
+	for _, p := range f.Permissions {
+		key, cmd, err := fp.resolvePermission(ctx, signedInUser, f.OrgID, p)
+		if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, team.ErrTeamNotFound) {
+			// Users from LDAP or OAuth only exist after their first login, the folder is provisioned without their permission until then.
+			fp.log.Warn("Skipping folder permission of a user or team that does not exist", "uid", f.UID, "orgId", f.OrgID, "user", p.User, "team", p.Team)
+			continue
+		}
+		if err != nil {
+			return resource, fmt.Errorf("failed to set permissions of folder %q: %w", f.UID, err)
+		}
+		resource.Assignments[key] = p.Permission
+		commands = append(commands, cmd)
+	}
+
+	// Only permissions that were granted by the provisioner are revoked, permissions granted in the UI are kept.
+	for key := range prev.Assignments {
+		if _, ok := resource.Assignments[key]; ok {
+			continue
+		}
+		if cmd, ok := parseAssignment(key); ok {
+			commands = append(commands, cmd)
+		}
+	}
+
+	if len(commands) > 0 {
+		if _, err := fp.folderPermissionsService.SetPermissions(ctx, f.OrgID, f.UID, commands...); err != nil {
+			return resource, fmt.Errorf("failed to set permissions of folder %q: %w", f.UID, err)
+		}
+	}
+
+	return resource, nil
+}
+
+// resolvePermission returns the provenance key and the permission command for a permission from the configuration.
+func (fp *FolderProvisioner) resolvePermission(ctx context.Context, signedInUser identity.Requester, orgID int64, p *permissionFromConfig) (string, accesscontrol.SetResourcePermissionCommand, error) {
+	cmd := accesscontrol.SetResourcePermissionCommand{Permission: p.Permission}
+
+	switch {
+	case p.Role != "":
+		cmd.BuiltinRole = p.Role
+		return "role:" + p.Role, cmd, nil
+	case p.Team != "":
+		result, err := fp.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{OrgID: orgID, Name: p.Team, Limit: 1, SignedInUser: signedInUser})
+		if err != nil {
+			return "", cmd, err
+		}
+		if len(result.Teams) == 0 {
+			return "", cmd, fmt.Errorf("team %q: %w", p.Team, team.ErrTeamNotFound)
+		}
+		cmd.TeamID = result.Teams[0].ID
+		return "team:" + strconv.FormatInt(cmd.TeamID, 10), cmd, nil
+	default:
+		usr, err := fp.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: p.User})
+		if err != nil {
+			return "", cmd, fmt.Errorf("user %q: %w", p.User, err)
+		}
+		cmd.UserID = usr.ID
+		return "user:" + strconv.FormatInt(cmd.UserID, 10), cmd, nil
+	}
+}
+
+// parseAssignment returns the command revoking a permission recorded with the given provenance key.
+func parseAssignment(key string) (accesscontrol.SetResourcePermissionCommand, bool) {
+	kind, value, ok := strings.Cut(key, ":")
+	if !ok {
+		return accesscontrol.SetResourcePermissionCommand{}, false
+	}
+
+	switch kind {
+	case "role":
+		return accesscontrol.SetResourcePermissionCommand{BuiltinRole: value}, true
+	case "team", "user":
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return accesscontrol.SetResourcePermissionCommand{}, false
+		}
+		if kind == "team" {
+			return accesscontrol.SetResourcePermissionCommand{TeamID: id}, true
+		}
+		return accesscontrol.SetResourcePermissionCommand{UserID: id}, true
+	}
+	return accesscontrol.SetResourcePermissionCommand{}, false
+}
+
+// removeFolders deletes or releases folders that were provisioned before but are no longer part of the configuration.
+func (fp *FolderProvisioner) removeFolders(ctx context.Context, previous, current utils.ProvisionedResources) error {
+	for orgID, resources := range previous {
+		for uid, resource := range resources {
+			if _, ok := current.Get(orgID, uid); ok {
+				continue
+			}
+
+			if !resource.DeleteOnRemoval {
+				fp.log.Info("Folder removed from configuration, it is no longer provisioned", "uid", uid, "orgId", orgID)
+				continue
+			}
+
+			fp.log.Info("Deleting folder removed from configuration", "uid", uid, "orgId", orgID)
+			err := fp.folderService.Delete(ctx, &folder.DeleteFolderCommand{
+				UID:          uid,
+				OrgID:        orgID,
+				SignedInUser: accesscontrol.BackgroundUser("folder_provisioning", orgID, org.RoleAdmin, provisionerPermissions),
+			})
+			if err != nil && !errors.Is(err, dashboards.ErrFolderNotFound) && !errors.Is(err, folder.ErrFolderNotFound) {
+				return fmt.Errorf("failed to delete folder %q: %w", uid, err)
+			}
+		}
+	}
+
+	return nil
+}
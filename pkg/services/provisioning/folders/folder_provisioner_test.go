@@ -0,0 +1,222 @@
+package folders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestFolderProvisioner(t *testing.T) {
+	t.Run("Should return error when config reader returns error", func(t *testing.T) {
+		expectedErr := errors.New("test")
+		fp := FolderProvisioner{log: log.New("test"), cfgProvider: &testConfigReader{err: expectedErr}}
+		err := fp.applyChanges(context.Background(), "")
+		require.Equal(t, expectedErr, err)
+	})
+
+	folders := &fakeFolderService{folders: map[string]*folder.Folder{}}
+	permissions := &fakePermissionsService{permissions: map[string]map[string]string{}}
+
+	var created []string
+	dashboardProvisioningService := dashboards.NewFakeDashboardProvisioning(t)
+	dashboardProvisioningService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).
+		Return(&folder.Folder{}, nil).
+		Run(func(args mock.Arguments) {
+			cmd := args.Get(1).(*folder.CreateFolderCommand)
+			folders.folders[cmd.UID] = &folder.Folder{UID: cmd.UID, OrgID: cmd.OrgID, Title: cmd.Title, Description: cmd.Description, ParentUID: cmd.ParentUID}
+			created = append(created, cmd.UID)
+		})
+
+	reader := &testConfigReader{}
+	fp := FolderProvisioner{
+		log:                          log.New("test"),
+		cfgProvider:                  reader,
+		folderService:                folders,
+		dashboardProvisioningService: dashboardProvisioningService,
+		folderPermissionsService:     permissions,
+		teamService:                  &fakeTeamService{teams: []*team.TeamDTO{{ID: 7, OrgID: 1, Name: "Platform"}}},
+		userService:                  &fakeUserService{users: []*user.User{{ID: 1, Login: "alice"}}},
+		orgService:                   orgtest.NewOrgServiceFake(),
+		provenance:                   utils.NewProvenanceStore(kvstore.NewFakeKVStore(), "folders"),
+	}
+
+	t.Run("Should create folders and set permissions", func(t *testing.T) {
+		reader.result = []*foldersAsConfig{{Folders: []*folderFromConfig{
+			{OrgID: 1, UID: "platform-alerts", Title: "Alerts", ParentUID: "platform", Permissions: []*permissionFromConfig{
+				{User: "alice", Permission: "Admin"},
+			}},
+			{OrgID: 1, UID: "platform", Title: "Platform", DeleteOnRemoval: true, Permissions: []*permissionFromConfig{
+				{Role: "Viewer", Permission: "View"},
+				{Team: "Platform", Permission: "Edit"},
+			}},
+		}}}
+
+		require.NoError(t, fp.applyChanges(context.Background(), ""))
+		require.Equal(t, []string{"platform", "platform-alerts"}, created)
+		require.Equal(t, map[string]string{"role:Viewer": "View", "team:7": "Edit"}, permissions.permissions["platform"])
+		require.Equal(t, map[string]string{"user:1": "Admin"}, permissions.permissions["platform-alerts"])
+	})
+
+	t.Run("Should be idempotent", func(t *testing.T) {
+		require.NoError(t, fp.applyChanges(context.Background(), ""))
+		require.Len(t, created, 2)
+		require.Zero(t, folders.updates)
+	})
+
+	t.Run("Should update, move and revoke provisioned permissions", func(t *testing.T) {
+		permissions.permissions["platform-alerts"]["user:2"] = "View"
+		reader.result = []*foldersAsConfig{{Folders: []*folderFromConfig{
+			{OrgID: 1, UID: "platform-alerts", Title: "Alerts"},
+			{OrgID: 1, UID: "platform", Title: "Platform team", DeleteOnRemoval: true, Permissions: []*permissionFromConfig{
+				{Role: "Viewer", Permission: "View"},
+				{Team: "Platform", Permission: "Edit"},
+			}},
+		}}}
+
+		require.NoError(t, fp.applyChanges(context.Background(), ""))
+		require.Equal(t, "Platform team", folders.folders["platform"].Title)
+		require.Equal(t, "", folders.folders["platform-alerts"].ParentUID)
+		require.Equal(t, 2, folders.updates)
+		require.Equal(t, map[string]string{"user:2": "View"}, permissions.permissions["platform-alerts"])
+	})
+
+	t.Run("Should skip permissions of users and teams that do not exist", func(t *testing.T) {
+		reader.result = []*foldersAsConfig{{Folders: []*folderFromConfig{
+			{OrgID: 1, UID: "platform-alerts", Title: "Alerts", Permissions: []*permissionFromConfig{
+				{User: "dave", Permission: "View"},
+				{Team: "Support", Permission: "View"},
+				{User: "alice", Permission: "Edit"},
+			}},
+			{OrgID: 1, UID: "platform", Title: "Platform team", DeleteOnRemoval: true, Permissions: []*permissionFromConfig{
+				{Role: "Viewer", Permission: "View"},
+				{Team: "Platform", Permission: "Edit"},
+			}},
+		}}}
+
+		require.NoError(t, fp.applyChanges(context.Background(), ""))
+		require.Equal(t, map[string]string{"user:1": "Edit", "user:2": "View"}, permissions.permissions["platform-alerts"])
+		require.Equal(t, map[string]string{"role:Viewer": "View", "team:7": "Edit"}, permissions.permissions["platform"])
+	})
+
+	t.Run("Should only delete folders marked for deletion on removal", func(t *testing.T) {
+		reader.result = nil
+
+		require.NoError(t, fp.applyChanges(context.Background(), ""))
+		require.NotContains(t, folders.folders, "platform")
+		require.Contains(t, folders.folders, "platform-alerts")
+	})
+}
+
+type testConfigReader struct {
+	result []*foldersAsConfig
+	err    error
+}
+
+func (tcr *testConfigReader) readConfig(_ string) ([]*foldersAsConfig, error) {
+	return tcr.result, tcr.err
+}
+
+type fakeFolderService struct {
+	folder.Service
+	folders map[string]*folder.Folder
+	updates int
+}
+
+func (s *fakeFolderService) Get(_ context.Context, q *folder.GetFolderQuery) (*folder.Folder, error) {
+	f, ok := s.folders[*q.UID]
+	if !ok {
+		return nil, dashboards.ErrFolderNotFound
+	}
+	return f, nil
+}
+
+func (s *fakeFolderService) Update(_ context.Context, cmd *folder.UpdateFolderCommand) (*folder.Folder, error) {
+	s.updates++
+	f := s.folders[cmd.UID]
+	f.Title = *cmd.NewTitle
+	f.Description = *cmd.NewDescription
+	return f, nil
+}
+
+func (s *fakeFolderService) Move(_ context.Context, cmd *folder.MoveFolderCommand) (*folder.Folder, error) {
+	s.updates++
+	f := s.folders[cmd.UID]
+	f.ParentUID = cmd.NewParentUID
+	return f, nil
+}
+
+func (s *fakeFolderService) Delete(_ context.Context, cmd *folder.DeleteFolderCommand) error {
+	if _, ok := s.folders[cmd.UID]; !ok {
+		return dashboards.ErrFolderNotFound
+	}
+	delete(s.folders, cmd.UID)
+	return nil
+}
+
+type fakePermissionsService struct {
+	accesscontrol.FolderPermissionsService
+	permissions map[string]map[string]string
+}
+
+func (s *fakePermissionsService) SetPermissions(_ context.Context, _ int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
+	if _, ok := s.permissions[resourceID]; !ok {
+		s.permissions[resourceID] = map[string]string{}
+	}
+	for _, cmd := range commands {
+		key := "role:" + cmd.BuiltinRole
+		if cmd.TeamID != 0 {
+			key = fmt.Sprintf("team:%d", cmd.TeamID)
+		} else if cmd.UserID != 0 {
+			key = fmt.Sprintf("user:%d", cmd.UserID)
+		}
+		if cmd.Permission == "" {
+			delete(s.permissions[resourceID], key)
+			continue
+		}
+		s.permissions[resourceID][key] = cmd.Permission
+	}
+	return nil, nil
+}
+
+type fakeTeamService struct {
+	team.Service
+	teams []*team.TeamDTO
+}
+
+func (s *fakeTeamService) SearchTeams(_ context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{}
+	for _, t := range s.teams {
+		if t.OrgID == query.OrgID && t.Name == query.Name {
+			result.Teams = append(result.Teams, t)
+		}
+	}
+	return result, nil
+}
+
+type fakeUserService struct {
+	user.Service
+	users []*user.User
+}
+
+func (s *fakeUserService) GetByLogin(_ context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
+	for _, u := range s.users {
+		if u.Login == query.LoginOrEmail {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
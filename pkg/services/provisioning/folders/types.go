@@ -0,0 +1,81 @@
+package folders
+
+import "github.com/grafana/grafana/pkg/services/provisioning/values"
+
+// foldersAsConfig is a normalized data object for folders config data. Any config version should be mappable
+// to this type.
+type foldersAsConfig struct {
+	Folders []*folderFromConfig
+}
+
+type folderFromConfig struct {
+	OrgID           int64
+	UID             string
+	Title           string
+	Description     string
+	ParentUID       string
+	DeleteOnRemoval bool
+	Permissions     []*permissionFromConfig
+}
+
+// permissionFromConfig grants a permission on a folder to exactly one of a basic role, a team or a user.
+type permissionFromConfig struct {
+	Role       string
+	Team       string
+	User       string
+	Permission string
+}
+
+// foldersAsConfigV1 is a mapping for version 1 configs. This is mapped to its normalised version.
+type foldersAsConfigV1 struct {
+	APIVersion values.Int64Value     `json:"apiVersion" yaml:"apiVersion"`
+	Folders    []*folderFromConfigV1 `json:"folders" yaml:"folders"`
+}
+
+type folderFromConfigV1 struct {
+	OrgID           values.Int64Value         `json:"orgId" yaml:"orgId"`
+	UID             values.StringValue        `json:"uid" yaml:"uid"`
+	Title           values.StringValue        `json:"title" yaml:"title"`
+	Description     values.StringValue        `json:"description" yaml:"description"`
+	ParentUID       values.StringValue        `json:"parentUid" yaml:"parentUid"`
+	DeleteOnRemoval values.BoolValue          `json:"deleteOnRemoval" yaml:"deleteOnRemoval"`
+	Permissions     []*permissionFromConfigV1 `json:"permissions" yaml:"permissions"`
+}
+
+type permissionFromConfigV1 struct {
+	Role       values.StringValue `json:"role" yaml:"role"`
+	Team       values.StringValue `json:"team" yaml:"team"`
+	User       values.StringValue `json:"user" yaml:"user"`
+	Permission values.StringValue `json:"permission" yaml:"permission"`
+}
+
+// mapToFoldersFromConfig maps config syntax to a normalized foldersAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *foldersAsConfigV1) mapToFoldersFromConfig() *foldersAsConfig {
+	r := &foldersAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, f := range cfg.Folders {
+		folder := &folderFromConfig{
+			OrgID:           f.OrgID.Value(),
+			UID:             f.UID.Value(),
+			Title:           f.Title.Value(),
+			Description:     f.Description.Value(),
+			ParentUID:       f.ParentUID.Value(),
+			DeleteOnRemoval: f.DeleteOnRemoval.Value(),
+		}
+		for _, p := range f.Permissions {
+			folder.Permissions = append(folder.Permissions, &permissionFromConfig{
+				Role:       p.Role.Value(),
+				Team:       p.Team.Value(),
+				User:       p.User.Value(),
+				Permission: p.Permission.Value(),
+			})
+		}
+		r.Folders = append(r.Folders, folder)
+	}
+
+	return r
+}
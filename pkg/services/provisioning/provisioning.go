@@ -3,13 +3,17 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
 	"github.com/grafana/grafana/pkg/services/correlations"
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
@@ -26,10 +30,17 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	prov_folders "github.com/grafana/grafana/pkg/services/provisioning/folders"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
+	prov_roles "github.com/grafana/grafana/pkg/services/provisioning/roles"
+	prov_serviceaccounts "github.com/grafana/grafana/pkg/services/provisioning/serviceaccounts"
+	prov_teams "github.com/grafana/grafana/pkg/services/provisioning/teams"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -50,6 +61,14 @@ func ProvideService(
 	quotaService quota.Service,
 	secrectService secrets.Service,
 	orgService org.Service,
+	acService accesscontrol.Service,
+	teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService,
+	folderPermissionsService accesscontrol.FolderPermissionsService,
+	userService user.Service,
+	serviceAccountsService serviceaccounts.Service,
+	customRolesService customroles.Service,
+	kvStore kvstore.KVStore,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -62,6 +81,10 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		provisionTeams:               prov_teams.Provision,
+		provisionFolders:             prov_folders.Provision,
+		provisionServiceAccounts:     prov_serviceaccounts.Provision,
+		provisionRoles:               prov_roles.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -73,6 +96,14 @@ func ProvideService(
 		log:                          log.New("provisioning"),
 		orgService:                   orgService,
 		folderService:                folderService,
+		acService:                    acService,
+		teamService:                  teamService,
+		teamPermissionsService:       teamPermissionsService,
+		folderPermissionsService:     folderPermissionsService,
+		userService:                  userService,
+		serviceAccountsService:       serviceAccountsService,
+		customRolesService:           customRolesService,
+		kvStore:                      kvStore,
 	}
 
 	err := s.setDashboardProvisioner()
@@ -100,18 +131,29 @@ type ProvisioningService interface {
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	ProvisionTeams(ctx context.Context) error
+	ProvisionFolders(ctx context.Context) error
+	ProvisionServiceAccounts(ctx context.Context) error
+	ProvisionRoles(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	HandleDashboardsWebhook(name string, header http.Header, body []byte) error
+	GetDataSourcesDrift(ctx context.Context) ([]*datasources.DataSourceDrift, error)
+	IsDataSourceProvisioned(orgID int64, name string) bool
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
 func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 	logger := log.New("provisioning")
 	return &ProvisioningServiceImpl{
-		log:                     logger,
-		newDashboardProvisioner: dashboards.New,
-		provisionDatasources:    datasources.Provision,
-		provisionPlugins:        plugins.Provision,
+		log:                      logger,
+		newDashboardProvisioner:  dashboards.New,
+		provisionDatasources:     datasources.Provision,
+		provisionPlugins:         plugins.Provision,
+		provisionTeams:           prov_teams.Provision,
+		provisionFolders:         prov_folders.Provision,
+		provisionServiceAccounts: prov_serviceaccounts.Provision,
+		provisionRoles:           prov_roles.Provision,
 	}
 }
 
@@ -145,6 +187,10 @@ type ProvisioningServiceImpl struct {
 	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	provisionTeams               func(context.Context, string, team.Service, accesscontrol.TeamPermissionsService, accesscontrol.Service, user.Service, org.Service, kvstore.KVStore) error
+	provisionFolders             func(context.Context, string, folder.Service, dashboardservice.DashboardProvisioningService, accesscontrol.FolderPermissionsService, team.Service, user.Service, org.Service, kvstore.KVStore) error
+	provisionServiceAccounts     func(context.Context, string, serviceaccounts.Service, org.Service, kvstore.KVStore) error
+	provisionRoles               func(context.Context, string, customroles.Service, user.Service, team.Service, serviceaccounts.Service, org.Service, kvstore.KVStore) error
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -155,6 +201,17 @@ type ProvisioningServiceImpl struct {
 	quotaService                 quota.Service
 	secretService                secrets.Service
 	folderService                folder.Service
+	acService                    accesscontrol.Service
+	teamService                  team.Service
+	teamPermissionsService       accesscontrol.TeamPermissionsService
+	folderPermissionsService     accesscontrol.FolderPermissionsService
+	userService                  user.Service
+	serviceAccountsService       serviceaccounts.Service
+	customRolesService           customroles.Service
+	kvStore                      kvstore.KVStore
+	// datasourcesMutex serializes the data source provisioning, and protects the provisioned data sources.
+	datasourcesMutex       sync.RWMutex
+	provisionedDataSources map[datasources.DataSourceMapKey]bool
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -170,6 +227,30 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionTeams(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision teams", "error", err)
+		return err
+	}
+
+	err = ps.ProvisionFolders(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision folders", "error", err)
+		return err
+	}
+
+	err = ps.ProvisionServiceAccounts(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision service accounts", "error", err)
+		return err
+	}
+
+	err = ps.ProvisionRoles(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision roles", "error", err)
+		return err
+	}
+
 	err = ps.ProvisionAlerting(ctx)
 	if err != nil {
 		ps.log.Error("Failed to provision alerting", "error", err)
@@ -180,6 +261,10 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
+	if ps.Cfg.DataSourceProvisioning.Watch {
+		go ps.watchDatasources(ctx)
+	}
+
 	err := ps.ProvisionDashboards(ctx)
 	if err != nil {
 		ps.log.Error("Failed to provision dashboard", "error", err)
@@ -212,15 +297,74 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) error {
+	ps.datasourcesMutex.Lock()
+	defer ps.datasourcesMutex.Unlock()
+
 	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
 	if err := ps.provisionDatasources(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService); err != nil {
 		err = fmt.Errorf("%v: %w", "Datasource provisioning error", err)
 		ps.log.Error("Failed to provision data sources", "error", err)
 		return err
 	}
+
+	provisioned, err := datasources.ProvisionedDataSources(ctx, datasourcePath, ps.orgService)
+	if err != nil {
+		ps.log.Error("Failed to read the provisioned data sources", "error", err)
+		return err
+	}
+	ps.provisionedDataSources = provisioned
 	return nil
 }
 
+// watchDatasources provisions the data sources again when their provisioning files change. Every instance applies
+// the files, as they do at startup.
+func (ps *ProvisioningServiceImpl) watchDatasources(ctx context.Context) {
+	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+	checksum, err := datasources.FilesChecksum(datasourcePath)
+	if err != nil {
+		ps.log.Error("Failed to read the data source provisioning files", "path", datasourcePath, "error", err)
+	}
+
+	ticker := time.NewTicker(ps.Cfg.DataSourceProvisioning.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		current, err := datasources.FilesChecksum(datasourcePath)
+		if err != nil {
+			ps.log.Error("Failed to read the data source provisioning files", "path", datasourcePath, "error", err)
+			continue
+		}
+		if current == checksum {
+			continue
+		}
+
+		// The checksum is updated even when the provisioning fails, so that an invalid file is reported once, and
+		// applied again once it is fixed.
+		checksum = current
+		ps.log.Info("Data source provisioning files changed, provisioning the data sources again", "path", datasourcePath)
+		_ = ps.ProvisionDatasources(ctx)
+	}
+}
+
+// GetDataSourcesDrift returns the provisioned data sources that differ from their provisioning files.
+func (ps *ProvisioningServiceImpl) GetDataSourcesDrift(ctx context.Context) ([]*datasources.DataSourceDrift, error) {
+	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+	return datasources.DetectDrift(ctx, datasourcePath, ps.datasourceService, ps.orgService)
+}
+
+// IsDataSourceProvisioned returns whether the data source is in the provisioning files that were applied last.
+func (ps *ProvisioningServiceImpl) IsDataSourceProvisioned(orgID int64, name string) bool {
+	ps.datasourcesMutex.RLock()
+	defer ps.datasourcesMutex.RUnlock()
+
+	return ps.provisionedDataSources[datasources.DataSourceMapKey{Name: name, OrgId: orgID}]
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
 	if err := ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService); err != nil {
@@ -231,6 +375,48 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionTeams(ctx context.Context) error {
+	teamsPath := filepath.Join(ps.Cfg.ProvisioningPath, "teams")
+	if err := ps.provisionTeams(ctx, teamsPath, ps.teamService, ps.teamPermissionsService, ps.acService, ps.userService, ps.orgService, ps.kvStore); err != nil {
+		err = fmt.Errorf("%v: %w", "team provisioning error", err)
+		ps.log.Error("Failed to provision teams", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (ps *ProvisioningServiceImpl) ProvisionFolders(ctx context.Context) error {
+	foldersPath := filepath.Join(ps.Cfg.ProvisioningPath, "folders")
+	if err := ps.provisionFolders(ctx, foldersPath, ps.folderService, ps.dashboardProvisioningService, ps.folderPermissionsService,
+		ps.teamService, ps.userService, ps.orgService, ps.kvStore); err != nil {
+		err = fmt.Errorf("%v: %w", "folder provisioning error", err)
+		ps.log.Error("Failed to provision folders", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (ps *ProvisioningServiceImpl) ProvisionServiceAccounts(ctx context.Context) error {
+	serviceAccountsPath := filepath.Join(ps.Cfg.ProvisioningPath, "serviceaccounts")
+	if err := ps.provisionServiceAccounts(ctx, serviceAccountsPath, ps.serviceAccountsService, ps.orgService, ps.kvStore); err != nil {
+		err = fmt.Errorf("%v: %w", "service account provisioning error", err)
+		ps.log.Error("Failed to provision service accounts", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (ps *ProvisioningServiceImpl) ProvisionRoles(ctx context.Context) error {
+	rolesPath := filepath.Join(ps.Cfg.ProvisioningPath, "access-control")
+	if err := ps.provisionRoles(ctx, rolesPath, ps.customRolesService, ps.userService, ps.teamService,
+		ps.serviceAccountsService, ps.orgService, ps.kvStore); err != nil {
+		err = fmt.Errorf("%v: %w", "role provisioning error", err)
+		ps.log.Error("Failed to provision roles", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
@@ -298,6 +484,14 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// HandleDashboardsWebhook forwards a webhook received from a Git server to the dashboard provisioner with the given name.
+func (ps *ProvisioningServiceImpl) HandleDashboardsWebhook(name string, header http.Header, body []byte) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	return ps.dashboardProvisioner.HandleWebhook(name, header, body)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,11 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -8,8 +13,15 @@ type Calls struct {
 	ProvisionPlugins                    []any
 	ProvisionDashboards                 []any
 	ProvisionAlerting                   []any
+	ProvisionTeams                      []any
+	ProvisionFolders                    []any
+	ProvisionServiceAccounts            []any
+	ProvisionRoles                      []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	HandleDashboardsWebhook             []any
+	GetDataSourcesDrift                 []any
+	IsDataSourceProvisioned             []any
 	Run                                 []any
 }
 
@@ -21,6 +33,9 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	HandleDashboardsWebhookFunc             func(name string, header http.Header, body []byte) error
+	GetDataSourcesDriftFunc                 func(ctx context.Context) ([]*datasources.DataSourceDrift, error)
+	IsDataSourceProvisionedFunc             func(orgID int64, name string) bool
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -67,6 +82,26 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionTeams(ctx context.Context) error {
+	mock.Calls.ProvisionTeams = append(mock.Calls.ProvisionTeams, nil)
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) ProvisionFolders(ctx context.Context) error {
+	mock.Calls.ProvisionFolders = append(mock.Calls.ProvisionFolders, nil)
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) ProvisionServiceAccounts(ctx context.Context) error {
+	mock.Calls.ProvisionServiceAccounts = append(mock.Calls.ProvisionServiceAccounts, nil)
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) ProvisionRoles(ctx context.Context) error {
+	mock.Calls.ProvisionRoles = append(mock.Calls.ProvisionRoles, nil)
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -83,6 +118,30 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) HandleDashboardsWebhook(name string, header http.Header, body []byte) error {
+	mock.Calls.HandleDashboardsWebhook = append(mock.Calls.HandleDashboardsWebhook, name)
+	if mock.HandleDashboardsWebhookFunc != nil {
+		return mock.HandleDashboardsWebhookFunc(name, header, body)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetDataSourcesDrift(ctx context.Context) ([]*datasources.DataSourceDrift, error) {
+	mock.Calls.GetDataSourcesDrift = append(mock.Calls.GetDataSourcesDrift, nil)
+	if mock.GetDataSourcesDriftFunc != nil {
+		return mock.GetDataSourcesDriftFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (mock *ProvisioningServiceMock) IsDataSourceProvisioned(orgID int64, name string) bool {
+	mock.Calls.IsDataSourceProvisioned = append(mock.Calls.IsDataSourceProvisioned, name)
+	if mock.IsDataSourceProvisionedFunc != nil {
+		return mock.IsDataSourceProvisionedFunc(orgID, name)
+	}
+	return false
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -0,0 +1,126 @@
+package roles
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*rolesAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*rolesAsConfig, error) {
+	var configs []*rolesAsConfig
+	cr.log.Debug("Looking for role provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read role provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing role provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseRoleConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating roles")
+	if err := validateRoles(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseRoleConfig(path string, file fs.DirEntry) (*rolesAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *rolesAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToRolesFromConfig(), nil
+}
+
+// validateRoles checks the structure of the configuration, the permissions themselves are validated against the
+// registered actions when the roles are provisioned.
+func validateRoles(configs []*rolesAsConfig) error {
+	seen := map[int64]map[string]bool{}
+	for _, cfg := range configs {
+		for index, r := range cfg.Roles {
+			if r.Name == "" {
+				return fmt.Errorf("role item %d in configuration doesn't contain required field name", index+1)
+			}
+			if !strings.HasPrefix(r.Name, accesscontrol.CustomRolePrefix) {
+				return fmt.Errorf("role %q must be prefixed with %q", r.Name, accesscontrol.CustomRolePrefix)
+			}
+			if r.OrgID < 1 {
+				r.OrgID = 1
+			}
+			if seen[r.OrgID][r.Name] {
+				return fmt.Errorf("role %q in organization %d is provisioned more than once", r.Name, r.OrgID)
+			}
+			if _, ok := seen[r.OrgID]; !ok {
+				seen[r.OrgID] = map[string]bool{}
+			}
+			seen[r.OrgID][r.Name] = true
+
+			for _, p := range r.Permissions {
+				if p.Action == "" {
+					return fmt.Errorf("permission of role %q doesn't contain required field action", r.Name)
+				}
+			}
+
+			for _, a := range r.Assignments {
+				set := 0
+				for _, v := range []string{a.User, a.Team, a.ServiceAccount} {
+					if v != "" {
+						set++
+					}
+				}
+				if set != 1 {
+					return fmt.Errorf("assignment of role %q must contain exactly one of user, team or serviceAccount", r.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package roles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+	invalidAssignment = "./testdata/test-configs/invalid-assignment"
+	duplicateRole     = "./testdata/test-configs/duplicate-role"
+	correctProperties = "./testdata/test-configs/correct-properties"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Assignment with several assignees should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(invalidAssignment)
+		require.EqualError(t, err, `assignment of role "custom:dashboards:reader" must contain exactly one of user, team or serviceAccount`)
+	})
+
+	t.Run("Role provisioned twice should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(duplicateRole)
+		require.EqualError(t, err, `role "custom:dashboards:reader" in organization 1 is provisioned more than once`)
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("ROLE_NAME", "custom:users:reader")
+
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+		require.Len(t, cfg[0].Roles, 2)
+
+		dashboards := cfg[0].Roles[0]
+		require.Equal(t, "dashboards-reader", dashboards.UID)
+		require.Equal(t, "custom:dashboards:reader", dashboards.Name)
+		require.Equal(t, "Dashboards reader", dashboards.DisplayName)
+		require.Equal(t, "Read all dashboards", dashboards.Description)
+		require.Equal(t, "Dashboards", dashboards.Group)
+		require.Equal(t, int64(1), dashboards.OrgID)
+		require.True(t, dashboards.DeleteOnRemoval)
+		require.Equal(t, []*permissionFromConfig{
+			{Action: "dashboards:read", Scope: "dashboards:*"},
+			{Action: "folders:read", Scope: "folders:*"},
+		}, dashboards.Permissions)
+		require.Equal(t, []*assignmentFromConfig{
+			{User: "alice"},
+			{Team: "Platform"},
+			{ServiceAccount: "ci"},
+		}, dashboards.Assignments)
+
+		users := cfg[0].Roles[1]
+		require.Equal(t, "custom:users:reader", users.Name)
+		require.Equal(t, int64(2), users.OrgID)
+		require.False(t, users.DeleteOnRemoval)
+		require.Equal(t, []*permissionFromConfig{{Action: "users:read"}}, users.Permissions)
+		require.Empty(t, users.Assignments)
+	})
+}
@@ -0,0 +1,302 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var provisionerPermissions = []accesscontrol.Permission{
+	{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+}
+
+// Provision scans a directory for provisioning config files
+// and provisions the custom roles and their assignments in those files.
+func Provision(ctx context.Context, configDirectory string, customRolesService customroles.Service, userService user.Service,
+	teamService team.Service, serviceAccountsService serviceaccounts.Service, orgService org.Service, kv kvstore.KVStore) error {
+	logger := log.New("provisioning.roles")
+	rp := RoleProvisioner{
+		log:                    logger,
+		cfgProvider:            newConfigReader(logger),
+		customRolesService:     customRolesService,
+		userService:            userService,
+		teamService:            teamService,
+		serviceAccountsService: serviceAccountsService,
+		orgService:             orgService,
+		provenance:             utils.NewProvenanceStore(kv, "roles"),
+	}
+	return rp.applyChanges(ctx, configDirectory)
+}
+
+// RoleProvisioner is responsible for provisioning custom roles and their assignments based on
+// configuration read by the `configReader`
+type RoleProvisioner struct {
+	log                    log.Logger
+	cfgProvider            configReader
+	customRolesService     customroles.Service
+	userService            user.Service
+	teamService            team.Service
+	serviceAccountsService serviceaccounts.Service
+	orgService             org.Service
+	provenance             *utils.ProvenanceStore
+}
+
+func (rp *RoleProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := rp.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	previous, err := rp.provenance.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := utils.ProvisionedResources{}
+	for _, cfg := range configs {
+		for _, r := range cfg.Roles {
+			if err := utils.CheckOrgExists(ctx, rp.orgService, r.OrgID); err != nil {
+				return err
+			}
+
+			prev, _ := previous.Get(r.OrgID, r.Name)
+			resource, err := rp.apply(ctx, r, prev)
+			if err != nil {
+				return err
+			}
+			current.Set(r.OrgID, r.Name, resource)
+		}
+	}
+
+	if err := rp.removeRoles(ctx, previous, current); err != nil {
+		return err
+	}
+
+	return rp.provenance.Save(ctx, current)
+}
+
+// apply creates or updates the role and adds or removes its provisioned assignments.
+func (rp *RoleProvisioner) apply(ctx context.Context, r *roleFromConfig, prev utils.ProvisionedResource) (utils.ProvisionedResource, error) {
+	resource := utils.ProvisionedResource{DeleteOnRemoval: r.DeleteOnRemoval, Assignments: map[string]string{}}
+
+	permissions := make([]accesscontrol.Permission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		permissions = append(permissions, accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+	}
+
+	existing, err := rp.getRoleByName(ctx, r.OrgID, r.Name)
+	if err != nil {
+		return resource, err
+	}
+
+	var role *accesscontrol.RoleDTO
+	switch {
+	case existing == nil:
+		rp.log.Info("Creating role from configuration", "name", r.Name, "orgId", r.OrgID)
+		role, err = rp.customRolesService.CreateRole(ctx, &customroles.CreateRoleCommand{
+			OrgID:       r.OrgID,
+			UID:         r.UID,
+			Name:        r.Name,
+			DisplayName: r.DisplayName,
+			Description: r.Description,
+			Group:       r.Group,
+			Permissions: permissions,
+		})
+		if err != nil {
+			return resource, fmt.Errorf("failed to create role %q: %w", r.Name, err)
+		}
+	case roleChanged(existing, r, permissions):
+		rp.log.Info("Updating role from configuration", "name", r.Name, "orgId", r.OrgID)
+		role, err = rp.customRolesService.UpdateRole(ctx, &customroles.UpdateRoleCommand{
+			OrgID:       r.OrgID,
+			UID:         existing.UID,
+			Name:        r.Name,
+			DisplayName: r.DisplayName,
+			Description: r.Description,
+			Group:       r.Group,
+			Permissions: permissions,
+		})
+		if err != nil {
+			return resource, fmt.Errorf("failed to update role %q: %w", r.Name, err)
+		}
+	default:
+		role = existing
+	}
+	resource.ID = role.ID
+
+	assignments, err := rp.customRolesService.GetAssignments(ctx, r.OrgID, role.UID)
+	if err != nil {
+		return resource, err
+	}
+	assigned := map[string]bool{}
+	for _, id := range assignments.Users {
+		assigned[assignmentKey(customroles.AssigneeUser, id)] = true
+	}
+	for _, id := range assignments.Teams {
+		assigned[assignmentKey(customroles.AssigneeTeam, id)] = true
+	}
+	for _, id := range assignments.ServiceAccounts {
+		assigned[assignmentKey(customroles.AssigneeServiceAccount, id)] = true
+	}
+
+	for _, a := range r.Assignments {
+		assignee, name, err := rp.getAssignee(ctx, r.OrgID, a)
+		if err != nil {
+			return resource, fmt.Errorf("failed to assign role %q: %w", r.Name, err)
+		}
+
+		key := assignmentKey(assignee.Kind, assignee.ID)
+		resource.Assignments[key] = name
+		if assigned[key] {
+			continue
+		}
+		if err := rp.customRolesService.AddAssignment(ctx, r.OrgID, role.UID, assignee); err != nil {
+			return resource, fmt.Errorf("failed to assign role %q to %s %q: %w", r.Name, assignee.Kind, name, err)
+		}
+	}
+
+	// Only assignments that were added by the provisioner are removed, assignments added in the UI are kept.
+	for key, name := range prev.Assignments {
+		if _, ok := resource.Assignments[key]; ok || !assigned[key] {
+			continue
+		}
+		assignee, ok := parseAssignmentKey(key)
+		if !ok {
+			continue
+		}
+		if err := rp.customRolesService.RemoveAssignment(ctx, r.OrgID, role.UID, assignee); err != nil {
+			return resource, fmt.Errorf("failed to unassign role %q from %s %q: %w", r.Name, assignee.Kind, name, err)
+		}
+	}
+
+	return resource, nil
+}
+
+func (rp *RoleProvisioner) getRoleByName(ctx context.Context, orgID int64, name string) (*accesscontrol.RoleDTO, error) {
+	roles, err := rp.customRolesService.GetRoles(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return nil, nil
+}
+
+// getAssignee resolves the user, team or service account of the assignment, it returns the assignee and its name.
+func (rp *RoleProvisioner) getAssignee(ctx context.Context, orgID int64, a *assignmentFromConfig) (customroles.Assignee, string, error) {
+	switch {
+	case a.User != "":
+		usr, err := rp.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: a.User})
+		if err != nil {
+			return customroles.Assignee{}, a.User, fmt.Errorf("user %q: %w", a.User, err)
+		}
+		return customroles.Assignee{Kind: customroles.AssigneeUser, ID: usr.ID}, a.User, nil
+	case a.Team != "":
+		result, err := rp.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+			OrgID:        orgID,
+			Name:         a.Team,
+			Limit:        1,
+			SignedInUser: accesscontrol.BackgroundUser("role_provisioning", orgID, org.RoleAdmin, provisionerPermissions),
+		})
+		if err != nil {
+			return customroles.Assignee{}, a.Team, fmt.Errorf("team %q: %w", a.Team, err)
+		}
+		if len(result.Teams) == 0 {
+			return customroles.Assignee{}, a.Team, fmt.Errorf("team %q: %w", a.Team, team.ErrTeamNotFound)
+		}
+		return customroles.Assignee{Kind: customroles.AssigneeTeam, ID: result.Teams[0].ID}, a.Team, nil
+	default:
+		id, err := rp.serviceAccountsService.RetrieveServiceAccountIdByName(ctx, orgID, a.ServiceAccount)
+		if err != nil {
+			return customroles.Assignee{}, a.ServiceAccount, fmt.Errorf("service account %q: %w", a.ServiceAccount, err)
+		}
+		return customroles.Assignee{Kind: customroles.AssigneeServiceAccount, ID: id}, a.ServiceAccount, nil
+	}
+}
+
+// removeRoles deletes or releases roles that were provisioned before but are no longer part of the configuration.
+func (rp *RoleProvisioner) removeRoles(ctx context.Context, previous, current utils.ProvisionedResources) error {
+	for orgID, resources := range previous {
+		for name, resource := range resources {
+			if _, ok := current.Get(orgID, name); ok {
+				continue
+			}
+
+			if !resource.DeleteOnRemoval {
+				rp.log.Info("Role removed from configuration, it is no longer provisioned", "name", name, "orgId", orgID)
+				continue
+			}
+
+			role, err := rp.getRoleByName(ctx, orgID, name)
+			if err != nil {
+				return err
+			}
+			if role == nil {
+				continue
+			}
+
+			rp.log.Info("Deleting role removed from configuration", "name", name, "orgId", orgID)
+			if err := rp.customRolesService.DeleteRole(ctx, orgID, role.UID, true); err != nil {
+				if errors.Is(err, customroles.ErrRoleNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to delete role %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// roleChanged returns whether the role differs from its configuration.
+func roleChanged(existing *accesscontrol.RoleDTO, r *roleFromConfig, permissions []accesscontrol.Permission) bool {
+	if existing.DisplayName != r.DisplayName || existing.Description != r.Description || existing.Group != r.Group {
+		return true
+	}
+
+	type key struct{ action, scope string }
+	want := map[key]bool{}
+	for _, p := range permissions {
+		want[key{p.Action, p.Scope}] = true
+	}
+	if len(want) != len(existing.Permissions) {
+		return true
+	}
+	for _, p := range existing.Permissions {
+		if !want[key{p.Action, p.Scope}] {
+			return true
+		}
+	}
+	return false
+}
+
+func assignmentKey(kind customroles.AssigneeKind, id int64) string {
+	return string(kind) + ":" + strconv.FormatInt(id, 10)
+}
+
+func parseAssignmentKey(key string) (customroles.Assignee, bool) {
+	kind, rawID, ok := strings.Cut(key, ":")
+	if !ok {
+		return customroles.Assignee{}, false
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return customroles.Assignee{}, false
+	}
+	return customroles.Assignee{Kind: customroles.AssigneeKind(kind), ID: id}, true
+}
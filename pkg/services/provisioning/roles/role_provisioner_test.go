@@ -0,0 +1,233 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestRoleProvisioner(t *testing.T) {
+	t.Run("Should return error when config reader returns error", func(t *testing.T) {
+		expectedErr := errors.New("test")
+		rp := RoleProvisioner{log: log.New("test"), cfgProvider: &testConfigReader{err: expectedErr}}
+		err := rp.applyChanges(context.Background(), "")
+		require.Equal(t, expectedErr, err)
+	})
+
+	roles := newFakeCustomRolesService()
+	reader := &testConfigReader{}
+	rp := RoleProvisioner{
+		log:                    log.New("test"),
+		cfgProvider:            reader,
+		customRolesService:     roles,
+		userService:            &fakeUserService{users: map[string]int64{"alice": 1, "bob": 2}},
+		teamService:            &fakeTeamService{teams: map[string]int64{"Platform": 10}},
+		serviceAccountsService: &fakeServiceAccountsService{accounts: map[string]int64{"ci": 20}},
+		orgService:             orgtest.NewOrgServiceFake(),
+		provenance:             utils.NewProvenanceStore(kvstore.NewFakeKVStore(), "roles"),
+	}
+
+	reader.result = []*rolesAsConfig{{Roles: []*roleFromConfig{
+		{
+			OrgID:           1,
+			UID:             "dashboards-reader",
+			Name:            "custom:dashboards:reader",
+			DeleteOnRemoval: true,
+			Permissions:     []*permissionFromConfig{{Action: "dashboards:read", Scope: "dashboards:*"}},
+			Assignments:     []*assignmentFromConfig{{User: "alice"}, {Team: "Platform"}, {ServiceAccount: "ci"}},
+		},
+		{OrgID: 1, Name: "custom:users:reader", Permissions: []*permissionFromConfig{{Action: "users:read"}}},
+	}}}
+
+	t.Run("Should create roles and assign them", func(t *testing.T) {
+		require.NoError(t, rp.applyChanges(context.Background(), ""))
+		require.Len(t, roles.roles, 2)
+		require.Equal(t, 2, roles.writes)
+		require.Equal(t, &customroles.RoleAssignments{
+			RoleUID:         "dashboards-reader",
+			Users:           []int64{1},
+			Teams:           []int64{10},
+			ServiceAccounts: []int64{20},
+		}, roles.assignments["dashboards-reader"])
+
+		provisioned, err := rp.provenance.Load(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"user:1": "alice", "team:10": "Platform", "serviceAccount:20": "ci"},
+			provisioned[1]["custom:dashboards:reader"].Assignments)
+	})
+
+	t.Run("Should be idempotent", func(t *testing.T) {
+		require.NoError(t, rp.applyChanges(context.Background(), ""))
+		require.Equal(t, 2, roles.writes)
+	})
+
+	t.Run("Should update roles and only remove provisioned assignments", func(t *testing.T) {
+		// bob was assigned in the UI
+		require.NoError(t, roles.AddAssignment(context.Background(), 1, "dashboards-reader", customroles.Assignee{Kind: customroles.AssigneeUser, ID: 2}))
+
+		dashboards := reader.result[0].Roles[0]
+		dashboards.Permissions = append(dashboards.Permissions, &permissionFromConfig{Action: "folders:read", Scope: "folders:*"})
+		dashboards.Assignments = []*assignmentFromConfig{{Team: "Platform"}}
+
+		require.NoError(t, rp.applyChanges(context.Background(), ""))
+		require.Equal(t, 3, roles.writes)
+		require.Len(t, roles.roles["dashboards-reader"].Permissions, 2)
+		require.Equal(t, &customroles.RoleAssignments{
+			RoleUID:         "dashboards-reader",
+			Users:           []int64{2},
+			Teams:           []int64{10},
+			ServiceAccounts: []int64{},
+		}, roles.assignments["dashboards-reader"])
+	})
+
+	t.Run("Should only delete roles marked for deletion on removal", func(t *testing.T) {
+		reader.result = nil
+
+		require.NoError(t, rp.applyChanges(context.Background(), ""))
+		require.Len(t, roles.roles, 1)
+		for _, role := range roles.roles {
+			require.Equal(t, "custom:users:reader", role.Name)
+		}
+	})
+}
+
+type testConfigReader struct {
+	result []*rolesAsConfig
+	err    error
+}
+
+func (tcr *testConfigReader) readConfig(_ string) ([]*rolesAsConfig, error) {
+	return tcr.result, tcr.err
+}
+
+type fakeCustomRolesService struct {
+	customroles.Service
+	roles       map[string]*accesscontrol.RoleDTO
+	assignments map[string]*customroles.RoleAssignments
+	writes      int
+}
+
+func newFakeCustomRolesService() *fakeCustomRolesService {
+	return &fakeCustomRolesService{
+		roles:       map[string]*accesscontrol.RoleDTO{},
+		assignments: map[string]*customroles.RoleAssignments{},
+	}
+}
+
+func (s *fakeCustomRolesService) GetRoles(_ context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	result := make([]*accesscontrol.RoleDTO, 0, len(s.roles))
+	for _, role := range s.roles {
+		if role.OrgID == orgID {
+			result = append(result, role)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeCustomRolesService) CreateRole(_ context.Context, cmd *customroles.CreateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	s.writes++
+	uid := cmd.UID
+	if uid == "" {
+		uid = cmd.Name
+	}
+	role := &accesscontrol.RoleDTO{ID: int64(len(s.roles) + 1), OrgID: cmd.OrgID, UID: uid, Name: cmd.Name, Permissions: cmd.Permissions}
+	s.roles[uid] = role
+	s.assignments[uid] = &customroles.RoleAssignments{RoleUID: uid, Users: []int64{}, Teams: []int64{}, ServiceAccounts: []int64{}}
+	return role, nil
+}
+
+func (s *fakeCustomRolesService) UpdateRole(_ context.Context, cmd *customroles.UpdateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	s.writes++
+	s.roles[cmd.UID].Permissions = cmd.Permissions
+	return s.roles[cmd.UID], nil
+}
+
+func (s *fakeCustomRolesService) DeleteRole(_ context.Context, _ int64, uid string, _ bool) error {
+	delete(s.roles, uid)
+	delete(s.assignments, uid)
+	return nil
+}
+
+func (s *fakeCustomRolesService) GetAssignments(_ context.Context, _ int64, uid string) (*customroles.RoleAssignments, error) {
+	return s.assignments[uid], nil
+}
+
+func (s *fakeCustomRolesService) AddAssignment(_ context.Context, _ int64, uid string, assignee customroles.Assignee) error {
+	ids := s.assigneeIDs(uid, assignee.Kind)
+	*ids = append(*ids, assignee.ID)
+	return nil
+}
+
+func (s *fakeCustomRolesService) RemoveAssignment(_ context.Context, _ int64, uid string, assignee customroles.Assignee) error {
+	ids := s.assigneeIDs(uid, assignee.Kind)
+	kept := []int64{}
+	for _, id := range *ids {
+		if id != assignee.ID {
+			kept = append(kept, id)
+		}
+	}
+	*ids = kept
+	return nil
+}
+
+func (s *fakeCustomRolesService) assigneeIDs(uid string, kind customroles.AssigneeKind) *[]int64 {
+	switch kind {
+	case customroles.AssigneeTeam:
+		return &s.assignments[uid].Teams
+	case customroles.AssigneeServiceAccount:
+		return &s.assignments[uid].ServiceAccounts
+	default:
+		return &s.assignments[uid].Users
+	}
+}
+
+type fakeUserService struct {
+	user.Service
+	users map[string]int64
+}
+
+func (s *fakeUserService) GetByLogin(_ context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
+	id, ok := s.users[query.LoginOrEmail]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return &user.User{ID: id, Login: query.LoginOrEmail}, nil
+}
+
+type fakeTeamService struct {
+	team.Service
+	teams map[string]int64
+}
+
+func (s *fakeTeamService) SearchTeams(_ context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{Teams: []*team.TeamDTO{}}
+	if id, ok := s.teams[query.Name]; ok {
+		result.Teams = append(result.Teams, &team.TeamDTO{ID: id, OrgID: query.OrgID, Name: query.Name})
+	}
+	return result, nil
+}
+
+type fakeServiceAccountsService struct {
+	serviceaccounts.Service
+	accounts map[string]int64
+}
+
+func (s *fakeServiceAccountsService) RetrieveServiceAccountIdByName(_ context.Context, _ int64, name string) (int64, error) {
+	id, ok := s.accounts[name]
+	if !ok {
+		return 0, serviceaccounts.ErrServiceAccountNotFound.Errorf("service account with name %s not found", name)
+	}
+	return id, nil
+}
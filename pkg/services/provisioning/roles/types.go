@@ -0,0 +1,100 @@
+package roles
+
+import "github.com/grafana/grafana/pkg/services/provisioning/values"
+
+// rolesAsConfig is a normalized data object for custom roles config data. Any config version should be mappable
+// to this type.
+type rolesAsConfig struct {
+	Roles []*roleFromConfig
+}
+
+type roleFromConfig struct {
+	OrgID           int64
+	UID             string
+	Name            string
+	DisplayName     string
+	Description     string
+	Group           string
+	DeleteOnRemoval bool
+	Permissions     []*permissionFromConfig
+	Assignments     []*assignmentFromConfig
+}
+
+type permissionFromConfig struct {
+	Action string
+	Scope  string
+}
+
+// assignmentFromConfig holds exactly one of the user login or email, the team name or the service account name the
+// role is assigned to.
+type assignmentFromConfig struct {
+	User           string
+	Team           string
+	ServiceAccount string
+}
+
+// rolesAsConfigV1 is a mapping for version 1 configs. This is mapped to its normalised version.
+type rolesAsConfigV1 struct {
+	APIVersion values.Int64Value   `json:"apiVersion" yaml:"apiVersion"`
+	Roles      []*roleFromConfigV1 `json:"roles" yaml:"roles"`
+}
+
+type roleFromConfigV1 struct {
+	OrgID           values.Int64Value         `json:"orgId" yaml:"orgId"`
+	UID             values.StringValue        `json:"uid" yaml:"uid"`
+	Name            values.StringValue        `json:"name" yaml:"name"`
+	DisplayName     values.StringValue        `json:"displayName" yaml:"displayName"`
+	Description     values.StringValue        `json:"description" yaml:"description"`
+	Group           values.StringValue        `json:"group" yaml:"group"`
+	DeleteOnRemoval values.BoolValue          `json:"deleteOnRemoval" yaml:"deleteOnRemoval"`
+	Permissions     []*permissionFromConfigV1 `json:"permissions" yaml:"permissions"`
+	Assignments     []*assignmentFromConfigV1 `json:"assignments" yaml:"assignments"`
+}
+
+type permissionFromConfigV1 struct {
+	Action values.StringValue `json:"action" yaml:"action"`
+	Scope  values.StringValue `json:"scope" yaml:"scope"`
+}
+
+type assignmentFromConfigV1 struct {
+	User           values.StringValue `json:"user" yaml:"user"`
+	Team           values.StringValue `json:"team" yaml:"team"`
+	ServiceAccount values.StringValue `json:"serviceAccount" yaml:"serviceAccount"`
+}
+
+// mapToRolesFromConfig maps config syntax to a normalized rolesAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *rolesAsConfigV1) mapToRolesFromConfig() *rolesAsConfig {
+	r := &rolesAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, rl := range cfg.Roles {
+		role := &roleFromConfig{
+			OrgID:           rl.OrgID.Value(),
+			UID:             rl.UID.Value(),
+			Name:            rl.Name.Value(),
+			DisplayName:     rl.DisplayName.Value(),
+			Description:     rl.Description.Value(),
+			Group:           rl.Group.Value(),
+			DeleteOnRemoval: rl.DeleteOnRemoval.Value(),
+		}
+		for _, p := range rl.Permissions {
+			role.Permissions = append(role.Permissions, &permissionFromConfig{
+				Action: p.Action.Value(),
+				Scope:  p.Scope.Value(),
+			})
+		}
+		for _, a := range rl.Assignments {
+			role.Assignments = append(role.Assignments, &assignmentFromConfig{
+				User:           a.User.Value(),
+				Team:           a.Team.Value(),
+				ServiceAccount: a.ServiceAccount.Value(),
+			})
+		}
+		r.Roles = append(r.Roles, role)
+	}
+
+	return r
+}
@@ -0,0 +1,109 @@
+package serviceaccounts
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*serviceAccountsAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*serviceAccountsAsConfig, error) {
+	var configs []*serviceAccountsAsConfig
+	cr.log.Debug("Looking for service account provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read service account provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing service account provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseServiceAccountConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating service accounts")
+	if err := validateServiceAccounts(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseServiceAccountConfig(path string, file fs.DirEntry) (*serviceAccountsAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *serviceAccountsAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToServiceAccountsFromConfig(), nil
+}
+
+func validateServiceAccounts(configs []*serviceAccountsAsConfig) error {
+	seen := map[int64]map[string]bool{}
+	for _, cfg := range configs {
+		for index, sa := range cfg.ServiceAccounts {
+			if sa.Name == "" {
+				return fmt.Errorf("service account item %d in configuration doesn't contain required field name", index+1)
+			}
+			if sa.OrgID < 1 {
+				sa.OrgID = 1
+			}
+			if sa.Role == "" {
+				sa.Role = string(org.RoleViewer)
+			}
+			if !org.RoleType(sa.Role).IsValid() {
+				return fmt.Errorf("invalid role %q for service account %q", sa.Role, sa.Name)
+			}
+			if seen[sa.OrgID][sa.Name] {
+				return fmt.Errorf("service account %q in organization %d is provisioned more than once", sa.Name, sa.OrgID)
+			}
+			if _, ok := seen[sa.OrgID]; !ok {
+				seen[sa.OrgID] = map[string]bool{}
+			}
+			seen[sa.OrgID][sa.Name] = true
+		}
+	}
+
+	return nil
+}
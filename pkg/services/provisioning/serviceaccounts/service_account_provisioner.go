@@ -0,0 +1,136 @@
+package serviceaccounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+// Provision scans a directory for provisioning config files
+// and provisions the service accounts in those files.
+func Provision(ctx context.Context, configDirectory string, serviceAccountsService serviceaccounts.Service, orgService org.Service, kv kvstore.KVStore) error {
+	logger := log.New("provisioning.serviceaccounts")
+	sp := ServiceAccountProvisioner{
+		log:                    logger,
+		cfgProvider:            newConfigReader(logger),
+		serviceAccountsService: serviceAccountsService,
+		orgService:             orgService,
+		provenance:             utils.NewProvenanceStore(kv, "serviceaccounts"),
+	}
+	return sp.applyChanges(ctx, configDirectory)
+}
+
+// ServiceAccountProvisioner is responsible for provisioning service accounts based on
+// configuration read by the `configReader`
+type ServiceAccountProvisioner struct {
+	log                    log.Logger
+	cfgProvider            configReader
+	serviceAccountsService serviceaccounts.Service
+	orgService             org.Service
+	provenance             *utils.ProvenanceStore
+}
+
+func (sp *ServiceAccountProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := sp.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	previous, err := sp.provenance.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := utils.ProvisionedResources{}
+	for _, cfg := range configs {
+		for _, sa := range cfg.ServiceAccounts {
+			if err := utils.CheckOrgExists(ctx, sp.orgService, sa.OrgID); err != nil {
+				return err
+			}
+
+			id, err := sp.apply(ctx, sa)
+			if err != nil {
+				return err
+			}
+			current.Set(sa.OrgID, sa.Name, utils.ProvisionedResource{ID: id, DeleteOnRemoval: sa.DeleteOnRemoval})
+		}
+	}
+
+	if err := sp.removeServiceAccounts(ctx, previous, current); err != nil {
+		return err
+	}
+
+	return sp.provenance.Save(ctx, current)
+}
+
+// apply creates or updates the service account and returns its ID.
+func (sp *ServiceAccountProvisioner) apply(ctx context.Context, sa *serviceAccountFromConfig) (int64, error) {
+	role := org.RoleType(sa.Role)
+
+	id, err := sp.serviceAccountsService.RetrieveServiceAccountIdByName(ctx, sa.OrgID, sa.Name)
+	if err != nil {
+		if !errors.Is(err, serviceaccounts.ErrServiceAccountNotFound) {
+			return 0, err
+		}
+
+		sp.log.Info("Creating service account from configuration", "name", sa.Name, "orgId", sa.OrgID)
+		created, err := sp.serviceAccountsService.CreateServiceAccount(ctx, sa.OrgID, &serviceaccounts.CreateServiceAccountForm{
+			Name:       sa.Name,
+			Role:       &role,
+			IsDisabled: &sa.IsDisabled,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create service account %q: %w", sa.Name, err)
+		}
+		return created.Id, nil
+	}
+
+	existing, err := sp.serviceAccountsService.RetrieveServiceAccount(ctx, sa.OrgID, id)
+	if err != nil {
+		return 0, err
+	}
+	if existing.Role == sa.Role && existing.IsDisabled == sa.IsDisabled {
+		return id, nil
+	}
+
+	sp.log.Info("Updating service account from configuration", "name", sa.Name, "orgId", sa.OrgID)
+	if _, err := sp.serviceAccountsService.UpdateServiceAccount(ctx, sa.OrgID, id, &serviceaccounts.UpdateServiceAccountForm{
+		ServiceAccountID: id,
+		Role:             &role,
+		IsDisabled:       &sa.IsDisabled,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to update service account %q: %w", sa.Name, err)
+	}
+	return id, nil
+}
+
+// removeServiceAccounts deletes or releases service accounts that were provisioned before but are no longer part of
+// the configuration.
+func (sp *ServiceAccountProvisioner) removeServiceAccounts(ctx context.Context, previous, current utils.ProvisionedResources) error {
+	for orgID, resources := range previous {
+		for name, resource := range resources {
+			if _, ok := current.Get(orgID, name); ok {
+				continue
+			}
+
+			if !resource.DeleteOnRemoval {
+				sp.log.Info("Service account removed from configuration, it is no longer provisioned", "name", name, "orgId", orgID)
+				continue
+			}
+
+			sp.log.Info("Deleting service account removed from configuration", "name", name, "orgId", orgID)
+			err := sp.serviceAccountsService.DeleteServiceAccount(ctx, orgID, resource.ID)
+			if err != nil && !errors.Is(err, serviceaccounts.ErrServiceAccountNotFound) {
+				return fmt.Errorf("failed to delete service account %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
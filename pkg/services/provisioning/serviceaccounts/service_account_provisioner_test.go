@@ -0,0 +1,110 @@
+package serviceaccounts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+func TestServiceAccountProvisioner(t *testing.T) {
+	t.Run("Should return error when config reader returns error", func(t *testing.T) {
+		expectedErr := errors.New("test")
+		sp := ServiceAccountProvisioner{log: log.New("test"), cfgProvider: &testConfigReader{err: expectedErr}}
+		err := sp.applyChanges(context.Background(), "")
+		require.Equal(t, expectedErr, err)
+	})
+
+	store := &fakeServiceAccountsService{accounts: map[int64]*serviceaccounts.ServiceAccountProfileDTO{
+		5: {Id: 5, OrgId: 1, Name: "backup", Role: "Admin"},
+	}}
+	reader := &testConfigReader{}
+	sp := ServiceAccountProvisioner{
+		log:                    log.New("test"),
+		cfgProvider:            reader,
+		serviceAccountsService: store,
+		orgService:             orgtest.NewOrgServiceFake(),
+		provenance:             utils.NewProvenanceStore(kvstore.NewFakeKVStore(), "serviceaccounts"),
+	}
+
+	t.Run("Should create and update service accounts", func(t *testing.T) {
+		reader.result = []*serviceAccountsAsConfig{{ServiceAccounts: []*serviceAccountFromConfig{
+			{OrgID: 1, Name: "ci", Role: "Editor", DeleteOnRemoval: true},
+			{OrgID: 1, Name: "backup", Role: "Viewer", IsDisabled: true},
+		}}}
+
+		require.NoError(t, sp.applyChanges(context.Background(), ""))
+		require.Len(t, store.accounts, 2)
+		require.Equal(t, 2, store.writes)
+		require.Equal(t, "Viewer", store.accounts[5].Role)
+		require.True(t, store.accounts[5].IsDisabled)
+	})
+
+	t.Run("Should be idempotent", func(t *testing.T) {
+		require.NoError(t, sp.applyChanges(context.Background(), ""))
+		require.Len(t, store.accounts, 2)
+		require.Equal(t, 2, store.writes)
+	})
+
+	t.Run("Should only delete service accounts marked for deletion on removal", func(t *testing.T) {
+		reader.result = nil
+
+		require.NoError(t, sp.applyChanges(context.Background(), ""))
+		require.Len(t, store.accounts, 1)
+		require.Contains(t, store.accounts, int64(5))
+	})
+}
+
+type testConfigReader struct {
+	result []*serviceAccountsAsConfig
+	err    error
+}
+
+func (tcr *testConfigReader) readConfig(_ string) ([]*serviceAccountsAsConfig, error) {
+	return tcr.result, tcr.err
+}
+
+type fakeServiceAccountsService struct {
+	serviceaccounts.Service
+	accounts map[int64]*serviceaccounts.ServiceAccountProfileDTO
+	writes   int
+}
+
+func (s *fakeServiceAccountsService) RetrieveServiceAccountIdByName(_ context.Context, orgID int64, name string) (int64, error) {
+	for _, sa := range s.accounts {
+		if sa.OrgId == orgID && sa.Name == name {
+			return sa.Id, nil
+		}
+	}
+	return 0, serviceaccounts.ErrServiceAccountNotFound.Errorf("service account with name %s not found", name)
+}
+
+func (s *fakeServiceAccountsService) RetrieveServiceAccount(_ context.Context, _, id int64) (*serviceaccounts.ServiceAccountProfileDTO, error) {
+	return s.accounts[id], nil
+}
+
+func (s *fakeServiceAccountsService) CreateServiceAccount(_ context.Context, orgID int64, form *serviceaccounts.CreateServiceAccountForm) (*serviceaccounts.ServiceAccountDTO, error) {
+	s.writes++
+	id := int64(len(s.accounts) + 100)
+	s.accounts[id] = &serviceaccounts.ServiceAccountProfileDTO{Id: id, OrgId: orgID, Name: form.Name, Role: string(*form.Role), IsDisabled: *form.IsDisabled}
+	return &serviceaccounts.ServiceAccountDTO{Id: id, Name: form.Name}, nil
+}
+
+func (s *fakeServiceAccountsService) UpdateServiceAccount(_ context.Context, _, id int64, form *serviceaccounts.UpdateServiceAccountForm) (*serviceaccounts.ServiceAccountProfileDTO, error) {
+	s.writes++
+	s.accounts[id].Role = string(*form.Role)
+	s.accounts[id].IsDisabled = *form.IsDisabled
+	return s.accounts[id], nil
+}
+
+func (s *fakeServiceAccountsService) DeleteServiceAccount(_ context.Context, _, id int64) error {
+	delete(s.accounts, id)
+	return nil
+}
@@ -0,0 +1,52 @@
+package serviceaccounts
+
+import "github.com/grafana/grafana/pkg/services/provisioning/values"
+
+// serviceAccountsAsConfig is a normalized data object for service accounts config data. Any config version
+// should be mappable to this type.
+type serviceAccountsAsConfig struct {
+	ServiceAccounts []*serviceAccountFromConfig
+}
+
+type serviceAccountFromConfig struct {
+	OrgID           int64
+	Name            string
+	Role            string
+	IsDisabled      bool
+	DeleteOnRemoval bool
+}
+
+// serviceAccountsAsConfigV1 is a mapping for version 1 configs. This is mapped to its normalised version.
+type serviceAccountsAsConfigV1 struct {
+	APIVersion      values.Int64Value             `json:"apiVersion" yaml:"apiVersion"`
+	ServiceAccounts []*serviceAccountFromConfigV1 `json:"serviceAccounts" yaml:"serviceAccounts"`
+}
+
+type serviceAccountFromConfigV1 struct {
+	OrgID           values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name            values.StringValue `json:"name" yaml:"name"`
+	Role            values.StringValue `json:"role" yaml:"role"`
+	IsDisabled      values.BoolValue   `json:"isDisabled" yaml:"isDisabled"`
+	DeleteOnRemoval values.BoolValue   `json:"deleteOnRemoval" yaml:"deleteOnRemoval"`
+}
+
+// mapToServiceAccountsFromConfig maps config syntax to a normalized serviceAccountsAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *serviceAccountsAsConfigV1) mapToServiceAccountsFromConfig() *serviceAccountsAsConfig {
+	r := &serviceAccountsAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, sa := range cfg.ServiceAccounts {
+		r.ServiceAccounts = append(r.ServiceAccounts, &serviceAccountFromConfig{
+			OrgID:           sa.OrgID.Value(),
+			Name:            sa.Name.Value(),
+			Role:            sa.Role.Value(),
+			IsDisabled:      sa.IsDisabled.Value(),
+			DeleteOnRemoval: sa.DeleteOnRemoval.Value(),
+		})
+	}
+
+	return r
+}
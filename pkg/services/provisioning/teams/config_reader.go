@@ -0,0 +1,120 @@
+package teams
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	permissionMember = "Member"
+	permissionAdmin  = "Admin"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*teamsAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*teamsAsConfig, error) {
+	var configs []*teamsAsConfig
+	cr.log.Debug("Looking for team provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read team provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing team provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseTeamConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating teams")
+	if err := validateTeams(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseTeamConfig(path string, file fs.DirEntry) (*teamsAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *teamsAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToTeamsFromConfig(), nil
+}
+
+func validateTeams(configs []*teamsAsConfig) error {
+	seen := map[int64]map[string]bool{}
+	for _, cfg := range configs {
+		for index, t := range cfg.Teams {
+			if t.Name == "" {
+				return fmt.Errorf("team item %d in configuration doesn't contain required field name", index+1)
+			}
+			if t.OrgID < 1 {
+				t.OrgID = 1
+			}
+			if seen[t.OrgID][t.Name] {
+				return fmt.Errorf("team %q in organization %d is provisioned more than once", t.Name, t.OrgID)
+			}
+			if _, ok := seen[t.OrgID]; !ok {
+				seen[t.OrgID] = map[string]bool{}
+			}
+			seen[t.OrgID][t.Name] = true
+
+			for _, m := range t.Members {
+				if m.Login == "" && m.Email == "" {
+					return fmt.Errorf("member of team %q doesn't contain required field login or email", t.Name)
+				}
+				switch m.Permission {
+				case "":
+					m.Permission = permissionMember
+				case permissionMember, permissionAdmin:
+				default:
+					return fmt.Errorf("invalid permission %q for member of team %q, must be %s or %s", m.Permission, t.Name, permissionMember, permissionAdmin)
+				}
+			}
+		}
+	}
+
+	return nil
+}
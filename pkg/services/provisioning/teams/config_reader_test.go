@@ -0,0 +1,70 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+	missingMember     = "./testdata/test-configs/missing-member"
+	duplicateTeam     = "./testdata/test-configs/duplicate-team"
+	correctProperties = "./testdata/test-configs/correct-properties"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Member without login or email should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(missingMember)
+		require.EqualError(t, err, `member of team "Platform" doesn't contain required field login or email`)
+	})
+
+	t.Run("Team provisioned twice should return error", func(t *testing.T) {
+		reader := newConfigReader(log.New("test logger"))
+		_, err := reader.readConfig(duplicateTeam)
+		require.EqualError(t, err, `team "Platform" in organization 1 is provisioned more than once`)
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("TEAM_NAME", "Support")
+
+		reader := newConfigReader(log.New("test logger"))
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+		require.Len(t, cfg[0].Teams, 2)
+
+		platform := cfg[0].Teams[0]
+		require.Equal(t, "Platform", platform.Name)
+		require.Equal(t, "platform@example.com", platform.Email)
+		require.Equal(t, int64(1), platform.OrgID)
+		require.True(t, platform.DeleteOnRemoval)
+		require.Equal(t, []*memberFromConfig{
+			{Login: "alice", Permission: permissionAdmin},
+			{Email: "bob@example.com", Permission: permissionMember},
+		}, platform.Members)
+
+		support := cfg[0].Teams[1]
+		require.Equal(t, "Support", support.Name)
+		require.Equal(t, int64(2), support.OrgID)
+		require.False(t, support.DeleteOnRemoval)
+		require.Empty(t, support.Members)
+	})
+}
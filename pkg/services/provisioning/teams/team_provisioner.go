@@ -0,0 +1,233 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var provisionerPermissions = []accesscontrol.Permission{
+	{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+	{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+}
+
+// Provision scans a directory for provisioning config files
+// and provisions the teams in those files.
+func Provision(ctx context.Context, configDirectory string, teamService team.Service, teamPermissionsService accesscontrol.TeamPermissionsService,
+	acService accesscontrol.Service, userService user.Service, orgService org.Service, kv kvstore.KVStore) error {
+	logger := log.New("provisioning.teams")
+	tp := TeamProvisioner{
+		log:                    logger,
+		cfgProvider:            newConfigReader(logger),
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		acService:              acService,
+		userService:            userService,
+		orgService:             orgService,
+		provenance:             utils.NewProvenanceStore(kv, "teams"),
+	}
+	return tp.applyChanges(ctx, configDirectory)
+}
+
+// TeamProvisioner is responsible for provisioning teams and their members based on
+// configuration read by the `configReader`
+type TeamProvisioner struct {
+	log                    log.Logger
+	cfgProvider            configReader
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	acService              accesscontrol.Service
+	userService            user.Service
+	orgService             org.Service
+	provenance             *utils.ProvenanceStore
+}
+
+func (tp *TeamProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := tp.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	previous, err := tp.provenance.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := utils.ProvisionedResources{}
+	for _, cfg := range configs {
+		for _, t := range cfg.Teams {
+			if err := utils.CheckOrgExists(ctx, tp.orgService, t.OrgID); err != nil {
+				return err
+			}
+
+			prev, _ := previous.Get(t.OrgID, t.Name)
+			resource, err := tp.apply(ctx, t, prev)
+			if err != nil {
+				return err
+			}
+			current.Set(t.OrgID, t.Name, resource)
+		}
+	}
+
+	if err := tp.removeTeams(ctx, previous, current); err != nil {
+		return err
+	}
+
+	return tp.provenance.Save(ctx, current)
+}
+
+// apply creates or updates the team and adds, updates or removes its provisioned members.
+func (tp *TeamProvisioner) apply(ctx context.Context, t *teamFromConfig, prev utils.ProvisionedResource) (utils.ProvisionedResource, error) {
+	resource := utils.ProvisionedResource{DeleteOnRemoval: t.DeleteOnRemoval, Assignments: map[string]string{}}
+	signedInUser := accesscontrol.BackgroundUser("team_provisioning", t.OrgID, org.RoleAdmin, provisionerPermissions)
+
+	result, err := tp.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        t.OrgID,
+		Name:         t.Name,
+		Limit:        1,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return resource, err
+	}
+
+	if len(result.Teams) == 0 {
+		tp.log.Info("Creating team from configuration", "name", t.Name, "orgId", t.OrgID)
+		created, err := tp.teamService.CreateTeam(ctx, t.Name, t.Email, t.OrgID)
+		if err != nil {
+			return resource, fmt.Errorf("failed to create team %q: %w", t.Name, err)
+		}
+		resource.ID = created.ID
+	} else {
+		existing := result.Teams[0]
+		resource.ID = existing.ID
+		if existing.Email != t.Email {
+			tp.log.Info("Updating team from configuration", "name", t.Name, "orgId", t.OrgID)
+			if err := tp.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{
+				ID:    existing.ID,
+				OrgID: t.OrgID,
+				Name:  t.Name,
+				Email: t.Email,
+			}); err != nil {
+				return resource, fmt.Errorf("failed to update team %q: %w", t.Name, err)
+			}
+		}
+	}
+
+	members, err := tp.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+		OrgID:        t.OrgID,
+		TeamID:       resource.ID,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return resource, err
+	}
+	existing := make(map[string]string, len(members))
+	for _, m := range members {
+		permission := permissionMember
+		if m.Permission == dashboardaccess.PERMISSION_ADMIN {
+			permission = permissionAdmin
+		}
+		existing[strconv.FormatInt(m.UserID, 10)] = permission
+	}
+
+	for _, m := range t.Members {
+		usr, err := tp.getUser(ctx, m)
+		if errors.Is(err, user.ErrUserNotFound) {
+			// Users from LDAP or OAuth only exist after their first login, the team is provisioned without them until then.
+			tp.log.Warn("Skipping team member that does not exist", "name", t.Name, "orgId", t.OrgID, "login", m.Login, "email", m.Email)
+			continue
+		}
+		if err != nil {
+			return resource, fmt.Errorf("failed to add member to team %q: %w", t.Name, err)
+		}
+
+		key := strconv.FormatInt(usr.ID, 10)
+		resource.Assignments[key] = m.Permission
+		if existing[key] == m.Permission {
+			continue
+		}
+		if err := tp.setMemberPermission(ctx, t.OrgID, resource.ID, usr.ID, m.Permission); err != nil {
+			return resource, fmt.Errorf("failed to add member %q to team %q: %w", usr.Login, t.Name, err)
+		}
+	}
+
+	// Only members that were added by the provisioner are removed, members added in the UI are kept.
+	for key := range prev.Assignments {
+		if _, ok := resource.Assignments[key]; ok {
+			continue
+		}
+		if _, ok := existing[key]; !ok {
+			continue
+		}
+		userID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := tp.setMemberPermission(ctx, t.OrgID, resource.ID, userID, ""); err != nil {
+			return resource, fmt.Errorf("failed to remove member from team %q: %w", t.Name, err)
+		}
+	}
+
+	return resource, nil
+}
+
+func (tp *TeamProvisioner) getUser(ctx context.Context, m *memberFromConfig) (*user.User, error) {
+	if m.Login != "" {
+		usr, err := tp.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: m.Login})
+		if err != nil {
+			return nil, fmt.Errorf("user with login %q: %w", m.Login, err)
+		}
+		return usr, nil
+	}
+
+	usr, err := tp.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: m.Email})
+	if err != nil {
+		return nil, fmt.Errorf("user with email %q: %w", m.Email, err)
+	}
+	return usr, nil
+}
+
+func (tp *TeamProvisioner) setMemberPermission(ctx context.Context, orgID, teamID, userID int64, permission string) error {
+	_, err := tp.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, strconv.FormatInt(teamID, 10), permission)
+	return err
+}
+
+// removeTeams deletes or releases teams that were provisioned before but are no longer part of the configuration.
+func (tp *TeamProvisioner) removeTeams(ctx context.Context, previous, current utils.ProvisionedResources) error {
+	for orgID, resources := range previous {
+		for name, resource := range resources {
+			if _, ok := current.Get(orgID, name); ok {
+				continue
+			}
+
+			if !resource.DeleteOnRemoval {
+				tp.log.Info("Team removed from configuration, it is no longer provisioned", "name", name, "orgId", orgID)
+				continue
+			}
+
+			tp.log.Info("Deleting team removed from configuration", "name", name, "orgId", orgID)
+			if err := tp.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: orgID, ID: resource.ID}); err != nil {
+				if errors.Is(err, team.ErrTeamNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to delete team %q: %w", name, err)
+			}
+			if err := tp.acService.DeleteTeamPermissions(ctx, orgID, resource.ID); err != nil {
+				return fmt.Errorf("failed to delete permissions of team %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestTeamProvisioner(t *testing.T) {
+	t.Run("Should return error when config reader returns error", func(t *testing.T) {
+		expectedErr := errors.New("test")
+		tp := TeamProvisioner{log: log.New("test"), cfgProvider: &testConfigReader{err: expectedErr}}
+		err := tp.applyChanges(context.Background(), "")
+		require.Equal(t, expectedErr, err)
+	})
+
+	teams := newFakeTeamService()
+	teams.teams[10] = &team.TeamDTO{ID: 10, OrgID: 1, Name: "Support"}
+	teams.members[10] = map[int64]string{3: permissionMember}
+
+	reader := &testConfigReader{}
+	kv := kvstore.NewFakeKVStore()
+	tp := TeamProvisioner{
+		log:                    log.New("test"),
+		cfgProvider:            reader,
+		teamService:            teams,
+		teamPermissionsService: &fakeTeamPermissionsService{teams: teams},
+		acService:              &fakeACService{teams: teams},
+		userService: &fakeUserService{users: []*user.User{
+			{ID: 1, Login: "alice", Email: "alice@example.com"},
+			{ID: 2, Login: "bob", Email: "bob@example.com"},
+			{ID: 3, Login: "carol", Email: "carol@example.com"},
+		}},
+		orgService: orgtest.NewOrgServiceFake(),
+		provenance: utils.NewProvenanceStore(kv, "teams"),
+	}
+
+	platform := func(members ...*memberFromConfig) *teamFromConfig {
+		return &teamFromConfig{OrgID: 1, Name: "Platform", Email: "platform@example.com", DeleteOnRemoval: true, Members: members}
+	}
+	alice := &memberFromConfig{Login: "alice", Permission: permissionAdmin}
+	bob := &memberFromConfig{Email: "bob@example.com", Permission: permissionMember}
+	carol := &memberFromConfig{Login: "carol", Permission: permissionMember}
+
+	t.Run("Should create teams and add members", func(t *testing.T) {
+		reader.result = []*teamsAsConfig{{Teams: []*teamFromConfig{
+			platform(alice, bob),
+			{OrgID: 1, Name: "Support", Members: []*memberFromConfig{carol}},
+		}}}
+
+		require.NoError(t, tp.applyChanges(context.Background(), ""))
+		require.Len(t, teams.teams, 2)
+		platformID := teams.byName("Platform").ID
+		require.Equal(t, map[int64]string{1: permissionAdmin, 2: permissionMember}, teams.members[platformID])
+		require.Equal(t, map[int64]string{3: permissionMember}, teams.members[10])
+		require.Equal(t, 2, teams.permissionUpdates)
+
+		provisioned, err := tp.provenance.Load(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, utils.ProvisionedResource{
+			ID:              platformID,
+			DeleteOnRemoval: true,
+			Assignments:     map[string]string{"1": permissionAdmin, "2": permissionMember},
+		}, provisioned[1]["Platform"])
+	})
+
+	t.Run("Should be idempotent", func(t *testing.T) {
+		require.NoError(t, tp.applyChanges(context.Background(), ""))
+		require.Len(t, teams.teams, 2)
+		require.Equal(t, 2, teams.permissionUpdates)
+	})
+
+	t.Run("Should only remove provisioned members", func(t *testing.T) {
+		platformID := teams.byName("Platform").ID
+		teams.members[platformID][4] = permissionMember
+		reader.result = []*teamsAsConfig{{Teams: []*teamFromConfig{platform(alice)}}}
+
+		require.NoError(t, tp.applyChanges(context.Background(), ""))
+		require.Equal(t, map[int64]string{1: permissionAdmin, 4: permissionMember}, teams.members[platformID])
+	})
+
+	t.Run("Should skip members that do not exist", func(t *testing.T) {
+		platformID := teams.byName("Platform").ID
+		dave := &memberFromConfig{Login: "dave", Permission: permissionMember}
+		reader.result = []*teamsAsConfig{{Teams: []*teamFromConfig{
+			platform(dave, alice, bob),
+			{OrgID: 1, Name: "Support", Members: []*memberFromConfig{carol}},
+		}}}
+
+		require.NoError(t, tp.applyChanges(context.Background(), ""))
+		require.Equal(t, map[int64]string{1: permissionAdmin, 2: permissionMember, 4: permissionMember}, teams.members[platformID])
+
+		provisioned, err := tp.provenance.Load(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"1": permissionAdmin, "2": permissionMember}, provisioned[1]["Platform"].Assignments)
+		require.Contains(t, provisioned[1], "Support")
+	})
+
+	t.Run("Should keep removed teams unless they are deleted on removal", func(t *testing.T) {
+		require.NotNil(t, teams.teams[10])
+		require.Equal(t, map[int64]string{3: permissionMember}, teams.members[10])
+
+		reader.result = nil
+		require.NoError(t, tp.applyChanges(context.Background(), ""))
+		require.Len(t, teams.teams, 1)
+		require.NotNil(t, teams.teams[10])
+
+		_, ok, err := kv.Get(context.Background(), 0, "provisioning", "teams")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
+
+type testConfigReader struct {
+	result []*teamsAsConfig
+	err    error
+}
+
+func (tcr *testConfigReader) readConfig(_ string) ([]*teamsAsConfig, error) {
+	return tcr.result, tcr.err
+}
+
+type fakeTeamService struct {
+	team.Service
+	teams             map[int64]*team.TeamDTO
+	members           map[int64]map[int64]string
+	permissionUpdates int
+}
+
+func newFakeTeamService() *fakeTeamService {
+	return &fakeTeamService{teams: map[int64]*team.TeamDTO{}, members: map[int64]map[int64]string{}}
+}
+
+func (s *fakeTeamService) byName(name string) *team.TeamDTO {
+	for _, t := range s.teams {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *fakeTeamService) CreateTeam(_ context.Context, name, email string, orgID int64) (team.Team, error) {
+	id := int64(len(s.teams) + 100)
+	s.teams[id] = &team.TeamDTO{ID: id, OrgID: orgID, Name: name, Email: email}
+	s.members[id] = map[int64]string{}
+	return team.Team{ID: id, OrgID: orgID, Name: name, Email: email}, nil
+}
+
+func (s *fakeTeamService) UpdateTeam(_ context.Context, cmd *team.UpdateTeamCommand) error {
+	s.teams[cmd.ID].Email = cmd.Email
+	return nil
+}
+
+func (s *fakeTeamService) DeleteTeam(_ context.Context, cmd *team.DeleteTeamCommand) error {
+	if _, ok := s.teams[cmd.ID]; !ok {
+		return team.ErrTeamNotFound
+	}
+	delete(s.teams, cmd.ID)
+	return nil
+}
+
+func (s *fakeTeamService) SearchTeams(_ context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{}
+	if t := s.byName(query.Name); t != nil && t.OrgID == query.OrgID {
+		result.Teams = append(result.Teams, t)
+	}
+	return result, nil
+}
+
+func (s *fakeTeamService) GetTeamMembers(_ context.Context, query *team.GetTeamMembersQuery) ([]*team.TeamMemberDTO, error) {
+	var result []*team.TeamMemberDTO
+	for userID, permission := range s.members[query.TeamID] {
+		m := &team.TeamMemberDTO{OrgID: query.OrgID, TeamID: query.TeamID, UserID: userID}
+		if permission == permissionAdmin {
+			m.Permission = dashboardaccess.PERMISSION_ADMIN
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+type fakeTeamPermissionsService struct {
+	accesscontrol.TeamPermissionsService
+	teams *fakeTeamService
+}
+
+func (s *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, u accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	teamID, err := strconv.ParseInt(resourceID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	s.teams.permissionUpdates++
+	if permission == "" {
+		delete(s.teams.members[teamID], u.ID)
+		return nil, nil
+	}
+	s.teams.members[teamID][u.ID] = permission
+	return &accesscontrol.ResourcePermission{}, nil
+}
+
+type fakeACService struct {
+	actest.FakeService
+	teams *fakeTeamService
+}
+
+func (s *fakeACService) DeleteTeamPermissions(_ context.Context, _, teamID int64) error {
+	delete(s.teams.members, teamID)
+	return nil
+}
+
+type fakeUserService struct {
+	user.Service
+	users []*user.User
+}
+
+func (s *fakeUserService) GetByLogin(_ context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
+	for _, u := range s.users {
+		if u.Login == query.LoginOrEmail {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (s *fakeUserService) GetByEmail(_ context.Context, query *user.GetUserByEmailQuery) (*user.User, error) {
+	for _, u := range s.users {
+		if u.Email == query.Email {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
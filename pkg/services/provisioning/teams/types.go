@@ -0,0 +1,71 @@
+package teams
+
+import "github.com/grafana/grafana/pkg/services/provisioning/values"
+
+// teamsAsConfig is a normalized data object for teams config data. Any config version should be mappable
+// to this type.
+type teamsAsConfig struct {
+	Teams []*teamFromConfig
+}
+
+type teamFromConfig struct {
+	OrgID           int64
+	Name            string
+	Email           string
+	DeleteOnRemoval bool
+	Members         []*memberFromConfig
+}
+
+type memberFromConfig struct {
+	Login      string
+	Email      string
+	Permission string
+}
+
+// teamsAsConfigV1 is a mapping for version 1 configs. This is mapped to its normalised version.
+type teamsAsConfigV1 struct {
+	APIVersion values.Int64Value   `json:"apiVersion" yaml:"apiVersion"`
+	Teams      []*teamFromConfigV1 `json:"teams" yaml:"teams"`
+}
+
+type teamFromConfigV1 struct {
+	OrgID           values.Int64Value     `json:"orgId" yaml:"orgId"`
+	Name            values.StringValue    `json:"name" yaml:"name"`
+	Email           values.StringValue    `json:"email" yaml:"email"`
+	DeleteOnRemoval values.BoolValue      `json:"deleteOnRemoval" yaml:"deleteOnRemoval"`
+	Members         []*memberFromConfigV1 `json:"members" yaml:"members"`
+}
+
+type memberFromConfigV1 struct {
+	Login      values.StringValue `json:"login" yaml:"login"`
+	Email      values.StringValue `json:"email" yaml:"email"`
+	Permission values.StringValue `json:"permission" yaml:"permission"`
+}
+
+// mapToTeamsFromConfig maps config syntax to a normalized teamsAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *teamsAsConfigV1) mapToTeamsFromConfig() *teamsAsConfig {
+	r := &teamsAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, t := range cfg.Teams {
+		team := &teamFromConfig{
+			OrgID:           t.OrgID.Value(),
+			Name:            t.Name.Value(),
+			Email:           t.Email.Value(),
+			DeleteOnRemoval: t.DeleteOnRemoval.Value(),
+		}
+		for _, m := range t.Members {
+			team.Members = append(team.Members, &memberFromConfig{
+				Login:      m.Login.Value(),
+				Email:      m.Email.Value(),
+				Permission: m.Permission.Value(),
+			})
+		}
+		r.Teams = append(r.Teams, team)
+	}
+
+	return r
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const provenanceNamespace = "provisioning"
+
+// ProvisionedResource records a resource created or updated by a provisioner.
+type ProvisionedResource struct {
+	ID int64 `json:"id,omitempty"`
+	// DeleteOnRemoval is true if the resource should be deleted once it is removed from the provisioning files.
+	DeleteOnRemoval bool `json:"deleteOnRemoval,omitempty"`
+	// Assignments holds the members or permissions that were provisioned for the resource, keyed by assignee.
+	Assignments map[string]string `json:"assignments,omitempty"`
+}
+
+// ProvisionedResources maps org ID to the provisioned resources of that org keyed by their identifier.
+type ProvisionedResources map[int64]map[string]ProvisionedResource
+
+// Get returns the resource with the given identifier in the org.
+func (r ProvisionedResources) Get(orgID int64, identifier string) (ProvisionedResource, bool) {
+	res, ok := r[orgID][identifier]
+	return res, ok
+}
+
+// Set records the resource with the given identifier in the org.
+func (r ProvisionedResources) Set(orgID int64, identifier string, resource ProvisionedResource) {
+	if _, ok := r[orgID]; !ok {
+		r[orgID] = map[string]ProvisionedResource{}
+	}
+	r[orgID][identifier] = resource
+}
+
+// ProvenanceStore keeps track of the resources of a kind that were provisioned from files, so that provisioners can
+// tell provisioned resources apart from resources created in the UI and clean up resources removed from the files.
+type ProvenanceStore struct {
+	kv   kvstore.KVStore
+	kind string
+}
+
+func NewProvenanceStore(kv kvstore.KVStore, kind string) *ProvenanceStore {
+	return &ProvenanceStore{kv: kv, kind: kind}
+}
+
+// Load returns all provisioned resources of the store's kind.
+func (s *ProvenanceStore) Load(ctx context.Context) (ProvisionedResources, error) {
+	resources := ProvisionedResources{}
+	value, ok, err := s.kv.Get(ctx, 0, provenanceNamespace, s.kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provisioned %s: %w", s.kind, err)
+	}
+	if !ok {
+		return resources, nil
+	}
+	if err := json.Unmarshal([]byte(value), &resources); err != nil {
+		return nil, fmt.Errorf("failed to load provisioned %s: %w", s.kind, err)
+	}
+	return resources, nil
+}
+
+// Save replaces the provisioned resources of the store's kind.
+func (s *ProvenanceStore) Save(ctx context.Context, resources ProvisionedResources) error {
+	for orgID, r := range resources {
+		if len(r) == 0 {
+			delete(resources, orgID)
+		}
+	}
+	if len(resources) == 0 {
+		return s.kv.Del(ctx, 0, provenanceNamespace, s.kind)
+	}
+
+	value, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, 0, provenanceNamespace, s.kind, string(value))
+}
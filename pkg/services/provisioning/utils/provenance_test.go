@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+func TestProvenanceStore(t *testing.T) {
+	kv := kvstore.NewFakeKVStore()
+	store := NewProvenanceStore(kv, "teams")
+
+	resources, err := store.Load(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, resources)
+
+	resources.Set(1, "Platform", ProvisionedResource{ID: 3, DeleteOnRemoval: true, Assignments: map[string]string{"1": "Admin"}})
+	resources.Set(2, "Support", ProvisionedResource{ID: 4})
+	require.NoError(t, store.Save(context.Background(), resources))
+
+	loaded, err := store.Load(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, resources, loaded)
+
+	res, ok := loaded.Get(1, "Platform")
+	require.True(t, ok)
+	require.Equal(t, int64(3), res.ID)
+	_, ok = loaded.Get(1, "Support")
+	require.False(t, ok)
+
+	other, err := NewProvenanceStore(kv, "folders").Load(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, other)
+
+	require.NoError(t, store.Save(context.Background(), ProvisionedResources{1: {}}))
+	_, ok, err = kv.Get(context.Background(), 0, "provisioning", "teams")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -230,6 +230,30 @@ func TestIntegrationExistsEnabledByAccessToken(t *testing.T) {
 		require.False(t, res)
 	})
 
+	t.Run("ExistsEnabledByAccessToken will return false when the public dashboard is expired", func(t *testing.T) {
+		setup()
+
+		expiresAt := time.Now().Add(-time.Hour)
+		_, err := publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				IsEnabled:    true,
+				Uid:          "abc123",
+				DashboardUid: savedDashboard.UID,
+				OrgId:        savedDashboard.OrgID,
+				CreatedAt:    time.Now(),
+				CreatedBy:    7,
+				AccessToken:  "accessToken",
+				ExpiresAt:    &expiresAt,
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), "accessToken")
+		require.NoError(t, err)
+
+		require.False(t, res)
+	})
+
 	t.Run("ExistsEnabledByAccessToken will return false when no public dashboard has matching access token", func(t *testing.T) {
 		setup()
 
@@ -584,6 +608,7 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		require.NoError(t, err)
 		assert.EqualValues(t, affectedRows, 1)
 
+		expiresAt := time.Now().UTC().Add(time.Hour).Round(time.Second)
 		updatedPublicDashboard := PublicDashboard{
 			Uid:                  pdUid,
 			DashboardUid:         savedDashboard.UID,
@@ -593,6 +618,10 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 			TimeSelectionEnabled: true,
 			Share:                EmailShareType,
 			TimeSettings:         &TimeSettings{From: "now-8", To: "now"},
+			ExpiresAt:            &expiresAt,
+			AllowedCIDRs:         &CIDRList{"10.0.0.0/8"},
+			QueriesPerMinute:     60,
+			MaxConcurrentQueries: 5,
 			UpdatedAt:            time.Now().UTC().Round(time.Second),
 			UpdatedBy:            8,
 		}
@@ -614,6 +643,10 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
 		assert.Equal(t, updatedPublicDashboard.TimeSelectionEnabled, pdRetrieved.TimeSelectionEnabled)
 		assert.Equal(t, updatedPublicDashboard.Share, pdRetrieved.Share)
+		assert.Equal(t, expiresAt, pdRetrieved.ExpiresAt.UTC())
+		assert.Equal(t, updatedPublicDashboard.AllowedCIDRs, pdRetrieved.AllowedCIDRs)
+		assert.Equal(t, updatedPublicDashboard.QueriesPerMinute, pdRetrieved.QueriesPerMinute)
+		assert.Equal(t, updatedPublicDashboard.MaxConcurrentQueries, pdRetrieved.MaxConcurrentQueries)
 
 		// not updated dashboard shouldn't have changed
 		pdNotUpdatedRetrieved, err := publicdashboardStore.FindByDashboardUid(context.Background(), anotherSavedDashboard.OrgID, anotherSavedDashboard.UID)
@@ -35,15 +35,20 @@ func ProvideService(
 }
 
 func (s *Service) registerMetrics(prom prometheus.Registerer) error {
-	err := prom.Register(s.Metrics.PublicDashboardsAmount)
-	var alreadyRegisterErr prometheus.AlreadyRegisteredError
-	if errors.As(err, &alreadyRegisterErr) {
-		if alreadyRegisterErr.ExistingCollector == alreadyRegisterErr.NewCollector {
-			err = nil
+	for _, collector := range []prometheus.Collector{s.Metrics.PublicDashboardsAmount, s.Metrics.QueriesServed, s.Metrics.QueriesRejected} {
+		err := prom.Register(collector)
+		var alreadyRegisterErr prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegisterErr) {
+			if alreadyRegisterErr.ExistingCollector == alreadyRegisterErr.NewCollector {
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
 		}
 	}
 
-	return err
+	return nil
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -8,8 +8,33 @@ const (
 	namespace = "grafana"
 )
 
+// Reasons for which a public dashboard query is rejected
+const (
+	RejectReasonExpired            = "expired"
+	RejectReasonIPNotAllowed       = "ip_not_allowed"
+	RejectReasonRateLimited        = "rate_limited"
+	RejectReasonConcurrencyLimited = "concurrency_limited"
+)
+
+// The query counters are updated by the query path of the public dashboards service, they are shared by all the
+// instances of the metric service.
+var (
+	queriesServed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "public_dashboards_queries_served_total",
+		Help:      "Total number of public dashboard queries served",
+	})
+	queriesRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "public_dashboards_queries_rejected_total",
+		Help:      "Total number of public dashboard queries rejected by the access limits",
+	}, []string{"reason"})
+)
+
 type Metrics struct {
 	PublicDashboardsAmount *prometheus.GaugeVec
+	QueriesServed          prometheus.Counter
+	QueriesRejected        *prometheus.CounterVec
 }
 
 func newMetrics() *Metrics {
@@ -19,5 +44,17 @@ func newMetrics() *Metrics {
 			Name:      "public_dashboards_amount",
 			Help:      "Total amount of public dashboards",
 		}, []string{"is_enabled", "share_type"}),
+		QueriesServed:   queriesServed,
+		QueriesRejected: queriesRejected,
 	}
 }
+
+// RecordQueryServed counts a public dashboard query that was sent to the data sources
+func RecordQueryServed() {
+	queriesServed.Inc()
+}
+
+// RecordQueryRejected counts a public dashboard query that was rejected by the access limits
+func RecordQueryRejected(reason string) {
+	queriesRejected.WithLabelValues(reason).Inc()
+}
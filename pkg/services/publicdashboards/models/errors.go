@@ -20,9 +20,16 @@ var (
 	ErrInvalidMaxDataPoints                = errutil.BadRequest("publicdashboards.maxDataPoints", errutil.WithPublicMessage("maxDataPoints should be greater than 0"))
 	ErrInvalidTimeRange                    = errutil.BadRequest("publicdashboards.invalidTimeRange", errutil.WithPublicMessage("Invalid time range"))
 	ErrInvalidShareType                    = errutil.BadRequest("publicdashboards.invalidShareType", errutil.WithPublicMessage("Invalid share type"))
+	ErrInvalidAllowedCIDR                  = errutil.BadRequest("publicdashboards.invalidAllowedCidr", errutil.WithPublicMessage("Invalid allowed CIDR"))
+	ErrInvalidQueryLimit                   = errutil.BadRequest("publicdashboards.invalidQueryLimit", errutil.WithPublicMessage("Query limits should be greater than or equal to 0"))
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
 
-	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardNotEnabled   = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardExpired      = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Public dashboard expired"))
+	ErrPublicDashboardIPNotAllowed = errutil.Forbidden("publicdashboards.ipNotAllowed", errutil.WithPublicMessage("Public dashboard is not available from this network"))
+
+	ErrPublicDashboardRateLimited        = errutil.TooManyRequests("publicdashboards.rateLimited", errutil.WithPublicMessage("Too many queries for the public dashboard"))
+	ErrPublicDashboardConcurrencyLimited = errutil.TooManyRequests("publicdashboards.concurrencyLimited", errutil.WithPublicMessage("Too many concurrent queries for the public dashboard"))
 )
@@ -54,6 +54,11 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	//access limits
+	ExpiresAt            *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+	AllowedCIDRs         *CIDRList  `json:"allowedCidrs,omitempty" xorm:"allowed_cidrs"`
+	QueriesPerMinute     int        `json:"queriesPerMinute" xorm:"queries_per_minute"`
+	MaxConcurrentQueries int        `json:"maxConcurrentQueries" xorm:"max_concurrent_queries"`
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +68,13 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt is the time the access token stops working, the zero time removes the expiry.
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// AllowedCIDRs are the networks the public dashboard can be accessed from, an empty list allows every network.
+	AllowedCIDRs *[]string `json:"allowedCidrs"`
+	// QueriesPerMinute and MaxConcurrentQueries limit the queries of the access token, 0 means no limit.
+	QueriesPerMinute     *int `json:"queriesPerMinute"`
+	MaxConcurrentQueries *int `json:"maxConcurrentQueries"`
 }
 
 type EmailDTO struct {
@@ -134,6 +146,24 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// CIDRList is the list of networks a public dashboard can be accessed from
+type CIDRList []string
+
+func (l *CIDRList) FromDB(data []byte) error {
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
+func (l *CIDRList) ToDB() ([]byte, error) {
+	if l == nil || len(*l) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
 // DTO for transforming user input in the api
 type SavePublicDashboardDTO struct {
 	Uid             string
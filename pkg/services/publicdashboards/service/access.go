@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+// clientIPFromContext returns the IP address of the client of the request in the context, it is empty when the
+// context has no request. The forwarding headers are only honoured when the request comes from one of the trusted
+// proxies, otherwise any client could claim an allowed IP address.
+func clientIPFromContext(ctx context.Context, trustedProxies []string) string {
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Context == nil || reqCtx.Req == nil {
+		return ""
+	}
+	return clientIP(reqCtx.Req, trustedProxies)
+}
+
+// clientIP returns the IP address of the peer of the request. When the peer is a trusted proxy the X-Forwarded-For
+// header is walked from the right, skipping the trusted proxies, and X-Real-IP is used as a fallback.
+func clientIP(req *http.Request, trustedProxies []string) string {
+	remoteIP := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteIP); err == nil {
+		remoteIP = host
+	}
+	remoteIP = strings.Trim(remoteIP, "[]")
+
+	if len(trustedProxies) == 0 || !isIPAllowed(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	if forwardedFor := req.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.Trim(strings.TrimSpace(hops[i]), "[]")
+			if net.ParseIP(hop) == nil {
+				// a malformed hop can not be trusted, neither can anything on its left
+				return remoteIP
+			}
+			if !isIPAllowed(hop, trustedProxies) {
+				return hop
+			}
+		}
+		return remoteIP
+	}
+
+	if realIP := strings.Trim(strings.TrimSpace(req.Header.Get("X-Real-IP")), "[]"); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return remoteIP
+}
+
+// isIPAllowed checks that the IP address is in one of the networks. An unknown IP address is never allowed.
+func isIPAllowed(clientIP string, cidrs []string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -43,11 +43,13 @@ func newPublicDashboardServiceImpl(
 	return &PublicDashboardServiceImpl{
 		AnnotationsRepo:    annotationsRepo,
 		log:                log.New("test.logger"),
+		cfg:                cfg,
 		intervalCalculator: intervalv2.NewCalculator(),
 		dashboardService:   dashboardService,
 		store:              publicDashboardStore,
 		serviceWrapper:     serviceWrapper,
 		license:            license,
 		features:           featuremgmt.WithFeatures(),
+		queryLimiter:       newQueryLimiter(),
 	}, db, cfg
 }
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// limiterIdleTimeout is the time after which the limits of an access token that is not queried anymore are dropped
+const limiterIdleTimeout = 10 * time.Minute
+
+// queryLimiter enforces the query rate and concurrency limits of the public dashboards. The limits are tracked per
+// access token, in memory, so they apply to each Grafana instance.
+type queryLimiter struct {
+	mu        sync.Mutex
+	tokens    map[string]*tokenLimits
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type tokenLimits struct {
+	queriesPerMinute int
+	rate             *rate.Limiter
+	running          int
+	lastUsed         time.Time
+}
+
+func newQueryLimiter() *queryLimiter {
+	return &queryLimiter{
+		tokens: make(map[string]*tokenLimits),
+		now:    time.Now,
+	}
+}
+
+// acquire reserves a query for the access token of the public dashboard. The returned function must be called once
+// the query is done.
+func (l *queryLimiter) acquire(pubdash *PublicDashboard) (func(), error) {
+	if pubdash.QueriesPerMinute <= 0 && pubdash.MaxConcurrentQueries <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	limits, ok := l.tokens[pubdash.AccessToken]
+	if !ok {
+		limits = &tokenLimits{}
+		l.tokens[pubdash.AccessToken] = limits
+	}
+	limits.lastUsed = now
+
+	if pubdash.MaxConcurrentQueries > 0 && limits.running >= pubdash.MaxConcurrentQueries {
+		return nil, ErrPublicDashboardConcurrencyLimited.Errorf("acquire: %d queries are already running", limits.running)
+	}
+
+	if pubdash.QueriesPerMinute > 0 {
+		// the limits of the public dashboard might have been updated since the last query
+		if limits.rate == nil || limits.queriesPerMinute != pubdash.QueriesPerMinute {
+			limits.queriesPerMinute = pubdash.QueriesPerMinute
+			limits.rate = rate.NewLimiter(rate.Every(time.Minute/time.Duration(pubdash.QueriesPerMinute)), pubdash.QueriesPerMinute)
+		}
+		if !limits.rate.AllowN(now, 1) {
+			return nil, ErrPublicDashboardRateLimited.Errorf("acquire: more than %d queries per minute", pubdash.QueriesPerMinute)
+		}
+	}
+
+	limits.running++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			limits.running--
+		})
+	}, nil
+}
+
+// prune drops the limits of the access tokens that have not been queried for a while. It is called with the lock held.
+func (l *queryLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+
+	for token, limits := range l.tokens {
+		if limits.running == 0 && now.Sub(limits.lastUsed) > limiterIdleTimeout {
+			delete(l.tokens, token)
+		}
+	}
+}
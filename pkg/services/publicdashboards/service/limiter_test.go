@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	newLimiter := func(now *time.Time) *queryLimiter {
+		l := newQueryLimiter()
+		l.now = func() time.Time { return *now }
+		return l
+	}
+
+	t.Run("does not track public dashboards without limits", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+
+		for i := 0; i < 100; i++ {
+			release, err := l.acquire(&PublicDashboard{AccessToken: "token"})
+			require.NoError(t, err)
+			release()
+		}
+		require.Empty(t, l.tokens)
+	})
+
+	t.Run("limits the queries per minute of the access token", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+		pubdash := &PublicDashboard{AccessToken: "token", QueriesPerMinute: 2}
+
+		for i := 0; i < 2; i++ {
+			release, err := l.acquire(pubdash)
+			require.NoError(t, err)
+			release()
+		}
+		_, err := l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrPublicDashboardRateLimited)
+
+		// other access tokens have their own limits
+		release, err := l.acquire(&PublicDashboard{AccessToken: "other", QueriesPerMinute: 2})
+		require.NoError(t, err)
+		release()
+
+		now = now.Add(30 * time.Second)
+		release, err = l.acquire(pubdash)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("limits the concurrent queries of the access token", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+		pubdash := &PublicDashboard{AccessToken: "token", MaxConcurrentQueries: 1}
+
+		release, err := l.acquire(pubdash)
+		require.NoError(t, err)
+
+		_, err = l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrPublicDashboardConcurrencyLimited)
+
+		release()
+		release()
+		require.Equal(t, 0, l.tokens["token"].running)
+
+		release, err = l.acquire(pubdash)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("drops the limits of idle access tokens", func(t *testing.T) {
+		now := time.Now()
+		l := newLimiter(&now)
+
+		release, err := l.acquire(&PublicDashboard{AccessToken: "idle", QueriesPerMinute: 10})
+		require.NoError(t, err)
+		release()
+
+		now = now.Add(limiterIdleTimeout + time.Minute)
+		release, err = l.acquire(&PublicDashboard{AccessToken: "token", QueriesPerMinute: 10})
+		require.NoError(t, err)
+		release()
+
+		require.NotContains(t, l.tokens, "idle")
+		require.Contains(t, l.tokens, "token")
+	})
+}
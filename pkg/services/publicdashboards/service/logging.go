@@ -1,8 +1,11 @@
 package service
 
 import (
+	"errors"
+
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
 )
 
@@ -22,6 +25,26 @@ func LogQueryFailure(datasources []string, log log.Logger, err error) {
 	metrics.MPublicDashboardDatasourceQuerySuccess.WithLabelValues(label, models.QueryFailure).Inc()
 }
 
+// LogQueryRejected records the queries rejected by the access limits of the public dashboard, other errors are ignored
+func LogQueryRejected(err error, log log.Logger) {
+	var reason string
+	switch {
+	case errors.Is(err, models.ErrPublicDashboardExpired):
+		reason = metric.RejectReasonExpired
+	case errors.Is(err, models.ErrPublicDashboardIPNotAllowed):
+		reason = metric.RejectReasonIPNotAllowed
+	case errors.Is(err, models.ErrPublicDashboardRateLimited):
+		reason = metric.RejectReasonRateLimited
+	case errors.Is(err, models.ErrPublicDashboardConcurrencyLimited):
+		reason = metric.RejectReasonConcurrencyLimited
+	default:
+		return
+	}
+
+	log.Warn("Public dashboard query rejected", "reason", reason, "error", err.Error())
+	metric.RecordQueryRejected(reason)
+}
+
 func getLabelName(datasources []string) string {
 	size := len(datasources)
 
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -127,9 +128,17 @@ func (pd *PublicDashboardServiceImpl) GetMetricRequest(ctx context.Context, dash
 func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context, skipDSCache bool, queryDto models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
 	publicDashboard, dashboard, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
 	if err != nil {
+		LogQueryRejected(err, pd.log)
 		return nil, err
 	}
 
+	release, err := pd.queryLimiter.acquire(publicDashboard)
+	if err != nil {
+		LogQueryRejected(err, pd.log)
+		return nil, err
+	}
+	defer release()
+
 	metricReq, err := pd.GetMetricRequest(ctx, dashboard, publicDashboard, panelId, queryDto)
 	if err != nil {
 		return nil, err
@@ -148,6 +157,7 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, err
 	}
 	LogQuerySuccess(reqDatasources, pd.log)
+	metric.RecordQueryServed()
 
 	sanitizeMetadataFromQueryData(res)
 
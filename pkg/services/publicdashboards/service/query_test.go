@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
@@ -26,6 +27,8 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -727,6 +730,45 @@ func TestGetQueryDataResponse(t *testing.T) {
 		resp, _ := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
 		require.NotNil(t, resp)
 	})
+
+	t.Run("Rejects the queries over the rate limit of the access token", func(t *testing.T) {
+		metrics, err := metric.ProvideService(nil, prometheus.NewRegistry())
+		require.NoError(t, err)
+		servedBefore := testutil.ToFloat64(metrics.Metrics.QueriesServed)
+		rejectedBefore := testutil.ToFloat64(metrics.Metrics.QueriesRejected.WithLabelValues(metric.RejectReasonRateLimited))
+
+		customPanels := []any{
+			map[string]any{
+				"id":         1,
+				"datasource": map[string]any{"uid": "ds1"},
+				"targets":    []any{map[string]any{"refId": "A", "datasource": map[string]any{"uid": "ds1"}}},
+			}}
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashWithRateLimit", 1, 0, "", true, []map[string]any{}, customPanels)
+		rateLimitedDashboardService := &dashboards.FakeDashboardService{}
+		rateLimitedDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+		service.dashboardService = rateLimitedDashboardService
+
+		isEnabled, queriesPerMinute := true, 1
+		dto := &SavePublicDashboardDTO{
+			DashboardUid: dashboard.UID,
+			UserId:       7,
+			OrgID:        dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{
+				IsEnabled:        &isEnabled,
+				QueriesPerMinute: &queriesPerMinute,
+			},
+		}
+		pubdashDto, err := service.Create(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+
+		_, err = service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
+		require.NoError(t, err)
+		_, err = service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
+		require.ErrorIs(t, err, ErrPublicDashboardRateLimited)
+
+		assert.Equal(t, servedBefore+1, testutil.ToFloat64(metrics.Metrics.QueriesServed))
+		assert.Equal(t, rejectedBefore+1, testutil.ToFloat64(metrics.Metrics.QueriesRejected.WithLabelValues(metric.RejectReasonRateLimited)))
+	})
 }
 
 func TestFindAnnotations(t *testing.T) {
@@ -42,6 +42,7 @@ type PublicDashboardServiceImpl struct {
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
 	license            licensing.Licensing
+	queryLimiter       *queryLimiter
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -75,6 +76,7 @@ func ProvideService(
 		serviceWrapper:     serviceWrapper,
 		dashboardService:   dashboardService,
 		license:            license,
+		queryLimiter:       newQueryLimiter(),
 	}
 }
 
@@ -166,6 +168,17 @@ func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAc
 		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
 
+	if pubdash.ExpiresAt != nil && !time.Now().Before(*pubdash.ExpiresAt) {
+		return nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard expired at %s accessToken: %s", pubdash.ExpiresAt, accessToken)
+	}
+
+	if pubdash.AllowedCIDRs != nil && len(*pubdash.AllowedCIDRs) > 0 {
+		clientIP := clientIPFromContext(ctx, pd.cfg.PublicDashboardsTrustedProxies)
+		if !isIPAllowed(clientIP, *pubdash.AllowedCIDRs) {
+			return nil, nil, ErrPublicDashboardIPNotAllowed.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: IP %s is not allowed accessToken: %s", clientIP, accessToken)
+		}
+	}
+
 	return pubdash, dash, err
 }
 
@@ -503,6 +516,16 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		share = PublicShareType
 	}
 
+	var allowedCIDRs *CIDRList
+	if dto.PublicDashboard.AllowedCIDRs != nil && len(*dto.PublicDashboard.AllowedCIDRs) > 0 {
+		allowedCIDRs = util.Pointer(CIDRList(*dto.PublicDashboard.AllowedCIDRs))
+	}
+
+	var expiresAt *time.Time
+	if dto.PublicDashboard.ExpiresAt != nil && !dto.PublicDashboard.ExpiresAt.IsZero() {
+		expiresAt = dto.PublicDashboard.ExpiresAt
+	}
+
 	now := time.Now()
 
 	return &PublicDashboard{
@@ -514,6 +537,10 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
 		Share:                share,
+		ExpiresAt:            expiresAt,
+		AllowedCIDRs:         allowedCIDRs,
+		QueriesPerMinute:     returnIntOrDefault(dto.PublicDashboard.QueriesPerMinute, 0),
+		MaxConcurrentQueries: returnIntOrDefault(dto.PublicDashboard.MaxConcurrentQueries, 0),
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
@@ -533,6 +560,24 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	// a zero expiry removes the expiry
+	expiresAt := pd.ExpiresAt
+	if pubdashDTO.ExpiresAt != nil {
+		expiresAt = nil
+		if !pubdashDTO.ExpiresAt.IsZero() {
+			expiresAt = pubdashDTO.ExpiresAt
+		}
+	}
+
+	// an empty list removes the restriction
+	allowedCIDRs := pd.AllowedCIDRs
+	if pubdashDTO.AllowedCIDRs != nil {
+		allowedCIDRs = nil
+		if len(*pubdashDTO.AllowedCIDRs) > 0 {
+			allowedCIDRs = util.Pointer(CIDRList(*pubdashDTO.AllowedCIDRs))
+		}
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
@@ -540,6 +585,10 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		ExpiresAt:            expiresAt,
+		AllowedCIDRs:         allowedCIDRs,
+		QueriesPerMinute:     returnIntOrDefault(pubdashDTO.QueriesPerMinute, pd.QueriesPerMinute),
+		MaxConcurrentQueries: returnIntOrDefault(pubdashDTO.MaxConcurrentQueries, pd.MaxConcurrentQueries),
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
@@ -552,3 +601,11 @@ func returnValueOrDefault(value *bool, defaultValue bool) bool {
 
 	return defaultValue
 }
+
+func returnIntOrDefault(value *int, defaultValue int) int {
+	if value != nil {
+		return *value
+	}
+
+	return defaultValue
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +18,8 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -31,6 +34,7 @@ import (
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var timeSettings = &TimeSettings{From: "now-12h", To: "now"}
@@ -578,6 +582,110 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 	}
 }
 
+func TestGetEnabledPublicDashboardAccessLimits(t *testing.T) {
+	contextWithHeaders := func(remoteAddr string, header http.Header) context.Context {
+		req := &http.Request{RemoteAddr: remoteAddr, Header: header}
+		return ctxkey.Set(context.Background(), &contextmodel.ReqContext{Context: &web.Context{Req: req}})
+	}
+	contextWithRemoteAddr := func(remoteAddr string) context.Context {
+		return contextWithHeaders(remoteAddr, http.Header{})
+	}
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		Name           string
+		Ctx            context.Context
+		TrustedProxies []string
+		Pubdash        *PublicDashboard
+		ErrResp        error
+	}{
+		{
+			Name:    "returns the dashboard before the expiry",
+			Ctx:     context.Background(),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, ExpiresAt: &future},
+		},
+		{
+			Name:    "returns ErrPublicDashboardExpired after the expiry",
+			Ctx:     context.Background(),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, ExpiresAt: &past},
+			ErrResp: ErrPublicDashboardExpired,
+		},
+		{
+			Name:    "returns the dashboard when the client IP is in an allowed network",
+			Ctx:     contextWithRemoteAddr("10.1.2.3:52000"),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"192.168.0.0/16", "10.0.0.0/8"}},
+		},
+		{
+			Name:    "returns the dashboard when the IPv6 client IP is in an allowed network",
+			Ctx:     contextWithRemoteAddr("[2001:db8::1]:52000"),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"2001:db8::/32"}},
+		},
+		{
+			Name:    "returns ErrPublicDashboardIPNotAllowed when the client IP is not in an allowed network",
+			Ctx:     contextWithRemoteAddr("172.16.0.1:52000"),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+			ErrResp: ErrPublicDashboardIPNotAllowed,
+		},
+		{
+			Name:    "returns ErrPublicDashboardIPNotAllowed when the client spoofs X-Real-IP",
+			Ctx:     contextWithHeaders("172.16.0.1:52000", http.Header{"X-Real-Ip": []string{"10.1.2.3"}}),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+			ErrResp: ErrPublicDashboardIPNotAllowed,
+		},
+		{
+			Name:    "returns ErrPublicDashboardIPNotAllowed when the client spoofs X-Forwarded-For",
+			Ctx:     contextWithHeaders("172.16.0.1:52000", http.Header{"X-Forwarded-For": []string{"10.1.2.3"}}),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+			ErrResp: ErrPublicDashboardIPNotAllowed,
+		},
+		{
+			Name:           "returns ErrPublicDashboardIPNotAllowed when the client spoofs X-Forwarded-For behind a trusted proxy",
+			Ctx:            contextWithHeaders("192.168.1.1:52000", http.Header{"X-Forwarded-For": []string{"10.1.2.3, 172.16.0.1"}}),
+			TrustedProxies: []string{"192.168.0.0/16"},
+			Pubdash:        &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+			ErrResp:        ErrPublicDashboardIPNotAllowed,
+		},
+		{
+			Name:           "returns the dashboard when a trusted proxy forwards an allowed client IP",
+			Ctx:            contextWithHeaders("192.168.1.1:52000", http.Header{"X-Forwarded-For": []string{"10.1.2.3"}}),
+			TrustedProxies: []string{"192.168.0.0/16"},
+			Pubdash:        &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+		},
+		{
+			Name:           "returns the dashboard when a trusted proxy sets an allowed X-Real-IP",
+			Ctx:            contextWithHeaders("192.168.1.1:52000", http.Header{"X-Real-Ip": []string{"10.1.2.3"}}),
+			TrustedProxies: []string{"192.168.0.0/16"},
+			Pubdash:        &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+		},
+		{
+			Name:    "returns ErrPublicDashboardIPNotAllowed when the client IP is unknown",
+			Ctx:     context.Background(),
+			Pubdash: &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, AllowedCIDRs: &CIDRList{"10.0.0.0/8"}},
+			ErrResp: ErrPublicDashboardIPNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			fakeStore := &FakePublicDashboardStore{}
+			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.Pubdash, nil)
+			fakeDashboardService := &dashboards.FakeDashboardService{}
+			fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "mydashboard", Data: dashboardData}, nil)
+			service, _, cfg := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
+			cfg.PublicDashboardsTrustedProxies = test.TrustedProxies
+
+			pdc, _, err := service.FindEnabledPublicDashboardAndDashboardByAccessToken(test.Ctx, "abcdToken")
+			if test.ErrResp != nil {
+				require.ErrorIs(t, err, test.ErrResp)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Pubdash, pdc)
+		})
+	}
+}
+
 // We're using sqlite here because testing all of the behaviors with mocks in
 // the correct order is convoluted.
 func TestCreatePublicDashboard(t *testing.T) {
@@ -1057,6 +1165,59 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, &TimeSettings{}, updatedPubdash.TimeSettings)
 	})
 
+	t.Run("Updating access limits", func(t *testing.T) {
+		isEnabled, queriesPerMinute := true, 60
+		expiresAt := time.Now().Add(time.Hour).UTC().Round(time.Second)
+		limitedDashboard := insertTestDashboard(t, dashboardStore, "testLimitedDashie", 1, 0, "", true, []map[string]any{}, nil)
+
+		dto := &SavePublicDashboardDTO{
+			DashboardUid: limitedDashboard.UID,
+			UserId:       7,
+			PublicDashboard: &PublicDashboardDTO{
+				IsEnabled:        &isEnabled,
+				ExpiresAt:        &expiresAt,
+				AllowedCIDRs:     &[]string{"10.0.0.0/8"},
+				QueriesPerMinute: &queriesPerMinute,
+			},
+		}
+
+		savedPubdash, err := service.Create(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Equal(t, expiresAt, savedPubdash.ExpiresAt.UTC())
+		assert.Equal(t, &CIDRList{"10.0.0.0/8"}, savedPubdash.AllowedCIDRs)
+		assert.Equal(t, queriesPerMinute, savedPubdash.QueriesPerMinute)
+
+		// omitted limits are kept
+		maxConcurrentQueries := 2
+		dto = &SavePublicDashboardDTO{
+			Uid:          savedPubdash.Uid,
+			DashboardUid: limitedDashboard.UID,
+			UserId:       8,
+			PublicDashboard: &PublicDashboardDTO{
+				MaxConcurrentQueries: &maxConcurrentQueries,
+			},
+		}
+
+		updatedPubdash, err := service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Equal(t, expiresAt, updatedPubdash.ExpiresAt.UTC())
+		assert.Equal(t, &CIDRList{"10.0.0.0/8"}, updatedPubdash.AllowedCIDRs)
+		assert.Equal(t, queriesPerMinute, updatedPubdash.QueriesPerMinute)
+		assert.Equal(t, maxConcurrentQueries, updatedPubdash.MaxConcurrentQueries)
+
+		// the zero time and an empty list remove the limits
+		dto.PublicDashboard = &PublicDashboardDTO{
+			ExpiresAt:    &time.Time{},
+			AllowedCIDRs: &[]string{},
+		}
+
+		updatedPubdash, err = service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Nil(t, updatedPubdash.ExpiresAt)
+		assert.Nil(t, updatedPubdash.AllowedCIDRs)
+		assert.Equal(t, maxConcurrentQueries, updatedPubdash.MaxConcurrentQueries)
+	})
+
 	t.Run("Should fail when public dashboard uid does not match dashboard uid", func(t *testing.T) {
 		isEnabled := true
 
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"net"
+
 	"github.com/google/uuid"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
@@ -13,9 +15,25 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if dto.PublicDashboard.AllowedCIDRs != nil {
+		for _, cidr := range *dto.PublicDashboard.AllowedCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return ErrInvalidAllowedCIDR.Errorf("ValidateSavePublicDashboard: invalid allowed CIDR %q", cidr)
+			}
+		}
+	}
+
+	if isNegative(dto.PublicDashboard.QueriesPerMinute) || isNegative(dto.PublicDashboard.MaxConcurrentQueries) {
+		return ErrInvalidQueryLimit.Errorf("ValidateSavePublicDashboard: query limits should be greater than or equal to 0")
+	}
+
 	return nil
 }
 
+func isNegative(value *int) bool {
+	return value != nil && *value < 0
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *PublicDashboard) error {
 	if req.IntervalMs < 0 {
 		return ErrInvalidInterval.Errorf("ValidateQueryPublicDashboardRequest: intervalMS should be greater than 0")
@@ -22,6 +22,28 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns no error when allowed CIDRs are valid", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{AllowedCIDRs: &[]string{"10.0.0.0/8", "2001:db8::/32"}}}
+
+		err := ValidatePublicDashboard(dto)
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns error when an allowed CIDR is invalid", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{AllowedCIDRs: &[]string{"10.0.0.1"}}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidAllowedCIDR)
+	})
+
+	t.Run("Returns error when a query limit is negative", func(t *testing.T) {
+		limit := -1
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{MaxConcurrentQueries: &limit}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidQueryLimit)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
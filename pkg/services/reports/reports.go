@@ -0,0 +1,138 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	ActionRead   = "reports:read"
+	ActionCreate = "reports:create"
+	ActionWrite  = "reports:write"
+	ActionDelete = "reports:delete"
+	ActionSend   = "reports:send"
+)
+
+var (
+	ScopeAll      = "reports:*"
+	ScopeProvider = accesscontrol.NewScopeProvider("reports")
+)
+
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatPDF Format = "pdf"
+)
+
+type RunStatus string
+
+const (
+	RunStatusSuccess RunStatus = "success"
+	RunStatusFailed  RunStatus = "failed"
+)
+
+type RunTrigger string
+
+const (
+	RunTriggerSchedule RunTrigger = "schedule"
+	RunTriggerManual   RunTrigger = "manual"
+)
+
+const invalidReportMessage = "Invalid report: {{ .Public.reason }}"
+
+var (
+	ErrReportNotFound = errutil.NotFound("reports.notFound", errutil.WithPublicMessage("Report not found"))
+	ErrInvalidReport  = errutil.BadRequest("reports.invalid").
+				MustTemplate(invalidReportMessage, errutil.WithPublic(invalidReportMessage))
+)
+
+// ErrInvalidReportReason returns an ErrInvalidReport error explaining why the report is invalid.
+func ErrInvalidReportReason(reason string) error {
+	return ErrInvalidReport.Build(errutil.TemplateData{Public: map[string]any{"reason": reason}})
+}
+
+// Service manages the reports of the organizations: dashboards rendered on a schedule and delivered by email or
+// webhook.
+type Service interface {
+	GetReports(ctx context.Context, orgID int64) ([]*Report, error)
+	GetReport(ctx context.Context, orgID int64, uid string) (*Report, error)
+	CreateReport(ctx context.Context, cmd *CreateReportCommand) (*Report, error)
+	UpdateReport(ctx context.Context, cmd *UpdateReportCommand) (*Report, error)
+	DeleteReport(ctx context.Context, orgID int64, uid string) error
+
+	// SendReport renders and delivers the report right away, regardless of its schedule.
+	SendReport(ctx context.Context, orgID int64, uid string) (*Run, error)
+	// GetRuns returns the latest runs of the report, most recent first.
+	GetRuns(ctx context.Context, orgID int64, uid string, limit int) ([]*Run, error)
+}
+
+// Report is a dashboard rendered on a schedule and delivered to a list of recipients.
+type Report struct {
+	ID           int64  `json:"id"`
+	UID          string `json:"uid"`
+	OrgID        int64  `json:"orgId"`
+	Name         string `json:"name"`
+	DashboardUID string `json:"dashboardUid"`
+	ReportOptions
+	// CreatedBy is the user that created or last updated the report. The dashboard is rendered as them, the report
+	// fails when they lose access to the dashboard.
+	CreatedBy int64      `json:"createdBy"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	NextRun   *time.Time `json:"nextRun,omitempty"`
+}
+
+// ReportOptions are the options of a report that can be changed.
+type ReportOptions struct {
+	// Variables are the values of the template variables of the dashboard, by variable name.
+	Variables map[string][]string `json:"variables,omitempty"`
+	TimeRange TimeRange           `json:"timeRange"`
+	// Schedule is a cron expression, for example 0 8 * * 1 every Monday at 8:00.
+	Schedule string `json:"schedule"`
+	// Timezone of the schedule and the rendered dashboard, defaults to UTC.
+	Timezone   string   `json:"timezone,omitempty"`
+	Format     Format   `json:"format"`
+	Recipients []string `json:"recipients,omitempty"`
+	WebhookURL string   `json:"webhookUrl,omitempty"`
+	Enabled    bool     `json:"enabled"`
+}
+
+// TimeRange of the rendered dashboard, for example now-7d and now.
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type CreateReportCommand struct {
+	OrgID     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+	// UID is generated when empty.
+	UID          string `json:"uid"`
+	Name         string `json:"name"`
+	DashboardUID string `json:"dashboardUid"`
+	ReportOptions
+}
+
+type UpdateReportCommand struct {
+	OrgID int64  `json:"-"`
+	UID   string `json:"-"`
+	// UpdatedBy becomes the owner of the report, unless it is 0.
+	UpdatedBy int64  `json:"-"`
+	Name      string `json:"name"`
+	ReportOptions
+}
+
+// Run is an execution of a report.
+type Run struct {
+	ID       int64      `json:"id"`
+	ReportID int64      `json:"reportId"`
+	Trigger  RunTrigger `json:"trigger"`
+	Status   RunStatus  `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished time.Time  `json:"finished"`
+}
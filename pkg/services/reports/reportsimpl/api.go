@@ -0,0 +1,148 @@
+package reportsimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := reports.ScopeProvider.GetResourceScopeUID(ac.Parameter(":reportUID"))
+
+	s.routeRegister.Group("/api/reports", func(r routing.RouteRegister) {
+		r.Get("/", authorize(ac.EvalPermission(reports.ActionRead)), routing.Wrap(s.listReportsHandler))
+		r.Post("/", authorize(ac.EvalPermission(reports.ActionCreate)), routing.Wrap(s.createReportHandler))
+		r.Get("/:reportUID", authorize(ac.EvalPermission(reports.ActionRead, uidScope)), routing.Wrap(s.getReportHandler))
+		r.Put("/:reportUID", authorize(ac.EvalPermission(reports.ActionWrite, uidScope)), routing.Wrap(s.updateReportHandler))
+		r.Delete("/:reportUID", authorize(ac.EvalPermission(reports.ActionDelete, uidScope)), routing.Wrap(s.deleteReportHandler))
+		r.Post("/:reportUID/send", authorize(ac.EvalPermission(reports.ActionSend, uidScope)), routing.Wrap(s.sendReportHandler))
+		r.Get("/:reportUID/runs", authorize(ac.EvalPermission(reports.ActionRead, uidScope)), routing.Wrap(s.getRunsHandler))
+	}, requestmeta.SetOwner(requestmeta.TeamBackend))
+}
+
+// GET /api/reports
+func (s *Service) listReportsHandler(c *contextmodel.ReqContext) response.Response {
+	list, err := s.GetReports(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+
+	// Only return the reports the signed in user can read
+	filtered := make([]*reports.Report, 0, len(list))
+	for _, report := range list {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(reports.ActionRead, reports.ScopeProvider.GetResourceScopeUID(report.UID)))
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list reports", err)
+		}
+		if ok {
+			filtered = append(filtered, report)
+		}
+	}
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// GET /api/reports/:reportUID
+func (s *Service) getReportHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.GetReport(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":reportUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// POST /api/reports
+func (s *Service) createReportHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := reports.CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if c.SignedInUser.UserID == 0 {
+		return response.Error(http.StatusBadRequest, "Reports can only be created by users and service accounts", nil)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.CreatedBy = c.SignedInUser.UserID
+
+	// The dashboard is rendered as the creator of the report, they must be able to read it.
+	ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(cmd.DashboardUID)))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create report", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "You cannot create a report of a dashboard you cannot read", nil)
+	}
+
+	report, err := s.CreateReport(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create report", err)
+	}
+	return response.JSON(http.StatusCreated, report)
+}
+
+// PUT /api/reports/:reportUID
+func (s *Service) updateReportHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := reports.UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if c.SignedInUser.UserID == 0 {
+		return response.Error(http.StatusBadRequest, "Reports can only be updated by users and service accounts", nil)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":reportUID"]
+	cmd.UpdatedBy = c.SignedInUser.UserID
+
+	existing, err := s.GetReport(c.Req.Context(), cmd.OrgID, cmd.UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+
+	// The user that updates the report becomes its owner and the dashboard is rendered as them, they must be able
+	// to read it.
+	ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(existing.DashboardUID)))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "You cannot update a report of a dashboard you cannot read", nil)
+	}
+
+	report, err := s.UpdateReport(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// DELETE /api/reports/:reportUID
+func (s *Service) deleteReportHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteReport(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":reportUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete report", err)
+	}
+	return response.Success("Report deleted")
+}
+
+// POST /api/reports/:reportUID/send
+func (s *Service) sendReportHandler(c *contextmodel.ReqContext) response.Response {
+	run, err := s.SendReport(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":reportUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to send report", err)
+	}
+	return response.JSON(http.StatusOK, run)
+}
+
+// GET /api/reports/:reportUID/runs
+func (s *Service) getRunsHandler(c *contextmodel.ReqContext) response.Response {
+	runs, err := s.GetRuns(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":reportUID"], c.QueryInt("limit"))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report runs", err)
+	}
+	return response.JSON(http.StatusOK, runs)
+}
@@ -0,0 +1,74 @@
+package reportsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestIntegrationAPI_UpdateReport(t *testing.T) {
+	s, _ := setupService(t, &notifications.NotificationServiceMock{})
+	s.accessControl = acimpl.ProvideAccessControl(setting.NewCfg())
+	s.routeRegister = routing.NewRouteRegister()
+	s.registerAPIEndpoints()
+	server := webtest.NewServer(t, s.routeRegister)
+
+	// The report is created by the user 2
+	report, err := s.CreateReport(context.Background(), validCommand())
+	require.NoError(t, err)
+
+	update := func(t *testing.T, permissions map[string][]string) *http.Response {
+		t.Helper()
+		body := `{"name": "Daily overview", "schedule": "0 8 * * 1", "format": "png", "webhookUrl": "https://example.com/hook", "enabled": true}`
+		req := server.NewRequest(http.MethodPut, "/api/reports/"+report.UID, strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+			UserID:      3,
+			OrgID:       1,
+			OrgRole:     org.RoleEditor,
+			Permissions: map[int64]map[string][]string{1: permissions},
+		})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("should forbid a user that cannot read the dashboard to update the report of another user", func(t *testing.T) {
+		res := update(t, map[string][]string{reports.ActionWrite: {reports.ScopeAll}})
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		stored, err := s.GetReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, "Weekly overview", stored.Name)
+		assert.Empty(t, stored.WebhookURL)
+		assert.Equal(t, int64(2), stored.CreatedBy)
+	})
+
+	t.Run("should make a user that can read the dashboard the owner of the report of another user", func(t *testing.T) {
+		res := update(t, map[string][]string{
+			reports.ActionWrite:             {reports.ScopeAll},
+			dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")},
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var updated reports.Report
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&updated))
+		assert.Equal(t, "Daily overview", updated.Name)
+		assert.Equal(t, int64(3), updated.CreatedBy)
+	})
+}
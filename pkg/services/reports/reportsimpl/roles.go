@@ -0,0 +1,40 @@
+package reportsimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/reports"
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:reports:reader",
+			DisplayName: "Reports reader",
+			Description: "Read the reports of the organization and their run history.",
+			Group:       "Reports",
+			Permissions: []accesscontrol.Permission{
+				{Action: reports.ActionRead, Scope: reports.ScopeAll},
+			},
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:reports:writer",
+			DisplayName: "Reports writer",
+			Description: "Create, update, delete and send the reports of the organization.",
+			Group:       "Reports",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: reports.ActionCreate},
+				{Action: reports.ActionWrite, Scope: reports.ScopeAll},
+				{Action: reports.ActionDelete, Scope: reports.ScopeAll},
+				{Action: reports.ActionSend, Scope: reports.ScopeAll},
+			}),
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reader, writer)
+}
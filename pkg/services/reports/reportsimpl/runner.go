@@ -0,0 +1,235 @@
+package reportsimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const emailTemplate = "report"
+
+// rendered is a report rendered to a file.
+type rendered struct {
+	dashboard *dashboards.Dashboard
+	// dashboardURL is the URL of the dashboard with the time range and variables of the report.
+	dashboardURL string
+	fileName     string
+	contentType  string
+	content      []byte
+}
+
+// webhookPayload is the body of the request sent to the webhook of a report.
+type webhookPayload struct {
+	ReportUID      string            `json:"reportUid"`
+	ReportName     string            `json:"reportName"`
+	DashboardUID   string            `json:"dashboardUid"`
+	DashboardTitle string            `json:"dashboardTitle"`
+	DashboardURL   string            `json:"dashboardUrl"`
+	TimeRange      reports.TimeRange `json:"timeRange"`
+	FileName       string            `json:"fileName"`
+	ContentType    string            `json:"contentType"`
+	// Content is the base64 encoded file.
+	Content string `json:"content"`
+}
+
+// run renders and delivers the report, and records the run in its history.
+func (s *Service) run(ctx context.Context, report *reports.Report, trigger reports.RunTrigger) *reports.Run {
+	run := &reports.Run{
+		ReportID: report.ID,
+		Trigger:  trigger,
+		Status:   reports.RunStatusSuccess,
+		Started:  s.now(),
+	}
+
+	logger := s.log.New("uid", report.UID, "orgId", report.OrgID, "trigger", trigger)
+	if err := s.send(ctx, report); err != nil {
+		logger.Error("Failed to send report", "error", err)
+		run.Status = reports.RunStatusFailed
+		run.Error = err.Error()
+	} else {
+		logger.Info("Report sent", "recipients", len(report.Recipients), "webhook", report.WebhookURL != "")
+	}
+	run.Finished = s.now()
+
+	if err := s.store.createRun(ctx, report.OrgID, run); err != nil {
+		logger.Error("Failed to record the report run", "error", err)
+	}
+	return run
+}
+
+func (s *Service) send(ctx context.Context, report *reports.Report) error {
+	r, err := s.render(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	// Both deliveries are attempted even if one of them fails.
+	var errs []error
+	if len(report.Recipients) > 0 {
+		if err := s.sendEmail(ctx, report, r); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send email: %w", err))
+		}
+	}
+	if report.WebhookURL != "" {
+		if err := s.sendWebhook(ctx, report, r); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// render renders the dashboard as the user who created the report, so that the report only contains what they can see.
+func (s *Service) render(ctx context.Context, report *reports.Report) (*rendered, error) {
+	if !s.renderService.IsAvailable(ctx) {
+		return nil, rendering.ErrRenderUnavailable
+	}
+
+	owner, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.CreatedBy, OrgID: report.OrgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the user who created the report: %w", err)
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: report.OrgID, UID: report.DashboardUID})
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardPath := path.Join("d", dash.UID, dash.Slug)
+	query := dashboardQuery(report)
+
+	renderType := rendering.RenderPNG
+	if report.Format == reports.FormatPDF {
+		renderType = rendering.RenderPDF
+	}
+
+	renderQuery := url.Values{}
+	for k, v := range query {
+		renderQuery[k] = v
+	}
+	renderQuery.Set("kiosk", "true")
+
+	result, err := s.renderService.Render(ctx, renderType, rendering.Opts{
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   report.OrgID,
+			UserID:  owner.UserID,
+			OrgRole: owner.OrgRole,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: s.cfg.Reports.RenderTimeout,
+		},
+		Width:           s.cfg.Reports.RenderWidth,
+		Height:          s.cfg.Reports.RenderHeight,
+		Timezone:        report.Timezone,
+		ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+		Theme:           models.ThemeLight,
+		Path:            dashboardPath + "?" + renderQuery.Encode(),
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the dashboard: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(result.FilePath); err != nil {
+			s.log.Warn("Failed to remove rendered report", "path", result.FilePath, "error", err)
+		}
+	}()
+
+	content, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the rendered dashboard: %w", err)
+	}
+
+	contentType := "image/png"
+	if report.Format == reports.FormatPDF {
+		contentType = "application/pdf"
+	}
+
+	return &rendered{
+		dashboard:    dash,
+		dashboardURL: strings.TrimSuffix(s.cfg.AppURL, "/") + "/" + dashboardPath + "?" + query.Encode(),
+		fileName:     fmt.Sprintf("%s.%s", slugify.Slugify(report.Name), report.Format),
+		contentType:  contentType,
+		content:      content,
+	}, nil
+}
+
+func (s *Service) sendEmail(ctx context.Context, report *reports.Report, r *rendered) error {
+	return s.notificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       report.Recipients,
+			Template: emailTemplate,
+			Data: map[string]any{
+				"ReportName":     report.Name,
+				"DashboardTitle": r.dashboard.Title,
+				"DashboardURL":   r.dashboardURL,
+				"TimeFrom":       report.TimeRange.From,
+				"TimeTo":         report.TimeRange.To,
+			},
+			AttachedFiles: []*notifications.SendEmailAttachFile{
+				{Name: r.fileName, Content: r.content},
+			},
+		},
+	})
+}
+
+func (s *Service) sendWebhook(ctx context.Context, report *reports.Report, r *rendered) error {
+	body, err := json.Marshal(webhookPayload{
+		ReportUID:      report.UID,
+		ReportName:     report.Name,
+		DashboardUID:   r.dashboard.UID,
+		DashboardTitle: r.dashboard.Title,
+		DashboardURL:   r.dashboardURL,
+		TimeRange:      report.TimeRange,
+		FileName:       r.fileName,
+		ContentType:    r.contentType,
+		Content:        base64.StdEncoding.EncodeToString(r.content),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notificationService.SendWebhookSync(ctx, &notifications.SendWebhookSync{
+		Url:         report.WebhookURL,
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+		Body:        string(body),
+	})
+}
+
+// dashboardQuery returns the query parameters of the dashboard for the time range and variables of the report.
+func dashboardQuery(report *reports.Report) url.Values {
+	query := url.Values{}
+	query.Set("orgId", strconv.FormatInt(report.OrgID, 10))
+	if report.TimeRange.From != "" {
+		query.Set("from", report.TimeRange.From)
+		query.Set("to", report.TimeRange.To)
+	}
+	if report.Timezone != "" {
+		query.Set("timezone", report.Timezone)
+	}
+
+	for name, values := range report.Variables {
+		for _, value := range values {
+			query.Add("var-"+name, value)
+		}
+	}
+	return query
+}
@@ -0,0 +1,303 @@
+package reportsimpl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	lockName          = "reports scheduler"
+	retentionInterval = time.Hour
+	maxNameLength     = 190
+	defaultRunsLimit  = 20
+)
+
+var _ reports.Service = (*Service)(nil)
+
+type serverLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+type Service struct {
+	cfg                 *setting.Cfg
+	store               *store
+	renderService       rendering.Service
+	notificationService notifications.Service
+	dashboardService    dashboards.DashboardService
+	userService         user.Service
+	accessControl       accesscontrol.AccessControl
+	serverLock          serverLock
+	routeRegister       routing.RouteRegister
+	log                 log.Logger
+	now                 func() time.Time
+
+	lastCleanup time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	acService accesscontrol.Service, renderService rendering.Service, notificationService notifications.Service,
+	dashboardService dashboards.DashboardService, userService user.Service, serverLockService *serverlock.ServerLockService,
+) (*Service, error) {
+	s := &Service{
+		cfg:                 cfg,
+		store:               &store{db: db},
+		renderService:       renderService,
+		notificationService: notificationService,
+		dashboardService:    dashboardService,
+		userService:         userService,
+		accessControl:       accessControl,
+		serverLock:          serverLockService,
+		routeRegister:       routeRegister,
+		log:                 log.New("reports"),
+		now:                 time.Now,
+	}
+	if !cfg.Reports.Enabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Reports.Enabled
+}
+
+// Run sends the reports that are due. The reports are looked up on a single instance at a time, and the next run of
+// a report is updated before it is sent so that it is not sent twice.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Reports.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, lockName, s.cfg.Reports.CheckInterval/2, func(ctx context.Context) {
+				s.runDueReports(ctx)
+				s.cleanup(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute the reports scheduler", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) runDueReports(ctx context.Context) {
+	now := s.now()
+	due, err := s.store.getDueReports(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to get the reports that are due", "error", err)
+		return
+	}
+
+	for _, report := range due {
+		prev := report.NextRun.Unix()
+		var next int64
+		if nextRun, err := nextRun(report.Schedule, report.Timezone, now); err != nil {
+			s.log.Error("Invalid report schedule, the report is no longer scheduled", "uid", report.UID, "orgId", report.OrgID, "error", err)
+		} else {
+			next = nextRun.Unix()
+		}
+
+		claimed, err := s.store.setNextRun(ctx, report.ID, prev, next)
+		if err != nil {
+			s.log.Error("Failed to update the next run of the report", "uid", report.UID, "orgId", report.OrgID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		s.run(ctx, report, reports.RunTriggerSchedule)
+	}
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	now := s.now()
+	if now.Sub(s.lastCleanup) < retentionInterval {
+		return
+	}
+	s.lastCleanup = now
+
+	deleted, err := s.store.deleteRunsOlderThan(ctx, now.Add(-s.cfg.Reports.RunHistoryRetention))
+	if err != nil {
+		s.log.Error("Failed to delete the old report runs", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted old report runs", "count", deleted)
+	}
+}
+
+func (s *Service) GetReports(ctx context.Context, orgID int64) ([]*reports.Report, error) {
+	return s.store.getReports(ctx, orgID)
+}
+
+func (s *Service) GetReport(ctx context.Context, orgID int64, uid string) (*reports.Report, error) {
+	return s.store.getReport(ctx, orgID, uid)
+}
+
+func (s *Service) CreateReport(ctx context.Context, cmd *reports.CreateReportCommand) (*reports.Report, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(cmd.UID) || util.IsShortUIDTooLong(cmd.UID) {
+		return nil, reports.ErrInvalidReportReason(fmt.Sprintf("invalid uid %q", cmd.UID))
+	}
+
+	if _, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: cmd.OrgID, UID: cmd.DashboardUID}); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report := &reports.Report{
+		UID:           cmd.UID,
+		OrgID:         cmd.OrgID,
+		Name:          cmd.Name,
+		DashboardUID:  cmd.DashboardUID,
+		ReportOptions: cmd.ReportOptions,
+		CreatedBy:     cmd.CreatedBy,
+		Created:       now,
+		Updated:       now,
+	}
+	if err := s.validate(report); err != nil {
+		return nil, err
+	}
+	if err := s.store.createReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) UpdateReport(ctx context.Context, cmd *reports.UpdateReportCommand) (*reports.Report, error) {
+	report, err := s.store.getReport(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Name = cmd.Name
+	report.ReportOptions = cmd.ReportOptions
+	if cmd.UpdatedBy != 0 {
+		report.CreatedBy = cmd.UpdatedBy
+	}
+	report.Updated = s.now()
+	if err := s.validate(report); err != nil {
+		return nil, err
+	}
+	if err := s.store.updateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) DeleteReport(ctx context.Context, orgID int64, uid string) error {
+	return s.store.deleteReport(ctx, orgID, uid)
+}
+
+func (s *Service) SendReport(ctx context.Context, orgID int64, uid string) (*reports.Run, error) {
+	report, err := s.store.getReport(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, report, reports.RunTriggerManual), nil
+}
+
+func (s *Service) GetRuns(ctx context.Context, orgID int64, uid string, limit int) ([]*reports.Run, error) {
+	report, err := s.store.getReport(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	return s.store.getRuns(ctx, report.ID, limit)
+}
+
+// validate checks the report and sets its defaults and next run.
+func (s *Service) validate(report *reports.Report) error {
+	if report.Name == "" || len(report.Name) > maxNameLength {
+		return reports.ErrInvalidReportReason(fmt.Sprintf("name must be between 1 and %d characters", maxNameLength))
+	}
+
+	if report.Format == "" {
+		report.Format = reports.FormatPNG
+	}
+	if report.Format != reports.FormatPNG && report.Format != reports.FormatPDF {
+		return reports.ErrInvalidReportReason(fmt.Sprintf("unsupported format %q, use png or pdf", report.Format))
+	}
+
+	if len(report.Recipients) == 0 && report.WebhookURL == "" {
+		return reports.ErrInvalidReportReason("at least one recipient or a webhook URL is required")
+	}
+	for _, recipient := range report.Recipients {
+		if !util.IsEmail(recipient) {
+			return reports.ErrInvalidReportReason(fmt.Sprintf("invalid recipient %q", recipient))
+		}
+	}
+	if report.WebhookURL != "" {
+		u, err := url.Parse(report.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return reports.ErrInvalidReportReason("webhook URL must be an absolute http or https URL")
+		}
+	}
+
+	if (report.TimeRange.From == "") != (report.TimeRange.To == "") {
+		return reports.ErrInvalidReportReason("the time range requires both from and to")
+	}
+
+	next, err := nextRun(report.Schedule, report.Timezone, s.now())
+	if err != nil {
+		return reports.ErrInvalidReportReason(err.Error())
+	}
+	report.NextRun = nil
+	if report.Enabled {
+		report.NextRun = &next
+	}
+	return nil
+}
+
+// nextRun returns the next time the schedule is due after the given time, in the timezone of the report.
+func nextRun(schedule, timezone string, after time.Time) (time.Time, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q", timezone)
+		}
+	}
+
+	sched, err := cron.ParseStandard(strings.TrimSpace(schedule))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %s", schedule, err)
+	}
+	next := sched.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("schedule %q is never due", schedule)
+	}
+	return next, nil
+}
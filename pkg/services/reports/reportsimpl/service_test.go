@@ -0,0 +1,336 @@
+package reportsimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+var renderedContent = []byte("rendered dashboard")
+
+type fakeRenderer struct {
+	rendering.Service
+	dir         string
+	unavailable bool
+
+	renders []rendering.Opts
+}
+
+func (f *fakeRenderer) IsAvailable(ctx context.Context) bool {
+	return !f.unavailable
+}
+
+func (f *fakeRenderer) Render(ctx context.Context, renderType rendering.RenderType, opts rendering.Opts, session rendering.Session) (*rendering.RenderResult, error) {
+	f.renders = append(f.renders, opts)
+	path := filepath.Join(f.dir, "render."+string(renderType))
+	if err := os.WriteFile(path, renderedContent, 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: path}, nil
+}
+
+func setupService(t *testing.T, notificationService notifications.Service) (*Service, *fakeRenderer) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.Reports.Enabled = true
+	cfg.Reports.RenderTimeout = time.Minute
+	cfg.Reports.RenderWidth = 1600
+	cfg.Reports.RenderHeight = 900
+	cfg.Reports.RunHistoryRetention = 24 * time.Hour
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(query *dashboards.GetDashboardQuery) bool {
+		return query.UID == "dash"
+	})).Maybe().Return(&dashboards.Dashboard{UID: "dash", Slug: "my-dashboard", Title: "My dashboard"}, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Maybe().Return(nil, dashboards.ErrDashboardNotFound)
+
+	renderer := &fakeRenderer{dir: t.TempDir()}
+	s := &Service{
+		cfg:                 cfg,
+		store:               &store{db: db.InitTestDB(t)},
+		renderService:       renderer,
+		notificationService: notificationService,
+		dashboardService:    dashboardService,
+		userService: &usertest.FakeUserService{
+			ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor},
+		},
+		serverLock: &fakeServerLock{},
+		log:        log.NewNopLogger(),
+		now:        time.Now,
+	}
+	return s, renderer
+}
+
+func setupSMTP(t *testing.T) (*notifications.NotificationService, *notifications.FakeMailer) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.StaticRootPath = "../../../../public/"
+	cfg.Smtp.Enabled = true
+	cfg.Smtp.TemplatesPatterns = []string{"emails/*.html", "emails/*.txt"}
+	cfg.Smtp.FromAddress = "from@address.com"
+	cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
+
+	mailer := notifications.NewFakeMailer()
+	ns, err := notifications.ProvideService(bus.ProvideBus(tracing.InitializeTracerForTest()), cfg, mailer, nil)
+	require.NoError(t, err)
+	return ns, mailer
+}
+
+type fakeServerLock struct{}
+
+func (f *fakeServerLock) LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
+	fn(ctx)
+	return nil
+}
+
+func validCommand() *reports.CreateReportCommand {
+	return &reports.CreateReportCommand{
+		OrgID:        1,
+		CreatedBy:    2,
+		Name:         "Weekly overview",
+		DashboardUID: "dash",
+		ReportOptions: reports.ReportOptions{
+			Variables:  map[string][]string{"env": {"prod", "dev"}},
+			TimeRange:  reports.TimeRange{From: "now-7d", To: "now"},
+			Schedule:   "0 8 * * 1",
+			Recipients: []string{"ops@example.com"},
+			Enabled:    true,
+		},
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	after := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) // Wednesday
+
+	next, err := nextRun("0 8 * * 1", "", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC), next.UTC())
+
+	next, err = nextRun("0 8 * * *", "Europe/Paris", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 6, 0, 0, 0, time.UTC), next.UTC())
+
+	_, err = nextRun("every monday", "", after)
+	require.Error(t, err)
+
+	_, err = nextRun("0 8 * * 1", "Mars/Olympus", after)
+	require.Error(t, err)
+}
+
+func TestIntegrationService_CreateReport(t *testing.T) {
+	s, _ := setupService(t, &notifications.NotificationServiceMock{})
+
+	t.Run("should create a report and compute its next run", func(t *testing.T) {
+		report, err := s.CreateReport(context.Background(), validCommand())
+		require.NoError(t, err)
+		assert.NotEmpty(t, report.UID)
+		assert.Equal(t, reports.FormatPNG, report.Format)
+		require.NotNil(t, report.NextRun)
+
+		stored, err := s.GetReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, report.Variables, stored.Variables)
+		assert.Equal(t, report.Recipients, stored.Recipients)
+		assert.Equal(t, report.NextRun.Unix(), stored.NextRun.Unix())
+	})
+
+	t.Run("should not schedule a disabled report", func(t *testing.T) {
+		cmd := validCommand()
+		cmd.Enabled = false
+		report, err := s.CreateReport(context.Background(), cmd)
+		require.NoError(t, err)
+		assert.Nil(t, report.NextRun)
+	})
+
+	t.Run("should fail when the dashboard does not exist", func(t *testing.T) {
+		cmd := validCommand()
+		cmd.DashboardUID = "missing"
+		_, err := s.CreateReport(context.Background(), cmd)
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	invalid := map[string]func(cmd *reports.CreateReportCommand){
+		"missing name":         func(cmd *reports.CreateReportCommand) { cmd.Name = "" },
+		"unsupported format":   func(cmd *reports.CreateReportCommand) { cmd.Format = "csv" },
+		"no delivery":          func(cmd *reports.CreateReportCommand) { cmd.Recipients = nil },
+		"invalid recipient":    func(cmd *reports.CreateReportCommand) { cmd.Recipients = []string{"ops"} },
+		"relative webhook URL": func(cmd *reports.CreateReportCommand) { cmd.WebhookURL = "/hook" },
+		"partial time range":   func(cmd *reports.CreateReportCommand) { cmd.TimeRange.To = "" },
+		"invalid schedule":     func(cmd *reports.CreateReportCommand) { cmd.Schedule = "weekly" },
+		"invalid timezone":     func(cmd *reports.CreateReportCommand) { cmd.Timezone = "Mars/Olympus" },
+	}
+	for name, modify := range invalid {
+		t.Run("should reject "+name, func(t *testing.T) {
+			cmd := validCommand()
+			modify(cmd)
+			_, err := s.CreateReport(context.Background(), cmd)
+			require.ErrorIs(t, err, reports.ErrInvalidReport)
+		})
+	}
+}
+
+func TestIntegrationService_UpdateReport(t *testing.T) {
+	s, _ := setupService(t, &notifications.NotificationServiceMock{})
+
+	report, err := s.CreateReport(context.Background(), validCommand())
+	require.NoError(t, err)
+
+	t.Run("should make the user that updates the report its owner", func(t *testing.T) {
+		updated, err := s.UpdateReport(context.Background(), &reports.UpdateReportCommand{
+			OrgID:         1,
+			UID:           report.UID,
+			UpdatedBy:     3,
+			Name:          "Daily overview",
+			ReportOptions: report.ReportOptions,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), updated.CreatedBy)
+
+		stored, err := s.GetReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, "Daily overview", stored.Name)
+		assert.Equal(t, int64(3), stored.CreatedBy)
+	})
+
+	t.Run("should return not found for unknown reports", func(t *testing.T) {
+		_, err := s.UpdateReport(context.Background(), &reports.UpdateReportCommand{OrgID: 1, UID: "missing", Name: "Missing"})
+		require.ErrorIs(t, err, reports.ErrReportNotFound)
+	})
+}
+
+func TestIntegrationService_RunDueReports(t *testing.T) {
+	ns, mailer := setupSMTP(t)
+	s, renderer := setupService(t, ns)
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return created }
+	report, err := s.CreateReport(context.Background(), validCommand())
+	require.NoError(t, err)
+
+	// Nothing is due before the first run
+	s.runDueReports(context.Background())
+	require.Empty(t, renderer.renders)
+
+	due := report.NextRun.Add(time.Minute)
+	s.now = func() time.Time { return due }
+	s.runDueReports(context.Background())
+
+	require.Len(t, renderer.renders, 1)
+	opts := renderer.renders[0]
+	assert.Equal(t, int64(2), opts.UserID)
+	assert.Equal(t, org.RoleEditor, opts.OrgRole)
+	assert.Equal(t, "d/dash/my-dashboard?from=now-7d&kiosk=true&orgId=1&to=now&var-env=prod&var-env=dev", opts.Path)
+
+	require.Len(t, mailer.Sent, 1)
+	msg := mailer.Sent[0]
+	assert.Equal(t, []string{"ops@example.com"}, msg.To)
+	assert.Equal(t, "Report: Weekly overview", msg.Subject)
+	assert.Contains(t, msg.Body["text/plain"], "http://localhost:3000/d/dash/my-dashboard?")
+	require.Len(t, msg.AttachedFiles, 1)
+	assert.Equal(t, "weekly-overview.png", msg.AttachedFiles[0].Name)
+	assert.Equal(t, renderedContent, msg.AttachedFiles[0].Content)
+
+	// The rendered file is removed once delivered
+	_, err = os.Stat(filepath.Join(renderer.dir, "render.png"))
+	assert.True(t, os.IsNotExist(err))
+
+	runs, err := s.GetRuns(context.Background(), 1, report.UID, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, reports.RunTriggerSchedule, runs[0].Trigger)
+	assert.Equal(t, reports.RunStatusSuccess, runs[0].Status)
+
+	// The report is rescheduled and not sent twice
+	stored, err := s.GetReport(context.Background(), 1, report.UID)
+	require.NoError(t, err)
+	assert.Equal(t, report.NextRun.Add(7*24*time.Hour).Unix(), stored.NextRun.Unix())
+
+	s.runDueReports(context.Background())
+	assert.Len(t, mailer.Sent, 1)
+}
+
+func TestIntegrationService_SendReport(t *testing.T) {
+	t.Run("should send the rendered dashboard to the webhook", func(t *testing.T) {
+		ns := &notifications.NotificationServiceMock{}
+		s, _ := setupService(t, ns)
+
+		cmd := validCommand()
+		cmd.Recipients = nil
+		cmd.WebhookURL = "https://example.com/hook"
+		report, err := s.CreateReport(context.Background(), cmd)
+		require.NoError(t, err)
+
+		run, err := s.SendReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, reports.RunStatusSuccess, run.Status)
+		assert.Equal(t, reports.RunTriggerManual, run.Trigger)
+
+		assert.Equal(t, "https://example.com/hook", ns.Webhook.Url)
+		var payload webhookPayload
+		require.NoError(t, json.Unmarshal([]byte(ns.Webhook.Body), &payload))
+		assert.Equal(t, report.UID, payload.ReportUID)
+		assert.Equal(t, "My dashboard", payload.DashboardTitle)
+		assert.Equal(t, "image/png", payload.ContentType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(renderedContent), payload.Content)
+	})
+
+	t.Run("should record failed runs", func(t *testing.T) {
+		ns := &notifications.NotificationServiceMock{ShouldError: errors.New("smtp is down")}
+		s, renderer := setupService(t, ns)
+		report, err := s.CreateReport(context.Background(), validCommand())
+		require.NoError(t, err)
+
+		run, err := s.SendReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, reports.RunStatusFailed, run.Status)
+		assert.Contains(t, run.Error, "smtp is down")
+
+		renderer.unavailable = true
+		run, err = s.SendReport(context.Background(), 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, reports.RunStatusFailed, run.Status)
+
+		runs, err := s.GetRuns(context.Background(), 1, report.UID, 0)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		for _, r := range runs {
+			assert.Equal(t, reports.RunStatusFailed, r.Status)
+		}
+	})
+
+	t.Run("should return not found for unknown reports", func(t *testing.T) {
+		s, _ := setupService(t, &notifications.NotificationServiceMock{})
+		_, err := s.SendReport(context.Background(), 1, "unknown")
+		require.ErrorIs(t, err, reports.ErrReportNotFound)
+	})
+}
@@ -0,0 +1,289 @@
+package reportsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/reports"
+)
+
+type reportRow struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	UID          string    `xorm:"uid"`
+	Name         string    `xorm:"name"`
+	DashboardUID string    `xorm:"dashboard_uid"`
+	Variables    string    `xorm:"variables"`
+	TimeFrom     string    `xorm:"time_from"`
+	TimeTo       string    `xorm:"time_to"`
+	Schedule     string    `xorm:"schedule"`
+	Timezone     string    `xorm:"timezone"`
+	Format       string    `xorm:"format"`
+	Recipients   string    `xorm:"recipients"`
+	WebhookURL   string    `xorm:"webhook_url"`
+	Enabled      bool      `xorm:"enabled"`
+	CreatedBy    int64     `xorm:"created_by"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+	// NextRun is the unix time of the next scheduled run, 0 when the report is disabled.
+	NextRun int64 `xorm:"next_run"`
+}
+
+func (reportRow) TableName() string { return "report" }
+
+type runRow struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	OrgID       int64     `xorm:"org_id"`
+	ReportID    int64     `xorm:"report_id"`
+	TriggeredBy string    `xorm:"triggered_by"`
+	Status      string    `xorm:"status"`
+	Error       string    `xorm:"error"`
+	Started     time.Time `xorm:"started"`
+	Finished    time.Time `xorm:"finished"`
+}
+
+func (runRow) TableName() string { return "report_run" }
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) getReports(ctx context.Context, orgID int64) ([]*reports.Report, error) {
+	var rows []*reportRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).OrderBy("name ASC").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReports(rows)
+}
+
+func (s *store) getReport(ctx context.Context, orgID int64, uid string) (*reports.Report, error) {
+	var row reportRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return reports.ErrReportNotFound.Errorf("report with uid %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReport(&row)
+}
+
+// getDueReports returns the enabled reports whose next run is before the given time.
+func (s *store) getDueReports(ctx context.Context, now time.Time) ([]*reports.Report, error) {
+	var rows []*reportRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run > 0 AND next_run <= ?", true, now.Unix()).OrderBy("next_run ASC").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReports(rows)
+}
+
+func (s *store) createReport(ctx context.Context, report *reports.Report) error {
+	row, err := fromReport(report)
+	if err != nil {
+		return err
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(row); err != nil {
+			return err
+		}
+		report.ID = row.ID
+		return nil
+	})
+}
+
+func (s *store) updateReport(ctx context.Context, report *reports.Report) error {
+	row, err := fromReport(report)
+	if err != nil {
+		return err
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(row.ID).AllCols().Update(row)
+		return err
+	})
+}
+
+// setNextRun updates the next run of the report, it returns false when the next run was changed in the meantime,
+// for example by another instance.
+func (s *store) setNextRun(ctx context.Context, id int64, prev, next int64) (bool, error) {
+	var updated int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE report SET next_run = ? WHERE id = ? AND next_run = ?", next, id, prev)
+		if err != nil {
+			return err
+		}
+		updated, err = res.RowsAffected()
+		return err
+	})
+	return updated > 0, err
+}
+
+func (s *store) deleteReport(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var row reportRow
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return reports.ErrReportNotFound.Errorf("report with uid %s not found", uid)
+		}
+		if _, err := sess.Exec("DELETE FROM report_run WHERE report_id = ?", row.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM report WHERE id = ?", row.ID)
+		return err
+	})
+}
+
+func (s *store) createRun(ctx context.Context, orgID int64, run *reports.Run) error {
+	row := &runRow{
+		OrgID:       orgID,
+		ReportID:    run.ReportID,
+		TriggeredBy: string(run.Trigger),
+		Status:      string(run.Status),
+		Error:       run.Error,
+		Started:     run.Started,
+		Finished:    run.Finished,
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(row); err != nil {
+			return err
+		}
+		run.ID = row.ID
+		return nil
+	})
+}
+
+func (s *store) getRuns(ctx context.Context, reportID int64, limit int) ([]*reports.Run, error) {
+	var rows []*runRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("report_id = ?", reportID).OrderBy("started DESC, id DESC").Limit(limit).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*reports.Run, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, &reports.Run{
+			ID:       row.ID,
+			ReportID: row.ReportID,
+			Trigger:  reports.RunTrigger(row.TriggeredBy),
+			Status:   reports.RunStatus(row.Status),
+			Error:    row.Error,
+			Started:  row.Started,
+			Finished: row.Finished,
+		})
+	}
+	return runs, nil
+}
+
+func (s *store) deleteRunsOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM report_run WHERE started < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+func fromReport(report *reports.Report) (*reportRow, error) {
+	variables, err := json.Marshal(report.Variables)
+	if err != nil {
+		return nil, err
+	}
+	recipients, err := json.Marshal(report.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &reportRow{
+		ID:           report.ID,
+		OrgID:        report.OrgID,
+		UID:          report.UID,
+		Name:         report.Name,
+		DashboardUID: report.DashboardUID,
+		Variables:    string(variables),
+		TimeFrom:     report.TimeRange.From,
+		TimeTo:       report.TimeRange.To,
+		Schedule:     report.Schedule,
+		Timezone:     report.Timezone,
+		Format:       string(report.Format),
+		Recipients:   string(recipients),
+		WebhookURL:   report.WebhookURL,
+		Enabled:      report.Enabled,
+		CreatedBy:    report.CreatedBy,
+		Created:      report.Created,
+		Updated:      report.Updated,
+	}
+	if report.NextRun != nil {
+		row.NextRun = report.NextRun.Unix()
+	}
+	return row, nil
+}
+
+func toReport(row *reportRow) (*reports.Report, error) {
+	report := &reports.Report{
+		ID:           row.ID,
+		UID:          row.UID,
+		OrgID:        row.OrgID,
+		Name:         row.Name,
+		DashboardUID: row.DashboardUID,
+		ReportOptions: reports.ReportOptions{
+			TimeRange:  reports.TimeRange{From: row.TimeFrom, To: row.TimeTo},
+			Schedule:   row.Schedule,
+			Timezone:   row.Timezone,
+			Format:     reports.Format(row.Format),
+			WebhookURL: row.WebhookURL,
+			Enabled:    row.Enabled,
+		},
+		CreatedBy: row.CreatedBy,
+		Created:   row.Created,
+		Updated:   row.Updated,
+	}
+	if row.Variables != "" {
+		if err := json.Unmarshal([]byte(row.Variables), &report.Variables); err != nil {
+			return nil, err
+		}
+	}
+	if row.Recipients != "" {
+		if err := json.Unmarshal([]byte(row.Recipients), &report.Recipients); err != nil {
+			return nil, err
+		}
+	}
+	if row.NextRun > 0 {
+		next := time.Unix(row.NextRun, 0)
+		report.NextRun = &next
+	}
+	return report, nil
+}
+
+func toReports(rows []*reportRow) ([]*reports.Report, error) {
+	result := make([]*reports.Report, 0, len(rows))
+	for _, row := range rows {
+		report, err := toReport(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, report)
+	}
+	return result, nil
+}
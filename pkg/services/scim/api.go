@@ -0,0 +1,428 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// The users are provisioned in the organization of the service account, so the writes are gated on the organization
+// scoped permissions. Creating, disabling and logging out the accounts of the users are done by the service.
+var (
+	usersReadEvaluator  = ac.EvalPermission(ac.ActionOrgUsersRead, ac.ScopeUsersAll)
+	usersWriteEvaluator = ac.EvalAll(
+		ac.EvalPermission(ac.ActionOrgUsersAdd, ac.ScopeUsersAll),
+		ac.EvalPermission(ac.ActionOrgUsersWrite, ac.ScopeUsersAll),
+		ac.EvalPermission(ac.ActionOrgUsersRemove, ac.ScopeUsersAll),
+	)
+	groupsReadEvaluator  = ac.EvalPermission(ac.ActionTeamsRead, ac.ScopeTeamsAll)
+	groupsWriteEvaluator = ac.EvalAll(
+		ac.EvalPermission(ac.ActionTeamsCreate),
+		ac.EvalPermission(ac.ActionTeamsWrite, ac.ScopeTeamsAll),
+		ac.EvalPermission(ac.ActionTeamsDelete, ac.ScopeTeamsAll),
+		ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsAll),
+	)
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/scim/v2", func(r routing.RouteRegister) {
+		r.Get("/ServiceProviderConfig", routing.Wrap(s.serviceProviderConfigHandler))
+		r.Get("/ResourceTypes", routing.Wrap(s.resourceTypesHandler))
+
+		r.Get("/Users", authorize(usersReadEvaluator), routing.Wrap(s.listUsersHandler))
+		r.Post("/Users", authorize(usersWriteEvaluator), routing.Wrap(s.createUserHandler))
+		r.Get("/Users/:id", authorize(usersReadEvaluator), routing.Wrap(s.getUserHandler))
+		r.Put("/Users/:id", authorize(usersWriteEvaluator), routing.Wrap(s.replaceUserHandler))
+		r.Patch("/Users/:id", authorize(usersWriteEvaluator), routing.Wrap(s.patchUserHandler))
+		r.Delete("/Users/:id", authorize(usersWriteEvaluator), routing.Wrap(s.deleteUserHandler))
+
+		r.Get("/Groups", authorize(groupsReadEvaluator), routing.Wrap(s.listGroupsHandler))
+		r.Post("/Groups", authorize(groupsWriteEvaluator), routing.Wrap(s.createGroupHandler))
+		r.Get("/Groups/:id", authorize(groupsReadEvaluator), routing.Wrap(s.getGroupHandler))
+		r.Put("/Groups/:id", authorize(groupsWriteEvaluator), routing.Wrap(s.replaceGroupHandler))
+		r.Patch("/Groups/:id", authorize(groupsWriteEvaluator), routing.Wrap(s.patchGroupHandler))
+		r.Delete("/Groups/:id", authorize(groupsWriteEvaluator), routing.Wrap(s.deleteGroupHandler))
+
+		r.Post("/Bulk", authorize(ac.EvalAll(usersWriteEvaluator, groupsWriteEvaluator)), routing.Wrap(s.bulkHandler))
+	}, middleware.ReqSignedIn, requireServiceAccount)
+}
+
+// requireServiceAccount rejects requests that are not authenticated with a service account token.
+func requireServiceAccount(c *contextmodel.ReqContext) {
+	if c.SignedInUser.GetID().IsNamespace(identity.NamespaceServiceAccount) {
+		return
+	}
+	scimResponse(http.StatusForbidden, newError(http.StatusForbidden, "", "SCIM requests must be authenticated with a service account token")).WriteTo(c)
+}
+
+func (s *Service) serviceProviderConfigHandler(c *contextmodel.ReqContext) response.Response {
+	return scimResponse(http.StatusOK, map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": true, "maxOperations": s.cfg.SCIM.MaxBulkOperations, "maxPayloadSize": 1048576},
+		"filter":         map[string]any{"supported": true, "maxResults": s.cfg.SCIM.MaxResults},
+		"changePassword": map[string]any{"supported": false},
+		"sort":           map[string]any{"supported": false},
+		"etag":           map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with a Grafana service account token in the Authorization header",
+			"primary":     true,
+		}},
+	})
+}
+
+func (s *Service) resourceTypesHandler(c *contextmodel.ReqContext) response.Response {
+	resourceTypes := []any{
+		map[string]any{"schemas": []string{SchemaResourceType}, "id": ResourceTypeUser, "name": ResourceTypeUser, "endpoint": "/Users", "schema": SchemaUser},
+		map[string]any{"schemas": []string{SchemaResourceType}, "id": ResourceTypeGroup, "name": ResourceTypeGroup, "endpoint": "/Groups", "schema": SchemaGroup},
+	}
+	return scimResponse(http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	})
+}
+
+func (s *Service) listUsersHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.ListUsers(c.Req.Context(), c.SignedInUser, listQuery(c))
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) getUserHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) createUserHandler(c *contextmodel.ReqContext) response.Response {
+	u := &User{}
+	if err := bind(c.Req, u); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.CreateUser(c.Req.Context(), c.SignedInUser, u)
+	return s.respond(c, http.StatusCreated, result, err)
+}
+
+func (s *Service) replaceUserHandler(c *contextmodel.ReqContext) response.Response {
+	u := &User{}
+	if err := bind(c.Req, u); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.ReplaceUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], u)
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) patchUserHandler(c *contextmodel.ReqContext) response.Response {
+	patch := &PatchRequest{}
+	if err := bind(c.Req, patch); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.PatchUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], patch.Operations)
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) deleteUserHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	return s.respond(c, http.StatusNoContent, nil, err)
+}
+
+func (s *Service) listGroupsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.ListGroups(c.Req.Context(), c.SignedInUser, listQuery(c))
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) getGroupHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) createGroupHandler(c *contextmodel.ReqContext) response.Response {
+	g := &Group{}
+	if err := bind(c.Req, g); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.CreateGroup(c.Req.Context(), c.SignedInUser, g)
+	return s.respond(c, http.StatusCreated, result, err)
+}
+
+func (s *Service) replaceGroupHandler(c *contextmodel.ReqContext) response.Response {
+	g := &Group{}
+	if err := bind(c.Req, g); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.ReplaceGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], g)
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) patchGroupHandler(c *contextmodel.ReqContext) response.Response {
+	patch := &PatchRequest{}
+	if err := bind(c.Req, patch); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	result, err := s.PatchGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], patch.Operations)
+	return s.respond(c, http.StatusOK, result, err)
+}
+
+func (s *Service) deleteGroupHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	return s.respond(c, http.StatusNoContent, nil, err)
+}
+
+// bulkHandler runs the operations of a bulk request in order. Resources created by an operation can be referenced
+// by later operations with bulkId:<bulkId>, for example to add a user created in the same request to a group.
+func (s *Service) bulkHandler(c *contextmodel.ReqContext) response.Response {
+	req := &BulkRequest{}
+	if err := bind(c.Req, req); err != nil {
+		return s.respond(c, 0, nil, err)
+	}
+	if len(req.Operations) > s.cfg.SCIM.MaxBulkOperations {
+		return s.respond(c, 0, nil, newError(http.StatusRequestEntityTooLarge, "", "the bulk request exceeds the maximum number of %d operations", s.cfg.SCIM.MaxBulkOperations))
+	}
+
+	resp := &BulkResponse{Schemas: []string{SchemaBulkResponse}, Operations: []BulkOperationResponse{}}
+	bulkIDs := map[string]string{}
+	errorCount := 0
+	for _, op := range req.Operations {
+		status, location, result, err := s.bulkOperation(c, op, bulkIDs)
+		opResp := BulkOperationResponse{Method: op.Method, BulkID: op.BulkID, Location: location, Status: fmt.Sprint(status)}
+		if err != nil {
+			errorCount++
+			status = statusCode(err)
+			opResp.Status = fmt.Sprint(status)
+			opResp.Response = s.toError(c, status, err)
+		} else if op.BulkID != "" && strings.EqualFold(op.Method, http.MethodPost) {
+			bulkIDs[op.BulkID] = resourceID(result)
+		}
+		resp.Operations = append(resp.Operations, opResp)
+
+		if req.FailOnErrors > 0 && errorCount >= req.FailOnErrors {
+			break
+		}
+	}
+	return scimResponse(http.StatusOK, resp)
+}
+
+func (s *Service) bulkOperation(c *contextmodel.ReqContext, op BulkOperation, bulkIDs map[string]string) (int, string, any, error) {
+	ctx := c.Req.Context()
+	data, err := resolveBulkIDs(op.Data, bulkIDs)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	path, err := resolveBulkIDs(op.Path, bulkIDs)
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	segments := strings.Split(strings.Trim(path.(string), "/"), "/")
+	resourceType := ""
+	switch segments[0] {
+	case "Users":
+		resourceType = ResourceTypeUser
+	case "Groups":
+		resourceType = ResourceTypeGroup
+	default:
+		return 0, "", nil, errInvalidPath("unsupported bulk path %q", op.Path)
+	}
+	id := ""
+	if len(segments) == 2 {
+		id = segments[1]
+	}
+	method := strings.ToUpper(op.Method)
+	if (method == http.MethodPost) != (id == "") || len(segments) > 2 {
+		return 0, "", nil, errInvalidPath("invalid path %q for method %s", op.Path, op.Method)
+	}
+
+	var result any
+	status := http.StatusOK
+	switch {
+	case method == http.MethodDelete && resourceType == ResourceTypeUser:
+		status, err = http.StatusNoContent, s.DeleteUser(ctx, c.SignedInUser, id)
+	case method == http.MethodDelete:
+		status, err = http.StatusNoContent, s.DeleteGroup(ctx, c.SignedInUser, id)
+	case method == http.MethodPatch:
+		patch := &PatchRequest{}
+		if err := decodeData(data, patch); err != nil {
+			return 0, "", nil, err
+		}
+		if resourceType == ResourceTypeUser {
+			result, err = s.PatchUser(ctx, c.SignedInUser, id, patch.Operations)
+		} else {
+			result, err = s.PatchGroup(ctx, c.SignedInUser, id, patch.Operations)
+		}
+	case resourceType == ResourceTypeUser:
+		u := &User{}
+		if err := decodeData(data, u); err != nil {
+			return 0, "", nil, err
+		}
+		if method == http.MethodPost {
+			status = http.StatusCreated
+			result, err = s.CreateUser(ctx, c.SignedInUser, u)
+		} else if method == http.MethodPut {
+			result, err = s.ReplaceUser(ctx, c.SignedInUser, id, u)
+		} else {
+			return 0, "", nil, errInvalidValue("unsupported method %q", op.Method)
+		}
+	default:
+		g := &Group{}
+		if err := decodeData(data, g); err != nil {
+			return 0, "", nil, err
+		}
+		if method == http.MethodPost {
+			status = http.StatusCreated
+			result, err = s.CreateGroup(ctx, c.SignedInUser, g)
+		} else if method == http.MethodPut {
+			result, err = s.ReplaceGroup(ctx, c.SignedInUser, id, g)
+		} else {
+			return 0, "", nil, errInvalidValue("unsupported method %q", op.Method)
+		}
+	}
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	location := ""
+	switch r := result.(type) {
+	case *User:
+		location = r.Meta.Location
+	case *Group:
+		location = r.Meta.Location
+	}
+	return status, location, result, nil
+}
+
+// resolveBulkIDs replaces the bulkId:<bulkId> references with the IDs of the resources created in the same request.
+func resolveBulkIDs(value any, bulkIDs map[string]string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		idx := strings.Index(v, "bulkId:")
+		if idx < 0 {
+			return v, nil
+		}
+		ref := v[idx+len("bulkId:"):]
+		if end := strings.Index(ref, "/"); end >= 0 {
+			ref = ref[:end]
+		}
+		id, ok := bulkIDs[ref]
+		if !ok {
+			return nil, newError(http.StatusConflict, "invalidValue", "unresolved bulkId %q", ref)
+		}
+		return strings.Replace(v, "bulkId:"+ref, id, 1), nil
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			resolved, err := resolveBulkIDs(item, bulkIDs)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			resolved, err := resolveBulkIDs(item, bulkIDs)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, resolved)
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func resourceID(resource any) string {
+	switch r := resource.(type) {
+	case *User:
+		return r.ID
+	case *Group:
+		return r.ID
+	}
+	return ""
+}
+
+func decodeData(data any, v any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "invalid data: %s", err)
+	}
+	return nil
+}
+
+func listQuery(c *contextmodel.ReqContext) ListQuery {
+	excludeMembers := false
+	for _, attr := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			excludeMembers = true
+		}
+	}
+	// a missing count returns the default number of resources, count=0 only returns totalResults
+	count := -1
+	if n, err := strconv.Atoi(c.Query("count")); err == nil {
+		count = n
+	}
+	return ListQuery{
+		Filter:         c.Query("filter"),
+		StartIndex:     c.QueryInt("startIndex"),
+		Count:          count,
+		ExcludeMembers: excludeMembers,
+	}
+}
+
+// bind decodes the body of requests sent with the SCIM or the JSON media type.
+func bind(req *http.Request, v any) error {
+	m, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || (m != ContentType && m != "application/json") {
+		return newError(http.StatusUnsupportedMediaType, "", "content type must be %s", ContentType)
+	}
+	defer func() { _ = req.Body.Close() }()
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
+
+func (s *Service) respond(c *contextmodel.ReqContext, status int, result any, err error) response.Response {
+	if err != nil {
+		status := statusCode(err)
+		return scimResponse(status, s.toError(c, status, err))
+	}
+	if status == http.StatusNoContent {
+		return response.Respond(http.StatusNoContent, nil)
+	}
+	return scimResponse(status, result)
+}
+
+// toError hides the details of internal errors from the client.
+func (s *Service) toError(c *contextmodel.ReqContext, status int, err error) *Error {
+	var scimErr *Error
+	if errors.As(err, &scimErr) {
+		return scimErr
+	}
+	s.log.FromContext(c.Req.Context()).Error("SCIM request failed", "path", c.Req.URL.Path, "error", err)
+	return newError(status, "", "internal server error")
+}
+
+func scimResponse(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", ContentType)
+}
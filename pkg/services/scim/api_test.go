@@ -0,0 +1,103 @@
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_Authorization(t *testing.T) {
+	// the permissions of the Admin role of the organization on its users and teams
+	orgAdminPermissions := []accesscontrol.Permission{
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersAdd, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersWrite, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersRemove, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsCreate},
+		{Action: accesscontrol.ActionTeamsWrite, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsDelete, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsPermissionsWrite, Scope: accesscontrol.ScopeTeamsAll},
+	}
+	viewerPermissions := []accesscontrol.Permission{
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+	}
+
+	type testCase struct {
+		desc             string
+		serviceAccount   bool
+		permissions      []accesscontrol.Permission
+		expectedCreate   int
+		expectedDelete   int
+		expectedDisabled bool
+	}
+
+	tests := []testCase{
+		{
+			desc:             "service account with the organization Admin role can provision and deprovision users",
+			serviceAccount:   true,
+			permissions:      orgAdminPermissions,
+			expectedCreate:   http.StatusCreated,
+			expectedDelete:   http.StatusNoContent,
+			expectedDisabled: true,
+		},
+		{
+			desc:           "service account without the permissions to manage the users of the organization is forbidden",
+			serviceAccount: true,
+			permissions:    viewerPermissions,
+			expectedCreate: http.StatusForbidden,
+			expectedDelete: http.StatusForbidden,
+		},
+		{
+			desc:           "users are forbidden",
+			permissions:    orgAdminPermissions,
+			expectedCreate: http.StatusForbidden,
+			expectedDelete: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, env := newTestService(t)
+			s.routeRegister = routing.NewRouteRegister()
+			s.accessControl = acimpl.ProvideAccessControl(s.cfg)
+			s.registerAPIEndpoints()
+			server := webtest.NewServer(t, s.routeRegister)
+
+			env.orgs.roles[env.users.add(&user.User{Login: "admin"}).ID] = org.RoleAdmin
+			jane := env.users.add(&user.User{Login: "jane"})
+			env.orgs.roles[jane.ID] = org.RoleViewer
+
+			signedInUser := &user.SignedInUser{
+				OrgID:            1,
+				UserID:           100,
+				Login:            "sa-scim",
+				IsServiceAccount: tc.serviceAccount,
+				OrgRole:          org.RoleAdmin,
+				Permissions:      map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tc.permissions)},
+			}
+			send := func(req *http.Request) int {
+				req.Header.Set("Content-Type", ContentType)
+				res, err := server.Send(webtest.RequestWithSignedInUser(req, signedInUser))
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+				return res.StatusCode
+			}
+
+			require.Equal(t, tc.expectedCreate, send(server.NewPostRequest("/api/scim/v2/Users", strings.NewReader(`{"userName":"john"}`))))
+			require.Equal(t, tc.expectedDelete, send(server.NewRequest(http.MethodDelete, "/api/scim/v2/Users/"+strconv.FormatInt(jane.ID, 10), nil)))
+			require.Equal(t, tc.expectedDisabled, jane.IsDisabled)
+		})
+	}
+}
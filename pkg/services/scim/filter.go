@@ -0,0 +1,482 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a parsed SCIM filter expression (RFC 7644 section 3.4.2.2) that is evaluated against
+// the JSON representation of a resource.
+type Filter interface {
+	Matches(resource map[string]any) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f *logicalFilter) Matches(r map[string]any) bool {
+	if f.and {
+		return f.left.Matches(r) && f.right.Matches(r)
+	}
+	return f.left.Matches(r) || f.right.Matches(r)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f *notFilter) Matches(r map[string]any) bool {
+	return !f.filter.Matches(r)
+}
+
+type presentFilter struct {
+	path []string
+}
+
+func (f *presentFilter) Matches(r map[string]any) bool {
+	for _, v := range attributeValues(r, f.path) {
+		if !isEmpty(v) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (f *compareFilter) Matches(r map[string]any) bool {
+	values := attributeValues(r, f.path)
+	if f.op == "ne" {
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches if any item of the multi-valued attribute matches the filter, for example emails[type eq "work"].
+type valuePathFilter struct {
+	path   []string
+	filter Filter
+}
+
+func (f *valuePathFilter) Matches(r map[string]any) bool {
+	for _, v := range attributeValues(r, f.path) {
+		if item, ok := v.(map[string]any); ok && f.filter.Matches(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilter parses a SCIM filter expression.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, errInvalidFilter("unexpected %q", p.peek().value)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, value: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, value: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, value: "]"})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(runes) {
+				return nil, errInvalidFilter("unterminated string in %q", expr)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(string(runes[i:j+1])), &s); err != nil {
+				return nil, errInvalidFilter("invalid string %s", string(runes[i:j+1]))
+			}
+			tokens = append(tokens, token{kind: tokenString, value: s})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()[]\"", runes[j]); j++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.done() {
+		return token{}, errInvalidFilter("unexpected end of filter")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	return !p.done() && p.peek().kind == tokenWord && strings.EqualFold(p.peek().value, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return errInvalidFilter("expected %q but got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (Filter, error) {
+	if !p.isKeyword("not") {
+		return p.parsePrimary()
+	}
+	p.pos++
+	if err := p.expect(tokenOpenParen, "("); err != nil {
+		return nil, err
+	}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenCloseParen, ")"); err != nil {
+		return nil, err
+	}
+	return &notFilter{filter: f}, nil
+}
+
+func (p *filterParser) parsePrimary() (Filter, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == tokenOpenParen {
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if t.kind != tokenWord {
+		return nil, errInvalidFilter("expected attribute but got %q", t.value)
+	}
+	path := splitAttributePath(t.value)
+
+	if !p.done() && p.peek().kind == tokenOpenBracket {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{path: path, filter: f}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.value)
+	switch op {
+	case "pr":
+		return &presentFilter{path: path}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, errInvalidFilter("unsupported operator %q", opToken.value)
+	}
+
+	valueToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseFilterValue(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return &compareFilter{path: path, op: op, value: value}, nil
+}
+
+func parseFilterValue(t token) (any, error) {
+	if t.kind == tokenString {
+		return t.value, nil
+	}
+	if t.kind != tokenWord {
+		return nil, errInvalidFilter("expected value but got %q", t.value)
+	}
+	switch strings.ToLower(t.value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.value, 64)
+	if err != nil {
+		return nil, errInvalidFilter("invalid value %q", t.value)
+	}
+	return n, nil
+}
+
+// splitAttributePath removes the schema URN prefix of the attribute and splits the sub-attributes.
+func splitAttributePath(attr string) []string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(attr) > len(schema) && strings.EqualFold(attr[:len(schema)+1], schema+":") {
+			attr = attr[len(schema)+1:]
+			break
+		}
+	}
+	return strings.Split(attr, ".")
+}
+
+// attributeValues returns the values of the attribute, flattening multi-valued attributes.
+// Attribute names are case-insensitive.
+func attributeValues(r map[string]any, path []string) []any {
+	if len(path) == 0 {
+		return nil
+	}
+	v, ok := lookup(r, path[0])
+	if !ok {
+		return nil
+	}
+
+	var values []any
+	if items, ok := v.([]any); ok {
+		values = items
+	} else {
+		values = []any{v}
+	}
+	if len(path) == 1 {
+		return values
+	}
+
+	var result []any
+	for _, item := range values {
+		if m, ok := item.(map[string]any); ok {
+			result = append(result, attributeValues(m, path[1:])...)
+		}
+	}
+	return result
+}
+
+func lookup(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func compare(actual any, op string, expected any) bool {
+	// comparing a complex multi-valued attribute compares its value sub-attribute, for example emails eq "a@b.c"
+	if m, ok := actual.(map[string]any); ok {
+		actual = m["value"]
+	}
+
+	switch e := expected.(type) {
+	case nil:
+		return op == "eq" && isEmpty(actual)
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		return compareOrdered(a, e, op)
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		if at, err := time.Parse(time.RFC3339, a); err == nil {
+			if et, err := time.Parse(time.RFC3339, e); err == nil {
+				return compareOrdered(float64(at.UnixNano()), float64(et.UnixNano()), op)
+			}
+		}
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		}
+		return compareOrdered(strings.Compare(a, e), 0, op)
+	}
+	return false
+}
+
+func compareOrdered[T int | float64](a, b T, op string) bool {
+	switch op {
+	case "eq":
+		return a == b
+	case "gt":
+		return a > b
+	case "ge":
+		return a >= b
+	case "lt":
+		return a < b
+	case "le":
+		return a <= b
+	}
+	return false
+}
+
+func isEmpty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	}
+	return false
+}
+
+// toMap returns the JSON representation of the resource that filters and patch operations work with.
+func toMap(resource any) (map[string]any, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fromMap converts the JSON representation back to the resource.
+func fromMap(m map[string]any, resource any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, resource); err != nil {
+		return errInvalidValue("invalid resource: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	active := true
+	user, err := toMap(&User{
+		Schemas:     []string{SchemaUser},
+		ID:          "42",
+		ExternalID:  "00u1",
+		UserName:    "Jane.Doe@example.com",
+		DisplayName: "Jane Doe",
+		Name:        &Name{GivenName: "Jane", FamilyName: "Doe"},
+		Emails: []MultiValued{
+			{Value: "jane@example.com", Type: "work", Primary: true},
+			{Value: "jane@home.example", Type: "home"},
+		},
+		Active: &active,
+		Roles:  []MultiValued{{Value: "Editor", Primary: true}},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		filter   string
+		expected bool
+	}{
+		{filter: `userName eq "jane.doe@example.com"`, expected: true},
+		{filter: `userName eq "john"`, expected: false},
+		{filter: `USERNAME Eq "Jane.Doe@example.com"`, expected: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "jane.doe@example.com"`, expected: true},
+		{filter: `userName ne "john"`, expected: true},
+		{filter: `userName co "doe"`, expected: true},
+		{filter: `userName sw "jane"`, expected: true},
+		{filter: `userName ew "example.com"`, expected: true},
+		{filter: `userName gt "a"`, expected: true},
+		{filter: `userName lt "a"`, expected: false},
+		{filter: `externalId eq "00u1"`, expected: true},
+		{filter: `name.givenName eq "Jane"`, expected: true},
+		{filter: `emails eq "jane@home.example"`, expected: true},
+		{filter: `emails.value eq "jane@example.com"`, expected: true},
+		{filter: `emails[type eq "work" and value co "@example.com"]`, expected: true},
+		{filter: `emails[type eq "work" and value co "@home"]`, expected: false},
+		{filter: `active eq true`, expected: true},
+		{filter: `active eq false`, expected: false},
+		{filter: `title pr`, expected: false},
+		{filter: `displayName pr`, expected: true},
+		{filter: `userName eq "john" or roles.value eq "Editor"`, expected: true},
+		{filter: `userName eq "john" and roles.value eq "Editor"`, expected: false},
+		{filter: `not (userName eq "john")`, expected: true},
+		{filter: `(userName eq "john" or userName sw "jane") and active eq true`, expected: true},
+		{filter: `displayName eq "Jane \"JD\" Doe"`, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.Matches(user))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		`userName`,
+		`userName eq`,
+		`userName xx "a"`,
+		`userName eq "a`,
+		`(userName eq "a"`,
+		`emails[type eq "work"`,
+		`userName eq "a" or`,
+		`userName eq "a" "b"`,
+		`userName eq value`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := ParseFilter(filter)
+			require.Error(t, err)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			require.Equal(t, "invalidFilter", scimErr.ScimType)
+		})
+	}
+}
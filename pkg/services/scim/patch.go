@@ -0,0 +1,235 @@
+package scim
+
+import (
+	"strings"
+)
+
+// patchPath is the parsed path of a patch operation, for example emails[type eq "work"].value.
+type patchPath struct {
+	attr    string
+	filter  Filter
+	subAttr string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	p := &patchPath{}
+	if open := strings.Index(path, "["); open >= 0 {
+		closing := strings.LastIndex(path, "]")
+		if closing < open {
+			return nil, errInvalidPath("invalid path %q", path)
+		}
+		f, err := ParseFilter(path[open+1 : closing])
+		if err != nil {
+			return nil, errInvalidPath("invalid filter in path %q: %s", path, err)
+		}
+		p.filter = f
+		rest := path[closing+1:]
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") || strings.Contains(rest[1:], ".") {
+				return nil, errInvalidPath("invalid path %q", path)
+			}
+			p.subAttr = rest[1:]
+		}
+		path = path[:open]
+	}
+
+	segments := splitAttributePath(path)
+	switch {
+	case len(segments) == 1:
+		p.attr = segments[0]
+	case len(segments) == 2 && p.filter == nil:
+		p.attr, p.subAttr = segments[0], segments[1]
+	default:
+		return nil, errInvalidPath("invalid path %q", path)
+	}
+	if p.attr == "" {
+		return nil, errInvalidPath("invalid path %q", path)
+	}
+	return p, nil
+}
+
+// ApplyPatch applies the operations of a PATCH request (RFC 7644 section 3.5.2) to the JSON representation of a resource.
+func ApplyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, op := range operations {
+		if err := applyOperation(resource, strings.ToLower(op.Op), op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]any, op, path string, value any) error {
+	switch op {
+	case "add", "replace", "remove":
+	default:
+		return errInvalidValue("unsupported patch operation %q", op)
+	}
+
+	if path == "" {
+		if op == "remove" {
+			return errNoTarget("remove operations require a path")
+		}
+		values, ok := value.(map[string]any)
+		if !ok {
+			return errInvalidValue("operations without path require an object value")
+		}
+		// some clients send paths as keys of the value, for example {"name.givenName": "Jane"}
+		for k, v := range values {
+			if err := applyOperation(resource, op, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	if p.filter != nil {
+		return applyFilteredOperation(resource, op, p, value)
+	}
+
+	target := resource
+	attr := p.attr
+	if p.subAttr != "" {
+		key := keyOf(resource, p.attr)
+		sub, ok := resource[key].(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			sub = map[string]any{}
+			resource[key] = sub
+		}
+		target, attr = sub, p.subAttr
+	}
+	key := keyOf(target, attr)
+
+	switch op {
+	case "remove":
+		items, isList := target[key].([]any)
+		removeValues, hasValues := value.([]any)
+		if !isList || !hasValues {
+			delete(target, key)
+			return nil
+		}
+		// remove the items with the given values, for example {"op":"remove","path":"members","value":[{"value":"1"}]}
+		target[key] = removeItems(items, removeValues)
+	case "add":
+		if items, ok := target[key].([]any); ok {
+			target[key] = addItems(items, value)
+			return nil
+		}
+		if existing, ok := target[key].(map[string]any); ok {
+			if values, ok := value.(map[string]any); ok {
+				for k, v := range values {
+					existing[keyOf(existing, k)] = v
+				}
+				return nil
+			}
+		}
+		target[key] = value
+	case "replace":
+		target[key] = value
+	}
+	return nil
+}
+
+func applyFilteredOperation(resource map[string]any, op string, p *patchPath, value any) error {
+	key := keyOf(resource, p.attr)
+	items, _ := resource[key].([]any)
+
+	matched := false
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok || !p.filter.Matches(m) {
+			result = append(result, item)
+			continue
+		}
+		matched = true
+
+		switch {
+		case op == "remove" && p.subAttr == "":
+			continue
+		case op == "remove":
+			delete(m, keyOf(m, p.subAttr))
+		case p.subAttr != "":
+			m[keyOf(m, p.subAttr)] = value
+		default:
+			values, ok := value.(map[string]any)
+			if !ok {
+				return errInvalidValue("value of %q must be an object", p.attr)
+			}
+			for k, v := range values {
+				m[keyOf(m, k)] = v
+			}
+		}
+		result = append(result, m)
+	}
+
+	if !matched {
+		if op == "remove" {
+			return nil
+		}
+		return errNoTarget("no value of %q matches the filter", p.attr)
+	}
+	resource[key] = result
+	return nil
+}
+
+func addItems(items []any, value any) []any {
+	values, ok := value.([]any)
+	if !ok {
+		values = []any{value}
+	}
+	for _, v := range values {
+		if containsItem(items, v) {
+			continue
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+func removeItems(items []any, values []any) []any {
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		if !containsItem(values, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// containsItem compares items of multi-valued attributes by their value sub-attribute.
+func containsItem(items []any, item any) bool {
+	for _, i := range items {
+		if strings.EqualFold(itemValue(i), itemValue(item)) {
+			return true
+		}
+	}
+	return false
+}
+
+func itemValue(item any) string {
+	if m, ok := item.(map[string]any); ok {
+		item, _ = lookup(m, "value")
+	}
+	s, _ := item.(string)
+	return s
+}
+
+// keyOf returns the key of the attribute in the map, attribute names are case-insensitive.
+func keyOf(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
@@ -0,0 +1,145 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	newUser := func() map[string]any {
+		return map[string]any{
+			"userName": "jane",
+			"active":   true,
+			"name":     map[string]any{"givenName": "Jane", "familyName": "Doe"},
+			"emails": []any{
+				map[string]any{"value": "jane@example.com", "type": "work", "primary": true},
+				map[string]any{"value": "jane@home.example", "type": "home"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc       string
+		operations []PatchOperation
+		expected   func(m map[string]any)
+	}{
+		{
+			desc:       "replace attribute",
+			operations: []PatchOperation{{Op: "replace", Path: "active", Value: false}},
+			expected:   func(m map[string]any) { m["active"] = false },
+		},
+		{
+			desc:       "operation and attribute names are case-insensitive",
+			operations: []PatchOperation{{Op: "Replace", Path: "UserName", Value: "janed"}},
+			expected:   func(m map[string]any) { m["userName"] = "janed" },
+		},
+		{
+			desc:       "replace sub-attribute",
+			operations: []PatchOperation{{Op: "replace", Path: "name.familyName", Value: "Smith"}},
+			expected:   func(m map[string]any) { m["name"].(map[string]any)["familyName"] = "Smith" },
+		},
+		{
+			desc:       "add sub-attribute to missing attribute",
+			operations: []PatchOperation{{Op: "add", Path: "meta.version", Value: "1"}},
+			expected:   func(m map[string]any) { m["meta"] = map[string]any{"version": "1"} },
+		},
+		{
+			desc:       "add merges objects",
+			operations: []PatchOperation{{Op: "add", Path: "name", Value: map[string]any{"formatted": "Jane Doe"}}},
+			expected:   func(m map[string]any) { m["name"].(map[string]any)["formatted"] = "Jane Doe" },
+		},
+		{
+			desc: "add appends new items only",
+			operations: []PatchOperation{{Op: "add", Path: "emails", Value: []any{
+				map[string]any{"value": "JANE@example.com"},
+				map[string]any{"value": "jd@example.com", "type": "other"},
+			}}},
+			expected: func(m map[string]any) {
+				m["emails"] = append(m["emails"].([]any), map[string]any{"value": "jd@example.com", "type": "other"})
+			},
+		},
+		{
+			desc:       "remove attribute",
+			operations: []PatchOperation{{Op: "remove", Path: "emails"}},
+			expected:   func(m map[string]any) { delete(m, "emails") },
+		},
+		{
+			desc:       "remove items by value",
+			operations: []PatchOperation{{Op: "remove", Path: "emails", Value: []any{map[string]any{"value": "jane@home.example"}}}},
+			expected:   func(m map[string]any) { m["emails"] = m["emails"].([]any)[:1] },
+		},
+		{
+			desc:       "remove items matching filter",
+			operations: []PatchOperation{{Op: "remove", Path: `emails[type eq "home"]`}},
+			expected:   func(m map[string]any) { m["emails"] = m["emails"].([]any)[:1] },
+		},
+		{
+			desc:       "remove with filter matching nothing is a no-op",
+			operations: []PatchOperation{{Op: "remove", Path: `emails[type eq "other"]`}},
+			expected:   func(m map[string]any) {},
+		},
+		{
+			desc:       "replace sub-attribute of items matching filter",
+			operations: []PatchOperation{{Op: "replace", Path: `emails[type eq "work"].value`, Value: "jd@example.com"}},
+			expected: func(m map[string]any) {
+				m["emails"].([]any)[0].(map[string]any)["value"] = "jd@example.com"
+			},
+		},
+		{
+			desc: "operation without path",
+			operations: []PatchOperation{{Op: "replace", Value: map[string]any{
+				"active":         false,
+				"name.givenName": "Janet",
+			}}},
+			expected: func(m map[string]any) {
+				m["active"] = false
+				m["name"].(map[string]any)["givenName"] = "Janet"
+			},
+		},
+		{
+			desc: "operations are applied in order",
+			operations: []PatchOperation{
+				{Op: "remove", Path: "name"},
+				{Op: "add", Path: "name.givenName", Value: "J"},
+			},
+			expected: func(m map[string]any) { m["name"] = map[string]any{"givenName": "J"} },
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual := newUser()
+			require.NoError(t, ApplyPatch(actual, tc.operations))
+
+			expected := newUser()
+			tc.expected(expected)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		operation PatchOperation
+		scimType  string
+	}{
+		{desc: "unsupported operation", operation: PatchOperation{Op: "move", Path: "active"}, scimType: "invalidValue"},
+		{desc: "remove without path", operation: PatchOperation{Op: "remove"}, scimType: "noTarget"},
+		{desc: "add without path requires object", operation: PatchOperation{Op: "add", Value: "x"}, scimType: "invalidValue"},
+		{desc: "invalid filter in path", operation: PatchOperation{Op: "replace", Path: `emails[type xx "work"]`, Value: "x"}, scimType: "invalidPath"},
+		{desc: "too deep path", operation: PatchOperation{Op: "replace", Path: "name.givenName.x", Value: "x"}, scimType: "invalidPath"},
+		{desc: "filter matching nothing", operation: PatchOperation{Op: "replace", Path: `emails[type eq "other"].value`, Value: "x"}, scimType: "noTarget"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			resource := map[string]any{
+				"emails": []any{map[string]any{"value": "jane@example.com", "type": "work"}},
+			}
+			err := ApplyPatch(resource, []PatchOperation{tc.operation})
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			require.Equal(t, tc.scimType, scimErr.ScimType)
+		})
+	}
+}
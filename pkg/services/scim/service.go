@@ -0,0 +1,775 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const teamPermissionMember = "Member"
+
+// Service is a SCIM 2.0 server (RFC 7643 and RFC 7644) that lets identity providers provision the users and teams
+// of the organization of the service account that calls it.
+type Service struct {
+	cfg                    *setting.Cfg
+	store                  store
+	routeRegister          routing.RouteRegister
+	accessControl          accesscontrol.AccessControl
+	acService              accesscontrol.Service
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	tokenService           auth.UserTokenService
+	log                    log.Logger
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl, acService accesscontrol.Service,
+	userService user.Service, orgService org.Service, teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService, tokenService auth.UserTokenService,
+) *Service {
+	s := &Service{
+		cfg:                    cfg,
+		store:                  &xormStore{db: sqlStore, now: time.Now},
+		routeRegister:          routeRegister,
+		accessControl:          accessControl,
+		acService:              acService,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		tokenService:           tokenService,
+		log:                    log.New("scim"),
+	}
+
+	teamService.RegisterDelete("DELETE FROM scim_resource WHERE org_id = ? AND resource_type = 'Group' AND resource_id = ?")
+	orgService.RegisterDelete("DELETE FROM scim_resource WHERE org_id = ?")
+
+	if cfg.SCIM.Enabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+// ListQuery contains the parameters of a list request.
+type ListQuery struct {
+	Filter     string
+	StartIndex int
+	// Count is the maximum number of resources returned, a negative count uses the configured maximum.
+	Count int
+	// ExcludeMembers does not return the members of groups, identity providers use it to list large groups.
+	ExcludeMembers bool
+}
+
+func (s *Service) ListUsers(ctx context.Context, requester identity.Requester, query ListQuery) (*ListResponse, error) {
+	filter, err := parseOptionalFilter(query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	orgID := requester.GetOrgID()
+	result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+		OrgID:                    orgID,
+		Query:                    searchHint(filter, "userName", "emails", "emails.value"),
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	managed, err := s.store.List(ctx, orgID, ResourceTypeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]any, 0, len(result.OrgUsers))
+	for _, ou := range result.OrgUsers {
+		u := s.toUser(ou, managed[ou.UserID], nil)
+		ok, err := matches(filter, u)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resources = append(resources, u)
+		}
+	}
+	return s.paginate(resources, query), nil
+}
+
+func (s *Service) GetUser(ctx context.Context, requester identity.Requester, id string) (*User, error) {
+	orgID := requester.GetOrgID()
+	ou, err := s.getOrgUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.store.Get(ctx, orgID, ResourceTypeUser, ou.UserID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.teamService.GetTeamsByUser(ctx, &team.GetTeamsByUserQuery{OrgID: orgID, UserID: ou.UserID, SignedInUser: requester})
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]MultiValued, 0, len(teams))
+	for _, t := range teams {
+		groups = append(groups, MultiValued{Value: strconv.FormatInt(t.ID, 10), Display: t.Name, Ref: s.location(ResourceTypeGroup, t.ID)})
+	}
+	return s.toUser(ou, r, groups), nil
+}
+
+// CreateUser creates the user and adds it to the organization. Users that already exist in Grafana,
+// for example because they logged in before, are added to the organization instead.
+func (s *Service) CreateUser(ctx context.Context, requester identity.Requester, u *User) (*User, error) {
+	attrs, err := s.userAttributes(u, true)
+	if err != nil {
+		return nil, err
+	}
+	orgID := requester.GetOrgID()
+
+	if u.ExternalID != "" {
+		r, err := s.store.GetByExternalID(ctx, orgID, ResourceTypeUser, u.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			return nil, errUniqueness("user with externalId %q already exists", u.ExternalID)
+		}
+	}
+
+	usr, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: attrs.login})
+	if errors.Is(err, user.ErrUserNotFound) && attrs.email != "" {
+		usr, err = s.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: attrs.email})
+	}
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		usr, err = s.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        attrs.login,
+			Email:        attrs.email,
+			Name:         attrs.name,
+			IsDisabled:   !attrs.active,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case usr.IsServiceAccount:
+		return nil, errUniqueness("userName %q is used by a service account", attrs.login)
+	case usr.IsDisabled != !attrs.active:
+		if err := s.checkActiveChange(ctx, orgID, usr.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: orgID, UserID: usr.ID, Role: attrs.role})
+	if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+		return nil, errUniqueness("user %q already exists", attrs.login)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Upsert(ctx, &resource{OrgID: orgID, ResourceType: ResourceTypeUser, ResourceID: usr.ID, ExternalID: u.ExternalID}); err != nil {
+		return nil, err
+	}
+	s.log.FromContext(ctx).Info("User provisioned", "orgID", orgID, "userID", usr.ID, "login", usr.Login)
+
+	// an existing user may have been disabled
+	if usr.IsDisabled != !attrs.active {
+		if err := s.setDisabled(ctx, usr.ID, !attrs.active); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetUser(ctx, requester, strconv.FormatInt(usr.ID, 10))
+}
+
+// ReplaceUser updates all attributes of the user. Users that are not active are disabled and logged out, the active
+// state of users that are members of other organizations can not be changed.
+func (s *Service) ReplaceUser(ctx context.Context, requester identity.Requester, id string, u *User) (*User, error) {
+	attrs, err := s.userAttributes(u, false)
+	if err != nil {
+		return nil, err
+	}
+	orgID := requester.GetOrgID()
+	ou, err := s.getOrgUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ou.IsDisabled != !attrs.active {
+		if err := s.checkActiveChange(ctx, orgID, ou.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := &user.UpdateUserCommand{
+		UserID: ou.UserID,
+		Login:  attrs.login,
+		Email:  util.StringsFallback2(attrs.email, ou.Email),
+		Name:   attrs.name,
+	}
+	if err := s.userService.Update(ctx, cmd); err != nil {
+		if errors.Is(err, user.ErrCaseInsensitive) || errors.Is(err, user.ErrUserAlreadyExists) {
+			return nil, errUniqueness("userName %q is already taken", attrs.login)
+		}
+		return nil, err
+	}
+
+	if ou.IsDisabled != !attrs.active {
+		if err := s.setDisabled(ctx, ou.UserID, !attrs.active); err != nil {
+			return nil, err
+		}
+	}
+
+	if attrs.role != "" && string(attrs.role) != ou.Role {
+		err := s.orgService.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: orgID, UserID: ou.UserID, Role: attrs.role})
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return nil, errMutability("cannot change the role of the last administrator of the organization")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.Upsert(ctx, &resource{OrgID: orgID, ResourceType: ResourceTypeUser, ResourceID: ou.UserID, ExternalID: u.ExternalID}); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, requester, id)
+}
+
+func (s *Service) PatchUser(ctx context.Context, requester identity.Requester, id string, operations []PatchOperation) (*User, error) {
+	current, err := s.GetUser(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyPatch(m, operations); err != nil {
+		return nil, err
+	}
+	// some identity providers send booleans as strings
+	if active, ok := m[keyOf(m, "active")].(string); ok {
+		m[keyOf(m, "active")] = strings.EqualFold(active, "true")
+	}
+
+	patched := &User{}
+	if err := fromMap(m, patched); err != nil {
+		return nil, err
+	}
+	return s.ReplaceUser(ctx, requester, id, patched)
+}
+
+// DeleteUser deprovisions the user: the user is removed from the organization. The account of the user is disabled
+// and logged out when the user is not a member of any other organization.
+func (s *Service) DeleteUser(ctx context.Context, requester identity.Requester, id string) error {
+	orgID := requester.GetOrgID()
+	ou, err := s.getOrgUser(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	// the user is removed first so that the last administrator of the organization is not disabled
+	err = s.orgService.RemoveOrgUser(ctx, &org.RemoveOrgUserCommand{OrgID: orgID, UserID: ou.UserID})
+	if errors.Is(err, org.ErrLastOrgAdmin) {
+		return errMutability("cannot remove the last administrator of the organization")
+	}
+	if err != nil {
+		return err
+	}
+	otherOrgs, err := s.memberOfOtherOrgs(ctx, orgID, ou.UserID)
+	if err != nil {
+		return err
+	}
+	if !otherOrgs {
+		if err := s.setDisabled(ctx, ou.UserID, true); err != nil {
+			return err
+		}
+	}
+	if err := s.store.Delete(ctx, orgID, ResourceTypeUser, ou.UserID); err != nil {
+		return err
+	}
+	s.log.FromContext(ctx).Info("User deprovisioned", "orgID", orgID, "userID", ou.UserID, "login", ou.Login)
+	return nil
+}
+
+func (s *Service) ListGroups(ctx context.Context, requester identity.Requester, query ListQuery) (*ListResponse, error) {
+	filter, err := parseOptionalFilter(query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	orgID := requester.GetOrgID()
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        orgID,
+		Query:        searchHint(filter, "displayName"),
+		SignedInUser: requester,
+	})
+	if err != nil {
+		return nil, err
+	}
+	managed, err := s.store.List(ctx, orgID, ResourceTypeGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]any, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		var members []*team.TeamMemberDTO
+		// members are loaded if they are returned or used by the filter
+		if !query.ExcludeMembers || filter != nil {
+			members, err = s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: t.ID, SignedInUser: requester})
+			if err != nil {
+				return nil, err
+			}
+		}
+		g := s.toGroup(t, members, managed[t.ID])
+		ok, err := matches(filter, g)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if query.ExcludeMembers {
+			g.Members = nil
+		}
+		resources = append(resources, g)
+	}
+	return s.paginate(resources, query), nil
+}
+
+func (s *Service) GetGroup(ctx context.Context, requester identity.Requester, id string) (*Group, error) {
+	orgID := requester.GetOrgID()
+	t, err := s.getTeam(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: t.ID, SignedInUser: requester})
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.store.Get(ctx, orgID, ResourceTypeGroup, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toGroup(t, members, r), nil
+}
+
+func (s *Service) CreateGroup(ctx context.Context, requester identity.Requester, g *Group) (*Group, error) {
+	if strings.TrimSpace(g.DisplayName) == "" {
+		return nil, errInvalidValue("displayName is required")
+	}
+	orgID := requester.GetOrgID()
+	memberIDs, err := s.memberIDs(ctx, orgID, g.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.teamService.CreateTeam(ctx, g.DisplayName, "", orgID)
+	if errors.Is(err, team.ErrTeamNameTaken) {
+		return nil, errUniqueness("group %q already exists", g.DisplayName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Upsert(ctx, &resource{OrgID: orgID, ResourceType: ResourceTypeGroup, ResourceID: t.ID, ExternalID: g.ExternalID}); err != nil {
+		return nil, err
+	}
+	if err := s.syncMembers(ctx, orgID, t.ID, nil, memberIDs); err != nil {
+		return nil, err
+	}
+	s.log.FromContext(ctx).Info("Team provisioned", "orgID", orgID, "teamID", t.ID, "name", t.Name)
+	return s.GetGroup(ctx, requester, strconv.FormatInt(t.ID, 10))
+}
+
+// ReplaceGroup updates the name of the team and its members. Members that are not part of the group are removed from the team.
+func (s *Service) ReplaceGroup(ctx context.Context, requester identity.Requester, id string, g *Group) (*Group, error) {
+	if strings.TrimSpace(g.DisplayName) == "" {
+		return nil, errInvalidValue("displayName is required")
+	}
+	orgID := requester.GetOrgID()
+	t, err := s.getTeam(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := s.memberIDs(ctx, orgID, g.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Name != g.DisplayName {
+		err := s.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{ID: t.ID, OrgID: orgID, Name: g.DisplayName, Email: t.Email})
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return nil, errUniqueness("group %q already exists", g.DisplayName)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: t.ID, SignedInUser: requester})
+	if err != nil {
+		return nil, err
+	}
+	current := make([]int64, 0, len(members))
+	for _, m := range members {
+		current = append(current, m.UserID)
+	}
+	if err := s.syncMembers(ctx, orgID, t.ID, current, memberIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Upsert(ctx, &resource{OrgID: orgID, ResourceType: ResourceTypeGroup, ResourceID: t.ID, ExternalID: g.ExternalID}); err != nil {
+		return nil, err
+	}
+	return s.GetGroup(ctx, requester, id)
+}
+
+func (s *Service) PatchGroup(ctx context.Context, requester identity.Requester, id string, operations []PatchOperation) (*Group, error) {
+	current, err := s.GetGroup(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyPatch(m, operations); err != nil {
+		return nil, err
+	}
+	patched := &Group{}
+	if err := fromMap(m, patched); err != nil {
+		return nil, err
+	}
+	return s.ReplaceGroup(ctx, requester, id, patched)
+}
+
+func (s *Service) DeleteGroup(ctx context.Context, requester identity.Requester, id string) error {
+	orgID := requester.GetOrgID()
+	t, err := s.getTeam(ctx, requester, id)
+	if err != nil {
+		return err
+	}
+	if err := s.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: orgID, ID: t.ID}); err != nil {
+		return err
+	}
+	// clear associated team assignments, managed role and permissions
+	if err := s.acService.DeleteTeamPermissions(ctx, orgID, t.ID); err != nil {
+		return err
+	}
+	s.log.FromContext(ctx).Info("Team deprovisioned", "orgID", orgID, "teamID", t.ID, "name", t.Name)
+	return nil
+}
+
+func (s *Service) getOrgUser(ctx context.Context, orgID int64, id string) (*org.OrgUserDTO, error) {
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errNotFound(ResourceTypeUser, id)
+	}
+	result, err := s.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{OrgID: orgID, UserID: userID, DontEnforceAccessControl: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, ou := range result.OrgUsers {
+		if ou.UserID == userID {
+			return ou, nil
+		}
+	}
+	return nil, errNotFound(ResourceTypeUser, id)
+}
+
+func (s *Service) getTeam(ctx context.Context, requester identity.Requester, id string) (*team.TeamDTO, error) {
+	teamID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errNotFound(ResourceTypeGroup, id)
+	}
+	t, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: requester.GetOrgID(), ID: teamID, SignedInUser: requester})
+	if errors.Is(err, team.ErrTeamNotFound) {
+		return nil, errNotFound(ResourceTypeGroup, id)
+	}
+	return t, err
+}
+
+// setDisabled disables or enables the user. Disabled users are logged out.
+func (s *Service) setDisabled(ctx context.Context, userID int64, disabled bool) error {
+	if err := s.userService.Update(ctx, &user.UpdateUserCommand{UserID: userID, IsDisabled: &disabled}); err != nil {
+		return err
+	}
+	if disabled {
+		return s.tokenService.RevokeAllUserTokens(ctx, userID)
+	}
+	return nil
+}
+
+// checkActiveChange rejects changes of the active state of users that are members of other organizations, as
+// disabling or enabling their accounts would also affect those organizations.
+func (s *Service) checkActiveChange(ctx context.Context, orgID, userID int64) error {
+	otherOrgs, err := s.memberOfOtherOrgs(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if otherOrgs {
+		return errMutability("cannot change the active state of a user that is a member of other organizations")
+	}
+	return nil
+}
+
+// memberOfOtherOrgs checks whether the user is a member of organizations other than orgID.
+func (s *Service) memberOfOtherOrgs(ctx context.Context, orgID, userID int64) (bool, error) {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		if o.OrgID != orgID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memberIDs returns the IDs of the users of the members. Only users are supported as members, not nested groups.
+func (s *Service) memberIDs(ctx context.Context, orgID int64, members []MultiValued) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		if m.Type != "" && !strings.EqualFold(m.Type, ResourceTypeUser) {
+			return nil, errInvalidValue("member %q: only users can be members of groups", m.Value)
+		}
+		if _, err := s.getOrgUser(ctx, orgID, m.Value); err != nil {
+			return nil, errInvalidValue("member %q is not a user of the organization", m.Value)
+		}
+		userID, _ := strconv.ParseInt(m.Value, 10, 64)
+		ids = append(ids, userID)
+	}
+	return ids, nil
+}
+
+func (s *Service) syncMembers(ctx context.Context, orgID, teamID int64, current, desired []int64) error {
+	currentSet := make(map[int64]bool, len(current))
+	for _, id := range current {
+		currentSet[id] = true
+	}
+	desiredSet := make(map[int64]bool, len(desired))
+	for _, id := range desired {
+		desiredSet[id] = true
+	}
+
+	teamIDString := strconv.FormatInt(teamID, 10)
+	for id := range desiredSet {
+		if currentSet[id] {
+			continue
+		}
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: id}, teamIDString, teamPermissionMember); err != nil {
+			return err
+		}
+	}
+	for id := range currentSet {
+		if desiredSet[id] {
+			continue
+		}
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: id}, teamIDString, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type userAttributes struct {
+	login  string
+	email  string
+	name   string
+	role   org.RoleType
+	active bool
+}
+
+// userAttributes maps the SCIM attributes to the attributes of the Grafana user. On creation, users without role
+// get the role configured by auto_assign_org_role.
+func (s *Service) userAttributes(u *User, create bool) (*userAttributes, error) {
+	attrs := &userAttributes{login: strings.TrimSpace(u.UserName), active: u.Active == nil || *u.Active}
+	if attrs.login == "" {
+		return nil, errInvalidValue("userName is required")
+	}
+
+	for _, e := range u.Emails {
+		if attrs.email == "" || e.Primary {
+			attrs.email = e.Value
+		}
+	}
+	if attrs.email == "" && strings.Contains(attrs.login, "@") {
+		attrs.email = attrs.login
+	}
+
+	attrs.name = u.DisplayName
+	if attrs.name == "" && u.Name != nil {
+		attrs.name = util.StringsFallback2(u.Name.Formatted, strings.TrimSpace(u.Name.GivenName+" "+u.Name.FamilyName))
+	}
+
+	for _, r := range u.Roles {
+		if attrs.role == "" || r.Primary {
+			attrs.role = org.RoleType(r.Value)
+		}
+	}
+	if attrs.role != "" && !attrs.role.IsValid() {
+		return nil, errInvalidValue("invalid role %q", attrs.role)
+	}
+	if attrs.role == "" && create {
+		attrs.role = org.RoleType(s.cfg.AutoAssignOrgRole)
+	}
+	return attrs, nil
+}
+
+func (s *Service) toUser(ou *org.OrgUserDTO, r *resource, groups []MultiValued) *User {
+	active := !ou.IsDisabled
+	u := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          strconv.FormatInt(ou.UserID, 10),
+		UserName:    ou.Login,
+		DisplayName: ou.Name,
+		Active:      &active,
+		Roles:       []MultiValued{{Value: ou.Role, Primary: true}},
+		Groups:      groups,
+		Meta:        s.meta(ResourceTypeUser, ou.UserID, ou.Created, ou.Updated),
+	}
+	if ou.Name != "" {
+		u.Name = &Name{Formatted: ou.Name}
+	}
+	if ou.Email != "" {
+		u.Emails = []MultiValued{{Value: ou.Email, Type: "work", Primary: true}}
+	}
+	if r != nil {
+		u.ExternalID = r.ExternalID
+	}
+	return u
+}
+
+func (s *Service) toGroup(t *team.TeamDTO, members []*team.TeamMemberDTO, r *resource) *Group {
+	g := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Meta:        &Meta{ResourceType: ResourceTypeGroup, Location: s.location(ResourceTypeGroup, t.ID)},
+	}
+	for _, m := range members {
+		g.Members = append(g.Members, MultiValued{
+			Value:   strconv.FormatInt(m.UserID, 10),
+			Display: m.Login,
+			Type:    ResourceTypeUser,
+			Ref:     s.location(ResourceTypeUser, m.UserID),
+		})
+	}
+	if r != nil {
+		g.ExternalID = r.ExternalID
+		g.Meta.Created = &r.Created
+		g.Meta.LastModified = &r.Updated
+	}
+	return g
+}
+
+func (s *Service) meta(resourceType string, id int64, created, updated time.Time) *Meta {
+	m := &Meta{ResourceType: resourceType, Location: s.location(resourceType, id)}
+	if !created.IsZero() {
+		m.Created = &created
+	}
+	if !updated.IsZero() {
+		m.LastModified = &updated
+	}
+	return m
+}
+
+func (s *Service) location(resourceType string, id int64) string {
+	return strings.TrimSuffix(s.cfg.AppURL, "/") + "/api/scim/v2/" + resourceType + "s/" + strconv.FormatInt(id, 10)
+}
+
+// paginate returns the page of the resources. startIndex is 1-based as defined by RFC 7644 section 3.4.2.4.
+func (s *Service) paginate(resources []any, query ListQuery) *ListResponse {
+	count := query.Count
+	if count < 0 || count > s.cfg.SCIM.MaxResults {
+		count = s.cfg.SCIM.MaxResults
+	}
+	start := query.StartIndex
+	if start < 1 {
+		start = 1
+	}
+
+	page := []any{}
+	if start <= len(resources) {
+		end := start - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[start-1 : end]
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func parseOptionalFilter(expr string) (Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	return ParseFilter(expr)
+}
+
+func matches(filter Filter, resource any) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	m, err := toMap(resource)
+	if err != nil {
+		return false, err
+	}
+	return filter.Matches(m), nil
+}
+
+// searchHint returns the value of the filter if it compares one of the attributes for equality, for example
+// userName eq "jane". It narrows the users loaded from the database before the filter is evaluated.
+func searchHint(filter Filter, attrs ...string) string {
+	f, ok := filter.(*compareFilter)
+	if !ok || f.op != "eq" {
+		return ""
+	}
+	value, ok := f.value.(string)
+	if !ok {
+		return ""
+	}
+	path := strings.Join(f.path, ".")
+	for _, attr := range attrs {
+		if strings.EqualFold(path, attr) {
+			return value
+		}
+	}
+	return ""
+}
+
+// statusCode returns the HTTP status of the error returned by the service.
+func statusCode(err error) int {
+	var scimErr *Error
+	if errors.As(err, &scimErr) {
+		return scimErr.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
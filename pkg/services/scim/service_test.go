@@ -0,0 +1,534 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestPaginate(t *testing.T) {
+	s := &Service{cfg: &setting.Cfg{SCIM: setting.AuthSCIMSettings{MaxResults: 2}}}
+	resources := []any{"a", "b", "c"}
+
+	testCases := []struct {
+		desc     string
+		query    ListQuery
+		expected []any
+	}{
+		{desc: "missing count returns the maximum", query: ListQuery{Count: -1}, expected: []any{"a", "b"}},
+		{desc: "count is capped by the maximum", query: ListQuery{Count: 10}, expected: []any{"a", "b"}},
+		{desc: "count is applied after the start index", query: ListQuery{StartIndex: 2, Count: 1}, expected: []any{"b"}},
+		{desc: "count=0 returns no resources", query: ListQuery{Count: 0}, expected: []any{}},
+		{desc: "start index after the last resource returns no resources", query: ListQuery{StartIndex: 4, Count: -1}, expected: []any{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp := s.paginate(resources, tc.query)
+			require.Equal(t, tc.expected, resp.Resources)
+			require.Equal(t, len(tc.expected), resp.ItemsPerPage)
+			require.Equal(t, 3, resp.TotalResults)
+		})
+	}
+}
+
+func TestService_CreateUser(t *testing.T) {
+	newUser := func(userName, externalID string) *User {
+		return &User{Schemas: []string{SchemaUser}, UserName: userName, ExternalID: externalID, Emails: []MultiValued{{Value: userName + "@example.com", Primary: true}}}
+	}
+
+	t.Run("creates the user and adds it to the organization", func(t *testing.T) {
+		s, env := newTestService(t)
+
+		created, err := s.CreateUser(context.Background(), env.requester, newUser("jane", "00u1"))
+		require.NoError(t, err)
+		require.Equal(t, "jane", created.UserName)
+		require.Equal(t, "00u1", created.ExternalID)
+		require.Equal(t, []MultiValued{{Value: "Viewer", Primary: true}}, created.Roles)
+		require.Len(t, env.users.users, 1)
+		require.Equal(t, org.RoleViewer, env.orgs.roles[env.users.byLogin("jane").ID])
+	})
+
+	t.Run("adds existing users to the organization", func(t *testing.T) {
+		s, env := newTestService(t)
+		env.users.add(&user.User{Login: "jane", Email: "jane@example.com"})
+
+		created, err := s.CreateUser(context.Background(), env.requester, newUser("jane", ""))
+		require.NoError(t, err)
+		require.Equal(t, strconv.FormatInt(env.users.byLogin("jane").ID, 10), created.ID)
+		require.Len(t, env.users.users, 1)
+	})
+
+	t.Run("returns a conflict", func(t *testing.T) {
+		testCases := []struct {
+			desc  string
+			setup func(env *testEnv)
+			user  *User
+		}{
+			{
+				desc: "when the external ID is already provisioned",
+				setup: func(env *testEnv) {
+					u := env.users.add(&user.User{Login: "john"})
+					env.orgs.roles[u.ID] = org.RoleViewer
+					env.store.resources[resourceKey(1, ResourceTypeUser, u.ID)] = &resource{OrgID: 1, ResourceType: ResourceTypeUser, ResourceID: u.ID, ExternalID: "00u1"}
+				},
+				user: newUser("jane", "00u1"),
+			},
+			{
+				desc: "when the user is already a member of the organization",
+				setup: func(env *testEnv) {
+					u := env.users.add(&user.User{Login: "jane"})
+					env.orgs.roles[u.ID] = org.RoleEditor
+				},
+				user: newUser("jane", ""),
+			},
+			{
+				desc: "when the userName is used by a service account",
+				setup: func(env *testEnv) {
+					env.users.add(&user.User{Login: "jane", IsServiceAccount: true})
+				},
+				user: newUser("jane", ""),
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.desc, func(t *testing.T) {
+				s, env := newTestService(t)
+				tc.setup(env)
+				usersBefore := len(env.users.users)
+
+				_, err := s.CreateUser(context.Background(), env.requester, tc.user)
+				require.Equal(t, http.StatusConflict, statusCode(err))
+				require.Len(t, env.users.users, usersBefore)
+			})
+		}
+	})
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	t.Run("disables the user, revokes its sessions and removes it from the organization", func(t *testing.T) {
+		s, env := newTestService(t)
+		env.orgs.roles[env.users.add(&user.User{Login: "admin"}).ID] = org.RoleAdmin
+		created, err := s.CreateUser(context.Background(), env.requester, &User{UserName: "jane", ExternalID: "00u1"})
+		require.NoError(t, err)
+		userID := env.users.byLogin("jane").ID
+
+		require.NoError(t, s.DeleteUser(context.Background(), env.requester, created.ID))
+		require.True(t, env.users.byLogin("jane").IsDisabled)
+		require.Equal(t, []int64{userID}, env.revokedUserIDs)
+		require.NotContains(t, env.orgs.roles, userID)
+		require.Empty(t, env.store.resources)
+
+		_, err = s.GetUser(context.Background(), env.requester, created.ID)
+		require.Equal(t, http.StatusNotFound, statusCode(err))
+	})
+
+	t.Run("keeps the account of users that are members of other organizations", func(t *testing.T) {
+		s, env := newTestService(t)
+		u := env.users.add(&user.User{Login: "jane"})
+		env.orgs.roles[u.ID] = org.RoleViewer
+		env.orgs.otherOrgs[u.ID] = true
+
+		require.NoError(t, s.DeleteUser(context.Background(), env.requester, strconv.FormatInt(u.ID, 10)))
+		require.NotContains(t, env.orgs.roles, u.ID)
+		require.False(t, u.IsDisabled)
+		require.Empty(t, env.revokedUserIDs)
+	})
+
+	t.Run("keeps the last administrator of the organization", func(t *testing.T) {
+		s, env := newTestService(t)
+		admin := env.users.add(&user.User{Login: "admin"})
+		env.orgs.roles[admin.ID] = org.RoleAdmin
+
+		err := s.DeleteUser(context.Background(), env.requester, strconv.FormatInt(admin.ID, 10))
+		require.Equal(t, http.StatusBadRequest, statusCode(err))
+		require.False(t, admin.IsDisabled)
+		require.Empty(t, env.revokedUserIDs)
+		require.Contains(t, env.orgs.roles, admin.ID)
+	})
+
+	t.Run("returns not found for users of other organizations", func(t *testing.T) {
+		s, env := newTestService(t)
+		u := env.users.add(&user.User{Login: "jane"})
+
+		err := s.DeleteUser(context.Background(), env.requester, strconv.FormatInt(u.ID, 10))
+		require.Equal(t, http.StatusNotFound, statusCode(err))
+		require.False(t, u.IsDisabled)
+		require.Empty(t, env.revokedUserIDs)
+	})
+}
+
+func TestService_ReplaceUser(t *testing.T) {
+	t.Run("disables inactive users and revokes their sessions", func(t *testing.T) {
+		s, env := newTestService(t)
+		u := env.users.add(&user.User{Login: "jane"})
+		env.orgs.roles[u.ID] = org.RoleViewer
+
+		_, err := s.ReplaceUser(context.Background(), env.requester, strconv.FormatInt(u.ID, 10), &User{UserName: "jane", Active: util.Pointer(false)})
+		require.NoError(t, err)
+		require.True(t, u.IsDisabled)
+		require.Equal(t, []int64{u.ID}, env.revokedUserIDs)
+	})
+
+	t.Run("does not change the active state of users that are members of other organizations", func(t *testing.T) {
+		s, env := newTestService(t)
+		u := env.users.add(&user.User{Login: "jane"})
+		env.orgs.roles[u.ID] = org.RoleViewer
+		env.orgs.otherOrgs[u.ID] = true
+
+		_, err := s.ReplaceUser(context.Background(), env.requester, strconv.FormatInt(u.ID, 10), &User{UserName: "jane", Active: util.Pointer(false)})
+		require.Equal(t, http.StatusBadRequest, statusCode(err))
+		require.False(t, u.IsDisabled)
+		require.Empty(t, env.revokedUserIDs)
+	})
+}
+
+func TestService_Bulk(t *testing.T) {
+	bulk := func(t *testing.T, s *Service, env *testEnv, req BulkRequest) *BulkResponse {
+		t.Helper()
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/scim/v2/Bulk", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", ContentType)
+		c := &contextmodel.ReqContext{Context: &web.Context{Req: httpReq}, SignedInUser: env.requester}
+
+		resp, ok := s.bulkHandler(c).(*response.NormalResponse)
+		require.True(t, ok)
+		require.Equal(t, http.StatusOK, resp.Status())
+		result := &BulkResponse{}
+		require.NoError(t, json.Unmarshal(resp.Body(), result))
+		return result
+	}
+	statuses := func(resp *BulkResponse) []string {
+		result := make([]string, 0, len(resp.Operations))
+		for _, op := range resp.Operations {
+			result = append(result, op.Status)
+		}
+		return result
+	}
+	createUser := func(bulkID, userName string) BulkOperation {
+		return BulkOperation{Method: http.MethodPost, BulkID: bulkID, Path: "/Users", Data: map[string]any{"userName": userName}}
+	}
+
+	t.Run("runs the remaining operations after a failed operation", func(t *testing.T) {
+		s, env := newTestService(t)
+
+		resp := bulk(t, s, env, BulkRequest{Operations: []BulkOperation{
+			createUser("jane", "jane"),
+			createUser("jane-again", "jane"),
+			{Method: http.MethodPost, BulkID: "sre", Path: "/Groups", Data: map[string]any{
+				"displayName": "SRE",
+				"members":     []any{map[string]any{"value": "bulkId:jane"}},
+			}},
+			{Method: http.MethodPost, Path: "/Groups", Data: map[string]any{
+				"displayName": "Support",
+				"members":     []any{map[string]any{"value": "bulkId:jane-again"}},
+			}},
+		}})
+
+		require.Equal(t, []string{"201", "409", "201", "409"}, statuses(resp))
+		require.Equal(t, "uniqueness", resp.Operations[1].Response.(map[string]any)["scimType"])
+		require.Len(t, env.users.users, 1)
+		require.Len(t, env.teams.teams, 1)
+		for id := range env.teams.teams {
+			require.Equal(t, []int64{env.users.byLogin("jane").ID}, env.teams.members[id])
+		}
+	})
+
+	t.Run("stops after failOnErrors errors", func(t *testing.T) {
+		s, env := newTestService(t)
+		env.orgs.roles[env.users.add(&user.User{Login: "jane"}).ID] = org.RoleViewer
+
+		resp := bulk(t, s, env, BulkRequest{FailOnErrors: 1, Operations: []BulkOperation{
+			createUser("", "jane"),
+			createUser("", "john"),
+		}})
+
+		require.Equal(t, []string{"409"}, statuses(resp))
+		require.Nil(t, env.users.byLogin("john"))
+	})
+}
+
+type testEnv struct {
+	requester      *user.SignedInUser
+	store          *fakeStore
+	users          *fakeUserService
+	orgs           *fakeOrgService
+	teams          *fakeTeamService
+	revokedUserIDs []int64
+}
+
+func newTestService(t *testing.T) (*Service, *testEnv) {
+	t.Helper()
+	users := &fakeUserService{users: map[int64]*user.User{}}
+	teams := &fakeTeamService{teams: map[int64]*team.TeamDTO{}, members: map[int64][]int64{}}
+	env := &testEnv{
+		requester: &user.SignedInUser{OrgID: 1, UserID: 100, Login: "sa-scim", IsServiceAccount: true},
+		store:     &fakeStore{resources: map[string]*resource{}},
+		users:     users,
+		orgs:      &fakeOrgService{users: users, roles: map[int64]org.RoleType{}, otherOrgs: map[int64]bool{}},
+		teams:     teams,
+	}
+
+	tokenService := authtest.NewFakeUserAuthTokenService()
+	tokenService.RevokeAllUserTokensProvider = func(_ context.Context, userID int64) error {
+		env.revokedUserIDs = append(env.revokedUserIDs, userID)
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.AutoAssignOrgRole = string(org.RoleViewer)
+	cfg.SCIM = setting.AuthSCIMSettings{MaxBulkOperations: 10, MaxResults: 100}
+
+	return &Service{
+		cfg:                    cfg,
+		store:                  env.store,
+		userService:            env.users,
+		orgService:             env.orgs,
+		teamService:            env.teams,
+		teamPermissionsService: &fakeTeamPermissionsService{teams: teams},
+		tokenService:           tokenService,
+		log:                    log.New("scim.test"),
+	}, env
+}
+
+func resourceKey(orgID int64, resourceType string, resourceID int64) string {
+	return fmt.Sprintf("%d/%s/%d", orgID, resourceType, resourceID)
+}
+
+type fakeStore struct {
+	resources map[string]*resource
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, resourceType string, resourceID int64) (*resource, error) {
+	return f.resources[resourceKey(orgID, resourceType, resourceID)], nil
+}
+
+func (f *fakeStore) GetByExternalID(_ context.Context, orgID int64, resourceType string, externalID string) (*resource, error) {
+	for _, r := range f.resources {
+		if r.OrgID == orgID && r.ResourceType == resourceType && r.ExternalID == externalID {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeStore) List(_ context.Context, orgID int64, resourceType string) (map[int64]*resource, error) {
+	result := map[int64]*resource{}
+	for _, r := range f.resources {
+		if r.OrgID == orgID && r.ResourceType == resourceType {
+			result[r.ResourceID] = r
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) Upsert(_ context.Context, r *resource) error {
+	f.resources[resourceKey(r.OrgID, r.ResourceType, r.ResourceID)] = r
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, orgID int64, resourceType string, resourceID int64) error {
+	delete(f.resources, resourceKey(orgID, resourceType, resourceID))
+	return nil
+}
+
+type fakeUserService struct {
+	user.Service
+	users map[int64]*user.User
+}
+
+func (f *fakeUserService) add(u *user.User) *user.User {
+	u.ID = int64(len(f.users) + 1)
+	f.users[u.ID] = u
+	return u
+}
+
+func (f *fakeUserService) byLogin(login string) *user.User {
+	for _, u := range f.users {
+		if u.Login == login {
+			return u
+		}
+	}
+	return nil
+}
+
+func (f *fakeUserService) GetByLogin(_ context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
+	if u := f.byLogin(query.LoginOrEmail); u != nil {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (f *fakeUserService) GetByEmail(_ context.Context, query *user.GetUserByEmailQuery) (*user.User, error) {
+	for _, u := range f.users {
+		if u.Email != "" && u.Email == query.Email {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (f *fakeUserService) Create(_ context.Context, cmd *user.CreateUserCommand) (*user.User, error) {
+	if f.byLogin(cmd.Login) != nil {
+		return nil, user.ErrUserAlreadyExists
+	}
+	return f.add(&user.User{Login: cmd.Login, Email: cmd.Email, Name: cmd.Name, IsDisabled: cmd.IsDisabled}), nil
+}
+
+func (f *fakeUserService) Update(_ context.Context, cmd *user.UpdateUserCommand) error {
+	u, ok := f.users[cmd.UserID]
+	if !ok {
+		return user.ErrUserNotFound
+	}
+	if cmd.Login != "" {
+		u.Login, u.Email, u.Name = cmd.Login, cmd.Email, cmd.Name
+	}
+	if cmd.IsDisabled != nil {
+		u.IsDisabled = *cmd.IsDisabled
+	}
+	return nil
+}
+
+// fakeOrgService contains the users of the organization 1 and their roles, and the users that are also members of
+// the organization 2.
+type fakeOrgService struct {
+	org.Service
+	users     *fakeUserService
+	roles     map[int64]org.RoleType
+	otherOrgs map[int64]bool
+}
+
+func (f *fakeOrgService) GetUserOrgList(_ context.Context, query *org.GetUserOrgListQuery) ([]*org.UserOrgDTO, error) {
+	result := []*org.UserOrgDTO{}
+	if role, ok := f.roles[query.UserID]; ok {
+		result = append(result, &org.UserOrgDTO{OrgID: 1, Role: role})
+	}
+	if f.otherOrgs[query.UserID] {
+		result = append(result, &org.UserOrgDTO{OrgID: 2, Role: org.RoleViewer})
+	}
+	return result, nil
+}
+
+func (f *fakeOrgService) SearchOrgUsers(_ context.Context, query *org.SearchOrgUsersQuery) (*org.SearchOrgUsersQueryResult, error) {
+	result := &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{}}
+	for id, role := range f.roles {
+		if query.UserID != 0 && query.UserID != id {
+			continue
+		}
+		u := f.users.users[id]
+		result.OrgUsers = append(result.OrgUsers, &org.OrgUserDTO{OrgID: 1, UserID: id, Login: u.Login, Email: u.Email, Name: u.Name, Role: string(role), IsDisabled: u.IsDisabled})
+	}
+	result.TotalCount = int64(len(result.OrgUsers))
+	return result, nil
+}
+
+func (f *fakeOrgService) AddOrgUser(_ context.Context, cmd *org.AddOrgUserCommand) error {
+	if _, ok := f.roles[cmd.UserID]; ok {
+		return org.ErrOrgUserAlreadyAdded
+	}
+	f.roles[cmd.UserID] = cmd.Role
+	return nil
+}
+
+func (f *fakeOrgService) UpdateOrgUser(_ context.Context, cmd *org.UpdateOrgUserCommand) error {
+	f.roles[cmd.UserID] = cmd.Role
+	return nil
+}
+
+func (f *fakeOrgService) RemoveOrgUser(_ context.Context, cmd *org.RemoveOrgUserCommand) error {
+	if f.roles[cmd.UserID] == org.RoleAdmin {
+		admins := 0
+		for _, role := range f.roles {
+			if role == org.RoleAdmin {
+				admins++
+			}
+		}
+		if admins == 1 {
+			return org.ErrLastOrgAdmin
+		}
+	}
+	delete(f.roles, cmd.UserID)
+	return nil
+}
+
+type fakeTeamService struct {
+	team.Service
+	teams   map[int64]*team.TeamDTO
+	members map[int64][]int64
+}
+
+func (f *fakeTeamService) CreateTeam(_ context.Context, name, email string, orgID int64) (team.Team, error) {
+	for _, t := range f.teams {
+		if t.Name == name {
+			return team.Team{}, team.ErrTeamNameTaken
+		}
+	}
+	id := int64(len(f.teams) + 1)
+	f.teams[id] = &team.TeamDTO{ID: id, OrgID: orgID, Name: name, Email: email}
+	return team.Team{ID: id, OrgID: orgID, Name: name, Email: email}, nil
+}
+
+func (f *fakeTeamService) GetTeamByID(_ context.Context, query *team.GetTeamByIDQuery) (*team.TeamDTO, error) {
+	if t, ok := f.teams[query.ID]; ok {
+		return t, nil
+	}
+	return nil, team.ErrTeamNotFound
+}
+
+func (f *fakeTeamService) GetTeamsByUser(_ context.Context, query *team.GetTeamsByUserQuery) ([]*team.TeamDTO, error) {
+	result := []*team.TeamDTO{}
+	for id, members := range f.members {
+		if slices.Contains(members, query.UserID) {
+			result = append(result, f.teams[id])
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeTeamService) GetTeamMembers(_ context.Context, query *team.GetTeamMembersQuery) ([]*team.TeamMemberDTO, error) {
+	result := []*team.TeamMemberDTO{}
+	for _, userID := range f.members[query.TeamID] {
+		result = append(result, &team.TeamMemberDTO{OrgID: query.OrgID, TeamID: query.TeamID, UserID: userID})
+	}
+	return result, nil
+}
+
+type fakeTeamPermissionsService struct {
+	accesscontrol.TeamPermissionsService
+	teams *fakeTeamService
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, u accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	teamID, err := strconv.ParseInt(resourceID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	members := slices.DeleteFunc(f.teams.members[teamID], func(id int64) bool { return id == u.ID })
+	if permission != "" {
+		members = append(members, u.ID)
+	}
+	f.teams.members[teamID] = members
+	return &accesscontrol.ResourcePermission{}, nil
+}
@@ -0,0 +1,103 @@
+package scim
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// resource tracks the users and teams managed by SCIM: their external ID and the time they were provisioned.
+type resource struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	ResourceType string    `xorm:"resource_type"`
+	ResourceID   int64     `xorm:"resource_id"`
+	ExternalID   string    `xorm:"external_id"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (resource) TableName() string {
+	return "scim_resource"
+}
+
+type store interface {
+	Get(ctx context.Context, orgID int64, resourceType string, resourceID int64) (*resource, error)
+	GetByExternalID(ctx context.Context, orgID int64, resourceType string, externalID string) (*resource, error)
+	List(ctx context.Context, orgID int64, resourceType string) (map[int64]*resource, error)
+	Upsert(ctx context.Context, r *resource) error
+	Delete(ctx context.Context, orgID int64, resourceType string, resourceID int64) error
+}
+
+type xormStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+func (xs *xormStore) Get(ctx context.Context, orgID int64, resourceType string, resourceID int64) (*resource, error) {
+	return xs.get(ctx, "org_id = ? AND resource_type = ? AND resource_id = ?", orgID, resourceType, resourceID)
+}
+
+func (xs *xormStore) GetByExternalID(ctx context.Context, orgID int64, resourceType string, externalID string) (*resource, error) {
+	return xs.get(ctx, "org_id = ? AND resource_type = ? AND external_id = ?", orgID, resourceType, externalID)
+}
+
+// get returns nil if the resource is not managed by SCIM.
+func (xs *xormStore) get(ctx context.Context, where string, args ...any) (*resource, error) {
+	var result *resource
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		r := resource{}
+		has, err := sess.Where(where, args...).Get(&r)
+		if err != nil {
+			return err
+		}
+		if has {
+			result = &r
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (xs *xormStore) List(ctx context.Context, orgID int64, resourceType string) (map[int64]*resource, error) {
+	result := map[int64]*resource{}
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var resources []*resource
+		if err := sess.Where("org_id = ? AND resource_type = ?", orgID, resourceType).Find(&resources); err != nil {
+			return err
+		}
+		for _, r := range resources {
+			result[r.ResourceID] = r
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (xs *xormStore) Upsert(ctx context.Context, r *resource) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := resource{}
+		has, err := sess.Where("org_id = ? AND resource_type = ? AND resource_id = ?", r.OrgID, r.ResourceType, r.ResourceID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		r.Updated = xs.now()
+		if !has {
+			r.Created = r.Updated
+			_, err = sess.Insert(r)
+			return err
+		}
+		r.ID = existing.ID
+		r.Created = existing.Created
+		_, err = sess.ID(existing.ID).AllCols().Update(r)
+		return err
+	})
+}
+
+func (xs *xormStore) Delete(ctx context.Context, orgID int64, resourceType string, resourceID int64) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM scim_resource WHERE org_id = ? AND resource_type = ? AND resource_id = ?", orgID, resourceType, resourceID)
+		return err
+	})
+}
@@ -7,12 +7,14 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
-// ResourceFilter checks if a given a uid (resource identifier) check if we have the requested permission
-type ResourceFilter func(kind entityKind, uid, parentUID string) bool
+// ResourceFilter checks if a given a uid (resource identifier) check if we have the requested permission. The
+// dsUIDs are the data sources queried by the resource, they are only set for alert rules.
+type ResourceFilter func(kind entityKind, uid, parentUID string, dsUIDs ...string) bool
 
 // FutureAuthService eventually implemented by the security service
 type FutureAuthService interface {
@@ -30,7 +32,8 @@ type simpleAuthService struct {
 
 func (a *simpleAuthService) GetDashboardReadFilter(ctx context.Context, orgID int64, user *user.SignedInUser) (ResourceFilter, error) {
 	canReadDashboard, canReadFolder := accesscontrol.Checker(user, dashboards.ActionDashboardsRead), accesscontrol.Checker(user, dashboards.ActionFoldersRead)
-	return func(kind entityKind, uid, parent string) bool {
+	canReadAlertRule, canQueryDatasource := accesscontrol.Checker(user, accesscontrol.ActionAlertingRuleRead), accesscontrol.Checker(user, datasources.ActionQuery)
+	return func(kind entityKind, uid, parent string, dsUIDs ...string) bool {
 		if kind == entityKindFolder {
 			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, uid, a.folderService)
 			if err != nil {
@@ -46,6 +49,22 @@ func (a *simpleAuthService) GetDashboardReadFilter(ctx context.Context, orgID in
 			scopes = append(scopes, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
 			scopes = append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent))
 			return canReadDashboard(scopes...)
+		} else if kind == entityKindAlertRule {
+			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, parent, a.folderService)
+			if err != nil {
+				a.logger.Debug("Could not retrieve inherited folder scopes:", "err", err)
+			}
+			scopes = append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent))
+			if !canReadAlertRule(scopes...) {
+				return false
+			}
+			// Same as ngalert, the rule and its queries can only be read with query access to all of its data sources
+			for _, dsUID := range dsUIDs {
+				if !canQueryDatasource(datasources.ScopeProvider.GetResourceScopeUID(dsUID)) {
+					return false
+				}
+			}
+			return true
 		}
 		return false
 	}, nil
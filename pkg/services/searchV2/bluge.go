@@ -33,11 +33,16 @@ const (
 	documentFieldTransformer = "transformer"
 	documentFieldDSUID       = "ds_uid"
 	documentFieldDSType      = "ds_type"
+	documentFieldDescription = "description"
+	documentFieldQuery       = "query" // text of the panel or alert rule queries
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
 )
 
-func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
+// alertRuleDocIDPrefix prevents alert rule documents from colliding with dashboards sharing the same UID.
+const alertRuleDocIDPrefix = "alertrule:"
+
+func initOrgIndex(dashboards []dashboard, alertRules []alertRule, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
 	dashboardWriter, err := bluge.OpenWriter(bluge.InMemoryOnlyConfig())
 	if err != nil {
 		return nil, fmt.Errorf("error opening writer: %v", err)
@@ -120,6 +125,14 @@ func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDas
 		}
 	}
 
+	// Then each alert rule, they are located in a folder.
+	for _, rule := range alertRules {
+		batch.Insert(getAlertRuleDoc(rule))
+		if err := flushIfRequired(false); err != nil {
+			return nil, err
+		}
+	}
+
 	// Flush docs in batch with force as we are in the end.
 	if err := flushIfRequired(true); err != nil {
 		return nil, err
@@ -160,6 +173,10 @@ func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 		AddField(bluge.NewDateTimeField(DocumentFieldCreatedAt, dash.created).Sortable().StoreValue()).
 		AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, dash.updated).Sortable().StoreValue())
 
+	for _, panelQueries := range dash.queries {
+		addQueryFields(doc, panelQueries)
+	}
+
 	// dashboards only use the key part of labels
 	for k := range dash.summary.Labels {
 		doc.AddField(bluge.NewKeywordField(documentFieldTag, k).
@@ -206,6 +223,7 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 		doc := newSearchDocument(panel.UID, panel.Name, panel.Description, url).
 			AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue()).
 			AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindPanel)).Aggregatable().StoreValue()) // likely want independent index for this
+		addQueryFields(doc, dash.queries[int64(panelId)])
 
 		for _, ref := range panel.References {
 			switch ref.Family {
@@ -238,6 +256,31 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 	return docs
 }
 
+func getAlertRuleDoc(rule alertRule) *bluge.Document {
+	url := fmt.Sprintf("/alerting/grafana/%s/view", rule.uid)
+
+	doc := newSearchDocument(alertRuleDocIDPrefix+rule.uid, rule.title, rule.description, url).
+		AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindAlertRule)).Aggregatable().StoreValue()).
+		AddField(bluge.NewKeywordField(documentFieldLocation, rule.folderUID).Aggregatable().StoreValue()).
+		AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, rule.updated).Sortable().StoreValue())
+
+	for _, dsUID := range rule.dsUIDs {
+		doc.AddField(bluge.NewKeywordField(documentFieldDSUID, dsUID).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+	addQueryFields(doc, rule.queries)
+
+	return doc
+}
+
+func addQueryFields(doc *bluge.Document, queries []string) {
+	for _, q := range queries {
+		doc.AddField(bluge.NewTextField(documentFieldQuery, q).StoreValue().SearchTermPositions())
+	}
+}
+
 // Names need to be indexed a few ways to support key features
 func newSearchDocument(uid string, name string, descr string, url string) *bluge.Document {
 	doc := bluge.NewDocument(uid)
@@ -252,6 +295,9 @@ func newSearchDocument(uid string, name string, descr string, url string) *bluge
 			doc.AddField(bluge.NewKeywordField(documentFieldName_sort, sortStr).Sortable())
 		}
 	}
+	if descr != "" {
+		doc.AddField(bluge.NewTextField(documentFieldDescription, descr))
+	}
 	if url != "" {
 		doc.AddField(bluge.NewKeywordField(documentFieldURL, url).StoreValue())
 	}
@@ -398,6 +444,9 @@ func doSearchQuery(
 		}
 		fullQuery.AddMust(bq)
 		hasConstraints = true
+	} else {
+		// Alert rules are only returned when explicitly requested
+		fullQuery.AddMustNot(bluge.NewTermQuery(string(entityKindAlertRule)).SetField(documentFieldKind))
 	}
 
 	// Explicit UID lookup (stars etc)
@@ -447,6 +496,14 @@ func doSearchQuery(
 		hasConstraints = true
 	}
 
+	// Query text (PromQL, LogQL, SQL...)
+	if q.QueryText != "" {
+		fullQuery.AddMust(bluge.NewMatchQuery(q.QueryText).
+			SetField(documentFieldQuery).
+			SetOperator(bluge.MatchQueryOperatorAnd)) // all terms must match
+		hasConstraints = true
+	}
+
 	isMatchAllQuery := q.Query == "*" || q.Query == ""
 	if isMatchAllQuery {
 		if !hasConstraints {
@@ -466,6 +523,11 @@ func doSearchQuery(
 				SetAnalyzer(ngramQueryAnalyzer).SetBoost(1))
 		}
 
+		bq.AddShould(bluge.NewMatchQuery(q.Query).
+			SetField(documentFieldDescription).
+			SetOperator(bluge.MatchQueryOperatorAnd).
+			SetBoost(0.5))
+
 		fullQuery.AddMust(bq)
 	}
 
@@ -514,6 +576,7 @@ func doSearchQuery(
 	fTags := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
 	fDSUIDs := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
 	fExplain := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
+	fHighlight := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
 
 	fScore.Name = "score"
 	fUID.Name = "uid"
@@ -530,11 +593,15 @@ func doSearchQuery(
 	fDSUIDs.Name = "ds_uid"
 	fTags.Name = "tags"
 	fExplain.Name = "explain"
+	fHighlight.Name = "highlight"
 
 	frame := data.NewFrame("Query results", fKind, fUID, fName, fPType, fURL, fTags, fDSUIDs, fLocation)
 	if q.Explain {
 		frame.Fields = append(frame.Fields, fScore, fExplain)
 	}
+	if q.QueryText != "" {
+		frame.Fields = append(frame.Fields, fHighlight)
+	}
 	frame.SetMeta(&data.FrameMeta{
 		Type:   "search-results",
 		Custom: header,
@@ -556,6 +623,7 @@ func doSearchQuery(
 		loc := ""
 		var dsUIDs []string
 		var tags []string
+		var queries []string
 
 		err = match.VisitStoredFields(func(field string, value []byte) bool {
 			switch field {
@@ -575,6 +643,8 @@ func doSearchQuery(
 				dsUIDs = append(dsUIDs, string(value))
 			case documentFieldTag:
 				tags = append(tags, string(value))
+			case documentFieldQuery:
+				queries = append(queries, string(value))
 			default:
 				ext(field, value)
 			}
@@ -586,6 +656,10 @@ func doSearchQuery(
 			return response
 		}
 
+		if kind == string(entityKindAlertRule) {
+			uid = strings.TrimPrefix(uid, alertRuleDocIDPrefix)
+		}
+
 		fKind.Append(kind)
 		fUID.Append(uid)
 		fPType.Append(ptype)
@@ -616,6 +690,16 @@ func doSearchQuery(
 		jsb := json.RawMessage(js)
 		fDSUIDs.Append(jsb)
 
+		if q.QueryText != "" {
+			if highlighted := highlightQueries(queries, q.QueryText); len(highlighted) > 0 {
+				js, _ := json.Marshal(highlighted)
+				jsb := json.RawMessage(js)
+				fHighlight.Append(&jsb)
+			} else {
+				fHighlight.Append(nil)
+			}
+		}
+
 		if q.Explain {
 			if isMatchAllQuery {
 				fScore.Append(float64(fieldLen + q.From))
@@ -26,18 +26,19 @@ const (
 	entityKindFolder     entityKind = entity.StandardKindFolder
 	entityKindDatasource entityKind = entity.StandardKindDataSource
 	entityKindQuery      entityKind = entity.StandardKindQuery
+	entityKindAlertRule  entityKind = entity.StandardKindAlertRule
 )
 
 func (r entityKind) IsValid() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r == entityKindAlertRule
 }
 
 func (r entityKind) supportsAuthzCheck() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r == entityKindAlertRule
 }
 
 var (
-	permissionFilterFields                 = []string{documentFieldUID, documentFieldKind, documentFieldLocation}
+	permissionFilterFields                 = []string{documentFieldUID, documentFieldKind, documentFieldLocation, documentFieldDSUID}
 	panelIdFieldRegex                      = regexp.MustCompile(`^(.*)#([0-9]{1,4})$`)
 	panelIdFieldDashboardUidSubmatchIndex  = 1
 	panelIdFieldPanelIdSubmatchIndex       = 2
@@ -66,7 +67,7 @@ func (q *PermissionFilter) logAccessDecision(decision bool, kind any, id string,
 	}
 }
 
-func (q *PermissionFilter) canAccess(kind entityKind, id, location string) bool {
+func (q *PermissionFilter) canAccess(kind entityKind, id, location string, dsUIDs []string) bool {
 	if !kind.supportsAuthzCheck() {
 		q.logAccessDecision(false, kind, id, "entityDoesNotSupportAuthz")
 		return false
@@ -79,6 +80,11 @@ func (q *PermissionFilter) canAccess(kind entityKind, id, location string) bool
 		decision := q.filter(kind, id, location)
 		q.logAccessDecision(decision, kind, id, "resourceFilter")
 		return decision
+	case entityKindAlertRule:
+		// Location is <folder_uid>, the data sources of the rule queries are checked too
+		decision := q.filter(kind, strings.TrimPrefix(id, alertRuleDocIDPrefix), location, dsUIDs...)
+		q.logAccessDecision(decision, kind, id, "resourceFilter")
+		return decision
 	case entityKindPanel:
 		matches := panelIdFieldRegex.FindStringSubmatch(id)
 		submatchCount := len(matches)
@@ -116,6 +122,7 @@ func (q *PermissionFilter) Searcher(i search.Reader, options search.SearcherOpti
 	}
 	return searcher.NewFilteringSearcher(s, func(d *search.DocumentMatch) bool {
 		var kind, id, location string
+		var dsUIDs []string
 		err := dvReader.VisitDocumentValues(d.Number, func(field string, term []byte) {
 			if field == documentFieldKind {
 				kind = string(term)
@@ -123,6 +130,8 @@ func (q *PermissionFilter) Searcher(i search.Reader, options search.SearcherOpti
 				id = string(term)
 			} else if field == documentFieldLocation {
 				location = string(term)
+			} else if field == documentFieldDSUID {
+				dsUIDs = append(dsUIDs, string(term))
 			}
 		})
 		if err != nil {
@@ -136,6 +145,6 @@ func (q *PermissionFilter) Searcher(i search.Reader, options search.SearcherOpti
 			return false
 		}
 
-		return q.canAccess(e, id, location)
+		return q.canAccess(e, id, location, dsUIDs)
 	}), err
 }
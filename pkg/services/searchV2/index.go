@@ -3,6 +3,7 @@ package searchV2
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -35,6 +36,10 @@ type dashboardLoader interface {
 	// return dashboard with specified UID or empty slice if not found (this is required
 	// to apply partial update).
 	LoadDashboards(ctx context.Context, orgID int64, dashboardUID string) ([]dashboard, error)
+
+	// LoadAlertRules returns the Grafana managed alert rules of the organization. If ruleUID
+	// is not empty – then only return the rule with specified UID or empty slice if not found.
+	LoadAlertRules(ctx context.Context, orgID int64, ruleUID string) ([]alertRule, error)
 }
 
 type eventStore interface {
@@ -57,6 +62,19 @@ type dashboard struct {
 
 	// Use generic structure
 	summary *entity.EntitySummary
+
+	// Text of the panel queries, by panel ID
+	queries map[int64][]string
+}
+
+type alertRule struct {
+	uid         string
+	title       string
+	folderUID   string
+	description string
+	queries     []string
+	dsUIDs      []string
+	updated     time.Time
 }
 
 // buildSignal is sent when search index is accessed in organization for which
@@ -489,14 +507,21 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	}
 	i.logger.Info("Finish loading org dashboards", "elapsed", orgSearchIndexLoadTime, "orgId", orgID)
 
+	alertRules, err := i.loader.LoadAlertRules(ctx, orgID, "")
+	if err != nil {
+		return 0, fmt.Errorf("error loading alert rules: %w", err)
+	}
+	orgSearchIndexLoadTime = time.Since(started)
+
 	dashboardExtender := i.extender.GetDashboardExtender(orgID)
 
 	_, initOrgIndexSpan := i.tracer.Start(ctx, "searchV2 buildOrgIndex init org index", trace.WithAttributes(
 		attribute.Int64("org_id", orgID),
 		attribute.Int("dashboardCount", len(dashboards)),
+		attribute.Int("alertRuleCount", len(alertRules)),
 	))
 
-	index, err := initOrgIndex(dashboards, i.logger, dashboardExtender)
+	index, err := initOrgIndex(dashboards, alertRules, i.logger, dashboardExtender)
 
 	initOrgIndexSpan.End()
 
@@ -511,7 +536,8 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 			"orgSearchIndexLoadTime", orgSearchIndexLoadTime,
 			"orgSearchIndexBuildTime", orgSearchIndexBuildTime,
 			"orgSearchIndexTotalTime", orgSearchIndexTotalTime,
-			"orgSearchDashboardCount", len(dashboards))...)
+			"orgSearchDashboardCount", len(dashboards),
+			"orgSearchAlertRuleCount", len(alertRules))...)
 
 	i.mu.Lock()
 	if oldIndex, ok := i.perOrgIndex[orgID]; ok {
@@ -654,6 +680,10 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	}
 	i.mu.Unlock()
 
+	if kind == store.EntityTypeAlertRule {
+		return i.applyAlertRuleEvent(ctx, orgID, uid)
+	}
+
 	// Both dashboard and folder share same DB table.
 	dbDashboards, err := i.loader.LoadDashboards(ctx, orgID, uid)
 	if err != nil {
@@ -688,6 +718,31 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	return nil
 }
 
+func (i *searchIndex) applyAlertRuleEvent(ctx context.Context, orgID int64, uid string) error {
+	rules, err := i.loader.LoadAlertRules(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	index, ok := i.perOrgIndex[orgID]
+	if !ok {
+		// Skip event for org not yet fully indexed.
+		return nil
+	}
+
+	writer := index.writerForIndex(indexTypeDashboard)
+	if len(rules) == 0 {
+		batch := bluge.NewBatch()
+		batch.Delete(bluge.NewDocument(alertRuleDocIDPrefix + uid).ID())
+		return writer.Batch(batch)
+	}
+	doc := getAlertRuleDoc(rules[0])
+	return writer.Update(doc.ID(), doc)
+}
+
 func (i *searchIndex) removeDashboard(_ context.Context, index *orgIndex, dashboardUID string) error {
 	dashboardLocation, ok, err := getDashboardLocation(index, dashboardUID)
 	if err != nil {
@@ -957,6 +1012,7 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 				created:  row.Created,
 				updated:  row.Updated,
 				summary:  summary,
+				queries:  extractPanelQueries(row.Data),
 			})
 		}
 		readDashboardSpan.End()
@@ -965,6 +1021,71 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 	return dashboards, err
 }
 
+func (l sqlDashboardLoader) LoadAlertRules(ctx context.Context, orgID int64, ruleUID string) ([]alertRule, error) {
+	ctx, span := l.tracer.Start(ctx, "sqlDashboardLoader LoadAlertRules", trace.WithAttributes(
+		attribute.Int64("orgID", orgID),
+	))
+	defer span.End()
+
+	rows := make([]*alertRuleQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("alert_rule").
+			Where("org_id = ?", orgID)
+
+		if ruleUID != "" {
+			sess.Where("uid = ?", ruleUID)
+		}
+
+		sess.Cols("uid", "title", "namespace_uid", "data", "annotations", "updated")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]alertRule, 0, len(rows))
+	for _, row := range rows {
+		rule := alertRule{
+			uid:       row.UID,
+			title:     row.Title,
+			folderUID: row.NamespaceUID,
+			updated:   row.Updated,
+		}
+
+		var annotations map[string]string
+		if row.Annotations != "" {
+			if err := json.Unmarshal([]byte(row.Annotations), &annotations); err != nil {
+				l.logger.Warn("Error indexing alert rule annotations", "error", err, "ruleUID", row.UID)
+			}
+		}
+		rule.description = annotations["description"]
+
+		var queries []struct {
+			DatasourceUID string         `json:"datasourceUid"`
+			Model         map[string]any `json:"model"`
+		}
+		if err := json.Unmarshal([]byte(row.Data), &queries); err != nil {
+			l.logger.Warn("Error indexing alert rule queries", "error", err, "ruleUID", row.UID)
+		}
+		for _, q := range queries {
+			// Server side expressions reference the other queries, they are not indexed
+			if q.DatasourceUID == expressionDatasourceUID {
+				continue
+			}
+			if q.DatasourceUID != "" && !stringInSlice(q.DatasourceUID, rule.dsUIDs) {
+				rule.dsUIDs = append(rule.dsUIDs, q.DatasourceUID)
+			}
+			if text := queryText(q.Model); text != "" {
+				rule.queries = append(rule.queries, text)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
 func newFolderIDLookup(sql db.DB) folderUIDLookup {
 	return func(ctx context.Context, folderID int64) (string, error) {
 		uid := ""
@@ -992,3 +1113,12 @@ type dashboardQueryResult struct {
 	Created  time.Time
 	Updated  time.Time
 }
+
+type alertRuleQueryResult struct {
+	UID          string `xorm:"uid"`
+	Title        string
+	NamespaceUID string `xorm:"namespace_uid"`
+	Data         string
+	Annotations  string
+	Updated      time.Time
+}
@@ -2,6 +2,7 @@ package searchV2
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -14,27 +15,37 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 type testDashboardLoader struct {
 	dashboards []dashboard
+	alertRules []alertRule
 }
 
 func (t *testDashboardLoader) LoadDashboards(_ context.Context, _ int64, _ string) ([]dashboard, error) {
 	return t.dashboards, nil
 }
 
+func (t *testDashboardLoader) LoadAlertRules(_ context.Context, _ int64, _ string) ([]alertRule, error) {
+	return t.alertRules, nil
+}
+
 var testLogger = log.New("index-test-logger")
 
-var testAllowAllFilter = func(kind entityKind, uid, parent string) bool {
+var testAllowAllFilter = func(kind entityKind, uid, parent string, dsUIDs ...string) bool {
 	return true
 }
 
-var testDisallowAllFilter = func(kind entityKind, uid, parent string) bool {
+var testDisallowAllFilter = func(kind entityKind, uid, parent string, dsUIDs ...string) bool {
 	return false
 }
 
@@ -548,6 +559,128 @@ func TestDashboardIndex_Panels(t *testing.T) {
 	})
 }
 
+var dashboardsWithQueries = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		summary: &entity.EntitySummary{
+			Name: "HTTP",
+			Nested: []*entity.EntitySummary{
+				newNestedPanel(1, 1, "Requests"),
+				newNestedPanel(2, 1, "Errors"),
+			},
+		},
+		queries: map[int64][]string{
+			1: {`sum(rate(http_requests_total[5m]))`},
+			2: {`sum(rate(http_requests_total{status="500"}[5m]))`, `{app="api"} |= "error"`},
+		},
+	},
+}
+
+func TestDashboardIndex_QueryText(t *testing.T) {
+	t.Run("query-text-matches-panels", func(t *testing.T) {
+		index := initTestOrgIndexFromDashes(t, dashboardsWithQueries)
+		resp := doSearchQuery(
+			context.Background(), testLogger, index, testAllowAllFilter,
+			DashboardQuery{QueryText: "http_requests_total", Kind: []string{string(entityKindPanel)}},
+			&NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok, fmt.Sprintf("actual type: %T", resp.Frames[0].Meta.Custom))
+		require.Equal(t, uint64(2), custom.Count)
+	})
+
+	t.Run("query-text-highlights-matching-queries", func(t *testing.T) {
+		index := initTestOrgIndexFromDashes(t, dashboardsWithQueries)
+		resp := doSearchQuery(
+			context.Background(), testLogger, index, testAllowAllFilter,
+			DashboardQuery{QueryText: "error", Kind: []string{string(entityKindPanel)}},
+			&NoopQueryExtender{}, "")
+		frame := resp.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+
+		uid, _ := frame.FieldByName("uid")
+		require.Equal(t, "1#2", uid.At(0))
+		highlight, _ := frame.FieldByName("highlight")
+		require.NotNil(t, highlight)
+		require.JSONEq(t, `["{app=\"api\"} |= \"<mark>error</mark>\""]`, string(*highlight.At(0).(*json.RawMessage)))
+	})
+}
+
+func TestDashboardIndex_AlertRules(t *testing.T) {
+	loader := &testDashboardLoader{
+		dashboards: dashboardsWithQueries,
+		alertRules: []alertRule{
+			{
+				uid:       "rule1",
+				title:     "High error rate",
+				folderUID: "f1",
+				queries:   []string{`sum(rate(http_requests_total{status="500"}[5m]))`},
+				dsUIDs:    []string{"prometheus"},
+			},
+		},
+	}
+	index := newSearchIndex(loader, &store.MockEntityEventsService{}, &NoopDocumentExtender{}, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	_, err := index.buildOrgIndex(context.Background(), testOrgID)
+	require.NoError(t, err)
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+
+	search := func(q DashboardQuery) *data.Frame {
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter, q, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		return resp.Frames[0]
+	}
+
+	t.Run("alert rules are only returned when requested", func(t *testing.T) {
+		require.Equal(t, 0, search(DashboardQuery{Query: "High error"}).Rows())
+
+		frame := search(DashboardQuery{Query: "High error", Kind: []string{string(entityKindAlertRule)}})
+		require.Equal(t, 1, frame.Rows())
+		uid, _ := frame.FieldByName("uid")
+		require.Equal(t, "rule1", uid.At(0))
+		url, _ := frame.FieldByName("url")
+		require.Equal(t, "/alerting/grafana/rule1/view", url.At(0))
+	})
+
+	t.Run("alert rules match query text and datasource", func(t *testing.T) {
+		frame := search(DashboardQuery{QueryText: "status 500", Kind: []string{string(entityKindAlertRule), string(entityKindPanel)}})
+		require.Equal(t, 2, frame.Rows())
+
+		frame = search(DashboardQuery{Datasource: "prometheus", Kind: []string{string(entityKindAlertRule)}})
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("alert rules require query access to their data sources", func(t *testing.T) {
+		auth := &simpleAuthService{folderService: foldertest.NewFakeService(), logger: testLogger}
+		signedInUser := func(dsScopes ...string) *user.SignedInUser {
+			return &user.SignedInUser{OrgID: testOrgID, Permissions: map[int64]map[string][]string{testOrgID: {
+				accesscontrol.ActionAlertingRuleRead: {dashboards.ScopeFoldersAll},
+				datasources.ActionQuery:              dsScopes,
+			}}}
+		}
+		query := DashboardQuery{QueryText: "status 500", Kind: []string{string(entityKindAlertRule)}}
+
+		filter, err := auth.GetDashboardReadFilter(context.Background(), testOrgID, signedInUser(datasources.ScopeProvider.GetResourceScopeUID("loki")))
+		require.NoError(t, err)
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, filter, query, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		require.Equal(t, 0, resp.Frames[0].Rows())
+
+		filter, err = auth.GetDashboardReadFilter(context.Background(), testOrgID, signedInUser(datasources.ScopeProvider.GetResourceScopeUID("prometheus")))
+		require.NoError(t, err)
+		resp = doSearchQuery(context.Background(), testLogger, orgIdx, filter, query, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		require.Equal(t, 1, resp.Frames[0].Rows())
+	})
+
+	t.Run("alert rules are removed on delete event", func(t *testing.T) {
+		loader.alertRules = nil
+		err := index.applyEvent(context.Background(), testOrgID, store.EntityTypeAlertRule, "rule1", store.EntityEventTypeDelete)
+		require.NoError(t, err)
+		require.Equal(t, 0, search(DashboardQuery{Kind: []string{string(entityKindAlertRule)}}).Rows())
+	})
+}
+
 var punctuationSplitNgramDashboards = []dashboard{
 	{
 		id:  1,
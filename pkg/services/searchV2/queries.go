@@ -0,0 +1,117 @@
+package searchV2
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// expressionDatasourceUID is the UID of the server side expressions datasource used by alert rules.
+const expressionDatasourceUID = "__expr__"
+
+// queryTextKeys are the keys of a query model holding the query text, for example the PromQL or LogQL
+// expression or the raw SQL. The first non empty value is used.
+var queryTextKeys = []string{"expr", "expression", "rawSql", "queryText", "query"}
+
+type panelQueries struct {
+	ID      int64             `json:"id"`
+	Type    string            `json:"type"`
+	Targets json.RawMessage   `json:"targets"`
+	Panels  []json.RawMessage `json:"panels"` // collapsed rows
+}
+
+// extractPanelQueries returns the text of the queries of each panel of the dashboard, keyed by panel ID.
+func extractPanelQueries(data []byte) map[int64][]string {
+	var dash struct {
+		Panels []json.RawMessage `json:"panels"`
+	}
+	if err := json.Unmarshal(data, &dash); err != nil {
+		return nil
+	}
+
+	queries := make(map[int64][]string)
+	var visit func(panels []json.RawMessage)
+	visit = func(panels []json.RawMessage) {
+		for _, raw := range panels {
+			var p panelQueries
+			if err := json.Unmarshal(raw, &p); err != nil {
+				continue
+			}
+			if p.Type == "row" {
+				visit(p.Panels)
+				continue
+			}
+			for _, target := range parseTargets(p.Targets) {
+				if text := queryText(target); text != "" {
+					queries[p.ID] = append(queries[p.ID], text)
+				}
+			}
+		}
+	}
+	visit(dash.Panels)
+	return queries
+}
+
+// parseTargets supports targets saved as an array, and the legacy format where targets are an object keyed by refId.
+func parseTargets(raw json.RawMessage) []map[string]any {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var targets []map[string]any
+	if err := json.Unmarshal(raw, &targets); err == nil {
+		return targets
+	}
+
+	var byRefID map[string]map[string]any
+	if err := json.Unmarshal(raw, &byRefID); err != nil {
+		return nil
+	}
+	refIDs := make([]string, 0, len(byRefID))
+	for refID := range byRefID {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+	for _, refID := range refIDs {
+		targets = append(targets, byRefID[refID])
+	}
+	return targets
+}
+
+func queryText(model map[string]any) string {
+	for _, key := range queryTextKeys {
+		if text, ok := model[key].(string); ok {
+			if text = strings.TrimSpace(text); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// highlightQueries returns the queries containing at least one of the terms of the query text, with the matching
+// terms wrapped in <mark> tags.
+func highlightQueries(queries []string, queryText string) []string {
+	terms := strings.Fields(queryText)
+	if len(terms) == 0 {
+		return nil
+	}
+	// Prefer the longest match when terms overlap
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	for i, term := range terms {
+		terms[i] = regexp.QuoteMeta(term)
+	}
+	re, err := regexp.Compile("(?i)" + strings.Join(terms, "|"))
+	if err != nil {
+		return nil
+	}
+
+	var highlighted []string
+	for _, q := range queries {
+		if re.MatchString(q) {
+			highlighted = append(highlighted, re.ReplaceAllString(q, "<mark>$0</mark>"))
+		}
+	}
+	return highlighted
+}
@@ -0,0 +1,38 @@
+package searchV2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPanelQueries(t *testing.T) {
+	data := []byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "targets": [{"refId": "A", "expr": " rate(up[5m]) "}, {"refId": "B", "expr": ""}]},
+			{"id": 2, "type": "row", "panels": [
+				{"id": 3, "type": "table", "targets": [{"refId": "A", "rawSql": "SELECT 1"}]}
+			]},
+			{"id": 4, "type": "logs", "targets": {"B": {"expr": "{app=\"b\"}"}, "A": {"expr": "{app=\"a\"}"}}},
+			{"id": 5, "type": "text"}
+		]
+	}`)
+
+	require.Equal(t, map[int64][]string{
+		1: {"rate(up[5m])"},
+		3: {"SELECT 1"},
+		4: {`{app="a"}`, `{app="b"}`},
+	}, extractPanelQueries(data))
+
+	require.Nil(t, extractPanelQueries([]byte("invalid")))
+}
+
+func TestHighlightQueries(t *testing.T) {
+	queries := []string{`sum(rate(http_requests_total[5m]))`, `up{job="api"}`}
+
+	require.Equal(t, []string{`sum(<mark>rate</mark>(<mark>HTTP_requests_total</mark>[5m]))`},
+		highlightQueries([]string{`sum(rate(HTTP_requests_total[5m]))`}, "http_requests_total rate"))
+	require.Equal(t, []string{`up{job="<mark>api</mark>"}`}, highlightQueries(queries, "API"))
+	require.Nil(t, highlightQueries(queries, "node_load1"))
+	require.Nil(t, highlightQueries(queries, " "))
+}
@@ -21,8 +21,9 @@ type DashboardQuery struct {
 	Datasource         string       `json:"ds_uid,omitempty"`   // "datasource" collides with the JSON value at the same level :()
 	DatasourceType     string       `json:"ds_type,omitempty"`
 	Tags               []string     `json:"tags,omitempty"`
-	Kind               []string     `json:"kind,omitempty"`
+	Kind               []string     `json:"kind,omitempty"` // alert rules are only returned when requested
 	PanelType          string       `json:"panel_type,omitempty"`
+	QueryText          string       `json:"queryText,omitempty"` // matches the text of panel and alert rule queries
 	UIDs               []string     `json:"uid,omitempty"`
 	Explain            bool         `json:"explain,omitempty"`            // adds details on why document matched
 	WithAllowedActions bool         `json:"withAllowedActions,omitempty"` // adds allowed actions per entity
@@ -454,6 +454,15 @@ func (s *SecretsService) RotateDataKeys(ctx context.Context) error {
 	defer s.mtx.Unlock()
 
 	s.log.Info("Data keys rotation started")
+
+	// Rotating the key of the current provider first, so the new data keys are encrypted with the new key version.
+	if provider, ok := s.providers[s.currentProviderID].(secrets.RotatableProvider); ok {
+		if err := provider.RotateKey(ctx); err != nil {
+			s.log.Error("Data keys rotation failed", "error", err)
+			return err
+		}
+	}
+
 	err := s.store.DisableDataKeys(ctx)
 	if err != nil {
 		s.log.Error("Data keys rotation failed", "error", err)
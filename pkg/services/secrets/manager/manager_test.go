@@ -248,6 +248,17 @@ func (p *fakeProvider) Decrypt(_ context.Context, _ []byte) ([]byte, error) {
 	return []byte{}, nil
 }
 
+type fakeRotatableProvider struct {
+	fakeProvider
+	rotateCalled bool
+	rotateErr    error
+}
+
+func (p *fakeRotatableProvider) RotateKey(_ context.Context) error {
+	p.rotateCalled = true
+	return p.rotateErr
+}
+
 type fakeKMS struct {
 	kms  osskmsproviders.Service
 	fake *fakeProvider
@@ -320,6 +331,41 @@ func TestSecretsService_Run(t *testing.T) {
 	})
 }
 
+func TestSecretsService_RotateDataKeys(t *testing.T) {
+	ctx := context.Background()
+	testDB := db.InitTestDB(t)
+	svc := SetupTestService(t, database.ProvideSecretsStore(testDB))
+
+	t.Run("key of the current provider should be rotated", func(t *testing.T) {
+		provider := &fakeRotatableProvider{}
+		svc.providers["fakeProvider.v1"] = provider
+		svc.currentProviderID = "fakeProvider.v1"
+
+		err := svc.RotateDataKeys(ctx)
+		require.NoError(t, err)
+		assert.True(t, provider.rotateCalled)
+	})
+
+	t.Run("data keys should not be disabled if the key rotation fails", func(t *testing.T) {
+		provider := &fakeRotatableProvider{rotateErr: errors.New("permission denied")}
+		svc.providers["fakeProvider.v1"] = provider
+		svc.currentProviderID = "fakeProvider.v1"
+
+		_, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+		require.NoError(t, err)
+
+		err = svc.RotateDataKeys(ctx)
+		require.ErrorIs(t, err, provider.rotateErr)
+
+		dataKeys, err := svc.store.GetAllDataKeys(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, dataKeys)
+		for _, dataKey := range dataKeys {
+			assert.True(t, dataKey.Active)
+		}
+	})
+}
+
 func TestSecretsService_ReEncryptDataKeys(t *testing.T) {
 	ctx := context.Background()
 	testDB := db.InitTestDB(t)
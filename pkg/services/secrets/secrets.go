@@ -73,6 +73,13 @@ type BackgroundProvider interface {
 	Run(ctx context.Context) error
 }
 
+// RotatableProvider should be implemented for a provider whose key can be rotated along with the data keys.
+// Data keys encrypted with previous versions of the key must remain decryptable after the rotation.
+type RotatableProvider interface {
+	Provider
+	RotateKey(ctx context.Context) error
+}
+
 // Migrator is responsible for secrets migrations like re-encrypting or rolling back secrets.
 type Migrator interface {
 	// ReEncryptSecrets decrypts and re-encrypts the secrets with most recent
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -177,3 +178,133 @@ func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	type TestCase struct {
+		desc         string
+		saID         int64
+		tokenID      int64
+		body         string
+		permissions  []accesscontrol.Permission
+		tokenTTL     int64
+		tokens       []apikey.APIKey
+		expectedErr  error
+		expectedCode int
+	}
+
+	expires := time.Now().Add(24 * time.Hour).Unix()
+	tokens := []apikey.APIKey{
+		{ID: 1, Name: "never expires", Created: time.Now()},
+		{ID: 2, Name: "expires", Created: time.Now(), Expires: &expires},
+	}
+
+	tests := []TestCase{
+		{
+			desc:         "should be able to rotate service account token with correct permission",
+			saID:         1,
+			tokenID:      1,
+			body:         `{}`,
+			tokenTTL:     -1,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to rotate service account token with wrong permission",
+			saID:         2,
+			tokenID:      1,
+			body:         `{}`,
+			tokenTTL:     -1,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not be able to rotate service account token that does not exist",
+			saID:         1,
+			tokenID:      3,
+			body:         `{}`,
+			tokenTTL:     -1,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "should be able to rotate service account token that inherits a lifetime within the max ttl",
+			saID:         1,
+			tokenID:      2,
+			body:         `{}`,
+			tokenTTL:     int64(48 * time.Hour / time.Second),
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to rotate service account token that never expires if max ttl is configured",
+			saID:         1,
+			tokenID:      1,
+			body:         `{}`,
+			tokenTTL:     int64(48 * time.Hour / time.Second),
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not be able to rotate service account token with a negative overlap",
+			saID:         1,
+			tokenID:      1,
+			body:         `{"overlapSeconds": -1}`,
+			tokenTTL:     -1,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not be able to rotate revoked service account token",
+			saID:         1,
+			tokenID:      1,
+			body:         `{}`,
+			tokenTTL:     -1,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			tokens:       tokens,
+			expectedErr:  serviceaccounts.ErrTokenCannotBeRotated.Errorf(""),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.cfg.ApiKeyMaxSecondsToLive = tt.tokenTTL
+				a.service = &fakeRotateService{
+					FakeServiceAccountService: &satests.FakeServiceAccountService{
+						ExpectedServiceAccountTokens: tt.tokens,
+						ExpectedAPIKey:               &apikey.APIKey{ID: 3, Name: "rotated"},
+					},
+					expectedErr: tt.expectedErr,
+				}
+			})
+
+			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens/%d/rotate", tt.saID, tt.tokenID), strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+// fakeRotateService only fails the rotation, so the rotated token can be found.
+type fakeRotateService struct {
+	*satests.FakeServiceAccountService
+	expectedErr error
+}
+
+func (f *fakeRotateService) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if f.expectedErr != nil {
+		return nil, f.expectedErr
+	}
+	return f.FakeServiceAccountService.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -109,6 +110,85 @@ func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context
 	})
 }
 
+// RotateServiceAccountToken replaces a service account token with a new token with the same name. The rotated token
+// is renamed and remains valid for the overlap period, unless it expires before.
+func (s *ServiceAccountsStoreImpl) RotateServiceAccountToken(ctx context.Context, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	var apiKey *apikey.APIKey
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		var token apikey.APIKey
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("id=? AND org_id=? AND service_account_id=?", tokenId, cmd.OrgId, serviceAccountId).Get(&token)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenId, serviceAccountId)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if (token.IsRevoked != nil && *token.IsRevoked) || (token.Expires != nil && *token.Expires <= now.Unix()) {
+			return serviceaccounts.ErrTokenCannotBeRotated.Errorf("service account token with id %d is revoked or expired", tokenId)
+		}
+
+		var overlap int64
+		if cmd.OverlapSeconds != nil {
+			overlap = *cmd.OverlapSeconds
+		}
+		expires := now.Unix() + overlap
+		if token.Expires != nil && *token.Expires < expires {
+			expires = *token.Expires
+		}
+
+		err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE api_key SET name=?, expires=?, updated=? WHERE id=?",
+				fmt.Sprintf("%s-rotated-%d", token.Name, now.Unix()), expires, now, token.ID)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		secondsToLive := cmd.SecondsToLive
+		if secondsToLive == 0 && token.Expires != nil {
+			secondsToLive = *token.Expires - token.Created.Unix()
+		}
+
+		apiKey, err = s.AddServiceAccountToken(ctx, serviceAccountId, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          token.Name,
+			OrgId:         cmd.OrgId,
+			Key:           cmd.Key,
+			SecondsToLive: secondsToLive,
+		})
+		return err
+	})
+	return apiKey, err
+}
+
+// ListActiveTokens returns the service account tokens of all the organizations that are neither revoked nor expired.
+func (s *ServiceAccountsStoreImpl) ListActiveTokens(ctx context.Context) ([]*serviceaccounts.TokenUsage, error) {
+	result := make([]*serviceaccounts.TokenUsage, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		quotedUser := s.sqlStore.GetDialect().Quote("user")
+		rawSQL := `SELECT api_key.id, api_key.name, api_key.org_id, api_key.service_account_id, ` +
+			quotedUser + `.name AS service_account_name, api_key.created, api_key.last_used_at, api_key.expires
+			FROM api_key
+			INNER JOIN ` + quotedUser + ` ON ` + quotedUser + `.id = api_key.service_account_id
+			WHERE (api_key.is_revoked IS NULL OR api_key.is_revoked = ?)
+			AND (api_key.expires IS NULL OR api_key.expires > ?)`
+
+		if err := sess.SQL(rawSQL, s.sqlStore.GetDialect().BooleanStr(false), time.Now().Unix()).Find(&result); err != nil {
+			return fmt.Errorf("%s: %w", "list active tokens error", err)
+		}
+		return nil
+	})
+	return result, err
+}
+
 // assignApiKeyToServiceAccount sets the API key service account ID
 func (s *ServiceAccountsStoreImpl) assignApiKeyToServiceAccount(ctx context.Context, apiKeyId int64, serviceAccountId int64) error {
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
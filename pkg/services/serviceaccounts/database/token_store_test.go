@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -176,3 +178,96 @@ func TestStore_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_RotateServiceAccountToken(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	keyName := t.Name()
+	key, err := apikeygen.New(sa.OrgID, keyName)
+	require.NoError(t, err)
+
+	oldKey, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+		Name:          keyName,
+		OrgId:         sa.OrgID,
+		Key:           key.HashedKey,
+		SecondsToLive: 3600,
+	})
+	require.NoError(t, err)
+
+	newKey, err := apikeygen.New(sa.OrgID, keyName)
+	require.NoError(t, err)
+	overlap := int64(60)
+
+	rotated, err := store.RotateServiceAccountToken(context.Background(), sa.ID, oldKey.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		OrgId:          sa.OrgID,
+		Key:            newKey.HashedKey,
+		OverlapSeconds: &overlap,
+	})
+	require.NoError(t, err)
+	require.Equal(t, keyName, rotated.Name)
+	require.Equal(t, newKey.HashedKey, rotated.Key)
+	require.NotNil(t, rotated.Expires)
+	// the new token has the lifetime of the rotated token
+	require.InDelta(t, time.Now().Unix()+3600, *rotated.Expires, 5)
+
+	keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &sa.OrgID,
+		ServiceAccountID: &sa.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	for _, k := range keys {
+		if k.ID == oldKey.ID {
+			require.True(t, strings.HasPrefix(k.Name, keyName+"-rotated-"))
+			require.LessOrEqual(t, *k.Expires, time.Now().Unix()+overlap)
+		}
+	}
+
+	// revoked tokens cannot be rotated
+	require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, rotated.ID))
+	_, err = store.RotateServiceAccountToken(context.Background(), sa.ID, rotated.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		OrgId: sa.OrgID,
+		Key:   key.HashedKey,
+	})
+	require.ErrorIs(t, err, serviceaccounts.ErrTokenCannotBeRotated)
+
+	// tokens of other service accounts cannot be rotated
+	_, err = store.RotateServiceAccountToken(context.Background(), sa.ID+1, oldKey.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		OrgId: sa.OrgID,
+		Key:   key.HashedKey,
+	})
+	require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+}
+
+func TestStore_ListActiveTokens(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	addToken := func(name string) int64 {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:  name,
+			OrgId: sa.OrgID,
+			Key:   key.HashedKey,
+		})
+		require.NoError(t, err)
+		return token.ID
+	}
+
+	activeID := addToken("active")
+	revokedID := addToken("revoked")
+	require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, revokedID))
+
+	tokens, err := store.ListActiveTokens(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, activeID, tokens[0].ID)
+	require.Equal(t, sa.ID, tokens[0].ServiceAccountID)
+	require.Equal(t, sa.Name, tokens[0].ServiceAccountName)
+	require.Nil(t, tokens[0].Expires)
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -16,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/secretscan"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tokenmonitor"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -23,6 +27,7 @@ import (
 const (
 	metricsCollectionInterval = time.Minute * 30
 	defaultSecretScanInterval = time.Minute * 5
+	tokenMonitorInterval      = time.Hour
 )
 
 type ServiceAccountsService struct {
@@ -34,6 +39,9 @@ type ServiceAccountsService struct {
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
+
+	// tokenMonitorService is nil when the tokens about to expire and the unused tokens are not reported
+	tokenMonitorService tokenmonitor.Checker
 }
 
 func ProvideServiceAccountsService(
@@ -45,6 +53,8 @@ func ProvideServiceAccountsService(
 	userService user.Service,
 	orgService org.Service,
 	accesscontrolService accesscontrol.Service,
+	serverLockService *serverlock.ServerLockService,
+	reg prometheus.Registerer,
 ) (*ServiceAccountsService, error) {
 	serviceAccountsStore := database.ProvideServiceAccountsStore(
 		cfg,
@@ -80,6 +90,16 @@ func ProvideServiceAccountsService(
 		}
 	}
 
+	if tokenmonitor.IsEnabled(cfg) {
+		tokenMonitorService, err := tokenmonitor.NewService(s.store, cfg, serverLockService, kvStore, reg, tokenMonitorInterval)
+		if err != nil {
+			s.log.Warn("Failed to initialize token monitor service. expiring and unused tokens are not reported",
+				"error", err.Error())
+		} else {
+			s.tokenMonitorService = tokenMonitorService
+		}
+	}
+
 	return s, nil
 }
 
@@ -114,6 +134,19 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	tokenMonitorTicker := time.NewTicker(tokenMonitorInterval)
+
+	if sa.tokenMonitorService == nil {
+		tokenMonitorTicker.Stop()
+	} else {
+		sa.backgroundLog.Debug("Enabled token monitor and executing first check")
+		if err := sa.tokenMonitorService.CheckTokens(ctx); err != nil {
+			sa.backgroundLog.Warn("Failed to check for expiring and unused tokens", "error", err.Error())
+		}
+
+		defer tokenMonitorTicker.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -136,6 +169,12 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-tokenMonitorTicker.C:
+			sa.backgroundLog.Debug("Checking for expiring and unused tokens")
+
+			if err := sa.tokenMonitorService.CheckTokens(ctx); err != nil {
+				sa.backgroundLog.Warn("Failed to check for expiring and unused tokens", "error", err.Error())
+			}
 		}
 	}
 }
@@ -221,6 +260,19 @@ func (sa *ServiceAccountsService) AddServiceAccountToken(ctx context.Context, se
 	return sa.store.AddServiceAccountToken(ctx, serviceAccountID, query)
 }
 
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(cmd.OrgId); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+	return sa.store.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
+
 func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID int64, tokenID int64) error {
 	if err := validOrgID(orgID); err != nil {
 		return err
@@ -98,6 +98,16 @@ func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, se
 	return f.ExpectedAPIKey, f.ExpectedError
 }
 
+// RotateServiceAccountToken is a fake rotating a service account token.
+func (f *FakeServiceAccountStore) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedError
+}
+
+// ListActiveTokens is a fake listing the active tokens.
+func (f *FakeServiceAccountStore) ListActiveTokens(ctx context.Context) ([]*serviceaccounts.TokenUsage, error) {
+	return nil, f.ExpectedError
+}
+
 // DeleteServiceAccountToken is a fake deleting a service account token.
 func (f *FakeServiceAccountStore) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
 	return f.ExpectedError
@@ -119,7 +129,7 @@ func (f *SecretsCheckerFake) CheckTokens(ctx context.Context) error {
 func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 	storeMock := newServiceAccountStoreFake()
 	acSvc := actest.FakeService{}
-	svc := ServiceAccountsService{acSvc, storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0}
+	svc := ServiceAccountsService{acSvc, storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0, nil}
 	testOrgId := 1
 
 	t.Run("should create service account", func(t *testing.T) {
@@ -43,6 +43,7 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrTokenCannotBeRotated              = errutil.BadRequest("serviceaccounts.ErrTokenCannotBeRotated", errutil.WithPublicMessage("revoked or expired service account tokens cannot be rotated"))
 )
 
 type MigrationResult struct {
@@ -110,6 +111,27 @@ type AddServiceAccountTokenCommand struct {
 	SecondsToLive int64  `json:"secondsToLive"`
 }
 
+type RotateServiceAccountTokenCommand struct {
+	OrgId int64  `json:"-"`
+	Key   string `json:"-"`
+	// SecondsToLive of the new token, it has the lifetime of the rotated token when it is not set.
+	SecondsToLive int64 `json:"secondsToLive"`
+	// OverlapSeconds is how long the rotated token remains valid.
+	OverlapSeconds *int64 `json:"overlapSeconds"`
+}
+
+// TokenUsage describes a service account token that is about to expire or has not been used for a while.
+type TokenUsage struct {
+	ID                 int64      `xorm:"id"`
+	Name               string     `xorm:"name"`
+	OrgID              int64      `xorm:"org_id"`
+	ServiceAccountID   int64      `xorm:"service_account_id"`
+	ServiceAccountName string     `xorm:"service_account_name"`
+	Created            time.Time  `xorm:"created"`
+	LastUsedAt         *time.Time `xorm:"last_used_at"`
+	Expires            *int64     `xorm:"expires"`
+}
+
 type SearchOrgServiceAccountsQuery struct {
 	OrgID        int64
 	Query        string
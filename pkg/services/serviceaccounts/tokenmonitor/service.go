@@ -0,0 +1,270 @@
+package tokenmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	lockName      = "service account token monitor"
+	kvNamespace   = "serviceaccounts"
+	kvNotifiedKey = "token-monitor-notified"
+
+	metricsNamespace = "grafana"
+	metricsSubsystem = "serviceaccounts"
+
+	ReasonExpiring = "expiring"
+	ReasonUnused   = "unused"
+)
+
+type Checker interface {
+	CheckTokens(ctx context.Context) error
+}
+
+type TokenStore interface {
+	ListActiveTokens(ctx context.Context) ([]*serviceaccounts.TokenUsage, error)
+	RevokeServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
+}
+
+type WebHookClient interface {
+	Notify(ctx context.Context, token *serviceaccounts.TokenUsage, reason string, revoked bool) error
+}
+
+type serverLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+type metrics struct {
+	expiringTokens prometheus.Gauge
+	unusedTokens   prometheus.Gauge
+	revokedTokens  prometheus.Counter
+}
+
+// Service reports the service account tokens that are about to expire or that have not been used for a while,
+// and revokes the unused tokens when it is enabled.
+type Service struct {
+	store         TokenStore
+	webHookClient WebHookClient
+	serverLock    serverLock
+	kv            *kvstore.NamespacedKVStore
+	logger        log.Logger
+	metrics       *metrics
+	now           func() time.Time
+
+	warningPeriod time.Duration
+	unusedPeriod  time.Duration
+	revokeUnused  bool
+	// lockInterval prevents several instances from notifying and revoking during the same check
+	lockInterval time.Duration
+}
+
+// IsEnabled returns true if tokens that are about to expire or unused tokens are reported.
+func IsEnabled(cfg *setting.Cfg) bool {
+	return cfg.SATokenExpiryWarningDays > 0 || cfg.SATokenUnusedDays > 0
+}
+
+func NewService(store TokenStore, cfg *setting.Cfg, serverLock serverLock, kv kvstore.KVStore,
+	reg prometheus.Registerer, checkInterval time.Duration,
+) (*Service, error) {
+	var webHookClient WebHookClient
+	if cfg.SATokenWebhookURL != "" {
+		var err error
+		webHookClient, err = newWebHookClient(cfg.SATokenWebhookURL, cfg.BuildVersion, cfg.Env == setting.Dev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token monitor webhook client: %w", err)
+		}
+	}
+
+	return &Service{
+		store:         store,
+		webHookClient: webHookClient,
+		serverLock:    serverLock,
+		kv:            kvstore.WithNamespace(kv, 0, kvNamespace),
+		logger:        log.New("serviceaccounts.tokenmonitor"),
+		metrics:       newMetrics(reg),
+		now:           time.Now,
+		warningPeriod: time.Duration(cfg.SATokenExpiryWarningDays) * 24 * time.Hour,
+		unusedPeriod:  time.Duration(cfg.SATokenUnusedDays) * 24 * time.Hour,
+		revokeUnused:  cfg.SATokenRevokeUnused && cfg.SATokenUnusedDays > 0,
+		lockInterval:  checkInterval / 2,
+	}, nil
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		expiringTokens: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "tokens_expiring",
+			Help:      "Number of service account tokens that expire within token_expiry_warning_days.",
+		}),
+		unusedTokens: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "tokens_unused",
+			Help:      "Number of service account tokens that have not been used for token_unused_days.",
+		}),
+		revokedTokens: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "tokens_revoked_unused_total",
+			Help:      "Number of unused service account tokens revoked since start up.",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.expiringTokens, m.unusedTokens, m.revokedTokens)
+	}
+
+	return m
+}
+
+// CheckTokens updates the metrics of the tokens that are about to expire or unused on every instance, the
+// notifications and the revocation of the unused tokens happen on a single instance.
+func (s *Service) CheckTokens(ctx context.Context) error {
+	tokens, err := s.store.ListActiveTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve tokens for checking: %w", err)
+	}
+
+	expiring, unused := s.filterTokens(tokens)
+	s.metrics.expiringTokens.Set(float64(len(expiring)))
+	s.metrics.unusedTokens.Set(float64(len(unused)))
+
+	if len(expiring) == 0 && len(unused) == 0 {
+		s.logger.Debug("No expiring or unused tokens")
+	}
+
+	return s.serverLock.LockAndExecute(ctx, lockName, s.lockInterval, func(ctx context.Context) {
+		s.processTokens(ctx, expiring, unused)
+	})
+}
+
+// filterTokens returns the tokens that expire within the warning period and the tokens that have not been used
+// during the unused period. Tokens that were never used are considered from their creation.
+func (s *Service) filterTokens(tokens []*serviceaccounts.TokenUsage) ([]*serviceaccounts.TokenUsage, []*serviceaccounts.TokenUsage) {
+	now := s.now()
+	expiring := make([]*serviceaccounts.TokenUsage, 0)
+	unused := make([]*serviceaccounts.TokenUsage, 0)
+
+	for _, token := range tokens {
+		if s.warningPeriod > 0 && token.Expires != nil && time.Unix(*token.Expires, 0).Before(now.Add(s.warningPeriod)) {
+			expiring = append(expiring, token)
+		}
+
+		lastUsed := token.Created
+		if token.LastUsedAt != nil {
+			lastUsed = *token.LastUsedAt
+		}
+		if s.unusedPeriod > 0 && lastUsed.Before(now.Add(-s.unusedPeriod)) {
+			unused = append(unused, token)
+		}
+	}
+
+	return expiring, unused
+}
+
+func (s *Service) processTokens(ctx context.Context, expiring, unused []*serviceaccounts.TokenUsage) {
+	notified := s.notifiedTokens(ctx)
+	current := map[string][]int64{ReasonExpiring: {}, ReasonUnused: {}}
+
+	for _, token := range expiring {
+		current[ReasonExpiring] = append(current[ReasonExpiring], token.ID)
+		if notified[ReasonExpiring][token.ID] {
+			continue
+		}
+
+		s.logger.Warn("Found service account token about to expire",
+			"token_id", token.ID, "token", token.Name, "org", token.OrgID,
+			"serviceAccount", token.ServiceAccountID, "expires", time.Unix(*token.Expires, 0))
+		s.notify(ctx, token, ReasonExpiring, false)
+	}
+
+	for _, token := range unused {
+		revoked := false
+		if s.revokeUnused {
+			if err := s.store.RevokeServiceAccountToken(ctx, token.OrgID, token.ServiceAccountID, token.ID); err != nil {
+				s.logger.Error("Failed to revoke unused token", "error", err,
+					"token_id", token.ID, "token", token.Name, "org", token.OrgID, "serviceAccount", token.ServiceAccountID)
+			} else {
+				revoked = true
+				s.metrics.revokedTokens.Inc()
+			}
+		}
+
+		// revoked tokens are not listed anymore, they are only notified once
+		if !revoked {
+			current[ReasonUnused] = append(current[ReasonUnused], token.ID)
+			if notified[ReasonUnused][token.ID] {
+				continue
+			}
+		}
+
+		s.logger.Warn("Found unused service account token",
+			"token_id", token.ID, "token", token.Name, "org", token.OrgID,
+			"serviceAccount", token.ServiceAccountID, "lastUsedAt", token.LastUsedAt, "revoked", revoked)
+		s.notify(ctx, token, ReasonUnused, revoked)
+	}
+
+	s.saveNotifiedTokens(ctx, current)
+}
+
+func (s *Service) notify(ctx context.Context, token *serviceaccounts.TokenUsage, reason string, revoked bool) {
+	if s.webHookClient == nil {
+		return
+	}
+
+	if err := s.webHookClient.Notify(ctx, token, reason, revoked); err != nil {
+		s.logger.Warn("Failed to call token monitor webhook", "error", err, "token_id", token.ID, "reason", reason)
+	}
+}
+
+// notifiedTokens returns the tokens that were reported by the previous check, by reason, so that they are
+// reported only once.
+func (s *Service) notifiedTokens(ctx context.Context) map[string]map[int64]bool {
+	result := map[string]map[int64]bool{ReasonExpiring: {}, ReasonUnused: {}}
+
+	value, ok, err := s.kv.Get(ctx, kvNotifiedKey)
+	if err != nil {
+		s.logger.Warn("Failed to retrieve notified tokens", "error", err)
+		return result
+	}
+	if !ok {
+		return result
+	}
+
+	stored := map[string][]int64{}
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		s.logger.Warn("Failed to parse notified tokens", "error", err)
+		return result
+	}
+	for reason, ids := range stored {
+		if _, ok := result[reason]; !ok {
+			continue
+		}
+		for _, id := range ids {
+			result[reason][id] = true
+		}
+	}
+
+	return result
+}
+
+func (s *Service) saveNotifiedTokens(ctx context.Context, notified map[string][]int64) {
+	value, err := json.Marshal(notified)
+	if err == nil {
+		err = s.kv.Set(ctx, kvNotifiedKey, string(value))
+	}
+	if err != nil {
+		s.logger.Warn("Failed to save notified tokens", "error", err)
+	}
+}
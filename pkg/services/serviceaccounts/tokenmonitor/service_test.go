@@ -0,0 +1,135 @@
+package tokenmonitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeTokenStore struct {
+	tokens    []*serviceaccounts.TokenUsage
+	errRevoke error
+
+	revokeCalls []int64
+}
+
+func (f *fakeTokenStore) ListActiveTokens(ctx context.Context) ([]*serviceaccounts.TokenUsage, error) {
+	return f.tokens, nil
+}
+
+func (f *fakeTokenStore) RevokeServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
+	f.revokeCalls = append(f.revokeCalls, tokenID)
+	return f.errRevoke
+}
+
+type notification struct {
+	tokenID int64
+	reason  string
+	revoked bool
+}
+
+type fakeWebHookClient struct {
+	notifications []notification
+}
+
+func (f *fakeWebHookClient) Notify(ctx context.Context, token *serviceaccounts.TokenUsage, reason string, revoked bool) error {
+	f.notifications = append(f.notifications, notification{tokenID: token.ID, reason: reason, revoked: revoked})
+	return nil
+}
+
+type fakeServerLock struct{}
+
+func (f *fakeServerLock) LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
+	fn(ctx)
+	return nil
+}
+
+func setupTokenMonitor(t *testing.T, revokeUnused bool, tokens ...*serviceaccounts.TokenUsage) (*Service, *fakeTokenStore, *fakeWebHookClient) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.SATokenExpiryWarningDays = 7
+	cfg.SATokenUnusedDays = 90
+	cfg.SATokenRevokeUnused = revokeUnused
+
+	store := &fakeTokenStore{tokens: tokens}
+	s, err := NewService(store, cfg, &fakeServerLock{}, kvstore.NewFakeKVStore(), nil, time.Hour)
+	require.NoError(t, err)
+
+	webHookClient := &fakeWebHookClient{}
+	s.webHookClient = webHookClient
+	s.logger = log.NewNopLogger()
+	return s, store, webHookClient
+}
+
+func TestService_CheckTokens(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-24 * time.Hour)
+	longAgo := now.Add(-100 * 24 * time.Hour)
+	expiresSoon := now.Add(2 * 24 * time.Hour).Unix()
+	expiresLater := now.Add(30 * 24 * time.Hour).Unix()
+
+	tokens := []*serviceaccounts.TokenUsage{
+		{ID: 1, Name: "used", Created: longAgo, LastUsedAt: &recently},
+		{ID: 2, Name: "expiring", Created: longAgo, LastUsedAt: &recently, Expires: &expiresSoon},
+		{ID: 3, Name: "not expiring yet", Created: recently, Expires: &expiresLater},
+		{ID: 4, Name: "unused", Created: longAgo, LastUsedAt: &longAgo},
+		{ID: 5, Name: "never used", Created: longAgo},
+	}
+
+	t.Run("should report expiring and unused tokens once", func(t *testing.T) {
+		s, store, webHookClient := setupTokenMonitor(t, false, tokens...)
+		s.now = func() time.Time { return now }
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.expiringTokens))
+		assert.Equal(t, float64(2), testutil.ToFloat64(s.metrics.unusedTokens))
+		assert.Empty(t, store.revokeCalls)
+		assert.Equal(t, []notification{
+			{tokenID: 2, reason: ReasonExpiring},
+			{tokenID: 4, reason: ReasonUnused},
+			{tokenID: 5, reason: ReasonUnused},
+		}, webHookClient.notifications)
+
+		webHookClient.notifications = nil
+		require.NoError(t, s.CheckTokens(context.Background()))
+		assert.Empty(t, webHookClient.notifications)
+	})
+
+	t.Run("should revoke unused tokens", func(t *testing.T) {
+		s, store, webHookClient := setupTokenMonitor(t, true, tokens...)
+		s.now = func() time.Time { return now }
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+
+		assert.Equal(t, []int64{4, 5}, store.revokeCalls)
+		assert.Equal(t, float64(2), testutil.ToFloat64(s.metrics.revokedTokens))
+		assert.Equal(t, []notification{
+			{tokenID: 2, reason: ReasonExpiring},
+			{tokenID: 4, reason: ReasonUnused, revoked: true},
+			{tokenID: 5, reason: ReasonUnused, revoked: true},
+		}, webHookClient.notifications)
+	})
+
+	t.Run("should notify unused tokens that could not be revoked", func(t *testing.T) {
+		s, store, webHookClient := setupTokenMonitor(t, true, tokens[3])
+		s.now = func() time.Time { return now }
+		store.errRevoke = errors.New("database is locked")
+
+		require.NoError(t, s.CheckTokens(context.Background()))
+
+		assert.Equal(t, float64(0), testutil.ToFloat64(s.metrics.revokedTokens))
+		assert.Equal(t, []notification{{tokenID: 4, reason: ReasonUnused}}, webHookClient.notifications)
+	})
+}
@@ -0,0 +1,117 @@
+package tokenmonitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+var (
+	errWebHookURL               = errors.New("webhook url must be https")
+	ErrInvalidWebHookStatusCode = errors.New("invalid webhook status code")
+)
+
+// webHookClient is a client for sending expiring and unused token notifications.
+type webHookClient struct {
+	httpClient *http.Client
+	version    string
+	url        string
+}
+
+func newWebHookClient(url, version string, dev bool) (*webHookClient, error) {
+	if !strings.HasPrefix(url, "https://") && !dev {
+		return nil, errWebHookURL
+	}
+
+	return &webHookClient{
+		version: version,
+		url:     url,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+			},
+			Timeout: time.Second * 30,
+		},
+	}, nil
+}
+
+func (wClient *webHookClient) Notify(ctx context.Context, token *serviceaccounts.TokenUsage, reason string, revoked bool) error {
+	values := map[string]any{
+		// the same token is reported as expiring and unused by two different alerts
+		"alert_uid":          fmt.Sprintf("serviceaccount-token-%d-%s", token.ID, reason),
+		"title":              webHookTitle(reason),
+		"state":              "alerting",
+		"message":            webHookMessage(token, reason, revoked),
+		"reason":             reason,
+		"revoked":            revoked,
+		"token_id":           token.ID,
+		"token_name":         token.Name,
+		"org_id":             token.OrgID,
+		"service_account_id": token.ServiceAccountID,
+	}
+	if token.Expires != nil {
+		values["expires"] = time.Unix(*token.Expires, 0).UTC()
+	}
+	if token.LastUsedAt != nil {
+		values["last_used_at"] = token.LastUsedAt.UTC()
+	}
+
+	jsonValue, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to marshal webhook request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wClient.url, bytes.NewReader(jsonValue))
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to make http request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "grafana-tokenmonitor-webhook-client/"+wClient.version)
+
+	resp, err := wClient.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to webhook request", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w. status code %s", ErrInvalidWebHookStatusCode, resp.Status)
+	}
+
+	return nil
+}
+
+func webHookTitle(reason string) string {
+	if reason == ReasonExpiring {
+		return "Grafana service account token about to expire"
+	}
+	return "Grafana service account token unused"
+}
+
+func webHookMessage(token *serviceaccounts.TokenUsage, reason string, revoked bool) string {
+	if reason == ReasonExpiring {
+		return fmt.Sprintf("Token %s of service account %s in organization %d expires on %s.",
+			token.Name, token.ServiceAccountName, token.OrgID, time.Unix(*token.Expires, 0).UTC().Format(time.RFC3339))
+	}
+
+	lastUsed := "has never been used"
+	if token.LastUsedAt != nil {
+		lastUsed = "was last used on " + token.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	msg := fmt.Sprintf("Token %s of service account %s in organization %d %s.",
+		token.Name, token.ServiceAccountName, token.OrgID, lastUsed)
+	if revoked {
+		msg += " Grafana has revoked this token."
+	}
+	return msg
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAuditLogMigrations(mg *Migrator) {
+	auditLogV1 := Table{
+		Name: "audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "resource_kind", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "result", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "status_code", Type: DB_Int, Nullable: false},
+			{Name: "request_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "remote_addr", Type: DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"resource_kind", "resource_uid"}},
+		},
+	}
+
+	mg.AddMigration("create audit_log table", NewAddTableMigration(auditLogV1))
+	mg.AddMigration("add index audit_log.created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[0]))
+	mg.AddMigration("add index audit_log.org_id_created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[1]))
+	mg.AddMigration("add index audit_log.resource_kind_resource_uid", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[2]))
+}
@@ -235,3 +235,9 @@ func addDashboardMigration(mg *Migrator) {
 		Name: "is_public", Type: DB_Bool, Nullable: false, Default: "0",
 	}))
 }
+
+func addDashboardProvisioningCommitMigration(mg *Migrator) {
+	mg.AddMigration("Add commit_sha column to dashboard_provisioning", NewAddColumnMigration(Table{Name: "dashboard_provisioning"}, &Column{
+		Name: "commit_sha", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+}
@@ -96,4 +96,30 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add expires_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "expires_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add allowed_cidrs column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "allowed_cidrs",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add queries_per_minute column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "queries_per_minute",
+		Type:     DB_Int,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add max_concurrent_queries column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_concurrent_queries",
+		Type:     DB_Int,
+		Nullable: false,
+		Default:  "0",
+	}))
 }
@@ -0,0 +1,68 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	dashboardUsageV1 := Table{
+		Name: "dashboard_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "query_errors", Type: DB_BigInt, Nullable: false},
+			{Name: "query_duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "last_viewed", Type: DB_BigInt, Nullable: false},
+			{Name: "last_viewed_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage table", NewAddTableMigration(dashboardUsageV1))
+	mg.AddMigration("add unique index dashboard_usage.org_id_dashboard_uid", NewAddIndexMigration(dashboardUsageV1, dashboardUsageV1.Indices[0]))
+
+	dashboardUsageDailyV1 := Table{
+		Name: "dashboard_usage_daily",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day_start", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "query_errors", Type: DB_BigInt, Nullable: false},
+			{Name: "query_duration_ms", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "day_start"}, Type: UniqueIndex},
+			{Cols: []string{"day_start"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_daily table", NewAddTableMigration(dashboardUsageDailyV1))
+	mg.AddMigration("add unique index dashboard_usage_daily.org_id_dashboard_uid_day_start", NewAddIndexMigration(dashboardUsageDailyV1, dashboardUsageDailyV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage_daily.day_start", NewAddIndexMigration(dashboardUsageDailyV1, dashboardUsageDailyV1.Indices[1]))
+
+	dashboardUsageUserV1 := Table{
+		Name: "dashboard_usage_user",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "last_viewed", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"last_viewed"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_user table", NewAddTableMigration(dashboardUsageUserV1))
+	mg.AddMigration("add unique index dashboard_usage_user.org_id_dashboard_uid_user_id", NewAddIndexMigration(dashboardUsageUserV1, dashboardUsageUserV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage_user.last_viewed", NewAddIndexMigration(dashboardUsageUserV1, dashboardUsageUserV1.Indices[1]))
+}
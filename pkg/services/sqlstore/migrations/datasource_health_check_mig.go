@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDataSourceHealthCheckMigrations(mg *Migrator) {
+	dataSourceHealthCheckV1 := Table{
+		Name: "data_source_health_check",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "latency_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "checked", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_uid", "checked"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_health_check table", NewAddTableMigration(dataSourceHealthCheckV1))
+	mg.AddMigration("add index data_source_health_check.org_id_data_source_uid_checked", NewAddIndexMigration(dataSourceHealthCheckV1, dataSourceHealthCheckV1.Indices[0]))
+}
@@ -121,6 +121,22 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAlertingScopeRemovalMigration(mg)
 
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
+
+	addDashboardProvisioningCommitMigration(mg)
+
+	ualert.AddRuleMaxInstancesColumns(mg)
+
+	addUserMFAMigrations(mg)
+
+	addSCIMMigrations(mg)
+
+	addAuditLogMigrations(mg)
+
+	addReportMigrations(mg)
+
+	addDashboardUsageMigrations(mg)
+
+	addDataSourceHealthCheckMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -27,6 +27,7 @@ const (
 	EntityTypeFolder    EntityType = "folder"
 	EntityTypeImage     EntityType = "image"
 	EntityTypeJSON      EntityType = "json"
+	EntityTypeAlertRule EntityType = "alertrule"
 )
 
 // CreateDatabaseEntityId creates entityId for entities stored in the existing SQL tables
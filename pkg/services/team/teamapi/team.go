@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
@@ -65,6 +66,7 @@ func (tapi *TeamAPI) createTeam(c *contextmodel.ReqContext) response.Response {
 		c.Logger.Warn("Could not add creator to team because is not a real user")
 	}
 
+	audit.SetResource(c.Req.Context(), "teams", strconv.FormatInt(t.ID, 10))
 	return response.JSON(http.StatusOK, &util.DynMap{
 		"teamId":  t.ID,
 		"message": "Team created",
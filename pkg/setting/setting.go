@@ -198,6 +198,9 @@ type Cfg struct {
 	PluginInstallToken               string
 	ForwardHostEnvVars               []string
 
+	PluginRepositories                 []PluginRepositorySettings
+	PluginGrafanaComRepositoryDisabled bool
+
 	PluginsCDNURLTemplate    string
 	PluginLogBackendRequests bool
 
@@ -217,9 +220,10 @@ type Cfg struct {
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
-	DashboardVersionsToKeep  int
-	MinRefreshInterval       string
-	DefaultHomeDashboardPath string
+	DashboardVersionsToKeep    int
+	LibraryPanelVersionsToKeep int
+	MinRefreshInterval         string
+	DefaultHomeDashboardPath   string
 
 	// Auth
 	LoginCookieName               string
@@ -263,6 +267,8 @@ type Cfg struct {
 
 	JWTAuth    AuthJWTSettings
 	ExtJWTAuth ExtJWTSettings
+	MFA        AuthMFASettings
+	SCIM       AuthSCIMSettings
 
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
@@ -311,6 +317,20 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	SATokenRotationOverlap    time.Duration
+	SATokenExpiryWarningDays  int
+	SATokenUnusedDays         int
+	SATokenRevokeUnused       bool
+	SATokenWebhookURL         string
+
+	// Audit log
+	Audit AuditSettings
+
+	// Scheduled reports
+	Reports ReportsSettings
+
+	// Dashboard usage analytics
+	DashboardUsage DashboardUsageSettings
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -326,6 +346,14 @@ type Cfg struct {
 	DataSourceLimit int
 	// Number of queries to be executed concurrently. Only for the datasource supports concurrency.
 	ConcurrentQueryCount int
+	// Concurrency limits and circuit breakers of the data sources
+	DataSourceLimits DataSourceLimitsSettings
+	// Size limits of the query responses
+	QueryResponseLimits QueryResponseLimitsSettings
+	// Background health checks of the data sources
+	DataSourceHealthCheck DataSourceHealthCheckSettings
+	// Live reload and drift detection of the provisioned data sources
+	DataSourceProvisioning DataSourceProvisioningSettings
 
 	// IP range access control
 	IPRangeACEnabled     bool
@@ -490,6 +518,9 @@ type Cfg struct {
 
 	// Public dashboards
 	PublicDashboardsEnabled bool
+	// PublicDashboardsTrustedProxies are the networks of the reverse proxies whose forwarding headers are used to
+	// find the client IP address for the public dashboard IP allow-lists.
+	PublicDashboardsTrustedProxies []string
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -1091,6 +1122,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	// read dashboard settings
 	dashboards := iniFile.Section("dashboards")
 	cfg.DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
+	cfg.LibraryPanelVersionsToKeep = dashboards.Key("library_panel_versions_to_keep").MustInt(20)
 	cfg.MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
 
@@ -1199,6 +1231,8 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAzureSettings()
 	cfg.readAuthJWTSettings()
 	cfg.readAuthExtJWTSettings()
+	cfg.readAuthMFASettings()
+	cfg.readAuthSCIMSettings()
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	if err := cfg.readSmtpSettings(); err != nil {
@@ -1207,6 +1241,15 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	if err := cfg.readAnnotationSettings(); err != nil {
 		return err
 	}
+	if err := cfg.readAuditSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readReportsSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readDashboardUsageSettings(); err != nil {
+		return err
+	}
 
 	cfg.readQuotaSettings()
 
@@ -1216,6 +1259,18 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	}
 
 	cfg.readDataSourcesSettings()
+	if err := cfg.readDataSourceLimitsSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readQueryResponseLimitsSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readDataSourceHealthCheckSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readDataSourceProvisioningSettings(); err != nil {
+		return err
+	}
 	cfg.readDataSourceSecuritySettings()
 	cfg.readSqlDataSourceSettings()
 
@@ -1706,6 +1761,11 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SATokenRotationOverlap = serviceAccount.Key("token_rotation_overlap").MustDuration(24 * time.Hour)
+	cfg.SATokenExpiryWarningDays = serviceAccount.Key("token_expiry_warning_days").MustInt(7)
+	cfg.SATokenUnusedDays = serviceAccount.Key("token_unused_days").MustInt(90)
+	cfg.SATokenRevokeUnused = serviceAccount.Key("revoke_unused_tokens").MustBool(false)
+	cfg.SATokenWebhookURL = valueAsString(serviceAccount, "token_webhook_url", "")
 	return nil
 }
 
@@ -1999,4 +2059,5 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsTrustedProxies = util.SplitString(publicDashboards.Key("trusted_proxies").String())
 }
@@ -0,0 +1,55 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// defaultAuditExcludePaths are API calls that use POST without changing anything, or that are very frequent.
+const defaultAuditExcludePaths = "/api/ds/query,/api/tsdb/query,/api/datasources/proxy/,/api/frontend-metrics,/api/live/,/api/user/auth-tokens/rotate"
+
+// AuditSettings contains the settings of the audit log of the mutating API calls.
+type AuditSettings struct {
+	Enabled bool
+	// Sinks are the destinations of the audit entries: sql, file and loki.
+	Sinks []string
+	// ExcludePaths are the prefixes of the API paths that are not audited.
+	ExcludePaths []string
+
+	// SQLRetention is how long the entries are kept in the database.
+	SQLRetention time.Duration
+	// FilePath is the file the entries are appended to, defaults to audit.log in the logs directory.
+	FilePath string
+
+	LokiURL               string
+	LokiTenantID          string
+	LokiBasicAuthUser     string
+	LokiBasicAuthPassword string
+}
+
+func (cfg *Cfg) readAuditSettings() error {
+	auditSettings := AuditSettings{}
+	audit := cfg.SectionWithEnvOverrides("audit")
+	auditSettings.Enabled = audit.Key("enabled").MustBool(false)
+	auditSettings.Sinks = util.SplitString(audit.Key("sinks").MustString("sql"))
+	auditSettings.ExcludePaths = util.SplitString(audit.Key("exclude_paths").MustString(defaultAuditExcludePaths))
+
+	retention, err := gtime.ParseDuration(audit.Key("sql_retention").MustString("90d"))
+	if err != nil {
+		return fmt.Errorf("invalid audit sql_retention: %w", err)
+	}
+	auditSettings.SQLRetention = retention
+
+	auditSettings.FilePath = audit.Key("file_path").MustString("")
+	auditSettings.LokiURL = audit.Key("loki_url").MustString("")
+	auditSettings.LokiTenantID = audit.Key("loki_tenant_id").MustString("")
+	auditSettings.LokiBasicAuthUser = audit.Key("loki_basic_auth_user").MustString("")
+	auditSettings.LokiBasicAuthPassword = audit.Key("loki_basic_auth_password").MustString("")
+
+	cfg.Audit = auditSettings
+	return nil
+}
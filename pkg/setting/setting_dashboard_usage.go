@@ -0,0 +1,44 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// DashboardUsageSettings contains the settings of the dashboard usage analytics.
+type DashboardUsageSettings struct {
+	Enabled bool
+	// FlushInterval is how often the recorded views and queries are written to the database.
+	FlushInterval time.Duration
+	// Retention is how long the daily and per user usage is kept.
+	Retention time.Duration
+	// StaleAfter is how long a dashboard must not have been viewed to be listed as stale by default.
+	StaleAfter time.Duration
+}
+
+func (cfg *Cfg) readDashboardUsageSettings() error {
+	usageSettings := DashboardUsageSettings{}
+	usage := cfg.SectionWithEnvOverrides("dashboard_usage")
+	usageSettings.Enabled = usage.Key("enabled").MustBool(true)
+	usageSettings.FlushInterval = usage.Key("flush_interval").MustDuration(time.Minute)
+	if usageSettings.FlushInterval < time.Second {
+		return fmt.Errorf("dashboard_usage flush_interval must be at least 1s, got %s", usageSettings.FlushInterval)
+	}
+
+	retention, err := gtime.ParseDuration(usage.Key("retention").MustString("90d"))
+	if err != nil {
+		return fmt.Errorf("invalid dashboard_usage retention: %w", err)
+	}
+	usageSettings.Retention = retention
+
+	staleAfter, err := gtime.ParseDuration(usage.Key("stale_after").MustString("90d"))
+	if err != nil {
+		return fmt.Errorf("invalid dashboard_usage stale_after: %w", err)
+	}
+	usageSettings.StaleAfter = staleAfter
+
+	cfg.DashboardUsage = usageSettings
+	return nil
+}
@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataSourceHealthCheckSettings contains the settings of the background health checks of the data sources.
+type DataSourceHealthCheckSettings struct {
+	Enabled bool
+	// Interval is how often the health of every data source is checked.
+	Interval time.Duration
+	// Timeout is how long a health check can take before the data source is considered unhealthy.
+	Timeout time.Duration
+	// HistorySize is the number of results kept per data source.
+	HistorySize int
+	// AlertingEnabled sends an alert to the Grafana Alertmanager of the organization when a data source is unhealthy.
+	AlertingEnabled bool
+}
+
+func (cfg *Cfg) readDataSourceHealthCheckSettings() error {
+	section := cfg.SectionWithEnvOverrides("datasources.health_check")
+	s := DataSourceHealthCheckSettings{
+		Enabled:         section.Key("enabled").MustBool(false),
+		Interval:        section.Key("interval").MustDuration(5 * time.Minute),
+		Timeout:         section.Key("timeout").MustDuration(30 * time.Second),
+		HistorySize:     section.Key("history_size").MustInt(20),
+		AlertingEnabled: section.Key("alerting_enabled").MustBool(false),
+	}
+
+	if s.Interval < 10*time.Second {
+		return fmt.Errorf("datasources.health_check interval must be at least 10s, got %s", s.Interval)
+	}
+	if s.Timeout <= 0 || s.Timeout > s.Interval {
+		return fmt.Errorf("datasources.health_check timeout must be positive and not longer than the interval, got %s", s.Timeout)
+	}
+	if s.HistorySize < 1 {
+		return fmt.Errorf("datasources.health_check history_size must be at least 1, got %d", s.HistorySize)
+	}
+
+	cfg.DataSourceHealthCheck = s
+	return nil
+}
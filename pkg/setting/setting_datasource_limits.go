@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataSourceLimitsSettings contains the settings that protect data sources from too many concurrent queries.
+type DataSourceLimitsSettings struct {
+	Enabled bool
+	// MaxConcurrentQueries is the default number of requests that run concurrently for each data source, 0 is unlimited.
+	// Data sources can override it with the maxConcurrentQueries field of their JSON data.
+	MaxConcurrentQueries int
+	// MaxQueuedQueries is the number of requests that wait for a data source that runs the maximum concurrent queries.
+	MaxQueuedQueries int
+	// QueueTimeout is how long a request waits in the queue before it fails.
+	QueueTimeout time.Duration
+	// CircuitBreakerFailures is the number of consecutive failed or timed out requests that open the circuit breaker
+	// of a data source, 0 disables the circuit breaker.
+	CircuitBreakerFailures int
+	// CircuitBreakerOpenDuration is how long the circuit breaker rejects the requests before it lets a request through
+	// to check if the data source recovered.
+	CircuitBreakerOpenDuration time.Duration
+}
+
+func (cfg *Cfg) readDataSourceLimitsSettings() error {
+	limits := cfg.SectionWithEnvOverrides("datasources.limits")
+	s := DataSourceLimitsSettings{}
+	s.Enabled = limits.Key("enabled").MustBool(false)
+	s.MaxConcurrentQueries = limits.Key("max_concurrent_queries").MustInt(0)
+	s.MaxQueuedQueries = limits.Key("max_queued_queries").MustInt(100)
+	s.QueueTimeout = limits.Key("queue_timeout").MustDuration(10 * time.Second)
+	s.CircuitBreakerFailures = limits.Key("circuit_breaker_failures").MustInt(0)
+	s.CircuitBreakerOpenDuration = limits.Key("circuit_breaker_open_duration").MustDuration(30 * time.Second)
+
+	if s.MaxConcurrentQueries < 0 || s.MaxQueuedQueries < 0 || s.CircuitBreakerFailures < 0 {
+		return fmt.Errorf("datasources.limits max_concurrent_queries, max_queued_queries and circuit_breaker_failures must not be negative")
+	}
+	if s.QueueTimeout <= 0 || s.CircuitBreakerOpenDuration <= 0 {
+		return fmt.Errorf("datasources.limits queue_timeout and circuit_breaker_open_duration must be positive")
+	}
+
+	cfg.DataSourceLimits = s
+	return nil
+}
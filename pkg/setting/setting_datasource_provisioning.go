@@ -0,0 +1,48 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ProvisionedDataSourceUIEditsAllow allows to change the provisioned data sources in the UI and the API.
+	ProvisionedDataSourceUIEditsAllow = "allow"
+	// ProvisionedDataSourceUIEditsWarn allows to change the provisioned data sources, and warns that the changes
+	// differ from the provisioning files.
+	ProvisionedDataSourceUIEditsWarn = "warn"
+	// ProvisionedDataSourceUIEditsBlock rejects the changes of the provisioned data sources.
+	ProvisionedDataSourceUIEditsBlock = "block"
+)
+
+// DataSourceProvisioningSettings contains the settings of the data source provisioning files.
+type DataSourceProvisioningSettings struct {
+	// Watch applies the provisioning files again when they change.
+	Watch bool
+	// WatchInterval is how often the provisioning files are checked for changes.
+	WatchInterval time.Duration
+	// UIEdits is what to do with the changes of the provisioned data sources made in the UI or the API.
+	UIEdits string
+}
+
+func (cfg *Cfg) readDataSourceProvisioningSettings() error {
+	section := cfg.SectionWithEnvOverrides("datasources.provisioning")
+	s := DataSourceProvisioningSettings{
+		Watch:         section.Key("watch").MustBool(false),
+		WatchInterval: section.Key("watch_interval").MustDuration(30 * time.Second),
+		UIEdits:       section.Key("ui_edits").MustString(ProvisionedDataSourceUIEditsAllow),
+	}
+
+	if s.WatchInterval < time.Second {
+		return fmt.Errorf("datasources.provisioning watch_interval must be at least 1s, got %s", s.WatchInterval)
+	}
+	switch s.UIEdits {
+	case ProvisionedDataSourceUIEditsAllow, ProvisionedDataSourceUIEditsWarn, ProvisionedDataSourceUIEditsBlock:
+	default:
+		return fmt.Errorf("datasources.provisioning ui_edits must be %s, %s or %s, got %q",
+			ProvisionedDataSourceUIEditsAllow, ProvisionedDataSourceUIEditsWarn, ProvisionedDataSourceUIEditsBlock, s.UIEdits)
+	}
+
+	cfg.DataSourceProvisioning = s
+	return nil
+}
@@ -0,0 +1,25 @@
+package setting
+
+import "time"
+
+// AuthMFASettings contains the settings of the two-factor authentication of users stored in the Grafana database.
+type AuthMFASettings struct {
+	Enabled bool
+	// Issuer is the name of the issuer shown in authenticator apps.
+	Issuer string
+	// EnrollmentGracePeriod is the time users have to enroll after an organization starts requiring MFA
+	// or after an administrator resets the enrollment of the user.
+	EnrollmentGracePeriod time.Duration
+	// AllowBasicAuth allows users with MFA to use basic authentication without a second factor.
+	AllowBasicAuth bool
+}
+
+func (cfg *Cfg) readAuthMFASettings() {
+	mfaSettings := AuthMFASettings{}
+	authMFA := cfg.SectionWithEnvOverrides("auth.mfa")
+	mfaSettings.Enabled = authMFA.Key("enabled").MustBool(false)
+	mfaSettings.Issuer = valueAsString(authMFA, "issuer", "Grafana")
+	mfaSettings.EnrollmentGracePeriod = authMFA.Key("enrollment_grace_period").MustDuration(24 * time.Hour)
+	mfaSettings.AllowBasicAuth = authMFA.Key("allow_basic_auth").MustBool(false)
+	cfg.MFA = mfaSettings
+}
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"fmt"
 	"strings"
 
 	"gopkg.in/ini.v1"
@@ -11,6 +12,20 @@ import (
 // PluginSettings maps plugin id to map of key/value settings.
 type PluginSettings map[string]map[string]string
 
+// PluginRepositorySettings is a private plugin repository the plugins are installed from, configured in a
+// [plugin_repository.<name>] section.
+type PluginRepositorySettings struct {
+	Name string
+	// URL is the base URL of a repository serving the grafana.com plugin API, like https://grafana.com/api/plugins.
+	URL string
+	// IndexFile is the path or the URL of a static index file listing the plugins of the repository.
+	IndexFile     string
+	SkipTLSVerify bool
+	// PublicKeyID and PublicKeyFile are the public key the plugins of the repository are signed with.
+	PublicKeyID   string
+	PublicKeyFile string
+}
+
 func extractPluginSettings(sections []*ini.Section) PluginSettings {
 	psMap := PluginSettings{}
 	for _, section := range sections {
@@ -55,5 +70,40 @@ func (cfg *Cfg) readPluginSettings(iniFile *ini.File) error {
 	// Installation token for managed plugins
 	cfg.PluginInstallToken = pluginsSection.Key("install_token").MustString("")
 
+	// Plugin repositories
+	cfg.PluginGrafanaComRepositoryDisabled = pluginsSection.Key("grafana_com_repository_disabled").MustBool(false)
+	repositories, err := readPluginRepositories(iniFile, util.SplitString(pluginsSection.Key("repositories").MustString("")))
+	if err != nil {
+		return err
+	}
+	cfg.PluginRepositories = repositories
+
 	return nil
 }
+
+func readPluginRepositories(iniFile *ini.File, names []string) ([]PluginRepositorySettings, error) {
+	repositories := make([]PluginRepositorySettings, 0, len(names))
+	for _, name := range names {
+		section, err := iniFile.GetSection("plugin_repository." + name)
+		if err != nil {
+			return nil, fmt.Errorf("plugin repository %q has no [plugin_repository.%s] section", name, name)
+		}
+
+		repository := PluginRepositorySettings{
+			Name:          name,
+			URL:           strings.TrimRight(section.Key("url").MustString(""), "/"),
+			IndexFile:     section.Key("index_file").MustString(""),
+			SkipTLSVerify: section.Key("skip_tls_verify").MustBool(false),
+			PublicKeyID:   section.Key("public_key_id").MustString(""),
+			PublicKeyFile: section.Key("public_key_file").MustString(""),
+		}
+		if (repository.URL == "") == (repository.IndexFile == "") {
+			return nil, fmt.Errorf("plugin repository %q must have either a url or an index_file", name)
+		}
+		if (repository.PublicKeyID == "") != (repository.PublicKeyFile == "") {
+			return nil, fmt.Errorf("plugin repository %q must have both a public_key_id and a public_key_file, or neither", name)
+		}
+		repositories = append(repositories, repository)
+	}
+	return repositories, nil
+}
@@ -96,3 +96,48 @@ func Test_readPluginSettings(t *testing.T) {
 		}
 	})
 }
+
+func Test_readPluginRepositories(t *testing.T) {
+	newCfg := func(t *testing.T, repositories string, keys map[string]map[string]string) *Cfg {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("plugins")
+		require.NoError(t, err)
+		_, err = sec.NewKey("repositories", repositories)
+		require.NoError(t, err)
+		for name, values := range keys {
+			sec, err := cfg.Raw.NewSection("plugin_repository." + name)
+			require.NoError(t, err)
+			for k, v := range values {
+				_, err = sec.NewKey(k, v)
+				require.NoError(t, err)
+			}
+		}
+		return cfg
+	}
+
+	t.Run("should read the repositories in order", func(t *testing.T) {
+		cfg := newCfg(t, "internal, mirror", map[string]map[string]string{
+			"internal": {"index_file": "/var/lib/plugins/index.json", "public_key_id": "acme", "public_key_file": "/etc/grafana/acme.asc"},
+			"mirror":   {"url": "https://plugins.example.com/api/plugins/", "skip_tls_verify": "true"},
+		})
+
+		require.NoError(t, cfg.readPluginSettings(cfg.Raw))
+		require.False(t, cfg.PluginGrafanaComRepositoryDisabled)
+		require.Equal(t, []PluginRepositorySettings{
+			{Name: "internal", IndexFile: "/var/lib/plugins/index.json", PublicKeyID: "acme", PublicKeyFile: "/etc/grafana/acme.asc"},
+			{Name: "mirror", URL: "https://plugins.example.com/api/plugins", SkipTLSVerify: true},
+		}, cfg.PluginRepositories)
+	})
+
+	t.Run("should fail for invalid repositories", func(t *testing.T) {
+		for _, keys := range []map[string]map[string]string{
+			{},
+			{"internal": {}},
+			{"internal": {"url": "https://plugins.example.com", "index_file": "/var/lib/plugins/index.json"}},
+			{"internal": {"url": "https://plugins.example.com", "public_key_id": "acme"}},
+		} {
+			cfg := newCfg(t, "internal", keys)
+			require.Error(t, cfg.readPluginSettings(cfg.Raw))
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// QueryResponseLimitActionTruncate truncates the responses above the limits and adds a notice to the last frame.
+	QueryResponseLimitActionTruncate = "truncate"
+	// QueryResponseLimitActionReject fails the queries whose responses are above the limits.
+	QueryResponseLimitActionReject = "reject"
+
+	queryResponseLimitsSection = "datasources.response_limits"
+)
+
+// QueryResponseLimits are the limits of the response of a query data request, 0 is unlimited.
+type QueryResponseLimits struct {
+	MaxFrames int
+	MaxRows   int
+	// MaxBytes is the maximum estimated size of the frames once serialized to JSON.
+	MaxBytes int64
+	Action   string
+}
+
+// QueryResponseLimitsSettings contains the settings that protect Grafana from oversized query responses.
+type QueryResponseLimitsSettings struct {
+	Enabled bool
+	Default QueryResponseLimits
+	// DataSourceTypes overrides the default limits for data source types.
+	DataSourceTypes map[string]QueryResponseLimits
+	// Orgs overrides the default limits for organizations.
+	Orgs map[int64]QueryResponseLimits
+}
+
+// Limits returns the limits of the responses of a data source type in an organization. When both the organization
+// and the data source type override the default limits, the stricter of their limits applies.
+func (s QueryResponseLimitsSettings) Limits(orgID int64, dataSourceType string) QueryResponseLimits {
+	orgLimits, hasOrg := s.Orgs[orgID]
+	typeLimits, hasType := s.DataSourceTypes[dataSourceType]
+	switch {
+	case hasOrg && hasType:
+		limits := QueryResponseLimits{
+			MaxFrames: stricterLimit(orgLimits.MaxFrames, typeLimits.MaxFrames),
+			MaxRows:   stricterLimit(orgLimits.MaxRows, typeLimits.MaxRows),
+			MaxBytes:  stricterLimit(orgLimits.MaxBytes, typeLimits.MaxBytes),
+			Action:    orgLimits.Action,
+		}
+		if typeLimits.Action == QueryResponseLimitActionReject {
+			limits.Action = QueryResponseLimitActionReject
+		}
+		return limits
+	case hasOrg:
+		return orgLimits
+	case hasType:
+		return typeLimits
+	}
+	return s.Default
+}
+
+// stricterLimit returns the lowest of two limits, 0 is unlimited.
+func stricterLimit[T int | int64](a, b T) T {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func (cfg *Cfg) readQueryResponseLimitsSettings() error {
+	section := cfg.SectionWithEnvOverrides(queryResponseLimitsSection)
+	s := QueryResponseLimitsSettings{
+		Enabled:         section.Key("enabled").MustBool(false),
+		DataSourceTypes: map[string]QueryResponseLimits{},
+		Orgs:            map[int64]QueryResponseLimits{},
+	}
+
+	var err error
+	if s.Default, err = cfg.readQueryResponseLimits(queryResponseLimitsSection, QueryResponseLimits{
+		Action: QueryResponseLimitActionTruncate,
+	}); err != nil {
+		return err
+	}
+
+	// The data source type and org sections override the default limits, see Limits for how they are combined.
+	// Their unset keys inherit the default limits.
+	typePrefix := queryResponseLimitsSection + ".type."
+	orgPrefix := queryResponseLimitsSection + ".org."
+	for _, sec := range cfg.Raw.Sections() {
+		name := sec.Name()
+		switch {
+		case strings.HasPrefix(name, typePrefix):
+			if s.DataSourceTypes[strings.TrimPrefix(name, typePrefix)], err = cfg.readQueryResponseLimits(name, s.Default); err != nil {
+				return err
+			}
+		case strings.HasPrefix(name, orgPrefix):
+			orgID, err := strconv.ParseInt(strings.TrimPrefix(name, orgPrefix), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid organization id in section %s: %w", name, err)
+			}
+			if s.Orgs[orgID], err = cfg.readQueryResponseLimits(name, s.Default); err != nil {
+				return err
+			}
+		}
+	}
+
+	cfg.QueryResponseLimits = s
+	return nil
+}
+
+func (cfg *Cfg) readQueryResponseLimits(name string, defaults QueryResponseLimits) (QueryResponseLimits, error) {
+	section := cfg.SectionWithEnvOverrides(name)
+	limits := QueryResponseLimits{
+		MaxFrames: section.Key("max_frames").MustInt(defaults.MaxFrames),
+		MaxRows:   section.Key("max_rows").MustInt(defaults.MaxRows),
+		MaxBytes:  section.Key("max_bytes").MustInt64(defaults.MaxBytes),
+		Action:    section.Key("action").MustString(defaults.Action),
+	}
+
+	if limits.MaxFrames < 0 || limits.MaxRows < 0 || limits.MaxBytes < 0 {
+		return QueryResponseLimits{}, fmt.Errorf("%s max_frames, max_rows and max_bytes must not be negative", name)
+	}
+	if limits.Action != QueryResponseLimitActionTruncate && limits.Action != QueryResponseLimitActionReject {
+		return QueryResponseLimits{}, fmt.Errorf("%s action must be %s or %s", name, QueryResponseLimitActionTruncate, QueryResponseLimitActionReject)
+	}
+
+	return limits, nil
+}
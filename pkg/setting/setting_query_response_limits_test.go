@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadQueryResponseLimitsSettings(t *testing.T) {
+	readSettings := func(t *testing.T, rawCfg string) (*Cfg, error) {
+		raw, err := ini.Load([]byte(rawCfg))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = raw
+		return cfg, cfg.readQueryResponseLimitsSettings()
+	}
+
+	t.Run("Should read the default limits", func(t *testing.T) {
+		cfg, err := readSettings(t, ``)
+		require.NoError(t, err)
+		require.False(t, cfg.QueryResponseLimits.Enabled)
+		require.Equal(t, QueryResponseLimits{Action: QueryResponseLimitActionTruncate}, cfg.QueryResponseLimits.Limits(1, "loki"))
+	})
+
+	t.Run("Should override the limits per data source type and org", func(t *testing.T) {
+		cfg, err := readSettings(t, `
+		[datasources.response_limits]
+		enabled = true
+		max_frames = 100
+		max_rows = 1000
+
+		[datasources.response_limits.type.loki]
+		max_rows = 5000
+		action = reject
+
+		[datasources.response_limits.org.2]
+		max_bytes = 1048576
+		`)
+		require.NoError(t, err)
+		require.True(t, cfg.QueryResponseLimits.Enabled)
+
+		limits := cfg.QueryResponseLimits
+		require.Equal(t, QueryResponseLimits{MaxFrames: 100, MaxRows: 1000, Action: "truncate"}, limits.Limits(1, "prometheus"))
+		require.Equal(t, QueryResponseLimits{MaxFrames: 100, MaxRows: 5000, Action: "reject"}, limits.Limits(1, "loki"))
+		require.Equal(t, QueryResponseLimits{MaxFrames: 100, MaxRows: 1000, MaxBytes: 1048576, Action: "truncate"}, limits.Limits(2, "prometheus"))
+		require.Equal(t, QueryResponseLimits{MaxFrames: 100, MaxRows: 1000, MaxBytes: 1048576, Action: "reject"}, limits.Limits(2, "loki"))
+	})
+
+	t.Run("Should apply the stricter of the data source type and org limits", func(t *testing.T) {
+		cfg, err := readSettings(t, `
+		[datasources.response_limits]
+		enabled = true
+
+		[datasources.response_limits.type.loki]
+		max_rows = 5000
+		max_bytes = 1048576
+
+		[datasources.response_limits.org.2]
+		max_frames = 10
+		max_rows = 10000
+		max_bytes = 10485760
+		`)
+		require.NoError(t, err)
+
+		limits := cfg.QueryResponseLimits
+		require.Equal(t, QueryResponseLimits{MaxFrames: 10, MaxRows: 5000, MaxBytes: 1048576, Action: "truncate"}, limits.Limits(2, "loki"))
+		require.Equal(t, QueryResponseLimits{MaxFrames: 10, MaxRows: 10000, MaxBytes: 10485760, Action: "truncate"}, limits.Limits(2, "prometheus"))
+	})
+
+	t.Run("Should fail with invalid limits", func(t *testing.T) {
+		_, err := readSettings(t, `
+		[datasources.response_limits]
+		max_rows = -1
+		`)
+		require.ErrorContains(t, err, "must not be negative")
+
+		_, err = readSettings(t, `
+		[datasources.response_limits.type.loki]
+		action = drop
+		`)
+		require.ErrorContains(t, err, "action must be truncate or reject")
+
+		_, err = readSettings(t, `
+		[datasources.response_limits.org.main]
+		max_rows = 10
+		`)
+		require.ErrorContains(t, err, "invalid organization id")
+	})
+}
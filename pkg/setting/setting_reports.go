@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// ReportsSettings contains the settings of the scheduled dashboard reports.
+type ReportsSettings struct {
+	Enabled bool
+	// CheckInterval is how often the reports that are due are looked up.
+	CheckInterval time.Duration
+	// RenderTimeout is the timeout of the rendering of a report.
+	RenderTimeout time.Duration
+	RenderWidth   int
+	RenderHeight  int
+	// RunHistoryRetention is how long the runs of the reports are kept.
+	RunHistoryRetention time.Duration
+}
+
+func (cfg *Cfg) readReportsSettings() error {
+	reportsSettings := ReportsSettings{}
+	reports := cfg.SectionWithEnvOverrides("reports")
+	reportsSettings.Enabled = reports.Key("enabled").MustBool(false)
+	reportsSettings.CheckInterval = reports.Key("check_interval").MustDuration(time.Minute)
+	if reportsSettings.CheckInterval < 10*time.Second {
+		return fmt.Errorf("reports check_interval must be at least 10s, got %s", reportsSettings.CheckInterval)
+	}
+	reportsSettings.RenderTimeout = reports.Key("render_timeout").MustDuration(time.Minute)
+	reportsSettings.RenderWidth = reports.Key("render_width").MustInt(1600)
+	reportsSettings.RenderHeight = reports.Key("render_height").MustInt(900)
+
+	retention, err := gtime.ParseDuration(reports.Key("run_history_retention").MustString("30d"))
+	if err != nil {
+		return fmt.Errorf("invalid reports run_history_retention: %w", err)
+	}
+	reportsSettings.RunHistoryRetention = retention
+
+	cfg.Reports = reportsSettings
+	return nil
+}
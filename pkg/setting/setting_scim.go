@@ -0,0 +1,19 @@
+package setting
+
+// AuthSCIMSettings contains the settings of the SCIM 2.0 server used by identity providers to provision users and teams.
+type AuthSCIMSettings struct {
+	Enabled bool
+	// MaxBulkOperations is the maximum number of operations of a bulk request.
+	MaxBulkOperations int
+	// MaxResults is the maximum number of resources returned by a list request.
+	MaxResults int
+}
+
+func (cfg *Cfg) readAuthSCIMSettings() {
+	scimSettings := AuthSCIMSettings{}
+	authSCIM := cfg.SectionWithEnvOverrides("auth.scim")
+	scimSettings.Enabled = authSCIM.Key("enabled").MustBool(false)
+	scimSettings.MaxBulkOperations = authSCIM.Key("max_bulk_operations").MustInt(100)
+	scimSettings.MaxResults = authSCIM.Key("max_results").MustInt(1000)
+	cfg.SCIM = scimSettings
+}
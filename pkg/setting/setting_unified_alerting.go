@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -100,6 +101,12 @@ type UnifiedAlertingSettings struct {
 	MaxStateSaveConcurrency   int
 	StatePeriodicSaveInterval time.Duration
 	RulesPerRuleGroupLimit    int64
+	// MaxInstancesPerRule is the maximum number of alert instances a single rule can produce. 0 means no limit.
+	MaxInstancesPerRule int64
+	// MaxInstancesPerOrg is the maximum number of alert instances of all rules in an organization. 0 means no limit.
+	MaxInstancesPerOrg int64
+	// MaxInstancesByOrg overrides MaxInstancesPerOrg for organizations.
+	MaxInstancesByOrg map[int64]int64
 
 	// Retention period for Alertmanager notification log entries.
 	NotificationLogRetention time.Duration
@@ -263,6 +270,18 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.MaxAttempts = ua.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
 
+	uaCfg.MaxInstancesPerRule = ua.Key("max_instances_per_rule").MustInt64(0)
+	if uaCfg.MaxInstancesPerRule < 0 {
+		return errors.New("value of setting 'max_instances_per_rule' cannot be negative")
+	}
+	uaCfg.MaxInstancesPerOrg = ua.Key("max_instances_per_org").MustInt64(0)
+	if uaCfg.MaxInstancesPerOrg < 0 {
+		return errors.New("value of setting 'max_instances_per_org' cannot be negative")
+	}
+	if uaCfg.MaxInstancesByOrg, err = readMaxInstancesByOrg(iniFile); err != nil {
+		return err
+	}
+
 	uaCfg.BaseInterval = SchedulerBaseInterval
 
 	// TODO: This was promoted from a feature toggle and is now the default behavior.
@@ -402,3 +421,24 @@ func splitTrim(s string, sep string) []string {
 	}
 	return spl
 }
+
+// readMaxInstancesByOrg reads the limits of alert instances of the [unified_alerting.org.<org id>] sections.
+func readMaxInstancesByOrg(iniFile *ini.File) (map[int64]int64, error) {
+	const orgPrefix = "unified_alerting.org."
+	limits := map[int64]int64{}
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), orgPrefix) || !section.HasKey("max_instances_per_org") {
+			continue
+		}
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(section.Name(), orgPrefix), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid organization id in section %s: %w", section.Name(), err)
+		}
+		limit, err := section.Key("max_instances_per_org").Int64()
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("value of setting 'max_instances_per_org' of section %s must be a positive number or 0", section.Name())
+		}
+		limits[orgID] = limit
+	}
+	return limits, nil
+}
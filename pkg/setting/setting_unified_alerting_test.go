@@ -298,3 +298,45 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxInstancesByOrg(t *testing.T) {
+	load := func(t *testing.T, rawCfg string) (*Cfg, error) {
+		f, err := ini.Load([]byte(rawCfg))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("should read the limits of the organizations", func(t *testing.T) {
+		cfg, err := load(t, `
+		[unified_alerting]
+		max_instances_per_org = 1000
+
+		[unified_alerting.org.2]
+		max_instances_per_org = 5000
+
+		[unified_alerting.org.3]
+		max_instances_per_org = 0
+		`)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), cfg.UnifiedAlerting.MaxInstancesPerOrg)
+		require.Equal(t, map[int64]int64{2: 5000, 3: 0}, cfg.UnifiedAlerting.MaxInstancesByOrg)
+	})
+
+	t.Run("should fail with an invalid organization id", func(t *testing.T) {
+		_, err := load(t, `
+		[unified_alerting.org.main]
+		max_instances_per_org = 10
+		`)
+		require.ErrorContains(t, err, "invalid organization id")
+	})
+
+	t.Run("should fail with a negative limit", func(t *testing.T) {
+		_, err := load(t, `
+		[unified_alerting.org.2]
+		max_instances_per_org = -1
+		`)
+		require.ErrorContains(t, err, "max_instances_per_org")
+	})
+}
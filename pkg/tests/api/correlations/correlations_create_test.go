@@ -358,4 +358,71 @@ func TestIntegrationCreateCorrelation(t *testing.T) {
 
 		require.NoError(t, res.Body.Close())
 	})
+
+	t.Run("Should correctly create an external correlation without a target data source", func(t *testing.T) {
+		configType := correlations.ConfigTypeExternal
+		transformation := correlations.Transformation{Type: "jsonpath", Expression: "$.ticket.id", MapValue: "ticketId"}
+		res := ctx.Post(PostParams{
+			url: fmt.Sprintf("/api/datasources/uid/%s/correlations", writableDs),
+			body: fmt.Sprintf(`{
+					"label": "Open ticket",
+					"config": {
+						"type": "%s",
+						"field": "line",
+						"target": { "url": "https://tickets.example.com/${ticketId}" },
+						"transformations": [
+							{"type": "jsonpath", "expression": "$.ticket.id", "mapValue": "ticketId"}
+						]
+					}
+				}`, configType),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var response correlations.CreateCorrelationResponseBody
+		err = json.Unmarshal(responseBody, &response)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		require.Nil(t, response.Result.TargetUID)
+		require.Equal(t, configType, response.Result.Config.Type)
+		require.Equal(t, map[string]any{"url": "https://tickets.example.com/${ticketId}"}, response.Result.Config.Target)
+		require.Equal(t, transformation, response.Result.Config.Transformations[0])
+
+		res = ctx.Get(GetParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", writableDs, response.Result.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var correlation correlations.Correlation
+		err = json.Unmarshal(responseBody, &correlation)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		require.Equal(t, configType, correlation.Config.Type)
+	})
+
+	t.Run("Should not create an external correlation without a target url", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url: fmt.Sprintf("/api/datasources/uid/%s/correlations", writableDs),
+			body: `{
+					"label": "Open ticket",
+					"config": {
+						"type": "external",
+						"field": "line",
+						"target": {}
+					}
+				}`,
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
 }
@@ -0,0 +1,107 @@
+package flux
+
+import (
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/promlib/exemplar"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// addExemplars adds the rows of the frames with a value in one of the trace ID columns as exemplars. The frames are
+// kept as they are, the time series panels ignore the string columns.
+func addExemplars(frames data.Frames, refID string, interval time.Duration, destinations []models.ExemplarTraceIDDestination) data.Frames {
+	sampler := exemplar.NewStandardDeviationSampler()
+	sampler.SetStep(interval)
+	labelTracker := exemplar.NewLabelTracker()
+	framer := exemplar.NewFramer(sampler, labelTracker)
+	framer.SetRefID(refID)
+	framer.SetMeta(exemplar.NewAnnotationsMeta())
+	for _, d := range destinations {
+		framer.SetLinks(d.Name, traceIDLinks(d))
+	}
+
+	for _, frame := range frames {
+		framer.AddFrame(frame)
+
+		timeField, valueField := exemplarFields(frame)
+		if timeField == nil || valueField == nil {
+			continue
+		}
+		for _, d := range destinations {
+			traceIDField, _ := frame.FieldByName(d.Name)
+			if traceIDField == nil || traceIDField.Type() != data.FieldTypeNullableString && traceIDField.Type() != data.FieldTypeString {
+				continue
+			}
+			for i := 0; i < frame.Rows(); i++ {
+				traceID, ok := traceIDField.ConcreteAt(i)
+				if !ok || traceID.(string) == "" {
+					continue
+				}
+				ts, ok := timeField.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				value, err := valueField.FloatAt(i)
+				if err != nil || math.IsNaN(value) {
+					continue
+				}
+
+				labels := make(map[string]string, len(valueField.Labels)+1)
+				for k, v := range valueField.Labels {
+					labels[k] = v
+				}
+				labels[d.Name] = traceID.(string)
+				labelTracker.Add(labels)
+				sampler.Add(exemplar.Exemplar{SeriesLabels: labels, Value: value, Timestamp: ts.(time.Time)})
+			}
+		}
+	}
+
+	result, err := framer.Frames()
+	if err != nil {
+		return frames
+	}
+	return result
+}
+
+// exemplarFields returns the first time field and the first numeric field of the frame.
+func exemplarFields(frame *data.Frame) (timeField *data.Field, valueField *data.Field) {
+	for _, field := range frame.Fields {
+		switch {
+		case timeField == nil && field.Type().Time():
+			timeField = field
+		case valueField == nil && field.Type().Numeric():
+			valueField = field
+		}
+	}
+	return timeField, valueField
+}
+
+// traceIDLinks returns the links of the trace ID column, they are the same as the links of the Prometheus exemplars.
+func traceIDLinks(d models.ExemplarTraceIDDestination) []data.DataLink {
+	var links []data.DataLink
+	if d.DatasourceUID != "" {
+		title := d.URLDisplayLabel
+		if title == "" {
+			title = "Query the trace"
+		}
+		links = append(links, data.DataLink{
+			Title: title,
+			Internal: &data.InternalDataLink{
+				DatasourceUID: d.DatasourceUID,
+				Query:         map[string]any{"query": "${__value.raw}", "queryType": "traceql"},
+			},
+		})
+	}
+	if d.URL != "" {
+		title := d.URLDisplayLabel
+		if title == "" {
+			title = "Go to " + d.URL
+		}
+		links = append(links, data.DataLink{Title: title, URL: d.URL, TargetBlank: true})
+	}
+	return links
+}
@@ -0,0 +1,49 @@
+package flux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestAddExemplars(t *testing.T) {
+	dr := executeMockedQuery(t, "exemplars", queryModel{MaxDataPoints: 100})
+	require.NoError(t, dr.Error)
+	require.Len(t, dr.Frames, 2)
+
+	t.Run("should add the trace IDs as exemplars", func(t *testing.T) {
+		frames := addExemplars(dr.Frames, "A", 30*time.Second, []models.ExemplarTraceIDDestination{
+			{Name: "trace_id", DatasourceUID: "tempo"},
+			{Name: "missing", URL: "https://example.com/${__value.raw}"},
+		})
+		require.Len(t, frames, 3)
+
+		exemplarFrame := frames[2]
+		require.Equal(t, "exemplar", exemplarFrame.Name)
+		require.Equal(t, "A", exemplarFrame.RefID)
+		require.Equal(t, data.DataTopicAnnotations, exemplarFrame.Meta.DataTopic)
+		require.Equal(t, 3, exemplarFrame.Rows())
+
+		traceIDField, _ := exemplarFrame.FieldByName("trace_id")
+		require.NotNil(t, traceIDField)
+		require.Equal(t, "abc", traceIDField.At(0))
+		require.Equal(t, "ghi", traceIDField.At(1))
+		require.Equal(t, "def", traceIDField.At(2))
+		require.Equal(t, "tempo", traceIDField.Config.Links[0].Internal.DatasourceUID)
+
+		hostField, _ := exemplarFrame.FieldByName("host")
+		require.NotNil(t, hostField)
+		require.Equal(t, "hst1", hostField.At(0))
+		require.Equal(t, "hst2", hostField.At(1))
+		require.Equal(t, float64(11), exemplarFrame.Fields[1].At(2))
+	})
+
+	t.Run("should not add an exemplar frame without trace IDs", func(t *testing.T) {
+		frames := addExemplars(dr.Frames, "A", time.Minute, []models.ExemplarTraceIDDestination{{Name: "missing", DatasourceUID: "tempo"}})
+		require.Len(t, frames, 2)
+	})
+}
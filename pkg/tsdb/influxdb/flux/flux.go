@@ -38,6 +38,9 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, tsdbQuery backend
 		// If the default changes also update labels/placeholder in config page.
 		maxSeries := dsInfo.MaxSeries
 		res := executeQuery(ctx, logger, *qm, r, maxSeries)
+		if res.Error == nil && len(dsInfo.ExemplarTraceIDDestinations) > 0 {
+			res.Frames = addExemplars(res.Frames, query.RefID, qm.Interval, dsInfo.ExemplarTraceIDDestinations)
+		}
 
 		tRes.Responses[query.RefID] = res
 	}
@@ -85,6 +85,8 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			InsecureGrpc:  jsonData.InsecureGrpc,
 			Token:         settings.DecryptedSecureJSONData["token"],
 			Timeout:       opts.Timeouts.Timeout,
+
+			ExemplarTraceIDDestinations: jsonData.ExemplarTraceIDDestinations,
 		}
 		return model, nil
 	}
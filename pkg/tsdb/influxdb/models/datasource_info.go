@@ -22,4 +22,15 @@ type DatasourceInfo struct {
 
 	// FlightSQL grpc connection
 	InsecureGrpc bool `json:"insecureGrpc"`
+
+	// ExemplarTraceIDDestinations are the columns of the Flux results added as exemplars
+	ExemplarTraceIDDestinations []ExemplarTraceIDDestination `json:"exemplarTraceIdDestinations"`
+}
+
+// ExemplarTraceIDDestination is a column with trace IDs, and where they link to.
+type ExemplarTraceIDDestination struct {
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	URLDisplayLabel string `json:"urlDisplayLabel"`
+	DatasourceUID   string `json:"datasourceUid"`
 }
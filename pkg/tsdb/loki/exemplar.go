@@ -0,0 +1,236 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/promlib/exemplar"
+)
+
+// exemplarMaxLines is the number of log lines the trace IDs are extracted from, for each metric query.
+const exemplarMaxLines = 1000
+
+type derivedFieldConfig struct {
+	MatcherRegex    string `json:"matcherRegex"`
+	MatcherType     string `json:"matcherType"`
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	URLDisplayLabel string `json:"urlDisplayLabel"`
+	DatasourceUID   string `json:"datasourceUid"`
+}
+
+// traceIDMatcher extracts a trace ID from the log lines, it is built from a derived field of the data source.
+type traceIDMatcher struct {
+	name  string
+	regex *regexp.Regexp
+	links []data.DataLink
+}
+
+// parseTraceIDMatchers returns the matchers of the derived fields that find a value in the log line with a regular
+// expression, and link it to a data source or a URL.
+func parseTraceIDMatchers(derivedFields []derivedFieldConfig) []traceIDMatcher {
+	matchers := make([]traceIDMatcher, 0, len(derivedFields))
+	for _, f := range derivedFields {
+		if f.MatcherType == "label" || f.Name == "" || f.MatcherRegex == "" {
+			continue
+		}
+		// The regular expressions are written for the browser, the ones Go does not support are ignored.
+		regex, err := regexp.Compile(f.MatcherRegex)
+		if err != nil || regex.NumSubexp() < 1 {
+			continue
+		}
+
+		var link data.DataLink
+		switch {
+		case f.DatasourceUID != "":
+			link = data.DataLink{
+				Title: f.URLDisplayLabel,
+				Internal: &data.InternalDataLink{
+					DatasourceUID: f.DatasourceUID,
+					Query:         map[string]any{"query": f.URL, "queryType": "traceql"},
+				},
+			}
+		case f.URL != "":
+			link = data.DataLink{Title: f.URLDisplayLabel, URL: f.URL}
+		default:
+			continue
+		}
+		matchers = append(matchers, traceIDMatcher{name: f.Name, regex: regex, links: []data.DataLink{link}})
+	}
+	return matchers
+}
+
+// logQueryFromMetricQuery returns the log query of the first range aggregation of the metric query, without the
+// unwrap stage, e.g. `{job="api"} |= "error"` for `sum(rate({job="api"} |= "error" [5m]))`.
+func logQueryFromMetricQuery(expr string) (string, bool) {
+	start, end, unwrap := -1, -1, -1
+	var quote rune
+	for i := 0; i < len(expr) && end < 0; i++ {
+		c := rune(expr[i])
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '{' && start < 0:
+			start = i
+		case c == '[' && start >= 0:
+			end = i
+		case c == '|' && start >= 0 && unwrap < 0:
+			if strings.HasPrefix(strings.TrimLeft(expr[i+1:], " \t\n"), "unwrap ") {
+				unwrap = i
+			}
+		}
+	}
+	if start < 0 || end < 0 {
+		return "", false
+	}
+	if unwrap >= 0 {
+		end = unwrap
+	}
+	return strings.TrimSpace(expr[start:end]), true
+}
+
+// addExemplars runs the log query of the metric query and adds the trace IDs found in the log lines as exemplars.
+// The value of an exemplar is the value of the series the log line belongs to, at the next step.
+func addExemplars(ctx context.Context, api *LokiAPI, query *lokiQuery, res *backend.DataResponse, matchers []traceIDMatcher, plog log.Logger) {
+	logQuery, ok := logQueryFromMetricQuery(query.Expr)
+	if !ok {
+		return
+	}
+
+	logsRes, err := api.DataQuery(ctx, lokiQuery{
+		Expr:                logQuery,
+		QueryType:           QueryTypeRange,
+		Direction:           DirectionBackward,
+		Step:                query.Step,
+		MaxLines:            exemplarMaxLines,
+		Start:               query.Start,
+		End:                 query.End,
+		RefID:               query.RefID,
+		SupportingQueryType: SupportingQueryExemplars,
+	}, ResponseOpts{})
+	if err == nil && logsRes.Error != nil {
+		err = logsRes.Error
+	}
+	if err != nil {
+		plog.Warn("Failed to query the log lines of the exemplars", "error", err)
+		return
+	}
+
+	series := make([]*data.Frame, 0, len(res.Frames))
+	for _, frame := range res.Frames {
+		if len(frame.Fields) == 2 && frame.Fields[0].Type() == data.FieldTypeTime && frame.Fields[1].Type() == data.FieldTypeFloat64 {
+			series = append(series, frame)
+		}
+	}
+	if len(series) == 0 {
+		return
+	}
+
+	sampler := exemplar.NewStandardDeviationSampler()
+	sampler.SetStep(query.Step)
+	labelTracker := exemplar.NewLabelTracker()
+	framer := exemplar.NewFramer(sampler, labelTracker)
+	framer.SetRefID(query.RefID)
+	framer.SetMeta(exemplar.NewAnnotationsMeta())
+	for _, frame := range res.Frames {
+		framer.AddFrame(frame)
+	}
+	for _, m := range matchers {
+		framer.SetLinks(m.name, m.links)
+	}
+
+	for _, frame := range logsRes.Frames {
+		if len(frame.Fields) < 3 || frame.Fields[0].Type() != data.FieldTypeJSON || frame.Fields[1].Type() != data.FieldTypeTime || frame.Fields[2].Type() != data.FieldTypeString {
+			continue
+		}
+		for i := 0; i < frame.Fields[1].Len(); i++ {
+			var streamLabels map[string]string
+			if err := json.Unmarshal(frame.Fields[0].At(i).(json.RawMessage), &streamLabels); err != nil {
+				continue
+			}
+			ts := frame.Fields[1].At(i).(time.Time)
+			line := frame.Fields[2].At(i).(string)
+
+			for _, m := range matchers {
+				match := m.regex.FindStringSubmatch(line)
+				if len(match) < 2 || match[1] == "" {
+					continue
+				}
+				labels, value, ok := seriesValueAt(series, streamLabels, ts)
+				if !ok {
+					break
+				}
+				labels[m.name] = match[1]
+				labelTracker.Add(labels)
+				sampler.Add(exemplar.Exemplar{SeriesLabels: labels, Value: value, Timestamp: ts})
+			}
+		}
+	}
+
+	frames, err := framer.Frames()
+	if err != nil {
+		plog.Warn("Failed to build the exemplar frame", "error", err)
+		return
+	}
+	res.Frames = frames
+}
+
+// seriesValueAt returns the labels and the value of the first series whose labels are all in the stream labels, at
+// the first step after ts.
+func seriesValueAt(series []*data.Frame, streamLabels map[string]string, ts time.Time) (map[string]string, float64, bool) {
+	for _, frame := range series {
+		timeField, valueField := frame.Fields[0], frame.Fields[1]
+		if !labelsMatch(valueField.Labels, streamLabels) {
+			continue
+		}
+		idx := sort.Search(timeField.Len(), func(i int) bool {
+			return !timeField.At(i).(time.Time).Before(ts)
+		})
+		if idx == timeField.Len() {
+			continue
+		}
+		labels := make(map[string]string, len(valueField.Labels)+1)
+		for k, v := range valueField.Labels {
+			labels[k] = v
+		}
+		return labels, valueField.At(idx).(float64), true
+	}
+	return nil, 0, false
+}
+
+func labelsMatch(seriesLabels data.Labels, streamLabels map[string]string) bool {
+	for k, v := range seriesLabels {
+		if streamLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDerivedFields(jsonData json.RawMessage) ([]derivedFieldConfig, error) {
+	if len(jsonData) == 0 {
+		return nil, nil
+	}
+	var settings struct {
+		DerivedFields []derivedFieldConfig `json:"derivedFields"`
+	}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return nil, fmt.Errorf("error reading settings: %w", err)
+	}
+	return settings.DerivedFields, nil
+}
@@ -0,0 +1,117 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogQueryFromMetricQuery(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		ok       bool
+	}{
+		{expr: `rate({job="api"}[5m])`, expected: `{job="api"}`, ok: true},
+		{expr: `sum by (level) (count_over_time({job="api"} |= "error" | logfmt [$__auto]))`, expected: `{job="api"} |= "error" | logfmt`, ok: true},
+		{expr: `sum(rate({job="api"} |~ "[a-z]+\"[" [1m])) / sum(rate({job="web"}[1m]))`, expected: `{job="api"} |~ "[a-z]+\"["`, ok: true},
+		{expr: "avg_over_time({job=\"api\"} | logfmt | unwrap duration | __error__=\"\" [1m])", expected: `{job="api"} | logfmt`, ok: true},
+		{expr: `{job="api"} |= "error"`, ok: false},
+		{expr: `vector(1)`, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			logQuery, ok := logQueryFromMetricQuery(tt.expr)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, logQuery)
+		})
+	}
+}
+
+func TestParseTraceIDMatchers(t *testing.T) {
+	matchers := parseTraceIDMatchers([]derivedFieldConfig{
+		{Name: "traceID", MatcherRegex: `traceID=(\w+)`, URL: "${__value.raw}", DatasourceUID: "tempo"},
+		{Name: "external", MatcherRegex: `id=(\w+)`, URL: "https://example.com/${__value.raw}", URLDisplayLabel: "Open"},
+		{Name: "label", MatcherRegex: "trace_id", MatcherType: "label", DatasourceUID: "tempo"},
+		{Name: "noGroup", MatcherRegex: `traceID=\w+`, DatasourceUID: "tempo"},
+		{Name: "unsupported", MatcherRegex: `(?<=traceID=)(\w+)`, DatasourceUID: "tempo"},
+		{Name: "noLink", MatcherRegex: `traceID=(\w+)`},
+	})
+
+	require.Len(t, matchers, 2)
+	require.Equal(t, "traceID", matchers[0].name)
+	require.Equal(t, "tempo", matchers[0].links[0].Internal.DatasourceUID)
+	require.Equal(t, "external", matchers[1].name)
+	require.Equal(t, "https://example.com/${__value.raw}", matchers[1].links[0].URL)
+}
+
+func TestNewInstanceSettingsWithInvalidDerivedFields(t *testing.T) {
+	factory := newInstanceSettings(httpclient.NewProvider(), backend.NewLoggerWith("logger", "test"))
+	instance, err := factory(context.Background(), backend.DataSourceInstanceSettings{
+		UID:      "loki",
+		URL:      "http://localhost:3100",
+		JSONData: []byte(`{"derivedFields": {"name": "traceID"}}`),
+	})
+	require.NoError(t, err)
+	require.Empty(t, instance.(*datasourceInfo).traceIDMatchers)
+}
+
+func TestAddExemplars(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	query := &lokiQuery{
+		Expr:      `sum by (level) (count_over_time({job="api"} | logfmt [1m]))`,
+		QueryType: QueryTypeRange,
+		Step:      time.Minute,
+		Start:     start,
+		End:       start.Add(3 * time.Minute),
+		RefID:     "A",
+	}
+	newSeries := func(level string, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = start.Add(time.Duration(i+1) * time.Minute)
+		}
+		return data.NewFrame("",
+			data.NewField("Time", nil, times),
+			data.NewField("Value", data.Labels{"level": level}, values),
+		)
+	}
+	matchers := parseTraceIDMatchers([]derivedFieldConfig{{Name: "traceID", MatcherRegex: `traceID=(\w+)`, URL: "${__value.raw}", DatasourceUID: "tempo"}})
+
+	response := []byte(`{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"job":"api","level":"error"},"values":[["1700000090000000000","msg=failed traceID=abc"],["1700000030000000000","msg=failed"]]},
+		{"stream":{"job":"api","level":"info"},"values":[["1700000150000000000","msg=done traceID=def"]]}
+	]}}`)
+	var logQuery *http.Request
+	api := makeMockedAPI(http.StatusOK, "application/json", response, func(req *http.Request) { logQuery = req }, false)
+
+	res := &backend.DataResponse{Frames: data.Frames{newSeries("error", 1, 5, 2), newSeries("info", 3, 4, 8)}}
+	addExemplars(context.Background(), api, query, res, matchers, backend.NewLoggerWith("logger", "test"))
+
+	require.NotNil(t, logQuery)
+	require.Equal(t, `{job="api"} | logfmt`, logQuery.URL.Query().Get("query"))
+	require.Equal(t, "Source=exemplars", logQuery.Header.Get("X-Query-Tags"))
+
+	require.Len(t, res.Frames, 3)
+	exemplarFrame := res.Frames[2]
+	require.Equal(t, "exemplar", exemplarFrame.Name)
+	require.Equal(t, data.DataTopicAnnotations, exemplarFrame.Meta.DataTopic)
+	require.Equal(t, 2, exemplarFrame.Rows())
+	require.Equal(t, []string{"Time", "Value", "level", "traceID"}, []string{exemplarFrame.Fields[0].Name, exemplarFrame.Fields[1].Name, exemplarFrame.Fields[2].Name, exemplarFrame.Fields[3].Name})
+
+	// The log line of 00:01:30 is in the step of 00:02:00 of the error series
+	require.Equal(t, time.Unix(1700000090, 0).UTC(), exemplarFrame.Fields[0].At(0).(time.Time).UTC())
+	require.Equal(t, float64(5), exemplarFrame.Fields[1].At(0))
+	require.Equal(t, "error", exemplarFrame.Fields[2].At(0))
+	require.Equal(t, "abc", exemplarFrame.Fields[3].At(0))
+	require.Equal(t, float64(8), exemplarFrame.Fields[1].At(1))
+	require.Equal(t, "info", exemplarFrame.Fields[2].At(1))
+	require.Equal(t, "def", exemplarFrame.Fields[3].At(1))
+	require.Equal(t, "tempo", exemplarFrame.Fields[3].Config.Links[0].Internal.DatasourceUID)
+}
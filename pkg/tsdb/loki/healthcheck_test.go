@@ -93,7 +93,7 @@ func Test_healthcheck(t *testing.T) {
 	t.Run("should do a successful health check", func(t *testing.T) {
 		httpProvider := getMockProvider[*healthCheckSuccessRoundTripper]()
 		s := &Service{
-			im:       datasource.NewInstanceManager(newInstanceSettings(httpProvider)),
+			im:       datasource.NewInstanceManager(newInstanceSettings(httpProvider, backend.NewLoggerWith("logger", "loki test"))),
 			features: featuremgmt.WithFeatures(featuremgmt.FlagLokiLogsDataplane, featuremgmt.FlagLokiMetricDataplane),
 			tracer:   tracing.InitializeTracerForTest(),
 			logger:   backend.NewLoggerWith("logger", "loki test"),
@@ -112,7 +112,7 @@ func Test_healthcheck(t *testing.T) {
 	t.Run("should return an error for an unsuccessful health check", func(t *testing.T) {
 		httpProvider := getMockProvider[*healthCheckFailRoundTripper]()
 		s := &Service{
-			im:       datasource.NewInstanceManager(newInstanceSettings(httpProvider)),
+			im:       datasource.NewInstanceManager(newInstanceSettings(httpProvider, backend.NewLoggerWith("logger", "loki test"))),
 			features: featuremgmt.WithFeatures(featuremgmt.FlagLokiLogsDataplane, featuremgmt.FlagLokiMetricDataplane),
 			tracer:   tracing.InitializeTracerForTest(),
 			logger:   backend.NewLoggerWith("logger", "loki test"),
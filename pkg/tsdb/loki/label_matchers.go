@@ -0,0 +1,102 @@
+package loki
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// variablesWithBraces are interpolated when the query is executed, so they are not stream selectors.
+var variablesWithBraces = []string{varIntervalMsAlt, varIntervalAlt, varRangeMsAlt, varRangeSAlt, varRangeAlt}
+
+// InjectStreamMatchers adds the matchers to every stream selector of the query, so the log lines and samples returned
+// by the query always come from streams that match them. LogQL has no parser we can use here, so the query is
+// scanned for stream selectors, which are the only curly braces outside of strings. Anything the scanner does not
+// understand, like comments, makes the query fail rather than risking a stream selector to be missed.
+func InjectStreamMatchers(expr string, matchers []*labels.Matcher) (string, error) {
+	if len(matchers) == 0 {
+		return expr, nil
+	}
+
+	rendered := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		rendered = append(rendered, matcher.String())
+	}
+	injected := strings.Join(rendered, ", ")
+
+	var sb strings.Builder
+	selectorStart := -1
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '"', '`':
+			end, err := stringLiteralEnd(expr, i)
+			if err != nil {
+				return "", err
+			}
+			if selectorStart < 0 {
+				sb.WriteString(expr[i : end+1])
+			}
+			i = end
+		case '#', '\'':
+			return "", fmt.Errorf("unexpected %q at position %d", c, i)
+		case '$':
+			variable := variableWithBracesAt(expr, i)
+			if selectorStart < 0 {
+				sb.WriteString(expr[i : i+len(variable)+1])
+			}
+			i += len(variable)
+		case '{':
+			if selectorStart >= 0 {
+				return "", fmt.Errorf("unexpected { at position %d", i)
+			}
+			selectorStart = i
+		case '}':
+			if selectorStart < 0 {
+				return "", fmt.Errorf("unexpected } at position %d", i)
+			}
+			selector := strings.TrimSpace(expr[selectorStart+1 : i])
+			if selector == "" {
+				sb.WriteString("{" + injected + "}")
+			} else {
+				sb.WriteString("{" + selector + ", " + injected + "}")
+			}
+			selectorStart = -1
+		default:
+			if selectorStart < 0 {
+				sb.WriteByte(c)
+			}
+		}
+	}
+
+	if selectorStart >= 0 {
+		return "", fmt.Errorf("unclosed stream selector at position %d", selectorStart)
+	}
+	return sb.String(), nil
+}
+
+// stringLiteralEnd returns the position of the quote that closes the string starting at start.
+func stringLiteralEnd(expr string, start int) (int, error) {
+	quote := expr[start]
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed string at position %d", start)
+}
+
+// variableWithBracesAt returns the variable starting at the $ at start without the $, or an empty string.
+func variableWithBracesAt(expr string, start int) string {
+	for _, variable := range variablesWithBraces {
+		if strings.HasPrefix(expr[start:], variable) {
+			return variable[1:]
+		}
+	}
+	return ""
+}
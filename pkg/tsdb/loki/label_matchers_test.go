@@ -0,0 +1,90 @@
+package loki
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectStreamMatchers(t *testing.T) {
+	matchers := []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchRegexp, "namespace", "payments-.*"),
+		labels.MustNewMatcher(labels.MatchEqual, "cluster", "prod"),
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Log query",
+			query:    `{app="checkout"} |= "error"`,
+			expected: `{app="checkout", namespace=~"payments-.*", cluster="prod"} |= "error"`,
+		},
+		{
+			name:     "Log query with a matcher on the same label",
+			query:    `{namespace="billing"}`,
+			expected: `{namespace="billing", namespace=~"payments-.*", cluster="prod"}`,
+		},
+		{
+			name:     "Empty stream selector",
+			query:    `{ }`,
+			expected: `{namespace=~"payments-.*", cluster="prod"}`,
+		},
+		{
+			name:     "Metric query with several stream selectors",
+			query:    `sum(rate({app="a"}[$__auto])) / sum(rate({app="b"} | json [${__interval}]))`,
+			expected: `sum(rate({app="a", namespace=~"payments-.*", cluster="prod"}[$__auto])) / sum(rate({app="b", namespace=~"payments-.*", cluster="prod"} | json [${__interval}]))`,
+		},
+		{
+			name:     "Curly braces in strings",
+			query:    "{app=\"a\"} |~ \"x{2}\\\"}\" | line_format `{{.message}}`",
+			expected: "{app=\"a\", namespace=~\"payments-.*\", cluster=\"prod\"} |~ \"x{2}\\\"}\" | line_format `{{.message}}`",
+		},
+		{
+			name:     "Curly braces in a stream selector value",
+			query:    `{app="}"}`,
+			expected: `{app="}", namespace=~"payments-.*", cluster="prod"}`,
+		},
+		{
+			name:      "Unclosed stream selector",
+			query:     `{app="a"`,
+			expectErr: true,
+		},
+		{
+			name:      "Nested curly braces",
+			query:     `{app="a", {}`,
+			expectErr: true,
+		},
+		{
+			name:      "Unexpected closing curly brace",
+			query:     `{app="a"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "Unclosed string",
+			query:     `{app="a}`,
+			expectErr: true,
+		},
+		{
+			name:      "Comment",
+			query:     "{app=\"a\"} # \"\n{app=\"b\"} \"",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := InjectStreamMatchers(tt.query, matchers)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, expr)
+		})
+	}
+}
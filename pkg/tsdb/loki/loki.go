@@ -41,11 +41,12 @@ var (
 )
 
 func ProvideService(httpClientProvider *httpclient.Provider, features featuremgmt.FeatureToggles, tracer tracing.Tracer) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.loki")
 	return &Service{
-		im:       datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		im:       datasource.NewInstanceManager(newInstanceSettings(httpClientProvider, logger)),
 		features: features,
 		tracer:   tracer,
-		logger:   backend.NewLoggerWith("logger", "tsdb.loki"),
+		logger:   logger,
 	}
 }
 
@@ -64,6 +65,9 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 
+	// traceIDMatchers extract the trace IDs added as exemplars to the metric queries
+	traceIDMatchers []traceIDMatcher
+
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
@@ -73,6 +77,7 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	Exemplar            *bool   `json:"exemplar,omitempty"`
 }
 
 type ResponseOpts struct {
@@ -86,7 +91,7 @@ func parseQueryModel(raw json.RawMessage) (*QueryJSONModel, error) {
 	return model, err
 }
 
-func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.InstanceFactoryFunc {
+func newInstanceSettings(httpClientProvider *httpclient.Provider, logger log.Logger) datasource.InstanceFactoryFunc {
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions(ctx)
 		if err != nil {
@@ -98,10 +103,17 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 
+		// the derived fields are only used for the exemplars, so the data source works without them
+		derivedFields, err := parseDerivedFields(settings.JSONData)
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to parse the derived fields, the exemplars of the metric queries are disabled", "datasourceUID", settings.UID, "error", err)
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			streams:    make(map[string]data.FrameJSONCache),
+			HTTPClient:      client,
+			URL:             settings.URL,
+			traceIDMatchers: parseTraceIDMatchers(derivedFields),
+			streams:         make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
 	}
@@ -196,7 +208,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.traceIDMatchers, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -205,7 +217,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.traceIDMatchers, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -213,7 +225,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, traceIDMatchers []traceIDMatcher, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -236,6 +248,8 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		queryRes.Error = err
+	} else if query.Exemplar && query.QueryType == QueryTypeRange && len(traceIDMatchers) > 0 && queryRes.Error == nil {
+		addExemplars(ctx, api, query, queryRes, traceIDMatchers, plog)
 	}
 
 	return *queryRes
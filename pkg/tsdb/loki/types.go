@@ -21,6 +21,8 @@ const (
 	SupportingQueryDataSample                         = dataquery.SupportingQueryTypeDataSample
 	SupportingQueryInfiniteScroll                     = dataquery.SupportingQueryTypeInfiniteScroll
 	SupportingQueryNone           SupportingQueryType = "none"
+	// SupportingQueryExemplars is the log query of a metric query, run to find the trace IDs of its exemplars.
+	SupportingQueryExemplars SupportingQueryType = "exemplars"
 )
 
 const (
@@ -39,4 +41,5 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	Exemplar            bool
 }
@@ -14,6 +14,7 @@ export interface FormModel {
   user: string;
   password: string;
   email: string;
+  mfaCode?: string;
 }
 
 interface Props {
@@ -32,6 +33,7 @@ interface Props {
     passwordHint: string;
     showDefaultPasswordWarning: boolean;
     loginErrorMessage: string | undefined;
+    mfaRequired: boolean;
   }) => JSX.Element;
 }
 
@@ -40,6 +42,7 @@ interface State {
   isChangingPassword: boolean;
   showDefaultPasswordWarning: boolean;
   loginErrorMessage?: string;
+  mfaRequired: boolean;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
@@ -52,6 +55,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
       isChangingPassword: false,
       showDefaultPasswordWarning: false,
       loginErrorMessage: config.loginError,
+      mfaRequired: false,
     };
   }
 
@@ -103,8 +107,11 @@ export class LoginCtrl extends PureComponent<Props, State> {
       })
       .catch((err) => {
         const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
+        const messageId = isFetchError(err) ? err.data?.messageId : undefined;
         this.setState({
           isLoggingIn: false,
+          // the password was correct, ask for the code of the second factor
+          mfaRequired: this.state.mfaRequired || messageId === 'mfa.code-required' || messageId === 'mfa.invalid-code',
           loginErrorMessage: fetchErrorMessage || t('login.error.unknown', 'Unknown error occurred'),
         });
       });
@@ -132,7 +139,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage } = this.state;
+    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage, mfaRequired } = this.state;
     const { login, toGrafana, changePassword } = this;
     const { loginHint, passwordHint, disableLoginForm, disableUserSignUp } = config;
 
@@ -151,6 +158,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
           isChangingPassword,
           showDefaultPasswordWarning,
           loginErrorMessage,
+          mfaRequired,
         })}
       </>
     );
@@ -165,6 +173,10 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
     case 'password-auth.failed':
     case 'password-auth.invalid':
       return t('login.error.invalid-user-or-password', 'Invalid username or password');
+    case 'mfa.code-required':
+      return t('login.error.mfa-code-required', 'Enter the code from your authenticator app');
+    case 'mfa.invalid-code':
+      return t('login.error.mfa-invalid-code', 'Invalid two-factor authentication code');
     case 'login-attempt.blocked':
       return t(
         'login.error.blocked',
@@ -17,12 +17,14 @@ interface Props {
   isLoggingIn: boolean;
   passwordHint: string;
   loginHint: string;
+  mfaRequired?: boolean;
 }
 
-export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, loginHint }: Props) => {
+export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, loginHint, mfaRequired }: Props) => {
   const styles = useStyles2(getStyles);
   const usernameId = useId();
   const passwordId = useId();
+  const mfaCodeId = useId();
   const {
     handleSubmit,
     register,
@@ -58,6 +60,25 @@ export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, login
             placeholder={passwordHint || t('login.form.password-placeholder', 'password')}
           />
         </Field>
+        {mfaRequired && (
+          <Field
+            label={t('login.form.mfa-code-label', 'Two-factor authentication code')}
+            description={t(
+              'login.form.mfa-code-description',
+              'Enter the code from your authenticator app or a recovery code'
+            )}
+            invalid={!!errors.mfaCode}
+            error={errors.mfaCode?.message}
+          >
+            <Input
+              {...register('mfaCode', { required: t('login.form.mfa-code-required', 'Code is required') })}
+              id={mfaCodeId}
+              autoFocus
+              autoComplete="one-time-code"
+              placeholder={t('login.form.mfa-code-placeholder', 'code')}
+            />
+          </Field>
+        )}
         <Button
           type="submit"
           data-testid={selectors.pages.Login.submit}
@@ -35,6 +35,7 @@ export const LoginPage = () => {
         isChangingPassword,
         showDefaultPasswordWarning,
         loginErrorMessage,
+        mfaRequired,
       }) => (
         <LoginLayout isChangingPassword={isChangingPassword}>
           {!isChangingPassword && (
@@ -46,7 +47,13 @@ export const LoginPage = () => {
               )}
 
               {!disableLoginForm && (
-                <LoginForm onSubmit={login} loginHint={loginHint} passwordHint={passwordHint} isLoggingIn={isLoggingIn}>
+                <LoginForm
+                  onSubmit={login}
+                  loginHint={loginHint}
+                  passwordHint={passwordHint}
+                  isLoggingIn={isLoggingIn}
+                  mfaRequired={mfaRequired}
+                >
                   <Stack justifyContent="flex-end">
                     {!config.auth.disableLogin && (
                       <LinkButton
@@ -24,6 +24,11 @@ export const getTransformationVars = (
     }
   } else if (transformation.type === SupportedTransformationType.Logfmt) {
     transformVal = logfmt.parse(fieldValue);
+  } else if (transformation.type === SupportedTransformationType.JsonPath && transformation.expression) {
+    const value = getJsonPathValue(fieldValue, transformation.expression);
+    if (value !== undefined) {
+      transformVal[transformation.mapValue || fieldName] = typeof value === 'string' ? value : safeStringifyValue(value);
+    }
   }
 
   Object.keys(transformVal).forEach((key) => {
@@ -34,3 +39,36 @@ export const getTransformationVars = (
 
   return transformationScopedVars;
 };
+
+const jsonPathSegment = /^(?:\.([^.[]+)|\[(?:'([^']+)'|"([^"]+)"|(\d+))\])/;
+
+/**
+ * Evaluates the subset of JSONPath supported by correlations: the root `$` followed by keys (`.key` or `['key']`)
+ * and array indexes (`[0]`). Returns undefined if the value is not valid JSON or the path does not match.
+ */
+export const getJsonPathValue = (fieldValue: unknown, expression: string): unknown => {
+  if (!expression.startsWith('$')) {
+    return undefined;
+  }
+
+  let current: unknown;
+  try {
+    current = typeof fieldValue === 'string' ? JSON.parse(fieldValue) : fieldValue;
+  } catch (e) {
+    return undefined;
+  }
+
+  let rest = expression.slice(1);
+  while (rest.length > 0) {
+    const match = rest.match(jsonPathSegment);
+    if (!match || current === null || typeof current !== 'object') {
+      return undefined;
+    }
+
+    const key = match[1] ?? match[2] ?? match[3] ?? match[4];
+    current = (current as Record<string, unknown>)[key];
+    rest = rest.slice(match[0].length);
+  }
+
+  return current;
+};
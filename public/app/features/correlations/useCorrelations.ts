@@ -26,7 +26,8 @@ export interface CorrelationsResponse {
 
 export interface CorrelationData extends Omit<Correlation, 'sourceUID' | 'targetUID'> {
   source: DataSourceInstanceSettings;
-  target: DataSourceInstanceSettings;
+  // undefined for external correlations, which open a URL instead of querying a data source
+  target?: DataSourceInstanceSettings;
 }
 
 export interface CorrelationsData {
@@ -42,7 +43,8 @@ const toEnrichedCorrelationData = ({
   ...correlation
 }: Correlation): CorrelationData | undefined => {
   const sourceDatasource = getDataSourceSrv().getInstanceSettings(sourceUID);
-  const targetDatasource = getDataSourceSrv().getInstanceSettings(targetUID);
+  const isExternal = correlation.config?.type === 'external';
+  const targetDatasource = isExternal ? undefined : getDataSourceSrv().getInstanceSettings(targetUID);
 
   // According to #72258 we will remove logic to handle orgId=0/null as global correlations.
   // This logging is to check if there are any customers who did not migrate existing correlations.
@@ -54,8 +56,7 @@ const toEnrichedCorrelationData = ({
   if (
     sourceDatasource &&
     sourceDatasource?.uid !== undefined &&
-    targetDatasource &&
-    targetDatasource.uid !== undefined
+    (isExternal || (targetDatasource && targetDatasource.uid !== undefined))
   ) {
     return {
       ...correlation,
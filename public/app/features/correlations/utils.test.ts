@@ -1,4 +1,11 @@
-import { DataFrame, DataSourceInstanceSettings, FieldType, toDataFrame } from '@grafana/data';
+import {
+  DataFrame,
+  DataLinkConfigOrigin,
+  DataSourceInstanceSettings,
+  FieldType,
+  SupportedTransformationType,
+  toDataFrame,
+} from '@grafana/data';
 
 import { CorrelationData } from './useCorrelations';
 import { attachCorrelationsToDataFrames } from './utils';
@@ -68,6 +75,38 @@ describe('correlations utils', () => {
     // Prometheus value (linked to Elastic)
     expect(testDataFrames[2].fields[0].config.links).toHaveLength(1);
   });
+
+  it('attaches external correlations as links to the target url', () => {
+    const { testDataFrames, refIdMap } = setup();
+    const loki = { uid: 'loki-uid', name: 'loki' } as DataSourceInstanceSettings;
+    const transformations = [{ type: SupportedTransformationType.JsonPath, expression: '$.ticket', mapValue: 'ticket' }];
+    const correlations: CorrelationData[] = [
+      {
+        uid: 'loki-to-tickets',
+        label: 'open ticket',
+        source: loki,
+        config: {
+          type: 'external',
+          field: 'line',
+          target: { url: 'https://tickets.example.com/${ticket}' },
+          transformations,
+        },
+        provisioned: false,
+      },
+    ];
+
+    attachCorrelationsToDataFrames(testDataFrames, correlations, refIdMap);
+
+    expect(testDataFrames[0].fields[0].config.links).toEqual([
+      {
+        title: 'open ticket',
+        url: 'https://tickets.example.com/${ticket}',
+        targetBlank: true,
+        transformations,
+        origin: DataLinkConfigOrigin.Correlations,
+      },
+    ]);
+  });
 });
 
 function setup() {
@@ -54,6 +54,18 @@ const decorateDataFrameWithInternalDataLinks = (dataFrame: DataFrame, correlatio
     field.config.links = field.config.links?.filter((link) => link.origin !== DataLinkConfigOrigin.Correlations) || [];
     correlations.map((correlation) => {
       if (correlation.config?.field === field.name) {
+        if (correlation.config.type === 'external' || !correlation.target) {
+          const { url } = (correlation.config.target || {}) as { url?: string };
+          field.config.links!.push({
+            url: url || '',
+            title: correlation.label || '',
+            targetBlank: true,
+            transformations: correlation.config.transformations,
+            origin: DataLinkConfigOrigin.Correlations,
+          });
+          return;
+        }
+
         const targetQuery = correlation.config?.target || {};
         field.config.links!.push({
           internal: {
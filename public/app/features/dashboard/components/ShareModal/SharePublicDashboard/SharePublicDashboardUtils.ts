@@ -26,6 +26,10 @@ export interface PublicDashboard extends PublicDashboardSettings {
   timeSettings?: object;
   share: PublicDashboardShareType;
   recipients?: Array<{ uid: string; recipient: string }>;
+  expiresAt?: string;
+  allowedCidrs?: string[];
+  queriesPerMinute?: number;
+  maxConcurrentQueries?: number;
 }
 
 export interface SessionDashboard {
@@ -56,7 +56,8 @@ export class DashboardLoaderSrv {
         })
         .catch((e) => {
           const isPublicDashboardPaused =
-            e.data.statusCode === 403 && e.data.messageId === 'publicdashboards.notEnabled';
+            e.data.statusCode === 403 &&
+            (e.data.messageId === 'publicdashboards.notEnabled' || e.data.messageId === 'publicdashboards.expired');
           const isPublicDashboardNotFound =
             e.data.statusCode === 404 && e.data.messageId === 'publicdashboards.notFound';
           const isDashboardNotFound =
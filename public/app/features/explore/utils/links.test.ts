@@ -458,6 +458,47 @@ describe('explore links utils', () => {
       );
     });
 
+    it('returns internal links with jsonpath transformation', () => {
+      const transformationLink: DataLink = {
+        title: '',
+        url: '',
+        internal: {
+          query: { query: 'http_requests{app=${application} user=${msg}}' },
+          datasourceUid: 'uid_1',
+          datasourceName: 'test_ds',
+          transformations: [
+            { type: SupportedTransformationType.JsonPath, expression: '$.app.name', mapValue: 'application' },
+            { type: SupportedTransformationType.JsonPath, expression: "$['users'][0]" },
+          ],
+        },
+      };
+
+      const { field, range, dataFrame } = setup(transformationLink, true, {
+        name: 'msg',
+        type: FieldType.string,
+        values: ['{"app":{"name":"foo"},"users":["bar"]}', '{"app":{"name":"foo"}}'],
+        config: {
+          links: [transformationLink],
+        },
+      });
+
+      const links = [
+        getFieldLinksForExplore({ field, rowIndex: 0, range, dataFrame }),
+        getFieldLinksForExplore({ field, rowIndex: 1, range, dataFrame }),
+      ];
+      expect(links[0]).toHaveLength(1);
+      expect(links[0][0].href).toBe(
+        `/explore?left=${encodeURIComponent(
+          '{"range":{"from":"now-1h","to":"now"},"datasource":"uid_1","queries":[{"query":"http_requests{app=foo user=bar}"}]}'
+        )}`
+      );
+      // the path does not match so the msg variable keeps the field value
+      expect(links[1]).toHaveLength(1);
+      expect(links[1][0].variables).toContainEqual(
+        expect.objectContaining({ variableName: 'application', value: 'foo' })
+      );
+    });
+
     it('returns internal links with logfmt with correct data on transformation-defined field', () => {
       const transformationLink: DataLink = {
         title: '',
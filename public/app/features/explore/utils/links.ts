@@ -17,6 +17,7 @@ import {
   CoreApp,
   SplitOpenOptions,
   DataLinkPostProcessor,
+  DataLinkTransformationConfig,
   ExploreUrlState,
   urlUtil,
 } from '@grafana/data';
@@ -158,10 +159,30 @@ export const getFieldLinksForExplore = (options: {
       return DATA_LINK_FILTERS.every((filter) => filter(link, scopedVars));
     });
 
+    const getTransformationsVars = (transformations: DataLinkTransformationConfig[] | undefined) => {
+      let transformationsVars: ScopedVars = {};
+      transformations?.forEach((transformation) => {
+        let fieldValue;
+        if (transformation.field) {
+          const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
+          fieldValue = transformField?.values[rowIndex];
+        } else {
+          fieldValue = field.values[rowIndex];
+        }
+
+        transformationsVars = {
+          ...transformationsVars,
+          ...getTransformationVars(transformation, fieldValue, field.name),
+        };
+      });
+      return transformationsVars;
+    };
+
     const fieldLinks = links.map((link) => {
       if (!link.internal) {
-        const replace: InterpolateFunction = (value, vars) =>
-          getTemplateSrv().replace(value, { ...vars, ...scopedVars });
+        // external correlations can extract variables from the field value with transformations
+        const linkVars = { ...scopedVars, ...getTransformationsVars(link.transformations) };
+        const replace: InterpolateFunction = (value, vars) => getTemplateSrv().replace(value, { ...vars, ...linkVars });
 
         const linkModel = getLinkSrv().getDataLinkUIModel(link, replace, field);
         if (!linkModel.title) {
@@ -169,25 +190,7 @@ export const getFieldLinksForExplore = (options: {
         }
         return linkModel;
       } else {
-        let internalLinkSpecificVars: ScopedVars = {};
-        if (link.internal?.transformations) {
-          link.internal?.transformations.forEach((transformation) => {
-            let fieldValue;
-            if (transformation.field) {
-              const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
-              fieldValue = transformField?.values[rowIndex];
-            } else {
-              fieldValue = field.values[rowIndex];
-            }
-
-            internalLinkSpecificVars = {
-              ...internalLinkSpecificVars,
-              ...getTransformationVars(transformation, fieldValue, field.name),
-            };
-          });
-        }
-
-        const allVars = { ...scopedVars, ...internalLinkSpecificVars };
+        const allVars = { ...scopedVars, ...getTransformationsVars(link.internal.transformations) };
         const variableData = getVariableUsageInfo(link, allVars);
         let variables: VariableInterpolation[] = [];
 
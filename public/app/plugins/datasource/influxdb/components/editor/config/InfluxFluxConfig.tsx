@@ -5,8 +5,10 @@ import {
   DataSourcePluginOptionsEditorProps,
   onUpdateDatasourceJsonDataOption,
   onUpdateDatasourceSecureJsonDataOption,
+  updateDatasourcePluginJsonDataOption,
   updateDatasourcePluginResetOption,
 } from '@grafana/data';
+import { ExemplarsSettings } from '@grafana/prometheus';
 import { InlineField, InlineFieldRow, Input, SecretInput } from '@grafana/ui';
 
 import { InfluxOptions, InfluxSecureJsonData } from '../../../types';
@@ -72,6 +74,13 @@ export const InfluxFluxConfig = (props: Props) => {
           />
         </InlineField>
       </InlineFieldRow>
+
+      <ExemplarsSettings
+        options={jsonData.exemplarTraceIdDestinations}
+        onChange={(exemplarOptions) =>
+          updateDatasourcePluginJsonDataOption(props, 'exemplarTraceIdDestinations', exemplarOptions)
+        }
+      />
     </>
   );
 };
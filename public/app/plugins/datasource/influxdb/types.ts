@@ -1,4 +1,5 @@
 import { AdHocVariableFilter, DataQuery, DataSourceJsonData } from '@grafana/data';
+import { ExemplarTraceIdDestination } from '@grafana/prometheus';
 
 export const DEFAULT_POLICY = 'default';
 
@@ -20,6 +21,7 @@ export interface InfluxOptions extends DataSourceJsonData {
   organization?: string;
   defaultBucket?: string;
   maxSeries?: number;
+  exemplarTraceIdDestinations?: ExemplarTraceIdDestination[];
 
   // With SQL
   metadata?: Array<Record<string, string>>;
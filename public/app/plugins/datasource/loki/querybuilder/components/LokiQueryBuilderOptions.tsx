@@ -2,7 +2,7 @@ import { trim } from 'lodash';
 import React, { useMemo, useState } from 'react';
 
 import { CoreApp, isValidDuration, isValidGrafanaDuration, SelectableValue } from '@grafana/data';
-import { EditorField, EditorRow, QueryOptionGroup } from '@grafana/experimental';
+import { EditorField, EditorRow, EditorSwitch, QueryOptionGroup } from '@grafana/experimental';
 import { config, reportInteraction } from '@grafana/runtime';
 import { Alert, AutoSizeInput, RadioButtonGroup, Select } from '@grafana/ui';
 
@@ -66,6 +66,11 @@ export const LokiQueryBuilderOptions = React.memo<Props>(
       onRunQuery();
     }
 
+    function onExemplarChange(e: React.SyntheticEvent<HTMLInputElement>) {
+      onChange({ ...query, exemplar: e.currentTarget.checked });
+      onRunQuery();
+    }
+
     const queryType = getLokiQueryType(query);
     const isLogQuery = isLogsQuery(query.expr);
 
@@ -126,6 +131,14 @@ export const LokiQueryBuilderOptions = React.memo<Props>(
                   onCommitChange={onStepChange}
                 />
               </EditorField>
+              {shouldShowExemplarSwitch(queryType, app) && (
+                <EditorField
+                  label="Exemplars"
+                  tooltip="Adds the trace IDs found in the log lines by the derived fields linked to a data source as exemplars."
+                >
+                  <EditorSwitch value={query.exemplar || false} onChange={onExemplarChange} />
+                </EditorField>
+              )}
               {query.resolution !== undefined && query.resolution > 1 && (
                 <>
                   <EditorField
@@ -169,6 +182,10 @@ export const LokiQueryBuilderOptions = React.memo<Props>(
   }
 );
 
+function shouldShowExemplarSwitch(queryType: LokiQueryType, app?: CoreApp) {
+  return app !== CoreApp.UnifiedAlerting && queryType === LokiQueryType.Range;
+}
+
 function getCollapsedInfo(
   query: LokiQuery,
   queryType: LokiQueryType,
@@ -199,6 +216,10 @@ function getCollapsedInfo(
     if (query.resolution) {
       items.push(`Resolution: ${resolutionLabel?.label}`);
     }
+
+    if (query.exemplar && queryType === LokiQueryType.Range) {
+      items.push(`Exemplars: true`);
+    }
   }
 
   return items;
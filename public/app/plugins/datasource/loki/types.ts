@@ -30,6 +30,8 @@ export interface LokiQuery extends LokiQueryFromSchema {
   // the temporary fix (until this gets improved in the codegen), is to
   // override it here
   queryType?: LokiQueryType;
+  /** Used to add the trace IDs found in the log lines of metric queries as exemplars */
+  exemplar?: boolean;
 
   /**
    * This is a property for the experimental query splitting feature.